@@ -0,0 +1,128 @@
+package statsview
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// clientAddr returns the address the request comes from
+func clientAddr(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// allowed reports whether addr is in one of the prefixes, every address is
+// allowed without prefix
+func allowed(addr netip.Addr, prefixes []netip.Prefix) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireAllowed refuses the clients out of the CIDRs set by
+// viewer.WithAllowedCIDRs, the requests whose address is unknown too
+func requireAllowed(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefixes := viewer.AllowedCIDRs()
+		if len(prefixes) > 0 {
+			addr, ok := clientAddr(r)
+			if !ok || !allowed(addr, prefixes) {
+				http.Error(w, "statsview: address not allowed", http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// rateLimiterIdle is how long the bucket of an address is kept without
+// requests, a full bucket is the same as none
+const rateLimiterIdle = 10 * time.Minute
+
+// bucket is the token bucket of an address
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits the requests of every address with a token bucket
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[netip.Addr]*bucket
+	pruned  time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[netip.Addr]*bucket)}
+}
+
+// take takes a token of the bucket of addr at now, it returns how long to
+// wait for the next one when the bucket is empty
+func (l *rateLimiter) take(addr netip.Addr, now time.Time, perSecond float64, burst int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.pruned) >= rateLimiterIdle {
+		for a, b := range l.buckets {
+			if now.Sub(b.last) >= rateLimiterIdle {
+				delete(l.buckets, a)
+			}
+		}
+		l.pruned = now
+	}
+
+	b, ok := l.buckets[addr]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		l.buckets[addr] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed*perSecond, float64(burst))
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// rateLimited limits the requests of every address to h as set by
+// viewer.WithRateLimit, the requests over the limit are answered with 429 Too
+// Many Requests
+func (vm *ViewManager) rateLimited(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		perSecond, burst := viewer.RateLimit()
+		if perSecond <= 0 {
+			h(w, r)
+			return
+		}
+		addr, _ := clientAddr(r)
+		if ok, wait := vm.limiter.take(addr, vm.Smgr.Now(), perSecond, burst); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "statsview: too many requests", http.StatusTooManyRequests)
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,137 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestClientAddr(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+		ok     bool
+	}{
+		{"192.0.2.1:1234", "192.0.2.1", true},
+		{"[2001:db8::1]:1234", "2001:db8::1", true},
+		{"[::ffff:192.0.2.1]:1234", "192.0.2.1", true},
+		{"192.0.2.1", "192.0.2.1", true},
+		{"pipe", "", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tt.remote
+		got, ok := clientAddr(r)
+		if ok != tt.ok || ok && got.String() != tt.want {
+			t.Errorf("clientAddr(%s) = %v, %v, want %s, %v", tt.remote, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	prefixes := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("2001:db8::/32")}
+	tests := []struct {
+		addr     string
+		prefixes []netip.Prefix
+		want     bool
+	}{
+		{"192.0.2.1", nil, true},
+		{"10.1.2.3", prefixes, true},
+		{"11.1.2.3", prefixes, false},
+		{"2001:db8::1", prefixes, true},
+		{"2001:db9::1", prefixes, false},
+	}
+	for _, tt := range tests {
+		if got := allowed(netip.MustParseAddr(tt.addr), tt.prefixes); got != tt.want {
+			t.Errorf("allowed(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterTake(t *testing.T) {
+	start := time.Unix(1000, 0)
+	a, b := netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("192.0.2.2")
+
+	// 2 requests per second with bursts of 3
+	tests := []struct {
+		name string
+		addr netip.Addr
+		at   time.Duration
+		ok   bool
+		wait time.Duration
+	}{
+		{"burst", a, 0, true, 0},
+		{"burst", a, 0, true, 0},
+		{"burst", a, 0, true, 0},
+		{"empty", a, 0, false, 500 * time.Millisecond},
+		{"other address", b, 0, true, 0},
+		{"half refilled", a, 250 * time.Millisecond, false, 250 * time.Millisecond},
+		{"refilled", a, 500 * time.Millisecond, true, 0},
+		{"capped at the burst", a, time.Hour, true, 0},
+		{"capped at the burst", a, time.Hour, true, 0},
+		{"capped at the burst", a, time.Hour, true, 0},
+		{"capped at the burst", a, time.Hour, false, 500 * time.Millisecond},
+	}
+	l := newRateLimiter()
+	for i, tt := range tests {
+		ok, wait := l.take(tt.addr, start.Add(tt.at), 2, 3)
+		if ok != tt.ok || wait != tt.wait {
+			t.Errorf("%d %s: take() = %v, %v, want %v, %v", i, tt.name, ok, wait, tt.ok, tt.wait)
+		}
+	}
+	// b is idle for an hour, its bucket is pruned
+	if _, ok := l.buckets[b]; ok {
+		t.Error("the idle bucket isn't pruned")
+	}
+}
+
+func TestAccessControl(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithAllowedCIDRs(nil), viewer.WithRateLimit(0, 0))
+
+	tests := []struct {
+		name     string
+		config   []viewer.Option
+		remote   string
+		path     string
+		requests int
+		status   int
+	}{
+		{"allowed", []viewer.Option{viewer.WithAllowedCIDRs([]string{"10.0.0.0/8"})}, "10.1.2.3:1234", "/debug/statsview", 1, http.StatusOK},
+		{"allowed address", []viewer.Option{viewer.WithAllowedCIDRs([]string{"192.0.2.1"})}, "192.0.2.1:1234", "/debug/statsview", 1, http.StatusOK},
+		{"not allowed", []viewer.Option{viewer.WithAllowedCIDRs([]string{"10.0.0.0/8"})}, "192.0.2.1:1234", "/debug/statsview", 1, http.StatusForbidden},
+		{"unknown address", []viewer.Option{viewer.WithAllowedCIDRs([]string{"10.0.0.0/8"})}, "pipe", "/debug/statsview/healthz", 1, http.StatusForbidden},
+		{"under the limit", []viewer.Option{viewer.WithRateLimit(1, 2)}, "192.0.2.1:1234", "/debug/statsview/api/v1/status", 2, http.StatusOK},
+		{"over the limit", []viewer.Option{viewer.WithRateLimit(1, 2)}, "192.0.2.1:1234", "/debug/statsview/api/v1/status", 3, http.StatusTooManyRequests},
+		{"dashboard not limited", []viewer.Option{viewer.WithRateLimit(1, 2)}, "192.0.2.1:1234", "/debug/statsview", 3, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := viewer.SetConfiguration(append([]viewer.Option{viewer.WithAllowedCIDRs(nil), viewer.WithRateLimit(0, 0)}, tt.config...)...); err != nil {
+				t.Fatal(err)
+			}
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < tt.requests; i++ {
+				r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				r.RemoteAddr = tt.remote
+				rec = httptest.NewRecorder()
+				mgr.srv.Handler.ServeHTTP(rec, r)
+			}
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "1" {
+				t.Errorf("Retry-After = %q, want 1", rec.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
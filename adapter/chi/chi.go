@@ -0,0 +1,18 @@
+// Package chiadapter mounts the statsview routes into a chi router
+//
+//	r := chi.NewRouter()
+//	chiadapter.Register(r, mgr)
+package chiadapter
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/mortum5/statsview"
+)
+
+// Register adds the dashboard, its data endpoints and pprof to r
+func Register(r chi.Router, mgr *statsview.ViewManager) {
+	h := mgr.Handler()
+	r.Handle(statsview.BasePath, h)
+	r.Handle(statsview.BasePath+"/*", h)
+	r.Handle(statsview.PprofPath+"/*", h)
+}
@@ -0,0 +1,25 @@
+module github.com/mortum5/statsview/adapter/chi
+
+go 1.23
+
+require github.com/mortum5/statsview v0.0.0-20261016185748-67e289beea63
+
+require (
+	github.com/golang/snappy v0.0.4 // indirect
+	github.com/google/pprof v0.0.0-20240409012703-83162a5b38cd // indirect
+	github.com/pelletier/go-toml/v2 v2.2.3 // indirect
+	golang.org/x/image v0.18.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+require (
+	github.com/go-chi/chi/v5 v5.3.1
+	github.com/go-echarts/go-echarts/v2 v2.2.3 // indirect
+	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c // indirect
+	github.com/rs/cors v1.7.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+)
+
+// the checkout is built against its own module, the users of the adapter
+// get the version required above
+replace github.com/mortum5/statsview => ../../
@@ -0,0 +1,23 @@
+// Package echoadapter mounts the statsview routes into an echo instance or group
+//
+//	e := echo.New()
+//	echoadapter.Register(e, mgr)
+package echoadapter
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/mortum5/statsview"
+)
+
+// Router is satisfied by both *echo.Echo and *echo.Group
+type Router interface {
+	Any(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) []*echo.Route
+}
+
+// Register adds the dashboard, its data endpoints and pprof to r
+func Register(r Router, mgr *statsview.ViewManager) {
+	h := echo.WrapHandler(mgr.Handler())
+	r.Any(statsview.BasePath, h)
+	r.Any(statsview.BasePath+"/*", h)
+	r.Any(statsview.PprofPath+"/*", h)
+}
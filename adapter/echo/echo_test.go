@@ -0,0 +1,95 @@
+package echoadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount func(e *echo.Echo, mgr *statsview.ViewManager)
+		path  string
+	}{
+		{"root", func(e *echo.Echo, mgr *statsview.ViewManager) { Register(e, mgr) }, "/debug/statsview/status"},
+		{"group", func(e *echo.Echo, mgr *statsview.ViewManager) { Register(e.Group("/admin"), mgr) }, "/admin/debug/statsview/status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := statsview.New(statsview.Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			e := echo.New()
+			tt.mount(e, mgr)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+		})
+	}
+}
+
+// pages are the pages of the dashboard, their scripts and their requests
+// must stay under the prefix the routes are mounted at
+var pages = []string{"", "/goroutines", "/objects", "/locks", "/heapdiff", "/profiles", "/flamegraph"}
+
+// assetSrc matches the scripts loaded by a page
+var assetSrc = regexp.MustCompile(`src="([^"]+)"`)
+
+func TestRegisterPages(t *testing.T) {
+	tests := []struct {
+		name   string
+		mount  func(e *echo.Echo, mgr *statsview.ViewManager)
+		prefix string
+	}{
+		{"root", func(e *echo.Echo, mgr *statsview.ViewManager) { Register(e, mgr) }, ""},
+		{"group", func(e *echo.Echo, mgr *statsview.ViewManager) { Register(e.Group("/admin"), mgr) }, "/admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := statsview.New(statsview.Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			e := echo.New()
+			tt.mount(e, mgr)
+			get := func(path string) (int, string) {
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				return rec.Code, rec.Body.String()
+			}
+			base := tt.prefix + statsview.BasePath
+			for _, page := range pages {
+				status, body := get(base + page)
+				if status != http.StatusOK {
+					t.Errorf("%s: status = %d, want %d", page, status, http.StatusOK)
+					continue
+				}
+				if !strings.Contains(body, `base = "`+base+`";`) {
+					t.Errorf("%s doesn't request under %s", page, base)
+				}
+				for _, src := range assetSrc.FindAllStringSubmatch(body, -1) {
+					if !strings.HasPrefix(src[1], base+"/") {
+						t.Errorf("%s loads %s outside of %s", page, src[1], base)
+						continue
+					}
+					if status, _ := get(src[1]); status != http.StatusOK {
+						t.Errorf("%s: %s status = %d, want %d", page, src[1], status, http.StatusOK)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+module github.com/mortum5/statsview/adapter/echo
+
+go 1.25.0
+
+require (
+	github.com/labstack/echo/v4 v4.15.4
+	github.com/mortum5/statsview v0.0.0-20261016185748-67e289beea63
+)
+
+require (
+	github.com/go-echarts/go-echarts/v2 v2.2.3 // indirect
+	github.com/golang/snappy v0.0.4 // indirect
+	github.com/google/pprof v0.0.0-20240409012703-83162a5b38cd // indirect
+	github.com/labstack/gommon v0.5.0 // indirect
+	github.com/mattn/go-colorable v0.1.15 // indirect
+	github.com/mattn/go-isatty v0.0.22 // indirect
+	github.com/pelletier/go-toml/v2 v2.2.3 // indirect
+	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c // indirect
+	github.com/rs/cors v1.7.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/crypto v0.53.0 // indirect
+	golang.org/x/image v0.18.0 // indirect
+	golang.org/x/net v0.56.0 // indirect
+	golang.org/x/sys v0.46.0 // indirect
+	golang.org/x/text v0.38.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// the checkout is built against its own module, the users of the adapter
+// get the version required above
+replace github.com/mortum5/statsview => ../../
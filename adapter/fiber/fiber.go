@@ -0,0 +1,19 @@
+// Package fiberadapter mounts the statsview routes into a fiber app or group
+//
+//	app := fiber.New()
+//	fiberadapter.Register(app, mgr)
+package fiberadapter
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/mortum5/statsview"
+)
+
+// Register adds the dashboard, its data endpoints and pprof to r
+func Register(r fiber.Router, mgr *statsview.ViewManager) {
+	h := adaptor.HTTPHandler(mgr.Handler())
+	r.All(statsview.BasePath, h)
+	r.All(statsview.BasePath+"/*", h)
+	r.All(statsview.PprofPath+"/*", h)
+}
@@ -0,0 +1,103 @@
+package fiberadapter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount func(app *fiber.App, mgr *statsview.ViewManager)
+		path  string
+	}{
+		{"root", func(app *fiber.App, mgr *statsview.ViewManager) { Register(app, mgr) }, "/debug/statsview/status"},
+		{"group", func(app *fiber.App, mgr *statsview.ViewManager) { Register(app.Group("/admin"), mgr) }, "/admin/debug/statsview/status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := statsview.New(statsview.Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			app := fiber.New()
+			tt.mount(app, mgr)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+		})
+	}
+}
+
+// pages are the pages of the dashboard, their scripts and their requests
+// must stay under the prefix the routes are mounted at
+var pages = []string{"", "/goroutines", "/objects", "/locks", "/heapdiff", "/profiles", "/flamegraph"}
+
+// assetSrc matches the scripts loaded by a page
+var assetSrc = regexp.MustCompile(`src="([^"]+)"`)
+
+func TestRegisterPages(t *testing.T) {
+	tests := []struct {
+		name   string
+		mount  func(app *fiber.App, mgr *statsview.ViewManager)
+		prefix string
+	}{
+		{"root", func(app *fiber.App, mgr *statsview.ViewManager) { Register(app, mgr) }, ""},
+		{"group", func(app *fiber.App, mgr *statsview.ViewManager) { Register(app.Group("/admin"), mgr) }, "/admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := statsview.New(statsview.Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			app := fiber.New()
+			tt.mount(app, mgr)
+			get := func(path string) (int, string) {
+				resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer resp.Body.Close()
+				body, _ := io.ReadAll(resp.Body)
+				return resp.StatusCode, string(body)
+			}
+			base := tt.prefix + statsview.BasePath
+			for _, page := range pages {
+				status, body := get(base + page)
+				if status != http.StatusOK {
+					t.Errorf("%s: status = %d, want %d", page, status, http.StatusOK)
+					continue
+				}
+				if !strings.Contains(body, `base = "`+base+`";`) {
+					t.Errorf("%s doesn't request under %s", page, base)
+				}
+				for _, src := range assetSrc.FindAllStringSubmatch(body, -1) {
+					if !strings.HasPrefix(src[1], base+"/") {
+						t.Errorf("%s loads %s outside of %s", page, src[1], base)
+						continue
+					}
+					if status, _ := get(src[1]); status != http.StatusOK {
+						t.Errorf("%s: %s status = %d, want %d", page, src[1], status, http.StatusOK)
+					}
+				}
+			}
+		})
+	}
+}
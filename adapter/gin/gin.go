@@ -0,0 +1,18 @@
+// Package ginadapter mounts the statsview routes into a gin engine or group
+//
+//	r := gin.Default()
+//	ginadapter.Register(r, mgr)
+package ginadapter
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mortum5/statsview"
+)
+
+// Register adds the dashboard, its data endpoints and pprof to r
+func Register(r gin.IRoutes, mgr *statsview.ViewManager) {
+	h := gin.WrapH(mgr.Handler())
+	r.Any(statsview.BasePath, h)
+	r.Any(statsview.BasePath+"/*path", h)
+	r.Any(statsview.PprofPath+"/*path", h)
+}
@@ -0,0 +1,97 @@
+package ginadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestRegister(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name  string
+		mount func(r *gin.Engine, mgr *statsview.ViewManager)
+		path  string
+	}{
+		{"root", func(r *gin.Engine, mgr *statsview.ViewManager) { Register(r, mgr) }, "/debug/statsview/status"},
+		{"group", func(r *gin.Engine, mgr *statsview.ViewManager) { Register(r.Group("/admin"), mgr) }, "/admin/debug/statsview/status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := statsview.New(statsview.Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			r := gin.New()
+			tt.mount(r, mgr)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+		})
+	}
+}
+
+// pages are the pages of the dashboard, their scripts and their requests
+// must stay under the prefix the routes are mounted at
+var pages = []string{"", "/goroutines", "/objects", "/locks", "/heapdiff", "/profiles", "/flamegraph"}
+
+// assetSrc matches the scripts loaded by a page
+var assetSrc = regexp.MustCompile(`src="([^"]+)"`)
+
+func TestRegisterPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		mount  func(r *gin.Engine, mgr *statsview.ViewManager)
+		prefix string
+	}{
+		{"root", func(r *gin.Engine, mgr *statsview.ViewManager) { Register(r, mgr) }, ""},
+		{"group", func(r *gin.Engine, mgr *statsview.ViewManager) { Register(r.Group("/admin"), mgr) }, "/admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := statsview.New(statsview.Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			r := gin.New()
+			tt.mount(r, mgr)
+			get := func(path string) (int, string) {
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				return rec.Code, rec.Body.String()
+			}
+			base := tt.prefix + statsview.BasePath
+			for _, page := range pages {
+				status, body := get(base + page)
+				if status != http.StatusOK {
+					t.Errorf("%s: status = %d, want %d", page, status, http.StatusOK)
+					continue
+				}
+				if !strings.Contains(body, `base = "`+base+`";`) {
+					t.Errorf("%s doesn't request under %s", page, base)
+				}
+				for _, src := range assetSrc.FindAllStringSubmatch(body, -1) {
+					if !strings.HasPrefix(src[1], base+"/") {
+						t.Errorf("%s loads %s outside of %s", page, src[1], base)
+						continue
+					}
+					if status, _ := get(src[1]); status != http.StatusOK {
+						t.Errorf("%s: %s status = %d, want %d", page, src[1], status, http.StatusOK)
+					}
+				}
+			}
+		})
+	}
+}
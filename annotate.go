@@ -0,0 +1,62 @@
+package statsview
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// maxAnnotations bounds the annotations kept, older ones have long scrolled
+// out of the charts anyway
+const maxAnnotations = 100
+
+// annotation marks a change of the process on the x-axis of every chart
+type annotation struct {
+	// Time is the x-axis category, i.e. the time of the last collection
+	// formatted with viewer.FormatTime
+	Time string    `json:"time"`
+	Text string    `json:"text"`
+	At   time.Time `json:"-"`
+}
+
+// Annotate places a labeled vertical marker on every chart at the current
+// collection time, e.g. for deploys, cache flushes or load-test phases
+func (vm *ViewManager) Annotate(text string) {
+	at := vm.Smgr.CollectTime()
+	if at.IsZero() {
+		// nothing collected yet, no chart to mark but the time is kept
+		at = vm.Smgr.Now()
+	}
+	a := annotation{
+		Time: viewer.FormatTime(at),
+		Text: text,
+		At:   at,
+	}
+
+	vm.annotationsMu.Lock()
+	defer vm.annotationsMu.Unlock()
+	vm.annotations = append(vm.annotations, a)
+	if n := len(vm.annotations); n > maxAnnotations {
+		vm.annotations = append(vm.annotations[:0], vm.annotations[n-maxAnnotations:]...)
+	}
+	viewer.Logger().Info("statsview: annotated", "text", text)
+}
+
+// serveAnnotations lists the annotations, a POST of `text` adds one
+func (vm *ViewManager) serveAnnotations(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		text := strings.TrimSpace(r.FormValue("text"))
+		if text == "" {
+			http.Error(w, "statsview: missing annotation text", http.StatusBadRequest)
+			return
+		}
+		vm.Annotate(text)
+	}
+
+	vm.annotationsMu.Lock()
+	annotations := append([]annotation{}, vm.annotations...)
+	vm.annotationsMu.Unlock()
+	writeData(w, r, annotations)
+}
@@ -0,0 +1,93 @@
+package statsview
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestServeAnnotations(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+	viewer.SetConfiguration(viewer.WithAdminToken("s3cret"))
+
+	tests := []struct {
+		name   string
+		method string
+		auth   string
+		text   string
+		status int
+		want   []string
+	}{
+		{name: "list", method: http.MethodGet, status: http.StatusOK, want: []string{}},
+		{name: "add", method: http.MethodPost, auth: "Bearer s3cret", text: " deploy v1.2 ", status: http.StatusOK, want: []string{"deploy v1.2"}},
+		{name: "unauthenticated", method: http.MethodPost, text: "deploy", status: http.StatusUnauthorized},
+		{name: "blank", method: http.MethodPost, auth: "Bearer s3cret", text: "  ", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := New(Viewers{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			form := url.Values{"text": {tt.text}}
+			r := httptest.NewRequest(tt.method, "/debug/statsview/annotations", strings.NewReader(form.Encode()))
+			r.Header.Set("X-Requested-With", "XMLHttpRequest")
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				if len(mgr.annotations) != 0 {
+					t.Errorf("annotations = %v, want none", mgr.annotations)
+				}
+				return
+			}
+			var as []annotation
+			if err := json.Unmarshal(rec.Body.Bytes(), &as); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, a := range as {
+				got = append(got, a.Text)
+				if a.Time == "" {
+					t.Errorf("annotation %q has no time", a.Text)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("annotations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateKeepsTheLatest(t *testing.T) {
+	mgr, err := New(Viewers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	for i := 0; i < maxAnnotations+5; i++ {
+		mgr.Annotate(fmt.Sprint(i))
+	}
+	if n := len(mgr.annotations); n != maxAnnotations {
+		t.Fatalf("kept %d annotations, want %d", n, maxAnnotations)
+	}
+	if first, last := mgr.annotations[0].Text, mgr.annotations[maxAnnotations-1].Text; first != "5" || last != fmt.Sprint(maxAnnotations+4) {
+		t.Errorf("kept %s to %s, want the latest", first, last)
+	}
+}
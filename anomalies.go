@@ -0,0 +1,125 @@
+package statsview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mortum5/statsview/internal/anomaly"
+	"github.com/mortum5/statsview/notify"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// notifyTimeout bounds the sending of an anomaly to a notifier
+const notifyTimeout = 10 * time.Second
+
+// Anomaly is a run of unusual values of a series, Score is the distance of
+// Value from the moving average in standard deviations. A resolved anomaly
+// ended at End with a normal value
+type Anomaly struct {
+	Viewer   string
+	Series   string
+	Time     time.Time
+	End      time.Time
+	Value    float64
+	Score    float64
+	Resolved bool
+}
+
+// anomalyRegion is a region of anomalous values, From and To are the x-axis
+// categories of the charts
+type anomalyRegion struct {
+	anomaly.Region
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// AddAnomalyHook registers a function called as an anomaly starts and as
+// it's resolved, e.g. to page someone or annotate the charts, with
+// viewer.WithAnomalyDetection. It must be called before Start
+func (vm *ViewManager) AddAnomalyHook(hook func(Anomaly)) {
+	vm.anomalyHooks = append(vm.anomalyHooks, hook)
+}
+
+// AddNotifier sends the anomalies starting and resolved to n, e.g.
+// notify.Slack, with viewer.WithAnomalyDetection. It must be called before Start
+func (vm *ViewManager) AddNotifier(n notify.Notifier) {
+	vm.notifiers = append(vm.notifiers, n)
+}
+
+// detectAnomalies folds the points into the detector and calls the hooks of
+// the anomalies starting or resolved
+func (vm *ViewManager) detectAnomalies(points []viewer.Point) {
+	for _, p := range points {
+		for _, e := range vm.anomalies.Observe(p.Viewer, p.Series, p.Time, p.Value) {
+			switch e.Change {
+			case anomaly.Started:
+				viewer.Logger().Info("statsview: anomaly", "viewer", e.Viewer, "series", e.Series, "value", e.Value, "score", e.Score)
+			case anomaly.Ended:
+				viewer.Logger().Info("statsview: anomaly resolved", "viewer", e.Viewer, "series", e.Series)
+			default:
+				continue
+			}
+			a := Anomaly{
+				Viewer:   e.Viewer,
+				Series:   e.Series,
+				Time:     e.Start,
+				End:      e.End,
+				Value:    e.Value,
+				Score:    e.Score,
+				Resolved: e.Change == anomaly.Ended,
+			}
+			for _, hook := range vm.anomalyHooks {
+				hook(a)
+			}
+			vm.notifyAnomaly(a)
+		}
+	}
+}
+
+// notifyAnomaly sends the anomaly to the notifiers in the background, so a
+// slow chat service doesn't hold the collection
+func (vm *ViewManager) notifyAnomaly(a Anomaly) {
+	if len(vm.notifiers) == 0 {
+		return
+	}
+	e := notify.Event{
+		Key:   a.Viewer + "." + a.Series,
+		Title: fmt.Sprintf("anomaly of %s.%s", a.Viewer, a.Series),
+		Text: fmt.Sprintf("%g is %.1f standard deviations from the average since %s",
+			a.Value, a.Score, a.Time.Format(time.RFC3339)),
+		Resolved: a.Resolved,
+		Time:     a.Time,
+	}
+	if a.Resolved {
+		e.Text = fmt.Sprintf("back to normal at %s after %s, it peaked at %g",
+			a.End.Format(time.RFC3339), a.End.Sub(a.Time).Round(time.Second), a.Value)
+		e.Time = a.End
+	}
+	for _, n := range vm.notifiers {
+		go func(n notify.Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+			if err := n.Notify(ctx, e); err != nil {
+				viewer.Logger().Error("statsview: notification failed", "notifier", fmt.Sprintf("%T", n), "key", e.Key, "err", err)
+			}
+		}(n)
+	}
+}
+
+// serveAnomalies lists the recent regions of anomalous values, 404 Not Found
+// when the detection is disabled
+func (vm *ViewManager) serveAnomalies(w http.ResponseWriter, r *http.Request) {
+	if vm.anomalies == nil {
+		http.Error(w, "statsview: the anomaly detection is disabled, see viewer.WithAnomalyDetection", http.StatusNotFound)
+		return
+	}
+
+	regions := vm.anomalies.Regions(time.Time{})
+	res := make([]anomalyRegion, 0, len(regions))
+	for _, rg := range regions {
+		res = append(res, anomalyRegion{Region: rg, From: viewer.FormatTime(rg.Start), To: viewer.FormatTime(rg.End)})
+	}
+	writeData(w, r, res)
+}
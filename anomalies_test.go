@@ -0,0 +1,134 @@
+package statsview
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/internal/anomaly"
+	"github.com/mortum5/statsview/notify"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestAnomalies(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	rec := httptest.NewRecorder()
+	mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/anomalies", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d without the detection, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	mgr.anomalies = anomaly.New(viewer.DefaultAnomalyThreshold)
+	var hooked []Anomaly
+	mgr.AddAnomalyHook(func(a Anomaly) { hooked = append(hooked, a) })
+
+	epoch := time.Unix(1700000000, 0)
+	at := func(s int) time.Time { return epoch.Add(time.Duration(s) * time.Second) }
+	for i := 0; i < 30; i++ {
+		mgr.detectAnomalies([]viewer.Point{{Viewer: viewer.VGoroutine, Series: "Goroutines", Value: float64(99 + i%2*2), Time: at(i)}})
+	}
+	// the region starting is hooked once, then as it's resolved
+	for i, v := range []float64{1000, 900, 100} {
+		mgr.detectAnomalies([]viewer.Point{{Viewer: viewer.VGoroutine, Series: "Goroutines", Value: v, Time: at(30 + i)}})
+	}
+
+	if len(hooked) != 2 {
+		t.Fatalf("hooked %d anomalies, want 2", len(hooked))
+	}
+	if a := hooked[0]; a.Viewer != viewer.VGoroutine || a.Series != "Goroutines" || !a.Time.Equal(at(30)) || a.Value != 1000 || a.Score <= 4 || a.Resolved {
+		t.Errorf("hooked %+v, want the spike of the goroutines", a)
+	}
+	if a := hooked[1]; !a.Resolved || !a.Time.Equal(at(30)) || !a.End.Equal(at(31)) || a.Value != 1000 {
+		t.Errorf("hooked %+v, want the spike resolved", a)
+	}
+
+	rec = httptest.NewRecorder()
+	mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/anomalies", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var regions []anomalyRegion
+	if err := json.Unmarshal(rec.Body.Bytes(), &regions); err != nil {
+		t.Fatal(err)
+	}
+	if len(regions) != 1 {
+		t.Fatalf("%d regions, want 1", len(regions))
+	}
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"viewer", regions[0].Viewer, viewer.VGoroutine},
+		{"from", regions[0].From, viewer.FormatTime(at(30))},
+		{"to", regions[0].To, viewer.FormatTime(at(31))},
+		{"open", regions[0].Open, false},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// notifications is a Notifier passing the events on
+type notifications chan notify.Event
+
+func (n notifications) Notify(_ context.Context, e notify.Event) error {
+	n <- e
+	return nil
+}
+
+func TestNotifyAnomaly(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	n := make(notifications, 1)
+	mgr.AddNotifier(n)
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		a    Anomaly
+		want notify.Event
+	}{
+		{
+			name: "firing",
+			a:    Anomaly{Viewer: "heap", Series: "Alloc", Time: start, Value: 1000, Score: 12.34},
+			want: notify.Event{
+				Key: "heap.Alloc", Title: "anomaly of heap.Alloc", Time: start,
+				Text: "1000 is 12.3 standard deviations from the average since 2026-10-16T12:00:00Z",
+			},
+		},
+		{
+			name: "resolved",
+			a:    Anomaly{Viewer: "heap", Series: "Alloc", Time: start, End: start.Add(90 * time.Second), Value: 1000, Resolved: true},
+			want: notify.Event{
+				Key: "heap.Alloc", Title: "anomaly of heap.Alloc", Time: start.Add(90 * time.Second), Resolved: true,
+				Text: "back to normal at 2026-10-16T12:01:30Z after 1m30s, it peaked at 1000",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr.notifyAnomaly(tt.a)
+			select {
+			case got := <-n:
+				if got != tt.want {
+					t.Errorf("notified %+v, want %+v", got, tt.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("nothing notified")
+			}
+		})
+	}
+}
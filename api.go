@@ -0,0 +1,353 @@
+package statsview
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/mortum5/statsview/internal/goroutine"
+	"github.com/mortum5/statsview/internal/heapprof"
+	"github.com/mortum5/statsview/internal/lockprof"
+	"github.com/mortum5/statsview/internal/memtrend"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// APIPrefix is the prefix of the versioned JSON API. Within v1 the routes,
+// their parameters and the fields of their responses stay, fields may only
+// be added
+const APIPrefix = "/debug/statsview/api/v1"
+
+// viewerParam is the path parameter of the routes served for every viewer
+const viewerParam = "{viewer}"
+
+// apiParam is a parameter of an API route, it's read from the query or
+// the form of a POST
+type apiParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Repeated    bool   `json:"repeated,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// apiRoute is a data endpoint of the API, it's served under its legacy
+// path too. A path ending with viewerParam is served for every viewer
+type apiRoute struct {
+	Path        string     `json:"path"`
+	Legacy      string     `json:"legacy,omitempty"`
+	Description string     `json:"description"`
+	Methods     []string   `json:"methods"`
+	Params      []apiParam `json:"params,omitempty"`
+	// Admin routes require the admin credentials for POST and PUT, reading
+	// them is in the read-only mode like the other routes unless AdminRead
+	Admin bool `json:"admin,omitempty"`
+	// AdminRead routes require the admin credentials for reading too, once
+	// they're configured, e.g. the captures of profiles
+	AdminRead bool `json:"admin_read,omitempty"`
+
+	// response is a value of the type of the JSON response, nil without
+	// content, request is the one of the JSON body of a PUT
+	response interface{}
+	// download is the content type of a response which isn't JSON, e.g. a
+	// profile in the pprof format
+	download string
+	request  interface{}
+	handler  http.HandlerFunc
+	// viewerHandler serves the routes of the viewers
+	viewerHandler func(v viewer.Viewer) http.HandlerFunc
+}
+
+var (
+	get     = []string{http.MethodGet}
+	post    = []string{http.MethodPost}
+	getPost = []string{http.MethodGet, http.MethodPost}
+	getPut  = []string{http.MethodGet, http.MethodPut}
+)
+
+// apiRoutes returns the endpoints of the API
+func (vm *ViewManager) apiRoutes() []apiRoute {
+	return []apiRoute{
+		{
+			Path: "/meta", Legacy: "/debug/statsview/meta", Methods: get,
+			Description: "The registered viewers and their series",
+			response:    meta{}, handler: vm.serveMeta,
+		},
+		{
+			Path: "/metrics", Legacy: "/debug/statsview/view/all", Methods: get,
+			Description: "The latest metrics of every viewer keyed by viewer, the skipped and failing viewers are left out",
+			response:    map[string]viewer.Metrics{}, handler: vm.serveAll,
+		},
+		{
+			Path: "/metrics/" + viewerParam, Legacy: "/debug/statsview/view/" + viewerParam, Methods: get,
+			Description: "The latest metrics of the viewer in the unit of its chart, 204 No Content while the viewer is skipped",
+			Params: []apiParam{
+				{Name: "series", Type: "string", Repeated: true, Description: "Keeps the values of the series, comma separated, e.g. Alloc,Inuse"},
+			},
+			response: viewer.Metrics{},
+			viewerHandler: func(v viewer.Viewer) http.HandlerFunc {
+				return vm.countErrors(v.Name(), vm.serveView(v))
+			},
+		},
+		{
+			Path: "/snapshot", Legacy: "/debug/statsview/snapshot", Methods: get,
+			Description: "The latest values of every collecting viewer in base units",
+			response:    snapshot{}, handler: vm.serveSnapshot,
+		},
+		{
+			Path: "/summary", Legacy: "/debug/statsview/summary", Methods: get,
+			Description: "The aggregates of the recorded history in base units, 404 Not Found when the history is disabled",
+			Params: []apiParam{
+				{Name: "last", Type: "duration", Description: "The aggregated window, e.g. 5m, the whole history by default"},
+				{Name: "series", Type: "string", Repeated: true, Description: "Keeps the targets containing one of the values"},
+			},
+			response: summary{}, handler: vm.serveSummary,
+		},
+		{
+			Path: "/status", Legacy: "/debug/statsview/status", Methods: get,
+			Description: "The state of the collection",
+			response:    collectionStatus{}, handler: vm.status,
+		},
+		{
+			Path: "/buildinfo", Legacy: "/debug/statsview/buildinfo", Methods: get,
+			Description: "The build and the environment of the process",
+			response:    buildInfo{}, handler: vm.serveBuildInfo,
+		},
+		{
+			Path: "/history/" + viewerParam, Legacy: "/debug/statsview/history/" + viewerParam, Methods: get,
+			Description: "The recorded points of the viewer in base units, 404 Not Found when the history is disabled",
+			Params: []apiParam{
+				{Name: "since", Type: "string", Description: "RFC 3339, unix milliseconds or a duration ago, e.g. 15m, the start of the history by default"},
+				{Name: "until", Type: "string", Description: "RFC 3339, unix milliseconds or a duration ago, now by default"},
+				{Name: "step", Type: "duration", Description: "Downsamples the points to their means of every step, e.g. 1m"},
+				{Name: "series", Type: "string", Repeated: true, Description: "Keeps the series, comma separated, e.g. Alloc,Inuse"},
+			},
+			response: viewerHistory{},
+			viewerHandler: func(v viewer.Viewer) http.HandlerFunc {
+				return vm.countErrors(v.Name(), vm.serveHistory(v))
+			},
+		},
+		{
+			Path: "/goroutines", Legacy: "/debug/statsview/goroutines/groups", Methods: get,
+			Description: "The goroutines grouped by stack or creation site",
+			Params: []apiParam{
+				{Name: "by", Type: "string", Description: "stack (default) or creator"},
+			},
+			response: []goroutine.Group{}, handler: goroutineGroups,
+		},
+		{
+			Path: "/goroutines/leaks", Legacy: "/debug/statsview/goroutines/leaks", Methods: get,
+			Description: "The creation sites suspected to leak goroutines",
+			response:    []goroutine.Leak{}, handler: vm.goroutineLeaks,
+		},
+		{
+			Path: "/goroutines/stuck", Legacy: "/debug/statsview/goroutines/stuck", Methods: get,
+			Description: "The sites where goroutines are blocked for longer than the stuck threshold",
+			response:    []goroutine.Stuck{}, handler: vm.goroutinesStuck,
+		},
+		{
+			Path: "/profiles", Legacy: "/debug/statsview/profiles/list", Methods: get,
+			Description: "The profiles of runtime/pprof, the custom ones of the program included",
+			response:    []profileInfo{}, handler: listProfiles,
+		},
+		{
+			Path: "/baseline", Legacy: "/debug/statsview/baseline", Methods: getPost, Admin: true,
+			Description: "The normal bands of the series recorded as the baseline in the units of their charts, a POST records the history of the last window as the baseline, 404 Not Found without a baseline or history",
+			Params: []apiParam{
+				{Name: "last", Type: "duration", Description: "The recorded window, 5m by default"},
+				{Name: "clear", Type: "boolean", Description: "Forgets the baseline when 1"},
+			},
+			response: baseline{}, handler: requireAdmin(vm.serveBaseline),
+		},
+		{
+			Path: "/anomalies", Legacy: "/debug/statsview/anomalies", Methods: get,
+			Description: "The recent regions of anomalous values of the series, 404 Not Found when the anomaly detection is disabled",
+			response:    []anomalyRegion{}, handler: vm.serveAnomalies,
+		},
+		{
+			Path: "/memory/trend", Legacy: "/debug/statsview/memory/trend", Methods: get,
+			Description: "The memory series growing steadily with the projected time to reach the memory limit",
+			response:    []memtrend.Warning{}, handler: vm.memoryTrend,
+		},
+		{
+			Path: "/objects/top", Legacy: "/debug/statsview/objects/top", Methods: get,
+			Description: "The top allocation sites by in-use bytes with their trend",
+			Params: []apiParam{
+				{Name: "n", Type: "integer", Description: "The number of sites, 20 by default"},
+			},
+			response: []heapprof.Site{}, handler: vm.objectsTop,
+		},
+		{
+			Path: "/heap/diff", Legacy: "/debug/statsview/heap/diff", Methods: getPost, Admin: true,
+			Description: "The in-use memory grown by allocation site since the heap baseline, a POST stores the current heap profile as the baseline, 404 Not Found without a baseline",
+			response:    heapDiff{}, handler: requireAdmin(vm.serveHeapDiff),
+		},
+		{
+			Path: "/profiles/capture", Legacy: "/debug/statsview/profiles/capture", Methods: get, Admin: true, AdminRead: true,
+			Description: "A snapshot of the profile of runtime/pprof, in the pprof format or as text, 404 Not Found when pprof is disabled",
+			Params: []apiParam{
+				{Name: "name", Type: "string", Required: true, Description: "The profile, e.g. heap or goroutine"},
+				{Name: "debug", Type: "integer", Description: "0 for the pprof format, 1 or 2 for text"},
+			},
+			download: "application/octet-stream", handler: requireAdminRead(captureProfile),
+		},
+		{
+			Path: "/profile/wallclock", Legacy: "/debug/statsview/profile/wallclock", Methods: get, Admin: true, AdminRead: true,
+			Description: "A wall-clock profile of the goroutines, on and off CPU, in the pprof format, 409 Conflict while another is captured",
+			Params: []apiParam{
+				{Name: "seconds", Type: "integer", Description: "The duration of the capture, 30 by default"},
+			},
+			download: "application/octet-stream", handler: requireAdminRead(vm.wallClockProfile),
+		},
+		{
+			Path: "/flamegraph/data", Legacy: "/debug/statsview/flamegraph/data", Methods: get, Admin: true, AdminRead: true,
+			Description: "The flame graph of a CPU or wall-clock profile captured for the duration, 409 Conflict while another is captured",
+			Params: []apiParam{
+				{Name: "kind", Type: "string", Description: "cpu or wallclock, cpu by default"},
+				{Name: "seconds", Type: "integer", Description: "The duration of the capture, 30 by default"},
+			},
+			response: flamegraph{}, handler: requireAdminRead(vm.flamegraphData),
+		},
+		{
+			Path: "/trace/flight", Legacy: "/debug/statsview/trace/flight", Methods: get, Admin: true, AdminRead: true,
+			Description: "The execution trace of the last flight recorder window, 404 Not Found when the flight recorder is disabled",
+			download:    "application/octet-stream", handler: requireAdminRead(vm.flightTrace),
+		},
+		{
+			Path: "/session", Legacy: "/debug/statsview/session", Methods: get, Admin: true, AdminRead: true,
+			Description: "The session file of the history, gzipped JSON, 404 Not Found when the history is disabled",
+			download:    "application/gzip", handler: requireAdminRead(vm.exportSession),
+		},
+		{
+			Path: "/locks/top", Legacy: "/debug/statsview/locks/top", Methods: get,
+			Description: "The top lock sites by cumulative contention delay with their trend",
+			Params: []apiParam{
+				{Name: "n", Type: "integer", Description: "The number of sites, 20 by default"},
+			},
+			response: []lockprof.Site{}, handler: vm.locksTop,
+		},
+		{
+			Path: "/overhead", Legacy: "/debug/statsview/overhead", Methods: get,
+			Description: "The accumulated cost of statsview",
+			response:    viewer.Overhead{}, handler: vm.overhead,
+		},
+		{
+			Path: "/annotations", Legacy: "/debug/statsview/annotations", Methods: getPost, Admin: true,
+			Description: "The annotations of the charts, a POST adds one",
+			Params: []apiParam{
+				{Name: "text", Type: "string", Description: "The text of the annotation to add"},
+			},
+			response: []annotation{}, handler: requireAdmin(vm.serveAnnotations),
+		},
+		{
+			Path: "/control/gc", Legacy: "/debug/statsview/control/gc", Methods: post, Admin: true,
+			Description: "Runs a garbage collection",
+			handler:     requireAdmin(vm.forceGC),
+		},
+		{
+			Path: "/control/freeosmemory", Legacy: "/debug/statsview/control/freeosmemory", Methods: post, Admin: true,
+			Description: "Runs a garbage collection and returns as much memory to the OS as possible",
+			handler:     requireAdmin(vm.freeOSMemory),
+		},
+		{
+			Path: "/control/gomaxprocs", Legacy: "/debug/statsview/control/gomaxprocs", Methods: getPost, Admin: true,
+			Description: "GOMAXPROCS, a POST changes it",
+			Params: []apiParam{
+				{Name: "procs", Type: "integer", Description: "The new GOMAXPROCS"},
+			},
+			response: procsState{}, handler: requireAdmin(vm.gomaxprocs),
+		},
+		{
+			Path: "/control/gctuning", Legacy: "/debug/statsview/gc", Methods: getPost, Admin: true,
+			Description: "GOGC and GOMEMLIMIT, a POST changes them",
+			Params: []apiParam{
+				{Name: "gogc", Type: "string", Description: "A percentage or off"},
+				{Name: "gomemlimit", Type: "string", Description: "Bytes with an optional B/KiB/MiB/GiB/TiB suffix or off"},
+			},
+			response: gcTuningState{}, handler: requireAdmin(vm.gcTuning),
+		},
+		{
+			Path: "/profile/block", Legacy: "/debug/statsview/profile/block", Methods: getPost, Admin: true,
+			Description: "The block profile rate, a POST changes it",
+			Params: []apiParam{
+				{Name: "rate", Type: "integer", Description: "The new rate, see runtime.SetBlockProfileRate"},
+			},
+			response: map[string]int64{}, handler: requireAdmin(blockProfileRate),
+		},
+		{
+			Path: "/profile/mutex", Legacy: "/debug/statsview/profile/mutex", Methods: getPost, Admin: true,
+			Description: "The mutex profile fraction, a POST changes it",
+			Params: []apiParam{
+				{Name: "fraction", Type: "integer", Description: "The new fraction, see runtime.SetMutexProfileFraction"},
+			},
+			response: map[string]int64{}, handler: requireAdmin(mutexProfileFraction),
+		},
+		{
+			Path: "/config", Legacy: "/debug/statsview/config", Methods: getPut, Admin: true,
+			Description: "The interval, the max points, the theme and the viewers served, a PUT changes them while the server runs",
+			response:    liveConfig{}, request: liveConfig{}, handler: requireAdmin(vm.serveConfig),
+		},
+		{
+			Path: "/audit", Legacy: "/debug/statsview/audit", Methods: get,
+			Description: "The latest actions on the admin routes, refused or not, the oldest first. It requires the admin credentials once they're configured",
+			response:    []auditEntry{}, handler: vm.serveAudit,
+		},
+		{
+			Path: "/openapi.json", Methods: get,
+			Description: "The OpenAPI document of the API",
+			response:    map[string]interface{}{}, handler: vm.serveOpenAPI,
+		},
+	}
+}
+
+// byViewer serves the routes of the viewers, the viewer is named by the last
+// element of the path so that the viewers registered later are served too
+func (vm *ViewManager) byViewer(h func(v viewer.Viewer) http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v := vm.viewerByName(path.Base(r.URL.Path))
+		if v == nil {
+			http.NotFound(w, r)
+			return
+		}
+		h(v)(w, r)
+	}
+}
+
+// handleAPI registers the API and its legacy aliases on mux, the index of
+// the API lists its routes
+func (vm *ViewManager) handleAPI(mux *http.ServeMux) {
+	routes := vm.apiRoutes()
+	handle := func(path, legacy string, h http.HandlerFunc) {
+		h = vm.rateLimited(h)
+		mux.HandleFunc(APIPrefix+path, h)
+		if legacy != "" {
+			mux.HandleFunc(legacy, h)
+		}
+	}
+	for _, r := range routes {
+		if r.Admin {
+			handle(r.Path, r.Legacy, vm.audited(r.handler))
+			continue
+		}
+		if r.viewerHandler == nil {
+			handle(r.Path, r.Legacy, r.handler)
+			continue
+		}
+		handle(strings.TrimSuffix(r.Path, viewerParam), strings.TrimSuffix(r.Legacy, viewerParam),
+			vm.byViewer(r.viewerHandler))
+	}
+	mux.HandleFunc(APIPrefix+"/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimSuffix(r.URL.Path, "/") != APIPrefix {
+			http.NotFound(w, r)
+			return
+		}
+		index := make([]apiRoute, len(routes))
+		for i, rt := range routes {
+			index[i] = rt
+			index[i].Path = APIPrefix + rt.Path
+		}
+		writeData(w, r, index)
+	})
+	mux.HandleFunc(APIPrefix, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, APIPrefix+"/", http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,82 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestAPI(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	tests := []struct {
+		path   string
+		legacy string
+		status int
+	}{
+		{"/meta", "/debug/statsview/meta", http.StatusOK},
+		{"/metrics", "/debug/statsview/view/all", http.StatusOK},
+		{"/metrics/goroutine", "/debug/statsview/view/goroutine", http.StatusOK},
+		{"/metrics/heap", "/debug/statsview/view/heap", http.StatusNotFound},
+		{"/snapshot", "/debug/statsview/snapshot", http.StatusOK},
+		{"/status", "/debug/statsview/status", http.StatusOK},
+		{"/buildinfo", "/debug/statsview/buildinfo", http.StatusOK},
+		{"/goroutines", "/debug/statsview/goroutines/groups", http.StatusOK},
+		{"/overhead", "/debug/statsview/overhead", http.StatusOK},
+		{"/control/gctuning", "/debug/statsview/gc", http.StatusOK},
+		{"/openapi.json", "", http.StatusOK},
+		{"/metrics/{viewer}", "", http.StatusNotFound},
+		{"/unknown", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if rec := get(APIPrefix + tt.path); rec.Code != tt.status {
+				t.Errorf("%s status = %d, want %d", APIPrefix+tt.path, rec.Code, tt.status)
+			}
+			if tt.legacy == "" {
+				return
+			}
+			if rec := get(tt.legacy); rec.Code != tt.status {
+				t.Errorf("%s status = %d, want %d", tt.legacy, rec.Code, tt.status)
+			}
+		})
+	}
+
+	t.Run("index", func(t *testing.T) {
+		rec := get(APIPrefix + "/")
+		var index []apiRoute
+		if err := json.Unmarshal(rec.Body.Bytes(), &index); err != nil {
+			t.Fatal(err)
+		}
+		paths := make(map[string]string, len(index))
+		for _, r := range index {
+			paths[r.Path] = r.Legacy
+		}
+		if len(index) != len(mgr.apiRoutes()) {
+			t.Errorf("the index lists %d routes, want %d", len(index), len(mgr.apiRoutes()))
+		}
+		if legacy, ok := paths[APIPrefix+"/metrics/{viewer}"]; !ok || legacy != "/debug/statsview/view/{viewer}" {
+			t.Errorf("the index lists %v", paths)
+		}
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		rec := get(APIPrefix)
+		if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != APIPrefix+"/" {
+			t.Errorf("status = %d, location %q", rec.Code, rec.Header().Get("Location"))
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package statsview
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+const (
+	// maxAuditEntries bounds the entries kept by the audit log
+	maxAuditEntries = 1000
+	// maxAuditParams bounds the parameters recorded of an action
+	maxAuditParams = 512
+)
+
+// auditEntry is an action on an admin route, Principal is empty when the
+// request wasn't authenticated and Status tells whether it was refused
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	Remote    string    `json:"remote"`
+	Principal string    `json:"principal"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Params    string    `json:"params,omitempty"`
+	Status    int       `json:"status"`
+}
+
+// auditLog keeps the latest entries, the oldest first
+type auditLog struct {
+	mu      sync.Mutex
+	entries []auditEntry
+}
+
+func (l *auditLog) add(e auditEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+	if drop := len(l.entries) - maxAuditEntries; drop > 0 {
+		l.entries = append(l.entries[:0], l.entries[drop:]...)
+	}
+}
+
+func (l *auditLog) list() []auditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]auditEntry{}, l.entries...)
+}
+
+// audited records the requests of h changing the process, POST and PUT,
+// refused or not, in the audit log and the logger
+func (vm *ViewManager) audited(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			h(w, r)
+			return
+		}
+
+		// the JSON bodies, e.g. of the configuration, are read ahead so
+		// they're recorded too, only once the request is allowed so a
+		// refused one costs no read
+		var body []byte
+		ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if (ct == "application/json" || r.Method == http.MethodPut) && adminAllowed(r) {
+			body, _ = io.ReadAll(io.LimitReader(r.Body, 1<<20))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h(rec, r)
+
+		e := auditEntry{
+			Time:      vm.Smgr.Now(),
+			Remote:    r.RemoteAddr,
+			Principal: principalOf(r),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			Status:    rec.status,
+		}
+		switch {
+		case len(r.Form) > 0:
+			e.Params = r.Form.Encode()
+		case len(body) > 0:
+			var compact bytes.Buffer
+			if json.Compact(&compact, body) == nil {
+				body = compact.Bytes()
+			}
+			e.Params = string(body)
+		}
+		if len(e.Params) > maxAuditParams {
+			e.Params = e.Params[:maxAuditParams] + "…"
+		}
+
+		vm.audit.add(e)
+		viewer.Logger().Info("statsview: audit", "method", e.Method, "path", e.Path, "params", e.Params,
+			"status", e.Status, "principal", e.Principal, "remote", e.Remote)
+	}
+}
+
+// serveAudit lists the latest actions on the admin routes, the oldest first.
+// It requires the admin credentials once they're configured
+func (vm *ViewManager) serveAudit(w http.ResponseWriter, r *http.Request) {
+	if adminConfigured() && !authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="statsview"`)
+		http.Error(w, "statsview: invalid admin token", http.StatusUnauthorized)
+		return
+	}
+	writeData(w, r, vm.audit.list())
+}
@@ -0,0 +1,266 @@
+package statsview
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestAudited(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+	viewer.SetConfiguration(viewer.WithAdminToken("s3cret"))
+
+	long := strings.Repeat("x", maxAuditParams+10)
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		auth        func(r *http.Request)
+		want        *auditEntry
+	}{
+		{name: "read", method: http.MethodGet},
+		{
+			name: "form", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", body: "gogc=50",
+			auth: func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") },
+			want: &auditEntry{Principal: "admin-token", Method: http.MethodPost, Params: "gogc=50", Status: http.StatusOK},
+		},
+		{
+			name: "refused", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", body: "gogc=50",
+			auth: func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") },
+			want: &auditEntry{Method: http.MethodPost, Status: http.StatusUnauthorized},
+		},
+		{
+			name: "JSON compacted", method: http.MethodPut, contentType: "application/json", body: "{\n  \"interval\": 1000\n}",
+			auth: func(r *http.Request) { r.SetBasicAuth("ops", "s3cret") },
+			want: &auditEntry{Principal: "ops (admin-token)", Method: http.MethodPut, Params: `{"interval":1000}`, Status: http.StatusOK},
+		},
+		{
+			name: "params truncated", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", body: "note=" + long,
+			auth: func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") },
+			want: &auditEntry{Principal: "admin-token", Method: http.MethodPost, Params: ("note=" + long)[:maxAuditParams] + "…", Status: http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			var read string
+			h := mgr.audited(requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") == "application/json" {
+					b, _ := io.ReadAll(r.Body)
+					read = string(b)
+					return
+				}
+				r.ParseForm()
+			}))
+			r := httptest.NewRequest(tt.method, "/debug/statsview/config", strings.NewReader(tt.body))
+			r.Header.Set("X-Requested-With", "XMLHttpRequest")
+			r.RemoteAddr = "192.0.2.1:4321"
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.auth != nil {
+				tt.auth(r)
+			}
+			h(httptest.NewRecorder(), r)
+
+			entries := mgr.audit.list()
+			if tt.want == nil {
+				if len(entries) != 0 {
+					t.Errorf("audited %+v, want nothing", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("got %d audit entries, want 1", len(entries))
+			}
+			got := entries[0]
+			if got.Time.IsZero() {
+				t.Error("audit entry without time")
+			}
+			want := *tt.want
+			want.Time, want.Remote, want.Path = got.Time, "192.0.2.1:4321", "/debug/statsview/config"
+			if got != want {
+				t.Errorf("audited %+v, want %+v", got, want)
+			}
+			if tt.contentType == "application/json" && tt.want.Status == http.StatusOK && read != tt.body {
+				t.Errorf("handler read %q, want the whole body", read)
+			}
+		})
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	var l auditLog
+	for i := 0; i < maxAuditEntries+5; i++ {
+		l.add(auditEntry{Params: fmt.Sprint(i)})
+	}
+	entries := l.list()
+	if len(entries) != maxAuditEntries {
+		t.Fatalf("%d entries kept, want %d", len(entries), maxAuditEntries)
+	}
+	if first, last := entries[0].Params, entries[len(entries)-1].Params; first != "5" || last != fmt.Sprint(maxAuditEntries+4) {
+		t.Errorf("entries from %s to %s, want the latest", first, last)
+	}
+	entries[0].Params = ""
+	if l.list()[0].Params != "5" {
+		t.Error("the entries listed aren't a copy")
+	}
+}
+
+func TestServeAudit(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+
+	tests := []struct {
+		name   string
+		token  string
+		auth   string
+		status int
+	}{
+		{"no admin token", "", "", http.StatusOK},
+		{"without credentials", "s3cret", "", http.StatusUnauthorized},
+		{"invalid token", "s3cret", "Bearer nope", http.StatusUnauthorized},
+		{"admin", "s3cret", "Bearer s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithAdminToken(tt.token))
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			// a refused forced GC is recorded too
+			gc := httptest.NewRequest(http.MethodPost, "/debug/statsview/control/gc", nil)
+			mgr.srv.Handler.ServeHTTP(httptest.NewRecorder(), gc)
+
+			r := httptest.NewRequest(http.MethodGet, "/debug/statsview/audit", nil)
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var entries []auditEntry
+			if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || entries[0].Path != "/debug/statsview/control/gc" || entries[0].Status < 400 {
+				t.Errorf("audit log %+v, want the refused forced GC", entries)
+			}
+		})
+	}
+}
+
+func TestPrincipalOf(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""), viewer.WithAdminAuth(nil))
+	viewer.SetConfiguration(viewer.WithAdminToken("s3cret"), viewer.WithAdminAuth(func(r *http.Request) bool {
+		return r.Header.Get("X-Forwarded-User") == "alice"
+	}))
+
+	tests := []struct {
+		name string
+		set  func(r *http.Request)
+		want string
+	}{
+		{"anonymous", func(*http.Request) {}, ""},
+		{"bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, "admin-token"},
+		{"basic auth", func(r *http.Request) { r.SetBasicAuth("ops", "s3cret") }, "ops (admin-token)"},
+		{"invalid basic auth", func(r *http.Request) { r.SetBasicAuth("ops", "nope") }, ""},
+		{"admin auth", func(r *http.Request) { r.Header.Set("X-Forwarded-User", "alice") }, "admin-auth"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/debug/statsview/control/gc", nil)
+			tt.set(r)
+			if got := principalOf(r); got != tt.want {
+				t.Errorf("principalOf() = %q, want %q", got, tt.want)
+			}
+			if got := authorized(r); got != (tt.want != "") {
+				t.Errorf("authorized() = %v, want %v", got, tt.want != "")
+			}
+		})
+	}
+}
+
+type countingReader struct {
+	io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += n
+	return n, err
+}
+
+func TestAuditedReadsAllowedBodies(t *testing.T) {
+	defer viewer.RestoreConfiguration(viewer.SaveConfiguration())
+
+	tests := []struct {
+		name   string
+		token  string
+		auth   string
+		status int
+		read   bool
+	}{
+		{"no admin token", "", "Bearer s3cret", http.StatusForbidden, false},
+		{"invalid token", "s3cret", "Bearer nope", http.StatusUnauthorized, false},
+		{"cross origin", "s3cret", "", http.StatusForbidden, false},
+		{"allowed", "s3cret", "Bearer s3cret", http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithAdminToken(tt.token))
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			h := mgr.audited(requireAdmin(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+			}))
+			body := &countingReader{Reader: strings.NewReader(`{"interval": 1000}`)}
+			r := httptest.NewRequest(http.MethodPut, "/debug/statsview/config", body)
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Origin", "http://evil.example")
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			h(rec, r)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if read := body.n > 0; read != tt.read {
+				t.Errorf("body read = %v, want %v", read, tt.read)
+			}
+			entries := mgr.audit.list()
+			if len(entries) != 1 {
+				t.Fatalf("got %d audit entries, want 1", len(entries))
+			}
+			if recorded := entries[0].Params != ""; recorded != tt.read {
+				t.Errorf("params %q recorded = %v, want %v", entries[0].Params, recorded, tt.read)
+			}
+		})
+	}
+}
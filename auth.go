@@ -0,0 +1,233 @@
+package statsview
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// The routes are split in two modes: the read-only routes, the dashboard
+// and its data, are open unless viewer.WithReadToken, viewer.WithReadAuth
+// or viewer.WithTrustedHeaders is set, and the POSTs of the admin routes,
+// which change the process, are refused unless viewer.WithAdminToken,
+// viewer.WithAdminAuth or the admins of the trusted headers are set
+
+// publicPaths are served without authentication, the probes of
+// orchestrators and the static assets
+var publicPaths = []string{
+	"/debug/statsview/healthz",
+	"/debug/statsview/readyz",
+	"/debug/statsview/statics/",
+}
+
+// tokenOf returns the token of the request, sent as `Authorization: Bearer
+// <token>` or as the password of basic auth
+func tokenOf(r *http.Request) (string, bool) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token, true
+	}
+	_, password, ok := r.BasicAuth()
+	return password, ok
+}
+
+// matches reports whether the request carries the configured token
+func matches(r *http.Request, token string) bool {
+	got, ok := tokenOf(r)
+	if !ok || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// adminConfigured reports whether the admin routes could be authorized
+func adminConfigured() bool {
+	return viewer.AdminToken() != "" || viewer.AdminAuth() != nil || len(viewer.TrustedHeaderAuth().Admins) > 0
+}
+
+// readRestricted reports whether the read-only routes require authentication
+func readRestricted() bool {
+	return viewer.ReadToken() != "" || viewer.ReadAuth() != nil || viewer.TrustedHeaderAuth().User != ""
+}
+
+// headerUser returns the user and the groups set by a trusted proxy in the
+// headers of the request, see viewer.WithTrustedHeaders
+func headerUser(r *http.Request) (string, []string, bool) {
+	h := viewer.TrustedHeaderAuth()
+	if h.User == "" {
+		return "", nil, false
+	}
+	if addr, ok := clientAddr(r); !ok || !allowed(addr, viewer.TrustedProxies()) {
+		return "", nil, false
+	}
+	user := strings.TrimSpace(r.Header.Get(h.User))
+	if user == "" {
+		return "", nil, false
+	}
+	var groups []string
+	if h.Groups != "" {
+		for _, g := range strings.Split(r.Header.Get(h.Groups), ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				groups = append(groups, g)
+			}
+		}
+	}
+	return user, groups, true
+}
+
+// member reports whether the user or one of the groups is in names
+func member(user string, groups, names []string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, user) {
+			return true
+		}
+		for _, g := range groups {
+			if strings.EqualFold(name, g) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// principalOf returns who the request is authenticated as on the admin
+// routes: `admin-auth` when viewer.WithAdminAuth accepts it, `admin-token`
+// with the admin token, after the user of basic auth if any, or the admin set
+// by a trusted proxy. It's empty when the request isn't authenticated
+func principalOf(r *http.Request) string {
+	var mode string
+	switch {
+	case viewer.AdminAuth() != nil && viewer.AdminAuth()(r):
+		mode = "admin-auth"
+	case matches(r, viewer.AdminToken()):
+		mode = "admin-token"
+	default:
+		user, groups, ok := headerUser(r)
+		if ok && member(user, groups, viewer.TrustedHeaderAuth().Admins) {
+			return user + " (trusted-header)"
+		}
+		return ""
+	}
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return user + " (" + mode + ")"
+	}
+	return mode
+}
+
+// authorized reports whether the request is allowed to use the admin routes
+func authorized(r *http.Request) bool {
+	return principalOf(r) != ""
+}
+
+// readAuthorized reports whether the request is allowed to read, the admin
+// credentials are accepted too
+func readAuthorized(r *http.Request) bool {
+	if !readRestricted() || matches(r, viewer.ReadToken()) {
+		return true
+	}
+	if a := viewer.ReadAuth(); a != nil && a(r) {
+		return true
+	}
+	if user, groups, ok := headerUser(r); ok {
+		readers := viewer.TrustedHeaderAuth().Readers
+		if len(readers) == 0 || member(user, groups, readers) {
+			return true
+		}
+	}
+	return authorized(r)
+}
+
+func isPublic(path string) bool {
+	for _, p := range publicPaths {
+		if path == p || strings.HasSuffix(p, "/") && strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireRead guards every route but the public ones with the read-only
+// credentials, browsers are asked for them with basic auth
+func requireRead(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isPublic(r.URL.Path) && !readAuthorized(r) {
+			w.Header().Add("WWW-Authenticate", `Basic realm="statsview"`)
+			w.Header().Add("WWW-Authenticate", `Bearer realm="statsview"`)
+			http.Error(w, "statsview: invalid read token", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether the request can't have been forged by the page
+// of another site. Browsers attach the basic auth and the cookies of an SSO
+// proxy to the forms posted cross-site, so the request must carry a bearer
+// token, come from the same origin by its Sec-Fetch-Site or Origin headers,
+// or carry X-Requested-With, which no other site may send without a
+// preflight the admin routes refuse
+func sameOrigin(r *http.Request) bool {
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		return true
+	}
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return true
+	case "":
+	default:
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		return err == nil && u.Host == r.Host
+	}
+	return r.Header.Get("X-Requested-With") != ""
+}
+
+// requireAdmin guards the requests of h changing the process, POST and PUT,
+// with the admin credentials, reading stays in the read-only mode
+func requireAdmin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if !adminConfigured() {
+				http.Error(w, "statsview: no admin token configured, see viewer.WithAdminToken", http.StatusForbidden)
+				return
+			}
+			if !sameOrigin(r) {
+				http.Error(w, "statsview: cross-origin request refused", http.StatusForbidden)
+				return
+			}
+			if !authorized(r) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="statsview"`)
+				http.Error(w, "statsview: invalid admin token", http.StatusUnauthorized)
+				return
+			}
+		}
+		h(w, r)
+	}
+}
+
+// adminAllowed reports whether requireAdmin lets the request changing the
+// process through
+func adminAllowed(r *http.Request) bool {
+	return adminConfigured() && sameOrigin(r) && authorized(r)
+}
+
+// requireAdminRead guards every request of h, reading included, with the
+// admin credentials once they're configured, e.g. the captures of profiles
+// costing seconds of CPU or exposing the heap. Browsers are asked for them
+// with basic auth so the downloads linked by the pages work
+func requireAdminRead(h http.HandlerFunc) http.HandlerFunc {
+	guarded := requireAdmin(h)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if adminConfigured() && !authorized(r) {
+			w.Header().Add("WWW-Authenticate", `Basic realm="statsview admin"`)
+			w.Header().Add("WWW-Authenticate", `Bearer realm="statsview"`)
+			http.Error(w, "statsview: invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		guarded(w, r)
+	}
+}
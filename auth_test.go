@@ -0,0 +1,290 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestAuthModes(t *testing.T) {
+	defer viewer.SetConfiguration(
+		viewer.WithReadToken(""), viewer.WithReadAuth(nil),
+		viewer.WithAdminToken(""), viewer.WithAdminAuth(nil),
+	)
+	fromProxy := func(r *http.Request) bool { return r.Header.Get("X-User") != "" }
+
+	tests := []struct {
+		name   string
+		config []viewer.Option
+		method string
+		path   string
+		header map[string]string
+		basic  string
+		status int
+	}{
+		{
+			name:   "open read",
+			method: http.MethodGet, path: "/debug/statsview/api/v1/status",
+			status: http.StatusOK,
+		},
+		{
+			name:   "read without the read token",
+			config: []viewer.Option{viewer.WithReadToken("reader")},
+			method: http.MethodGet, path: "/debug/statsview/api/v1/status",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "read with the read token",
+			config: []viewer.Option{viewer.WithReadToken("reader")},
+			method: http.MethodGet, path: "/debug/statsview/api/v1/status",
+			header: map[string]string{"Authorization": "Bearer reader"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "read with basic auth",
+			config: []viewer.Option{viewer.WithReadToken("reader")},
+			method: http.MethodGet, path: "/debug/statsview",
+			basic:  "reader",
+			status: http.StatusOK,
+		},
+		{
+			name:   "read with the admin token",
+			config: []viewer.Option{viewer.WithReadToken("reader"), viewer.WithAdminToken("admin")},
+			method: http.MethodGet, path: "/debug/statsview/api/v1/status",
+			header: map[string]string{"Authorization": "Bearer admin"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "read with a wrong token",
+			config: []viewer.Option{viewer.WithReadToken("reader")},
+			method: http.MethodGet, path: "/debug/statsview/api/v1/status",
+			header: map[string]string{"Authorization": "Bearer admin"},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "read authenticator",
+			config: []viewer.Option{viewer.WithReadAuth(fromProxy)},
+			method: http.MethodGet, path: "/debug/statsview/api/v1/status",
+			header: map[string]string{"X-User": "alice"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "read authenticator refusing",
+			config: []viewer.Option{viewer.WithReadAuth(fromProxy)},
+			method: http.MethodGet, path: "/debug/statsview/api/v1/status",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "public probe",
+			config: []viewer.Option{viewer.WithReadToken("reader")},
+			method: http.MethodGet, path: "/debug/statsview/readyz",
+			// served, not ready until the manager is started
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			name:   "public assets",
+			config: []viewer.Option{viewer.WithReadToken("reader")},
+			method: http.MethodGet, path: "/debug/statsview/statics/jquery.min.js",
+			status: http.StatusOK,
+		},
+		{
+			name:   "admin route without admin configured",
+			method: http.MethodPost, path: "/debug/statsview/api/v1/profile/block",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "admin route with the read token",
+			config: []viewer.Option{viewer.WithReadToken("reader"), viewer.WithAdminToken("admin")},
+			method: http.MethodPost, path: "/debug/statsview/api/v1/profile/block",
+			header: map[string]string{"Authorization": "Bearer reader"},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "read of an admin route",
+			config: []viewer.Option{viewer.WithAdminToken("admin")},
+			method: http.MethodGet, path: "/debug/statsview/api/v1/profile/block",
+			status: http.StatusOK,
+		},
+		{
+			name:   "admin authenticator",
+			config: []viewer.Option{viewer.WithAdminAuth(fromProxy)},
+			method: http.MethodPost, path: "/debug/statsview/api/v1/profile/block",
+			header: map[string]string{"X-User": "alice"},
+			status: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(append([]viewer.Option{
+				viewer.WithReadToken(""), viewer.WithReadAuth(nil),
+				viewer.WithAdminToken(""), viewer.WithAdminAuth(nil),
+			}, tt.config...)...)
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader("rate=0"))
+			r.Header.Set("X-Requested-With", "XMLHttpRequest")
+			if tt.method == http.MethodPost {
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			if tt.basic != "" {
+				r.SetBasicAuth("statsview", tt.basic)
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if rec.Code == http.StatusUnauthorized && tt.method == http.MethodGet && len(rec.Header().Values("WWW-Authenticate")) != 2 {
+				t.Errorf("WWW-Authenticate = %v", rec.Header().Values("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireAdminSameOrigin(t *testing.T) {
+	viewer.SetConfiguration(viewer.WithAdminToken("secret"))
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		basic   bool
+		want    int
+	}{
+		{"bearer token", map[string]string{"Authorization": "Bearer secret"}, false, http.StatusOK},
+		{"basic auth without origin", nil, true, http.StatusForbidden},
+		{"basic auth cross-site form", map[string]string{"Origin": "https://evil.example", "Sec-Fetch-Site": "cross-site"}, true, http.StatusForbidden},
+		{"basic auth other origin", map[string]string{"Origin": "https://evil.example"}, true, http.StatusForbidden},
+		{"basic auth null origin", map[string]string{"Origin": "null"}, true, http.StatusForbidden},
+		{"basic auth same origin", map[string]string{"Origin": "http://example.com"}, true, http.StatusOK},
+		{"basic auth same-origin fetch", map[string]string{"Sec-Fetch-Site": "same-origin"}, true, http.StatusOK},
+		{"basic auth XMLHttpRequest", map[string]string{"X-Requested-With": "XMLHttpRequest"}, true, http.StatusOK},
+		{"same origin without credentials", map[string]string{"Origin": "http://example.com"}, false, http.StatusUnauthorized},
+	}
+	h := requireAdmin(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "http://example.com/debug/statsview/control/gc", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			if tt.basic {
+				r.SetBasicAuth("alice", "secret")
+			}
+			rec := httptest.NewRecorder()
+			h(rec, r)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrossOriginAdminPreflight(t *testing.T) {
+	vm := &ViewManager{}
+	h := vm.crossOrigin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		path, method string
+		allowed      bool
+	}{
+		{"/debug/statsview/control/gc", http.MethodPost, false},
+		{"/debug/statsview/config", http.MethodPut, false},
+		{"/debug/statsview/config", http.MethodGet, true},
+		{"/debug/statsview/lease", http.MethodPost, true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodOptions, "http://example.com"+tt.path, nil)
+		r.Header.Set("Origin", "https://other.example")
+		r.Header.Set("Access-Control-Request-Method", tt.method)
+		r.Header.Set("Access-Control-Request-Headers", "X-Requested-With")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		if got := rec.Header().Get("Access-Control-Allow-Methods") != ""; got != tt.allowed {
+			t.Errorf("%s %s: preflight allowed = %v, want %v", tt.method, tt.path, got, tt.allowed)
+		}
+	}
+}
+
+func TestRequireAdminRead(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		bearer string
+		want   int
+	}{
+		{"open without admin", "", http.MethodGet, "", http.StatusOK},
+		{"read without token", "secret", http.MethodGet, "", http.StatusUnauthorized},
+		{"read with token", "secret", http.MethodGet, "secret", http.StatusOK},
+		{"read with wrong token", "secret", http.MethodGet, "other", http.StatusUnauthorized},
+	}
+	h := requireAdminRead(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithAdminToken(tt.token))
+			defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+
+			r := httptest.NewRequest(tt.method, "/debug/statsview/profile/wallclock", nil)
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			rec := httptest.NewRecorder()
+			h(rec, r)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminReadRoutes(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithReadToken(""), viewer.WithAdminToken(""))
+	viewer.SetConfiguration(viewer.WithReadToken("reader"), viewer.WithAdminToken("admin"))
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		path   string
+		token  string
+		status int
+	}{
+		{"/debug/pprof/", "reader", http.StatusUnauthorized},
+		{"/debug/pprof/", "admin", http.StatusOK},
+		{"/debug/pprof/goroutine?debug=1", "reader", http.StatusUnauthorized},
+		{"/debug/pprof/cmdline", "reader", http.StatusUnauthorized},
+		{"/debug/pprof/cmdline", "admin", http.StatusOK},
+		{"/debug/statsview/trace/flight", "reader", http.StatusUnauthorized},
+		{"/debug/statsview/api/v1/trace/flight", "reader", http.StatusUnauthorized},
+		{"/debug/statsview/session", "reader", http.StatusUnauthorized},
+		{"/debug/statsview/api/v1/session", "reader", http.StatusUnauthorized},
+		// the history is disabled
+		{"/debug/statsview/session", "admin", http.StatusNotFound},
+		{"/debug/statsview/api/v1/status", "reader", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.token, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
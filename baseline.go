@@ -0,0 +1,134 @@
+package statsview
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// defaultBaselineWindow is the history recorded as the baseline by default
+const defaultBaselineWindow = 5 * time.Minute
+
+// baselineBand is the normal range of a series, its mean within two
+// standard deviations, in the unit of its chart
+type baselineBand struct {
+	Viewer string  `json:"viewer"`
+	Series string  `json:"series"`
+	Mean   float64 `json:"mean"`
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+}
+
+// baseline is the normal behavior recorded within [From, To]
+type baseline struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	Bands []baselineBand `json:"bands"`
+}
+
+// baselineStore holds the recorded baseline, the summaries are in base units
+type baselineStore struct {
+	mu       sync.Mutex
+	from, to time.Time
+	series   []seriesSummary
+}
+
+// errNoBaselineHistory is returned by RecordBaseline without history
+var errNoBaselineHistory = errors.New("statsview: nothing recorded in the window, see viewer.WithHistory")
+
+// RecordBaseline records the history of the last window as the normal
+// behavior, e.g. before a canary takes traffic. The dashboard draws the
+// band of every series behind it so deviations stand out
+func (vm *ViewManager) RecordBaseline(window time.Duration) error {
+	if vm.historyWindow() <= 0 {
+		return errHistoryDisabled
+	}
+	to := vm.Smgr.Now()
+	from := to.Add(-window)
+	var series []seriesSummary
+	for _, k := range vm.history.keys() {
+		if s, ok := summarize(k, vm.history.query(k, from, to)); ok {
+			series = append(series, s)
+		}
+	}
+	if len(series) == 0 {
+		return errNoBaselineHistory
+	}
+
+	vm.baseline.mu.Lock()
+	defer vm.baseline.mu.Unlock()
+	vm.baseline.from, vm.baseline.to, vm.baseline.series = from, to, series
+	return nil
+}
+
+// ClearBaseline forgets the recorded baseline
+func (vm *ViewManager) ClearBaseline() {
+	vm.baseline.mu.Lock()
+	defer vm.baseline.mu.Unlock()
+	vm.baseline.series = nil
+}
+
+// serveBaseline returns the bands of the baseline in the units of the charts,
+// 404 Not Found without baseline. A POST records the history of the `last`
+// duration, 5m by default, or forgets the baseline with `clear=1`
+func (vm *ViewManager) serveBaseline(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if r.FormValue("clear") == "1" {
+			vm.ClearBaseline()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		window := defaultBaselineWindow
+		if s := r.FormValue("last"); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				http.Error(w, "statsview: invalid last duration", http.StatusBadRequest)
+				return
+			}
+			window = d
+		}
+		switch err := vm.RecordBaseline(window); err {
+		case nil:
+		case errHistoryDisabled:
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		default:
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+	}
+
+	vm.baseline.mu.Lock()
+	from, to, series := vm.baseline.from, vm.baseline.to, vm.baseline.series
+	vm.baseline.mu.Unlock()
+	if len(series) == 0 {
+		http.Error(w, "statsview: no baseline recorded", http.StatusNotFound)
+		return
+	}
+
+	// the bands are converted to the units the charts are served in
+	units := make(map[seriesKey]viewer.Unit)
+	for _, v := range vm.views() {
+		for _, s := range viewer.MetaOf(v).Series {
+			units[seriesKey{Viewer: v.Name(), Series: s.Name}] = s.Unit
+		}
+	}
+	res := baseline{From: from, To: to, Bands: make([]baselineBand, 0, len(series))}
+	for _, s := range series {
+		unit := units[seriesKey{Viewer: s.Viewer, Series: s.Series}]
+		low := math.Max(s.Mean-2*s.StdDev, s.Min)
+		high := math.Min(s.Mean+2*s.StdDev, s.Max)
+		res.Bands = append(res.Bands, baselineBand{
+			Viewer: s.Viewer,
+			Series: s.Series,
+			Mean:   unit.Convert(s.Mean),
+			Low:    unit.Convert(low),
+			High:   unit.Convert(high),
+		})
+	}
+	writeData(w, r, res)
+}
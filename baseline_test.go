@@ -0,0 +1,137 @@
+package statsview
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestRecordBaseline(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	if err := mgr.RecordBaseline(time.Minute); !errors.Is(err, errHistoryDisabled) {
+		t.Errorf("RecordBaseline() without history = %v, want %v", err, errHistoryDisabled)
+	}
+
+	defer viewer.SetConfiguration(viewer.WithHistory(0))
+	viewer.SetConfiguration(viewer.WithHistory(time.Hour))
+
+	tests := []struct {
+		name    string
+		window  time.Duration
+		want    error
+		wantMax float64
+	}{
+		{name: "nothing in the window", window: time.Second, want: errNoBaselineHistory},
+		{name: "recent window", window: 5 * time.Minute, wantMax: 30},
+		{name: "whole history", window: time.Hour, wantMax: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := recordedBaseline(t)
+			if err := mgr.RecordBaseline(tt.window); !errors.Is(err, tt.want) {
+				t.Fatalf("RecordBaseline() = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			if len(mgr.baseline.series) != 1 || mgr.baseline.series[0].Max != tt.wantMax {
+				t.Errorf("baseline %+v, want the max %v", mgr.baseline.series, tt.wantMax)
+			}
+			mgr.ClearBaseline()
+			if mgr.baseline.series != nil {
+				t.Error("baseline kept after ClearBaseline")
+			}
+		})
+	}
+}
+
+// recordedBaseline returns a manager whose goroutines were 1000 ten minutes
+// ago, then 10, 20 and 30 the last minutes
+func recordedBaseline(t *testing.T) *ViewManager {
+	t.Helper()
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mgr.Stop)
+	now := mgr.Smgr.Now()
+	for _, p := range []struct {
+		ago   time.Duration
+		value float64
+	}{{10 * time.Minute, 1000}, {3 * time.Minute, 10}, {2 * time.Minute, 20}, {time.Minute, 30}} {
+		mgr.history.record([]viewer.Point{{Viewer: viewer.VGoroutine, Series: "Goroutines", Value: p.value, Time: now.Add(-p.ago)}})
+	}
+	return mgr
+}
+
+func TestServeBaseline(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithHistory(0), viewer.WithAdminToken(""))
+	viewer.SetConfiguration(viewer.WithHistory(time.Hour), viewer.WithAdminToken("s3cret"))
+	mgr := recordedBaseline(t)
+
+	serve := func(method string, form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/debug/statsview/baseline", strings.NewReader(form.Encode()))
+		if method == http.MethodPost {
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.Header.Set("Authorization", "Bearer s3cret")
+		}
+		rec := httptest.NewRecorder()
+		mgr.srv.Handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	// the steps run in order, the baseline recorded is served afterwards
+	tests := []struct {
+		name   string
+		method string
+		form   url.Values
+		status int
+		want   *baselineBand
+	}{
+		{name: "nothing recorded", method: http.MethodGet, status: http.StatusNotFound},
+		{name: "invalid last", method: http.MethodPost, form: url.Values{"last": {"soon"}}, status: http.StatusBadRequest},
+		{name: "nothing in the window", method: http.MethodPost, form: url.Values{"last": {"1s"}}, status: http.StatusConflict},
+		{
+			name: "recorded", method: http.MethodPost, status: http.StatusOK,
+			want: &baselineBand{Viewer: viewer.VGoroutine, Series: "Goroutines", Mean: 20, Low: 10, High: 30},
+		},
+		{
+			name: "served", method: http.MethodGet, status: http.StatusOK,
+			want: &baselineBand{Viewer: viewer.VGoroutine, Series: "Goroutines", Mean: 20, Low: 10, High: 30},
+		},
+		{name: "cleared", method: http.MethodPost, form: url.Values{"clear": {"1"}}, status: http.StatusNoContent},
+		{name: "forgotten", method: http.MethodGet, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.method, tt.form)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.want == nil {
+				return
+			}
+			var b baseline
+			if err := json.Unmarshal(rec.Body.Bytes(), &b); err != nil {
+				t.Fatal(err)
+			}
+			if len(b.Bands) != 1 || b.Bands[0] != *tt.want {
+				t.Errorf("bands %+v, want %+v", b.Bands, *tt.want)
+			}
+			if got := b.To.Sub(b.From); got != defaultBaselineWindow {
+				t.Errorf("baseline of %v, want %v", got, defaultBaselineWindow)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package statsview
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// processStart approximates the start of the process with the initialization
+// of the package
+var processStart = time.Now()
+
+// buildInfo is the build and environment of the process shown on the dashboard
+type buildInfo struct {
+	Path        string    `json:"path,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	Revision    string    `json:"revision,omitempty"`
+	RevisionAt  string    `json:"revisionTime,omitempty"`
+	Modified    bool      `json:"modified,omitempty"`
+	GoVersion   string    `json:"goVersion"`
+	GOOS        string    `json:"goos"`
+	GOARCH      string    `json:"goarch"`
+	GOMAXPROCS  int       `json:"gomaxprocs"`
+	NumCPU      int       `json:"numcpu"`
+	GOGC        string    `json:"gogc"`
+	GOMEMLIMIT  string    `json:"gomemlimit"`
+	Start       time.Time `json:"start"`
+	StartFormat string    `json:"startFormatted"`
+	Uptime      string    `json:"uptime"`
+}
+
+func readBuildInfo() buildInfo {
+	gc := readGCSettings()
+	info := buildInfo{
+		GoVersion:   runtime.Version(),
+		GOOS:        runtime.GOOS,
+		GOARCH:      runtime.GOARCH,
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		NumCPU:      runtime.NumCPU(),
+		GOGC:        formatGOGC(gc.GOGC),
+		GOMEMLIMIT:  formatBytes(gc.GOMEMLIMIT),
+		Start:       processStart,
+		StartFormat: processStart.In(viewer.TimeLocation()).Format(time.DateTime),
+		Uptime:      time.Since(processStart).Truncate(time.Second).String(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Path, info.Version = bi.Main.Path, bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.RevisionAt = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// serveBuildInfo returns the module version, the VCS revision and the
+// runtime environment of the process
+func (vm *ViewManager) serveBuildInfo(w http.ResponseWriter, r *http.Request) {
+	writeData(w, r, readBuildInfo())
+}
@@ -0,0 +1,59 @@
+package statsview
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestServeBuildInfo(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(math.MaxInt64))
+
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		name       string
+		gogc       int
+		gomemlimit int64
+		wantGOGC   string
+		wantLimit  string
+	}{
+		{"defaults", 100, math.MaxInt64, "100", "off"},
+		{"tuned", 50, 512 << 20, "50", formatBytes(512 << 20)},
+		{"gc off", -1, 1 << 30, "off", formatBytes(1 << 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			debug.SetGCPercent(tt.gogc)
+			debug.SetMemoryLimit(tt.gomemlimit)
+
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/buildinfo", nil))
+			var info buildInfo
+			if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+				t.Fatal(err)
+			}
+			if info.GOGC != tt.wantGOGC || info.GOMEMLIMIT != tt.wantLimit {
+				t.Errorf("GOGC %s GOMEMLIMIT %s, want %s and %s", info.GOGC, info.GOMEMLIMIT, tt.wantGOGC, tt.wantLimit)
+			}
+			if info.GoVersion != runtime.Version() || info.GOOS != runtime.GOOS || info.GOARCH != runtime.GOARCH ||
+				info.GOMAXPROCS != runtime.GOMAXPROCS(0) || info.NumCPU != runtime.NumCPU() {
+				t.Errorf("environment = %+v", info)
+			}
+			if !info.Start.Equal(processStart) || info.Uptime == "" {
+				t.Errorf("start %v uptime %q, want %v", info.Start, info.Uptime, processStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Command statsview-hub renders a single dashboard for many statsview instances.
+//
+//	statsview-hub -addr :18070 -target api-1=http://10.0.0.1:18066 -target api-2=http://10.0.0.2:18066
+//
+// The pulls of a target send the bearer token of -target-token name=token
+// and the headers of -target-header "name=Key: Value", e.g. its read token.
+// Targets which could not be reached push their metrics via hub.Pusher with
+// the token of -push-token, read from STATSVIEW_HUB_PUSH_TOKEN by default.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mortum5/statsview/hub"
+)
+
+type targets map[string]string
+
+func (t targets) String() string {
+	return ""
+}
+
+func (t targets) Set(v string) error {
+	name, url, ok := strings.Cut(v, "=")
+	if !ok {
+		name, url = v, v
+	}
+	t[name] = url
+	return nil
+}
+
+// targetHeaders are the headers sent to the pulled targets
+type targetHeaders map[string]http.Header
+
+func (t targetHeaders) String() string {
+	return ""
+}
+
+func (t targetHeaders) Set(v string) error {
+	name, header, _ := strings.Cut(v, "=")
+	key, value, ok := strings.Cut(header, ":")
+	if name == "" || !ok {
+		return fmt.Errorf("want name=Key: Value, got %q", v)
+	}
+	if t[name] == nil {
+		t[name] = make(http.Header)
+	}
+	t[name].Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	return nil
+}
+
+func main() {
+	ts, tokens, headers := targets{}, targets{}, targetHeaders{}
+	addr := flag.String("addr", "localhost:18070", "listening address")
+	interval := flag.Duration("interval", hub.DefaultInterval, "pulling interval")
+	maxPoints := flag.Int("max-points", hub.DefaultMaxPoints, "points kept per target")
+	pushToken := flag.String("push-token", os.Getenv("STATSVIEW_HUB_PUSH_TOKEN"), "bearer token of the pushing targets, pushes are refused without")
+	maxTargets := flag.Int("max-targets", hub.DefaultMaxTargets, "pushing targets kept at most")
+	targetTTL := flag.Duration("target-ttl", hub.DefaultTargetTTL, "how long a pushing target is kept without a push")
+	flag.Var(ts, "target", "pulled target as name=url, repeatable")
+	flag.Var(tokens, "target-token", "bearer token sent to a pulled target as name=token, repeatable")
+	flag.Var(headers, "target-header", "header sent to a pulled target as \"name=Key: Value\", repeatable")
+	flag.Parse()
+
+	h := hub.New(hub.Config{
+		Targets:       ts,
+		Interval:      *interval,
+		MaxPoints:     *maxPoints,
+		TargetTokens:  tokens,
+		TargetHeaders: headers,
+		PushToken:     *pushToken,
+		MaxTargets:    *maxTargets,
+		TargetTTL:     *targetTTL,
+	})
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go h.Run(ctx)
+
+	srv := &http.Server{Addr: *addr, Handler: h.Handler()}
+	go func() {
+		<-ctx.Done()
+		sctx, scancel := context.WithTimeout(context.Background(), time.Second)
+		defer scancel()
+		srv.Shutdown(sctx)
+	}()
+
+	log.Printf("statsview-hub listening on http://%s", *addr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// runDemo serves the dashboard fed by synthetic metrics
+//
+//	statsview demo -interval 500ms
+func runDemo(args []string) {
+	fs := flag.NewFlagSet("demo", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:18066", "listening address of the dashboard")
+	interval := fs.Duration("interval", 2*time.Second, "collecting interval")
+	open := fs.Bool("open", false, "open the dashboard in the browser")
+	fs.Parse(args)
+
+	opts := []viewer.Option{
+		viewer.WithAddr(*addr),
+		viewer.WithInterval(int(*interval / time.Millisecond)),
+	}
+	if *open {
+		opts = append(opts, viewer.WithBrowserOpen())
+	}
+	if err := viewer.SetConfiguration(opts...); err != nil {
+		log.Fatal(err)
+	}
+
+	mgr, err := statsview.NewDemo()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		mgr.Stop()
+	}()
+
+	log.Printf("statsview demo listening on http://%s/debug/statsview", *addr)
+	if err := mgr.Start(); err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
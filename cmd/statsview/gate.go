@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/mortum5/statsview/gate"
+)
+
+type rules []gate.Rule
+
+func (r *rules) String() string {
+	return ""
+}
+
+func (r *rules) Set(v string) error {
+	rule, err := gate.ParseRule(v)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, rule)
+	return nil
+}
+
+// runGate compares two runs and exits with 1 when a metric regressed beyond
+// its threshold, 2 when the runs couldn't be compared
+//
+//	statsview gate -rule heap.Alloc:max:+10% main.session branch.session
+func runGate(args []string) {
+	var rs rules
+	fs := flag.NewFlagSet("gate", flag.ExitOnError)
+	fs.Var(&rs, "rule", "threshold as target:stat:limit, e.g. heap.Alloc:max:+10%, repeatable")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: statsview gate -rule target:stat:limit... base head")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 || len(rs) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	base, err := gate.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	head, err := gate.Load(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	results, err := gate.Check(base, head, rs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	regressed := false
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RULE\tBASE\tHEAD\tCHANGE\tRESULT")
+	for _, r := range results {
+		change := strconv.FormatFloat(r.Change, 'g', 4, 64)
+		if r.Rule.Relative {
+			change = strconv.FormatFloat(r.Change*100, 'f', 2, 64) + "%"
+		}
+		result := "ok"
+		if r.Regressed {
+			result, regressed = "REGRESSED", true
+		}
+		fmt.Fprintf(tw, "%s\t%g\t%g\t%s\t%s\n", r.Rule, r.Base, r.Head, change, result)
+	}
+	tw.Flush()
+	if regressed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,160 @@
+// Command statsview serves the dashboard locally while scraping remote
+// targets, for services which couldn't embed statsview but could be
+// port-forwarded to.
+//
+//	statsview -target api=http://localhost:6060/debug/vars -target worker=http://localhost:9090/metrics
+//
+// The format of a target is guessed from its path: expvar (`/debug/vars`),
+// another statsview (`/debug/statsview/snapshot`) or Prometheus otherwise.
+//
+// Recorded pprof profiles are charted side by side into an HTML page with
+//
+//	statsview profiles -o heap.html heap-1.pb.gz heap-2.pb.gz heap-3.pb.gz
+//
+// The dashboard is served with synthetic metrics, e.g. to work on its
+// templates, with
+//
+//	statsview demo
+//
+// A session file exported by a dashboard is replayed with
+//
+//	statsview replay prod.session
+//
+// and compared to another one, overlaid as dashed series, with
+//
+//	statsview replay -compare after.session before.session
+//
+// Two runs, session or summary files, gate a CI pipeline with
+//
+//	statsview gate -rule heap.Alloc:max:+10% main.session branch.session
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+type target struct {
+	name string
+	url  string
+}
+
+type targets []target
+
+func (t *targets) String() string {
+	return ""
+}
+
+func (t *targets) Set(v string) error {
+	name, rawURL, ok := strings.Cut(v, "=")
+	if !ok {
+		rawURL = v
+		u, err := url.Parse(v)
+		if err != nil {
+			return err
+		}
+		name = u.Hostname()
+	}
+	*t = append(*t, target{name: name, url: rawURL})
+	return nil
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "profiles" {
+		runProfiles(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "demo" {
+		runDemo(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		runReplay(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "gate" {
+		runGate(os.Args[2:])
+		return
+	}
+
+	var ts targets
+	addr := flag.String("addr", "localhost:18066", "listening address of the dashboard")
+	interval := flag.Duration("interval", 2*time.Second, "scraping interval")
+	only := flag.String("viewers", "", "charted viewers, comma separated, e.g. heap,goroutine (default all)")
+	open := flag.Bool("open", false, "open the dashboard in the browser")
+	flag.Var(&ts, "target", "scraped target as name=url, repeatable")
+	flag.Parse()
+
+	if len(ts) == 0 {
+		fmt.Fprintln(os.Stderr, "statsview: at least one -target is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := []viewer.Option{
+		viewer.WithAddr(*addr),
+		viewer.WithInterval(int(*interval / time.Millisecond)),
+	}
+	if *open {
+		opts = append(opts, viewer.WithBrowserOpen())
+	}
+	if err := viewer.SetConfiguration(opts...); err != nil {
+		log.Fatal(err)
+	}
+
+	viewers := statsview.NewEmptyViewers()
+	for _, t := range ts {
+		vs, err := statsview.AttachRemote(t.name, t.url)
+		if err != nil {
+			log.Fatalf("statsview: failed to attach %s: %v", t.name, err)
+		}
+		viewers.Register(filter(t.name, vs, *only)...)
+	}
+
+	mgr, err := statsview.New(viewers)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		mgr.Stop()
+	}()
+
+	log.Printf("statsview listening on http://%s/debug/statsview", *addr)
+	if err := mgr.Start(); err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
+
+// filter keeps the viewers named in the comma separated list, without the target prefix
+func filter(prefix string, vs statsview.Viewers, only string) statsview.Viewers {
+	if only == "" {
+		return vs
+	}
+
+	kept := statsview.NewEmptyViewers()
+	for _, v := range vs {
+		name := strings.TrimPrefix(v.Name(), prefix+"-")
+		for _, o := range strings.Split(only, ",") {
+			if strings.TrimSpace(o) == name {
+				kept.Register(v)
+				break
+			}
+		}
+	}
+	return kept
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestTargetsSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    target
+		wantErr bool
+	}{
+		{"api=http://localhost:6060/debug/vars", target{"api", "http://localhost:6060/debug/vars"}, false},
+		{"http://worker:9090/metrics", target{"worker", "http://worker:9090/metrics"}, false},
+		{"http://[::1]:9090/metrics", target{"::1", "http://[::1]:9090/metrics"}, false},
+		{"http://%zz/metrics", target{}, true},
+	}
+	for _, tt := range tests {
+		var ts targets
+		err := ts.Set(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q) = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (len(ts) != 1 || ts[0] != tt.want) {
+			t.Errorf("Set(%q) = %+v, want %+v", tt.value, ts, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	vs := statsview.Viewers{
+		viewer.NewRemoteViewer("api-heap", "Heap", "", viewer.FormatExpvar, viewer.UnitMiB),
+		viewer.NewRemoteViewer("api-gcnum", "GC Number", "", viewer.FormatExpvar, viewer.UnitNone),
+		viewer.NewRemoteViewer("api-goroutine", "Goroutines", "", viewer.FormatExpvar, viewer.UnitNone),
+	}
+
+	tests := []struct {
+		name string
+		only string
+		want []string
+	}{
+		{"all", "", []string{"api-heap", "api-gcnum", "api-goroutine"}},
+		{"selected", "goroutine, heap", []string{"api-heap", "api-goroutine"}},
+		{"prefixed names don't match", "api-heap", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range filter("api", vs, tt.only) {
+				got = append(got, v.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
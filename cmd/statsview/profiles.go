@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/mortum5/statsview/profiles"
+)
+
+// runProfiles charts recorded profiles into a standalone HTML page
+//
+//	statsview profiles -o heap.html heap-*.pb.gz
+func runProfiles(args []string) {
+	fs := flag.NewFlagSet("profiles", flag.ExitOnError)
+	out := fs.String("o", "", "output HTML file (default stdout)")
+	top := fs.Int("top", 10, "charted top functions")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: statsview profiles [-o file] [-top n] profile...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	summaries, err := profiles.LoadAll(fs.Args()...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := profiles.Render(w, summaries, *top); err != nil {
+		log.Fatal(err)
+	}
+}
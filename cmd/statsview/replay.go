@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// runReplay serves the dashboard replaying a session file, optionally with
+// another one overlaid
+//
+//	statsview replay -compare after.session before.session
+func runReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:18066", "listening address of the dashboard")
+	interval := fs.Duration("interval", 2*time.Second, "replaying interval, the one of the recording")
+	open := fs.Bool("open", false, "open the dashboard in the browser")
+	compare := fs.String("compare", "", "session file overlaid as dashed series")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "statsview: replay takes one session file")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts := []viewer.Option{
+		viewer.WithAddr(*addr),
+		viewer.WithInterval(int(*interval / time.Millisecond)),
+	}
+	if *open {
+		opts = append(opts, viewer.WithBrowserOpen())
+	}
+	if err := viewer.SetConfiguration(opts...); err != nil {
+		log.Fatal(err)
+	}
+
+	mgr, err := statsview.Compare(fs.Arg(0), *compare)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		mgr.Stop()
+	}()
+
+	log.Printf("statsview replaying %s on http://%s/debug/statsview", fs.Arg(0), *addr)
+	if err := mgr.Start(); err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
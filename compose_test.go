@@ -0,0 +1,64 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestComposedViewers(t *testing.T) {
+	tests := []struct {
+		name    string
+		viewers Viewers
+		err     string
+	}{
+		{
+			name:    "registered viewers",
+			viewers: Viewers{viewer.NewGoroutinesViewer(), viewer.NewHeapViewer(), viewer.Compose("concurrency", viewer.Ref(viewer.VGoroutine, "Goroutines"), viewer.Ref(viewer.VHeap, "Alloc"))},
+		},
+		{
+			name:    "registered after the composed viewer",
+			viewers: Viewers{viewer.Compose("concurrency", viewer.Ref(viewer.VGoroutine, "Goroutines")), viewer.NewGoroutinesViewer()},
+		},
+		{
+			name:    "unknown viewer",
+			viewers: Viewers{viewer.NewGoroutinesViewer(), viewer.Compose("concurrency", viewer.Ref(viewer.VHeap, "Alloc"))},
+			err:     "references the unknown viewer heap",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := New(tt.viewers)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("New() = %v, want %q", err, tt.err)
+				}
+				if err == nil {
+					mgr.Stop()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/view/concurrency", nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			var m viewer.Metrics
+			if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if m.Values[0] < 1 {
+				t.Errorf("served %v goroutines", m.Values[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package statsview
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+// forceGC runs a garbage collection, it must be an authenticated POST
+func (vm *ViewManager) forceGC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "statsview: force GC requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	runtime.GC()
+	vm.Annotate("Forced GC")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// freeOSMemory forces a GC and returns as much memory to the OS as possible,
+// it must be an authenticated POST
+func (vm *ViewManager) freeOSMemory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "statsview: free OS memory requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	debug.FreeOSMemory()
+	vm.Annotate("FreeOSMemory")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// procsState is GOMAXPROCS and the number of CPUs usable by the process
+type procsState struct {
+	Procs  int `json:"procs"`
+	NumCPU int `json:"numcpu"`
+}
+
+// gomaxprocs reports GOMAXPROCS, an authenticated POST with `procs` changes it
+func (vm *ViewManager) gomaxprocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		procs, err := strconv.Atoi(r.FormValue("procs"))
+		if err != nil || procs < 1 {
+			http.Error(w, "statsview: invalid procs", http.StatusBadRequest)
+			return
+		}
+		if prev := runtime.GOMAXPROCS(procs); prev != procs {
+			vm.Annotate(fmt.Sprintf("GOMAXPROCS %d → %d", prev, procs))
+		}
+	}
+
+	writeData(w, r, procsState{
+		Procs:  runtime.GOMAXPROCS(0),
+		NumCPU: runtime.NumCPU(),
+	})
+}
@@ -0,0 +1,75 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestControl(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+	viewer.SetConfiguration(viewer.WithAdminToken("s3cret"))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		auth     string
+		form     url.Values
+		status   int
+		annotate string
+	}{
+		{name: "gc requires POST", method: http.MethodGet, path: "/debug/statsview/control/gc", status: http.StatusMethodNotAllowed},
+		{name: "gc requires the token", method: http.MethodPost, path: "/debug/statsview/control/gc", status: http.StatusUnauthorized},
+		{name: "gc", method: http.MethodPost, path: "/debug/statsview/control/gc", auth: "Bearer s3cret", status: http.StatusNoContent, annotate: "Forced GC"},
+		{name: "free OS memory requires POST", method: http.MethodGet, path: "/debug/statsview/control/freeosmemory", status: http.StatusMethodNotAllowed},
+		{name: "free OS memory", method: http.MethodPost, path: "/debug/statsview/control/freeosmemory", auth: "Bearer s3cret", status: http.StatusNoContent, annotate: "FreeOSMemory"},
+		{name: "gomaxprocs read", method: http.MethodGet, path: "/debug/statsview/control/gomaxprocs", status: http.StatusOK},
+		{name: "gomaxprocs requires the token", method: http.MethodPost, path: "/debug/statsview/control/gomaxprocs", form: url.Values{"procs": {"1"}}, status: http.StatusUnauthorized},
+		{name: "gomaxprocs invalid", method: http.MethodPost, path: "/debug/statsview/control/gomaxprocs", auth: "Bearer s3cret", form: url.Values{"procs": {"0"}}, status: http.StatusBadRequest},
+		{name: "gomaxprocs", method: http.MethodPost, path: "/debug/statsview/control/gomaxprocs", auth: "Bearer s3cret", form: url.Values{"procs": {"1"}}, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := New(Viewers{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.form.Encode()))
+			r.Header.Set("X-Requested-With", "XMLHttpRequest")
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.annotate != "" && (len(mgr.annotations) != 1 || mgr.annotations[0].Text != tt.annotate) {
+				t.Errorf("annotations = %v, want %s", mgr.annotations, tt.annotate)
+			}
+
+			if strings.HasSuffix(tt.path, "gomaxprocs") && tt.status == http.StatusOK {
+				var got struct{ Procs, NumCPU int }
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Procs != runtime.GOMAXPROCS(0) || got.NumCPU != runtime.NumCPU() {
+					t.Errorf("gomaxprocs = %+v", got)
+				}
+				if tt.form != nil && got.Procs != 1 {
+					t.Errorf("procs = %d, want 1", got.Procs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package statsview
+
+import (
+	"github.com/mortum5/statsview/internal/demo"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// NewDemo returns a ViewManager serving the default viewers and the GC
+// pauses fed by synthetic metrics rather than the process, for developing
+// the dashboard, taking screenshots or debugging templates without a
+// workload. The metrics advance with the collection, so they are only
+// generated while the dashboard is open unless viewer.WithAlwaysCollect is set
+func NewDemo() (*ViewManager, error) {
+	viewers := NewDefaultViewers()
+	viewers.Register(viewer.NewPauseViewer())
+
+	mgr, err := New(viewers)
+	if err != nil {
+		return nil, err
+	}
+	gen := demo.New(mgr.Smgr.Now)
+	mgr.Smgr.SetMemStatsReader(gen.ReadMemStats)
+	mgr.Smgr.SetGoroutinesReader(gen.NumGoroutine)
+	mgr.Smgr.Refresh()
+	return mgr, nil
+}
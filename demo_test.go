@@ -0,0 +1,26 @@
+package statsview
+
+import (
+	"testing"
+)
+
+func TestNewDemo(t *testing.T) {
+	mgr, err := NewDemo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	// the synthetic heap starts at 40MiB and the system memory is derived
+	// from it, unlike the process' own statistics
+	ms := mgr.Smgr.MemStats()
+	if ms.HeapSys < 40<<20 {
+		t.Errorf("HeapSys = %d, want the synthetic heap", ms.HeapSys)
+	}
+	if sys := ms.HeapSys + ms.StackSys + ms.MSpanSys + ms.GCSys + 4<<20; ms.Sys != sys {
+		t.Errorf("Sys = %d, want %d", ms.Sys, sys)
+	}
+	if n := mgr.Smgr.NumGoroutine(); n < 8 {
+		t.Errorf("NumGoroutine() = %d, want the synthetic count", n)
+	}
+}
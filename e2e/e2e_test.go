@@ -0,0 +1,69 @@
+package e2e
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestDashboard(t *testing.T) {
+	viewers := statsview.NewEmptyViewers()
+	viewers.Register(viewer.NewGoroutinesViewer(), viewer.NewHeapViewer())
+
+	h := Start(t, viewers, viewer.WithInterval(200))
+	h.AssertRendered()
+	for _, name := range []string{viewer.VGoroutine, viewer.VHeap} {
+		h.WaitPoints(name, 3, 10*time.Second)
+		series, err := h.Series(name)
+		if err != nil {
+			t.Fatalf("series of %s: %v", name, err)
+		}
+		if len(series) == 0 || len(series[0]) == 0 {
+			t.Errorf("viewer %s has no data: %v", name, series)
+		}
+	}
+}
+
+func TestConfigurationRestored(t *testing.T) {
+	if !BrowserAvailable() {
+		t.Skip("e2e: no chrome/chromium binary found")
+	}
+	before := viewer.Interval()
+	t.Run("harness", func(t *testing.T) {
+		Start(t, statsview.NewEmptyViewers(), viewer.WithInterval(before+100))
+		if got := viewer.Interval(); got != before+100 {
+			t.Fatalf("interval = %d while the harness runs, want %d", got, before+100)
+		}
+	})
+	if got := viewer.Interval(); got != before {
+		t.Errorf("interval = %d after the harness, want %d", got, before)
+	}
+}
+
+func TestWaitServing(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		ok     bool
+	}{
+		{"ok", http.StatusOK, true},
+		{"unauthorized", http.StatusUnauthorized, false},
+		{"not found", http.StatusNotFound, false},
+		{"unavailable", http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			if err := waitServing(srv.URL, 300*time.Millisecond); (err == nil) != tt.ok {
+				t.Errorf("waitServing() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package e2e is an end-to-end test harness which starts a ViewManager and
+// drives the dashboard with a headless Chrome via chromedp, so the contract
+// between the Go generated templates and the browser side could be asserted.
+// Custom viewer authors could reuse it in their own tests:
+//
+//	func TestMyViewer(t *testing.T) {
+//		viewers := statsview.NewEmptyViewers()
+//		viewers.Register(NewMyViewer())
+//
+//		h := e2e.Start(t, viewers, viewer.WithInterval(500))
+//		h.AssertRendered()
+//		h.WaitPoints(NewMyViewer().Name(), 3, 10*time.Second)
+//	}
+//
+// Tests are skipped when no Chrome/Chromium binary could be found. The
+// configuration of statsview is global, it's restored once the test is done
+// and the tests using the harness mustn't run in parallel.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// Harness is a running ViewManager with a headless browser attached to its dashboard
+type Harness struct {
+	t       testing.TB
+	Manager *statsview.ViewManager
+	Addr    string
+
+	ctx context.Context
+}
+
+var browsers = []string{
+	"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "chrome", "headless-shell",
+}
+
+// BrowserAvailable reports whether a Chrome/Chromium binary is on the PATH
+func BrowserAvailable() bool {
+	for _, b := range browsers {
+		if _, err := exec.LookPath(b); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// freeAddr returns a free localhost address
+func freeAddr() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer ln.Close()
+	return ln.Addr().String(), nil
+}
+
+// Start applies opts, starts a ViewManager with viewers on a random port and
+// opens the dashboard in a headless browser. Everything is torn down with
+// t.Cleanup, the configuration is restored
+func Start(t testing.TB, viewers statsview.Viewers, opts ...viewer.Option) *Harness {
+	t.Helper()
+	if !BrowserAvailable() {
+		t.Skip("e2e: no chrome/chromium binary found")
+	}
+
+	addr, err := freeAddr()
+	if err != nil {
+		t.Fatalf("e2e: %v", err)
+	}
+	saved := viewer.SaveConfiguration()
+	t.Cleanup(func() { viewer.RestoreConfiguration(saved) })
+	if err := viewer.SetConfiguration(append(opts, viewer.WithAddr(addr))...); err != nil {
+		t.Fatalf("e2e: %v", err)
+	}
+
+	mgr, err := statsview.New(viewers)
+	if err != nil {
+		t.Fatalf("e2e: %v", err)
+	}
+	h := &Harness{t: t, Manager: mgr, Addr: addr}
+	go h.Manager.Start()
+	t.Cleanup(h.Manager.Stop)
+
+	if err := waitServing("http://"+addr+"/debug/statsview", 5*time.Second); err != nil {
+		t.Fatalf("e2e: dashboard is not served: %v", err)
+	}
+
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), chromedp.DefaultExecAllocatorOptions[:]...)
+	ctx, cancel := chromedp.NewContext(allocCtx)
+	t.Cleanup(func() {
+		cancel()
+		cancelAlloc()
+	})
+	h.ctx = ctx
+
+	if err := h.run(30*time.Second, chromedp.Navigate("http://"+addr+"/debug/statsview")); err != nil {
+		t.Fatalf("e2e: failed to open the dashboard: %v", err)
+	}
+	return h
+}
+
+// waitServing waits until url answers 200 OK, for timeout at most
+func waitServing(url string, timeout time.Duration) error {
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+			err = fmt.Errorf("%s answered %s", url, resp.Status)
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (h *Harness) run(timeout time.Duration, actions ...chromedp.Action) error {
+	ctx, cancel := context.WithTimeout(h.ctx, timeout)
+	defer cancel()
+	return chromedp.Run(ctx, actions...)
+}
+
+// Eval evaluates the JavaScript expression in the dashboard page and stores the result into res
+func (h *Harness) Eval(expr string, res interface{}) error {
+	return h.run(10*time.Second, chromedp.Evaluate(expr, res))
+}
+
+// ChartID returns the DOM id of the chart rendered by the named viewer
+func (h *Harness) ChartID(name string) string {
+	for _, v := range h.Manager.Viewers() {
+		if v.Name() == name {
+			return v.View().ChartID
+		}
+	}
+	h.t.Fatalf("e2e: viewer %q is not registered", name)
+	return ""
+}
+
+// AssertRendered fails the test unless every registered viewer has an
+// initialized echarts instance on the page
+func (h *Harness) AssertRendered() {
+	h.t.Helper()
+	for _, v := range h.Manager.Viewers() {
+		var ok bool
+		expr := fmt.Sprintf(`echarts.getInstanceByDom(document.getElementById(%q)) !== undefined`, v.View().ChartID)
+		if err := h.Eval(expr, &ok); err != nil || !ok {
+			h.t.Errorf("e2e: chart of viewer %q is not rendered: %v", v.Name(), err)
+		}
+	}
+}
+
+// Points returns the number of x-axis points the chart of the named viewer has
+func (h *Harness) Points(name string) (int, error) {
+	var n int
+	expr := fmt.Sprintf(`goecharts_%s.getOption().xAxis[0].data.length`, h.ChartID(name))
+	err := h.Eval(expr, &n)
+	return n, err
+}
+
+// Series returns the current series data of the chart of the named viewer
+func (h *Harness) Series(name string) ([][]float64, error) {
+	var res [][]float64
+	expr := fmt.Sprintf(`goecharts_%s.getOption().series.map(s => s.data.map(d => typeof d === "object" ? d.value : d))`,
+		h.ChartID(name))
+	err := h.Eval(expr, &res)
+	return res, err
+}
+
+// WaitPoints fails the test unless the chart of the named viewer receives at
+// least n points within timeout, which proves the update loop works
+func (h *Harness) WaitPoints(name string, n int, timeout time.Duration) {
+	h.t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		got, err := h.Points(name)
+		if err == nil && got >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			h.t.Fatalf("e2e: viewer %q got %d points, want %d (last error: %v)", name, got, n, err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
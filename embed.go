@@ -0,0 +1,115 @@
+package statsview
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/render"
+	"github.com/go-echarts/go-echarts/v2/templates"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// EmbedPath is the path prefix of the single charts served for iframes,
+// e.g. /debug/statsview/embed/heap
+const EmbedPath = BasePath + "/embed/"
+
+// embedTpl renders the charts of a page filling the frame, without the
+// navigation and the controls of the dashboard
+const embedTpl = `
+{{- define "embed" }}
+<!DOCTYPE html>
+<html>
+	{{- template "header" . }}
+<body style="margin:0">
+<style> .container { width:100vw; height:100vh } .item { width:100% !important; height:100% !important } </style>
+{{- range .Charts }} {{ template "base" . }} {{- end }}
+<script type="text/javascript">
+	window.statsview_base = {{ .Base }};
+	window.addEventListener("resize", function () {
+		$(".item").each(function () {
+			let chart = echarts.getInstanceByDom(this);
+			if (chart) {
+				chart.resize();
+			}
+		});
+	});
+</script>
+</body>
+</html>
+{{ end }}
+`
+
+// funcMarks are the marks of the JS functions in the options of the charts,
+// they're stripped like go-echarts does for its own templates
+var funcMarks = regexp.MustCompile(`(__f__")|("__f__)|(__f__)`)
+
+// embedRender renders a page with embedTpl, the metrics of its charts are
+// polled under base
+type embedRender struct {
+	page *components.Page
+	base string
+}
+
+func (r embedRender) Render(w io.Writer) error {
+	r.page.Validate()
+	tpl := render.MustTemplate("embed", []string{templates.HeaderTpl, templates.BaseTpl, embedTpl})
+
+	var buf bytes.Buffer
+	data := struct {
+		*components.Page
+		Base string
+	}{r.page, r.base}
+	if err := tpl.ExecuteTemplate(&buf, "embed", data); err != nil {
+		return err
+	}
+	_, err := w.Write(funcMarks.ReplaceAll(buf.Bytes(), nil))
+	return err
+}
+
+// frameFriendly allows the sources of viewer.EmbedAncestors to frame the
+// response in place of the default DENY
+func frameFriendly(h http.Header) {
+	h.Del("X-Frame-Options")
+	ancestors := "frame-ancestors " + strings.Join(viewer.EmbedAncestors(), " ")
+	csp := h.Get("Content-Security-Policy")
+	if csp == "" {
+		h.Set("Content-Security-Policy", ancestors)
+		return
+	}
+
+	directives := strings.Split(csp, ";")
+	replaced := false
+	for i, d := range directives {
+		if strings.HasPrefix(strings.TrimSpace(d), "frame-ancestors") {
+			directives[i], replaced = " "+ancestors, true
+		}
+	}
+	if !replaced {
+		directives = append(directives, " "+ancestors)
+	}
+	h.Set("Content-Security-Policy", strings.TrimSpace(strings.Join(directives, ";")))
+}
+
+// serveEmbed serves the chart of a viewer alone, e.g.
+// `/debug/statsview/embed/heap`, to embed it as an iframe in wikis and
+// portals
+func (vm *ViewManager) serveEmbed(w http.ResponseWriter, r *http.Request) {
+	v := vm.viewerByName(path.Base(r.URL.Path))
+	if v == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	base := basePath(r)
+	page := chartsPage(v.View().Title.Title, base, []viewer.Viewer{v}, viewer.ChartTheme())
+	page.Renderer = embedRender{page: page, base: base}
+	frameFriendly(w.Header())
+	if err := writeHTML(w, r, page.Render); err != nil {
+		viewer.Logger().Error("statsview: failed to render embedded chart", "viewer", v.Name(), "err", err)
+	}
+}
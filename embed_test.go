@@ -0,0 +1,94 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestFrameFriendly(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithEmbedAncestors())
+
+	tests := []struct {
+		name      string
+		ancestors []string
+		csp       string
+		want      string
+	}{
+		{"no CSP", nil, "", "frame-ancestors *"},
+		{"replaced", nil, "default-src 'self'; frame-ancestors 'none'; base-uri 'self'", "default-src 'self'; frame-ancestors *; base-uri 'self'"},
+		{"appended", nil, "default-src 'self'", "default-src 'self'; frame-ancestors *"},
+		{"restricted", []string{"https://wiki.example.com", "https://portal.example.com"}, "frame-ancestors 'none'",
+			"frame-ancestors https://wiki.example.com https://portal.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithEmbedAncestors(tt.ancestors...))
+			h := http.Header{}
+			h.Set("X-Frame-Options", "DENY")
+			if tt.csp != "" {
+				h.Set("Content-Security-Policy", tt.csp)
+			}
+			frameFriendly(h)
+			if got := h.Get("Content-Security-Policy"); got != tt.want {
+				t.Errorf("CSP = %q, want %q", got, tt.want)
+			}
+			if _, ok := h["X-Frame-Options"]; ok {
+				t.Error("X-Frame-Options is kept")
+			}
+		})
+	}
+}
+
+func TestServeEmbed(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer(), viewer.NewHeapViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		path   string
+		status int
+		want   []string
+		absent []string
+	}{
+		{
+			path:   EmbedPath + "heap",
+			status: http.StatusOK,
+			want:   []string{"<title>Heap</title>", `src="/debug/statsview/statics/echarts.min.js"`, `src="/debug/statsview/statics/jquery.min.js"`, `window.statsview_base = "/debug/statsview";`, "frame-ancestors *"},
+			absent: []string{"__f__", `id="force-gc"`, "go-echarts.github.io"},
+		},
+		{path: EmbedPath + "goroutine", status: http.StatusOK, want: []string{"<title>Goroutines</title>"}},
+		{path: EmbedPath + "pause", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			if _, ok := rec.Header()["X-Frame-Options"]; ok {
+				t.Error("the embedded chart can't be framed")
+			}
+			page := rec.Body.String() + rec.Header().Get("Content-Security-Policy")
+			for _, s := range tt.want {
+				if !strings.Contains(page, s) {
+					t.Errorf("the page doesn't contain %s", s)
+				}
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(page, s) {
+					t.Errorf("the page contains %s", s)
+				}
+			}
+		})
+	}
+}
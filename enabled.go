@@ -0,0 +1,102 @@
+package statsview
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+	"github.com/mortum5/statsview/notify"
+	"github.com/mortum5/statsview/registry"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// EnabledEnv is the environment variable which enables the manager returned by NewIfEnabled
+const EnabledEnv = "STATSVIEW_ENABLED"
+
+var errDisabled = errors.New("statsview: disabled, set " + EnabledEnv + "=1 to enable")
+
+// Manager is the API shared by ViewManager and the no-op manager of NewIfEnabled
+type Manager interface {
+	Start() error
+	Stop()
+	Handler() http.Handler
+	AddExporter(exp exporter.Exporter, flushTimeout time.Duration)
+	AddRegistrar(r registry.Registrar)
+	DumpTrace(w io.Writer) error
+	Export(w io.Writer) error
+	AddAnomalyHook(hook func(Anomaly))
+	AddNotifier(n notify.Notifier)
+	RecordBaseline(window time.Duration) error
+	ClearBaseline()
+	Annotate(text string)
+	Register(v viewer.Viewer) error
+	Unregister(name string) bool
+}
+
+var _ Manager = (*ViewManager)(nil)
+
+// NewIfEnabled returns a ViewManager when the STATSVIEW_ENABLED environment
+// variable is true, otherwise a no-op Manager which neither listens nor
+// collects, so the wiring could be shipped dormant in production binaries
+func NewIfEnabled(viewers Viewers) (Manager, error) {
+	if enabled, _ := strconv.ParseBool(os.Getenv(EnabledEnv)); !enabled {
+		return &noopManager{stopped: make(chan struct{})}, nil
+	}
+	mgr, err := New(viewers)
+	if err != nil {
+		return nil, err
+	}
+	return mgr, nil
+}
+
+// noopManager blocks Start until Stop like a running ViewManager would
+type noopManager struct {
+	once    sync.Once
+	stopped chan struct{}
+}
+
+func (m *noopManager) Start() error {
+	<-m.stopped
+	return http.ErrServerClosed
+}
+
+func (m *noopManager) Stop() {
+	m.once.Do(func() { close(m.stopped) })
+}
+
+func (m *noopManager) Handler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+func (m *noopManager) AddExporter(exporter.Exporter, time.Duration) {}
+
+func (m *noopManager) AddRegistrar(registry.Registrar) {}
+
+func (m *noopManager) Annotate(string) {}
+
+func (m *noopManager) Register(viewer.Viewer) error { return nil }
+
+func (m *noopManager) Unregister(string) bool { return false }
+
+func (m *noopManager) DumpTrace(io.Writer) error {
+	return errDisabled
+}
+
+func (m *noopManager) Export(io.Writer) error {
+	return errDisabled
+}
+
+func (m *noopManager) AddAnomalyHook(func(Anomaly)) {}
+
+func (m *noopManager) AddNotifier(notify.Notifier) {}
+
+func (m *noopManager) RecordBaseline(time.Duration) error {
+	return errDisabled
+}
+
+func (m *noopManager) ClearBaseline() {}
@@ -0,0 +1,96 @@
+package statsview
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestNewIfEnabled(t *testing.T) {
+	tests := []struct {
+		env  string
+		noop bool
+	}{
+		{"", true},
+		{"0", true},
+		{"yes", true},
+		{"1", false},
+		{"true", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(EnabledEnv, tt.env)
+			mgr, err := NewIfEnabled(Viewers{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			if _, noop := mgr.(*noopManager); noop != tt.noop {
+				t.Fatalf("NewIfEnabled() = %T, want no-op %v", mgr, tt.noop)
+			}
+			if !tt.noop {
+				return
+			}
+
+			rec := httptest.NewRecorder()
+			mgr.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview", nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want 404", rec.Code)
+			}
+
+			// Start blocks until Stop like a running manager
+			started := make(chan error, 1)
+			go func() { started <- mgr.Start() }()
+			select {
+			case err := <-started:
+				t.Fatalf("Start() = %v before Stop", err)
+			case <-time.After(10 * time.Millisecond):
+			}
+			mgr.Stop()
+			if err := <-started; !errors.Is(err, http.ErrServerClosed) {
+				t.Errorf("Start() = %v, want %v", err, http.ErrServerClosed)
+			}
+		})
+	}
+}
+
+func TestNoopManager(t *testing.T) {
+	t.Setenv(EnabledEnv, "")
+	mgr, err := NewIfEnabled(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func(Manager) error
+		want error
+	}{
+		{"dump trace", func(m Manager) error { return m.DumpTrace(io.Discard) }, errDisabled},
+		{"register", func(m Manager) error { return m.Register(viewer.NewHeapViewer()) }, nil},
+		{"unregister", func(m Manager) error {
+			if m.Unregister(viewer.VHeap) {
+				return errors.New("unregistered a viewer")
+			}
+			return nil
+		}, nil},
+		{"export", func(m Manager) error { return m.Export(io.Discard) }, errDisabled},
+		{"record baseline", func(m Manager) error { return m.RecordBaseline(time.Minute) }, errDisabled},
+		{"clear baseline", func(m Manager) error { m.ClearBaseline(); return nil }, nil},
+		{"anomaly hook", func(m Manager) error { m.AddAnomalyHook(func(Anomaly) {}); return nil }, nil},
+		{"notifier", func(m Manager) error { m.AddNotifier(nil); return nil }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(mgr); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
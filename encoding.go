@@ -0,0 +1,89 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/mortum5/statsview/internal/msgpack"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// ContentTypeMsgpack is negotiated via the Accept header to get the metrics
+// endpoints encoded as MessagePack instead of JSON
+const ContentTypeMsgpack = "application/msgpack"
+
+// acceptsMsgpack reports whether the client asked for MessagePack
+func acceptsMsgpack(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, ContentTypeMsgpack) || strings.Contains(accept, "application/x-msgpack")
+}
+
+// writeData writes v as JSON or as MessagePack if the client accepts it, an
+// encoding error is answered with a 500
+func writeData(w http.ResponseWriter, r *http.Request, v interface{}) {
+	w.Header().Add("Vary", "Accept")
+	if acceptsMsgpack(r) {
+		bs, err := msgpack.Marshal(v)
+		if err != nil {
+			viewer.Logger().Error("statsview: failed to encode msgpack", "path", r.URL.Path, "err", err)
+			viewer.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeMsgpack)
+		w.Write(bs)
+		return
+	}
+
+	viewer.WriteJSON(w, v)
+}
+
+// encodeMsgpack encodes the buffered response of a viewer as MessagePack,
+// its value directly. Only a body the viewer wrote itself, which is JSON,
+// is transcoded
+func encodeMsgpack(b *viewer.ResponseBuffer) ([]byte, error) {
+	if v, ok := b.Value(); ok {
+		return msgpack.Marshal(v)
+	}
+	var v interface{}
+	if err := json.Unmarshal(b.Body(), &v); err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(v)
+}
+
+// writeBuffered writes the buffered response of a viewer, encoded once in
+// the format the client accepts. The errors are written as they are, JSON
+func writeBuffered(w http.ResponseWriter, r *http.Request, b *viewer.ResponseBuffer) {
+	for k, vs := range b.Header() {
+		w.Header()[k] = vs
+	}
+	if b.Status() != http.StatusOK {
+		if v, ok := b.Value(); ok {
+			viewer.WriteJSONStatus(w, b.Status(), v)
+			return
+		}
+		w.WriteHeader(b.Status())
+		w.Write(b.Body())
+		return
+	}
+
+	if acceptsMsgpack(r) {
+		w.Header().Add("Vary", "Accept")
+		bs, err := encodeMsgpack(b)
+		if err != nil {
+			viewer.Logger().Error("statsview: failed to encode msgpack", "path", r.URL.Path, "err", err)
+			viewer.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", ContentTypeMsgpack)
+		w.Write(bs)
+		return
+	}
+	if v, ok := b.Value(); ok {
+		writeData(w, r, v)
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	w.Write(b.Body())
+}
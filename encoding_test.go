@@ -0,0 +1,117 @@
+package statsview
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/internal/msgpack"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestWriteBuffered(t *testing.T) {
+	m := viewer.Metrics{Values: []float64{1.5}, Time: "12:00:00"}
+	packed, err := msgpack.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transcoded, err := msgpack.Marshal(map[string]interface{}{"time": "12:00:00", "values": []interface{}{1.5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const body = `{"values":[1.5],"time":"12:00:00"}`
+	value := func(w http.ResponseWriter) { viewer.WriteJSON(w, m) }
+	written := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+
+	tests := []struct {
+		name        string
+		serve       func(w http.ResponseWriter)
+		accept      string
+		status      int
+		contentType string
+		want        []byte
+	}{
+		{"json", value, "", http.StatusOK, "application/json", []byte(body)},
+		{"msgpack of the value", value, "application/msgpack", http.StatusOK, ContentTypeMsgpack, packed},
+		{"x-msgpack", value, "application/x-msgpack, application/json;q=0.5", http.StatusOK, ContentTypeMsgpack, packed},
+		{"written json", written, "", http.StatusOK, "application/json", []byte(body)},
+		{"written body transcoded", written, "application/msgpack", http.StatusOK, ContentTypeMsgpack, transcoded},
+		{
+			"error stays json",
+			func(w http.ResponseWriter) { viewer.WriteError(w, http.StatusInternalServerError, errors.New("down")) },
+			"application/msgpack", http.StatusInternalServerError, "application/json", []byte(`{"error":"down"}`),
+		},
+		{
+			"error value stays json",
+			func(w http.ResponseWriter) {
+				viewer.WriteJSONStatus(w, http.StatusBadGateway, map[string]string{"error": "down"})
+			},
+			"application/msgpack", http.StatusBadGateway, "application/json", []byte(`{"error":"down"}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := viewer.NewResponseBuffer(nil)
+			tt.serve(resp)
+			r := httptest.NewRequest(http.MethodGet, "/debug/statsview/view/goroutine", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			writeBuffered(rec, r, resp)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("Content-Type = %s, want %s", ct, tt.contentType)
+			}
+			if !bytes.Equal(rec.Body.Bytes(), tt.want) {
+				t.Errorf("body = %x, want %x", rec.Body.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteData(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		v           interface{}
+		status      int
+		contentType string
+		body        string
+	}{
+		{"json", "", map[string]int{"rate": 1}, http.StatusOK, "application/json", `{"rate":1}`},
+		{"msgpack", ContentTypeMsgpack, map[string]int{"rate": 1}, http.StatusOK, ContentTypeMsgpack, "\x81\xa4rate\x01"},
+		{"json error", "", map[string]float64{"rate": math.NaN()}, http.StatusInternalServerError, "application/json", `{"error":`},
+		{"msgpack error", ContentTypeMsgpack, make(chan int), http.StatusInternalServerError, "application/json", `{"error":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/debug/statsview/status", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			writeData(rec, r, tt.v)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.body) {
+				t.Errorf("body = %q, want prefix %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
@@ -29,7 +29,10 @@ func main() {
 		static,
 	)
 
-	mgr := statsview.New(viewers)
+	mgr, err := statsview.New(viewers)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	go func() {
 		if err := mgr.Start(); err != nil && err != http.ErrServerClosed {
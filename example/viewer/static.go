@@ -1,9 +1,7 @@
 package viewer
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -49,15 +47,9 @@ func (vs *StaticViewer) View() *charts.Line {
 var i = 0
 
 func (vs *StaticViewer) Serve(w http.ResponseWriter, _ *http.Request) {
-	vs.smgr.Tick()
-
-	metrics := viewer.Metrics{
-		Values: []float64{float64(i % 10)},
-		Time:   time.Unix(vs.smgr.GetTime(), 0).Format(viewer.TimeFormat()),
-	}
+	metrics := viewer.NewMetrics([]float64{float64(i % 10)}, vs.smgr.CollectTime())
 
 	i++
 
-	bs, _ := json.Marshal(metrics)
-	w.Write(bs)
+	viewer.WriteJSON(w, metrics)
 }
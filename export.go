@@ -0,0 +1,128 @@
+package statsview
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+	"github.com/mortum5/statsview/viewer"
+)
+
+type exporterEntry struct {
+	exp          exporter.Exporter
+	flushTimeout time.Duration
+}
+
+// AddExporter registers an exporter which receives the metrics of all viewers
+// implementing viewer.Collector on every interval. flushTimeout bounds its Flush
+// call during Stop, zero means exporter.DefaultFlushTimeout.
+// It must be called before Start
+func (vm *ViewManager) AddExporter(exp exporter.Exporter, flushTimeout time.Duration) {
+	vm.exporters = append(vm.exporters, newExporterEntry(exp, flushTimeout))
+}
+
+func newExporterEntry(exp exporter.Exporter, flushTimeout time.Duration) exporterEntry {
+	if flushTimeout <= 0 {
+		flushTimeout = exporter.DefaultFlushTimeout
+	}
+	return exporterEntry{exp: exp, flushTimeout: flushTimeout}
+}
+
+// collect gathers the samples of every collecting viewer in base units
+func (vm *ViewManager) collect() []exporter.Sample {
+	return samplesOf(vm.collectPoints())
+}
+
+// samplesOf converts the points to samples named after their viewer and series
+func samplesOf(points []viewer.Point) []exporter.Sample {
+	var samples []exporter.Sample
+	for _, p := range points {
+		samples = append(samples, exporter.Sample{
+			Name:   sampleName(p.Viewer, p.Series),
+			Labels: map[string]string{"viewer": p.Viewer, "series": p.Series},
+			Value:  p.Value,
+			Time:   p.Time,
+		})
+	}
+	return samples
+}
+
+func export(ctx context.Context, exporters []exporterEntry, samples []exporter.Sample) {
+	for _, e := range exporters {
+		if err := e.exp.Export(ctx, samples); err != nil {
+			viewer.Logger().Error("statsview: export failed", "exporter", fmt.Sprintf("%T", e.exp), "err", err)
+		}
+	}
+}
+
+// exportLoop feeds the exporters until the manager is cancelled
+func (vm *ViewManager) exportLoop() {
+	defer vm.exportWg.Done()
+	exportLoop(vm.Ctx, vm.Smgr, vm.exporters, vm.collect)
+}
+
+// exportLoop feeds the exporters with the samples of collect on every
+// interval until ctx is cancelled
+func exportLoop(ctx context.Context, smgr *viewer.StatsMgr, exporters []exporterEntry, collect func() []exporter.Sample) {
+	interval := smgr.CurrentInterval()
+	ticker := smgr.Clock().NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+
+	var collected lastCollection
+	for {
+		select {
+		case <-ticker.C():
+			// the exporters are a client of the collection like the dashboard
+			smgr.Lease("exporter", 2*time.Duration(interval)*time.Millisecond)
+			// a tick ahead of the collection would export its samples twice
+			if collected.advanced(smgr.CollectTime()) {
+				exportCtx, cancel := context.WithTimeout(ctx, time.Duration(interval)*time.Millisecond)
+				export(exportCtx, exporters, collect())
+				cancel()
+			}
+
+			if current := smgr.CurrentInterval(); current != interval {
+				interval = current
+				ticker.Reset(time.Duration(interval) * time.Millisecond)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// flushExporters delivers the last samples and flushes every exporter
+// concurrently, each one bounded by its own timeout
+func flushExporters(exporters []exporterEntry, samples []exporter.Sample) {
+	var wg sync.WaitGroup
+	for _, e := range exporters {
+		wg.Add(1)
+		go func(e exporterEntry) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), e.flushTimeout)
+			defer cancel()
+			name := fmt.Sprintf("%T", e.exp)
+			if err := e.exp.Export(ctx, samples); err != nil {
+				viewer.Logger().Error("statsview: export failed", "exporter", name, "err", err)
+			}
+			if err := e.exp.Flush(ctx); err != nil {
+				viewer.Logger().Error("statsview: flush failed", "exporter", name, "err", err)
+			}
+		}(e)
+	}
+	wg.Wait()
+}
+
+// sampleName builds a Prometheus-friendly name like `statsview_heap_alloc`
+func sampleName(viewerName, series string) string {
+	name := []byte(strings.ToLower("statsview_" + viewerName + "_" + series))
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_') {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
@@ -0,0 +1,76 @@
+package statsview
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+)
+
+func TestSampleName(t *testing.T) {
+	tests := []struct {
+		viewer, series string
+		want           string
+	}{
+		{"heap", "Alloc", "statsview_heap_alloc"},
+		{"gc_cpu_fraction", "Fraction", "statsview_gc_cpu_fraction_fraction"},
+		{"block", "Delay (s)", "statsview_block_delay__s_"},
+		{"remote-1", "go.goroutines", "statsview_remote_1_go_goroutines"},
+	}
+	for _, tt := range tests {
+		if got := sampleName(tt.viewer, tt.series); got != tt.want {
+			t.Errorf("sampleName(%q, %q) = %q, want %q", tt.viewer, tt.series, got, tt.want)
+		}
+	}
+}
+
+// flushRecorder records whether it was flushed, or blocks until its context
+// is done when it's stuck
+type flushRecorder struct {
+	stuck   bool
+	flushed chan error
+}
+
+func (e *flushRecorder) Export(context.Context, []exporter.Sample) error { return nil }
+
+func (e *flushRecorder) Flush(ctx context.Context) error {
+	if e.stuck {
+		<-ctx.Done()
+		e.flushed <- ctx.Err()
+		return ctx.Err()
+	}
+	e.flushed <- nil
+	return nil
+}
+
+func TestFlushExporters(t *testing.T) {
+	stuck := &flushRecorder{stuck: true, flushed: make(chan error, 1)}
+	fast := &flushRecorder{flushed: make(chan error, 1)}
+
+	exporters := []exporterEntry{newExporterEntry(stuck, 20*time.Millisecond), newExporterEntry(fast, time.Hour)}
+
+	start := time.Now()
+	flushExporters(exporters, nil)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("flushExporters took %v, want the timeout of the stuck exporter", elapsed)
+	}
+	tests := []struct {
+		name string
+		exp  *flushRecorder
+		want error
+	}{
+		{"stuck", stuck, context.DeadlineExceeded},
+		{"fast", fast, nil},
+	}
+	for _, tt := range tests {
+		select {
+		case err := <-tt.exp.flushed:
+			if err != tt.want {
+				t.Errorf("%s exporter flushed with %v, want %v", tt.name, err, tt.want)
+			}
+		default:
+			t.Errorf("%s exporter not flushed", tt.name)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Types of the exporters of New
+const (
+	TypeInflux      = "influx"
+	TypeRemoteWrite = "remote_write"
+)
+
+// DefaultRemoteWriteInterval is the push interval of the remote-write
+// exporters of New without any interval
+const DefaultRemoteWriteInterval = 15 * time.Second
+
+// Config is an exporter read from a config file
+//
+//	exporters:
+//	  - type: influx
+//	    url: http://influx:8086/api/v2/write?org=lab&bucket=perf
+//	    headers: {Authorization: "Token s3cret"}
+//	  - type: influx
+//	    file: /var/log/statsview.lp
+//	  - type: remote_write
+//	    url: http://mimir:9009/api/v1/push
+//	    interval: 30s
+type Config struct {
+	Type string `yaml:"type" toml:"type"`
+	// URL is the write endpoint of InfluxDB or the remote-write receiver
+	URL string `yaml:"url" toml:"url"`
+	// File is the file the Influx line protocol is appended to instead of
+	// posting it to URL
+	File string `yaml:"file" toml:"file"`
+	// Headers are added to every request, e.g. Authorization
+	Headers map[string]string `yaml:"headers" toml:"headers"`
+	// Interval is the push interval of remote-write, e.g. 30s,
+	// DefaultRemoteWriteInterval when empty
+	Interval string `yaml:"interval" toml:"interval"`
+}
+
+// New returns the exporter configured by c
+func New(c Config) (Exporter, error) {
+	switch c.Type {
+	case TypeInflux:
+		switch {
+		case c.URL != "" && c.File != "":
+			return nil, fmt.Errorf("statsview: the influx exporter takes either a url or a file")
+		case c.File != "":
+			f, err := os.OpenFile(c.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("statsview: influx exporter: %w", err)
+			}
+			return NewInfluxWriter(f), nil
+		case c.URL != "":
+			e := NewInfluxHTTP(c.URL)
+			for k, v := range c.Headers {
+				e.Header.Set(k, v)
+			}
+			return e, nil
+		}
+		return nil, fmt.Errorf("statsview: the influx exporter requires a url or a file")
+
+	case TypeRemoteWrite:
+		if c.URL == "" {
+			return nil, fmt.Errorf("statsview: the remote_write exporter requires a url")
+		}
+		interval := DefaultRemoteWriteInterval
+		if c.Interval != "" {
+			d, err := time.ParseDuration(c.Interval)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("statsview: invalid remote_write interval %q", c.Interval)
+			}
+			interval = d
+		}
+		e := NewRemoteWrite(c.URL, interval)
+		for k, v := range c.Headers {
+			e.Header.Set(k, v)
+		}
+		return e, nil
+	}
+	return nil, fmt.Errorf("statsview: unknown exporter type %q", c.Type)
+}
@@ -0,0 +1,58 @@
+package exporter
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "statsview.lp")
+	tests := []struct {
+		name    string
+		config  Config
+		check   func(t *testing.T, e Exporter)
+		wantErr bool
+	}{
+		{"influx http", Config{Type: TypeInflux, URL: "http://influx:8086/write?db=perf", Headers: map[string]string{"Authorization": "Token s3cret"}},
+			func(t *testing.T, e Exporter) {
+				if got := e.(*Influx).Header.Get("Authorization"); got != "Token s3cret" {
+					t.Errorf("Authorization = %q", got)
+				}
+			}, false},
+		{"influx file", Config{Type: TypeInflux, File: file},
+			func(t *testing.T, e Exporter) {
+				if e.(*Influx).w == nil {
+					t.Error("the file sink isn't set")
+				}
+			}, false},
+		{"influx url and file", Config{Type: TypeInflux, URL: "http://influx:8086/write", File: file}, nil, true},
+		{"influx without sink", Config{Type: TypeInflux}, nil, true},
+		{"remote write", Config{Type: TypeRemoteWrite, URL: "http://mimir:9009/api/v1/push", Interval: "30s"},
+			func(t *testing.T, e Exporter) {
+				if got := e.(*RemoteWrite).interval; got != 30*time.Second {
+					t.Errorf("interval = %v", got)
+				}
+			}, false},
+		{"remote write default interval", Config{Type: TypeRemoteWrite, URL: "http://mimir:9009/api/v1/push"},
+			func(t *testing.T, e Exporter) {
+				if got := e.(*RemoteWrite).interval; got != DefaultRemoteWriteInterval {
+					t.Errorf("interval = %v", got)
+				}
+			}, false},
+		{"remote write invalid interval", Config{Type: TypeRemoteWrite, URL: "http://mimir:9009/api/v1/push", Interval: "-1s"}, nil, true},
+		{"remote write without url", Config{Type: TypeRemoteWrite}, nil, true},
+		{"unknown", Config{Type: "graphite", URL: "http://graphite"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// Package exporter defines the sinks which statsview delivers the collected
+// metrics to, in addition to the charts.
+package exporter
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultFlushTimeout bounds Flush when no timeout has been given
+const DefaultFlushTimeout = 5 * time.Second
+
+// Sample is a single collected value in base units (bytes, seconds, counts)
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+	Time   time.Time
+}
+
+// Exporter receives samples on every collection tick. Implementations may
+// buffer the samples, everything buffered must be delivered by Flush which is
+// called once when the ViewManager stops
+type Exporter interface {
+	Export(ctx context.Context, samples []Sample) error
+	Flush(ctx context.Context) error
+}
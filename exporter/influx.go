@@ -0,0 +1,164 @@
+package exporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxInfluxBytes bounds the line protocol buffered by an Influx HTTP sink
+// while the endpoint is failing, older lines are dropped first
+const MaxInfluxBytes = 8 << 20
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+)
+
+// Influx writes the samples in the InfluxDB line protocol, the sample name is
+// the measurement, the labels are tags and the value is the `value` field
+//
+//	statsview_heap_alloc,series=Alloc,viewer=heap value=4.2e+06 1700000000123000000
+type Influx struct {
+	mu sync.Mutex
+
+	// file sink
+	w *bufio.Writer
+
+	// HTTP sink
+	url     string
+	pending bytes.Buffer
+
+	// Header is added to every request of the HTTP sink, e.g. `Authorization: Token ...`
+	Header http.Header
+	// Client sends the requests of the HTTP sink
+	Client *http.Client
+}
+
+// NewInfluxWriter returns the Influx exporter writing into w, e.g. a file
+// fed to `influx write` later
+func NewInfluxWriter(w io.Writer) *Influx {
+	return &Influx{w: bufio.NewWriter(w)}
+}
+
+// NewInfluxHTTP returns the Influx exporter posting every batch to the write
+// endpoint at url with nanosecond precision, e.g.
+// `http://influx:8086/api/v2/write?org=lab&bucket=perf` (v2) or
+// `http://influx:8086/write?db=perf` (v1)
+func NewInfluxHTTP(url string) *Influx {
+	return &Influx{
+		url:    url,
+		Header: make(http.Header),
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *Influx) Export(ctx context.Context, samples []Sample) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.w != nil {
+		for _, s := range samples {
+			if _, err := e.w.Write(appendInfluxLine(nil, s)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, s := range samples {
+		e.pending.Write(appendInfluxLine(nil, s))
+	}
+	if over := e.pending.Len() - MaxInfluxBytes; over > 0 {
+		// drop whole lines only
+		b := e.pending.Bytes()
+		if i := bytes.IndexByte(b[over:], '\n'); i >= 0 {
+			e.pending.Next(over + i + 1)
+		}
+	}
+	return e.post(ctx)
+}
+
+func (e *Influx) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.w != nil {
+		return e.w.Flush()
+	}
+	return e.post(ctx)
+}
+
+// post sends the pending lines, they are kept for the next post on failure
+func (e *Influx) post(ctx context.Context) error {
+	if e.pending.Len() == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(e.pending.Bytes()))
+	if err != nil {
+		return err
+	}
+	for k, vs := range e.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		e.pending.Reset()
+		return nil
+	case resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests:
+		// the endpoint rejects the lines, retrying wouldn't help
+		e.pending.Reset()
+	}
+	return fmt.Errorf("statsview: influx write to %s answered %s: %s", e.url, resp.Status, bytes.TrimSpace(msg))
+}
+
+// appendInfluxLine appends the line of s, NaN and infinite values can't be
+// written in the line protocol and are skipped
+func appendInfluxLine(b []byte, s Sample) []byte {
+	if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+		return b
+	}
+
+	b = append(b, influxMeasurementEscaper.Replace(s.Name)...)
+
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if s.Labels[k] == "" {
+			// empty tag values are invalid in the line protocol
+			continue
+		}
+		b = append(b, ',')
+		b = append(b, influxTagEscaper.Replace(k)...)
+		b = append(b, '=')
+		b = append(b, influxTagEscaper.Replace(s.Labels[k])...)
+	}
+
+	b = append(b, " value="...)
+	b = strconv.AppendFloat(b, s.Value, 'g', -1, 64)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, s.Time.UnixNano(), 10)
+	return append(b, '\n')
+}
@@ -0,0 +1,108 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAppendInfluxLine(t *testing.T) {
+	at := time.Unix(1700000000, 5)
+	tests := []struct {
+		name   string
+		sample Sample
+		want   string
+	}{
+		{"plain", Sample{Name: "heap.Alloc", Value: 42, Time: at}, "heap.Alloc value=42 1700000000000000005\n"},
+		{"fraction", Sample{Name: "gc", Value: 0.25, Time: at}, "gc value=0.25 1700000000000000005\n"},
+		{"large", Sample{Name: "heap", Value: 1e21, Time: at}, "heap value=1e+21 1700000000000000005\n"},
+		{
+			"sorted tags",
+			Sample{Name: "heap", Labels: map[string]string{"service": "api", "env": "prod"}, Value: 1, Time: at},
+			"heap,env=prod,service=api value=1 1700000000000000005\n",
+		},
+		{
+			"empty tag skipped",
+			Sample{Name: "heap", Labels: map[string]string{"env": "", "host": "a"}, Value: 1, Time: at},
+			"heap,host=a value=1 1700000000000000005\n",
+		},
+		{
+			"escaped",
+			Sample{Name: "my heap,x", Labels: map[string]string{"a b": "c=d,e"}, Value: 1, Time: at},
+			`my\ heap\,x,a\ b=c\=d\,e value=1 1700000000000000005` + "\n",
+		},
+		{"NaN skipped", Sample{Name: "heap", Value: math.NaN(), Time: at}, ""},
+		{"infinity skipped", Sample{Name: "heap", Value: math.Inf(-1), Time: at}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(appendInfluxLine(nil, tt.sample)); got != tt.want {
+				t.Errorf("appendInfluxLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInfluxWriter(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewInfluxWriter(&buf)
+	at := time.Unix(1, 0)
+	if err := e.Export(context.Background(), []Sample{{Name: "a", Value: 1, Time: at}, {Name: "b", Value: math.NaN(), Time: at}}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q before the flush", buf.String())
+	}
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a value=1 1000000000\n"; buf.String() != want {
+		t.Errorf("wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestInfluxHTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+		pending bool
+	}{
+		{"accepted", http.StatusNoContent, false, false},
+		{"rejected", http.StatusBadRequest, true, false},
+		{"throttled", http.StatusTooManyRequests, true, true},
+		{"failing", http.StatusInternalServerError, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Token s3cret" {
+					t.Errorf("the configured header is missing: %v", r.Header)
+				}
+				body, _ := io.ReadAll(r.Body)
+				got = string(body)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			e := NewInfluxHTTP(srv.URL + "/write?db=perf")
+			e.Header.Set("Authorization", "Token s3cret")
+			err := e.Export(context.Background(), []Sample{{Name: "a", Value: 1, Time: time.Unix(1, 0)}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Export() = %v, want error %v", err, tt.wantErr)
+			}
+			if want := "a value=1 1000000000\n"; got != want {
+				t.Errorf("posted %q, want %q", got, want)
+			}
+			if pending := e.pending.Len() > 0; pending != tt.pending {
+				t.Errorf("pending = %v, want %v", pending, tt.pending)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSONLines writes every sample as a JSON object per line into a buffered writer
+type JSONLines struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+type jsonSample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+	Time   int64             `json:"time"`
+}
+
+// NewJSONLines returns the JSONLines exporter writing into w
+func NewJSONLines(w io.Writer) *JSONLines {
+	return &JSONLines{w: bufio.NewWriter(w)}
+}
+
+func (e *JSONLines) Export(_ context.Context, samples []Sample) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	enc := json.NewEncoder(e.w)
+	for _, s := range samples {
+		err := enc.Encode(jsonSample{
+			Name:   s.Name,
+			Labels: s.Labels,
+			Value:  s.Value,
+			Time:   s.Time.UnixMilli(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *JSONLines) Flush(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		done <- e.w.Flush()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,71 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJSONLines(t *testing.T) {
+	at := time.UnixMilli(1700000000000)
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    string
+	}{
+		{"none", nil, ""},
+		{
+			"labels",
+			[]Sample{{Name: "statsview_heap_alloc", Labels: map[string]string{"series": "Alloc", "viewer": "heap"}, Value: 1024, Time: at}},
+			`{"name":"statsview_heap_alloc","labels":{"series":"Alloc","viewer":"heap"},"value":1024,"time":1700000000000}` + "\n",
+		},
+		{
+			"one line per sample",
+			[]Sample{{Name: "a", Value: 1, Time: at}, {Name: "b", Value: 0.5, Time: at}},
+			`{"name":"a","value":1,"time":1700000000000}` + "\n" + `{"name":"b","value":0.5,"time":1700000000000}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := NewJSONLines(&buf)
+			if err := e.Export(context.Background(), tt.samples); err != nil {
+				t.Fatal(err)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("samples written before Flush: %q", buf.String())
+			}
+			if err := e.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// blockedWriter blocks every write until it's released
+type blockedWriter struct {
+	release chan struct{}
+}
+
+func (w blockedWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestJSONLinesFlushTimeout(t *testing.T) {
+	w := blockedWriter{release: make(chan struct{})}
+	defer close(w.release)
+
+	e := NewJSONLines(w)
+	e.Export(context.Background(), []Sample{{Name: "a", Value: 1, Time: time.Now()}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := e.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,99 @@
+package exporter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+)
+
+// Transform rewrites a sample before it leaves the process, returning false
+// drops the sample. The Labels map of the sample is a private copy and may be
+// modified in place
+type Transform func(s Sample) (Sample, bool)
+
+type redactor struct {
+	exp Exporter
+	fns []Transform
+}
+
+// Redact wraps exp so that every sample passes through fns before being
+// exported, which allows e.g. stripping tenant identifiers per exporter
+func Redact(exp Exporter, fns ...Transform) Exporter {
+	return &redactor{exp: exp, fns: fns}
+}
+
+func (r *redactor) Export(ctx context.Context, samples []Sample) error {
+	out := make([]Sample, 0, len(samples))
+	for _, s := range samples {
+		labels := make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		s.Labels = labels
+
+		keep := true
+		for _, fn := range r.fns {
+			if s, keep = fn(s); !keep {
+				break
+			}
+		}
+		if keep {
+			out = append(out, s)
+		}
+	}
+	return r.exp.Export(ctx, out)
+}
+
+func (r *redactor) Flush(ctx context.Context) error {
+	return r.exp.Flush(ctx)
+}
+
+// DropLabels removes the given label keys
+func DropLabels(keys ...string) Transform {
+	return func(s Sample) (Sample, bool) {
+		for _, k := range keys {
+			delete(s.Labels, k)
+		}
+		return s, true
+	}
+}
+
+// HashLabels replaces the values of the given label keys with a salted sha256
+// digest, series stay distinguishable but the original values are not shipped
+func HashLabels(salt string, keys ...string) Transform {
+	return func(s Sample) (Sample, bool) {
+		for _, k := range keys {
+			if v, ok := s.Labels[k]; ok {
+				sum := sha256.Sum256([]byte(salt + v))
+				s.Labels[k] = hex.EncodeToString(sum[:8])
+			}
+		}
+		return s, true
+	}
+}
+
+// ReplaceLabelValues replaces every match of re in all label values with repl
+func ReplaceLabelValues(re *regexp.Regexp, repl string) Transform {
+	return func(s Sample) (Sample, bool) {
+		for k, v := range s.Labels {
+			s.Labels[k] = re.ReplaceAllString(v, repl)
+		}
+		return s, true
+	}
+}
+
+// ReplaceName replaces every match of re in the sample name with repl
+func ReplaceName(re *regexp.Regexp, repl string) Transform {
+	return func(s Sample) (Sample, bool) {
+		s.Name = re.ReplaceAllString(s.Name, repl)
+		return s, true
+	}
+}
+
+// DropNames drops the samples whose name matches re
+func DropNames(re *regexp.Regexp) Transform {
+	return func(s Sample) (Sample, bool) {
+		return s, !re.MatchString(s.Name)
+	}
+}
@@ -0,0 +1,82 @@
+package exporter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// recorder keeps the exported samples
+type recorder struct {
+	samples []Sample
+}
+
+func (r *recorder) Export(_ context.Context, samples []Sample) error {
+	r.samples = append(r.samples, samples...)
+	return nil
+}
+
+func (r *recorder) Flush(context.Context) error { return nil }
+
+func TestRedact(t *testing.T) {
+	hashed := func(salt, v string) string {
+		sum := sha256.Sum256([]byte(salt + v))
+		return hex.EncodeToString(sum[:8])
+	}
+	sample := Sample{Name: "statsview_remote_tenant_acme_requests", Labels: map[string]string{"tenant": "acme", "url": "http://acme.internal/metrics"}, Value: 1}
+
+	tests := []struct {
+		name string
+		fns  []Transform
+		want []Sample
+	}{
+		{"none", nil, []Sample{sample}},
+		{
+			"drop labels",
+			[]Transform{DropLabels("tenant", "missing")},
+			[]Sample{{Name: sample.Name, Labels: map[string]string{"url": "http://acme.internal/metrics"}, Value: 1}},
+		},
+		{
+			"hash labels",
+			[]Transform{HashLabels("pepper", "tenant")},
+			[]Sample{{Name: sample.Name, Labels: map[string]string{"tenant": hashed("pepper", "acme"), "url": "http://acme.internal/metrics"}, Value: 1}},
+		},
+		{
+			"replace label values",
+			[]Transform{ReplaceLabelValues(regexp.MustCompile(`acme`), "x")},
+			[]Sample{{Name: sample.Name, Labels: map[string]string{"tenant": "x", "url": "http://x.internal/metrics"}, Value: 1}},
+		},
+		{
+			"replace name",
+			[]Transform{ReplaceName(regexp.MustCompile(`_tenant_[a-z]+`), "")},
+			[]Sample{{Name: "statsview_remote_requests", Labels: sample.Labels, Value: 1}},
+		},
+		{"drop names", []Transform{DropNames(regexp.MustCompile(`^statsview_remote_`))}, []Sample{}},
+		{
+			"chained",
+			[]Transform{DropLabels("url"), ReplaceName(regexp.MustCompile(`acme`), "tenant"), HashLabels("", "tenant")},
+			[]Sample{{Name: "statsview_remote_tenant_tenant_requests", Labels: map[string]string{"tenant": hashed("", "acme")}, Value: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			in := []Sample{{Name: sample.Name, Labels: map[string]string{"tenant": "acme", "url": "http://acme.internal/metrics"}, Value: 1}}
+			if err := Redact(rec, tt.fns...).Export(context.Background(), in); err != nil {
+				t.Fatal(err)
+			}
+			if len(rec.samples) == 0 {
+				rec.samples = []Sample{}
+			}
+			if !reflect.DeepEqual(rec.samples, tt.want) {
+				t.Errorf("exported %v, want %v", rec.samples, tt.want)
+			}
+			if !reflect.DeepEqual(in[0].Labels, sample.Labels) {
+				t.Errorf("the labels of the sample were changed: %v", in[0].Labels)
+			}
+		})
+	}
+}
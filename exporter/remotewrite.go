@@ -0,0 +1,161 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// MaxRemoteWriteSamples bounds the samples buffered by RemoteWrite while the
+// receiver is failing, the oldest ones are dropped first
+const MaxRemoteWriteSamples = 100000
+
+// RemoteWrite pushes the samples to a Prometheus remote-write receiver
+// (Prometheus, Mimir, VictoriaMetrics, Thanos, ...) every interval, for
+// ephemeral jobs which couldn't be scraped. The sample name becomes the
+// `__name__` label
+type RemoteWrite struct {
+	url      string
+	interval time.Duration
+
+	// Header is added to every request, e.g. Authorization or X-Scope-OrgID
+	Header http.Header
+	// Client sends the requests, http.DefaultClient with a 10s timeout by default
+	Client *http.Client
+
+	mu       sync.Mutex
+	buffered []Sample
+	pushed   time.Time
+}
+
+// NewRemoteWrite returns the RemoteWrite exporter pushing to url, e.g.
+// `http://mimir:9009/api/v1/push`, every interval
+func NewRemoteWrite(url string, interval time.Duration) *RemoteWrite {
+	return &RemoteWrite{
+		url:      url,
+		interval: interval,
+		Header:   make(http.Header),
+		Client:   &http.Client{Timeout: 10 * time.Second},
+		pushed:   time.Now(),
+	}
+}
+
+func (e *RemoteWrite) Export(ctx context.Context, samples []Sample) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.buffered = append(e.buffered, samples...)
+	if n := len(e.buffered); n > MaxRemoteWriteSamples {
+		e.buffered = append(e.buffered[:0], e.buffered[n-MaxRemoteWriteSamples:]...)
+	}
+	if time.Since(e.pushed) < e.interval {
+		return nil
+	}
+	return e.push(ctx)
+}
+
+func (e *RemoteWrite) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.push(ctx)
+}
+
+// push sends the buffered samples, they are kept for the next push on failure
+func (e *RemoteWrite) push(ctx context.Context) error {
+	e.pushed = time.Now()
+	if len(e.buffered) == 0 {
+		return nil
+	}
+
+	body := snappy.Encode(nil, encodeWriteRequest(e.buffered))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range e.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		e.buffered = e.buffered[:0]
+		return nil
+	case resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests:
+		// the receiver rejects the samples, retrying wouldn't help
+		e.buffered = e.buffered[:0]
+	}
+	return fmt.Errorf("statsview: remote-write to %s answered %s: %s", e.url, resp.Status, bytes.TrimSpace(msg))
+}
+
+// encodeWriteRequest encodes the samples as a prometheus.WriteRequest protobuf,
+// one time series per sample
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func encodeWriteRequest(samples []Sample) []byte {
+	var buf, ts, msg []byte
+	for _, s := range samples {
+		ts = ts[:0]
+
+		names := make([]string, 0, len(s.Labels)+1)
+		names = append(names, "__name__")
+		for k := range s.Labels {
+			if k != "__name__" {
+				names = append(names, k)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := s.Labels[name]
+			if name == "__name__" {
+				value = s.Name
+			}
+			msg = appendString(msg[:0], 1, name)
+			msg = appendString(msg, 2, value)
+			ts = appendBytes(ts, 1, msg)
+		}
+
+		msg = binary.AppendUvarint(msg[:0], 1<<3|1)
+		msg = binary.LittleEndian.AppendUint64(msg, math.Float64bits(s.Value))
+		msg = binary.AppendUvarint(msg, 2<<3)
+		msg = binary.AppendUvarint(msg, uint64(s.Time.UnixMilli()))
+		ts = appendBytes(ts, 2, msg)
+
+		buf = appendBytes(buf, 1, ts)
+	}
+	return buf
+}
+
+// appendBytes appends a length-delimited field
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendString(b []byte, field int, v string) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
@@ -0,0 +1,194 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// series is a decoded prometheus.TimeSeries with a single sample
+type series struct {
+	labels [][2]string
+	value  float64
+	time   int64
+}
+
+// fieldsOf decodes the fields of a protobuf message, the length-delimited
+// ones as bytes, the varints as uint64 and the fixed64 as float64
+func fieldsOf(b []byte) ([][2]interface{}, error) {
+	var fs [][2]interface{}
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid key")
+		}
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid varint")
+			}
+			fs, b = append(fs, [2]interface{}{field, v}), b[n:]
+		case 1:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("short fixed64")
+			}
+			fs, b = append(fs, [2]interface{}{field, math.Float64frombits(binary.LittleEndian.Uint64(b))}), b[8:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return nil, fmt.Errorf("invalid length")
+			}
+			fs, b = append(fs, [2]interface{}{field, b[n : n+int(l)]}), b[n+int(l):]
+		default:
+			return nil, fmt.Errorf("unexpected wire type %d", key&7)
+		}
+	}
+	return fs, nil
+}
+
+func decodeWriteRequest(t *testing.T, b []byte) []series {
+	t.Helper()
+	must := func(fs [][2]interface{}, err error) [][2]interface{} {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fs
+	}
+	var out []series
+	for _, f := range must(fieldsOf(b)) {
+		var s series
+		for _, tf := range must(fieldsOf(f[1].([]byte))) {
+			sub := must(fieldsOf(tf[1].([]byte)))
+			switch tf[0] {
+			case 1:
+				s.labels = append(s.labels, [2]string{string(sub[0][1].([]byte)), string(sub[1][1].([]byte))})
+			case 2:
+				s.value, s.time = sub[0][1].(float64), int64(sub[1][1].(uint64))
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestEncodeWriteRequest(t *testing.T) {
+	at := time.UnixMilli(1700000000123)
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    []series
+	}{
+		{"empty", nil, nil},
+		{
+			"name only",
+			[]Sample{{Name: "statsview_heap_alloc", Value: 42, Time: at}},
+			[]series{{labels: [][2]string{{"__name__", "statsview_heap_alloc"}}, value: 42, time: 1700000000123}},
+		},
+		{
+			"sorted labels",
+			[]Sample{{Name: "up", Labels: map[string]string{"service": "api", "env": "prod"}, Value: 1.5, Time: at}},
+			[]series{{labels: [][2]string{{"__name__", "up"}, {"env", "prod"}, {"service", "api"}}, value: 1.5, time: 1700000000123}},
+		},
+		{
+			"name label overridden by the name",
+			[]Sample{{Name: "up", Labels: map[string]string{"__name__": "other"}, Value: -1, Time: at}},
+			[]series{{labels: [][2]string{{"__name__", "up"}}, value: -1, time: 1700000000123}},
+		},
+		{
+			"one series per sample",
+			[]Sample{{Name: "a", Value: 1, Time: at}, {Name: "b", Value: 2, Time: at.Add(time.Second)}},
+			[]series{
+				{labels: [][2]string{{"__name__", "a"}}, value: 1, time: 1700000000123},
+				{labels: [][2]string{{"__name__", "b"}}, value: 2, time: 1700000001123},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeWriteRequest(t, encodeWriteRequest(tt.samples)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encodeWriteRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeWriteRequestBytes(t *testing.T) {
+	got := encodeWriteRequest([]Sample{{Name: "a", Value: 1, Time: time.UnixMilli(1)}})
+	want := []byte{
+		0x0a, 0x1c, // timeseries
+		0x0a, 0x0d, 0x0a, 0x08, '_', '_', 'n', 'a', 'm', 'e', '_', '_', 0x12, 0x01, 'a', // label
+		0x12, 0x0b, 0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, 0x10, 0x01, // sample
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeWriteRequest() = % x, want % x", got, want)
+	}
+}
+
+func TestRemoteWritePush(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantErr  bool
+		buffered int
+	}{
+		{"accepted", http.StatusNoContent, false, 0},
+		{"rejected", http.StatusBadRequest, true, 0},
+		{"throttled", http.StatusTooManyRequests, true, 2},
+		{"failing", http.StatusServiceUnavailable, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []series
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("X-Prometheus-Remote-Write-Version") != "0.1.0" {
+					t.Errorf("headers = %v", r.Header)
+				}
+				if r.Header.Get("X-Scope-OrgID") != "lab" {
+					t.Errorf("the configured header is missing: %v", r.Header)
+				}
+				body, _ := io.ReadAll(r.Body)
+				decoded, err := snappy.Decode(nil, body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = decodeWriteRequest(t, decoded)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			e := NewRemoteWrite(srv.URL, time.Hour)
+			e.Header.Set("X-Scope-OrgID", "lab")
+			at := time.UnixMilli(1000)
+			samples := []Sample{{Name: "a", Value: 1, Time: at}, {Name: "b", Value: 2, Time: at}}
+
+			// samples are buffered until the interval elapsed
+			if err := e.Export(context.Background(), samples); err != nil || got != nil {
+				t.Fatalf("Export() = %v and pushed %v before the interval", err, got)
+			}
+			err := e.Flush(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Flush() = %v, want error %v", err, tt.wantErr)
+			}
+			if len(got) != 2 {
+				t.Errorf("pushed %v, want 2 series", got)
+			}
+			if len(e.buffered) != tt.buffered {
+				t.Errorf("%d samples buffered, want %d", len(e.buffered), tt.buffered)
+			}
+		})
+	}
+}
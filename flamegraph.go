@@ -0,0 +1,164 @@
+package statsview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"runtime/pprof"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/mortum5/statsview/internal/flame"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// flameMinFraction prunes the frames narrower than a pixel of a wide screen
+const flameMinFraction = 0.0005
+
+var errCPUProfiling = errors.New("statsview: a CPU profile is already being captured")
+
+// flamegraph is the call tree of a captured profile
+type flamegraph struct {
+	Kind string      `json:"kind"`
+	Unit string      `json:"unit"`
+	Root *flame.Node `json:"root"`
+}
+
+// captureCPU runs the CPU profiler for d or until ctx is done
+func captureCPU(ctx context.Context, d time.Duration) (*profile.Profile, error) {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, errCPUProfiling
+	}
+	timer := time.NewTimer(d)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+	pprof.StopCPUProfile()
+	return profile.Parse(&buf)
+}
+
+// flamegraphData captures a CPU or wall-clock profile of `seconds` and
+// returns its call tree, the values are the time of the last sample type
+func (vm *ViewManager) flamegraphData(w http.ResponseWriter, r *http.Request) {
+	d, err := vm.profileDuration(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	var p *profile.Profile
+	switch kind {
+	case "", "cpu":
+		kind = "cpu"
+		p, err = captureCPU(r.Context(), d)
+	case "wallclock":
+		p, err = captureWallClock(r.Context(), d)
+	default:
+		http.Error(w, "statsview: unknown profile kind "+kind, http.StatusBadRequest)
+		return
+	}
+	switch {
+	case errors.Is(err, errCPUProfiling), errors.Is(err, errWallClockCapturing):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		viewer.Logger().Error("statsview: failed to capture profile", "profile", kind, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	i := len(p.SampleType) - 1
+	writeData(w, r, flamegraph{
+		Kind: kind,
+		Unit: p.SampleType[i].Unit,
+		Root: flame.Tree(p, i, flameMinFraction),
+	})
+}
+
+var flamegraphTpl = template.Must(template.New("flamegraph").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statsview - Flame graph</title>
+	<script src="{{ .Base }}/statics/jquery.min.js"></script>
+	<script src="{{ .Base }}/statics/echarts.min.js"></script>
+	<script src="{{ .Base }}/statics/flamegraph.js"></script>
+	<style>
+		body { font-family: sans-serif; margin: 20px 40px; }
+		#status { color: #888; margin-left: 8px; }
+		#flamegraph { width: 100%; margin-top: 12px; }
+	</style>
+</head>
+<body>
+	<h3>Flame graph</h3>
+	<p>The CPU profile samples the goroutines on CPU, the wall-clock one all of them, waiting ones included.
+		Click a frame to zoom into it, the root to zoom out.</p>
+	<label>Profile
+		<select id="kind">
+			<option value="cpu">CPU</option>
+			<option value="wallclock">wall-clock</option>
+		</select>
+	</label>
+	<label>Seconds <input id="seconds" size="3" value="10"></label>
+	<button id="capture">Capture</button>
+	<span id="status"></span>
+	<div id="flamegraph"></div>
+<script type="text/javascript">
+"use strict";
+const base = {{ .Base }};
+function duration(ns) {
+	if (ns >= 1e9) {
+		return (ns / 1e9).toFixed(2) + " s";
+	}
+	return (ns / 1e6).toFixed(2) + " ms";
+}
+// auth sends the admin token entered on the dashboard, if any
+function auth() {
+	let token = sessionStorage.getItem("statsview-token");
+	return token ? { Authorization: "Bearer " + token } : {};
+}
+function capture() {
+	let seconds = $("#seconds").val();
+	$("#capture").prop("disabled", true);
+	$("#status").text("Capturing for " + seconds + "s...");
+	$.ajax({
+		url: base + "/flamegraph/data",
+		dataType: "json",
+		data: { kind: $("#kind").val(), seconds: seconds },
+		headers: auth()
+	})
+		.done(function (r) {
+			$("#status").text(r.kind + " profile, " + duration(r.root.value) + " sampled");
+			statsview_flamegraph(document.getElementById("flamegraph"), r.root,
+				r.unit === "nanoseconds" ? duration : String);
+		})
+		.fail(function (xhr) { $("#status").text(xhr.responseText || "Capture failed"); })
+		.always(function () { $("#capture").prop("disabled", false); });
+}
+$(function () {
+	$("#capture").on("click", capture);
+});
+</script>
+</body>
+</html>
+`))
+
+func flamegraphPage(w http.ResponseWriter, r *http.Request) {
+	err := writeHTML(w, r, func(w io.Writer) error {
+		return flamegraphTpl.Execute(w, struct {
+			Base string
+		}{
+			Base: basePath(r),
+		})
+	})
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to render flame graph page", "err", err)
+	}
+}
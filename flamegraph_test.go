@@ -0,0 +1,78 @@
+package statsview
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestFlamegraphData(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		name      string
+		query     string
+		capturing bool
+		status    int
+		kind      string
+		unit      string
+	}{
+		{name: "invalid seconds", query: "?seconds=-1", status: http.StatusBadRequest},
+		{name: "unknown kind", query: "?kind=heap", status: http.StatusBadRequest},
+		{name: "wall clock already capturing", query: "?kind=wallclock", capturing: true, status: http.StatusConflict},
+		{name: "cpu by default", query: "?seconds=1", status: http.StatusOK, kind: "cpu", unit: "nanoseconds"},
+		{name: "wall clock", query: "?kind=wallclock&seconds=1", status: http.StatusOK, kind: "wallclock", unit: "nanoseconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.capturing {
+				atomic.StoreInt32(&wallClockCapturing, 1)
+				defer atomic.StoreInt32(&wallClockCapturing, 0)
+			}
+			// the capture ends as the client leaves
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			r := httptest.NewRequest(http.MethodGet, "/debug/statsview/flamegraph/data"+tt.query, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+			mgr.flamegraphData(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got flamegraph
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Kind != tt.kind || got.Unit != tt.unit || got.Root == nil || got.Root.Name != "root" {
+				t.Errorf("flamegraph of %s in %s, root %+v", got.Kind, got.Unit, got.Root)
+			}
+		})
+	}
+}
+
+func TestFlamegraphPage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	flamegraphPage(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/flamegraph", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	for _, want := range []string{`src="/debug/statsview/statics/flamegraph.js"`, `const base = "/debug/statsview";`, `base + "/flamegraph/data"`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("page doesn't contain %s", want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+//go:build go1.25
+
+package statsview
+
+import (
+	"io"
+	"runtime/trace"
+	"time"
+)
+
+// flightRecorder keeps a moving window of the execution trace in memory
+type flightRecorder struct {
+	fr *trace.FlightRecorder
+}
+
+func newFlightRecorder(window time.Duration) (*flightRecorder, error) {
+	fr := trace.NewFlightRecorder(trace.FlightRecorderConfig{MinAge: window})
+	if err := fr.Start(); err != nil {
+		return nil, err
+	}
+	return &flightRecorder{fr: fr}, nil
+}
+
+func (r *flightRecorder) writeTo(w io.Writer) error {
+	_, err := r.fr.WriteTo(w)
+	return err
+}
+
+func (r *flightRecorder) stop() {
+	r.fr.Stop()
+}
@@ -0,0 +1,23 @@
+//go:build !go1.25
+
+package statsview
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var errFlightRecorderUnsupported = errors.New("statsview: flight recorder requires go1.25 or later")
+
+type flightRecorder struct{}
+
+func newFlightRecorder(time.Duration) (*flightRecorder, error) {
+	return nil, errFlightRecorderUnsupported
+}
+
+func (r *flightRecorder) writeTo(io.Writer) error {
+	return errFlightRecorderUnsupported
+}
+
+func (r *flightRecorder) stop() {}
@@ -0,0 +1,216 @@
+// Package gate compares the aggregates of two recorded runs, session files
+// written by statsview's Export or JSON of its `/debug/statsview/summary`,
+// and reports the metrics which regressed beyond their thresholds, e.g. to
+// fail a CI pipeline when the max heap grew by more than 10%.
+//
+//	base, err := gate.Load("main.session")
+//	head, err := gate.Load("branch.session")
+//	rule, err := gate.ParseRule("heap.Alloc:max:+10%")
+//	results, err := gate.Check(base, head, []gate.Rule{rule})
+package gate
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Stats are the aggregates of a series in base units
+type Stats struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Last   float64 `json:"last"`
+}
+
+// Summary is the aggregates of a run keyed by target, `viewer.series`
+type Summary map[string]Stats
+
+// stats are the aggregates compared by the rules
+var stats = map[string]func(Stats) float64{
+	"min":    func(s Stats) float64 { return s.Min },
+	"max":    func(s Stats) float64 { return s.Max },
+	"mean":   func(s Stats) float64 { return s.Mean },
+	"stddev": func(s Stats) float64 { return s.StdDev },
+	"last":   func(s Stats) float64 { return s.Last },
+}
+
+// Load reads a session file or a summary JSON file, the gzipped files are
+// sessions
+func Load(path string) (Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic, err := r.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("gate: invalid session file %s: %w", path, err)
+		}
+		var s struct {
+			Samples []struct {
+				Viewer string    `json:"viewer"`
+				Series string    `json:"series"`
+				Values []float64 `json:"values"`
+			} `json:"samples"`
+		}
+		if err := json.NewDecoder(gz).Decode(&s); err != nil {
+			return nil, fmt.Errorf("gate: invalid session file %s: %w", path, err)
+		}
+		sum := make(Summary, len(s.Samples))
+		for _, samples := range s.Samples {
+			if st, ok := aggregate(samples.Values); ok {
+				sum[samples.Viewer+"."+samples.Series] = st
+			}
+		}
+		return sum, nil
+	}
+
+	var s struct {
+		Series []struct {
+			Target string `json:"target"`
+			Stats
+		} `json:"series"`
+	}
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("gate: invalid summary file %s: %w", path, err)
+	}
+	sum := make(Summary, len(s.Series))
+	for _, series := range s.Series {
+		sum[series.Target] = series.Stats
+	}
+	return sum, nil
+}
+
+// aggregate returns the Stats of the values like the summary endpoint, NaN
+// and infinite values are skipped
+func aggregate(values []float64) (Stats, bool) {
+	var (
+		s        Stats
+		n        int
+		mean, m2 float64
+	)
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		if n == 0 || v < s.Min {
+			s.Min = v
+		}
+		if n == 0 || v > s.Max {
+			s.Max = v
+		}
+		n++
+		d := v - mean
+		mean += d / float64(n)
+		m2 += d * (v - mean)
+		s.Last = v
+	}
+	if n == 0 {
+		return s, false
+	}
+	s.Mean, s.StdDev = mean, math.Sqrt(m2/float64(n))
+	return s, true
+}
+
+// Rule bounds the change of an aggregate of a target from the base run to
+// the head run. A positive Limit bounds the increase, a negative one the
+// decrease, relative to the base value when Relative is set
+type Rule struct {
+	Target   string
+	Stat     string
+	Limit    float64
+	Relative bool
+}
+
+// String returns the rule as parsed by ParseRule
+func (r Rule) String() string {
+	limit := strconv.FormatFloat(r.Limit, 'g', -1, 64)
+	if r.Relative {
+		limit = strconv.FormatFloat(r.Limit*100, 'g', -1, 64) + "%"
+	}
+	if r.Limit >= 0 {
+		limit = "+" + limit
+	}
+	return r.Target + ":" + r.Stat + ":" + limit
+}
+
+// ParseRule parses `target:stat:limit`, e.g. `heap.Alloc:max:+10%` fails
+// when the max heap grew by more than 10% and `goroutine.Goroutines:last:+50`
+// when 50 goroutines more are left. The stat is min, max, mean, stddev or last
+func ParseRule(s string) (Rule, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return Rule{}, fmt.Errorf("gate: rule %q isn't target:stat:limit", s)
+	}
+	r := Rule{Target: parts[0], Stat: parts[1]}
+	if _, ok := stats[r.Stat]; !ok {
+		return Rule{}, fmt.Errorf("gate: unknown stat %q of rule %q", r.Stat, s)
+	}
+	limit := parts[2]
+	if strings.HasSuffix(limit, "%") {
+		limit, r.Relative = strings.TrimSuffix(limit, "%"), true
+	}
+	v, err := strconv.ParseFloat(limit, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return Rule{}, fmt.Errorf("gate: invalid limit %q of rule %q", parts[2], s)
+	}
+	if r.Relative {
+		v /= 100
+	}
+	r.Limit = v
+	return r, nil
+}
+
+// Result is the outcome of a rule, Change is relative to Base for the
+// relative rules
+type Result struct {
+	Rule      Rule
+	Base      float64
+	Head      float64
+	Change    float64
+	Regressed bool
+}
+
+// Check applies the rules to the runs, a target missing from a run fails
+func Check(base, head Summary, rules []Rule) ([]Result, error) {
+	results := make([]Result, 0, len(rules))
+	for _, r := range rules {
+		b, ok := base[r.Target]
+		if !ok {
+			return nil, fmt.Errorf("gate: %s isn't recorded in the base run", r.Target)
+		}
+		h, ok := head[r.Target]
+		if !ok {
+			return nil, fmt.Errorf("gate: %s isn't recorded in the head run", r.Target)
+		}
+		stat := stats[r.Stat]
+		res := Result{Rule: r, Base: stat(b), Head: stat(h)}
+		res.Change = res.Head - res.Base
+		if r.Relative {
+			switch {
+			case res.Base != 0:
+				res.Change /= math.Abs(res.Base)
+			case res.Change != 0:
+				res.Change = math.Copysign(math.Inf(1), res.Change)
+			}
+		}
+		if r.Limit >= 0 {
+			res.Regressed = res.Change > r.Limit
+		} else {
+			res.Regressed = res.Change < r.Limit
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
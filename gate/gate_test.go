@@ -0,0 +1,163 @@
+package gate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rule
+		wantErr bool
+	}{
+		{in: "heap.Alloc:max:+10%", want: Rule{Target: "heap.Alloc", Stat: "max", Limit: 0.1, Relative: true}},
+		{in: "goroutine.Goroutines:last:+50", want: Rule{Target: "goroutine.Goroutines", Stat: "last", Limit: 50}},
+		{in: "gc.Rate:mean:-5%", want: Rule{Target: "gc.Rate", Stat: "mean", Limit: -0.05, Relative: true}},
+		{in: "heap.Alloc:stddev:0", want: Rule{Target: "heap.Alloc", Stat: "stddev"}},
+		{in: "heap.Alloc:max", wantErr: true},
+		{in: "heap.Alloc:p99:+10%", wantErr: true},
+		{in: "heap.Alloc:max:ten", wantErr: true},
+		{in: "heap.Alloc:max:NaN", wantErr: true},
+		{in: "heap.Alloc:max:+Inf%", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRule(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRule() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseRule() = %+v, want %+v", got, tt.want)
+			}
+			if again, err := ParseRule(got.String()); err != nil || again != got {
+				t.Errorf("ParseRule(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Stats
+		ok     bool
+	}{
+		{name: "empty"},
+		{name: "only NaN", values: []float64{math.NaN(), math.Inf(-1)}},
+		{name: "one value", values: []float64{3}, want: Stats{Min: 3, Max: 3, Mean: 3, Last: 3}, ok: true},
+		{name: "values", values: []float64{2, 4, 4, 4, 5, 5, 7, 9}, want: Stats{Min: 2, Max: 9, Mean: 5, StdDev: 2, Last: 9}, ok: true},
+		{name: "NaN skipped", values: []float64{1, math.NaN(), 3, math.Inf(1)}, want: Stats{Min: 1, Max: 3, Mean: 2, StdDev: 1, Last: 3}, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := aggregate(tt.values)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("aggregate() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	var session bytes.Buffer
+	gz := gzip.NewWriter(&session)
+	gz.Write([]byte(`{"version":1,"samples":[
+		{"viewer":"heap","series":"Alloc","times":[1,2,3],"values":[10,20,30]},
+		{"viewer":"gc","series":"Rate","times":[],"values":[]}]}`))
+	gz.Close()
+	corrupted := append([]byte{}, session.Bytes()[:12]...)
+
+	dir := t.TempDir()
+	write := func(name string, b []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		path    string
+		want    Summary
+		wantErr bool
+	}{
+		{
+			name: "session",
+			path: write("run.session", session.Bytes()),
+			want: Summary{"heap.Alloc": {Min: 10, Max: 30, Mean: 20, StdDev: math.Sqrt(200.0 / 3), Last: 30}},
+		},
+		{
+			name: "summary",
+			path: write("summary.json", []byte(`{"series":[{"target":"heap.Alloc","min":1,"max":5,"mean":3,"stddev":2,"last":4}]}`)),
+			want: Summary{"heap.Alloc": {Min: 1, Max: 5, Mean: 3, StdDev: 2, Last: 4}},
+		},
+		{name: "corrupted session", path: write("corrupted.session", corrupted), wantErr: true},
+		{name: "invalid summary", path: write("invalid.json", []byte("series")), wantErr: true},
+		{name: "missing", path: filepath.Join(dir, "missing.json"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	base := Summary{"heap.Alloc": {Max: 100, Last: 10}, "gc.Rate": {Mean: 0}}
+	head := Summary{"heap.Alloc": {Max: 115, Last: 60}, "gc.Rate": {Mean: 2}, "only.Head": {}}
+
+	tests := []struct {
+		name    string
+		rule    string
+		want    Result
+		wantErr bool
+	}{
+		{name: "relative regression", rule: "heap.Alloc:max:+10%", want: Result{Base: 100, Head: 115, Change: 0.15, Regressed: true}},
+		{name: "relative within", rule: "heap.Alloc:max:+20%", want: Result{Base: 100, Head: 115, Change: 0.15}},
+		{name: "absolute regression", rule: "heap.Alloc:last:+40", want: Result{Base: 10, Head: 60, Change: 50, Regressed: true}},
+		{name: "absolute within", rule: "heap.Alloc:last:+50", want: Result{Base: 10, Head: 60, Change: 50}},
+		{name: "decrease bounded", rule: "heap.Alloc:max:-10%", want: Result{Base: 100, Head: 115, Change: 0.15}},
+		{name: "relative to zero", rule: "gc.Rate:mean:+1000%", want: Result{Base: 0, Head: 2, Change: math.Inf(1), Regressed: true}},
+		{name: "missing in base", rule: "only.Head:max:+10%", wantErr: true},
+		{name: "missing in head", rule: "gc.Pause:max:+10%", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := Check(base, head, []Rule{rule})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := results[0]
+			tt.want.Rule = rule
+			if math.Abs(got.Change-tt.want.Change) < 1e-9 {
+				got.Change = tt.want.Change
+			}
+			if got != tt.want {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
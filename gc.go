@@ -0,0 +1,136 @@
+package statsview
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"runtime/debug"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+)
+
+// gcSettings is the GC tuning reported by `/debug/statsview/gc`, GOGC is -1
+// when the GC is off and GOMEMLIMIT is math.MaxInt64 when there is no limit
+type gcSettings struct {
+	GOGC       int64 `json:"gogc"`
+	GOMEMLIMIT int64 `json:"gomemlimit"`
+}
+
+// gcTuningState is the GC tuning in the GOGC and GOMEMLIMIT syntax
+type gcTuningState struct {
+	GOGC       string     `json:"gogc"`
+	GOMEMLIMIT string     `json:"gomemlimit"`
+	Raw        gcSettings `json:"raw"`
+}
+
+func readGCSettings() gcSettings {
+	samples := []metrics.Sample{{Name: "/gc/gogc:percent"}, {Name: "/gc/gomemlimit:bytes"}}
+	metrics.Read(samples)
+	return gcSettings{
+		GOGC:       int64(samples[0].Value.Uint64()),
+		GOMEMLIMIT: int64(samples[1].Value.Uint64()),
+	}
+}
+
+// parseGOGC parses the GOGC syntax, a percentage or `off`
+func parseGOGC(s string) (int, error) {
+	if s == "off" {
+		return -1, nil
+	}
+	p, err := strconv.Atoi(s)
+	if err != nil || p < 0 {
+		return 0, fmt.Errorf("statsview: invalid gogc %q", s)
+	}
+	return p, nil
+}
+
+var byteSuffixes = []struct {
+	suffix string
+	factor int64
+}{
+	{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}, {"B", 1},
+}
+
+// parseGOMEMLIMIT parses the GOMEMLIMIT syntax, bytes with an optional
+// B/KiB/MiB/GiB/TiB suffix or `off`
+func parseGOMEMLIMIT(s string) (int64, error) {
+	if s == "off" {
+		return math.MaxInt64, nil
+	}
+	num, factor := s, int64(1)
+	for _, b := range byteSuffixes {
+		if n, ok := strings.CutSuffix(s, b.suffix); ok {
+			num, factor = n, b.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/factor {
+		return 0, fmt.Errorf("statsview: invalid gomemlimit %q", s)
+	}
+	return n * factor, nil
+}
+
+// formatBytes formats n in the GOMEMLIMIT syntax with the largest exact suffix
+func formatBytes(n int64) string {
+	if n == math.MaxInt64 {
+		return "off"
+	}
+	for _, b := range byteSuffixes {
+		if n >= b.factor && n%b.factor == 0 {
+			return strconv.FormatInt(n/b.factor, 10) + b.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func formatGOGC(p int64) string {
+	if p < 0 {
+		return "off"
+	}
+	return strconv.FormatInt(p, 10)
+}
+
+// gcTuning reports GOGC and GOMEMLIMIT, an authenticated POST with `gogc`
+// and/or `gomemlimit` changes them and annotates the charts
+func (vm *ViewManager) gcTuning(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		var (
+			gogc     = r.FormValue("gogc")
+			memLimit = r.FormValue("gomemlimit")
+			percent  int
+			limit    int64
+			err      error
+		)
+		if gogc != "" {
+			if percent, err = parseGOGC(gogc); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if memLimit != "" {
+			if limit, err = parseGOMEMLIMIT(memLimit); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		prev := readGCSettings()
+		if gogc != "" && int64(percent) != prev.GOGC {
+			debug.SetGCPercent(percent)
+			vm.Annotate(fmt.Sprintf("GOGC %s → %s", formatGOGC(prev.GOGC), formatGOGC(int64(percent))))
+		}
+		if memLimit != "" && limit != prev.GOMEMLIMIT {
+			debug.SetMemoryLimit(limit)
+			vm.Annotate(fmt.Sprintf("GOMEMLIMIT %s → %s", formatBytes(prev.GOMEMLIMIT), formatBytes(limit)))
+		}
+	}
+
+	s := readGCSettings()
+	writeData(w, r, gcTuningState{
+		GOGC:       formatGOGC(s.GOGC),
+		GOMEMLIMIT: formatBytes(s.GOMEMLIMIT),
+		Raw:        s,
+	})
+}
@@ -0,0 +1,135 @@
+package statsview
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestParseGOMEMLIMIT(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"off", math.MaxInt64, true},
+		{"0", 0, true},
+		{"1024", 1024, true},
+		{"512B", 512, true},
+		{"64KiB", 64 << 10, true},
+		{"512MiB", 512 << 20, true},
+		{"2GiB", 2 << 30, true},
+		{"1TiB", 1 << 40, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"1.5GiB", 0, false},
+		{"1GB", 0, false},
+		{"MiB", 0, false},
+		{"9000000TiB", 0, false},
+		{"OFF", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseGOMEMLIMIT(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseGOMEMLIMIT(%q) = %d, %v, want %d, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{math.MaxInt64, "off"},
+		{0, "0"},
+		{1000, "1000B"},
+		{1536, "1536B"},
+		{512 << 20, "512MiB"},
+		{3 << 30, "3GiB"},
+	}
+	for _, tt := range tests {
+		got := formatBytes(tt.n)
+		if got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+		if back, err := parseGOMEMLIMIT(got); err != nil || back != tt.n {
+			t.Errorf("parseGOMEMLIMIT(%q) = %d, %v, want %d", got, back, err, tt.n)
+		}
+	}
+}
+
+func TestGCTuning(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(-1))
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+
+	tests := []struct {
+		name     string
+		token    string
+		auth     string
+		form     url.Values
+		status   int
+		gogc     string
+		memLimit string
+	}{
+		{name: "no token configured", auth: "Bearer s3cret", form: url.Values{"gogc": {"50"}}, status: http.StatusForbidden},
+		{name: "no credentials", token: "s3cret", form: url.Values{"gogc": {"50"}}, status: http.StatusUnauthorized},
+		{name: "wrong token", token: "s3cret", auth: "Bearer nope", form: url.Values{"gogc": {"50"}}, status: http.StatusUnauthorized},
+		{name: "invalid gogc", token: "s3cret", auth: "Bearer s3cret", form: url.Values{"gogc": {"-5"}}, status: http.StatusBadRequest},
+		{name: "invalid gomemlimit", token: "s3cret", auth: "Bearer s3cret", form: url.Values{"gomemlimit": {"1GB"}}, status: http.StatusBadRequest},
+		{name: "gogc", token: "s3cret", auth: "Bearer s3cret", form: url.Values{"gogc": {"50"}}, status: http.StatusOK, gogc: "50", memLimit: "off"},
+		{
+			name: "both", token: "s3cret", auth: "Bearer s3cret",
+			form:   url.Values{"gogc": {"off"}, "gomemlimit": {"512MiB"}},
+			status: http.StatusOK, gogc: "off", memLimit: "512MiB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			debug.SetGCPercent(100)
+			debug.SetMemoryLimit(math.MaxInt64)
+			viewer.SetConfiguration(viewer.WithAdminToken(tt.token))
+			mgr, err := New(Viewers{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/debug/statsview/gc", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("X-Requested-With", "XMLHttpRequest")
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				if len(mgr.annotations) != 0 {
+					t.Errorf("annotations = %v, want none", mgr.annotations)
+				}
+				return
+			}
+
+			var got struct{ GOGC, GOMEMLIMIT string }
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.GOGC != tt.gogc || got.GOMEMLIMIT != tt.memLimit {
+				t.Errorf("settings = %+v, want gogc %s, gomemlimit %s", got, tt.gogc, tt.memLimit)
+			}
+			if want := len(tt.form); len(mgr.annotations) != want {
+				t.Errorf("annotations = %v, want %d", mgr.annotations, want)
+			}
+		})
+	}
+}
@@ -4,8 +4,13 @@ go 1.21.0
 
 require (
 	github.com/go-echarts/go-echarts/v2 v2.2.3
+	github.com/golang/snappy v0.0.4
+	github.com/google/pprof v0.0.0-20240409012703-83162a5b38cd
+	github.com/pelletier/go-toml/v2 v2.2.3
 	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c
 	github.com/rs/cors v1.7.0
+	golang.org/x/image v0.18.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/sys v0.1.0 // indirect
+require golang.org/x/sys v0.10.0 // indirect
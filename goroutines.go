@@ -0,0 +1,271 @@
+package statsview
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/internal/goroutine"
+	"github.com/mortum5/statsview/viewer"
+)
+
+var goroutinesTpl = template.Must(template.New("goroutines").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statsview - Goroutines</title>
+	<script src="{{ .Base }}/statics/jquery.min.js"></script>
+	<style>
+		body { font-family: sans-serif; margin: 20px 40px; }
+		summary { cursor: pointer; padding: 4px 0; }
+		.count { display: inline-block; min-width: 60px; font-weight: bold; }
+		.states { color: #888; margin-left: 8px; }
+		pre { background: #f6f6f6; padding: 8px; margin: 4px 0 8px 60px; }
+	</style>
+</head>
+<body>
+	<h3>Goroutines <span id="total"></span></h3>
+	<label>Group by
+		<select id="by">
+			<option value="stack">stack</option>
+			<option value="creator">creation site</option>
+		</select>
+	</label>
+	<div id="groups"></div>
+<script type="text/javascript">
+"use strict";
+const base = {{ .Base }};
+let opened = {};
+function groups_sync() {
+	$.getJSON(base + "/goroutines/groups?by=" + $("#by").val(), function (result) {
+		$("#groups details").each(function () { opened[$(this).data("key")] = this.open; });
+		let box = $("<div>");
+		let total = 0;
+		for (const g of result) {
+			total += g.count;
+			let states = Object.entries(g.states).map(([s, n]) => s + ": " + n).join(", ");
+			let top = g.stack.length > 0 ? g.stack[0].func : "";
+			let stack = g.stack.map(f => f.func + "\n\t" + f.file).join("\n");
+			if (g.createdBy) {
+				stack += "\ncreated by " + g.createdBy.func + "\n\t" + g.createdBy.file;
+			}
+			let d = $("<details>").attr("data-key", g.key).prop("open", !!opened[g.key]);
+			d.append($("<summary>")
+				.append($("<span class='count'>").text(g.count))
+				.append($("<span>").text(top))
+				.append($("<span class='states'>").text(states)));
+			d.append($("<pre>").text(stack));
+			box.append(d);
+		}
+		$("#total").text("(" + total + ")");
+		$("#groups").replaceWith(box.attr("id", "groups"));
+	});
+}
+$(function () {
+	$("#by").change(groups_sync);
+	groups_sync();
+	setInterval(groups_sync, {{ .Interval }});
+});
+</script>
+</body>
+</html>
+`))
+
+func goroutinesPage(w http.ResponseWriter, r *http.Request) {
+	err := writeHTML(w, r, func(w io.Writer) error {
+		return goroutinesTpl.Execute(w, struct {
+			Base     string
+			Interval int
+		}{
+			Base:     basePath(r),
+			Interval: viewer.Interval(),
+		})
+	})
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to render goroutines page", "err", err)
+	}
+}
+
+func goroutineGroups(w http.ResponseWriter, r *http.Request) {
+	gs, err := goroutine.Capture()
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to capture goroutines", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	by := r.URL.Query().Get("by")
+	if by != goroutine.ByCreator {
+		by = goroutine.ByStack
+	}
+
+	writeData(w, r, goroutine.Aggregate(gs, by))
+}
+
+// leakSampleEvery bounds how often the goroutines are sampled for the leak
+// detection, the dashboard polls the suspects while it's open
+const leakSampleEvery = 10 * time.Second
+
+// leakSampler samples the goroutines lazily, only while they're looked at
+type leakSampler struct {
+	mu       sync.Mutex
+	sampled  time.Time
+	detector *goroutine.LeakDetector
+}
+
+func (s *leakSampler) suspects() ([]goroutine.Leak, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.detector == nil {
+		s.detector = goroutine.NewLeakDetector()
+	}
+	if time.Since(s.sampled) >= leakSampleEvery {
+		gs, err := goroutine.Capture()
+		if err != nil {
+			return nil, err
+		}
+		s.detector.Record(goroutine.Aggregate(gs, goroutine.ByCreator))
+		s.sampled = time.Now()
+	}
+	return s.detector.Suspects(), nil
+}
+
+// goroutineLeaks returns the creation sites suspected to leak goroutines
+func (vm *ViewManager) goroutineLeaks(w http.ResponseWriter, r *http.Request) {
+	leaks, err := vm.leaks.suspects()
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to capture goroutines", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeData(w, r, leaks)
+}
+
+// stuckSampleEvery bounds how often the goroutines are diffed for the stuck
+// detection, the dashboard polls the stuck goroutines while it's open
+const stuckSampleEvery = 10 * time.Second
+
+// stuckSampler samples the goroutines lazily, only while they're looked at
+type stuckSampler struct {
+	mu       sync.Mutex
+	sampled  time.Time
+	detector *goroutine.StuckDetector
+}
+
+func (s *stuckSampler) stuck() ([]goroutine.Stuck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.detector == nil {
+		s.detector = goroutine.NewStuckDetector()
+	}
+	if time.Since(s.sampled) >= stuckSampleEvery {
+		gs, err := goroutine.Capture()
+		if err != nil {
+			return nil, err
+		}
+		s.sampled = time.Now()
+		s.detector.Record(gs, s.sampled)
+	}
+	return s.detector.Stuck(viewer.StuckThreshold()), nil
+}
+
+// goroutinesStuck returns the sites where goroutines are blocked for longer
+// than the stuck threshold
+func (vm *ViewManager) goroutinesStuck(w http.ResponseWriter, r *http.Request) {
+	stuck, err := vm.stuck.stuck()
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to capture goroutines", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeData(w, r, stuck)
+}
+
+var stuckTpl = template.Must(template.New("stuck").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statsview - Stuck goroutines</title>
+	<script src="{{ .Base }}/statics/jquery.min.js"></script>
+	<style>
+		body { font-family: sans-serif; margin: 20px 40px; }
+		summary { cursor: pointer; padding: 4px 0; }
+		.count { display: inline-block; min-width: 60px; font-weight: bold; }
+		.blocked { display: inline-block; min-width: 100px; color: #c23531; }
+		.states { color: #888; margin-left: 8px; }
+		pre { background: #f6f6f6; padding: 8px; margin: 4px 0 8px 60px; }
+	</style>
+</head>
+<body>
+	<h3>Stuck goroutines <span id="total"></span></h3>
+	<p>Goroutines blocked on a channel, a select or a lock at the same stack for longer than {{ .Threshold }},
+		the goroutines are diffed every {{ .Every }} while this page or the dashboard is open.
+		Many goroutines stuck at the same site hint at a deadlock.</p>
+	<div id="stuck"></div>
+<script type="text/javascript">
+"use strict";
+const base = {{ .Base }};
+let opened = {};
+function duration(seconds) {
+	if (seconds < 60) {
+		return Math.round(seconds) + "s";
+	}
+	if (seconds < 3600) {
+		return Math.floor(seconds / 60) + "m" + Math.round(seconds % 60) + "s";
+	}
+	return Math.floor(seconds / 3600) + "h" + Math.floor(seconds % 3600 / 60) + "m";
+}
+function stuck_sync() {
+	$.getJSON(base + "/goroutines/stuck", function (result) {
+		$("#stuck details").each(function () { opened[$(this).data("key")] = this.open; });
+		let box = $("<div>");
+		let total = 0;
+		for (const s of result) {
+			total += s.count;
+			let top = s.stack.length > 0 ? s.stack[0].func : "";
+			let stack = "goroutines " + s.ids.join(", ") + "\n\n" + s.stack.map(f => f.func + "\n\t" + f.file).join("\n");
+			if (s.createdBy) {
+				stack += "\ncreated by " + s.createdBy.func + "\n\t" + s.createdBy.file;
+			}
+			let d = $("<details>").attr("data-key", s.key).prop("open", !!opened[s.key]);
+			d.append($("<summary>")
+				.append($("<span class='count'>").text(s.count))
+				.append($("<span class='blocked'>").text(duration(s.blocked)))
+				.append($("<span>").text(top))
+				.append($("<span class='states'>").text(s.state)));
+			d.append($("<pre>").text(stack));
+			box.append(d);
+		}
+		$("#total").text("(" + total + ")");
+		$("#stuck").replaceWith(box.attr("id", "stuck"));
+	});
+}
+$(function () {
+	stuck_sync();
+	setInterval(stuck_sync, {{ .Every.Milliseconds }});
+});
+</script>
+</body>
+</html>
+`))
+
+func stuckPage(w http.ResponseWriter, r *http.Request) {
+	err := writeHTML(w, r, func(w io.Writer) error {
+		return stuckTpl.Execute(w, struct {
+			Base      string
+			Every     time.Duration
+			Threshold time.Duration
+		}{
+			Base:      basePath(r),
+			Every:     stuckSampleEvery,
+			Threshold: viewer.StuckThreshold(),
+		})
+	})
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to render stuck goroutines page", "err", err)
+	}
+}
@@ -0,0 +1,148 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/internal/goroutine"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// blockOnChan blocks on ch, its goroutines are stuck at the same site
+func blockOnChan(ch chan struct{}) {
+	<-ch
+}
+
+func TestLeakSampler(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     time.Duration
+		sampled bool
+	}{
+		{"first sample", 0, true},
+		{"within the period", time.Second, false},
+		{"after the period", leakSampleEvery, true},
+	}
+	var s leakSampler
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !s.sampled.IsZero() {
+				s.sampled = time.Now().Add(-tt.age)
+			}
+			before := s.sampled
+			leaks, err := s.suspects()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(leaks) != 0 {
+				t.Errorf("got %d suspects before enough samples", len(leaks))
+			}
+			if sampled := !s.sampled.Equal(before); sampled != tt.sampled {
+				t.Errorf("sampled = %v, want %v", sampled, tt.sampled)
+			}
+		})
+	}
+}
+
+func TestGoroutineLeaks(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	rec := httptest.NewRecorder()
+	mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/goroutines/leaks", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != "[]" {
+		t.Errorf("body = %s, want no suspects yet", got)
+	}
+}
+
+func TestStuckSampler(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithStuckThreshold(0))
+	viewer.SetConfiguration(viewer.WithStuckThreshold(time.Nanosecond))
+
+	ch := make(chan struct{})
+	defer close(ch)
+	for i := 0; i < 3; i++ {
+		go blockOnChan(ch)
+	}
+	// the goroutines block soon after they're started
+	time.Sleep(10 * time.Millisecond)
+
+	// site returns the goroutines stuck in blockOnChan
+	site := func(stuck []goroutine.Stuck) int {
+		for _, s := range stuck {
+			for _, f := range s.Stack {
+				if strings.HasSuffix(f.Func, ".blockOnChan") {
+					return s.Count
+				}
+			}
+		}
+		return 0
+	}
+
+	tests := []struct {
+		name    string
+		age     time.Duration
+		sampled bool
+		stuck   int
+	}{
+		{"first sample", 0, true, 0},
+		{"within the period", time.Second, false, 0},
+		{"after the period", stuckSampleEvery, true, 3},
+	}
+	var s stuckSampler
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !s.sampled.IsZero() {
+				s.sampled = time.Now().Add(-tt.age)
+			}
+			before := s.sampled
+			stuck, err := s.stuck()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sampled := !s.sampled.Equal(before); sampled != tt.sampled {
+				t.Errorf("sampled = %v, want %v", sampled, tt.sampled)
+			}
+			if got := site(stuck); got != tt.stuck {
+				t.Errorf("%d goroutines stuck in blockOnChan, want %d", got, tt.stuck)
+			}
+		})
+	}
+}
+
+func TestGoroutinesStuck(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/debug/statsview/goroutines/stuck", "[]"},
+		{"/debug/statsview/stuck", "Stuck goroutines"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("body = %s, want %s", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
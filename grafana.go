@@ -0,0 +1,165 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The endpoints below implement the Grafana SimpleJSON datasource (and the
+// JSON/Infinity ones compatible with it) over the history, so Grafana could
+// chart statsview without Prometheus in the middle. Targets are named
+// `viewer.series`, e.g. `heap.HeapAlloc`, values are in base units
+
+type grafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type grafanaQuery struct {
+	Range   grafanaRange `json:"range"`
+	Targets []struct {
+		Target string `json:"target"`
+		Type   string `json:"type"`
+	} `json:"targets"`
+	MaxDataPoints int `json:"maxDataPoints"`
+}
+
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+type grafanaTable struct {
+	Type    string              `json:"type"`
+	Columns []map[string]string `json:"columns"`
+	Rows    [][2]float64        `json:"rows"`
+}
+
+type grafanaAnnotationQuery struct {
+	Range      grafanaRange    `json:"range"`
+	Annotation json.RawMessage `json:"annotation"`
+}
+
+type grafanaAnnotation struct {
+	Annotation json.RawMessage `json:"annotation"`
+	Time       int64           `json:"time"`
+	Title      string          `json:"title"`
+	Text       string          `json:"text"`
+}
+
+// grafanaTest answers the connection test of the datasource
+func (vm *ViewManager) grafanaTest(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/debug/statsview/grafana/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write([]byte("OK"))
+}
+
+// grafanaSearch lists the recorded targets containing the searched text
+func (vm *ViewManager) grafanaSearch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Target string `json:"target"`
+	}
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	targets := []string{}
+	for _, k := range vm.history.keys() {
+		if strings.Contains(k.String(), req.Target) {
+			targets = append(targets, k.String())
+		}
+	}
+	writeData(w, r, targets)
+}
+
+// grafanaQuery returns the recorded points of the targets within the range
+// as time series, or tables when requested
+func (vm *ViewManager) grafanaQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req grafanaQuery
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results := make([]interface{}, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		name, series, ok := strings.Cut(t.Target, ".")
+		if !ok {
+			continue
+		}
+		points := downsample(vm.history.query(seriesKey{Viewer: name, Series: series}, req.Range.From, req.Range.To), req.MaxDataPoints)
+
+		if t.Type == "table" {
+			table := grafanaTable{
+				Type:    "table",
+				Columns: []map[string]string{{"text": "Time", "type": "time"}, {"text": t.Target, "type": "number"}},
+				Rows:    make([][2]float64, 0, len(points)),
+			}
+			for _, p := range points {
+				table.Rows = append(table.Rows, [2]float64{float64(p.Time.UnixMilli()), p.Value})
+			}
+			results = append(results, table)
+			continue
+		}
+
+		s := grafanaSeries{Target: t.Target, Datapoints: make([][2]float64, 0, len(points))}
+		for _, p := range points {
+			s.Datapoints = append(s.Datapoints, [2]float64{p.Value, float64(p.Time.UnixMilli())})
+		}
+		results = append(results, s)
+	}
+	writeData(w, r, results)
+}
+
+// grafanaAnnotations returns the annotations within the range
+func (vm *ViewManager) grafanaAnnotations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req grafanaAnnotationQuery
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results := []grafanaAnnotation{}
+	vm.annotationsMu.Lock()
+	for _, a := range vm.annotations {
+		if a.At.Before(req.Range.From) || a.At.After(req.Range.To) {
+			continue
+		}
+		results = append(results, grafanaAnnotation{
+			Annotation: req.Annotation,
+			Time:       a.At.UnixMilli(),
+			Title:      a.Text,
+			Text:       a.Text,
+		})
+	}
+	vm.annotationsMu.Unlock()
+	writeData(w, r, results)
+}
+
+// downsample keeps every nth point so at most max points are returned
+func downsample(points []historyPoint, max int) []historyPoint {
+	if max <= 0 || len(points) <= max {
+		return points
+	}
+	step := (len(points) + max - 1) / max
+	kept := points[:0]
+	for i := 0; i < len(points); i += step {
+		kept = append(kept, points[i])
+	}
+	return kept
+}
@@ -0,0 +1,109 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestGrafana(t *testing.T) {
+	mgr, err := New(Viewers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	epoch := time.UnixMilli(1000).UTC()
+	mgr.history = newHistory(time.Hour, 0)
+	for i := 0; i < 4; i++ {
+		at := epoch.Add(time.Duration(i) * time.Second)
+		mgr.history.record([]viewer.Point{
+			{Viewer: "heap", Series: "HeapAlloc", Value: float64(i), Time: at},
+			{Viewer: "gcnum", Series: "GcNum", Value: 1, Time: at},
+		})
+	}
+	mgr.annotations = []annotation{{At: epoch.Add(time.Second), Text: "Forced GC"}, {At: epoch.Add(time.Hour), Text: "later"}}
+
+	const rng = `"range":{"from":"1970-01-01T00:00:01Z","to":"1970-01-01T00:00:03Z"}`
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"test", http.MethodGet, "/debug/statsview/grafana/", "", http.StatusOK, "OK"},
+		{"unknown", http.MethodGet, "/debug/statsview/grafana/unknown", "", http.StatusNotFound, "404 page not found\n"},
+		{"search all", http.MethodPost, "/debug/statsview/grafana/search", "", http.StatusOK, `["gcnum.GcNum","heap.HeapAlloc"]`},
+		{"search", http.MethodPost, "/debug/statsview/grafana/search", `{"target":"heap"}`, http.StatusOK, `["heap.HeapAlloc"]`},
+		{"search invalid", http.MethodPost, "/debug/statsview/grafana/search", `{"target":`, http.StatusBadRequest, ""},
+		{
+			"query series", http.MethodPost, "/debug/statsview/grafana/query",
+			`{` + rng + `,"targets":[{"target":"heap.HeapAlloc"},{"target":"invalid"}]}`, http.StatusOK,
+			`[{"target":"heap.HeapAlloc","datapoints":[[0,1000],[1,2000],[2,3000]]}]`,
+		},
+		{
+			"query downsampled", http.MethodPost, "/debug/statsview/grafana/query",
+			`{` + rng + `,"targets":[{"target":"heap.HeapAlloc"}],"maxDataPoints":2}`, http.StatusOK,
+			`[{"target":"heap.HeapAlloc","datapoints":[[0,1000],[2,3000]]}]`,
+		},
+		{
+			"query table", http.MethodPost, "/debug/statsview/grafana/query",
+			`{` + rng + `,"targets":[{"target":"gcnum.GcNum","type":"table"}]}`, http.StatusOK,
+			`[{"type":"table","columns":[{"text":"Time","type":"time"},{"text":"gcnum.GcNum","type":"number"}],"rows":[[1000,1],[2000,1],[3000,1]]}]`,
+		},
+		{"query requires POST", http.MethodGet, "/debug/statsview/grafana/query", "", http.StatusMethodNotAllowed, ""},
+		{
+			"annotations", http.MethodPost, "/debug/statsview/grafana/annotations",
+			`{` + rng + `,"annotation":{"name":"statsview"}}`, http.StatusOK,
+			`[{"annotation":{"name":"statsview"},"time":2000,"title":"Forced GC","text":"Forced GC"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.want != "" && rec.Body.String() != tt.want {
+				t.Errorf("body = %s, want %s", rec.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	points := make([]historyPoint, 10)
+	for i := range points {
+		points[i].Value = float64(i)
+	}
+
+	tests := []struct {
+		max  int
+		want []float64
+	}{
+		{0, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{10, []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{5, []float64{0, 2, 4, 6, 8}},
+		{3, []float64{0, 4, 8}},
+		{1, []float64{0}},
+	}
+	for _, tt := range tests {
+		var got []float64
+		for _, p := range downsample(append([]historyPoint(nil), points...), tt.max) {
+			got = append(got, p.Value)
+		}
+		if len(got) > tt.max && tt.max > 0 || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("downsample(%d) = %v, want %v", tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package statsview
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// Headless runs the collection loop and the exporters without any HTTP
+// server, for batch jobs and CLIs which only want the runtime metrics
+// recorded
+type Headless struct {
+	collectors []viewer.Collector
+	exporters  []exporterEntry
+	exportWg   sync.WaitGroup
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	startErr  error
+	// initialized are the collectors to close on Stop
+	initialized []viewer.Collector
+
+	Smgr   *viewer.StatsMgr
+	Ctx    context.Context
+	Cancel context.CancelFunc
+}
+
+// NewHeadless creates a Headless collecting the collectors, the built-in
+// viewers of NewDefaultViewers without any
+func NewHeadless(collectors ...viewer.Collector) *Headless {
+	if len(collectors) == 0 {
+		for _, v := range NewDefaultViewers() {
+			if c, ok := v.(viewer.Collector); ok {
+				collectors = append(collectors, c)
+			}
+		}
+	}
+
+	h := &Headless{collectors: collectors}
+	h.Ctx, h.Cancel = context.WithCancel(context.Background())
+	h.Smgr = viewer.NewStatsMgr(h.Ctx)
+	for _, c := range h.collectors {
+		c.SetStatsMgr(h.Smgr)
+	}
+	return h
+}
+
+// AddExporter registers an exporter which receives the samples of the
+// collectors on every interval. flushTimeout bounds its Flush call during
+// Stop, zero means exporter.DefaultFlushTimeout.
+// It must be called before Start
+func (h *Headless) AddExporter(exp exporter.Exporter, flushTimeout time.Duration) {
+	h.exporters = append(h.exporters, newExporterEntry(exp, flushTimeout))
+}
+
+// lookup returns the collector of the name when it's a viewer, the composed
+// viewers reference them by name
+func (h *Headless) lookup(name string) viewer.Viewer {
+	for _, c := range h.collectors {
+		if v, ok := c.(viewer.Viewer); ok && c.Name() == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Start resolves and initializes the collectors and starts feeding the
+// exporters in the background. It fails when a collector couldn't be
+// resolved or initialized, the ones initialized before are closed again
+func (h *Headless) Start() error {
+	h.startOnce.Do(func() {
+		for _, c := range h.collectors {
+			if r, ok := c.(viewer.Resolver); ok {
+				if err := r.Resolve(h.lookup); err != nil {
+					h.startErr = err
+					return
+				}
+			}
+		}
+		for _, c := range h.collectors {
+			in, ok := c.(viewer.Initializer)
+			if !ok {
+				continue
+			}
+			if err := in.Init(h.Ctx); err != nil {
+				viewer.Logger().Error("statsview: failed to init collector", "collector", c.Name(), "err", err)
+				closeCollectors(h.initialized)
+				h.initialized = nil
+				h.startErr = err
+				return
+			}
+			h.initialized = append(h.initialized, c)
+		}
+
+		h.Smgr.Refresh()
+		h.exportWg.Add(1)
+		go func() {
+			defer h.exportWg.Done()
+			exportLoop(h.Ctx, h.Smgr, h.exporters, h.Collect)
+		}()
+		viewer.Logger().Info("statsview: headless collection started", "collectors", len(h.collectors))
+	})
+	return h.startErr
+}
+
+// Collect returns the current samples of the collectors, a panicking
+// collector is logged and left out
+func (h *Headless) Collect() []exporter.Sample {
+	var points []viewer.Point
+	for _, c := range h.collectors {
+		points = append(points, safeCollect(c)...)
+	}
+	return samplesOf(points)
+}
+
+// Stop stops the collection, the exporters get the last samples and are
+// flushed before the collectors are closed
+func (h *Headless) Stop() {
+	h.stopOnce.Do(func() {
+		h.Cancel()
+		h.exportWg.Wait()
+		// a short batch job gets its final state rather than the last interval
+		h.Smgr.Refresh()
+		flushExporters(h.exporters, h.Collect())
+		closeCollectors(h.initialized)
+		viewer.Logger().Info("statsview: headless collection stopped")
+	})
+}
+
+// safeCollect collects the points of c, a panic is logged and nothing is
+// returned
+func safeCollect(c viewer.Collector) (points []viewer.Point) {
+	defer func() {
+		if p := recover(); p != nil {
+			logPanic(c.Name(), p)
+			points = nil
+		}
+	}()
+	return c.Collect()
+}
+
+func closeCollectors(collectors []viewer.Collector) {
+	for _, c := range collectors {
+		cl, ok := c.(viewer.Closer)
+		if !ok {
+			continue
+		}
+		if err := cl.Close(); err != nil {
+			viewer.Logger().Error("statsview: failed to close collector", "collector", c.Name(), "err", err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package statsview
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mortum5/statsview/exporter"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// pointCollector is a lifecycleViewer collecting a single point
+type pointCollector struct {
+	*lifecycleViewer
+	value float64
+}
+
+func (c *pointCollector) Collect() []viewer.Point {
+	return []viewer.Point{{Viewer: c.name, Series: "Depth", Value: c.value}}
+}
+
+// sampleRecorder records the samples of its last export and its flushes
+type sampleRecorder struct {
+	mu      sync.Mutex
+	last    []exporter.Sample
+	flushes int
+}
+
+func (e *sampleRecorder) Export(_ context.Context, samples []exporter.Sample) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.last = samples
+	return nil
+}
+
+func (e *sampleRecorder) Flush(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.flushes++
+	return nil
+}
+
+func newPointCollector(name string, value float64, initErr error) *pointCollector {
+	return &pointCollector{
+		lifecycleViewer: &lifecycleViewer{Viewer: viewer.NewGoroutinesViewer(), name: name, initErr: initErr},
+		value:           value,
+	}
+}
+
+func TestNewHeadless(t *testing.T) {
+	h := NewHeadless()
+	defer h.Stop()
+
+	var want int
+	for _, v := range NewDefaultViewers() {
+		if _, ok := v.(viewer.Collector); ok {
+			want++
+		}
+	}
+	if len(h.collectors) != want || want == 0 {
+		t.Errorf("NewHeadless() collects %d collectors, want the %d default ones", len(h.collectors), want)
+	}
+}
+
+func TestHeadless(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithLogger(nil))
+	viewer.SetConfiguration(viewer.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	errInit := errors.New("init failed")
+	tests := []struct {
+		name       string
+		collectors func() []*pointCollector
+		extra      []viewer.Collector
+		err        string
+		// samples are the viewers of the samples exported on Stop
+		samples []string
+		// inits and closes are the calls per collector
+		inits, closes []int
+	}{
+		{
+			name: "collects and flushes",
+			collectors: func() []*pointCollector {
+				return []*pointCollector{newPointCollector("queue", 3, nil), newPointCollector("pool", 5, nil)}
+			},
+			samples: []string{"queue", "pool"},
+			inits:   []int{1, 1},
+			closes:  []int{1, 1},
+		},
+		{
+			name: "panicking collector left out",
+			collectors: func() []*pointCollector {
+				return []*pointCollector{newPointCollector("queue", 3, nil)}
+			},
+			extra:   []viewer.Collector{&panickingViewer{Viewer: viewer.NewGoroutinesViewer()}},
+			samples: []string{"queue"},
+			inits:   []int{1},
+			closes:  []int{1},
+		},
+		{
+			name: "init failure",
+			collectors: func() []*pointCollector {
+				return []*pointCollector{
+					newPointCollector("queue", 3, nil),
+					newPointCollector("pool", 5, errInit),
+					newPointCollector("cache", 7, nil),
+				}
+			},
+			err:    "init failed",
+			inits:  []int{1, 1, 0},
+			closes: []int{1, 0, 0},
+		},
+		{
+			name: "unresolved reference",
+			collectors: func() []*pointCollector {
+				return []*pointCollector{newPointCollector("queue", 3, nil)}
+			},
+			extra:  []viewer.Collector{viewer.Compose("concurrency", viewer.Ref(viewer.VHeap, "Alloc"))},
+			err:    "references the unknown viewer heap",
+			inits:  []int{0},
+			closes: []int{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pcs := tt.collectors()
+			var collectors []viewer.Collector
+			for _, c := range pcs {
+				collectors = append(collectors, c)
+			}
+			collectors = append(collectors, tt.extra...)
+
+			h := NewHeadless(collectors...)
+			rec := &sampleRecorder{}
+			h.AddExporter(rec, 0)
+
+			err := h.Start()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Start() = %v, want %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatalf("Start() = %v", err)
+			}
+			if again := h.Start(); !errors.Is(again, err) {
+				t.Errorf("Start() again = %v, want %v", again, err)
+			}
+			h.Stop()
+			h.Stop()
+
+			var got []string
+			for _, s := range rec.last {
+				got = append(got, s.Labels["viewer"])
+			}
+			if tt.err == "" {
+				if !equalSet(got, tt.samples) {
+					t.Errorf("exported samples of %v on Stop, want %v", got, tt.samples)
+				}
+			}
+			if rec.flushes != 1 {
+				t.Errorf("flushed %d times, want 1", rec.flushes)
+			}
+			for i, c := range pcs {
+				if c.inits != tt.inits[i] || c.closes != tt.closes[i] {
+					t.Errorf("%s: %d inits and %d closes, want %d and %d", c.name, c.inits, c.closes, tt.inits[i], tt.closes[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package statsview
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+type viewerHealth struct {
+	Errors int64 `json:"errors"`
+}
+
+type health struct {
+	Status      string                  `json:"status"`
+	Started     bool                    `json:"started"`
+	Degraded    bool                    `json:"degraded"`
+	LastPoll    *time.Time              `json:"lastPoll,omitempty"`
+	LastCollect *time.Time              `json:"lastCollect,omitempty"`
+	Viewers     map[string]viewerHealth `json:"viewers"`
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// countErrors wraps the handler of the named viewer counting 5xx responses
+func (vm *ViewManager) countErrors(name string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h(rec, r)
+		if rec.status >= http.StatusInternalServerError {
+			vm.countError(name)
+		}
+	}
+}
+
+// alive reports whether the polling loop ran within three intervals
+func (vm *ViewManager) alive() bool {
+	last := vm.Smgr.LastPoll()
+	limit := 3 * time.Duration(vm.Smgr.CurrentInterval()) * time.Millisecond
+	return !last.IsZero() && vm.Smgr.Now().Sub(last) < limit
+}
+
+func (vm *ViewManager) health() health {
+	h := health{
+		Status:   "ok",
+		Started:  atomic.LoadInt32(&vm.started) == 1,
+		Degraded: vm.Smgr.Degraded(),
+		Viewers:  make(map[string]viewerHealth),
+	}
+	if !vm.alive() {
+		h.Status = "stale"
+	}
+	if t := vm.Smgr.LastPoll(); !t.IsZero() {
+		h.LastPoll = &t
+	}
+	if t := vm.Smgr.CollectTime(); !t.IsZero() {
+		h.LastCollect = &t
+	}
+	vm.viewsMu.RLock()
+	for name, counter := range vm.viewErrors {
+		h.Viewers[name] = viewerHealth{Errors: atomic.LoadInt64(counter)}
+	}
+	vm.viewsMu.RUnlock()
+	return h
+}
+
+// healthz is the liveness endpoint, it fails when the collector stopped polling
+func (vm *ViewManager) healthz(w http.ResponseWriter, _ *http.Request) {
+	h := vm.health()
+	status := http.StatusOK
+	if h.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	viewer.WriteJSONStatus(w, status, h)
+}
+
+// readyz is the readiness endpoint, it succeeds once the manager has been
+// started and the collector polled at least once
+func (vm *ViewManager) readyz(w http.ResponseWriter, _ *http.Request) {
+	if atomic.LoadInt32(&vm.started) == 0 || vm.Smgr.LastPoll().IsZero() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
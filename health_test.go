@@ -0,0 +1,93 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestHealth(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithInterval(viewer.DefaultInterval))
+
+	tests := []struct {
+		name     string
+		interval int
+		started  bool
+		polled   bool
+		healthz  int
+		status   string
+		readyz   int
+	}{
+		{"not polled yet", 60000, false, false, http.StatusServiceUnavailable, "stale", http.StatusServiceUnavailable},
+		{"polled, not started", 10, false, true, http.StatusOK, "ok", http.StatusServiceUnavailable},
+		{"started", 10, true, true, http.StatusOK, "ok", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithInterval(tt.interval))
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			if tt.started {
+				atomic.StoreInt32(&mgr.started, 1)
+			}
+			for deadline := time.Now().Add(time.Second); tt.polled && mgr.Smgr.LastPoll().IsZero(); {
+				if time.Now().After(deadline) {
+					t.Fatal("the collector didn't poll")
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			rec := httptest.NewRecorder()
+			mgr.healthz(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/healthz", nil))
+			var h health
+			if err := json.Unmarshal(rec.Body.Bytes(), &h); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.healthz || h.Status != tt.status || h.Started != tt.started {
+				t.Errorf("healthz = %d %+v, want %d, status %s, started %v", rec.Code, h, tt.healthz, tt.status, tt.started)
+			}
+
+			rec = httptest.NewRecorder()
+			mgr.readyz(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/readyz", nil))
+			if rec.Code != tt.readyz {
+				t.Errorf("readyz = %d, want %d", rec.Code, tt.readyz)
+			}
+		})
+	}
+}
+
+func TestCountErrors(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		status int
+		want   int64
+	}{
+		{http.StatusOK, 0},
+		{http.StatusNoContent, 0},
+		{http.StatusBadRequest, 0},
+		{http.StatusInternalServerError, 1},
+		{http.StatusBadGateway, 2},
+	}
+	for _, tt := range tests {
+		h := mgr.countErrors(viewer.VGoroutine, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(tt.status)
+		})
+		h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		if got := mgr.health().Viewers[viewer.VGoroutine].Errors; got != tt.want {
+			t.Errorf("after a %d: errors = %d, want %d", tt.status, got, tt.want)
+		}
+	}
+}
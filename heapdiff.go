@@ -0,0 +1,166 @@
+package statsview
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/internal/heapprof"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// heapBaseline is the heap profile the current one is compared to
+type heapBaseline struct {
+	mu    sync.Mutex
+	at    time.Time
+	sites map[string]heapprof.Site
+}
+
+// heapDiff is the growth of the in-use memory by allocation site since the
+// baseline
+type heapDiff struct {
+	Baseline time.Time         `json:"baseline"`
+	Time     time.Time         `json:"time"`
+	Sites    []heapprof.Growth `json:"sites"`
+}
+
+// serveHeapDiff returns the growth since the baseline, a POST stores the
+// current heap profile as the baseline
+func (vm *ViewManager) serveHeapDiff(w http.ResponseWriter, r *http.Request) {
+	sites, err := heapprof.Capture()
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to sample heap profile", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+
+	vm.heapBase.mu.Lock()
+	defer vm.heapBase.mu.Unlock()
+	if r.Method == http.MethodPost {
+		vm.heapBase.at, vm.heapBase.sites = now, sites
+	}
+	if vm.heapBase.sites == nil {
+		http.Error(w, "statsview: no heap baseline, POST to store one", http.StatusNotFound)
+		return
+	}
+	writeData(w, r, heapDiff{
+		Baseline: vm.heapBase.at,
+		Time:     now,
+		Sites:    heapprof.Diff(vm.heapBase.sites, sites),
+	})
+}
+
+var heapDiffTpl = template.Must(template.New("heapdiff").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statsview - Heap diff</title>
+	<script src="{{ .Base }}/statics/jquery.min.js"></script>
+	<script src="{{ .Base }}/statics/echarts.min.js"></script>
+	<style>
+		body { font-family: sans-serif; margin: 20px 40px; }
+		table { border-collapse: collapse; }
+		th, td { padding: 4px 12px; text-align: right; }
+		th:first-child, td:first-child { text-align: left; }
+		tr:nth-child(even) { background: #f6f6f6; }
+		#status { color: #888; margin-left: 8px; }
+		#treemap { width: 100%; height: 400px; margin: 12px 0; }
+	</style>
+</head>
+<body>
+	<h3>Heap growth by allocation site</h3>
+	<p>Store a baseline of the heap profile, let the program run, then compare: the in-use memory grown since
+		the baseline by allocation site, like <code>go tool pprof -base</code>. The heap profile reflects the
+		last GC and samples one allocation every 512 KiB by default, the values are estimates.</p>
+	<button id="baseline">Store baseline</button>
+	<button id="compare">Compare</button>
+	<span id="status"></span>
+	<div id="treemap"></div>
+	<table>
+		<thead><tr><th>Site</th><th>Objects</th><th>Bytes</th></tr></thead>
+		<tbody id="sites"></tbody>
+	</table>
+<script type="text/javascript">
+"use strict";
+const base = {{ .Base }};
+const url = base + "/heap/diff";
+function bytes(n) {
+	let sign = n < 0 ? "-" : "+";
+	n = Math.abs(n);
+	for (const u of ["B", "KiB", "MiB", "GiB"]) {
+		if (n < 1024 || u === "GiB") {
+			return sign + (u === "B" ? n : n.toFixed(2)) + " " + u;
+		}
+		n /= 1024;
+	}
+}
+// pkg returns the package of a function, e.g. net/http of net/http.(*conn).serve
+function pkg(fn) {
+	let slash = fn.lastIndexOf("/");
+	let dot = fn.indexOf(".", slash + 1);
+	return dot > 0 ? fn.slice(0, dot) : fn;
+}
+function show(diff) {
+	$("#status").text("Baseline " + new Date(diff.baseline).toLocaleTimeString() +
+		", compared " + new Date(diff.time).toLocaleTimeString());
+	let body = $("<tbody id='sites'>");
+	let packages = {};
+	for (const s of diff.sites) {
+		body.append($("<tr>")
+			.append($("<td>").text(s.site))
+			.append($("<td>").text((s.objects > 0 ? "+" : "") + s.objects))
+			.append($("<td>").text(bytes(s.bytes))));
+		if (s.bytes > 0) {
+			let p = pkg(s.site);
+			packages[p] = packages[p] || { name: p, value: 0, children: [] };
+			packages[p].value += s.bytes;
+			packages[p].children.push({ name: s.site.slice(p.length + 1) || s.site, value: s.bytes });
+		}
+	}
+	$("#sites").replaceWith(body);
+	let chart = echarts.getInstanceByDom(document.getElementById("treemap")) ||
+		echarts.init(document.getElementById("treemap"));
+	chart.setOption({
+		tooltip: { formatter: function (info) { return $("<div>").text(info.name).html() + "<br>" + bytes(info.value); } },
+		series: [{
+			type: "treemap",
+			name: "Growth",
+			data: Object.values(packages),
+			leafDepth: 1,
+			levels: [{ itemStyle: { borderWidth: 2, gapWidth: 2 } }, { itemStyle: { gapWidth: 1 } }]
+		}]
+	});
+}
+// auth sends the admin token entered on the dashboard, if any
+function auth() {
+	let token = sessionStorage.getItem("statsview-token");
+	return token ? { Authorization: "Bearer " + token } : {};
+}
+function fail(xhr) {
+	$("#status").text(xhr.responseText || "Failed");
+}
+$(function () {
+	$("#baseline").on("click", function () { $.ajax({ type: "POST", url: url, headers: auth() }).done(show).fail(fail); });
+	$("#compare").on("click", function () { $.getJSON(url).done(show).fail(fail); });
+	$.getJSON(url).done(show);
+});
+</script>
+</body>
+</html>
+`))
+
+func heapDiffPage(w http.ResponseWriter, r *http.Request) {
+	err := writeHTML(w, r, func(w io.Writer) error {
+		return heapDiffTpl.Execute(w, struct {
+			Base string
+		}{
+			Base: basePath(r),
+		})
+	})
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to render heap diff page", "err", err)
+	}
+}
@@ -0,0 +1,84 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+var heapDiffRetained [][]byte
+
+//go:noinline
+func heapDiffAllocate(n int) {
+	for i := 0; i < n; i++ {
+		heapDiffRetained = append(heapDiffRetained, make([]byte, 4096))
+	}
+}
+
+func TestServeHeapDiff(t *testing.T) {
+	defer func(rate int) { runtime.MemProfileRate = rate }(runtime.MemProfileRate)
+	runtime.MemProfileRate = 1
+	defer func() { heapDiffRetained = nil }()
+
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+	viewer.SetConfiguration(viewer.WithAdminToken("s3cret"))
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	const site = "github.com/mortum5/statsview.heapDiffAllocate"
+	tests := []struct {
+		name     string
+		method   string
+		allocate int
+		status   int
+		// grown is the growth in objects of the allocation site
+		grown int64
+	}{
+		{name: "no baseline", method: http.MethodGet, status: http.StatusNotFound},
+		{name: "baseline stored", method: http.MethodPost, status: http.StatusOK},
+		{name: "grown since the baseline", method: http.MethodGet, allocate: 100, status: http.StatusOK, grown: 100},
+		{name: "baseline reset", method: http.MethodPost, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heapDiffAllocate(tt.allocate)
+			// the heap profile reports the objects as of the last completed GC
+			runtime.GC()
+			runtime.GC()
+
+			r := httptest.NewRequest(tt.method, "/debug/statsview/heap/diff", nil)
+			r.Header.Set("Authorization", "Bearer s3cret")
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var diff heapDiff
+			if err := json.Unmarshal(rec.Body.Bytes(), &diff); err != nil {
+				t.Fatal(err)
+			}
+			if diff.Baseline.IsZero() || diff.Time.Before(diff.Baseline) {
+				t.Errorf("diff at %v against the baseline of %v", diff.Time, diff.Baseline)
+			}
+			var grown int64
+			for _, g := range diff.Sites {
+				if g.Site == site {
+					grown = g.Objects
+				}
+			}
+			if grown < tt.grown || (tt.grown == 0 && grown != 0) {
+				t.Errorf("%s grew by %d objects, want %d", site, grown, tt.grown)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package statsview
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// seriesKey identifies a series of the history
+type seriesKey struct {
+	Viewer string
+	Series string
+}
+
+// String returns the series as `viewer.series`, e.g. `heap.HeapAlloc`
+func (k seriesKey) String() string {
+	return k.Viewer + "." + k.Series
+}
+
+// historyPoint is a recorded value in base units, or the mean of Count
+// values once compacted
+type historyPoint struct {
+	Time  time.Time
+	Value float64
+	Count int
+}
+
+// weight returns how many values the point stands for, 1 until it's compacted
+func (p historyPoint) weight() int {
+	if p.Count > 1 {
+		return p.Count
+	}
+	return 1
+}
+
+// history keeps the collected points of every series for a window, so
+// clients could query what happened before they connected
+type history struct {
+	mu         sync.RWMutex
+	window     time.Duration
+	maxSamples int
+	series     map[seriesKey][]historyPoint
+}
+
+func newHistory(window time.Duration, maxSamples int) *history {
+	return &history{window: window, maxSamples: maxSamples, series: make(map[seriesKey][]historyPoint)}
+}
+
+// record appends the points and drops the ones older than the window or
+// beyond the max samples
+func (h *history) record(points []viewer.Point) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, p := range points {
+		key := seriesKey{Viewer: p.Viewer, Series: p.Series}
+		s := append(h.series[key], historyPoint{Time: p.Time, Value: p.Value})
+		cutoff := p.Time.Add(-h.window)
+		i := sort.Search(len(s), func(i int) bool { return !s[i].Time.Before(cutoff) })
+		if h.maxSamples > 0 && len(s)-i > h.maxSamples {
+			i = len(s) - h.maxSamples
+		}
+		if i > 0 {
+			s = append(s[:0], s[i:]...)
+		}
+		h.series[key] = s
+	}
+}
+
+// compact drops the points older than the window at now, e.g. of the series
+// of an unregistered viewer which record doesn't trim anymore, and
+// downsamples the other ones by the tiers of their age, see compactPoints
+func (h *history) compact(now time.Time, tiers []viewer.Tier) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := now.Add(-h.window)
+	for key, s := range h.series {
+		i := sort.Search(len(s), func(i int) bool { return !s[i].Time.Before(cutoff) })
+		if i == len(s) {
+			delete(h.series, key)
+			continue
+		}
+		if i > 0 {
+			s = append(s[:0], s[i:]...)
+		}
+		if len(tiers) > 0 {
+			s = compactPoints(s, now, tiers)
+		}
+		h.series[key] = s
+	}
+}
+
+// compactPoints replaces the points older than a tier by their means of
+// every step of the oldest tier they're in, weighted by the values each
+// point stands for. The steps are aligned on the unix epoch and the means
+// keep their count, so compacting again into a coarser tier keeps the means.
+// NaN and infinite values of the compacted points are dropped
+func compactPoints(points []historyPoint, now time.Time, tiers []viewer.Tier) []historyPoint {
+	kept := points[:0]
+	var (
+		start time.Time
+		step  time.Duration
+		sum   float64
+		n     int
+	)
+	flush := func() {
+		if n > 0 {
+			kept = append(kept, historyPoint{Time: start, Value: sum / float64(n), Count: n})
+		}
+		sum, n = 0, 0
+	}
+	for _, p := range points {
+		var tier time.Duration
+		for _, t := range tiers {
+			if now.Sub(p.Time) > t.After {
+				tier = t.Step
+			}
+		}
+		if tier == 0 {
+			flush()
+			kept = append(kept, p)
+			continue
+		}
+		if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+			continue
+		}
+		if b := p.Time.Truncate(tier); n == 0 || !b.Equal(start) || tier != step {
+			flush()
+			start, step = b, tier
+		}
+		sum += p.Value * float64(p.weight())
+		n += p.weight()
+	}
+	flush()
+	return kept
+}
+
+// keys returns the recorded series sorted by name
+func (h *history) keys() []seriesKey {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	keys := make([]seriesKey, 0, len(h.series))
+	for k := range h.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
+
+// query returns a copy of the points of the series recorded within [from, to]
+func (h *history) query(key seriesKey, from, to time.Time) []historyPoint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	s := h.series[key]
+	i := sort.Search(len(s), func(i int) bool { return !s[i].Time.Before(from) })
+	j := sort.Search(len(s), func(j int) bool { return s[j].Time.After(to) })
+	if i >= j {
+		return nil
+	}
+	return append([]historyPoint(nil), s[i:j]...)
+}
+
+// collectPoints gathers the points of every collecting viewer in base units
+func (vm *ViewManager) collectPoints() []viewer.Point {
+	var points []viewer.Point
+	for _, v := range vm.views() {
+		c, ok := v.(viewer.Collector)
+		if !ok || vm.skipped(v) {
+			continue
+		}
+		points = append(points, vm.safeCollect(v, c)...)
+	}
+	return points
+}
+
+// lastCollection is the time of the last collection a loop has seen, so it
+// handles every collection once however its ticker runs
+type lastCollection struct {
+	time time.Time
+}
+
+// advanced reports whether t is a newer collection, it's seen then
+func (c *lastCollection) advanced(t time.Time) bool {
+	if !t.After(c.time) {
+		return false
+	}
+	c.time = t
+	return true
+}
+
+// historyLoop records the points of every collection, once even when the
+// ticker runs ahead of the collection, set viewer.WithAlwaysCollect to
+// record with no client around. The history is compacted by the tiers of its
+// retention in the background. The points are fed to the anomaly detection
+// too
+func (vm *ViewManager) historyLoop() {
+	interval := vm.Smgr.CurrentInterval()
+	ticker := vm.Smgr.Clock().NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+
+	retention := viewer.HistoryRetention()
+	compaction := vm.Smgr.Clock().NewTicker(retention.CompactEvery)
+	defer compaction.Stop()
+
+	var collected lastCollection
+	for {
+		select {
+		case <-ticker.C():
+			if vm.Smgr.Collecting() && collected.advanced(vm.Smgr.CollectTime()) {
+				points := vm.collectPoints()
+				if vm.history.window > 0 {
+					vm.history.record(points)
+				}
+				if vm.anomalies != nil {
+					vm.detectAnomalies(points)
+				}
+			}
+
+			if current := vm.Smgr.CurrentInterval(); current != interval {
+				interval = current
+				ticker.Reset(time.Duration(interval) * time.Millisecond)
+			}
+		case <-compaction.C():
+			vm.history.compact(vm.Smgr.Now(), retention.Tiers)
+		case <-vm.Ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package statsview
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestHistory(t *testing.T) {
+	epoch := time.Unix(0, 0).UTC()
+	at := func(s int) time.Time { return epoch.Add(time.Duration(s) * time.Second) }
+
+	h := newHistory(10*time.Second, 0)
+	for s := 0; s <= 20; s += 5 {
+		h.record([]viewer.Point{
+			{Viewer: "heap", Series: "HeapAlloc", Value: float64(s), Time: at(s)},
+			{Viewer: "gcnum", Series: "GcNum", Value: float64(s / 5), Time: at(s)},
+		})
+	}
+
+	if got, want := h.keys(), []seriesKey{{"gcnum", "GcNum"}, {"heap", "HeapAlloc"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys() = %v, want %v", got, want)
+	}
+
+	heap := seriesKey{"heap", "HeapAlloc"}
+	tests := []struct {
+		name     string
+		key      seriesKey
+		from, to time.Time
+		want     []historyPoint
+	}{
+		{"window", heap, at(0), at(20), []historyPoint{{Time: at(10), Value: 10}, {Time: at(15), Value: 15}, {Time: at(20), Value: 20}}},
+		{"inclusive range", heap, at(15), at(20), []historyPoint{{Time: at(15), Value: 15}, {Time: at(20), Value: 20}}},
+		{"within", heap, at(11), at(19), []historyPoint{{Time: at(15), Value: 15}}},
+		{"dropped", heap, at(0), at(9), nil},
+		{"empty range", heap, at(20), at(10), nil},
+		{"other series", seriesKey{"gcnum", "GcNum"}, at(20), at(20), []historyPoint{{Time: at(20), Value: 4}}},
+		{"unknown series", seriesKey{"heap", "HeapSys"}, at(0), at(20), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.query(tt.key, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("query() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryMaxSamples(t *testing.T) {
+	epoch := time.Unix(0, 0).UTC()
+	at := func(s int) time.Time { return epoch.Add(time.Duration(s) * time.Second) }
+	heap := seriesKey{"heap", "HeapAlloc"}
+
+	tests := []struct {
+		name       string
+		maxSamples int
+		want       []historyPoint
+	}{
+		{"unbounded", 0, []historyPoint{{Time: at(1), Value: 1}, {Time: at(2), Value: 2}, {Time: at(3), Value: 3}, {Time: at(4), Value: 4}}},
+		{"oldest dropped", 2, []historyPoint{{Time: at(3), Value: 3}, {Time: at(4), Value: 4}}},
+		{"within the bound", 10, []historyPoint{{Time: at(1), Value: 1}, {Time: at(2), Value: 2}, {Time: at(3), Value: 3}, {Time: at(4), Value: 4}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistory(time.Hour, tt.maxSamples)
+			for s := 1; s <= 4; s++ {
+				h.record([]viewer.Point{{Viewer: "heap", Series: "HeapAlloc", Value: float64(s), Time: at(s)}})
+			}
+			if got := h.query(heap, at(0), at(4)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("query() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompactPoints(t *testing.T) {
+	epoch := time.Unix(0, 0).UTC()
+	now := epoch.Add(time.Hour)
+	at := func(d time.Duration, v float64) historyPoint { return historyPoint{Time: epoch.Add(d), Value: v} }
+	mean := func(d time.Duration, v float64, n int) historyPoint {
+		return historyPoint{Time: epoch.Add(d), Value: v, Count: n}
+	}
+	tiers := []viewer.Tier{{After: 10 * time.Minute, Step: time.Minute}, {After: 30 * time.Minute, Step: 10 * time.Minute}}
+
+	tests := []struct {
+		name   string
+		points []historyPoint
+		want   []historyPoint
+	}{
+		{"empty", nil, []historyPoint{}},
+		{
+			"recent points kept",
+			[]historyPoint{at(55*time.Minute, 1), at(55*time.Minute+10*time.Second, 2)},
+			[]historyPoint{at(55*time.Minute, 1), at(55*time.Minute+10*time.Second, 2)},
+		},
+		{
+			"mean of a step",
+			[]historyPoint{at(40*time.Minute, 1), at(40*time.Minute+20*time.Second, 2), at(40*time.Minute+40*time.Second, 6)},
+			[]historyPoint{mean(40*time.Minute, 3, 3)},
+		},
+		{
+			"steps aligned on the epoch",
+			[]historyPoint{at(40*time.Minute+50*time.Second, 2), at(41*time.Minute+10*time.Second, 4)},
+			[]historyPoint{mean(40*time.Minute, 2, 1), mean(41*time.Minute, 4, 1)},
+		},
+		{
+			"oldest tier",
+			[]historyPoint{at(5*time.Minute, 1), at(12*time.Minute, 3), at(15*time.Minute, 5)},
+			[]historyPoint{mean(0, 1, 1), mean(10*time.Minute, 4, 2)},
+		},
+		{
+			"NaN and infinite dropped",
+			[]historyPoint{at(40*time.Minute, math.NaN()), at(40*time.Minute+10*time.Second, 2), at(40*time.Minute+20*time.Second, math.Inf(1))},
+			[]historyPoint{mean(40*time.Minute, 2, 1)},
+		},
+		{
+			"compacting again keeps the means",
+			[]historyPoint{mean(40*time.Minute, 3, 2), mean(41*time.Minute, 4, 1), at(55*time.Minute, 7)},
+			[]historyPoint{mean(40*time.Minute, 3, 2), mean(41*time.Minute, 4, 1), at(55*time.Minute, 7)},
+		},
+		{
+			"means weighted by their count",
+			[]historyPoint{mean(11*time.Minute, 2, 3), mean(12*time.Minute, 6, 1), at(12*time.Minute+30*time.Second, 8)},
+			[]historyPoint{mean(10*time.Minute, 4, 5)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := append([]historyPoint{}, tt.points...)
+			if got := compactPoints(points, now, tiers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compactPoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryCompactAgesOut(t *testing.T) {
+	start := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	point := func(series string, d time.Duration) viewer.Point {
+		return viewer.Point{Viewer: "heap", Series: series, Value: 1, Time: start.Add(d)}
+	}
+
+	tests := []struct {
+		name string
+		now  time.Duration
+		want map[string]int
+	}{
+		{"within the window", 10 * time.Minute, map[string]int{"Alloc": 3, "Inuse": 2}},
+		{"series no longer recorded", 70 * time.Minute, map[string]int{"Alloc": 1}},
+		{"every series", 2 * time.Hour, map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistory(time.Hour, 0)
+			h.record([]viewer.Point{point("Alloc", 0), point("Inuse", 0)})
+			h.record([]viewer.Point{point("Alloc", time.Minute), point("Inuse", time.Minute)})
+			h.record([]viewer.Point{point("Alloc", 30*time.Minute)})
+
+			h.compact(start.Add(tt.now), nil)
+			got := map[string]int{}
+			for key, s := range h.series {
+				got[key.Series] = len(s)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("points by series = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastCollection(t *testing.T) {
+	start := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		collected time.Time
+		want      bool
+	}{
+		{time.Time{}, false},
+		{start, true},
+		{start, false},
+		{start.Add(-time.Second), false},
+		{start.Add(time.Second), true},
+	}
+	var c lastCollection
+	for i, tt := range tests {
+		if got := c.advanced(tt.collected); got != tt.want {
+			t.Errorf("%d: advanced(%v) = %v, want %v", i, tt.collected, got, tt.want)
+		}
+	}
+}
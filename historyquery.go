@@ -0,0 +1,161 @@
+package statsview
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// historyValue is a recorded or downsampled value in base units, NaN and
+// infinite values are left out
+type historyValue struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// historySeries is the history of a series of the viewer
+type historySeries struct {
+	Series string         `json:"series"`
+	Points []historyValue `json:"points"`
+}
+
+// viewerHistory is the history of a viewer within [From, To], the points
+// are the means of Step seconds when it's positive
+type viewerHistory struct {
+	Viewer string          `json:"viewer"`
+	From   time.Time       `json:"from"`
+	To     time.Time       `json:"to"`
+	Step   float64         `json:"step"`
+	Series []historySeries `json:"series"`
+}
+
+// errInvalidTime is returned by parseTimeParam
+var errInvalidTime = errors.New("statsview: times must be RFC 3339, unix milliseconds or a duration ago")
+
+// parseTimeParam parses a time of the query: RFC 3339, unix milliseconds or
+// a duration before now, e.g. `15m`. def is returned for an empty value
+func parseTimeParam(s string, now, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errInvalidTime
+}
+
+// meanByStep averages the points of every step from `from` on, weighted by
+// the values each compacted point stands for, the empty steps are left out
+// and NaN and infinite values are skipped
+func meanByStep(points []historyPoint, from time.Time, step time.Duration) []historyValue {
+	values := []historyValue{}
+	var (
+		bucket = -1
+		sum    float64
+		n      int
+	)
+	flush := func() {
+		if n > 0 {
+			values = append(values, historyValue{
+				Time:  from.Add(time.Duration(bucket) * step),
+				Value: sum / float64(n),
+			})
+		}
+		sum, n = 0, 0
+	}
+	for _, p := range points {
+		if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+			continue
+		}
+		if b := int(p.Time.Sub(from) / step); b != bucket {
+			flush()
+			bucket = b
+		}
+		sum += p.Value * float64(p.weight())
+		n += p.weight()
+	}
+	flush()
+	return values
+}
+
+// serveHistory returns the recorded points of the viewer in base units within
+// [since, until], the whole history by default. A positive `step`
+// downsamples them to their means of every step, e.g.
+// `/history/heap?since=1h&step=1m` for 60 points a series
+func (vm *ViewManager) serveHistory(v viewer.Viewer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		window := vm.historyWindow()
+		if window <= 0 {
+			http.Error(w, "statsview: the history is disabled, see viewer.WithHistory", http.StatusNotFound)
+			return
+		}
+
+		q := r.URL.Query()
+		now := vm.Smgr.Now()
+		from, err := parseTimeParam(q.Get("since"), now, now.Add(-window))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := parseTimeParam(q.Get("until"), now, now)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if to.Before(from) {
+			http.Error(w, "statsview: until is before since", http.StatusBadRequest)
+			return
+		}
+		var step time.Duration
+		if s := q.Get("step"); s != "" {
+			step, err = time.ParseDuration(s)
+			if err != nil || step < 0 {
+				http.Error(w, "statsview: invalid step duration", http.StatusBadRequest)
+				return
+			}
+		}
+
+		keep := make(map[string]bool)
+		if names := selectedSeries(r); names != nil {
+			_, selected, err := seriesIndexes(v, names)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			for _, name := range selected {
+				keep[name] = true
+			}
+		}
+
+		res := viewerHistory{Viewer: v.Name(), From: from, To: to, Step: step.Seconds(), Series: []historySeries{}}
+		for _, k := range vm.history.keys() {
+			if k.Viewer != v.Name() || len(keep) > 0 && !keep[k.Series] {
+				continue
+			}
+			points := vm.history.query(k, from, to)
+			s := historySeries{Series: k.Series}
+			if step > 0 {
+				s.Points = meanByStep(points, from, step)
+			} else {
+				s.Points = make([]historyValue, 0, len(points))
+				for _, p := range points {
+					if !math.IsNaN(p.Value) && !math.IsInf(p.Value, 0) {
+						s.Points = append(s.Points, historyValue{Time: p.Time, Value: p.Value})
+					}
+				}
+			}
+			res.Series = append(res.Series, s)
+		}
+		writeData(w, r, res)
+	}
+}
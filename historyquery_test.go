@@ -0,0 +1,146 @@
+package statsview
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestParseTimeParam(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	def := now.Add(-time.Hour)
+	tests := []struct {
+		in   string
+		want time.Time
+		err  bool
+	}{
+		{in: "", want: def},
+		{in: "1704110400000", want: time.UnixMilli(1704110400000)},
+		{in: "15m", want: now.Add(-15 * time.Minute)},
+		{in: "0s", want: now},
+		{in: "2024-01-01T11:30:00Z", want: now.Add(-30 * time.Minute)},
+		{in: "2024-01-01T11:30:00.5+01:00", want: time.Date(2024, time.January, 1, 10, 30, 0, 5e8, time.UTC)},
+		{in: "-15m", err: true},
+		{in: "yesterday", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeParam(tt.in, now, def)
+		if (err != nil) != tt.err || !got.Equal(tt.want) {
+			t.Errorf("parseTimeParam(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestMeanByStep(t *testing.T) {
+	from := time.Unix(1700000000, 0)
+	at := func(s int) time.Time { return from.Add(time.Duration(s) * time.Second) }
+	tests := []struct {
+		name   string
+		points []historyPoint
+		step   time.Duration
+		want   []historyValue
+	}{
+		{"empty", nil, time.Minute, []historyValue{}},
+		{
+			"means",
+			[]historyPoint{{Time: at(0), Value: 1}, {Time: at(10), Value: 3}, {Time: at(60), Value: 10}, {Time: at(119), Value: 20}},
+			time.Minute,
+			[]historyValue{{Time: at(0), Value: 2}, {Time: at(60), Value: 15}},
+		},
+		{
+			"empty steps left out",
+			[]historyPoint{{Time: at(5), Value: 4}, {Time: at(185), Value: 8}},
+			time.Minute,
+			[]historyValue{{Time: at(0), Value: 4}, {Time: at(180), Value: 8}},
+		},
+		{
+			"invalid values skipped",
+			[]historyPoint{{Time: at(0), Value: math.NaN()}, {Time: at(1), Value: 6}, {Time: at(2), Value: math.Inf(1)}, {Time: at(60), Value: math.NaN()}},
+			time.Minute,
+			[]historyValue{{Time: at(0), Value: 6}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := meanByStep(tt.points, from, tt.step); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("meanByStep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeHistory(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	rec := httptest.NewRecorder()
+	mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/history/goroutine", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d without history, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	defer viewer.SetConfiguration(viewer.WithHistory(0))
+	viewer.SetConfiguration(viewer.WithHistory(time.Hour))
+	mgr, err = New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	now := mgr.Smgr.Now()
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	mgr.history.record([]viewer.Point{{Viewer: viewer.VGoroutine, Series: "Goroutines", Value: 10, Time: ago(30 * time.Minute)}})
+	mgr.history.record([]viewer.Point{{Viewer: viewer.VGoroutine, Series: "Goroutines", Value: 20, Time: ago(20 * time.Minute)}})
+	mgr.history.record([]viewer.Point{{Viewer: viewer.VGoroutine, Series: "Goroutines", Value: 40, Time: ago(19 * time.Minute)}})
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   []float64
+	}{
+		{name: "whole history", status: http.StatusOK, want: []float64{10, 20, 40}},
+		{name: "since", query: "?since=25m", status: http.StatusOK, want: []float64{20, 40}},
+		{name: "until", query: "?until=25m", status: http.StatusOK, want: []float64{10}},
+		{name: "downsampled", query: "?since=35m&step=10m", status: http.StatusOK, want: []float64{10, 30}},
+		{name: "invalid since", query: "?since=yesterday", status: http.StatusBadRequest},
+		{name: "until before since", query: "?since=10m&until=20m", status: http.StatusBadRequest},
+		{name: "invalid step", query: "?step=-1m", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/history/goroutine"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var res viewerHistory
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			var got []float64
+			for _, s := range res.Series {
+				if s.Series != "Goroutines" {
+					continue
+				}
+				for _, p := range s.Points {
+					got = append(got, p.Value)
+				}
+			}
+			if res.Viewer != viewer.VGoroutine || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("history of %s = %v, want %v", res.Viewer, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package hub
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/mortum5/statsview/statics"
+)
+
+// Handler serves the hub dashboard and its API:
+//   - GET  /                    the dashboard
+//   - GET  /api/targets         the target list
+//   - GET  /api/targets/{name}  the recent data of a target
+//   - POST /api/push?target=    a Snapshot pushed by a target with the
+//     PushToken as bearer token
+func (h *Hub) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", h.page)
+	mux.HandleFunc("/api/targets", h.serveTargets)
+	mux.HandleFunc("/api/targets/", h.serveTarget)
+	mux.HandleFunc("/api/push", h.servePush)
+	mux.Handle("/statics/echarts.min.js", statics.JS(statics.EchartJS))
+	mux.Handle("/statics/jquery.min.js", statics.JS(statics.JqueryJS))
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (h *Hub) serveTargets(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, h.Targets())
+}
+
+func (h *Hub) serveTarget(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/targets/")
+	history, err := h.History(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, history)
+}
+
+func (h *Hub) servePush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "hub: push requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.cfg.PushToken == "" {
+		http.Error(w, "hub: pushing requires a token, see Config.PushToken", http.StatusForbidden)
+		return
+	}
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.PushToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="statsview-hub"`)
+		http.Error(w, "hub: invalid push token", http.StatusUnauthorized)
+		return
+	}
+	name := r.URL.Query().Get("target")
+	if name == "" {
+		http.Error(w, "hub: missing target", http.StatusBadRequest)
+		return
+	}
+
+	var s Snapshot
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&s); err != nil {
+		http.Error(w, "hub: invalid snapshot "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.push(name, s); errors.Is(err, errTooManyTargets) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var pageTpl = template.Must(template.New("hub").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statsview Hub</title>
+	<script src="statics/echarts.min.js"></script>
+	<script src="statics/jquery.min.js"></script>
+	<style>
+		body { font-family: sans-serif; margin: 20px 40px; }
+		.box { justify-content: center; display: flex; flex-wrap: wrap; }
+		.item { width: 600px; height: 400px; }
+		#error { color: #c23531; margin-left: 8px; }
+	</style>
+</head>
+<body>
+	<h3>Statsview Hub
+		<select id="target"></select>
+		<span id="error"></span>
+	</h3>
+	<div id="charts" class="box"></div>
+<script type="text/javascript">
+"use strict";
+let charts = {};
+function compact(v) {
+	let a = Math.abs(v);
+	if (a >= 1e9) return (v / 1e9).toFixed(2) + "G";
+	if (a >= 1e6) return (v / 1e6).toFixed(2) + "M";
+	if (a >= 1e3) return (v / 1e3).toFixed(2) + "k";
+	return +v.toFixed(6);
+}
+function targets_sync() {
+	$.getJSON("api/targets", function (ts) {
+		let sel = $("#target");
+		let current = sel.val() || location.hash.substring(1);
+		sel.empty();
+		for (const t of ts) {
+			sel.append($("<option>").val(t.name).text(t.name));
+		}
+		if (current) {
+			sel.val(current);
+		}
+		let t = ts.find(t => t.name === sel.val());
+		$("#error").text(t && t.error ? t.error : "");
+	});
+}
+function charts_sync() {
+	let name = $("#target").val();
+	if (!name) {
+		return;
+	}
+	$.getJSON("api/targets/" + encodeURIComponent(name), function (viewers) {
+		for (const v of viewers) {
+			if (!charts[v.name]) {
+				let div = $("<div class='item'>").appendTo("#charts");
+				charts[v.name] = echarts.init(div[0], null);
+			}
+			charts[v.name].setOption({
+				title: { text: v.title || v.name },
+				legend: { show: true },
+				tooltip: { trigger: "axis" },
+				xAxis: { type: "time" },
+				yAxis: { type: "value", axisLabel: { formatter: compact } },
+				series: v.series.map((s, i) => ({
+					name: s, type: "line", smooth: true, showSymbol: false,
+					data: v.times.map((t, j) => [t, v.values[i][j]])
+				}))
+			});
+		}
+	});
+}
+$(function () {
+	$("#target").change(function () {
+		location.hash = $(this).val();
+		for (const c of Object.values(charts)) {
+			c.dispose();
+		}
+		charts = {};
+		$("#charts").empty();
+		charts_sync();
+	});
+	targets_sync();
+	setInterval(targets_sync, 10000);
+	setInterval(charts_sync, {{ .Interval }});
+});
+</script>
+</body>
+</html>
+`))
+
+func (h *Hub) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	pageTpl.Execute(w, struct{ Interval int64 }{Interval: h.cfg.Interval.Milliseconds()})
+}
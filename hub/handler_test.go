@@ -0,0 +1,111 @@
+package hub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+)
+
+func push(h http.Handler, target, token string) int {
+	r := httptest.NewRequest(http.MethodPost, "/api/push?target="+target, strings.NewReader(`{"time":1,"viewers":[]}`))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec.Code
+}
+
+func TestServePush(t *testing.T) {
+	tests := []struct {
+		name      string
+		pushToken string
+		token     string
+		want      int
+	}{
+		{"no token configured", "", "secret", http.StatusForbidden},
+		{"missing token", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "other", http.StatusUnauthorized},
+		{"valid token", "secret", "secret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(Config{PushToken: tt.pushToken})
+			if got := push(h.Handler(), "worker-1", tt.token); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushMaxTargets(t *testing.T) {
+	h := New(Config{PushToken: "secret", MaxTargets: 2, Targets: map[string]string{"pulled": "http://127.0.0.1:1"}})
+	handler := h.Handler()
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"a", http.StatusNoContent},
+		{"b", http.StatusNoContent},
+		{"c", http.StatusTooManyRequests},
+		{"a", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		if got := push(handler, tt.target, "secret"); got != tt.want {
+			t.Errorf("push of %s = %d, want %d", tt.target, got, tt.want)
+		}
+	}
+	if n := len(h.Targets()); n != 3 {
+		t.Errorf("%d targets, want the pulled one and 2 pushing", n)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	h := New(Config{TargetTTL: time.Minute, Targets: map[string]string{"pulled": "http://127.0.0.1:1"}})
+	h.Add("pushing", Snapshot{})
+	now := time.Now()
+
+	tests := []struct {
+		at   time.Time
+		want []string
+	}{
+		{now, []string{"pulled", "pushing"}},
+		{now.Add(2 * time.Minute), []string{"pulled"}},
+	}
+	for _, tt := range tests {
+		h.expire(tt.at)
+		var names []string
+		for _, ts := range h.Targets() {
+			names = append(names, ts.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("targets = %v, want %v", names, tt.want)
+		}
+	}
+}
+
+func TestPusher(t *testing.T) {
+	h := New(Config{PushToken: "secret"})
+	srv := httptest.NewServer(h.Handler())
+	defer srv.Close()
+
+	samples := []exporter.Sample{{Name: "heap", Labels: map[string]string{"viewer": "heap", "series": "Alloc"}, Value: 1, Time: time.Now()}}
+	tests := []struct {
+		token string
+		ok    bool
+	}{
+		{"secret", true},
+		{"other", false},
+	}
+	for _, tt := range tests {
+		err := NewPusher(srv.URL, "worker", tt.token).Export(context.Background(), samples)
+		if (err == nil) != tt.ok {
+			t.Errorf("push with token %q: %v, want ok %v", tt.token, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+// Package hub aggregates the metrics of many statsview instances into one
+// dashboard with a target selector. Targets are either pulled from their
+// `/debug/statsview/snapshot` endpoint or push their samples via Pusher.
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultInterval   = 2 * time.Second
+	DefaultMaxPoints  = 300
+	DefaultMaxTargets = 100
+	DefaultTargetTTL  = 10 * time.Minute
+)
+
+// ViewerSnapshot holds the latest values of a viewer in base units
+type ViewerSnapshot struct {
+	Name   string    `json:"name"`
+	Title  string    `json:"title"`
+	Series []string  `json:"series"`
+	Values []float64 `json:"values"`
+}
+
+// Snapshot is the state of all viewers of a target at the given unix milliseconds
+type Snapshot struct {
+	Time    int64            `json:"time"`
+	Viewers []ViewerSnapshot `json:"viewers"`
+}
+
+// Config configures the Hub
+type Config struct {
+	// Targets maps the target name to the base url of a statsview instance
+	// which is pulled, e.g. `http://10.0.0.12:18066`. Pushing targets are
+	// added on the first push
+	Targets   map[string]string
+	Interval  time.Duration
+	MaxPoints int
+	Client    *http.Client
+	// TargetTokens maps a pulled target to the bearer token sent with its
+	// pulls, e.g. the read token of the target
+	TargetTokens map[string]string
+	// TargetHeaders maps a pulled target to the headers sent with its
+	// pulls, e.g. the credentials of the proxy in front of it
+	TargetHeaders map[string]http.Header
+	// PushToken is the bearer token the pushing targets must send, pushes
+	// are refused when it's empty
+	PushToken string
+	// MaxTargets bounds the pushing targets, the pushes of new targets are
+	// refused beyond it
+	MaxTargets int
+	// TargetTTL is how long a pushing target is kept without a push
+	TargetTTL time.Duration
+}
+
+type target struct {
+	url string
+	// header is sent with the pulls
+	header    http.Header
+	snapshots []Snapshot
+	lastSeen  time.Time
+	lastErr   string
+}
+
+// Hub keeps the recent snapshots of every target
+type Hub struct {
+	cfg Config
+
+	mu      sync.RWMutex
+	targets map[string]*target
+}
+
+// New returns the Hub instance
+func New(cfg Config) *Hub {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.MaxPoints <= 0 {
+		cfg.MaxPoints = DefaultMaxPoints
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Interval}
+	}
+	if cfg.MaxTargets <= 0 {
+		cfg.MaxTargets = DefaultMaxTargets
+	}
+	if cfg.TargetTTL <= 0 {
+		cfg.TargetTTL = DefaultTargetTTL
+	}
+
+	h := &Hub{cfg: cfg, targets: make(map[string]*target)}
+	for name, url := range cfg.Targets {
+		header := cfg.TargetHeaders[name].Clone()
+		if token := cfg.TargetTokens[name]; token != "" {
+			if header == nil {
+				header = make(http.Header)
+			}
+			header.Set("Authorization", "Bearer "+token)
+		}
+		h.targets[name] = &target{url: strings.TrimSuffix(url, "/"), header: header}
+	}
+	return h
+}
+
+// Run pulls the configured targets every interval until ctx is done, the
+// pushing targets idle for longer than the TargetTTL are dropped
+func (h *Hub) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			h.expire(now)
+			h.pullAll(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *Hub) pullAll(ctx context.Context) {
+	h.mu.RLock()
+	pulled := make(map[string]target)
+	for name, t := range h.targets {
+		if t.url != "" {
+			pulled[name] = target{url: t.url, header: t.header}
+		}
+	}
+	h.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, t := range pulled {
+		wg.Add(1)
+		go func(name string, t target) {
+			defer wg.Done()
+			s, err := h.pull(ctx, t.url, t.header)
+			if err != nil {
+				h.setError(name, err)
+				return
+			}
+			h.Add(name, s)
+		}(name, t)
+	}
+	wg.Wait()
+}
+
+func (h *Hub) pull(ctx context.Context, url string, header http.Header) (Snapshot, error) {
+	var s Snapshot
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/debug/statsview/snapshot", nil)
+	if err != nil {
+		return s, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	resp, err := h.cfg.Client.Do(req)
+	if err != nil {
+		return s, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s, fmt.Errorf("hub: %s answered %s", url, resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&s)
+	return s, err
+}
+
+func (h *Hub) setError(name string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if t, ok := h.targets[name]; ok {
+		t.lastErr = err.Error()
+	}
+}
+
+var errTooManyTargets = errors.New("hub: too many targets")
+
+// Add appends the snapshot to the named target, unknown targets are created
+// and dropped like the pushing ones once idle for the TargetTTL
+func (h *Hub) Add(name string, s Snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.add(name, s)
+}
+
+// push appends the snapshot pushed by the named target, a new target is
+// refused once there are MaxTargets pushing targets
+func (h *Hub) push(name string, s Snapshot) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.targets[name]; !ok {
+		h.expireLocked(time.Now())
+		if h.pushing() >= h.cfg.MaxTargets {
+			return errTooManyTargets
+		}
+	}
+	h.add(name, s)
+	return nil
+}
+
+// pushing returns the number of pushing targets, the ones without url
+func (h *Hub) pushing() int {
+	n := 0
+	for _, t := range h.targets {
+		if t.url == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// expire drops the pushing targets idle for longer than the TargetTTL, the
+// pulled ones are kept
+func (h *Hub) expire(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expireLocked(now)
+}
+
+func (h *Hub) expireLocked(now time.Time) {
+	for name, t := range h.targets {
+		if t.url == "" && now.Sub(t.lastSeen) > h.cfg.TargetTTL {
+			delete(h.targets, name)
+		}
+	}
+}
+
+func (h *Hub) add(name string, s Snapshot) {
+	t, ok := h.targets[name]
+	if !ok {
+		t = &target{}
+		h.targets[name] = t
+	}
+	t.snapshots = append(t.snapshots, s)
+	if n := len(t.snapshots); n > h.cfg.MaxPoints {
+		t.snapshots = append(t.snapshots[:0], t.snapshots[n-h.cfg.MaxPoints:]...)
+	}
+	t.lastSeen = time.Now()
+	t.lastErr = ""
+}
+
+// TargetStatus describes a target in the target list
+type TargetStatus struct {
+	Name     string    `json:"name"`
+	URL      string    `json:"url,omitempty"`
+	LastSeen time.Time `json:"lastSeen"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Targets returns the status of all targets sorted by name
+func (h *Hub) Targets() []TargetStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ts := make([]TargetStatus, 0, len(h.targets))
+	for name, t := range h.targets {
+		ts = append(ts, TargetStatus{Name: name, URL: t.url, LastSeen: t.lastSeen, Error: t.lastErr})
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Name < ts[j].Name })
+	return ts
+}
+
+// ViewerHistory is the recent data of a viewer of a target, Values[i] are
+// the values of Series[i] at Times
+type ViewerHistory struct {
+	Name   string      `json:"name"`
+	Title  string      `json:"title"`
+	Series []string    `json:"series"`
+	Times  []int64     `json:"times"`
+	Values [][]float64 `json:"values"`
+}
+
+var errUnknownTarget = errors.New("hub: unknown target")
+
+// History returns the recent data of every viewer of the named target
+func (h *Hub) History(name string) ([]ViewerHistory, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	t, ok := h.targets[name]
+	if !ok {
+		return nil, errUnknownTarget
+	}
+
+	var (
+		out []ViewerHistory
+		idx = make(map[string]int)
+	)
+	for _, s := range t.snapshots {
+		for _, v := range s.Viewers {
+			i, ok := idx[v.Name]
+			if !ok || len(out[i].Series) != len(v.Series) {
+				// the series of a viewer changed, e.g. the target restarted
+				// with another version, start over
+				if !ok {
+					i = len(out)
+					idx[v.Name] = i
+					out = append(out, ViewerHistory{})
+				}
+				out[i] = ViewerHistory{
+					Name:   v.Name,
+					Title:  v.Title,
+					Series: v.Series,
+					Values: make([][]float64, len(v.Series)),
+				}
+			}
+			out[i].Times = append(out[i].Times, s.Time)
+			for j, val := range v.Values {
+				if j < len(out[i].Values) {
+					out[i].Values[j] = append(out[i].Values[j], val)
+				}
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,159 @@
+package hub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+)
+
+func TestHistory(t *testing.T) {
+	heap := func(at int64, series []string, values ...float64) Snapshot {
+		return Snapshot{Time: at, Viewers: []ViewerSnapshot{{Name: "heap", Title: "Heap", Series: series, Values: values}}}
+	}
+	tests := []struct {
+		name      string
+		snapshots []Snapshot
+		want      []ViewerHistory
+	}{
+		{"no snapshot", nil, nil},
+		{
+			"series over time",
+			[]Snapshot{heap(1, []string{"Alloc", "Sys"}, 1, 10), heap(2, []string{"Alloc", "Sys"}, 2, 20)},
+			[]ViewerHistory{{Name: "heap", Title: "Heap", Series: []string{"Alloc", "Sys"}, Times: []int64{1, 2}, Values: [][]float64{{1, 2}, {10, 20}}}},
+		},
+		{
+			"series changed",
+			[]Snapshot{heap(1, []string{"Alloc", "Sys"}, 1, 10), heap(2, []string{"Alloc"}, 3)},
+			[]ViewerHistory{{Name: "heap", Title: "Heap", Series: []string{"Alloc"}, Times: []int64{2}, Values: [][]float64{{3}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(Config{})
+			h.Add("api", Snapshot{})
+			for _, s := range tt.snapshots {
+				h.Add("api", s)
+			}
+			got, err := h.History("api")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("History() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := New(Config{}).History("unknown"); err != errUnknownTarget {
+		t.Errorf("History() of an unknown target = %v, want %v", err, errUnknownTarget)
+	}
+}
+
+func TestAddKeepsMaxPoints(t *testing.T) {
+	h := New(Config{MaxPoints: 3})
+	for i := int64(1); i <= 5; i++ {
+		h.Add("api", Snapshot{Time: i, Viewers: []ViewerSnapshot{{Name: "heap", Series: []string{"Alloc"}, Values: []float64{float64(i)}}}})
+	}
+	got, _ := h.History("api")
+	if want := []int64{3, 4, 5}; !reflect.DeepEqual(got[0].Times, want) {
+		t.Errorf("kept %v, want %v", got[0].Times, want)
+	}
+}
+
+func TestPullAll(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/debug/statsview/snapshot" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"time": 42, "viewers": [{"name": "goroutine", "series": ["Goroutines"], "values": [7]}]}`))
+	}))
+	defer target.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	h := New(Config{Targets: map[string]string{"api": target.URL + "/", "billing": broken.URL}})
+	h.pullAll(context.Background())
+
+	tests := []struct {
+		name  string
+		url   string
+		seen  bool
+		error string
+	}{
+		{"api", target.URL, true, ""},
+		{"billing", broken.URL, false, "503 Service Unavailable"},
+	}
+	targets := h.Targets()
+	if len(targets) != len(tests) {
+		t.Fatalf("got %d targets, want %d", len(targets), len(tests))
+	}
+	for i, tt := range tests {
+		got := targets[i]
+		if got.Name != tt.name || got.URL != tt.url || got.LastSeen.IsZero() == tt.seen || !strings.Contains(got.Error, tt.error) {
+			t.Errorf("target %d = %+v, want %s at %s seen %v with error %q", i, got, tt.name, tt.url, tt.seen, tt.error)
+		}
+	}
+	if got, _ := h.History("api"); len(got) != 1 || got[0].Values[0][0] != 7 {
+		t.Errorf("History() = %+v, want the pulled snapshot", got)
+	}
+}
+
+func TestSnapshotOf(t *testing.T) {
+	at := time.UnixMilli(1000)
+	samples := []exporter.Sample{
+		{Labels: map[string]string{"viewer": "heap", "series": "Alloc"}, Value: 1, Time: at},
+		{Labels: map[string]string{"viewer": "goroutine", "series": "Goroutines"}, Value: 3, Time: at.Add(time.Second)},
+		{Labels: map[string]string{"viewer": "heap", "series": "Sys"}, Value: 2, Time: at},
+	}
+	want := Snapshot{Time: 2000, Viewers: []ViewerSnapshot{
+		{Name: "heap", Series: []string{"Alloc", "Sys"}, Values: []float64{1, 2}},
+		{Name: "goroutine", Series: []string{"Goroutines"}, Values: []float64{3}},
+	}}
+	if got := snapshotOf(samples); !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshotOf() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPullCredentials(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer reader" && r.Header.Get("X-Proxy-Key") != "key" {
+			http.Error(w, "statsview: invalid token", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"time": 42, "viewers": [{"name": "goroutine", "series": ["Goroutines"], "values": [7]}]}`))
+	}))
+	defer target.Close()
+
+	tests := []struct {
+		name    string
+		tokens  map[string]string
+		headers map[string]http.Header
+		error   string
+	}{
+		{name: "no credentials", error: "401 Unauthorized"},
+		{name: "token", tokens: map[string]string{"api": "reader"}},
+		{name: "wrong token", tokens: map[string]string{"api": "other"}, error: "401 Unauthorized"},
+		{name: "token of another target", tokens: map[string]string{"billing": "reader"}, error: "401 Unauthorized"},
+		{name: "header", headers: map[string]http.Header{"api": {"X-Proxy-Key": {"key"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(Config{Targets: map[string]string{"api": target.URL}, TargetTokens: tt.tokens, TargetHeaders: tt.headers})
+			h.pullAll(context.Background())
+
+			got := h.Targets()[0]
+			if !strings.Contains(got.Error, tt.error) || got.LastSeen.IsZero() != (tt.error != "") {
+				t.Errorf("target %+v, want error %q", got, tt.error)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+)
+
+// pushTimeout bounds a push to the hub
+const pushTimeout = 10 * time.Second
+
+// Pusher is an exporter which pushes the samples of a target to the hub, for
+// processes the hub could not reach
+//
+//	mgr.AddExporter(hub.NewPusher("http://hub:18070", "billing-1", os.Getenv("HUB_PUSH_TOKEN")), 0)
+type Pusher struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewPusher returns the Pusher sending to the hub at hubURL as target with
+// the push token of the hub
+func NewPusher(hubURL, target, token string) *Pusher {
+	return &Pusher{
+		url:    strings.TrimSuffix(hubURL, "/") + "/api/push?target=" + url.QueryEscape(target),
+		token:  token,
+		client: &http.Client{Timeout: pushTimeout},
+	}
+}
+
+// snapshotOf rebuilds the per viewer snapshot from the exporter samples
+func snapshotOf(samples []exporter.Sample) Snapshot {
+	var (
+		s   Snapshot
+		idx = make(map[string]int)
+	)
+	for _, sm := range samples {
+		name := sm.Labels["viewer"]
+		i, ok := idx[name]
+		if !ok {
+			i = len(s.Viewers)
+			idx[name] = i
+			s.Viewers = append(s.Viewers, ViewerSnapshot{Name: name})
+		}
+		s.Viewers[i].Series = append(s.Viewers[i].Series, sm.Labels["series"])
+		s.Viewers[i].Values = append(s.Viewers[i].Values, sm.Value)
+		if t := sm.Time.UnixMilli(); t > s.Time {
+			s.Time = t
+		}
+	}
+	return s
+}
+
+func (p *Pusher) Export(ctx context.Context, samples []exporter.Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	bs, err := json.Marshal(snapshotOf(samples))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("hub: push answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Flush is a no-op since every Export is delivered immediately
+func (p *Pusher) Flush(context.Context) error {
+	return nil
+}
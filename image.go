@@ -0,0 +1,129 @@
+package statsview
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mortum5/statsview/internal/chartimg"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// maxImageSize bounds the size of the rendered images in pixels
+const maxImageSize = 4096
+
+// imageFormats are the image renderers by file extension
+var imageFormats = map[string]struct {
+	contentType string
+	render      func(w http.ResponseWriter, c chartimg.Chart) error
+}{
+	".png": {"image/png", func(w http.ResponseWriter, c chartimg.Chart) error { return chartimg.PNG(w, c) }},
+	".svg": {"image/svg+xml", func(w http.ResponseWriter, c chartimg.Chart) error { return chartimg.SVG(w, c) }},
+}
+
+// serveImage renders the chart of a viewer to an image without a browser,
+// e.g. `/debug/statsview/image/heap.png?last=10m&width=800`. The recorded
+// history is drawn when it's kept, otherwise the current values
+func (vm *ViewManager) serveImage(w http.ResponseWriter, r *http.Request) {
+	file := path.Base(r.URL.Path)
+	ext := path.Ext(file)
+	format, ok := imageFormats[ext]
+	if !ok {
+		http.Error(w, "statsview: image format must be .png or .svg", http.StatusNotFound)
+		return
+	}
+
+	v := vm.viewerByName(strings.TrimSuffix(file, ext))
+	if v == nil {
+		http.NotFound(w, r)
+		return
+	}
+	c, ok := v.(viewer.Collector)
+	if !ok {
+		http.Error(w, "statsview: viewer "+v.Name()+" doesn't collect points", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	width, err := imageSize(q.Get("width"), chartimg.DefaultWidth)
+	if err != nil {
+		http.Error(w, "statsview: invalid width", http.StatusBadRequest)
+		return
+	}
+	height, err := imageSize(q.Get("height"), chartimg.DefaultHeight)
+	if err != nil {
+		http.Error(w, "statsview: invalid height", http.StatusBadRequest)
+		return
+	}
+	last := vm.historyWindow()
+	if s := q.Get("last"); s != "" {
+		if last, err = time.ParseDuration(s); err != nil || last <= 0 {
+			http.Error(w, "statsview: invalid last duration", http.StatusBadRequest)
+			return
+		}
+	}
+
+	chart := chartimg.Chart{
+		Title:    v.View().Title.Title,
+		Subtitle: "Base units",
+		Series:   vm.imageSeries(v, c, last),
+		Location: viewer.TimeLocation(),
+		Width:    width,
+		Height:   height,
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", `inline; filename="`+file+`"`)
+	if err := format.render(w, chart); err != nil {
+		viewer.Logger().Error("statsview: failed to render image", "path", r.URL.Path, "err", err)
+	}
+}
+
+// imageSeries returns the series shown on the chart of the viewer, the
+// recorded points of the last duration followed by the current value. All
+// the collected series are drawn for the charts showing other series, e.g.
+// the window of the GC pauses
+func (vm *ViewManager) imageSeries(v viewer.Viewer, c viewer.Collector, last time.Duration) []chartimg.Series {
+	points := vm.safeCollect(v, c)
+	shown := make(map[string]bool)
+	for _, s := range v.View().MultiSeries {
+		shown[s.Name] = true
+	}
+	filter := false
+	for _, p := range points {
+		filter = filter || shown[p.Series]
+	}
+
+	now := vm.Smgr.Now()
+	var series []chartimg.Series
+	for _, p := range points {
+		if filter && !shown[p.Series] {
+			continue
+		}
+		s := chartimg.Series{Name: p.Series}
+		if last > 0 {
+			for _, hp := range vm.history.query(seriesKey{Viewer: p.Viewer, Series: p.Series}, now.Add(-last), now) {
+				s.Points = append(s.Points, chartimg.Point{Time: hp.Time, Value: hp.Value})
+			}
+		}
+		if n := len(s.Points); n == 0 || p.Time.After(s.Points[n-1].Time) {
+			s.Points = append(s.Points, chartimg.Point{Time: p.Time, Value: p.Value})
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// imageSize parses a size in pixels, def when it's empty
+func imageSize(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 100 || n > maxImageSize {
+		return 0, strconv.ErrRange
+	}
+	return n, nil
+}
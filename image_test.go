@@ -0,0 +1,66 @@
+package statsview
+
+import (
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestServeImage(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer(), viewer.NewHeapViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		name        string
+		path        string
+		status      int
+		contentType string
+		width       int
+		height      int
+	}{
+		{"png", "goroutine.png", http.StatusOK, "image/png", 600, 400},
+		{"png size", "heap.png?width=800&height=300", http.StatusOK, "image/png", 800, 300},
+		{"svg", "heap.svg?last=10m", http.StatusOK, "image/svg+xml", 0, 0},
+		{"unknown format", "heap.gif", http.StatusNotFound, "", 0, 0},
+		{"unknown viewer", "missing.png", http.StatusNotFound, "", 0, 0},
+		{"invalid width", "heap.png?width=x", http.StatusBadRequest, "", 0, 0},
+		{"width too small", "heap.png?width=10", http.StatusBadRequest, "", 0, 0},
+		{"height too large", "heap.png?height=5000", http.StatusBadRequest, "", 0, 0},
+		{"invalid last", "heap.png?last=-1m", http.StatusBadRequest, "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/image/"+tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.contentType == "" {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %s, want %s", got, tt.contentType)
+			}
+			if file := strings.SplitN(tt.path, "?", 2)[0]; rec.Header().Get("Content-Disposition") != `inline; filename="`+file+`"` {
+				t.Errorf("Content-Disposition = %s", rec.Header().Get("Content-Disposition"))
+			}
+			if tt.contentType != "image/png" {
+				return
+			}
+			img, err := png.Decode(rec.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+				t.Errorf("image of %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.width, tt.height)
+			}
+		})
+	}
+}
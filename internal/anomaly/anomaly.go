@@ -0,0 +1,182 @@
+// Package anomaly flags the unusual values of the collected series online,
+// with the bands of their exponentially weighted moving average and variance
+package anomaly
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// alpha weighs the latest value in the averages, they follow roughly
+	// the last 20 values. The anomalous values weigh a quarter, so a spike
+	// stays anomalous as a whole and a new level is adopted gradually
+	alpha = 0.1
+	// warmup is the values observed before a series could be anomalous
+	warmup = 20
+	// minDeviation is the deviation relative to the mean below which the
+	// series are deemed flat, so small steps of flat series aren't anomalies
+	minDeviation = 0.05
+	// maxRegions bounds the regions kept
+	maxRegions = 100
+)
+
+// Region is a run of anomalous values of a series, Value is the farthest
+// from the average and Score its distance in standard deviations
+type Region struct {
+	Viewer string    `json:"viewer"`
+	Series string    `json:"series"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Value  float64   `json:"value"`
+	Score  float64   `json:"score"`
+	Open   bool      `json:"open"`
+}
+
+// Change is how an observed value changed the regions of its series
+type Change int
+
+const (
+	Unchanged Change = iota
+	// Started is a value starting a region
+	Started
+	// Ended is a normal value closing the open region
+	Ended
+)
+
+// Event is a region started or ended by an observed value
+type Event struct {
+	Region
+	Change Change
+}
+
+type key struct {
+	viewer, series string
+}
+
+// band is the averages of a series, open is the index of its open region
+// in the regions or -1
+type band struct {
+	n              int
+	mean, variance float64
+	open           int
+}
+
+// Detector flags the values of every series farther than threshold standard
+// deviations from their average
+type Detector struct {
+	mu        sync.Mutex
+	threshold float64
+	bands     map[key]*band
+	regions   []Region
+}
+
+// New returns a Detector flagging the values beyond threshold standard deviations
+func New(threshold float64) *Detector {
+	return &Detector{threshold: threshold, bands: make(map[key]*band)}
+}
+
+// Observe folds the value of the series collected at t into its averages,
+// it returns the region of the series when the value starts or ends one. A
+// region started drops the oldest beyond maxRegions, the open ones dropped
+// are returned as ended first, so every region started is ended
+func (d *Detector) Observe(viewer, series string, t time.Time, v float64) []Event {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	k := key{viewer: viewer, series: series}
+	b, ok := d.bands[k]
+	if !ok {
+		b = &band{mean: v, open: -1}
+		d.bands[k] = b
+	}
+
+	var score float64
+	if b.n >= warmup {
+		deviation := math.Max(math.Sqrt(b.variance), minDeviation*math.Abs(b.mean))
+		if deviation > 0 {
+			score = math.Abs(v-b.mean) / deviation
+		} else if v != b.mean {
+			score = math.Inf(1)
+		}
+	}
+	weight := alpha
+	if score > d.threshold {
+		weight /= 4
+	}
+	diff := v - b.mean
+	b.mean += weight * diff
+	b.variance = (1 - weight) * (b.variance + weight*diff*diff)
+	b.n++
+
+	if score <= d.threshold {
+		if b.open < 0 {
+			return nil
+		}
+		r := &d.regions[b.open]
+		r.Open = false
+		b.open = -1
+		return []Event{{Region: *r, Change: Ended}}
+	}
+	if math.IsInf(score, 0) {
+		score = math.MaxFloat64
+	}
+	if b.open >= 0 {
+		r := &d.regions[b.open]
+		r.End = t
+		if score > r.Score {
+			r.Value, r.Score = v, score
+		}
+		return nil
+	}
+	r := Region{Viewer: viewer, Series: series, Start: t, End: t, Value: v, Score: score, Open: true}
+	events := d.append(r)
+	b.open = len(d.regions) - 1
+	return append(events, Event{Region: r, Change: Started})
+}
+
+// append adds a region, the oldest are dropped beyond maxRegions. The open
+// regions dropped are returned as ended
+func (d *Detector) append(r Region) []Event {
+	d.regions = append(d.regions, r)
+	drop := len(d.regions) - maxRegions
+	if drop <= 0 {
+		return nil
+	}
+	var ended []Event
+	for _, r := range d.regions[:drop] {
+		if r.Open {
+			r.Open = false
+			ended = append(ended, Event{Region: r, Change: Ended})
+		}
+	}
+	d.regions = append(d.regions[:0], d.regions[drop:]...)
+	for _, b := range d.bands {
+		if b.open >= 0 {
+			b.open -= drop
+			if b.open < 0 {
+				b.open = -1
+			}
+		}
+	}
+	return ended
+}
+
+// Regions returns a copy of the regions ending at or after since, the
+// oldest first
+func (d *Detector) Regions(since time.Time) []Region {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	regions := []Region{}
+	for _, r := range d.regions {
+		if !r.End.Before(since) {
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
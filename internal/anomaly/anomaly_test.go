@@ -0,0 +1,319 @@
+package anomaly
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var epoch = time.Unix(1700000000, 0)
+
+func at(i int) time.Time {
+	return epoch.Add(time.Duration(i) * time.Second)
+}
+
+// observe folds the values a second apart into the series, it returns the
+// indexes of the values starting a region and of the ones ending one
+func observe(d *Detector, series string, values ...float64) (started, ended []int) {
+	for i, v := range values {
+		for _, e := range d.Observe("heap", series, at(i), v) {
+			switch e.Change {
+			case Started:
+				started = append(started, i)
+			case Ended:
+				ended = append(ended, i)
+			}
+		}
+	}
+	return started, ended
+}
+
+// changes returns the changes of the events
+func changes(events []Event) []Change {
+	var cs []Change
+	for _, e := range events {
+		cs = append(cs, e.Change)
+	}
+	return cs
+}
+
+// steady returns n values alternating around 100
+func steady(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = 99 + float64(i%2)*2
+	}
+	return values
+}
+
+func TestDetector(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []float64
+		started []int
+		ended   []int
+		regions []Region
+	}{
+		{name: "steady", values: steady(40)},
+		{name: "spike during the warmup", values: append([]float64{100, 100, 1000}, steady(30)...)},
+		{
+			name:    "spike",
+			values:  append(steady(30), 1000, 100),
+			started: []int{30},
+			ended:   []int{31},
+			regions: []Region{{Start: at(30), End: at(30), Value: 1000}},
+		},
+		{
+			name:    "run of anomalous values",
+			values:  append(steady(30), 200, 1000, 900, 100),
+			started: []int{30},
+			ended:   []int{33},
+			regions: []Region{{Start: at(30), End: at(32), Value: 1000}},
+		},
+		{
+			name:    "open region",
+			values:  append(steady(30), 1000, 1000),
+			started: []int{30},
+			regions: []Region{{Start: at(30), End: at(31), Value: 1000, Open: true}},
+		},
+		{
+			name:    "two regions",
+			values:  append(append(steady(30), 1000, 100, 100), 1000, 100),
+			started: []int{30, 33},
+			ended:   []int{31, 34},
+			regions: []Region{{Start: at(30), End: at(30), Value: 1000}, {Start: at(33), End: at(33), Value: 1000}},
+		},
+		{
+			name:   "small step of a flat series",
+			values: append(steady(30), 104, 104),
+		},
+		{
+			name:    "step of a zero series",
+			values:  append(make([]float64, 30), 1),
+			started: []int{30},
+			regions: []Region{{Start: at(30), End: at(30), Value: 1, Score: math.MaxFloat64, Open: true}},
+		},
+		{
+			name:   "NaN and infinite skipped",
+			values: append(steady(30), math.NaN(), math.Inf(1), 100),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(4)
+			started, ended := observe(d, "Alloc", tt.values...)
+			if !equalInts(started, tt.started) {
+				t.Errorf("regions started at %v, want %v", started, tt.started)
+			}
+			if !equalInts(ended, tt.ended) {
+				t.Errorf("regions ended at %v, want %v", ended, tt.ended)
+			}
+			regions := d.Regions(time.Time{})
+			if len(regions) != len(tt.regions) {
+				t.Fatalf("%d regions, want %d: %+v", len(regions), len(tt.regions), regions)
+			}
+			for i, r := range regions {
+				want := tt.regions[i]
+				if r.Viewer != "heap" || r.Series != "Alloc" || !r.Start.Equal(want.Start) || !r.End.Equal(want.End) ||
+					r.Value != want.Value || r.Open != want.Open {
+					t.Errorf("region %d = %+v, want %+v", i, r, want)
+				}
+				if r.Score <= 4 || (want.Score != 0 && r.Score != want.Score) {
+					t.Errorf("region %d score = %v, want beyond the threshold", i, r.Score)
+				}
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDetectorEnded(t *testing.T) {
+	d := New(4)
+	observe(d, "Alloc", append(steady(30), 200, 1000, 900)...)
+	events := d.Observe("heap", "Alloc", at(33), 100)
+	if len(events) != 1 || events[0].Change != Ended {
+		t.Fatalf("changes %v, want Ended", changes(events))
+	}
+	r := events[0].Region
+	want := Region{Viewer: "heap", Series: "Alloc", Start: at(30), End: at(32), Value: 1000, Score: r.Score}
+	if r != want || r.Score <= 4 {
+		t.Errorf("ended region %+v, want %+v", r, want)
+	}
+}
+
+func TestDetectorThreshold(t *testing.T) {
+	// the deviation of the steady values is 5% of their mean, 5
+	tests := []struct {
+		threshold float64
+		value     float64
+		want      bool
+	}{
+		{4, 115, false},
+		{4, 125, true},
+		{2, 115, true},
+		{10, 140, false},
+	}
+	for _, tt := range tests {
+		d := New(tt.threshold)
+		observe(d, "Alloc", steady(30)...)
+		if got := len(d.Observe("heap", "Alloc", at(30), tt.value)) == 1; got != tt.want {
+			t.Errorf("threshold %v: %v anomalous = %v, want %v", tt.threshold, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDetectorSeries(t *testing.T) {
+	d := New(4)
+	observe(d, "Alloc", steady(30)...)
+	if started, _ := observe(d, "Sys", 1000); len(started) != 0 {
+		t.Error("the first value of another series is anomalous")
+	}
+	if events := d.Observe("heap", "Alloc", at(30), 1000); len(events) != 1 || events[0].Change != Started {
+		t.Error("the spike of the warmed up series isn't anomalous")
+	}
+}
+
+func TestDetectorRegions(t *testing.T) {
+	d := New(4)
+	observe(d, "Alloc", append(steady(30), 1000, 100, 100, 100, 1000, 100)...)
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  int
+	}{
+		{"all", time.Time{}, 2},
+		{"ending at since", at(30), 2},
+		{"recent", at(31), 1},
+		{"none", at(35), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Regions(tt.since); len(got) != tt.want {
+				t.Errorf("%d regions, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	d.Regions(time.Time{})[0].Value = 0
+	if d.Regions(time.Time{})[0].Value != 1000 {
+		t.Error("the regions returned aren't a copy")
+	}
+}
+
+func TestDetectorMaxRegions(t *testing.T) {
+	d := New(4)
+	for i := 0; i < maxRegions+10; i++ {
+		series := "S" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		observe(d, series, append(steady(30), 1000)...)
+	}
+	// the open region of the last series is extended after the oldest were dropped
+	d.Observe("heap", "Sfe", at(40), 2000)
+
+	regions := d.Regions(time.Time{})
+	if len(regions) != maxRegions {
+		t.Fatalf("%d regions kept, want %d", len(regions), maxRegions)
+	}
+	if first := regions[0].Series; first != "Ska" {
+		t.Errorf("oldest region kept of %s, want Ska", first)
+	}
+	last := regions[len(regions)-1]
+	if last.Series != "Sfe" || !last.End.Equal(at(40)) {
+		t.Errorf("last region %+v, want the one of Sfe extended", last)
+	}
+}
+
+func TestDetectorEWMA(t *testing.T) {
+	// values alternating around a level moving by step per value
+	series := func(n int, level, step, spread float64) []float64 {
+		values := make([]float64, n)
+		for i := range values {
+			values[i] = level + step*float64(i) + float64(i%2)*spread
+		}
+		return values
+	}
+
+	tests := []struct {
+		name    string
+		values  []float64
+		started []int
+		ended   []int
+	}{
+		{name: "slow drift followed", values: series(200, 100, 0.5, 2)},
+		{name: "noise within the variance", values: append(series(30, 90, 0, 20), 125)},
+		{name: "beyond the variance of the noise", values: append(series(30, 90, 0, 20), 160), started: []int{30}},
+		{
+			// the anomalous values weigh a quarter, the new level is
+			// adopted after a few values
+			name:    "new level adopted",
+			values:  append(steady(30), series(20, 200, 0, 2)...),
+			started: []int{30},
+			ended:   []int{33},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started, ended := observe(New(4), "Alloc", tt.values...)
+			if !equalInts(started, tt.started) || !equalInts(ended, tt.ended) {
+				t.Errorf("regions started at %v and ended at %v, want %v and %v", started, ended, tt.started, tt.ended)
+			}
+		})
+	}
+}
+
+func TestDetectorEvicted(t *testing.T) {
+	d := New(4)
+	name := func(i int) string { return "S" + string(rune('a'+i%26)) + string(rune('a'+i/26)) }
+	for i := 0; i < maxRegions; i++ {
+		observe(d, name(i), append(steady(30), 1000)...)
+	}
+	observe(d, "new", steady(30)...)
+
+	// change is an event expected, the change of the region of the series
+	type change struct {
+		change Change
+		series string
+	}
+	tests := []struct {
+		name   string
+		series string
+		value  float64
+		want   []change
+	}{
+		// the open region of Saa, the oldest, is dropped: it's ended
+		// before the region of the new series is started
+		{"open region evicted", "new", 1000, []change{{Ended, "Saa"}, {Started, "new"}}},
+		{"no second end of the evicted region", "Saa", 100, nil},
+		{"end of a kept region", "Sba", 100, []change{{Ended, "Sba"}}},
+		{"closed region evicted silently", "Saa", 5000, []change{{Started, "Saa"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []change
+			for _, e := range d.Observe("heap", tt.series, at(31), tt.value) {
+				got = append(got, change{e.Change, e.Series})
+				if e.Open != (e.Change == Started) {
+					t.Errorf("region %+v open = %v", e.Region, e.Open)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changes %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if n := len(d.Regions(time.Time{})); n != maxRegions {
+		t.Errorf("%d regions kept, want %d", n, maxRegions)
+	}
+}
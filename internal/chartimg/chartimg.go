@@ -0,0 +1,246 @@
+// Package chartimg draws the series of a viewer as a static line chart, in
+// PNG or SVG, for the reports rendered without a browser
+package chartimg
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultWidth and DefaultHeight are the size of the dashboard charts
+	DefaultWidth  = 600
+	DefaultHeight = 400
+
+	marginLeft   = 64
+	marginRight  = 32
+	marginTop    = 56
+	marginBottom = 36
+	yTicks       = 5
+	xTicks       = 5
+)
+
+// palette is the default palette of ECharts, the series get the colors of
+// the dashboard
+var palette = []string{
+	"#5470c6", "#91cc75", "#fac858", "#ee6666", "#73c0de",
+	"#3ba272", "#fc8452", "#9a60b4", "#ea7ccc",
+}
+
+// Point is a value at a time
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a named line of the chart
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// Chart is what is drawn, Width and Height default to the size of the
+// dashboard charts
+type Chart struct {
+	Title    string
+	Subtitle string
+	Series   []Series
+	Location *time.Location
+	Width    int
+	Height   int
+}
+
+// canvas is what the chart is drawn on, coordinates are in pixels from the
+// top left corner
+type canvas interface {
+	line(x0, y0, x1, y1 float64, color string, width float64)
+	polyline(xs, ys []float64, color string)
+	dot(x, y float64, color string)
+	rect(x, y, w, h float64, color string)
+	text(x, y float64, s, color string, anchor anchor)
+}
+
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+func (c Chart) size() (int, int) {
+	w, h := c.Width, c.Height
+	if w <= 0 {
+		w = DefaultWidth
+	}
+	if h <= 0 {
+		h = DefaultHeight
+	}
+	return w, h
+}
+
+// draw lays out the chart on cv
+func (c Chart) draw(cv canvas) {
+	w, h := c.size()
+	left, right := float64(marginLeft), float64(w-marginRight)
+	top, bottom := float64(marginTop), float64(h-marginBottom)
+
+	cv.rect(0, 0, float64(w), float64(h), "#ffffff")
+	cv.text(12, 18, c.Title, "#333333", anchorStart)
+	if c.Subtitle != "" {
+		cv.text(12, 34, c.Subtitle, "#888888", anchorStart)
+	}
+	c.drawLegend(cv, float64(w)/2, 18)
+
+	t0, t1, ok := c.timeRange()
+	if !ok {
+		cv.text(float64(w)/2, float64(h)/2, "no data", "#888888", anchorMiddle)
+		return
+	}
+	lo, hi, step := c.valueRange()
+
+	ly := func(v float64) float64 { return bottom - (v-lo)/(hi-lo)*(bottom-top) }
+	lx := func(t time.Time) float64 {
+		if !t1.After(t0) {
+			return (left + right) / 2
+		}
+		return left + float64(t.Sub(t0))/float64(t1.Sub(t0))*(right-left)
+	}
+
+	for v := lo; v <= hi+step/2; v += step {
+		y := ly(v)
+		cv.line(left, y, right, y, "#e0e6f1", 1)
+		cv.text(left-6, y+4, formatValue(v, step), "#6e7079", anchorEnd)
+	}
+	cv.line(left, bottom, right, bottom, "#6e7079", 1)
+
+	loc := c.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	for i := 0; i <= xTicks; i++ {
+		t := t0.Add(time.Duration(float64(t1.Sub(t0)) * float64(i) / xTicks))
+		x := lx(t)
+		cv.line(x, bottom, x, bottom+4, "#6e7079", 1)
+		cv.text(x, bottom+18, t.In(loc).Format(time.TimeOnly), "#6e7079", anchorMiddle)
+		if !t1.After(t0) {
+			break
+		}
+	}
+
+	for i, s := range c.Series {
+		color := palette[i%len(palette)]
+		xs := make([]float64, 0, len(s.Points))
+		ys := make([]float64, 0, len(s.Points))
+		for _, p := range s.Points {
+			if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+				continue
+			}
+			xs = append(xs, lx(p.Time))
+			ys = append(ys, ly(p.Value))
+		}
+		if len(xs) == 1 {
+			cv.dot(xs[0], ys[0], color)
+			continue
+		}
+		cv.polyline(xs, ys, color)
+	}
+}
+
+// drawLegend centers the names of the series with their color around x
+func (c Chart) drawLegend(cv canvas, x, y float64) {
+	const swatch, gap = 18, 12
+	width := 0.0
+	for _, s := range c.Series {
+		width += swatch + 4 + textWidth(s.Name) + gap
+	}
+	x -= (width - gap) / 2
+	for i, s := range c.Series {
+		color := palette[i%len(palette)]
+		cv.line(x, y-4, x+swatch, y-4, color, 2)
+		cv.text(x+swatch+4, y, s.Name, "#333333", anchorStart)
+		x += swatch + 4 + textWidth(s.Name) + gap
+	}
+}
+
+// timeRange returns the first and the last time of the points
+func (c Chart) timeRange() (t0, t1 time.Time, ok bool) {
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			if !ok || p.Time.Before(t0) {
+				t0 = p.Time
+			}
+			if !ok || p.Time.After(t1) {
+				t1 = p.Time
+			}
+			ok = true
+		}
+	}
+	return t0, t1, ok
+}
+
+// valueRange returns the bounds of the Y axis rounded to a nice step, the
+// axis starts at zero for positive series as on the dashboard
+func (c Chart) valueRange() (lo, hi, step float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+				continue
+			}
+			lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+		}
+	}
+	if lo > hi {
+		lo, hi = 0, 1
+	}
+	if lo > 0 {
+		lo = 0
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+
+	step = niceStep((hi - lo) / yTicks)
+	return math.Floor(lo/step) * step, math.Ceil(hi/step) * step, step
+}
+
+// niceStep rounds the step up to 1, 2 or 5 times a power of ten
+func niceStep(raw float64) float64 {
+	exp := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+var siPrefixes = []struct {
+	factor float64
+	prefix string
+}{
+	{1e12, "T"}, {1e9, "G"}, {1e6, "M"}, {1e3, "k"},
+	{1, ""}, {1e-3, "m"}, {1e-6, "u"}, {1e-9, "n"},
+}
+
+// formatValue formats an axis label with a SI prefix, with the digits the
+// step of the axis needs. Micro is `u` since the font of the PNG is ASCII
+func formatValue(v, step float64) string {
+	if v == 0 {
+		return "0"
+	}
+	p := siPrefixes[len(siPrefixes)-1]
+	for _, sp := range siPrefixes {
+		if math.Abs(v) >= sp.factor {
+			p = sp
+			break
+		}
+	}
+	digits := 0
+	if s := step / p.factor; s < 1 {
+		digits = int(math.Ceil(-math.Log10(s)))
+	}
+	return strconv.FormatFloat(v/p.factor, 'f', digits, 64) + p.prefix
+}
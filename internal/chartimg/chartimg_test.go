@@ -0,0 +1,177 @@
+package chartimg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNiceStep(t *testing.T) {
+	tests := []struct {
+		raw, want float64
+	}{
+		{0.7, 1},
+		{1, 1},
+		{1.3, 2},
+		{3, 5},
+		{7, 10},
+		{42, 50},
+		{0.0012, 0.002},
+		{180000, 200000},
+	}
+	for _, tt := range tests {
+		if got := niceStep(tt.raw); math.Abs(got-tt.want) > tt.want*1e-9 {
+			t.Errorf("niceStep(%v) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		v, step float64
+		want    string
+	}{
+		{0, 1, "0"},
+		{5, 1, "5"},
+		{3000, 1000, "3k"},
+		{2500, 100, "2.5k"},
+		{3 << 20, 1 << 20, "3M"},
+		{0.002, 0.001, "2m"},
+		{0.0000015, 0.0000005, "1.5u"},
+		{-4000, 1000, "-4k"},
+	}
+	for _, tt := range tests {
+		if got := formatValue(tt.v, tt.step); got != tt.want {
+			t.Errorf("formatValue(%v, %v) = %q, want %q", tt.v, tt.step, got, tt.want)
+		}
+	}
+}
+
+func seriesOf(values ...float64) []Series {
+	t0 := time.Unix(1700000000, 0)
+	s := Series{Name: "a"}
+	for i, v := range values {
+		s.Points = append(s.Points, Point{Time: t0.Add(time.Duration(i) * time.Second), Value: v})
+	}
+	return []Series{s}
+}
+
+func TestValueRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		series       []Series
+		lo, hi, step float64
+	}{
+		{"no points", nil, 0, 1, 0.2},
+		{"positive from zero", seriesOf(3, 9), 0, 10, 2},
+		{"constant zero", seriesOf(0, 0), 0, 1, 0.2},
+		{"negative", seriesOf(-7, 2), -8, 2, 2},
+		{"NaN and Inf skipped", seriesOf(math.NaN(), 40, math.Inf(1)), 0, 40, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi, step := Chart{Series: tt.series}.valueRange()
+			if math.Abs(lo-tt.lo) > 1e-9 || math.Abs(hi-tt.hi) > 1e-9 || math.Abs(step-tt.step) > 1e-9 {
+				t.Errorf("valueRange() = %v, %v, %v, want %v, %v, %v", lo, hi, step, tt.lo, tt.hi, tt.step)
+			}
+		})
+	}
+}
+
+// recorder counts what is drawn on the canvas
+type recorder struct {
+	polylines [][2][]float64
+	dots      int
+	texts     []string
+}
+
+func (r *recorder) line(x0, y0, x1, y1 float64, color string, width float64) {}
+func (r *recorder) polyline(xs, ys []float64, color string) {
+	r.polylines = append(r.polylines, [2][]float64{xs, ys})
+}
+func (r *recorder) dot(x, y float64, color string)        { r.dots++ }
+func (r *recorder) rect(x, y, w, h float64, color string) {}
+func (r *recorder) text(x, y float64, s, color string, anchor anchor) {
+	r.texts = append(r.texts, s)
+}
+
+func TestDraw(t *testing.T) {
+	tests := []struct {
+		name      string
+		series    []Series
+		polylines []int
+		dots      int
+		noData    bool
+	}{
+		{"no series", nil, nil, 0, true},
+		{"empty series", []Series{{Name: "a"}}, nil, 0, true},
+		{"single point", seriesOf(1), nil, 1, false},
+		{"line", seriesOf(1, 2, 3), []int{3}, 0, false},
+		{"invalid values skipped", seriesOf(1, math.NaN(), 3), []int{2}, 0, false},
+		{"several series", append(seriesOf(1, 2), Series{Name: "b", Points: seriesOf(4, 5, 6)[0].Points}), []int{2, 3}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			Chart{Title: "Heap", Series: tt.series, Location: time.UTC}.draw(r)
+
+			var polylines []int
+			for _, p := range r.polylines {
+				polylines = append(polylines, len(p[0]))
+				for i := range p[0] {
+					if p[0][i] < marginLeft || p[0][i] > DefaultWidth-marginRight || p[1][i] < marginTop || p[1][i] > DefaultHeight-marginBottom {
+						t.Errorf("point (%v, %v) out of the plot", p[0][i], p[1][i])
+					}
+				}
+			}
+			if !reflect.DeepEqual(polylines, tt.polylines) {
+				t.Errorf("polylines of %v points, want %v", polylines, tt.polylines)
+			}
+			if r.dots != tt.dots {
+				t.Errorf("drew %d dots, want %d", r.dots, tt.dots)
+			}
+			if r.texts[0] != "Heap" {
+				t.Errorf("title = %q, want Heap", r.texts[0])
+			}
+			if noData := r.texts[len(r.texts)-1] == "no data"; noData != tt.noData {
+				t.Errorf("no data = %v, want %v", noData, tt.noData)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	c := Chart{Title: "Heap <MiB>", Series: seriesOf(1, 5, 2), Width: 300, Height: 200}
+
+	var svg bytes.Buffer
+	if err := SVG(&svg, c); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Width  int      `xml:"width,attr"`
+		Height int      `xml:"height,attr"`
+		Texts  []string `xml:"text"`
+	}
+	if err := xml.Unmarshal(svg.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid SVG: %v", err)
+	}
+	if doc.Width != 300 || doc.Height != 200 || doc.Texts[0] != "Heap <MiB>" {
+		t.Errorf("SVG %dx%d titled %q, want 300x200 titled %q", doc.Width, doc.Height, doc.Texts[0], c.Title)
+	}
+
+	var buf bytes.Buffer
+	if err := PNG(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 300 || b.Dy() != 200 {
+		t.Errorf("PNG of %dx%d, want 300x200", b.Dx(), b.Dy())
+	}
+}
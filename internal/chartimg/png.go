@@ -0,0 +1,95 @@
+package chartimg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// textWidth is the width of s in the fixed-width font of the PNG, the SVG
+// uses a monospace font of the same size
+func textWidth(s string) float64 {
+	return float64(basicfont.Face7x13.Advance * utf8.RuneCountInString(s))
+}
+
+// PNG writes the chart as a PNG image
+func PNG(w io.Writer, c Chart) error {
+	width, height := c.size()
+	img := &pngCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+	c.draw(img)
+	return png.Encode(w, img.img)
+}
+
+// pngCanvas rasterizes the chart, lines aren't anti-aliased
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func (cv *pngCanvas) line(x0, y0, x1, y1 float64, color string, width float64) {
+	c := parseColor(color)
+	half := int(width / 2)
+	for dx := -half; dx <= int(width)-1-half; dx++ {
+		for dy := -half; dy <= int(width)-1-half; dy++ {
+			cv.segment(x0+float64(dx), y0+float64(dy), x1+float64(dx), y1+float64(dy), c)
+		}
+	}
+}
+
+// segment draws a one pixel wide line by stepping along its longest side
+func (cv *pngCanvas) segment(x0, y0, x1, y1 float64, c color.RGBA) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0)))
+	if steps == 0 {
+		cv.img.SetRGBA(int(math.Round(x0)), int(math.Round(y0)), c)
+		return
+	}
+	for i := 0; i <= steps; i++ {
+		f := float64(i) / float64(steps)
+		cv.img.SetRGBA(int(math.Round(x0+(x1-x0)*f)), int(math.Round(y0+(y1-y0)*f)), c)
+	}
+}
+
+func (cv *pngCanvas) polyline(xs, ys []float64, color string) {
+	for i := 1; i < len(xs); i++ {
+		cv.line(xs[i-1], ys[i-1], xs[i], ys[i], color, 2)
+	}
+}
+
+func (cv *pngCanvas) dot(x, y float64, color string) {
+	cv.rect(x-2, y-2, 5, 5, color)
+}
+
+func (cv *pngCanvas) rect(x, y, w, h float64, color string) {
+	r := image.Rect(int(x), int(y), int(x+w), int(y+h))
+	draw.Draw(cv.img, r, &image.Uniform{C: parseColor(color)}, image.Point{}, draw.Src)
+}
+
+func (cv *pngCanvas) text(x, y float64, s, color string, anchor anchor) {
+	switch anchor {
+	case anchorMiddle:
+		x -= textWidth(s) / 2
+	case anchorEnd:
+		x -= textWidth(s)
+	}
+	d := font.Drawer{
+		Dst:  cv.img,
+		Src:  &image.Uniform{C: parseColor(color)},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(int(x), int(y)),
+	}
+	d.DrawString(s)
+}
+
+// parseColor parses a `#rrggbb` color, the only form the chart uses
+func parseColor(s string) color.RGBA {
+	v, _ := strconv.ParseUint(s[1:], 16, 32)
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
@@ -0,0 +1,58 @@
+package chartimg
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// SVG writes the chart as a SVG image
+func SVG(w io.Writer, c Chart) error {
+	width, height := c.size()
+	cv := &svgCanvas{w: bufio.NewWriter(w)}
+	fmt.Fprintf(cv.w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="12">`+"\n",
+		width, height, width, height)
+	c.draw(cv)
+	fmt.Fprintln(cv.w, `</svg>`)
+	return cv.w.Flush()
+}
+
+// svgCanvas writes the chart as SVG elements
+type svgCanvas struct {
+	w *bufio.Writer
+}
+
+func (cv *svgCanvas) line(x0, y0, x1, y1 float64, color string, width float64) {
+	fmt.Fprintf(cv.w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%g"/>`+"\n",
+		x0, y0, x1, y1, color, width)
+}
+
+func (cv *svgCanvas) polyline(xs, ys []float64, color string) {
+	points := make([]string, len(xs))
+	for i := range xs {
+		points[i] = fmt.Sprintf("%.1f,%.1f", xs[i], ys[i])
+	}
+	fmt.Fprintf(cv.w, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n",
+		strings.Join(points, " "), color)
+}
+
+func (cv *svgCanvas) dot(x, y float64, color string) {
+	fmt.Fprintf(cv.w, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`+"\n", x, y, color)
+}
+
+func (cv *svgCanvas) rect(x, y, w, h float64, color string) {
+	fmt.Fprintf(cv.w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", x, y, w, h, color)
+}
+
+var textAnchors = map[anchor]string{
+	anchorStart:  "start",
+	anchorMiddle: "middle",
+	anchorEnd:    "end",
+}
+
+func (cv *svgCanvas) text(x, y float64, s, color string, anchor anchor) {
+	fmt.Fprintf(cv.w, `<text x="%.1f" y="%.1f" fill="%s" text-anchor="%s">%s</text>`+"\n",
+		x, y, color, textAnchors[anchor], html.EscapeString(s))
+}
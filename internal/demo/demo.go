@@ -0,0 +1,171 @@
+// Package demo generates synthetic but realistic-looking runtime statistics:
+// a heap growing with a varying allocation rate and collected by GC cycles
+// paced like GOGC=100, with a slowly moving live heap and goroutine count
+package demo
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	mib = 1 << 20
+
+	// maxStep bounds the time simulated between two reads, a long idle time
+	// doesn't run hundreds of cycles at once
+	maxStep = 10 * time.Second
+	// pageSize rounds the in-use spans
+	pageSize = 8 << 10
+)
+
+// Generator simulates the runtime, the statistics advance with its clock
+// every time they are read
+type Generator struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	rnd   *rand.Rand
+	start time.Time
+	last  time.Time
+
+	ms         runtime.MemStats
+	goroutines int
+}
+
+// New returns a Generator advancing with now, e.g. time.Now
+func New(now func() time.Time) *Generator {
+	t := now()
+	g := &Generator{now: now, rnd: rand.New(rand.NewSource(t.UnixNano())), start: t, last: t}
+	g.ms.HeapAlloc = 12 * mib
+	g.ms.NextGC = 32 * mib
+	g.ms.HeapSys = 40 * mib
+	g.ms.Mallocs = g.ms.HeapAlloc / 96
+	g.ms.GCCPUFraction = 0.004
+	g.goroutines = g.goroutinesAt(0)
+	g.update()
+	return g
+}
+
+// ReadMemStats advances the simulation and copies its memory statistics
+func (g *Generator) ReadMemStats(ms *runtime.MemStats) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.advance()
+	*ms = g.ms
+}
+
+// NumGoroutine advances the simulation and returns its goroutine count
+func (g *Generator) NumGoroutine() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.advance()
+	return g.goroutines
+}
+
+// wave is a sine of the given period in seconds
+func wave(t, period float64) float64 {
+	return math.Sin(2 * math.Pi * t / period)
+}
+
+// liveHeap is the heap surviving a cycle at t seconds
+func (g *Generator) liveHeap(t float64) float64 {
+	return (24 + 8*wave(t, 600) + 2*wave(t, 97)) * mib
+}
+
+// allocRate is the allocation rate in bytes per second at t seconds, with
+// bursts of load
+func (g *Generator) allocRate(t float64) float64 {
+	rate := (4 + 2*wave(t, 120)) * mib
+	if wave(t, 45) > 0.9 {
+		rate *= 3
+	}
+	return rate * (0.8 + 0.4*g.rnd.Float64())
+}
+
+func (g *Generator) goroutinesAt(t float64) int {
+	n := 120 + 60*wave(t, 300) + 20*wave(t, 45) + 6*g.rnd.NormFloat64()
+	return int(math.Max(n, 8))
+}
+
+// advance simulates the time elapsed since the last read
+func (g *Generator) advance() {
+	now := g.now()
+	step := now.Sub(g.last)
+	if step <= 0 {
+		return
+	}
+	if step > maxStep {
+		step = maxStep
+	}
+	g.last = now
+	t := now.Sub(g.start).Seconds()
+
+	allocated := g.allocRate(t) * step.Seconds()
+	g.ms.TotalAlloc += uint64(allocated)
+	g.ms.Mallocs += uint64(allocated / 96)
+	heap := float64(g.ms.HeapAlloc) + allocated
+
+	// the cycles are spread over the step, the last one ends now
+	cycles := 0
+	for heap >= float64(g.ms.NextGC) && cycles < 8 {
+		heap = heap - float64(g.ms.NextGC) + g.liveHeap(t)
+		g.ms.NextGC = uint64(math.Max(2*g.liveHeap(t), 4*mib))
+		cycles++
+	}
+	for i := 0; i < cycles; i++ {
+		end := now.Add(-step * time.Duration(cycles-1-i) / time.Duration(cycles))
+		g.gc(end)
+	}
+	objects := uint64(heap / 96)
+	if g.ms.Mallocs < objects {
+		g.ms.Mallocs = objects
+	}
+	g.ms.Frees = g.ms.Mallocs - objects
+	g.ms.HeapAlloc = uint64(heap)
+
+	// the GC costs more while the allocation rate is high
+	target := 0.002 + 0.002*float64(cycles)/step.Seconds()
+	g.ms.GCCPUFraction += (target - g.ms.GCCPUFraction) * 0.1
+	g.goroutines = g.goroutinesAt(t)
+	g.update()
+}
+
+// gc records a cycle which ended at end, the pauses are mostly tens of
+// microseconds with a long tail
+func (g *Generator) gc(end time.Time) {
+	pause := uint64(math.Min(30e3*math.Exp(g.rnd.NormFloat64()*0.6), 2e6))
+	g.ms.PauseNs[g.ms.NumGC%uint32(len(g.ms.PauseNs))] = pause
+	g.ms.PauseEnd[g.ms.NumGC%uint32(len(g.ms.PauseEnd))] = uint64(end.UnixNano())
+	g.ms.PauseTotalNs += pause
+	g.ms.LastGC = uint64(end.UnixNano())
+	g.ms.NumGC++
+}
+
+// update derives the statistics following the heap and the goroutines
+func (g *Generator) update() {
+	ms := &g.ms
+	ms.Alloc = ms.HeapAlloc
+	ms.HeapObjects = ms.HeapAlloc / 96
+	ms.HeapInuse = (ms.HeapAlloc*108/100 + pageSize - 1) / pageSize * pageSize
+	if sys := ms.NextGC * 115 / 100; sys > ms.HeapSys {
+		ms.HeapSys = sys
+	}
+	if ms.HeapInuse > ms.HeapSys {
+		ms.HeapSys = ms.HeapInuse
+	}
+	ms.HeapIdle = ms.HeapSys - ms.HeapInuse
+	ms.HeapReleased = ms.HeapIdle / 2
+
+	ms.StackInuse = uint64(g.goroutines)*8<<10 + 256<<10
+	if ms.StackInuse > ms.StackSys {
+		ms.StackSys = ms.StackInuse + 64<<10
+	}
+	ms.MSpanInuse = ms.HeapInuse / 100
+	ms.MSpanSys = ms.HeapSys / 64
+	ms.GCSys = ms.HeapSys * 3 / 100
+	ms.Sys = ms.HeapSys + ms.StackSys + ms.MSpanSys + ms.GCSys + 4*mib
+}
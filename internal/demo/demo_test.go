@@ -0,0 +1,80 @@
+package demo
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGenerator(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	g := New(func() time.Time { return now })
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		// cycles tells whether GC cycles are expected during the advance
+		cycles bool
+	}{
+		{"no time elapsed", 0, false},
+		{"a short step", time.Second, false},
+		{"a minute", time.Minute, true},
+		{"idle for an hour", time.Hour, true},
+		{"steady", 30 * time.Second, true},
+	}
+	var prev runtime.MemStats
+	g.ReadMemStats(&prev)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ms runtime.MemStats
+			for elapsed := time.Duration(0); elapsed < tt.advance; elapsed += maxStep {
+				now = now.Add(maxStep)
+				g.ReadMemStats(&ms)
+			}
+			if tt.advance < maxStep {
+				now = now.Add(tt.advance)
+			}
+			g.ReadMemStats(&ms)
+
+			if tt.advance == 0 && ms != prev {
+				t.Error("the statistics moved without time elapsed")
+			}
+			if cycles := ms.NumGC > prev.NumGC; tt.cycles && !cycles {
+				t.Errorf("no GC cycle in %v", tt.advance)
+			}
+			if ms.TotalAlloc < prev.TotalAlloc || ms.Mallocs < prev.Mallocs || ms.PauseTotalNs < prev.PauseTotalNs {
+				t.Error("a cumulative statistic decreased")
+			}
+			if ms.HeapIdle != ms.HeapSys-ms.HeapInuse || ms.HeapInuse < ms.HeapAlloc || ms.Frees > ms.Mallocs {
+				t.Errorf("inconsistent heap: %+v", ms)
+			}
+			if ms.NumGC > 0 {
+				last := (ms.NumGC - 1) % uint32(len(ms.PauseEnd))
+				if ms.LastGC != ms.PauseEnd[last] || ms.PauseNs[last] == 0 || ms.PauseNs[last] > 2e6 {
+					t.Errorf("the last pause of %dns ended at %d, LastGC %d", ms.PauseNs[last], ms.PauseEnd[last], ms.LastGC)
+				}
+				if ms.LastGC > uint64(now.UnixNano()) {
+					t.Error("the last GC ended in the future")
+				}
+			}
+			if n := g.NumGoroutine(); n < 8 {
+				t.Errorf("%d goroutines, want at least 8", n)
+			}
+			prev = ms
+		})
+	}
+}
+
+func TestAdvanceBoundsTheStep(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	g := New(func() time.Time { return now })
+	var before, after runtime.MemStats
+	g.ReadMemStats(&before)
+
+	now = now.Add(24 * time.Hour)
+	g.ReadMemStats(&after)
+	// at most 3 times the fastest rate of 6 MiB/s and 20% of noise
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > uint64(maxStep.Seconds()*3*6*1.2*mib) {
+		t.Errorf("allocated %d bytes in a day of idle time, want the step bounded to %v", allocated, maxStep)
+	}
+}
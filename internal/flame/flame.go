@@ -0,0 +1,74 @@
+// Package flame folds the stacks of a pprof profile into the call tree a
+// flame graph is drawn from.
+package flame
+
+import (
+	"sort"
+
+	"github.com/google/pprof/profile"
+)
+
+// Node is a function in the call tree, its value is the one of the samples
+// whose stack went through it from the root
+type Node struct {
+	Name     string  `json:"name"`
+	Value    int64   `json:"value"`
+	Children []*Node `json:"children,omitempty"`
+
+	index map[string]*Node
+}
+
+func (n *Node) child(name string) *Node {
+	if c, ok := n.index[name]; ok {
+		return c
+	}
+	if n.index == nil {
+		n.index = make(map[string]*Node)
+	}
+	c := &Node{Name: name}
+	n.index[name] = c
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// Tree folds the samples of the profile by the value of sampleIndex, the
+// inlined functions are frames of their own. The nodes below minFraction of
+// the total are pruned, the children are sorted by name
+func Tree(p *profile.Profile, sampleIndex int, minFraction float64) *Node {
+	root := &Node{Name: "root"}
+	for _, s := range p.Sample {
+		v := s.Value[sampleIndex]
+		if v == 0 {
+			continue
+		}
+		root.Value += v
+		n := root
+		// the locations and their lines go from the leaf to the root
+		for i := len(s.Location) - 1; i >= 0; i-- {
+			lines := s.Location[i].Line
+			for j := len(lines) - 1; j >= 0; j-- {
+				name := "unknown"
+				if lines[j].Function != nil {
+					name = lines[j].Function.Name
+				}
+				n = n.child(name)
+				n.Value += v
+			}
+		}
+	}
+	prune(root, int64(float64(root.Value)*minFraction))
+	return root
+}
+
+func prune(n *Node, min int64) {
+	n.index = nil
+	kept := n.Children[:0]
+	for _, c := range n.Children {
+		if c.Value >= min {
+			prune(c, min)
+			kept = append(kept, c)
+		}
+	}
+	n.Children = kept
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+}
@@ -0,0 +1,110 @@
+package flame
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+// stack returns the locations of the frames from the leaf to the root, the
+// inlined functions of a location come first, "" is a line without function
+func stack(frames ...[]string) []*profile.Location {
+	var locs []*profile.Location
+	for _, names := range frames {
+		loc := &profile.Location{}
+		for _, n := range names {
+			var fn *profile.Function
+			if n != "" {
+				fn = &profile.Function{Name: n}
+			}
+			loc.Line = append(loc.Line, profile.Line{Function: fn})
+		}
+		locs = append(locs, loc)
+	}
+	return locs
+}
+
+func sample(v int64, frames ...[]string) *profile.Sample {
+	return &profile.Sample{Value: []int64{1, v}, Location: stack(frames...)}
+}
+
+// folded flattens the tree as `name=value(children...)`
+func folded(n *Node) string {
+	s := n.Name + "=" + strconv.FormatInt(n.Value, 10)
+	if len(n.Children) > 0 {
+		s += "("
+		for i, c := range n.Children {
+			if i > 0 {
+				s += " "
+			}
+			s += folded(c)
+		}
+		s += ")"
+	}
+	return s
+}
+
+func TestTree(t *testing.T) {
+	tests := []struct {
+		name        string
+		samples     []*profile.Sample
+		minFraction float64
+		want        string
+	}{
+		{"empty", nil, 0, "root=0"},
+		{
+			"merged stacks",
+			[]*profile.Sample{
+				sample(30, []string{"main.work"}, []string{"main.main"}),
+				sample(10, []string{"main.idle"}, []string{"main.main"}),
+				sample(20, []string{"main.work"}, []string{"main.main"}),
+			},
+			0,
+			"root=60(main.main=60(main.idle=10 main.work=50))",
+		},
+		{
+			"inlined frames",
+			[]*profile.Sample{sample(5, []string{"main.inlined", "main.caller"}, []string{"main.main"})},
+			0,
+			"root=5(main.main=5(main.caller=5(main.inlined=5)))",
+		},
+		{
+			"zero samples skipped",
+			[]*profile.Sample{sample(0, []string{"main.main"}), sample(4, []string{"main.other"})},
+			0,
+			"root=4(main.other=4)",
+		},
+		{
+			"unknown function",
+			[]*profile.Sample{sample(2, []string{""})},
+			0,
+			"root=2(unknown=2)",
+		},
+		{
+			"pruned",
+			[]*profile.Sample{
+				sample(99, []string{"main.hot"}, []string{"main.main"}),
+				sample(1, []string{"main.cold"}, []string{"main.main"}),
+			},
+			0.05,
+			"root=100(main.main=100(main.hot=99))",
+		},
+		{
+			"children sorted by name",
+			[]*profile.Sample{
+				sample(1, []string{"c"}), sample(1, []string{"a"}), sample(1, []string{"b"}),
+			},
+			0,
+			"root=3(a=1 b=1 c=1)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &profile.Profile{Sample: tt.samples}
+			if got := folded(Tree(p, 1, tt.minFraction)); got != tt.want {
+				t.Errorf("Tree() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
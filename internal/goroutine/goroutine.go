@@ -0,0 +1,194 @@
+// Package goroutine parses the `debug=2` text dump of the goroutine profile
+// and groups goroutines by their stack or creation site.
+package goroutine
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ByStack groups goroutines sharing the identical call stack
+	ByStack = "stack"
+	// ByCreator groups goroutines sharing the same `created by` location
+	ByCreator = "creator"
+)
+
+// Frame is a single entry of a goroutine stack
+type Frame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+}
+
+// Goroutine is a parsed goroutine record
+type Goroutine struct {
+	ID        int64   `json:"id"`
+	State     string  `json:"state"`
+	Wait      string  `json:"wait,omitempty"`
+	Stack     []Frame `json:"stack"`
+	CreatedBy *Frame  `json:"createdBy,omitempty"`
+}
+
+// Group is a set of goroutines which have the same grouping key
+type Group struct {
+	Key       string         `json:"key"`
+	Count     int            `json:"count"`
+	States    map[string]int `json:"states"`
+	Stack     []Frame        `json:"stack"`
+	CreatedBy *Frame         `json:"createdBy,omitempty"`
+}
+
+// Capture takes a snapshot of all goroutines via `pprof.Lookup("goroutine")`
+func Capture() ([]Goroutine, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		return nil, err
+	}
+	return Parse(&buf)
+}
+
+// Parse reads the goroutine dump produced with debug level 2
+func Parse(r io.Reader) ([]Goroutine, error) {
+	var (
+		gs  []Goroutine
+		cur *Goroutine
+	)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "goroutine "):
+			gs = append(gs, parseHeader(line))
+			cur = &gs[len(gs)-1]
+		case cur == nil || line == "":
+		case strings.HasPrefix(line, "\t"):
+			file := strings.TrimSpace(line)
+			if i := strings.LastIndex(file, " +0x"); i > 0 {
+				file = file[:i]
+			}
+			if cur.CreatedBy != nil && cur.CreatedBy.File == "" {
+				cur.CreatedBy.File = file
+			} else if n := len(cur.Stack); n > 0 && cur.Stack[n-1].File == "" {
+				cur.Stack[n-1].File = file
+			}
+		case strings.HasPrefix(line, "created by "):
+			fn := strings.TrimPrefix(line, "created by ")
+			if i := strings.Index(fn, " in goroutine "); i > 0 {
+				fn = fn[:i]
+			}
+			cur.CreatedBy = &Frame{Func: fn}
+		default:
+			cur.Stack = append(cur.Stack, Frame{Func: trimArgs(line)})
+		}
+	}
+
+	return gs, sc.Err()
+}
+
+// Aggregate groups goroutines by the given key, the biggest group comes first
+func Aggregate(gs []Goroutine, by string) []Group {
+	idx := make(map[string]int)
+	var groups []Group
+	for _, g := range gs {
+		key := groupKey(g, by)
+		i, ok := idx[key]
+		if !ok {
+			i = len(groups)
+			idx[key] = i
+			groups = append(groups, Group{
+				Key:       key,
+				States:    make(map[string]int),
+				Stack:     g.Stack,
+				CreatedBy: g.CreatedBy,
+			})
+		}
+		groups[i].Count++
+		groups[i].States[g.State]++
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Count > groups[j].Count
+	})
+	return groups
+}
+
+func groupKey(g Goroutine, by string) string {
+	if by == ByCreator {
+		if g.CreatedBy == nil {
+			return "(root)"
+		}
+		return g.CreatedBy.Func + " " + g.CreatedBy.File
+	}
+
+	var sb strings.Builder
+	for _, f := range g.Stack {
+		sb.WriteString(f.Func)
+		sb.WriteByte(' ')
+		sb.WriteString(f.File)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// parseHeader parses line like `goroutine 18 [chan receive, 5 minutes]:`
+func parseHeader(line string) Goroutine {
+	var g Goroutine
+	rest := strings.TrimPrefix(line, "goroutine ")
+	if i := strings.IndexByte(rest, ' '); i > 0 {
+		g.ID, _ = strconv.ParseInt(rest[:i], 10, 64)
+		rest = rest[i+1:]
+	}
+
+	rest = strings.TrimSuffix(strings.TrimPrefix(rest, "["), "]:")
+	parts := strings.Split(rest, ", ")
+	g.State = parts[0]
+	for _, p := range parts[1:] {
+		if strings.HasSuffix(p, "minutes") || strings.HasSuffix(p, "minute") {
+			g.Wait = p
+		}
+	}
+	return g
+}
+
+// trimArgs strips the argument list from a frame line `pkg.fn(0x1, 0x2)`
+func trimArgs(line string) string {
+	if i := strings.LastIndexByte(line, '('); i > 0 && strings.HasSuffix(line, ")") {
+		return line[:i]
+	}
+	return line
+}
+
+// Categories are the coarse states of Category, in the order they're charted
+var Categories = []string{"Running", "Runnable", "Chan", "Select", "IOWait", "Syscall", "Sync", "Sleep", "Other"}
+
+// Category maps the state of a goroutine dump, e.g. `chan receive` or
+// `sync.Mutex.Lock`, to one of the Categories
+func Category(state string) string {
+	state = strings.TrimSuffix(state, " (scan)")
+	switch {
+	case state == "running":
+		return "Running"
+	case state == "runnable":
+		return "Runnable"
+	case strings.HasPrefix(state, "chan "):
+		return "Chan"
+	case strings.HasPrefix(state, "select"):
+		return "Select"
+	case state == "IO wait":
+		return "IOWait"
+	case state == "syscall":
+		return "Syscall"
+	case strings.HasPrefix(state, "sync."), state == "semacquire":
+		return "Sync"
+	case state == "sleep":
+		return "Sleep"
+	}
+	return "Other"
+}
@@ -0,0 +1,141 @@
+package goroutine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const dump = `goroutine 1 [running]:
+main.main()
+	/app/main.go:12 +0x1d
+
+goroutine 18 [chan receive, 5 minutes]:
+main.worker(0xc000010000, 0x2)
+	/app/worker.go:30 +0x45
+created by main.start in goroutine 1
+	/app/main.go:20 +0x66
+
+goroutine 19 [chan receive]:
+main.worker(0xc000010000, 0x3)
+	/app/worker.go:30 +0x45
+created by main.start in goroutine 1
+	/app/main.go:20 +0x66
+
+goroutine 20 [select (scan), 1 minute]:
+net/http.(*persistConn).writeLoop(0xc0001)
+	/go/src/net/http/transport.go:2421 +0xf5
+created by net/http.(*Transport).dialConn
+	/go/src/net/http/transport.go:1777 +0x16f1
+`
+
+func TestParse(t *testing.T) {
+	gs, err := Parse(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker := []Frame{{Func: "main.worker", File: "/app/worker.go:30"}}
+	start := &Frame{Func: "main.start", File: "/app/main.go:20"}
+	want := []Goroutine{
+		{ID: 1, State: "running", Stack: []Frame{{Func: "main.main", File: "/app/main.go:12"}}},
+		{ID: 18, State: "chan receive", Wait: "5 minutes", Stack: worker, CreatedBy: start},
+		{ID: 19, State: "chan receive", Stack: worker, CreatedBy: start},
+		{
+			ID: 20, State: "select (scan)", Wait: "1 minute",
+			Stack:     []Frame{{Func: "net/http.(*persistConn).writeLoop", File: "/go/src/net/http/transport.go:2421"}},
+			CreatedBy: &Frame{Func: "net/http.(*Transport).dialConn", File: "/go/src/net/http/transport.go:1777"},
+		},
+	}
+	if !reflect.DeepEqual(gs, want) {
+		t.Errorf("Parse() = %+v, want %+v", gs, want)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		line string
+		want Goroutine
+	}{
+		{"goroutine 1 [running]:", Goroutine{ID: 1, State: "running"}},
+		{"goroutine 18 [chan receive, 5 minutes]:", Goroutine{ID: 18, State: "chan receive", Wait: "5 minutes"}},
+		{"goroutine 7 [IO wait, 1 minute]:", Goroutine{ID: 7, State: "IO wait", Wait: "1 minute"}},
+		{"goroutine 9 [select, locked to thread]:", Goroutine{ID: 9, State: "select"}},
+		{"goroutine 3 [sleep]:", Goroutine{ID: 3, State: "sleep"}},
+	}
+	for _, tt := range tests {
+		if got := parseHeader(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseHeader(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTrimArgs(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"main.main()", "main.main"},
+		{"main.worker(0xc000010000, 0x2)", "main.worker"},
+		{"net/http.(*conn).serve(0xc0001, {0x9, 0x1})", "net/http.(*conn).serve"},
+		{"runtime.gopark(...)", "runtime.gopark"},
+		{"main.func1", "main.func1"},
+	}
+	for _, tt := range tests {
+		if got := trimArgs(tt.in); got != tt.want {
+			t.Errorf("trimArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	gs, err := Parse(strings.NewReader(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		by     string
+		counts []int
+		first  string
+		states map[string]int
+	}{
+		{ByStack, []int{2, 1, 1}, "main.worker /app/worker.go:30\n", map[string]int{"chan receive": 2}},
+		{ByCreator, []int{2, 1, 1}, "main.start /app/main.go:20", map[string]int{"chan receive": 2}},
+	}
+	for _, tt := range tests {
+		groups := Aggregate(gs, tt.by)
+		var counts []int
+		for _, g := range groups {
+			counts = append(counts, g.Count)
+		}
+		if !reflect.DeepEqual(counts, tt.counts) {
+			t.Errorf("Aggregate(%s) counts = %v, want %v", tt.by, counts, tt.counts)
+		}
+		if groups[0].Key != tt.first || !reflect.DeepEqual(groups[0].States, tt.states) {
+			t.Errorf("Aggregate(%s) first group = %q %v, want %q %v", tt.by, groups[0].Key, groups[0].States, tt.first, tt.states)
+		}
+	}
+	if groups := Aggregate(gs, ByCreator); groups[1].Key != "(root)" {
+		t.Errorf("the goroutines without creator are grouped as %q, want (root)", groups[1].Key)
+	}
+}
+
+func TestCategory(t *testing.T) {
+	tests := []struct{ state, want string }{
+		{"running", "Running"},
+		{"runnable", "Runnable"},
+		{"chan receive", "Chan"},
+		{"chan send (nil chan)", "Chan"},
+		{"select", "Select"},
+		{"select (no cases)", "Select"},
+		{"select (scan)", "Select"},
+		{"IO wait", "IOWait"},
+		{"syscall", "Syscall"},
+		{"sync.Mutex.Lock", "Sync"},
+		{"sync.WaitGroup.Wait", "Sync"},
+		{"semacquire", "Sync"},
+		{"sleep", "Sleep"},
+		{"GC worker (idle)", "Other"},
+	}
+	for _, tt := range tests {
+		if got := Category(tt.state); got != tt.want {
+			t.Errorf("Category(%q) = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package goroutine
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	// LeakWindow is the number of samples the growth of a group is judged on
+	LeakWindow = 10
+	// LeakMinSamples is the number of samples needed before a group is judged
+	LeakMinSamples = 6
+	// LeakMinGrowth is the growth over the window a group needs to be suspected
+	LeakMinGrowth = 10
+)
+
+// Leak is a creation site whose goroutine count grew steadily
+type Leak struct {
+	Key       string `json:"key"`
+	CreatedBy *Frame `json:"createdBy,omitempty"`
+	Counts    []int  `json:"counts"`
+	Growth    int    `json:"growth"`
+}
+
+// LeakDetector tracks the goroutine counts by creation site and suspects the
+// sites which never shrank and grew by at least LeakMinGrowth over the window
+type LeakDetector struct {
+	mu       sync.Mutex
+	samples  int
+	counts   map[string][]int
+	creators map[string]*Frame
+}
+
+// NewLeakDetector returns an empty LeakDetector
+func NewLeakDetector() *LeakDetector {
+	return &LeakDetector{counts: make(map[string][]int), creators: make(map[string]*Frame)}
+}
+
+// Record appends the counts of groups aggregated ByCreator
+func (d *LeakDetector) Record(groups []Group) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.samples++
+	n := d.samples
+	if n > LeakWindow {
+		n = LeakWindow
+	}
+
+	current := make(map[string]int, len(groups))
+	for _, g := range groups {
+		current[g.Key] = g.Count
+		if _, ok := d.counts[g.Key]; !ok {
+			d.counts[g.Key] = make([]int, n-1, n)
+			d.creators[g.Key] = g.CreatedBy
+		}
+	}
+	for key, counts := range d.counts {
+		counts = append(counts, current[key])
+		if len(counts) > n {
+			counts = counts[len(counts)-n:]
+		}
+		if current[key] == 0 && allZero(counts) {
+			delete(d.counts, key)
+			delete(d.creators, key)
+			continue
+		}
+		d.counts[key] = counts
+	}
+}
+
+// Suspects returns the suspected leaks, the fastest growing first
+func (d *LeakDetector) Suspects() []Leak {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	leaks := []Leak{}
+	if d.samples < LeakMinSamples {
+		return leaks
+	}
+	for key, counts := range d.counts {
+		growth := counts[len(counts)-1] - counts[0]
+		if growth < LeakMinGrowth || !nonDecreasing(counts) {
+			continue
+		}
+		leaks = append(leaks, Leak{
+			Key:       key,
+			CreatedBy: d.creators[key],
+			Counts:    append([]int(nil), counts...),
+			Growth:    growth,
+		})
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		if leaks[i].Growth != leaks[j].Growth {
+			return leaks[i].Growth > leaks[j].Growth
+		}
+		return leaks[i].Key < leaks[j].Key
+	})
+	return leaks
+}
+
+func nonDecreasing(vs []int) bool {
+	for i := 1; i < len(vs); i++ {
+		if vs[i] < vs[i-1] {
+			return false
+		}
+	}
+	return true
+}
+
+func allZero(vs []int) bool {
+	for _, v := range vs {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
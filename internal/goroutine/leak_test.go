@@ -0,0 +1,64 @@
+package goroutine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLeakDetector(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		growth int
+	}{
+		{"steady growth", []int{1, 3, 5, 7, 9, 11, 13}, 12},
+		{"flat", []int{5, 5, 5, 5, 5, 5, 5}, 0},
+		{"too small", []int{1, 2, 3, 4, 5, 6, 7}, 0},
+		{"shrank once", []int{1, 5, 9, 8, 13, 17, 21}, 0},
+		{"too few samples", []int{1, 20, 40, 60, 80}, 0},
+		{"judged on the window", []int{100, 0, 1, 2, 4, 6, 8, 10, 12, 14, 16, 18}, 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewLeakDetector()
+			for _, n := range tt.counts {
+				d.Record([]Group{{Key: "site", Count: n}})
+			}
+			var growth int
+			if leaks := d.Suspects(); len(leaks) > 0 {
+				growth = leaks[0].Growth
+			}
+			if growth != tt.growth {
+				t.Errorf("growth = %d, want %d", growth, tt.growth)
+			}
+		})
+	}
+}
+
+func TestLeakDetectorForgetsGoneSites(t *testing.T) {
+	d := NewLeakDetector()
+	d.Record([]Group{{Key: "a", Count: 1}})
+	for i := 0; i < LeakWindow; i++ {
+		d.Record(nil)
+	}
+	if len(d.counts) != 0 {
+		t.Errorf("counts = %v, want the site forgotten", d.counts)
+	}
+}
+
+func TestMonotonic(t *testing.T) {
+	tests := []struct {
+		vs          []int
+		nonDecr, z0 bool
+	}{
+		{nil, true, true},
+		{[]int{0, 0}, true, true},
+		{[]int{1, 1, 2}, true, false},
+		{[]int{2, 1}, false, false},
+	}
+	for _, tt := range tests {
+		if got := [2]bool{nonDecreasing(tt.vs), allZero(tt.vs)}; !reflect.DeepEqual(got, [2]bool{tt.nonDecr, tt.z0}) {
+			t.Errorf("%v: nonDecreasing, allZero = %v, want %v, %v", tt.vs, got, tt.nonDecr, tt.z0)
+		}
+	}
+}
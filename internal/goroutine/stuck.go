@@ -0,0 +1,128 @@
+package goroutine
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stuck is a set of goroutines blocked at the same site, in the same wait
+// state at the same stack, for longer than the threshold
+type Stuck struct {
+	Key       string    `json:"key"`
+	State     string    `json:"state"`
+	Count     int       `json:"count"`
+	IDs       []int64   `json:"ids"`
+	Since     time.Time `json:"since"`
+	Blocked   float64   `json:"blocked"`
+	Stack     []Frame   `json:"stack"`
+	CreatedBy *Frame    `json:"createdBy,omitempty"`
+}
+
+// blocked is a goroutine seen waiting at the same site since a time
+type blocked struct {
+	key   string
+	since time.Time
+	g     Goroutine
+}
+
+// StuckDetector diffs the goroutine snapshots: a goroutine found waiting on a
+// channel, a select or a sync primitive at the same stack in consecutive
+// snapshots is blocked since it was first seen there, or since the wait the
+// runtime reports when it's longer
+type StuckDetector struct {
+	mu      sync.Mutex
+	at      time.Time
+	blocked map[int64]blocked
+}
+
+// NewStuckDetector returns an empty StuckDetector
+func NewStuckDetector() *StuckDetector {
+	return &StuckDetector{blocked: make(map[int64]blocked)}
+}
+
+// Record diffs the snapshot taken at now with the previous one, the
+// goroutines which aren't blocked anymore are forgotten
+func (d *StuckDetector) Record(gs []Goroutine, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := make(map[int64]blocked, len(d.blocked))
+	for _, g := range gs {
+		switch Category(g.State) {
+		case "Chan", "Select", "Sync":
+		default:
+			continue
+		}
+		key := g.State + "\n" + groupKey(g, ByStack)
+		since := now
+		if prev, ok := d.blocked[g.ID]; ok && prev.key == key {
+			since = prev.since
+		}
+		if waited := now.Add(-waitOf(g.Wait)); waited.Before(since) {
+			since = waited
+		}
+		current[g.ID] = blocked{key: key, since: since, g: g}
+	}
+	d.at, d.blocked = now, current
+}
+
+// Stuck returns the sites where goroutines were blocked for longer than the
+// threshold in the last snapshot, the longest blocked first
+func (d *StuckDetector) Stuck(threshold time.Duration) []Stuck {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	idx := make(map[string]int)
+	stuck := []Stuck{}
+	for id, b := range d.blocked {
+		if d.at.Sub(b.since) < threshold {
+			continue
+		}
+		i, ok := idx[b.key]
+		if !ok {
+			i = len(stuck)
+			idx[b.key] = i
+			stuck = append(stuck, Stuck{
+				Key:       b.key,
+				State:     b.g.State,
+				Since:     b.since,
+				Stack:     b.g.Stack,
+				CreatedBy: b.g.CreatedBy,
+			})
+		}
+		s := &stuck[i]
+		s.Count++
+		s.IDs = append(s.IDs, id)
+		if b.since.Before(s.Since) {
+			s.Since = b.since
+		}
+	}
+	for i := range stuck {
+		stuck[i].Blocked = d.at.Sub(stuck[i].Since).Seconds()
+		sort.Slice(stuck[i].IDs, func(a, b int) bool { return stuck[i].IDs[a] < stuck[i].IDs[b] })
+	}
+	sort.Slice(stuck, func(i, j int) bool {
+		if !stuck[i].Since.Equal(stuck[j].Since) {
+			return stuck[i].Since.Before(stuck[j].Since)
+		}
+		return stuck[i].Key < stuck[j].Key
+	})
+	return stuck
+}
+
+// waitOf parses the wait of a goroutine header, e.g. `5 minutes`, the
+// runtime reports it from one minute on
+func waitOf(wait string) time.Duration {
+	n, _, ok := strings.Cut(wait, " ")
+	if !ok {
+		return 0
+	}
+	minutes, err := strconv.Atoi(n)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,98 @@
+package goroutine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitOf(t *testing.T) {
+	tests := []struct {
+		wait string
+		want time.Duration
+	}{
+		{"", 0},
+		{"1 minute", time.Minute},
+		{"5 minutes", 5 * time.Minute},
+		{"locked to thread", 0},
+	}
+	for _, tt := range tests {
+		if got := waitOf(tt.wait); got != tt.want {
+			t.Errorf("waitOf(%q) = %v, want %v", tt.wait, got, tt.want)
+		}
+	}
+}
+
+func TestStuckDetector(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stack := []Frame{{Func: "main.worker", File: "/app/worker.go:30"}}
+	other := []Frame{{Func: "main.other", File: "/app/other.go:5"}}
+	g := func(id int64, state, wait string, stack []Frame) Goroutine {
+		return Goroutine{ID: id, State: state, Wait: wait, Stack: stack}
+	}
+
+	tests := []struct {
+		name      string
+		snapshots [][]Goroutine
+		threshold time.Duration
+		counts    []int
+		blocked   float64
+	}{
+		{
+			"blocked across snapshots",
+			[][]Goroutine{{g(1, "chan receive", "", stack)}, {g(1, "chan receive", "", stack)}, {g(1, "chan receive", "", stack)}},
+			time.Minute, []int{1}, 120,
+		},
+		{
+			"below the threshold",
+			[][]Goroutine{{g(1, "chan receive", "", stack)}, {g(1, "chan receive", "", stack)}},
+			2 * time.Minute, nil, 0,
+		},
+		{
+			"running isn't blocked",
+			[][]Goroutine{{g(1, "running", "", stack)}, {g(1, "running", "", stack)}, {g(1, "running", "", stack)}},
+			time.Minute, nil, 0,
+		},
+		{
+			"moved to another site",
+			[][]Goroutine{{g(1, "chan receive", "", stack)}, {g(1, "chan receive", "", stack)}, {g(1, "chan receive", "", other)}},
+			time.Minute, nil, 0,
+		},
+		{
+			"wait reported by the runtime",
+			[][]Goroutine{{g(1, "sync.Mutex.Lock", "10 minutes", stack)}},
+			5 * time.Minute, []int{1}, 600,
+		},
+		{
+			"grouped by site",
+			[][]Goroutine{
+				{g(1, "select", "", stack), g(2, "select", "", stack), g(3, "chan send", "", other)},
+				{g(1, "select", "", stack), g(2, "select", "", stack), g(3, "chan send", "", other)},
+			},
+			time.Minute, []int{1, 2}, 60,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewStuckDetector()
+			for i, gs := range tt.snapshots {
+				d.Record(gs, start.Add(time.Duration(i)*time.Minute))
+			}
+			stuck := d.Stuck(tt.threshold)
+			var counts []int
+			for _, s := range stuck {
+				counts = append(counts, s.Count)
+			}
+			if len(counts) != len(tt.counts) {
+				t.Fatalf("counts = %v, want %v", counts, tt.counts)
+			}
+			for i := range counts {
+				if counts[i] != tt.counts[i] {
+					t.Errorf("counts = %v, want %v", counts, tt.counts)
+				}
+			}
+			if len(stuck) > 0 && stuck[0].Blocked != tt.blocked {
+				t.Errorf("blocked = %vs, want %vs", stuck[0].Blocked, tt.blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package heapprof samples the heap profile and aggregates the in-use
+// objects by allocation site, keeping a short trend per site.
+package heapprof
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/pprof/profile"
+)
+
+// MaxTrend is the number of samples kept per allocation site
+const MaxTrend = 30
+
+// Site is the in-use memory allocated at an allocation site
+type Site struct {
+	Site    string  `json:"site"`
+	Objects int64   `json:"objects"`
+	Bytes   int64   `json:"bytes"`
+	Trend   []int64 `json:"trend"`
+}
+
+// Capture reads the heap profile and returns the in-use objects and bytes by
+// allocation site, which is the innermost function outside of the runtime
+func Capture() (map[string]Site, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+		return nil, err
+	}
+	p, err := profile.Parse(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, space := -1, -1
+	for i, st := range p.SampleType {
+		switch st.Type {
+		case "inuse_objects":
+			objects = i
+		case "inuse_space":
+			space = i
+		}
+	}
+	sites := make(map[string]Site)
+	if objects < 0 || space < 0 {
+		return sites, nil
+	}
+
+	for _, s := range p.Sample {
+		name := siteOf(s.Location)
+		site := sites[name]
+		site.Site = name
+		site.Objects += s.Value[objects]
+		site.Bytes += s.Value[space]
+		sites[name] = site
+	}
+	return sites, nil
+}
+
+func siteOf(locs []*profile.Location) string {
+	first := ""
+	for _, loc := range locs {
+		for _, line := range loc.Line {
+			if line.Function == nil {
+				continue
+			}
+			if first == "" {
+				first = line.Function.Name
+			}
+			if !strings.HasPrefix(line.Function.Name, "runtime.") {
+				return line.Function.Name
+			}
+		}
+	}
+	if first == "" {
+		return "unknown"
+	}
+	return first
+}
+
+// Tracker keeps the trend of the in-use objects of every allocation site
+// over the last MaxTrend samples
+type Tracker struct {
+	mu      sync.Mutex
+	samples int
+	latest  map[string]Site
+	trends  map[string][]int64
+}
+
+// NewTracker returns an empty Tracker
+func NewTracker() *Tracker {
+	return &Tracker{trends: make(map[string][]int64)}
+}
+
+// Record captures the heap profile and appends it to the trends
+func (t *Tracker) Record() error {
+	sites, err := Capture()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples++
+	n := t.samples
+	if n > MaxTrend {
+		n = MaxTrend
+	}
+	for name := range sites {
+		if _, ok := t.trends[name]; !ok {
+			// the site appeared now, it had no objects before
+			t.trends[name] = make([]int64, n-1, n)
+		}
+	}
+	for name, trend := range t.trends {
+		trend = append(trend, sites[name].Objects)
+		if len(trend) > n {
+			trend = trend[len(trend)-n:]
+		}
+		if allZero(trend) {
+			delete(t.trends, name)
+			continue
+		}
+		t.trends[name] = trend
+	}
+	t.latest = sites
+	return nil
+}
+
+// Top returns the n sites with the most in-use bytes of the last sample
+func (t *Tracker) Top(n int) []Site {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	top := make([]Site, 0, len(t.latest))
+	for name, s := range t.latest {
+		s.Trend = append([]int64(nil), t.trends[name]...)
+		top = append(top, s)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Bytes != top[j].Bytes {
+			return top[i].Bytes > top[j].Bytes
+		}
+		return top[i].Site < top[j].Site
+	})
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+func allZero(vs []int64) bool {
+	for _, v := range vs {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Growth is the change of the in-use memory of an allocation site between
+// two profiles
+type Growth struct {
+	Site    string `json:"site"`
+	Objects int64  `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// Diff returns the growth of the sites from base to current like the
+// `-base` option of pprof, the most grown first. The sites which didn't
+// change are left out
+func Diff(base, current map[string]Site) []Growth {
+	diff := []Growth{}
+	add := func(name string) {
+		g := Growth{
+			Site:    name,
+			Objects: current[name].Objects - base[name].Objects,
+			Bytes:   current[name].Bytes - base[name].Bytes,
+		}
+		if g.Objects != 0 || g.Bytes != 0 {
+			diff = append(diff, g)
+		}
+	}
+	for name := range current {
+		add(name)
+	}
+	for name := range base {
+		if _, ok := current[name]; !ok {
+			add(name)
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		if diff[i].Bytes != diff[j].Bytes {
+			return diff[i].Bytes > diff[j].Bytes
+		}
+		return diff[i].Site < diff[j].Site
+	})
+	return diff
+}
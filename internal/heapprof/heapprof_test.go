@@ -0,0 +1,166 @@
+package heapprof
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+func TestSiteOf(t *testing.T) {
+	loc := func(names ...string) *profile.Location {
+		l := &profile.Location{}
+		for _, n := range names {
+			l.Line = append(l.Line, profile.Line{Function: &profile.Function{Name: n}})
+		}
+		return l
+	}
+	tests := []struct {
+		name string
+		locs []*profile.Location
+		want string
+	}{
+		{"no location", nil, "unknown"},
+		{"no function", []*profile.Location{{Line: []profile.Line{{}}}}, "unknown"},
+		{"innermost function", []*profile.Location{loc("main.alloc"), loc("main.main")}, "main.alloc"},
+		{"runtime frames skipped", []*profile.Location{loc("runtime.mallocgc"), loc("runtime.makeslice"), loc("main.alloc")}, "main.alloc"},
+		{"inlined frames", []*profile.Location{loc("runtime.newobject", "main.inlined"), loc("main.main")}, "main.inlined"},
+		{"runtime only", []*profile.Location{loc("runtime.malg"), loc("runtime.newproc")}, "runtime.malg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := siteOf(tt.locs); got != tt.want {
+				t.Errorf("siteOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+var retained [][]byte
+
+//go:noinline
+func allocate(n int) {
+	for i := 0; i < n; i++ {
+		retained = append(retained, make([]byte, 4096))
+	}
+}
+
+func TestCapture(t *testing.T) {
+	defer func(rate int) { runtime.MemProfileRate = rate }(runtime.MemProfileRate)
+	runtime.MemProfileRate = 1
+	defer func() { retained = nil }()
+
+	allocate(100)
+	// the heap profile reports the objects as of the last completed GC
+	runtime.GC()
+	runtime.GC()
+
+	sites, err := Capture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	site, ok := sites["github.com/mortum5/statsview/internal/heapprof.allocate"]
+	if !ok {
+		t.Fatalf("allocate isn't an allocation site in %v", sites)
+	}
+	if site.Objects < 100 || site.Bytes < 100*4096 {
+		t.Errorf("allocate has %d objects of %d bytes, want at least 100 of %d", site.Objects, site.Bytes, 100*4096)
+	}
+}
+
+func TestTrackerTop(t *testing.T) {
+	tr := NewTracker()
+	tr.latest = map[string]Site{
+		"a": {Site: "a", Objects: 1, Bytes: 10},
+		"b": {Site: "b", Objects: 2, Bytes: 30},
+		"c": {Site: "c", Objects: 3, Bytes: 10},
+	}
+	tr.trends = map[string][]int64{"a": {0, 1}, "b": {2, 2}, "c": {3}}
+
+	tests := []struct {
+		name string
+		n    int
+		want []Site
+	}{
+		{"all", 0, []Site{
+			{Site: "b", Objects: 2, Bytes: 30, Trend: []int64{2, 2}},
+			{Site: "a", Objects: 1, Bytes: 10, Trend: []int64{0, 1}},
+			{Site: "c", Objects: 3, Bytes: 10, Trend: []int64{3}},
+		}},
+		{"top one", 1, []Site{{Site: "b", Objects: 2, Bytes: 30, Trend: []int64{2, 2}}}},
+		{"more than the sites", 5, []Site{
+			{Site: "b", Objects: 2, Bytes: 30, Trend: []int64{2, 2}},
+			{Site: "a", Objects: 1, Bytes: 10, Trend: []int64{0, 1}},
+			{Site: "c", Objects: 3, Bytes: 10, Trend: []int64{3}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tr.Top(tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Top(%d) = %+v, want %+v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerRecord(t *testing.T) {
+	tr := NewTracker()
+	for i := 0; i < MaxTrend+5; i++ {
+		if err := tr.Record(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tr.samples != MaxTrend+5 {
+		t.Errorf("samples = %d, want %d", tr.samples, MaxTrend+5)
+	}
+	for name, trend := range tr.trends {
+		if len(trend) > MaxTrend {
+			t.Errorf("trend of %s has %d samples, want at most %d", name, len(trend), MaxTrend)
+		}
+		if allZero(trend) {
+			t.Errorf("trend of %s is kept without objects", name)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name          string
+		base, current map[string]Site
+		want          []Growth
+	}{
+		{"empty", nil, nil, []Growth{}},
+		{
+			"grown and shrunk",
+			map[string]Site{"a": {Objects: 1, Bytes: 10}, "b": {Objects: 5, Bytes: 50}},
+			map[string]Site{"a": {Objects: 3, Bytes: 40}, "b": {Objects: 4, Bytes: 40}},
+			[]Growth{{Site: "a", Objects: 2, Bytes: 30}, {Site: "b", Objects: -1, Bytes: -10}},
+		},
+		{
+			"unchanged left out",
+			map[string]Site{"a": {Objects: 1, Bytes: 10}},
+			map[string]Site{"a": {Objects: 1, Bytes: 10}},
+			[]Growth{},
+		},
+		{
+			"new and freed sites",
+			map[string]Site{"gone": {Objects: 2, Bytes: 20}},
+			map[string]Site{"new": {Objects: 1, Bytes: 8}},
+			[]Growth{{Site: "new", Objects: 1, Bytes: 8}, {Site: "gone", Objects: -2, Bytes: -20}},
+		},
+		{
+			"ties by site",
+			nil,
+			map[string]Site{"b": {Objects: 1, Bytes: 8}, "a": {Objects: 2, Bytes: 8}},
+			[]Growth{{Site: "a", Objects: 2, Bytes: 8}, {Site: "b", Objects: 1, Bytes: 8}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.base, tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Package lockprof samples the mutex profile and aggregates the contention
+// by lock site, keeping a short trend of the delay per site.
+package lockprof
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/pprof/profile"
+)
+
+// MaxTrend is the number of samples kept per lock site
+const MaxTrend = 30
+
+// Site is the contention of a lock site since the program started, the
+// delay is in seconds
+type Site struct {
+	Site        string    `json:"site"`
+	Contentions int64     `json:"contentions"`
+	Delay       float64   `json:"delay"`
+	Trend       []float64 `json:"trend"`
+}
+
+// Capture reads the mutex profile and returns the contentions and the delay
+// by lock site, which is the innermost function outside of the runtime and
+// of the sync packages: the one releasing the contended lock
+func Capture() (map[string]Site, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("mutex").WriteTo(&buf, 0); err != nil {
+		return nil, err
+	}
+	p, err := profile.Parse(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	contentions, delay := -1, -1
+	for i, st := range p.SampleType {
+		switch st.Type {
+		case "contentions":
+			contentions = i
+		case "delay":
+			delay = i
+		}
+	}
+	sites := make(map[string]Site)
+	if contentions < 0 || delay < 0 {
+		return sites, nil
+	}
+
+	for _, s := range p.Sample {
+		name := siteOf(s.Location)
+		site := sites[name]
+		site.Site = name
+		site.Contentions += s.Value[contentions]
+		// the delay is in nanoseconds, scaled by the sampling fraction already
+		site.Delay += float64(s.Value[delay]) / 1e9
+		sites[name] = site
+	}
+	return sites, nil
+}
+
+func siteOf(locs []*profile.Location) string {
+	first := ""
+	for _, loc := range locs {
+		for _, line := range loc.Line {
+			if line.Function == nil {
+				continue
+			}
+			name := line.Function.Name
+			if first == "" {
+				first = name
+			}
+			if !strings.HasPrefix(name, "runtime.") && !strings.HasPrefix(name, "sync.") &&
+				!strings.HasPrefix(name, "internal/sync.") {
+				return name
+			}
+		}
+	}
+	if first == "" {
+		return "unknown"
+	}
+	return first
+}
+
+// Tracker keeps the trend of the delay of every lock site over the last
+// MaxTrend samples, the delay accumulated between two samples
+type Tracker struct {
+	mu      sync.Mutex
+	samples int
+	latest  map[string]Site
+	trends  map[string][]float64
+}
+
+// NewTracker returns an empty Tracker
+func NewTracker() *Tracker {
+	return &Tracker{trends: make(map[string][]float64)}
+}
+
+// Record captures the mutex profile and appends the delay since the
+// previous sample to the trends
+func (t *Tracker) Record() error {
+	sites, err := Capture()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples++
+	n := t.samples
+	if n > MaxTrend {
+		n = MaxTrend
+	}
+	for name := range sites {
+		if _, ok := t.trends[name]; !ok {
+			t.trends[name] = make([]float64, n-1, n)
+		}
+	}
+	for name, trend := range t.trends {
+		// the profile is cumulative, the first sample has no delta yet
+		var delta float64
+		if t.samples > 1 {
+			delta = sites[name].Delay - t.latest[name].Delay
+		}
+		trend = append(trend, delta)
+		if len(trend) > n {
+			trend = trend[len(trend)-n:]
+		}
+		t.trends[name] = trend
+	}
+	t.latest = sites
+	return nil
+}
+
+// Top returns the n sites with the most cumulative delay of the last sample
+func (t *Tracker) Top(n int) []Site {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	top := make([]Site, 0, len(t.latest))
+	for name, s := range t.latest {
+		s.Trend = append([]float64(nil), t.trends[name]...)
+		top = append(top, s)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Delay != top[j].Delay {
+			return top[i].Delay > top[j].Delay
+		}
+		return top[i].Site < top[j].Site
+	})
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
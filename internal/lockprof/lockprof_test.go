@@ -0,0 +1,120 @@
+package lockprof
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+func TestSiteOf(t *testing.T) {
+	loc := func(names ...string) *profile.Location {
+		l := &profile.Location{}
+		for _, n := range names {
+			l.Line = append(l.Line, profile.Line{Function: &profile.Function{Name: n}})
+		}
+		return l
+	}
+	tests := []struct {
+		name string
+		locs []*profile.Location
+		want string
+	}{
+		{"no location", nil, "unknown"},
+		{"no function", []*profile.Location{{Line: []profile.Line{{}}}}, "unknown"},
+		{
+			"releasing function",
+			[]*profile.Location{loc("sync.(*Mutex).Unlock"), loc("main.(*cache).put"), loc("main.main")},
+			"main.(*cache).put",
+		},
+		{
+			"inlined frames",
+			[]*profile.Location{loc("internal/sync.(*Mutex).Unlock", "sync.(*Mutex).Unlock"), loc("runtime.unlock", "net/http.(*Transport).getConn")},
+			"net/http.(*Transport).getConn",
+		},
+		{"runtime only", []*profile.Location{loc("runtime.unlock"), loc("runtime.goexit")}, "runtime.unlock"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := siteOf(tt.locs); got != tt.want {
+				t.Errorf("siteOf() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// contend makes goroutines contend on a mutex, the site is contend itself
+func contend(mu *sync.Mutex) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				mu.Lock()
+				time.Sleep(100 * time.Microsecond)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTracker(t *testing.T) {
+	defer runtime.SetMutexProfileFraction(runtime.SetMutexProfileFraction(1))
+
+	// site returns the contended site of the test
+	site := func(sites []Site) (Site, bool) {
+		for _, s := range sites {
+			if strings.Contains(s.Site, "lockprof.contend") {
+				return s, true
+			}
+		}
+		return Site{}, false
+	}
+
+	var mu sync.Mutex
+	tr := NewTracker()
+	tests := []struct {
+		name    string
+		contend bool
+		// trend is the length of the trend, rising whether its last delay
+		// is positive
+		trend  int
+		rising bool
+	}{
+		{"first sample", true, 1, false},
+		{"contended", true, 2, true},
+		{"quiet", false, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.contend {
+				contend(&mu)
+			}
+			if err := tr.Record(); err != nil {
+				t.Fatal(err)
+			}
+			s, ok := site(tr.Top(0))
+			if !ok {
+				t.Fatal("the contended site isn't reported")
+			}
+			if s.Contentions == 0 || s.Delay <= 0 {
+				t.Errorf("%d contentions for %vs, want the contention of the site", s.Contentions, s.Delay)
+			}
+			if len(s.Trend) != tt.trend {
+				t.Fatalf("trend = %v, want %d samples", s.Trend, tt.trend)
+			}
+			if rising := s.Trend[len(s.Trend)-1] > 0; rising != tt.rising {
+				t.Errorf("trend = %v, want rising %v", s.Trend, tt.rising)
+			}
+		})
+	}
+
+	if top := tr.Top(1); len(top) != 1 {
+		t.Errorf("Top(1) returned %d sites", len(top))
+	}
+}
@@ -0,0 +1,119 @@
+// Package memtrend fits a line on the recent memory samples to detect a
+// sustained growth and project when it would reach the memory limit
+package memtrend
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// Window is the number of samples the trend is fitted on
+	Window = 30
+	// MinSamples is the number of samples needed before the trend is judged
+	MinSamples = 12
+	// MinFit is the coefficient of determination a growth needs to be
+	// considered sustained rather than a saw-tooth of the GC
+	MinFit = 0.8
+	// MinGrowth is the growth over the window, as a fraction of the first
+	// sample, a series needs to be warned about
+	MinGrowth = 0.05
+)
+
+// Sample is the memory measured at a time in bytes
+type Sample struct {
+	Time   time.Time
+	Values map[string]float64
+}
+
+// Warning is a series growing steadily
+type Warning struct {
+	Series string `json:"series"`
+	// Current is the last sample in bytes
+	Current float64 `json:"current"`
+	// Rate is the fitted growth in bytes per second
+	Rate float64 `json:"rate"`
+	Fit  float64 `json:"fit"`
+	// Limit is the memory limit in bytes the series is projected against,
+	// LimitSource names it, 0 when there is no limit
+	Limit       float64 `json:"limit,omitempty"`
+	LimitSource string  `json:"limitSource,omitempty"`
+	// ETA is the projected time until the limit is reached, 0 without limit
+	ETA time.Duration `json:"eta,omitempty"`
+}
+
+// Detector keeps the last Window samples of every series
+type Detector struct {
+	mu      sync.Mutex
+	samples []Sample
+}
+
+// Record appends a sample and drops the ones out of the window
+func (d *Detector) Record(s Sample) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.samples = append(d.samples, s)
+	if len(d.samples) > Window {
+		d.samples = append(d.samples[:0], d.samples[len(d.samples)-Window:]...)
+	}
+}
+
+// Warnings returns the series of names growing steadily, projected against
+// limit when it is positive
+func (d *Detector) Warnings(names []string, limit float64, source string) []Warning {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	warnings := []Warning{}
+	if len(d.samples) < MinSamples {
+		return warnings
+	}
+
+	t0 := d.samples[0].Time
+	xs := make([]float64, len(d.samples))
+	ys := make([]float64, len(d.samples))
+	for _, name := range names {
+		for i, s := range d.samples {
+			xs[i] = s.Time.Sub(t0).Seconds()
+			ys[i] = s.Values[name]
+		}
+
+		rate, fit := linearFit(xs, ys)
+		first, last := ys[0], ys[len(ys)-1]
+		if rate <= 0 || fit < MinFit || first <= 0 || (last-first)/first < MinGrowth {
+			continue
+		}
+
+		w := Warning{Series: name, Current: last, Rate: rate, Fit: fit}
+		if limit > 0 {
+			w.Limit, w.LimitSource = limit, source
+			w.ETA = time.Duration(math.Max(limit-last, 0) / rate * float64(time.Second))
+		}
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// linearFit returns the slope of the least squares line through the points
+// and its coefficient of determination
+func linearFit(xs, ys []float64) (slope, r2 float64) {
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy, syy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+		syy += ys[i] * ys[i]
+	}
+
+	vx := n*sxx - sx*sx
+	vy := n*syy - sy*sy
+	if vx == 0 || vy == 0 {
+		return 0, 0
+	}
+	cov := n*sxy - sx*sy
+	return cov / vx, cov * cov / (vx * vy)
+}
@@ -0,0 +1,96 @@
+package memtrend
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// detectorOf records n samples every 10s of the series valued by f
+func detectorOf(n int, f func(i int) float64) *Detector {
+	d := &Detector{}
+	t0 := time.Unix(1700000000, 0)
+	for i := 0; i < n; i++ {
+		d.Record(Sample{Time: t0.Add(time.Duration(i) * 10 * time.Second), Values: map[string]float64{"heap": f(i)}})
+	}
+	return d
+}
+
+func TestWarnings(t *testing.T) {
+	linear := func(i int) float64 { return 1000 + 10*float64(i) }
+	tests := []struct {
+		name    string
+		samples int
+		value   func(i int) float64
+		limit   float64
+		// rate is the expected growth in bytes per second, 0 without warning
+		rate float64
+		eta  time.Duration
+	}{
+		{"too few samples", MinSamples - 1, linear, 0, 0, 0},
+		{"steady growth", MinSamples, linear, 0, 1, 0},
+		{"projected to the limit", MinSamples, linear, 1210, 1, 100 * time.Second},
+		{"limit already reached", MinSamples, linear, 1000, 1, 0},
+		{"window", 2 * Window, linear, 0, 1, 0},
+		{"flat", Window, func(int) float64 { return 1000 }, 0, 0, 0},
+		{"decreasing", Window, func(i int) float64 { return 2000 - 10*float64(i) }, 0, 0, 0},
+		{"below the minimum growth", Window, func(i int) float64 { return 100000 + float64(i) }, 0, 0, 0},
+		{"saw-tooth", Window, func(i int) float64 { return 1000 + float64(i%2)*500 + float64(i) }, 0, 0, 0},
+		{"nothing at first", Window, func(i int) float64 { return 10 * float64(i) }, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := detectorOf(tt.samples, tt.value).Warnings([]string{"heap"}, tt.limit, "GOMEMLIMIT")
+			if tt.rate == 0 {
+				if len(warnings) != 0 {
+					t.Fatalf("got %+v, want no warning", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 {
+				t.Fatalf("got %d warnings, want 1", len(warnings))
+			}
+			w := warnings[0]
+			if w.Series != "heap" || math.Abs(w.Rate-tt.rate) > 1e-9 || math.Abs(w.Fit-1) > 1e-9 {
+				t.Errorf("got %+v, want a rate of %v with a perfect fit", w, tt.rate)
+			}
+			if w.ETA.Round(time.Millisecond) != tt.eta {
+				t.Errorf("eta = %v, want %v", w.ETA, tt.eta)
+			}
+			if got, want := w.Limit, tt.limit; got != want {
+				t.Errorf("limit = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRecordKeepsTheWindow(t *testing.T) {
+	d := detectorOf(Window+7, func(i int) float64 { return float64(i) })
+	if len(d.samples) != Window {
+		t.Fatalf("kept %d samples, want %d", len(d.samples), Window)
+	}
+	if first := d.samples[0].Values["heap"]; first != 7 {
+		t.Errorf("first sample = %v, want 7", first)
+	}
+}
+
+func TestLinearFit(t *testing.T) {
+	tests := []struct {
+		name      string
+		xs, ys    []float64
+		slope, r2 float64
+	}{
+		{"line", []float64{0, 1, 2}, []float64{1, 3, 5}, 2, 1},
+		{"flat", []float64{0, 1, 2}, []float64{4, 4, 4}, 0, 0},
+		{"single x", []float64{1, 1, 1}, []float64{1, 2, 3}, 0, 0},
+		{"noisy", []float64{0, 1, 2, 3}, []float64{0, 2, 1, 3}, 0.8, 0.64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slope, r2 := linearFit(tt.xs, tt.ys)
+			if math.Abs(slope-tt.slope) > 1e-9 || math.Abs(r2-tt.r2) > 1e-9 {
+				t.Errorf("linearFit() = %v, %v, want %v, %v", slope, r2, tt.slope, tt.r2)
+			}
+		})
+	}
+}
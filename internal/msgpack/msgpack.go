@@ -0,0 +1,283 @@
+// Package msgpack encodes Go values into MessagePack (https://msgpack.org).
+// Values are mapped like encoding/json does: structs become maps keyed by
+// their json tag names, json.Marshaler types are encoded via their JSON form.
+package msgpack
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Marshal returns the MessagePack encoding of v
+func Marshal(v interface{}) ([]byte, error) {
+	e := &encoder{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Raw is an encoded MessagePack value, it's written as it is like
+// json.RawMessage is by encoding/json
+type Raw []byte
+
+type encoder struct {
+	buf []byte
+}
+
+var (
+	rawType        = reflect.TypeOf(Raw(nil))
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+func (e *encoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+
+	if v.Type() == rawType {
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		e.buf = append(e.buf, v.Bytes()...)
+		return nil
+	}
+	if v.Type() == rawMessageType || v.Type().Implements(marshalerType) {
+		return e.encodeJSON(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(v.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.encodeString(v.String())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.encodeBytes(v.Bytes())
+			return nil
+		}
+		return e.encodeArray(v)
+	case reflect.Array:
+		return e.encodeArray(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encodeMap(v)
+	case reflect.Struct:
+		return e.encodeStruct(v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// encodeJSON encodes a value through its JSON form
+func (e *encoder) encodeJSON(v reflect.Value) error {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+	bs, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(bs, &generic); err != nil {
+		return err
+	}
+	return e.encode(reflect.ValueOf(generic))
+}
+
+func (e *encoder) encodeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.encodeUint(uint64(n))
+	case n >= -32:
+		e.buf = append(e.buf, byte(int8(n)))
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(int8(n)))
+	case n >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(int16(n)))
+	case n >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(int32(n)))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(n))
+	}
+}
+
+func (e *encoder) encodeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf = append(e.buf, byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+	}
+}
+
+// encodeHeader writes the header of a sized type, fix is the fix format
+// marker (0 if there is none) and codes are the 8, 16 and 32 bit markers
+func (e *encoder) encodeHeader(n int, fix byte, fixMax int, codes [3]byte) {
+	switch {
+	case fix != 0 && n <= fixMax:
+		e.buf = append(e.buf, fix|byte(n))
+	case codes[0] != 0 && n <= math.MaxUint8:
+		e.buf = append(e.buf, codes[0], byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, codes[1])
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, codes[2])
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *encoder) encodeString(s string) {
+	e.encodeHeader(len(s), 0xa0, 31, [3]byte{0xd9, 0xda, 0xdb})
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) encodeBytes(bs []byte) {
+	e.encodeHeader(len(bs), 0, 0, [3]byte{0xc4, 0xc5, 0xc6})
+	e.buf = append(e.buf, bs...)
+}
+
+func (e *encoder) encodeArray(v reflect.Value) error {
+	e.encodeHeader(v.Len(), 0x90, 15, [3]byte{0, 0xdc, 0xdd})
+	for i := 0; i < v.Len(); i++ {
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) encodeMap(v reflect.Value) error {
+	keys := v.MapKeys()
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		if k.Kind() != reflect.String {
+			return fmt.Errorf("msgpack: unsupported map key type %s", k.Type())
+		}
+		names[i] = k.String()
+	}
+	sort.Sort(byName{names, keys})
+
+	e.encodeHeader(len(keys), 0x80, 15, [3]byte{0, 0xde, 0xdf})
+	for i, k := range keys {
+		e.encodeString(names[i])
+		if err := e.encode(v.MapIndex(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type byName struct {
+	names []string
+	keys  []reflect.Value
+}
+
+func (b byName) Len() int           { return len(b.names) }
+func (b byName) Less(i, j int) bool { return b.names[i] < b.names[j] }
+func (b byName) Swap(i, j int) {
+	b.names[i], b.names[j] = b.names[j], b.names[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
+
+type field struct {
+	name  string
+	value reflect.Value
+}
+
+// fields returns the encoded fields of a struct following the json tags,
+// embedded structs without a tag are flattened
+func fields(v reflect.Value) []field {
+	var fs []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+
+		if sf.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			fs = append(fs, fields(fv)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if strings.Contains(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+		if k := fv.Kind(); strings.Contains(opts, "omitempty") && (k == reflect.Slice || k == reflect.Map) && fv.Len() == 0 {
+			continue
+		}
+		fs = append(fs, field{name: name, value: fv})
+	}
+	return fs
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	fs := fields(v)
+	e.encodeHeader(len(fs), 0x80, 15, [3]byte{0, 0xde, 0xdf})
+	for _, f := range fs {
+		e.encodeString(f.name)
+		if err := e.encode(f.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+type point struct {
+	Name   string   `json:"name"`
+	Value  float64  `json:"value"`
+	Unit   string   `json:"unit,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+	Hidden int      `json:"-"`
+	skip   int
+}
+
+type wrapper struct {
+	point
+	Count uint8 `json:"count"`
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"true", true, []byte{0xc3}},
+		{"false", false, []byte{0xc2}},
+		{"positive fixint", 5, []byte{0x05}},
+		{"uint8", 200, []byte{0xcc, 0xc8}},
+		{"uint16", 1000, []byte{0xcd, 0x03, 0xe8}},
+		{"uint32", 70000, []byte{0xce, 0x00, 0x01, 0x11, 0x70}},
+		{"uint64", uint64(math.MaxUint32) + 1, []byte{0xcf, 0, 0, 0, 0x01, 0, 0, 0, 0}},
+		{"negative fixint", -3, []byte{0xfd}},
+		{"int8", -100, []byte{0xd0, 0x9c}},
+		{"int16", -1000, []byte{0xd1, 0xfc, 0x18}},
+		{"int32", -70000, []byte{0xd2, 0xff, 0xfe, 0xee, 0x90}},
+		{"int64", int64(math.MinInt32) - 1, []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff}},
+		{"float32", float32(1.5), []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}},
+		{"float64", 1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", "abc", []byte{0xa3, 'a', 'b', 'c'}},
+		{"str8", strings.Repeat("x", 32), append([]byte{0xd9, 32}, strings.Repeat("x", 32)...)},
+		{"bin", []byte{1, 2}, []byte{0xc4, 0x02, 0x01, 0x02}},
+		{"nil slice", []int(nil), []byte{0xc0}},
+		{"fixarray", []int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{"array", [2]bool{true, false}, []byte{0x92, 0xc3, 0xc2}},
+		{"map sorted by key", map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+		{"nil map", map[string]int(nil), []byte{0xc0}},
+		{"nil pointer", (*point)(nil), []byte{0xc0}},
+		{
+			"struct by json tags",
+			point{Name: "a", Value: 0, Hidden: 1, skip: 2},
+			[]byte{0x82, 0xa4, 'n', 'a', 'm', 'e', 0xa1, 'a', 0xa5, 'v', 'a', 'l', 'u', 'e', 0xcb, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			"embedded struct flattened",
+			wrapper{point: point{Name: "a"}, Count: 1},
+			[]byte{0x83, 0xa4, 'n', 'a', 'm', 'e', 0xa1, 'a', 0xa5, 'v', 'a', 'l', 'u', 'e', 0xcb, 0, 0, 0, 0, 0, 0, 0, 0,
+				0xa5, 'c', 'o', 'u', 'n', 't', 0x01},
+		},
+		{"raw json", json.RawMessage(`[1,"a"]`), []byte{0x92, 0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0, 0xa1, 'a'}},
+		{"raw", map[string]Raw{"a": {0x92, 0x01, 0x02}}, []byte{0x81, 0xa1, 'a', 0x92, 0x01, 0x02}},
+		{"nil raw", Raw(nil), []byte{0xc0}},
+		{
+			"json marshaler",
+			time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			append([]byte{0xb4}, "2024-01-02T03:04:05Z"...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal() = % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"channel", make(chan int)},
+		{"int keys", map[int]string{1: "a"}},
+		{"func field", struct{ F func() }{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(tt.v); err == nil {
+				t.Error("Marshal() succeeded, want an error")
+			}
+		})
+	}
+}
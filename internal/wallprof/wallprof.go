@@ -0,0 +1,139 @@
+// Package wallprof samples the stacks of all goroutines at a fixed rate,
+// whether they're running or waiting, and builds a wall-clock pprof profile
+// of them in the manner of fgprof.
+package wallprof
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// Stacks returns the stacks of all goroutines but the calling one
+func Stacks() [][]uintptr {
+	records := make([]runtime.StackRecord, runtime.NumGoroutine()+10)
+	for {
+		n, ok := runtime.GoroutineProfile(records)
+		if ok {
+			records = records[:n]
+			break
+		}
+		records = make([]runtime.StackRecord, n+10)
+	}
+
+	// the calling goroutine is recorded first
+	stacks := make([][]uintptr, 0, len(records))
+	for i := 1; i < len(records); i++ {
+		stacks = append(stacks, records[i].Stack())
+	}
+	return stacks
+}
+
+// OffCPU returns whether the goroutine of the stack is parked, i.e. waiting
+// on a channel, a lock, the network or a timer. The goroutines running,
+// runnable or in a syscall are on CPU
+func OffCPU(stack []uintptr) bool {
+	if len(stack) == 0 {
+		return false
+	}
+	frame, _ := runtime.CallersFrames(stack[:1]).Next()
+	return frame.Function == "runtime.gopark" || frame.Function == "runtime.goparkunlock"
+}
+
+// Profile samples the goroutines hz times per second for d or until ctx is
+// done, every sample of a stack accounts for the period in the wall-clock
+// time of its functions
+func Profile(ctx context.Context, d time.Duration, hz int) *profile.Profile {
+	period := time.Second / time.Duration(hz)
+	counts := make(map[string]int64)
+	stacks := make(map[string][]uintptr)
+
+	start := time.Now()
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+sampling:
+	for {
+		select {
+		case <-ticker.C:
+			for _, s := range Stacks() {
+				key := stackKey(s)
+				if _, ok := stacks[key]; !ok {
+					stacks[key] = s
+				}
+				counts[key]++
+			}
+		case <-timer.C:
+			break sampling
+		case <-ctx.Done():
+			break sampling
+		}
+	}
+	return build(stacks, counts, start, time.Since(start), period)
+}
+
+func stackKey(stack []uintptr) string {
+	var sb strings.Builder
+	for _, pc := range stack {
+		sb.WriteString(strconv.FormatUint(uint64(pc), 16))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+// build converts the sampled stacks into a profile, the frames are
+// symbolized here since the profile may be opened without the binary
+func build(stacks map[string][]uintptr, counts map[string]int64, start time.Time, d, period time.Duration) *profile.Profile {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "time", Unit: "nanoseconds"},
+		},
+		PeriodType:    &profile.ValueType{Type: "wallclock", Unit: "nanoseconds"},
+		Period:        int64(period),
+		TimeNanos:     start.UnixNano(),
+		DurationNanos: int64(d),
+	}
+
+	functions := make(map[string]*profile.Function)
+	locations := make(map[uintptr]*profile.Location)
+	for key, stack := range stacks {
+		sample := &profile.Sample{Value: []int64{counts[key], counts[key] * int64(period)}}
+		for _, pc := range stack {
+			loc, ok := locations[pc]
+			if !ok {
+				loc = &profile.Location{ID: uint64(len(p.Location) + 1), Address: uint64(pc)}
+				// the inlined frames of pc come first, the frame of pc last
+				frames := runtime.CallersFrames([]uintptr{pc})
+				for {
+					frame, more := frames.Next()
+					fn, ok := functions[frame.Function]
+					if !ok {
+						fn = &profile.Function{
+							ID:         uint64(len(p.Function) + 1),
+							Name:       frame.Function,
+							SystemName: frame.Function,
+							Filename:   frame.File,
+						}
+						functions[frame.Function] = fn
+						p.Function = append(p.Function, fn)
+					}
+					loc.Line = append(loc.Line, profile.Line{Function: fn, Line: int64(frame.Line)})
+					if !more {
+						break
+					}
+				}
+				locations[pc] = loc
+				p.Location = append(p.Location, loc)
+			}
+			sample.Location = append(sample.Location, loc)
+		}
+		p.Sample = append(p.Sample, sample)
+	}
+	return p
+}
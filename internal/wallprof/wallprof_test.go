@@ -0,0 +1,129 @@
+package wallprof
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parked blocks on ch, its goroutine is off CPU
+func parked(ch chan struct{}) {
+	<-ch
+}
+
+// stackOf returns the stack of the goroutine running fn
+func stackOf(t *testing.T, fn string) []uintptr {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		for _, s := range Stacks() {
+			frames := runtime.CallersFrames(s)
+			for {
+				frame, more := frames.Next()
+				if strings.HasSuffix(frame.Function, fn) {
+					return s
+				}
+				if !more {
+					break
+				}
+			}
+		}
+	}
+	t.Fatalf("no goroutine in %s", fn)
+	return nil
+}
+
+func TestOffCPU(t *testing.T) {
+	ch := make(chan struct{})
+	defer close(ch)
+	go parked(ch)
+	spin := make(chan struct{})
+	defer close(spin)
+	go func() {
+		for {
+			select {
+			case <-spin:
+				return
+			default:
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	tests := []struct {
+		name  string
+		stack func() []uintptr
+		want  bool
+	}{
+		{"empty", func() []uintptr { return nil }, false},
+		{"parked on a channel", func() []uintptr { return stackOf(t, ".parked") }, true},
+		{"running", func() []uintptr {
+			pcs := make([]uintptr, 32)
+			return pcs[:runtime.Callers(1, pcs)]
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OffCPU(tt.stack()); got != tt.want {
+				t.Errorf("OffCPU() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStacksLeaveOutTheCaller(t *testing.T) {
+	stacks := Stacks()
+	if len(stacks) != runtime.NumGoroutine()-1 {
+		t.Errorf("got %d stacks for %d goroutines", len(stacks), runtime.NumGoroutine())
+	}
+}
+
+func TestProfile(t *testing.T) {
+	ch := make(chan struct{})
+	defer close(ch)
+	go parked(ch)
+	stackOf(t, ".parked")
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		d       time.Duration
+		sampled bool
+	}{
+		{"sampled", context.Background(), 100 * time.Millisecond, true},
+		{"cancelled", cancelled, time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			p := Profile(tt.ctx, tt.d, 100)
+			if elapsed := time.Since(start); elapsed > tt.d+time.Second {
+				t.Errorf("Profile() took %v", elapsed)
+			}
+			if err := p.CheckValid(); err != nil {
+				t.Fatal(err)
+			}
+			if p.Period != int64(10*time.Millisecond) || p.PeriodType.Type != "wallclock" {
+				t.Errorf("period = %d %s, want 10ms of wall clock", p.Period, p.PeriodType.Type)
+			}
+
+			var found bool
+			for _, s := range p.Sample {
+				if s.Value[1] != s.Value[0]*p.Period {
+					t.Errorf("sample of %d counts for %dns", s.Value[0], s.Value[1])
+				}
+				for _, loc := range s.Location {
+					for _, line := range loc.Line {
+						found = found || strings.HasSuffix(line.Function.Name, ".parked")
+					}
+				}
+			}
+			if found != tt.sampled {
+				t.Errorf("parked sampled = %v, want %v", found, tt.sampled)
+			}
+		})
+	}
+}
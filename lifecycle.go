@@ -0,0 +1,74 @@
+package statsview
+
+import (
+	"sync/atomic"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// run initializes the viewers and starts the flight recorder, the exporters,
+// the history and the registrars once, for Start and Handler alike
+func (vm *ViewManager) run() error {
+	if err := vm.initViewers(); err != nil {
+		return err
+	}
+	if !atomic.CompareAndSwapInt32(&vm.running, 0, 1) {
+		return nil
+	}
+
+	if window := viewer.FlightRecorderWindow(); window > 0 {
+		recorder, err := newFlightRecorder(window)
+		if err != nil {
+			viewer.Logger().Error("statsview: failed to start flight recorder", "err", err)
+			atomic.StoreInt32(&vm.running, 0)
+			return err
+		}
+		vm.recorderMu.Lock()
+		vm.recorder = recorder
+		vm.recorderMu.Unlock()
+	}
+
+	if len(vm.exporters) > 0 {
+		vm.exportWg.Add(1)
+		go vm.exportLoop()
+	}
+	if (viewer.HistoryWindow() > 0 || vm.anomalies != nil) && vm.sessionStart.IsZero() {
+		go vm.historyLoop()
+	}
+	vm.register()
+	return nil
+}
+
+// initViewers initializes the viewers once, the viewers initialized before a
+// failure are closed again. Stop closes them unless the initialization failed
+func (vm *ViewManager) initViewers() error {
+	vm.initOnce.Do(func() {
+		views := vm.views()
+		for i, v := range views {
+			in, ok := v.(viewer.Initializer)
+			if !ok {
+				continue
+			}
+			if err := in.Init(vm.Ctx); err != nil {
+				viewer.Logger().Error("statsview: failed to init viewer", "viewer", v.Name(), "err", err)
+				vm.closeViewers(views[:i])
+				vm.initErr = err
+				return
+			}
+		}
+		atomic.StoreInt32(&vm.initialized, 1)
+	})
+	return vm.initErr
+}
+
+func (vm *ViewManager) closeViewers(views []viewer.Viewer) {
+	for _, v := range views {
+		c, ok := v.(viewer.Closer)
+		if !ok {
+			continue
+		}
+		if err := c.Close(); err != nil {
+			viewer.Logger().Error("statsview: failed to close viewer", "viewer", v.Name(), "err", err)
+		}
+	}
+}
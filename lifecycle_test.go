@@ -0,0 +1,152 @@
+package statsview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// lifecycleViewer records the calls of its Init and Close hooks
+type lifecycleViewer struct {
+	viewer.Viewer
+	name    string
+	initErr error
+	inits   int
+	closes  int
+}
+
+func (vr *lifecycleViewer) Name() string { return vr.name }
+
+func (vr *lifecycleViewer) Init(context.Context) error {
+	vr.inits++
+	return vr.initErr
+}
+
+func (vr *lifecycleViewer) Close() error {
+	vr.closes++
+	return nil
+}
+
+func TestInitViewers(t *testing.T) {
+	errInit := errors.New("init failed")
+	tests := []struct {
+		name     string
+		initErrs []error
+		inits    []int
+		// closes are counted after Stop
+		closes []int
+	}{
+		{"initialized", []error{nil, nil}, []int{1, 1}, []int{1, 1}},
+		{"first fails", []error{errInit, nil}, []int{1, 0}, []int{0, 0}},
+		{"second fails", []error{nil, errInit}, []int{1, 1}, []int{1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			views := make([]*lifecycleViewer, len(tt.initErrs))
+			vs := make(Viewers, 0, len(tt.initErrs))
+			for i, err := range tt.initErrs {
+				views[i] = &lifecycleViewer{Viewer: viewer.NewGoroutinesViewer(), name: fmt.Sprintf("viewer%d", i), initErr: err}
+				vs = append(vs, views[i])
+			}
+			// the plain viewers have no hooks
+			vs = append(vs, viewer.NewGCNumViewer())
+
+			mgr, err := New(vs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = mgr.initViewers()
+			if again := mgr.initViewers(); !errors.Is(err, again) {
+				t.Errorf("the second initialization = %v, want %v", again, err)
+			}
+			mgr.Stop()
+			mgr.Stop()
+
+			wantErr := tt.initErrs[0] != nil || tt.initErrs[1] != nil
+			if (err != nil) != wantErr {
+				t.Errorf("initViewers() = %v, want error %v", err, wantErr)
+			}
+			for i, v := range views {
+				if v.inits != tt.inits[i] || v.closes != tt.closes[i] {
+					t.Errorf("viewer %d: %d inits and %d closes, want %d and %d", i, v.inits, v.closes, tt.inits[i], tt.closes[i])
+				}
+			}
+		})
+	}
+}
+
+type closingViewer struct {
+	viewer.Viewer
+	closed bool
+}
+
+func (vr *closingViewer) Collect() []viewer.Point {
+	if vr.closed {
+		return nil
+	}
+	return []viewer.Point{{Viewer: vr.Name(), Series: "Last", Value: 1, Time: time.Now()}}
+}
+
+func (vr *closingViewer) Close() error {
+	vr.closed = true
+	return nil
+}
+
+type recordingExporter struct {
+	samples []exporter.Sample
+	flushed bool
+}
+
+func (e *recordingExporter) Export(_ context.Context, samples []exporter.Sample) error {
+	e.samples = append(e.samples, samples...)
+	return nil
+}
+
+func (e *recordingExporter) Flush(context.Context) error {
+	e.flushed = true
+	return nil
+}
+
+func TestStopFlushesBeforeClosing(t *testing.T) {
+	v := &closingViewer{Viewer: viewer.NewGoroutinesViewer()}
+	mgr, err := New(Viewers{v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &recordingExporter{}
+	mgr.AddExporter(exp, time.Second)
+	if err := mgr.initViewers(); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr.Stop()
+	if !v.closed {
+		t.Error("the viewer wasn't closed")
+	}
+	if !exp.flushed || len(exp.samples) != 1 {
+		t.Errorf("flushed %v with %d samples, want the last sample of the open viewer", exp.flushed, len(exp.samples))
+	}
+}
+
+func TestNewAddsConfiguredExporters(t *testing.T) {
+	defer viewer.RestoreConfiguration(viewer.SaveConfiguration())
+	exp := &recordingExporter{}
+	viewer.SetConfiguration(viewer.WithExporters(exp))
+
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.initViewers(); err != nil {
+		t.Fatal(err)
+	}
+	mgr.Stop()
+	if !exp.flushed {
+		t.Error("the configured exporter wasn't flushed on Stop")
+	}
+}
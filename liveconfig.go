@@ -0,0 +1,113 @@
+package statsview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// liveConfig is the configuration which could be changed while the server
+// runs, the zero fields of a PUT are left unchanged
+type liveConfig struct {
+	// Interval is the collecting interval in milliseconds
+	Interval  int    `json:"interval"`
+	MaxPoints int    `json:"maxPoints"`
+	Theme     string `json:"theme"`
+	// Viewers are the served viewers, in the order of Available
+	Viewers []string `json:"viewers"`
+	// Available are the viewers given to the manager and registered, a PUT
+	// ignores them
+	Available []string `json:"available,omitempty"`
+}
+
+func (vm *ViewManager) currentConfig() liveConfig {
+	c := liveConfig{
+		Interval:  viewer.Interval(),
+		MaxPoints: viewer.MaxPoints(),
+		Theme:     string(viewer.ChartTheme()),
+		Viewers:   []string{},
+		Available: vm.knownNames(),
+	}
+	for _, v := range vm.views() {
+		c.Viewers = append(c.Viewers, v.Name())
+	}
+	return c
+}
+
+// applyConfig validates c and applies its fields, every change annotates the
+// charts
+func (vm *ViewManager) applyConfig(c liveConfig) error {
+	var (
+		opts  []viewer.Option
+		notes []string
+		prev  = vm.currentConfig()
+	)
+	switch {
+	case c.Interval < 0:
+		return fmt.Errorf("statsview: invalid interval %d", c.Interval)
+	case c.Interval > 0 && c.Interval != prev.Interval:
+		opts = append(opts, viewer.WithInterval(c.Interval))
+		notes = append(notes, fmt.Sprintf("interval %dms → %dms", prev.Interval, c.Interval))
+	}
+	switch {
+	case c.MaxPoints < 0:
+		return fmt.Errorf("statsview: invalid max points %d", c.MaxPoints)
+	case c.MaxPoints > 0 && c.MaxPoints != prev.MaxPoints:
+		opts = append(opts, viewer.WithMaxPoints(c.MaxPoints))
+		notes = append(notes, fmt.Sprintf("max points %d → %d", prev.MaxPoints, c.MaxPoints))
+	}
+	if c.Theme != "" {
+		theme := viewer.Theme(strings.ToLower(c.Theme))
+		if theme != viewer.ThemeWesteros && theme != viewer.ThemeMacarons {
+			return fmt.Errorf("statsview: unknown theme %q", c.Theme)
+		}
+		if string(theme) != prev.Theme {
+			opts = append(opts, viewer.WithTheme(theme))
+			notes = append(notes, fmt.Sprintf("theme %s → %s", prev.Theme, theme))
+		}
+	}
+	if c.Viewers != nil {
+		if len(c.Viewers) == 0 {
+			return errors.New("statsview: at least one viewer must be served")
+		}
+		if err := vm.serveOnly(c.Viewers); err != nil {
+			return err
+		}
+		opts = append(opts, viewer.WithViewers(c.Viewers...))
+		if served := strings.Join(vm.currentConfig().Viewers, ", "); served != strings.Join(prev.Viewers, ", ") {
+			notes = append(notes, "viewers "+served)
+		}
+	}
+
+	if err := viewer.SetConfiguration(opts...); err != nil {
+		return err
+	}
+	for _, note := range notes {
+		vm.Annotate("Configuration: " + note)
+	}
+	return nil
+}
+
+// serveConfig reports the live configuration, an authenticated PUT of a JSON
+// liveConfig changes it. The collection follows right away, the dashboards
+// reload
+func (vm *ViewManager) serveConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPut {
+		var c liveConfig
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c); err != nil {
+			http.Error(w, "statsview: invalid configuration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := vm.applyConfig(c); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	writeData(w, r, vm.currentConfig())
+}
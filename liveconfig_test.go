@@ -0,0 +1,130 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestServeConfig(t *testing.T) {
+	defer viewer.SetConfiguration(
+		viewer.WithAdminToken(""), viewer.WithViewers(), viewer.WithTheme(viewer.DefaultTheme),
+		viewer.WithInterval(viewer.DefaultInterval), viewer.WithMaxPoints(viewer.DefaultMaxPoints),
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		auth   bool
+		status int
+		// want is the configuration afterwards, the served viewers and the
+		// annotations
+		want   liveConfig
+		served map[string]int
+		notes  []string
+	}{
+		{
+			name:   "read",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			want:   liveConfig{Interval: viewer.DefaultInterval, MaxPoints: viewer.DefaultMaxPoints, Theme: string(viewer.DefaultTheme), Viewers: []string{"goroutine", "heap"}},
+		},
+		{
+			name:   "change without the admin token",
+			method: http.MethodPut, body: `{"interval": 1000}`,
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "interval, max points and theme",
+			method: http.MethodPut, body: `{"interval": 1000, "maxPoints": 60, "theme": "Westeros"}`, auth: true,
+			status: http.StatusOK,
+			want:   liveConfig{Interval: 1000, MaxPoints: 60, Theme: "westeros", Viewers: []string{"goroutine", "heap"}},
+			notes:  []string{"Configuration: interval 2000ms → 1000ms", "Configuration: max points 30 → 60", "Configuration: theme macarons → westeros"},
+		},
+		{
+			name:   "viewers",
+			method: http.MethodPut, body: `{"viewers": ["heap"]}`, auth: true,
+			status: http.StatusOK,
+			want:   liveConfig{Interval: viewer.DefaultInterval, MaxPoints: viewer.DefaultMaxPoints, Theme: string(viewer.DefaultTheme), Viewers: []string{"heap"}},
+			served: map[string]int{"heap": http.StatusOK, "goroutine": http.StatusNotFound},
+			notes:  []string{"Configuration: viewers heap"},
+		},
+		{
+			name:   "unchanged",
+			method: http.MethodPut, body: `{"interval": 2000, "viewers": ["heap", "goroutine"]}`, auth: true,
+			status: http.StatusOK,
+			want:   liveConfig{Interval: viewer.DefaultInterval, MaxPoints: viewer.DefaultMaxPoints, Theme: string(viewer.DefaultTheme), Viewers: []string{"goroutine", "heap"}},
+			served: map[string]int{"heap": http.StatusOK, "goroutine": http.StatusOK},
+		},
+		{name: "invalid JSON", method: http.MethodPut, body: `{"interval":`, auth: true, status: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPut, body: `{"gogc": 50}`, auth: true, status: http.StatusBadRequest},
+		{name: "negative interval", method: http.MethodPut, body: `{"interval": -1}`, auth: true, status: http.StatusBadRequest},
+		{name: "negative max points", method: http.MethodPut, body: `{"maxPoints": -1}`, auth: true, status: http.StatusBadRequest},
+		{name: "unknown theme", method: http.MethodPut, body: `{"theme": "dark"}`, auth: true, status: http.StatusBadRequest},
+		{name: "no viewer", method: http.MethodPut, body: `{"viewers": []}`, auth: true, status: http.StatusBadRequest},
+		{name: "unknown viewer", method: http.MethodPut, body: `{"viewers": ["heap", "pause"]}`, auth: true, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(
+				viewer.WithAdminToken("s3cret"), viewer.WithViewers(), viewer.WithTheme(viewer.DefaultTheme),
+				viewer.WithInterval(viewer.DefaultInterval), viewer.WithMaxPoints(viewer.DefaultMaxPoints),
+			)
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer(), viewer.NewHeapViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			prev := mgr.currentConfig()
+
+			r := httptest.NewRequest(tt.method, APIPrefix+"/config", strings.NewReader(tt.body))
+			r.Header.Set("X-Requested-With", "XMLHttpRequest")
+			if tt.auth {
+				r.Header.Set("Authorization", "Bearer s3cret")
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			want := tt.want
+			if rec.Code != http.StatusOK {
+				want = prev
+			} else {
+				var served liveConfig
+				if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(served, mgr.currentConfig()) {
+					t.Errorf("served %+v, want the current configuration", served)
+				}
+			}
+			want.Available = []string{"goroutine", "heap"}
+			if got := mgr.currentConfig(); !reflect.DeepEqual(got, want) {
+				t.Errorf("configuration = %+v, want %+v", got, want)
+			}
+
+			for name, status := range tt.served {
+				rec := httptest.NewRecorder()
+				mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/view/"+name, nil))
+				if rec.Code != status {
+					t.Errorf("%s status = %d, want %d", name, rec.Code, status)
+				}
+			}
+			var notes []string
+			for _, a := range mgr.annotations {
+				notes = append(notes, a.Text)
+			}
+			if !reflect.DeepEqual(notes, tt.notes) {
+				t.Errorf("annotations = %q, want %q", notes, tt.notes)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package statsview
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/internal/lockprof"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// locksSampleEvery bounds how often the mutex profile is sampled while the
+// locks page is open
+const locksSampleEvery = 5 * time.Second
+
+// locksSampler samples the mutex profile lazily, only while it's looked at
+type locksSampler struct {
+	mu      sync.Mutex
+	sampled time.Time
+	tracker *lockprof.Tracker
+}
+
+func (s *locksSampler) top(n int) ([]lockprof.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tracker == nil {
+		s.tracker = lockprof.NewTracker()
+	}
+	if time.Since(s.sampled) >= locksSampleEvery {
+		if err := s.tracker.Record(); err != nil {
+			return nil, err
+		}
+		s.sampled = time.Now()
+	}
+	return s.tracker.Top(n), nil
+}
+
+var locksTpl = template.Must(template.New("locks").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statsview - Contended locks</title>
+	<script src="{{ .Base }}/statics/jquery.min.js"></script>
+	<style>
+		body { font-family: sans-serif; margin: 20px 40px; }
+		table { border-collapse: collapse; }
+		th, td { padding: 4px 12px; text-align: right; }
+		th:first-child, td:first-child { text-align: left; }
+		tr:nth-child(even) { background: #f6f6f6; }
+		#disabled { color: #c23531; }
+	</style>
+</head>
+<body>
+	<h3>Contended locks by site</h3>
+	<p>The cumulative contention of the mutex profile, sampled every {{ .Every }} while this page is open.
+		The site is the function releasing the contended lock, the trend is the delay between two samples.</p>
+	<p id="disabled" style="display:none">The mutex profile is disabled, set a mutex fraction on the dashboard
+		or call runtime.SetMutexProfileFraction.</p>
+	<table>
+		<thead><tr><th>Site</th><th>Contentions</th><th>Delay</th><th>Trend</th></tr></thead>
+		<tbody id="sites"></tbody>
+	</table>
+<script type="text/javascript">
+"use strict";
+const base = {{ .Base }};
+function sparkline(trend) {
+	let w = 120, h = 24;
+	let max = Math.max(1e-9, ...trend);
+	let step = trend.length > 1 ? w / (trend.length - 1) : 0;
+	let points = trend.map((v, i) => (i * step).toFixed(1) + "," + (h - v / max * h).toFixed(1)).join(" ");
+	return '<svg width="' + w + '" height="' + h + '"><polyline fill="none" stroke="#2f4554" points="' + points + '"/></svg>';
+}
+function seconds(s) {
+	if (s >= 1) {
+		return s.toFixed(2) + " s";
+	}
+	if (s >= 1e-3) {
+		return (s * 1e3).toFixed(2) + " ms";
+	}
+	return (s * 1e6).toFixed(0) + " µs";
+}
+function locks_sync() {
+	$.getJSON(base + "/profile/mutex", function (r) {
+		$("#disabled").toggle(r.fraction === 0);
+	});
+	$.getJSON(base + "/locks/top?n={{ .Top }}", function (sites) {
+		let body = $("<tbody id='sites'>");
+		for (const s of sites) {
+			body.append($("<tr>")
+				.append($("<td>").text(s.site))
+				.append($("<td>").text(s.contentions))
+				.append($("<td>").text(seconds(s.delay)))
+				.append($("<td>").html(sparkline(s.trend))));
+		}
+		$("#sites").replaceWith(body);
+	});
+}
+$(function () {
+	locks_sync();
+	setInterval(locks_sync, {{ .Every.Milliseconds }});
+});
+</script>
+</body>
+</html>
+`))
+
+func locksPage(w http.ResponseWriter, r *http.Request) {
+	err := writeHTML(w, r, func(w io.Writer) error {
+		return locksTpl.Execute(w, struct {
+			Base  string
+			Every time.Duration
+			Top   int
+		}{
+			Base:  basePath(r),
+			Every: locksSampleEvery,
+			Top:   20,
+		})
+	})
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to render locks page", "err", err)
+	}
+}
+
+// locksTop returns the top `n` lock sites by cumulative delay with their trend
+func (vm *ViewManager) locksTop(w http.ResponseWriter, r *http.Request) {
+	n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+	if n <= 0 {
+		n = 20
+	}
+
+	sites, err := vm.locks.top(n)
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to sample mutex profile", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeData(w, r, sites)
+}
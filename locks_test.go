@@ -0,0 +1,53 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/internal/lockprof"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestLocks(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		path string
+		want string
+		max  int
+	}{
+		{path: "/debug/statsview/locks", want: "Contended locks by site"},
+		{path: "/debug/statsview/locks/top", max: 20},
+		{path: "/debug/statsview/locks/top?n=1", max: 1},
+		{path: "/debug/statsview/locks/top?n=-1", max: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if tt.want != "" {
+				if !strings.Contains(rec.Body.String(), tt.want) {
+					t.Errorf("page doesn't contain %s", tt.want)
+				}
+				return
+			}
+			var sites []lockprof.Site
+			if err := json.Unmarshal(rec.Body.Bytes(), &sites); err != nil {
+				t.Fatal(err)
+			}
+			if len(sites) > tt.max {
+				t.Errorf("got %d sites, want at most %d", len(sites), tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package statsview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/exporter"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// failingExporter fails every export and flush
+type failingExporter struct{}
+
+func (failingExporter) Export(context.Context, []exporter.Sample) error {
+	return errors.New("sink unreachable")
+}
+
+func (failingExporter) Flush(context.Context) error { return errors.New("sink closed") }
+
+func TestLogger(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithLogger(nil))
+	if viewer.Logger().Enabled(context.Background(), slog.LevelError) {
+		t.Error("the logs aren't discarded by default")
+	}
+
+	tests := []struct {
+		name string
+		run  func(vm *ViewManager)
+		want []string
+	}{
+		{
+			"export failure",
+			func(vm *ViewManager) { export(context.Background(), vm.exporters, nil) },
+			[]string{`level=ERROR msg="statsview: export failed" exporter=statsview.failingExporter err="sink unreachable"`},
+		},
+		{
+			"flush failure",
+			func(vm *ViewManager) { flushExporters(vm.exporters, nil) },
+			[]string{
+				`msg="statsview: export failed" exporter=statsview.failingExporter err="sink unreachable"`,
+				`msg="statsview: flush failed" exporter=statsview.failingExporter err="sink closed"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			viewer.SetConfiguration(viewer.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+			vm := &ViewManager{}
+			vm.AddExporter(failingExporter{}, 0)
+			tt.run(vm)
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("log %q doesn't contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
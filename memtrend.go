@@ -0,0 +1,97 @@
+package statsview
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/internal/memtrend"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// memTrendSampleEvery bounds how often the memory is sampled for the trend
+// detection, the dashboard polls the warnings while it's open
+const memTrendSampleEvery = 10 * time.Second
+
+var memTrendSeries = []string{"HeapInuse", "RSS"}
+
+// memTrendSampler samples the memory lazily, only while it's looked at
+type memTrendSampler struct {
+	mu       sync.Mutex
+	sampled  time.Time
+	detector memtrend.Detector
+}
+
+func (s *memTrendSampler) warnings() []memtrend.Warning {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.sampled) >= memTrendSampleEvery {
+		s.sampled = time.Now()
+		s.detector.Record(memtrend.Sample{Time: s.sampled, Values: readMemTrend()})
+	}
+	limit, source := memoryLimit()
+	return s.detector.Warnings(memTrendSeries, limit, source)
+}
+
+// readMemTrend reads the in-use heap and the resident memory, which falls
+// back to the memory mapped by the runtime where /proc isn't available
+func readMemTrend() map[string]float64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/heap/objects:bytes"},
+		{Name: "/memory/classes/heap/unused:bytes"},
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+
+	rss, ok := residentMemory()
+	if !ok {
+		rss = float64(samples[2].Value.Uint64() - samples[3].Value.Uint64())
+	}
+	return map[string]float64{
+		"HeapInuse": float64(samples[0].Value.Uint64() + samples[1].Value.Uint64()),
+		"RSS":       rss,
+	}
+}
+
+// residentMemory returns the resident set size from `/proc/self/statm`
+func residentMemory() (float64, bool) {
+	bs, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(bs))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * float64(os.Getpagesize()), true
+}
+
+// memoryLimit returns the lowest of GOMEMLIMIT and the cgroup memory limit
+// and which one it is, 0 when there is none
+func memoryLimit() (float64, string) {
+	limit, source := 0.0, ""
+	if l := readGCSettings().GOMEMLIMIT; l != math.MaxInt64 {
+		limit, source = float64(l), "GOMEMLIMIT"
+	}
+	if l, _ := viewer.ContainerLimits(); l > 0 && (limit == 0 || l < limit) {
+		limit, source = l, "cgroup"
+	}
+	return limit, source
+}
+
+// memoryTrend returns the memory series growing steadily with the projected
+// time to reach the memory limit
+func (vm *ViewManager) memoryTrend(w http.ResponseWriter, r *http.Request) {
+	writeData(w, r, vm.memTrend.warnings())
+}
@@ -0,0 +1,33 @@
+package statsview
+
+import (
+	"net/http"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// meta describes the dashboard for the tools discovering the charts, the
+// intervals are in milliseconds
+type meta struct {
+	Interval        int           `json:"interval"`
+	CurrentInterval int           `json:"currentInterval"`
+	History         int64         `json:"history"`
+	Viewers         []viewer.Meta `json:"viewers"`
+}
+
+// serveMeta describes every registered viewer: its name, title, series with
+// their dimension and unit, and description, e.g. for a Grafana bridge
+// building its panels
+func (vm *ViewManager) serveMeta(w http.ResponseWriter, r *http.Request) {
+	views := vm.views()
+	m := meta{
+		Interval:        viewer.Interval(),
+		CurrentInterval: vm.Smgr.CurrentInterval(),
+		History:         vm.historyWindow().Milliseconds(),
+		Viewers:         make([]viewer.Meta, 0, len(views)),
+	}
+	for _, v := range views {
+		m.Viewers = append(m.Viewers, viewer.MetaOf(v))
+	}
+	writeData(w, r, m)
+}
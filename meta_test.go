@@ -0,0 +1,57 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestServeMeta(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer(), viewer.NewMutexViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	rec := httptest.NewRecorder()
+	mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/meta", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var m meta
+	if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Interval != viewer.Interval() || m.CurrentInterval != mgr.Smgr.CurrentInterval() {
+		t.Errorf("intervals = %d, %d", m.Interval, m.CurrentInterval)
+	}
+
+	tests := []struct {
+		name     string
+		series   []string
+		priority viewer.Priority
+	}{
+		{viewer.VGoroutine, []string{"Goroutines"}, viewer.PriorityNormal},
+		{viewer.VMutex, []string{"Events", "Delay"}, viewer.PriorityLow},
+	}
+	if len(m.Viewers) != len(tests) {
+		t.Fatalf("%d viewers, want %d", len(m.Viewers), len(tests))
+	}
+	for i, tt := range tests {
+		v := m.Viewers[i]
+		if v.Name != tt.name || v.Priority != tt.priority || !v.Collects || v.Description == "" {
+			t.Errorf("viewer %d = %+v, want %s", i, v, tt.name)
+		}
+		if len(v.Series) != len(tt.series) {
+			t.Fatalf("%s series = %+v, want %v", tt.name, v.Series, tt.series)
+		}
+		for j, s := range tt.series {
+			if v.Series[j].Name != s {
+				t.Errorf("%s series %d = %s, want %s", tt.name, j, v.Series[j].Name, s)
+			}
+		}
+	}
+}
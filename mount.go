@@ -0,0 +1,67 @@
+package statsview
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mountPrefix returns the prefix of the router group statsview is mounted
+// into, what precedes BasePath or PprofPath in path, false when neither is
+// found
+func mountPrefix(path string) (string, bool) {
+	for _, base := range []string{BasePath, PprofPath} {
+		for i := 0; i < len(path); {
+			j := strings.Index(path[i:], base)
+			if j < 0 {
+				break
+			}
+			end := i + j + len(base)
+			if end == len(path) || path[end] == '/' {
+				return path[:i+j], true
+			}
+			i = end
+		}
+	}
+	return "", false
+}
+
+// mountKey is the context key of the prefix stripped from a request
+type mountKey struct{}
+
+// basePath returns BasePath as the browser sees it, after the prefix of the
+// router group the request came through
+func basePath(r *http.Request) string {
+	prefix, _ := r.Context().Value(mountKey{}).(string)
+	return prefix + BasePath
+}
+
+// pprofPath returns PprofPath as the browser sees it, see basePath
+func pprofPath(r *http.Request) string {
+	prefix, _ := r.Context().Value(mountKey{}).(string)
+	return prefix + PprofPath
+}
+
+// stripMount strips the prefix of the router group from the requests, so
+// the routes registered under BasePath and PprofPath match wherever the
+// adapters mount h. The prefix is kept in the context, see basePath
+func stripMount(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix, ok := mountPrefix(r.URL.Path)
+		if !ok || prefix == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		r2 := r.WithContext(context.WithValue(r.Context(), mountKey{}, prefix))
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = strings.TrimPrefix(r.URL.Path, prefix)
+		if strings.HasPrefix(r.URL.RawPath, prefix) {
+			r2.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, prefix)
+		} else {
+			r2.URL.RawPath = ""
+		}
+		h.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,135 @@
+package statsview
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestMountPrefix(t *testing.T) {
+	tests := []struct {
+		path   string
+		prefix string
+		ok     bool
+	}{
+		{"/debug/statsview", "", true},
+		{"/debug/statsview/view/all", "", true},
+		{"/admin/debug/statsview", "/admin", true},
+		{"/admin/ops/debug/statsview/healthz", "/admin/ops", true},
+		{"/admin/debug/pprof/heap", "/admin", true},
+		{"/debug/statsviewer/debug/statsview/status", "/debug/statsviewer", true},
+		{"/debug/statsviewer", "", false},
+		{"/admin", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			prefix, ok := mountPrefix(tt.path)
+			if prefix != tt.prefix || ok != tt.ok {
+				t.Errorf("got %q, %v, want %q, %v", prefix, ok, tt.prefix, tt.ok)
+			}
+		})
+	}
+}
+
+// failingViewer fails to initialize
+type failingViewer struct {
+	viewer.Viewer
+}
+
+func (vr *failingViewer) Init(context.Context) error {
+	return errors.New("init failed")
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		viewers Viewers
+		path    string
+		status  int
+	}{
+		{"root", Viewers{viewer.NewGoroutinesViewer()}, "/debug/statsview/status", http.StatusOK},
+		{"group", Viewers{viewer.NewGoroutinesViewer()}, "/admin/debug/statsview/status", http.StatusOK},
+		{"unknown", Viewers{viewer.NewGoroutinesViewer()}, "/admin/healthz", http.StatusNotFound},
+		{"init failure", Viewers{&failingViewer{viewer.NewGoroutinesViewer()}}, "/debug/statsview/healthz", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := New(tt.viewers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			rec := httptest.NewRecorder()
+			mgr.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandlerStartsOnce(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	exp := &recordingExporter{}
+	mgr.AddExporter(exp, 0)
+
+	mgr.Handler()
+	mgr.Handler()
+	if mgr.running != 1 {
+		t.Errorf("running = %d, want 1", mgr.running)
+	}
+}
+
+// assetSrc matches the scripts loaded by a page
+var assetSrc = regexp.MustCompile(`src="([^"]+)"`)
+
+func TestPagesMounted(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	h := mgr.Handler()
+
+	pages := []string{"", "/goroutines", "/stuck", "/objects", "/locks", "/heapdiff", "/profiles", "/flamegraph", "/embed/goroutine"}
+	for _, prefix := range []string{"", "/admin", "/admin/ops"} {
+		for _, page := range pages {
+			t.Run(prefix+page, func(t *testing.T) {
+				base := prefix + BasePath
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, base+page, nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+				}
+				body := rec.Body.String()
+				// the scripts build the URLs of their requests on base
+				if !strings.Contains(body, `base = "`+base+`";`) {
+					t.Errorf("the page doesn't request under %s", base)
+				}
+				if strings.Contains(body, `"`+BasePath) && prefix != "" {
+					t.Errorf("the page links %s without the prefix", BasePath)
+				}
+				srcs := assetSrc.FindAllStringSubmatch(body, -1)
+				if len(srcs) == 0 {
+					t.Fatal("the page loads no script")
+				}
+				for _, src := range srcs {
+					if !strings.HasPrefix(src[1], base+"/statics/") {
+						t.Errorf("script %s isn't served under %s", src[1], base)
+					}
+				}
+			})
+		}
+	}
+}
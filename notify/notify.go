@@ -0,0 +1,244 @@
+// Package notify sends the alerts of statsview, e.g. the anomalies, to chat
+// services: Slack, Discord and Telegram. The messages are rendered from a
+// template and throttled by alert, resolved alerts are notified too.
+//
+//	n, err := notify.New(notify.Config{Type: notify.TypeSlack, URL: "https://hooks.slack.com/services/..."})
+//	if err != nil { ... }
+//	mgr.AddNotifier(n)
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Event is an alert firing or resolved
+type Event struct {
+	// Key identifies the alert, e.g. `heap.Alloc`, the throttling is by key
+	Key      string
+	Title    string
+	Text     string
+	Resolved bool
+	Time     time.Time
+}
+
+// Notifier sends the events
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// Types of the notifiers of New
+const (
+	TypeSlack    = "slack"
+	TypeDiscord  = "discord"
+	TypeTelegram = "telegram"
+)
+
+// DefaultTemplate is the text/template of the messages, executed with the Event
+const DefaultTemplate = `{{ if .Resolved }}✅ Resolved{{ else }}🚨 Firing{{ end }}: {{ .Title }}
+{{ .Text }}`
+
+// telegramAPI is the Bot API of Telegram
+const telegramAPI = "https://api.telegram.org"
+
+// Config is a notifier read from a config file
+//
+//	notifiers:
+//	  - type: slack
+//	    url: https://hooks.slack.com/services/...
+//	    throttle: 10m
+//	  - type: telegram
+//	    token: "123456:ABC"
+//	    chat_id: "-100123"
+type Config struct {
+	Type string `yaml:"type" toml:"type"`
+	// URL is the incoming webhook of Slack and Discord, the Bot API of
+	// Telegram when it's not the public one
+	URL    string `yaml:"url" toml:"url"`
+	Token  string `yaml:"token" toml:"token"`
+	ChatID string `yaml:"chat_id" toml:"chat_id"`
+	// Template is the text/template of the messages, DefaultTemplate when empty
+	Template string `yaml:"template" toml:"template"`
+	// Throttle is the least duration between two notifications of an
+	// alert, e.g. 10m, none when empty
+	Throttle string `yaml:"throttle" toml:"throttle"`
+}
+
+// New returns the notifier configured by c, the type is validated first
+func New(c Config) (Notifier, error) {
+	var w *Webhook
+	switch strings.ToLower(c.Type) {
+	case TypeSlack:
+		if c.URL == "" {
+			return nil, fmt.Errorf("notify: the slack notifier requires the url of a webhook")
+		}
+		w = Slack(c.URL)
+	case TypeDiscord:
+		if c.URL == "" {
+			return nil, fmt.Errorf("notify: the discord notifier requires the url of a webhook")
+		}
+		w = Discord(c.URL)
+	case TypeTelegram:
+		if c.Token == "" || c.ChatID == "" {
+			return nil, fmt.Errorf("notify: the telegram notifier requires a token and a chat_id")
+		}
+		w = Telegram(c.Token, c.ChatID)
+		if c.URL != "" {
+			w.API = strings.TrimSuffix(c.URL, "/")
+		}
+	default:
+		return nil, fmt.Errorf("notify: unknown notifier type %q", c.Type)
+	}
+	if c.Template != "" {
+		if err := w.SetTemplate(c.Template); err != nil {
+			return nil, fmt.Errorf("notify: invalid template of the %s notifier: %w", w.name, err)
+		}
+	}
+
+	var n Notifier = w
+	if c.Throttle != "" {
+		d, err := time.ParseDuration(c.Throttle)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("notify: invalid throttle %q of the %s notifier", c.Throttle, c.Type)
+		}
+		n = Throttle(n, d)
+	}
+	return n, nil
+}
+
+// Webhook posts the messages rendered from the events as the JSON payload
+// of a chat service
+type Webhook struct {
+	// API is the base URL of the Telegram Bot API
+	API    string
+	Client *http.Client
+
+	name     string
+	url      func(w *Webhook) string
+	payload  func(text string) interface{}
+	template *template.Template
+}
+
+func newWebhook(name string, url func(w *Webhook) string, payload func(text string) interface{}) *Webhook {
+	return &Webhook{
+		name:     name,
+		url:      url,
+		payload:  payload,
+		template: template.Must(template.New(name).Parse(DefaultTemplate)),
+		Client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Slack returns the notifier posting to the incoming webhook of Slack at url
+func Slack(url string) *Webhook {
+	return newWebhook(TypeSlack, func(*Webhook) string { return url },
+		func(text string) interface{} { return map[string]string{"text": text} })
+}
+
+// Discord returns the notifier posting to the webhook of Discord at url
+func Discord(url string) *Webhook {
+	return newWebhook(TypeDiscord, func(*Webhook) string { return url },
+		func(text string) interface{} { return map[string]string{"content": text} })
+}
+
+// Telegram returns the notifier sending the messages to the chat by the bot
+// of token
+func Telegram(token, chatID string) *Webhook {
+	w := newWebhook(TypeTelegram, func(w *Webhook) string { return w.API + "/bot" + token + "/sendMessage" },
+		func(text string) interface{} { return map[string]string{"chat_id": chatID, "text": text} })
+	w.API = telegramAPI
+	return w
+}
+
+// SetTemplate sets the text/template of the messages, executed with the Event
+func (w *Webhook) SetTemplate(text string) error {
+	tpl, err := template.New(w.name).Parse(text)
+	if err != nil {
+		return err
+	}
+	w.template = tpl
+	return nil
+}
+
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	var text bytes.Buffer
+	if err := w.template.Execute(&text, e); err != nil {
+		return fmt.Errorf("notify: failed to render the %s message: %w", w.name, err)
+	}
+	body, err := json.Marshal(w.payload(text.String()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url(w), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("notify: %s answered %s: %s", w.name, resp.Status, bytes.TrimSpace(msg))
+}
+
+// sent is the last firing notified of an alert, firing until it's resolved
+type sent struct {
+	at     time.Time
+	firing bool
+}
+
+// throttled drops the events of an alert notified less than period ago
+type throttled struct {
+	n      Notifier
+	period time.Duration
+
+	mu   sync.Mutex
+	sent map[string]sent
+}
+
+// Throttle returns a Notifier sending an alert firing at most once every
+// period. A resolved alert is only notified when its firing was. The events
+// are recorded once sent, a failed send doesn't throttle the next one
+func Throttle(n Notifier, period time.Duration) Notifier {
+	return &throttled{n: n, period: period, sent: make(map[string]sent)}
+}
+
+func (t *throttled) Notify(ctx context.Context, e Event) error {
+	t.mu.Lock()
+	s, ok := t.sent[e.Key]
+	t.mu.Unlock()
+	switch {
+	case e.Resolved && !s.firing:
+		return nil
+	case !e.Resolved && ok && e.Time.Sub(s.at) < t.period:
+		return nil
+	}
+
+	if err := t.n.Notify(ctx, e); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e.Resolved {
+		s = t.sent[e.Key]
+		s.firing = false
+	} else {
+		s = sent{at: e.Time, firing: true}
+	}
+	t.sent[e.Key] = s
+	return nil
+}
@@ -0,0 +1,262 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// chat is a chat service recording the payloads posted to it
+type chat struct {
+	mu       sync.Mutex
+	paths    []string
+	payloads []map[string]string
+	status   int
+}
+
+func (c *chat) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var p map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil || r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths = append(c.paths, r.URL.Path)
+	c.payloads = append(c.payloads, p)
+	if c.status != 0 {
+		http.Error(w, "rate limited", c.status)
+	}
+}
+
+var firing = Event{Key: "heap.Alloc", Title: "anomaly of heap.Alloc", Text: "1e+09 is 12.0 standard deviations from the average"}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		c         Config
+		throttled bool
+		wantErr   string
+	}{
+		{name: "slack", c: Config{Type: TypeSlack, URL: "https://hooks.slack.test/x"}},
+		{name: "discord", c: Config{Type: "Discord", URL: "https://discord.test/api/webhooks/x"}},
+		{name: "telegram", c: Config{Type: TypeTelegram, Token: "123:ABC", ChatID: "-100"}},
+		{name: "throttled", c: Config{Type: TypeSlack, URL: "https://hooks.slack.test/x", Throttle: "10m"}, throttled: true},
+		{name: "slack without url", c: Config{Type: TypeSlack}, wantErr: "requires the url"},
+		{name: "discord without url", c: Config{Type: TypeDiscord}, wantErr: "requires the url"},
+		{name: "telegram without chat", c: Config{Type: TypeTelegram, Token: "123:ABC"}, wantErr: "requires a token and a chat_id"},
+		{name: "unknown type", c: Config{Type: "pager"}, wantErr: `unknown notifier type "pager"`},
+		{name: "invalid template", c: Config{Type: TypeSlack, URL: "https://hooks.slack.test/x", Template: "{{ .Title"}, wantErr: "invalid template of the slack notifier"},
+		{name: "unknown type before the template", c: Config{Type: "pager", Template: "{{ .Title"}, wantErr: `unknown notifier type "pager"`},
+		{name: "missing url before the template", c: Config{Type: TypeDiscord, Template: "{{ .Title"}, wantErr: "requires the url"},
+		{name: "invalid throttle", c: Config{Type: TypeSlack, URL: "https://hooks.slack.test/x", Throttle: "often"}, wantErr: "invalid throttle"},
+		{name: "negative throttle", c: Config{Type: TypeSlack, URL: "https://hooks.slack.test/x", Throttle: "-1m"}, wantErr: "invalid throttle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New(tt.c)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := n.(*throttled); ok != tt.throttled {
+				t.Errorf("New() = %T, throttled %v", n, tt.throttled)
+			}
+		})
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	c := new(chat)
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		config   Config
+		event    Event
+		wantPath string
+		want     map[string]string
+	}{
+		{
+			name:     "slack",
+			config:   Config{Type: TypeSlack, URL: srv.URL + "/services/x"},
+			event:    firing,
+			wantPath: "/services/x",
+			want:     map[string]string{"text": "🚨 Firing: anomaly of heap.Alloc\n1e+09 is 12.0 standard deviations from the average"},
+		},
+		{
+			name:     "discord resolved",
+			config:   Config{Type: TypeDiscord, URL: srv.URL + "/api/webhooks/x"},
+			event:    Event{Key: "heap.Alloc", Title: "anomaly of heap.Alloc", Text: "back to normal", Resolved: true},
+			wantPath: "/api/webhooks/x",
+			want:     map[string]string{"content": "✅ Resolved: anomaly of heap.Alloc\nback to normal"},
+		},
+		{
+			name:     "telegram",
+			config:   Config{Type: TypeTelegram, URL: srv.URL + "/", Token: "123:ABC", ChatID: "-100"},
+			event:    firing,
+			wantPath: "/bot123:ABC/sendMessage",
+			want:     map[string]string{"chat_id": "-100", "text": "🚨 Firing: anomaly of heap.Alloc\n1e+09 is 12.0 standard deviations from the average"},
+		},
+		{
+			name:     "template",
+			config:   Config{Type: TypeSlack, URL: srv.URL + "/services/x", Template: "{{ .Key }}: {{ .Text }}"},
+			event:    firing,
+			wantPath: "/services/x",
+			want:     map[string]string{"text": "heap.Alloc: 1e+09 is 12.0 standard deviations from the average"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.paths, c.payloads = nil, nil
+			n, err := New(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Notify(context.Background(), tt.event); err != nil {
+				t.Fatal(err)
+			}
+			if len(c.payloads) != 1 || c.paths[0] != tt.wantPath || !reflect.DeepEqual(c.payloads[0], tt.want) {
+				t.Errorf("posted %v to %v, want %v to %s", c.payloads, c.paths, tt.want, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestWebhookErrors(t *testing.T) {
+	c := &chat{status: http.StatusTooManyRequests}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		w       func() *Webhook
+		wantErr string
+	}{
+		{name: "error status", w: func() *Webhook { return Slack(srv.URL) }, wantErr: "slack answered 429 Too Many Requests: rate limited"},
+		{name: "unreachable", w: func() *Webhook { return Discord("http://127.0.0.1:0") }, wantErr: "127.0.0.1:0"},
+		{
+			name: "template failing",
+			w: func() *Webhook {
+				w := Slack(srv.URL)
+				if err := w.SetTemplate("{{ .Missing }}"); err != nil {
+					t.Fatal(err)
+				}
+				return w
+			},
+			wantErr: "failed to render the slack message",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.w().Notify(context.Background(), firing)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Notify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := Slack(srv.URL).SetTemplate("{{ if }}"); err == nil {
+		t.Error("SetTemplate() of an invalid template succeeded")
+	}
+}
+
+// recorder is a Notifier recording the events, failing with err or the
+// events failing
+type recorder struct {
+	events  []Event
+	err     error
+	failing map[Event]bool
+}
+
+func (r *recorder) Notify(_ context.Context, e Event) error {
+	if r.failing[e] {
+		return errors.New("chat down")
+	}
+	r.events = append(r.events, e)
+	return r.err
+}
+
+func TestThrottle(t *testing.T) {
+	epoch := time.Unix(1700000000, 0)
+	at := func(m int) time.Time { return epoch.Add(time.Duration(m) * time.Minute) }
+	fire := func(key string, m int) Event { return Event{Key: key, Time: at(m)} }
+	resolve := func(key string, m int) Event { return Event{Key: key, Time: at(m), Resolved: true} }
+
+	tests := []struct {
+		name    string
+		events  []Event
+		failing []Event
+		want    []Event
+	}{
+		{"first firing", []Event{fire("a", 0)}, nil, []Event{fire("a", 0)}},
+		{"firing again within the period", []Event{fire("a", 0), fire("a", 5)}, nil, []Event{fire("a", 0)}},
+		{"firing again after the period", []Event{fire("a", 0), fire("a", 10)}, nil, []Event{fire("a", 0), fire("a", 10)}},
+		{"other keys", []Event{fire("a", 0), fire("b", 1)}, nil, []Event{fire("a", 0), fire("b", 1)}},
+		{"resolved", []Event{fire("a", 0), resolve("a", 1)}, nil, []Event{fire("a", 0), resolve("a", 1)}},
+		{"resolved once", []Event{fire("a", 0), resolve("a", 1), resolve("a", 2)}, nil, []Event{fire("a", 0), resolve("a", 1)}},
+		{"resolved never fired", []Event{resolve("a", 0)}, nil, nil},
+		{
+			"resolved after a dropped firing",
+			[]Event{fire("a", 0), resolve("a", 1), fire("a", 2), resolve("a", 3)},
+			nil,
+			[]Event{fire("a", 0), resolve("a", 1)},
+		},
+		{
+			"firing retried after a failed send",
+			[]Event{fire("a", 0), fire("a", 1)},
+			[]Event{fire("a", 0)},
+			[]Event{fire("a", 1)},
+		},
+		{
+			"no resolved of an undelivered firing",
+			[]Event{fire("a", 0), resolve("a", 1)},
+			[]Event{fire("a", 0)},
+			nil,
+		},
+		{
+			"resolved retried after a failed send",
+			[]Event{fire("a", 0), resolve("a", 1), resolve("a", 2)},
+			[]Event{resolve("a", 1)},
+			[]Event{fire("a", 0), resolve("a", 2)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{failing: make(map[Event]bool)}
+			for _, e := range tt.failing {
+				r.failing[e] = true
+			}
+			n := Throttle(r, 10*time.Minute)
+			for _, e := range tt.events {
+				if err := n.Notify(context.Background(), e); (err != nil) != r.failing[e] {
+					t.Fatalf("Notify(%+v) = %v", e, err)
+				}
+			}
+			if !reflect.DeepEqual(r.events, tt.want) {
+				t.Errorf("notified %+v, want %+v", r.events, tt.want)
+			}
+		})
+	}
+}
+
+func TestThrottleError(t *testing.T) {
+	errDown := errors.New("chat down")
+	n := Throttle(&recorder{err: errDown}, time.Minute)
+	if err := n.Notify(context.Background(), firing); !errors.Is(err, errDown) {
+		t.Errorf("Notify() error = %v, want %v", err, errDown)
+	}
+}
@@ -0,0 +1,135 @@
+package statsview
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/internal/heapprof"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// objectsSampleEvery bounds how often the heap profile is sampled while the
+// objects page is open, a heap profile is more expensive than MemStats
+const objectsSampleEvery = 10 * time.Second
+
+// objectsSampler samples the heap profile lazily, only while it's looked at
+type objectsSampler struct {
+	mu      sync.Mutex
+	sampled time.Time
+	tracker *heapprof.Tracker
+}
+
+func (s *objectsSampler) top(n int) ([]heapprof.Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tracker == nil {
+		s.tracker = heapprof.NewTracker()
+	}
+	if time.Since(s.sampled) >= objectsSampleEvery {
+		if err := s.tracker.Record(); err != nil {
+			return nil, err
+		}
+		s.sampled = time.Now()
+	}
+	return s.tracker.Top(n), nil
+}
+
+var objectsTpl = template.Must(template.New("objects").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statsview - Live objects</title>
+	<script src="{{ .Base }}/statics/jquery.min.js"></script>
+	<style>
+		body { font-family: sans-serif; margin: 20px 40px; }
+		table { border-collapse: collapse; }
+		th, td { padding: 4px 12px; text-align: right; }
+		th:first-child, td:first-child { text-align: left; }
+		tr:nth-child(even) { background: #f6f6f6; }
+	</style>
+</head>
+<body>
+	<h3>Live objects by allocation site</h3>
+	<p>In-use objects of the heap profile, sampled every {{ .Every }} while this page is open.
+		The profile samples one allocation every 512 KiB by default (runtime.MemProfileRate).</p>
+	<table>
+		<thead><tr><th>Site</th><th>Objects</th><th>Bytes</th><th>Trend</th></tr></thead>
+		<tbody id="sites"></tbody>
+	</table>
+<script type="text/javascript">
+"use strict";
+const base = {{ .Base }};
+function sparkline(trend) {
+	let w = 120, h = 24;
+	let max = Math.max(1, ...trend);
+	let step = trend.length > 1 ? w / (trend.length - 1) : 0;
+	let points = trend.map((v, i) => (i * step).toFixed(1) + "," + (h - v / max * h).toFixed(1)).join(" ");
+	return '<svg width="' + w + '" height="' + h + '"><polyline fill="none" stroke="#2f4554" points="' + points + '"/></svg>';
+}
+function bytes(n) {
+	for (const u of ["B", "KiB", "MiB", "GiB"]) {
+		if (n < 1024 || u === "GiB") {
+			return (u === "B" ? n : n.toFixed(2)) + " " + u;
+		}
+		n /= 1024;
+	}
+}
+function objects_sync() {
+	$.getJSON(base + "/objects/top?n={{ .Top }}", function (sites) {
+		let body = $("<tbody id='sites'>");
+		for (const s of sites) {
+			body.append($("<tr>")
+				.append($("<td>").text(s.site))
+				.append($("<td>").text(s.objects))
+				.append($("<td>").text(bytes(s.bytes)))
+				.append($("<td>").html(sparkline(s.trend))));
+		}
+		$("#sites").replaceWith(body);
+	});
+}
+$(function () {
+	objects_sync();
+	setInterval(objects_sync, {{ .Every.Milliseconds }});
+});
+</script>
+</body>
+</html>
+`))
+
+func objectsPage(w http.ResponseWriter, r *http.Request) {
+	err := writeHTML(w, r, func(w io.Writer) error {
+		return objectsTpl.Execute(w, struct {
+			Base  string
+			Every time.Duration
+			Top   int
+		}{
+			Base:  basePath(r),
+			Every: objectsSampleEvery,
+			Top:   20,
+		})
+	})
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to render objects page", "err", err)
+	}
+}
+
+// objectsTop returns the top `n` allocation sites by in-use bytes with their trend
+func (vm *ViewManager) objectsTop(w http.ResponseWriter, r *http.Request) {
+	n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+	if n <= 0 {
+		n = 20
+	}
+
+	sites, err := vm.objects.top(n)
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to sample heap profile", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeData(w, r, sites)
+}
@@ -0,0 +1,69 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/internal/heapprof"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestObjectsTop(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		name  string
+		query string
+		max   int
+	}{
+		{"default", "", 20},
+		{"limited", "?n=1", 1},
+		{"invalid", "?n=x", 20},
+		{"negative", "?n=-3", 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/objects/top"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			var sites []heapprof.Site
+			if err := json.Unmarshal(rec.Body.Bytes(), &sites); err != nil {
+				t.Fatal(err)
+			}
+			if len(sites) > tt.max {
+				t.Errorf("got %d sites, want at most %d", len(sites), tt.max)
+			}
+		})
+	}
+}
+
+func TestObjectsSamplerEvery(t *testing.T) {
+	var s objectsSampler
+	if _, err := s.top(1); err != nil {
+		t.Fatal(err)
+	}
+	sampled := s.sampled
+	if _, err := s.top(1); err != nil {
+		t.Fatal(err)
+	}
+	if !s.sampled.Equal(sampled) {
+		t.Errorf("sampled again after %v, want every %v", s.sampled.Sub(sampled), objectsSampleEvery)
+	}
+
+	s.sampled = time.Now().Add(-objectsSampleEvery)
+	if _, err := s.top(1); err != nil {
+		t.Fatal(err)
+	}
+	if !s.sampled.After(sampled) {
+		t.Errorf("not sampled again after %v", objectsSampleEvery)
+	}
+}
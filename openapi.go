@@ -0,0 +1,314 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// openAPIVersion is the version of the OpenAPI specification of the document
+const openAPIVersion = "3.0.3"
+
+// object is a JSON object of the OpenAPI document
+type object = map[string]interface{}
+
+// schemas generates the schemas of the responses from their Go types, the
+// named structs are components referenced by name
+type schemas struct {
+	components object
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: object{}, names: map[reflect.Type]string{}}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawType      = reflect.TypeOf(json.RawMessage{})
+)
+
+func (s *schemas) of(t reflect.Type) object {
+	switch t {
+	case timeType:
+		return object{"type": "string", "format": "date-time"}
+	case durationType:
+		return object{"type": "integer", "format": "int64", "description": "nanoseconds"}
+	case rawType:
+		return object{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.of(t.Elem())
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return object{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return object{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer", "format": "int64", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number", "format": "double"}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return object{"type": "string", "format": "byte"}
+		}
+		return object{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return object{"$ref": "#/components/schemas/" + s.component(t)}
+	}
+	return object{}
+}
+
+// component registers the schema of the named struct t and returns its
+// name, the exported form of the type name qualified with its package when
+// another package has a type of the same name
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := exported(t.Name())
+	if _, taken := s.components[name]; taken {
+		pkg := t.PkgPath()
+		name = exported(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+	}
+	s.names[t] = name
+	// registered before the properties for the recursive types
+	s.components[name] = object{}
+	s.components[name] = s.object(t)
+	return name
+}
+
+// object is the schema of the fields of t encoded by encoding/json, the
+// fields without omitempty are required
+func (s *schemas) object(t reflect.Type) object {
+	props := object{}
+	required := []string{}
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				add(f.Type)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			props[name] = s.of(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	add(t)
+
+	o := object{"type": "object", "properties": props}
+	if len(required) > 0 {
+		o["required"] = required
+	}
+	return o
+}
+
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	return string(unicode.ToUpper(rune(name[0]))) + name[1:]
+}
+
+// paramSchema is the schema of an apiParam type
+func paramSchema(p apiParam) object {
+	var o object
+	switch p.Type {
+	case "integer":
+		o = object{"type": "integer"}
+	case "duration":
+		o = object{"type": "string", "example": "5m"}
+	default:
+		o = object{"type": "string"}
+	}
+	if p.Repeated {
+		return object{"type": "array", "items": o}
+	}
+	return o
+}
+
+// operation describes a method of the route
+func (vm *ViewManager) operation(s *schemas, r apiRoute, method string) object {
+	op := object{
+		"summary":     r.Description,
+		"operationId": operationID(method, r.Path),
+	}
+
+	var params []interface{}
+	if strings.Contains(r.Path, viewerParam) {
+		views := vm.views()
+		names := make([]string, 0, len(views))
+		for _, v := range views {
+			names = append(names, v.Name())
+		}
+		params = append(params, object{
+			"name": "viewer", "in": "path", "required": true,
+			"schema": object{"type": "string", "enum": names},
+		})
+	}
+	form := false
+	for _, m := range r.Methods {
+		form = form || m == http.MethodPost
+	}
+	// the parameters of a route accepting POST are its form
+	takesParams := len(r.Params) > 0 && form == (method == http.MethodPost)
+	switch {
+	case takesParams && form:
+		props := object{}
+		for _, p := range r.Params {
+			schema := paramSchema(p)
+			schema["description"] = p.Description
+			props[p.Name] = schema
+		}
+		op["requestBody"] = object{
+			"content": object{
+				"application/x-www-form-urlencoded": object{
+					"schema": object{"type": "object", "properties": props},
+				},
+			},
+		}
+	case takesParams:
+		for _, p := range r.Params {
+			params = append(params, object{
+				"name": p.Name, "in": "query", "required": p.Required,
+				"description": p.Description, "schema": paramSchema(p),
+			})
+		}
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	body := method == http.MethodPut && r.request != nil
+	if body {
+		op["requestBody"] = object{
+			"required": true,
+			"content": object{
+				"application/json": object{"schema": s.of(reflect.TypeOf(r.request))},
+			},
+		}
+	}
+
+	responses := object{}
+	switch {
+	case r.download != "":
+		responses["200"] = object{
+			"description": "OK",
+			"content": object{
+				r.download: object{"schema": object{"type": "string", "format": "binary"}},
+			},
+		}
+	case r.response == nil:
+		responses["204"] = object{"description": "Done"}
+	default:
+		responses["200"] = object{
+			"description": "OK",
+			"content": object{
+				"application/json": object{"schema": s.of(reflect.TypeOf(r.response))},
+			},
+		}
+	}
+	if takesParams || body {
+		responses["400"] = object{"description": "Invalid parameter"}
+	}
+	switch {
+	case r.Admin && method != http.MethodGet:
+		op["security"] = []interface{}{object{"adminToken": []string{}}}
+		responses["401"] = object{"description": "Invalid admin token"}
+		responses["403"] = object{"description": "No admin token configured"}
+	case r.AdminRead:
+		op["security"] = []interface{}{object{"adminToken": []string{}}}
+		responses["401"] = object{"description": "Invalid admin token"}
+	}
+	op["responses"] = responses
+	return op
+}
+
+// operationID names the operation after its method and path, e.g.
+// getGoroutinesLeaks
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '.' || r == '{' || r == '}'
+	}) {
+		b.WriteString(exported(part))
+	}
+	return b.String()
+}
+
+// openAPI returns the OpenAPI document of the API
+func (vm *ViewManager) openAPI() object {
+	s := newSchemas()
+	paths := object{}
+	for _, r := range vm.apiRoutes() {
+		item := object{}
+		for _, m := range r.Methods {
+			item[strings.ToLower(m)] = vm.operation(s, r, m)
+		}
+		paths[r.Path] = item
+	}
+	doc := object{
+		"openapi": openAPIVersion,
+		"info": object{
+			"title":       "Statsview",
+			"version":     "v1",
+			"description": "The data of the statsview dashboard. Within v1 the routes, their parameters and the fields of their responses stay, fields may only be added.",
+		},
+		"servers": []interface{}{object{"url": "http://" + viewer.LinkAddr() + APIPrefix}},
+		"paths":   paths,
+		"components": object{
+			"schemas": s.components,
+			"securitySchemes": object{
+				"adminToken": object{"type": "http", "scheme": "bearer"},
+				"readToken":  object{"type": "http", "scheme": "bearer"},
+				"readBasic":  object{"type": "http", "scheme": "basic"},
+			},
+		},
+	}
+	if readRestricted() {
+		doc["security"] = []interface{}{object{"readToken": []string{}}, object{"readBasic": []string{}}}
+	}
+	return doc
+}
+
+// serveOpenAPI serves the OpenAPI document of the API, e.g. to generate
+// clients
+func (vm *ViewManager) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	bs, err := json.MarshalIndent(vm.openAPI(), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bs)
+}
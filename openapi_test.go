@@ -0,0 +1,237 @@
+package statsview
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestSchemasOf(t *testing.T) {
+	type inner struct {
+		Name string `json:"name"`
+	}
+	type embedded struct {
+		ID int64 `json:"id"`
+	}
+	type outer struct {
+		embedded
+		Inner    inner           `json:"inner"`
+		Optional *inner          `json:"optional,omitempty"`
+		Skipped  string          `json:"-"`
+		Raw      json.RawMessage `json:"raw,omitempty"`
+		private  int
+		Untagged bool
+	}
+
+	tests := []struct {
+		name       string
+		value      interface{}
+		want       object
+		components []string
+	}{
+		{"time", time.Time{}, object{"type": "string", "format": "date-time"}, nil},
+		{"duration", time.Second, object{"type": "integer", "format": "int64", "description": "nanoseconds"}, nil},
+		{"pointer", new(float64), object{"type": "number", "format": "double"}, nil},
+		{"unsigned", uint32(0), object{"type": "integer", "format": "int64", "minimum": 0}, nil},
+		{"bytes", []byte{}, object{"type": "string", "format": "byte"}, nil},
+		{"slice", []int{}, object{"type": "array", "items": object{"type": "integer", "format": "int32"}}, nil},
+		{"map", map[string]bool{}, object{"type": "object", "additionalProperties": object{"type": "boolean"}}, nil},
+		{
+			"anonymous struct",
+			struct {
+				A string `json:"a"`
+				B string `json:"b,omitempty"`
+			}{},
+			object{
+				"type":       "object",
+				"properties": object{"a": object{"type": "string"}, "b": object{"type": "string"}},
+				"required":   []string{"a"},
+			},
+			nil,
+		},
+		{"named struct", outer{}, object{"$ref": "#/components/schemas/Outer"}, []string{"Inner", "Outer"}},
+		{
+			"same name in two packages",
+			struct {
+				A meta        `json:"a"`
+				B viewer.Meta `json:"b"`
+			}{},
+			object{
+				"type": "object",
+				"properties": object{
+					"a": object{"$ref": "#/components/schemas/Meta"},
+					"b": object{"$ref": "#/components/schemas/ViewerMeta"},
+				},
+				"required": []string{"a", "b"},
+			},
+			[]string{"Meta", "SeriesMeta", "ViewerMeta"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSchemas()
+			if got := s.of(reflect.TypeOf(tt.value)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("of() = %v, want %v", got, tt.want)
+			}
+			var components []string
+			for name := range s.components {
+				components = append(components, name)
+			}
+			if !equalSet(components, tt.components) {
+				t.Errorf("components = %v, want %v", components, tt.components)
+			}
+		})
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		s := newSchemas()
+		s.of(reflect.TypeOf(outer{}))
+		o := s.components["Outer"].(object)
+		var props []string
+		for name := range o["properties"].(object) {
+			props = append(props, name)
+		}
+		if want := []string{"id", "inner", "optional", "raw", "Untagged"}; !equalSet(props, want) {
+			t.Errorf("properties = %v, want %v", props, want)
+		}
+		if want := []string{"id", "inner", "Untagged"}; !reflect.DeepEqual(o["required"], want) {
+			t.Errorf("required = %v, want %v", o["required"], want)
+		}
+	})
+}
+
+// equalSet tells whether a and b hold the same strings in any order
+func equalSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		if seen[s] == 0 {
+			return false
+		}
+		seen[s]--
+	}
+	return true
+}
+
+func TestOperationID(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{http.MethodGet, "/meta", "getMeta"},
+		{http.MethodGet, "/goroutines/leaks", "getGoroutinesLeaks"},
+		{http.MethodPost, "/control/gc", "postControlGc"},
+		{http.MethodGet, "/metrics/{viewer}", "getMetricsViewer"},
+		{http.MethodGet, "/openapi.json", "getOpenapiJson"},
+	}
+	for _, tt := range tests {
+		if got := operationID(tt.method, tt.path); got != tt.want {
+			t.Errorf("operationID(%s, %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer(), viewer.NewHeapViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	rec := httptest.NewRecorder()
+	mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, APIPrefix+"/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openAPIVersion {
+		t.Errorf("openapi = %s", doc.OpenAPI)
+	}
+	if len(doc.Paths) != len(mgr.apiRoutes()) {
+		t.Errorf("%d paths, want %d", len(doc.Paths), len(mgr.apiRoutes()))
+	}
+	// every reference resolves
+	for _, ref := range strings.Split(rec.Body.String(), `"$ref": "#/components/schemas/`)[1:] {
+		name := ref[:strings.IndexByte(ref, '"')]
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("unresolved reference to %s", name)
+		}
+	}
+
+	tests := []struct {
+		name, path, method string
+		want, absent       []string
+	}{
+		{
+			name: "viewer path", path: "/metrics/{viewer}", method: "get",
+			want: []string{`"in":"path"`, `"enum":["goroutine","heap"]`, `"$ref":"#/components/schemas/Metrics"`},
+		},
+		{
+			name: "query parameters", path: "/summary", method: "get",
+			want:   []string{`"name":"last"`, `"in":"query"`, `"type":"array"`, `"400"`},
+			absent: []string{`requestBody`},
+		},
+		{
+			name: "form of an admin route", path: "/control/gomaxprocs", method: "post",
+			want:   []string{`"application/x-www-form-urlencoded"`, `"procs"`, `"adminToken"`, `"401"`, `"403"`},
+			absent: []string{`"in":"query"`},
+		},
+		{
+			name: "read of an admin route", path: "/control/gomaxprocs", method: "get",
+			want:   []string{`"$ref":"#/components/schemas/ProcsState"`},
+			absent: []string{`"adminToken"`, `"400"`},
+		},
+		{
+			name: "JSON body of an admin route", path: "/config", method: "put",
+			want: []string{`"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/LiveConfig"}}},"required":true}`, `"adminToken"`, `"400"`},
+		},
+		{
+			name: "no content", path: "/control/gc", method: "post",
+			want:   []string{`"204"`},
+			absent: []string{`"200"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, ok := doc.Paths[tt.path][tt.method]
+			if !ok {
+				t.Fatalf("no %s %s", tt.method, tt.path)
+			}
+			var op bytes.Buffer
+			if err := json.Compact(&op, raw); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(op.String(), s) {
+					t.Errorf("%s doesn't contain %s", op.String(), s)
+				}
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(op.String(), s) {
+					t.Errorf("%s contains %s", op.String(), s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package statsview
+
+import (
+	"net/http"
+)
+
+// servedCounter counts the bytes written to the client
+type servedCounter struct {
+	http.ResponseWriter
+	n int
+}
+
+func (c *servedCounter) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.n += n
+	return n, err
+}
+
+func (c *servedCounter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// countServed accounts the size of every response as statsview overhead
+func (vm *ViewManager) countServed(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := &servedCounter{ResponseWriter: w}
+		h.ServeHTTP(c, r)
+		vm.Smgr.AddServed(c.n)
+	})
+}
+
+// overhead returns the accumulated cost of statsview, see viewer.Overhead
+func (vm *ViewManager) overhead(w http.ResponseWriter, r *http.Request) {
+	writeData(w, r, vm.Smgr.Overhead())
+}
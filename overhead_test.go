@@ -0,0 +1,38 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestCountServed(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	var served int
+	for _, path := range []string{"/debug/statsview/view/goroutine", "/debug/statsview/status", "/debug/statsview/missing"} {
+		rec := httptest.NewRecorder()
+		mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		served += rec.Body.Len()
+	}
+
+	rec := httptest.NewRecorder()
+	mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/overhead", nil))
+	var o viewer.Overhead
+	if err := json.Unmarshal(rec.Body.Bytes(), &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.ServedBytes != uint64(served) {
+		t.Errorf("served %d bytes, want %d", o.ServedBytes, served)
+	}
+	if o.Collections < 1 {
+		t.Errorf("collections = %d, want the served viewer counted", o.Collections)
+	}
+}
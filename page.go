@@ -0,0 +1,74 @@
+package statsview
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/render"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// chartsPage returns a page of the charts of views. The page is built for
+// every request so that it follows Register, Unregister and the live
+// configuration. The charts are validated once as their viewer is added,
+// which prefixes their assets with the host of go-echarts, they're served by
+// statsview under base instead
+func chartsPage(title, base string, views []viewer.Viewer, theme viewer.Theme) *components.Page {
+	page := components.NewPage()
+	page.PageTitle = title
+	page.AssetsHost = base + "/statics/"
+	page.Assets.JSAssets.Add("jquery.min.js")
+	for _, v := range views {
+		graph := viewer.ThemedView(v, theme)
+		for _, asset := range graph.JSAssets.Values {
+			page.Assets.JSAssets.Add(strings.TrimPrefix(asset, graph.AssetsHost))
+		}
+		page.Charts = append(page.Charts, graph)
+	}
+	return page
+}
+
+// themeCookie mirrors the theme picked in the dashboard, which keeps it in
+// the local storage, so the page is drawn with it
+const themeCookie = "statsview-theme"
+
+// pageTheme returns the theme picked by the user, the configured one by default
+func pageTheme(r *http.Request) viewer.Theme {
+	if c, err := r.Cookie(themeCookie); err == nil {
+		if theme := viewer.Theme(c.Value); theme == viewer.ThemeWesteros || theme == viewer.ThemeMacarons {
+			return theme
+		}
+	}
+	return viewer.ChartTheme()
+}
+
+// dashboard is the data of templates.PageTpl: the page, the path its
+// scripts build the URLs of the routes on, whether the admin controls are
+// shown and otherwise whether the admins could sign in
+type dashboard struct {
+	*components.Page
+	Base   string
+	Admin  bool
+	SignIn bool
+}
+
+// servePage serves the dashboard. The admin controls are shown to the
+// requests authorized on the admin routes, `?admin` asks the browser for the
+// admin credentials with basic auth, e.g. the admin token as the password
+func (vm *ViewManager) servePage(w http.ResponseWriter, r *http.Request) {
+	signIn := adminConfigured()
+	admin := signIn && authorized(r)
+	if _, ok := r.URL.Query()["admin"]; ok && signIn && !admin {
+		w.Header().Set("WWW-Authenticate", `Basic realm="statsview admin"`)
+		http.Error(w, "statsview: invalid admin token", http.StatusUnauthorized)
+		return
+	}
+
+	base := basePath(r)
+	page := chartsPage("Statsview", base, vm.views(), pageTheme(r))
+	page.Renderer = render.NewPageRender(dashboard{Page: page, Base: base, Admin: admin, SignIn: signIn}, page.Validate)
+	if err := writeHTML(w, r, page.Render); err != nil {
+		viewer.Logger().Error("statsview: failed to render page", "err", err)
+	}
+}
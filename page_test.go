@@ -0,0 +1,120 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestServePage(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithAdminToken(""))
+
+	tests := []struct {
+		name   string
+		token  string
+		path   string
+		auth   string
+		status int
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "no admin token",
+			path:   "/debug/statsview",
+			status: http.StatusOK,
+			want:   []string{`const base = "/debug/statsview";`, `href="/debug/statsview/goroutines"`},
+			absent: []string{`id="force-gc"`, `?admin`},
+		},
+		{
+			name:   "group",
+			path:   "/admin/debug/statsview",
+			status: http.StatusOK,
+			want:   []string{`const base = "/admin/debug/statsview";`, `href="/admin/debug/statsview/goroutines"`},
+			absent: []string{`"/debug/statsview/`},
+		},
+		{
+			name:   "read-only session",
+			token:  "s3cret",
+			path:   "/debug/statsview",
+			status: http.StatusOK,
+			want:   []string{`href="/debug/statsview?admin"`},
+			absent: []string{`id="force-gc"`, `id="baseline-set"`},
+		},
+		{
+			name:   "admin session",
+			token:  "s3cret",
+			path:   "/debug/statsview",
+			auth:   "Bearer s3cret",
+			status: http.StatusOK,
+			want:   []string{`id="force-gc"`, `id="baseline-set"`},
+			absent: []string{`?admin`},
+		},
+		{
+			name:   "admin sign in",
+			token:  "s3cret",
+			path:   "/debug/statsview?admin",
+			status: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithAdminToken(tt.token))
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			mgr.Handler().ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			body := rec.Body.String()
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("page doesn't contain %s", s)
+				}
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(body, s) {
+					t.Errorf("page contains %s", s)
+				}
+			}
+		})
+	}
+}
+
+func TestPageTheme(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithTheme(viewer.DefaultTheme))
+	viewer.SetConfiguration(viewer.WithTheme(viewer.ThemeMacarons))
+
+	tests := []struct {
+		name   string
+		cookie string
+		want   viewer.Theme
+	}{
+		{"configured", "", viewer.ThemeMacarons},
+		{"picked", "westeros", viewer.ThemeWesteros},
+		{"unknown", "dark", viewer.ThemeMacarons},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/debug/statsview", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: themeCookie, Value: tt.cookie})
+			}
+			if got := pageTheme(r); got != tt.want {
+				t.Errorf("pageTheme() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
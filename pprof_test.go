@@ -0,0 +1,32 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestPprofRoutes(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/debug/pprof/", http.StatusOK},
+		{"/debug/pprof/cmdline", http.StatusOK},
+		{"/debug/pprof/goroutine?debug=1", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package statsview
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// blockRate keeps the last value passed to runtime.SetBlockProfileRate
+// since the runtime offers no way to read it back
+var blockRate int64
+
+// blockProfileRate reports the block profile rate, POST with `rate` changes it
+func blockProfileRate(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		rate, err := strconv.Atoi(r.FormValue("rate"))
+		if err != nil {
+			http.Error(w, "statsview: invalid rate "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		runtime.SetBlockProfileRate(rate)
+		atomic.StoreInt64(&blockRate, int64(rate))
+	}
+
+	writeData(w, r, map[string]int64{"rate": atomic.LoadInt64(&blockRate)})
+}
+
+// mutexProfileFraction reports the mutex profile fraction, POST with `fraction` changes it
+func mutexProfileFraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		fraction, err := strconv.Atoi(r.FormValue("fraction"))
+		if err != nil || fraction < 0 {
+			http.Error(w, "statsview: invalid fraction", http.StatusBadRequest)
+			return
+		}
+		runtime.SetMutexProfileFraction(fraction)
+	}
+
+	writeData(w, r, map[string]int{"fraction": runtime.SetMutexProfileFraction(-1)})
+}
@@ -0,0 +1,57 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestProfileRates(t *testing.T) {
+	defer runtime.SetBlockProfileRate(0)
+	defer runtime.SetMutexProfileFraction(runtime.SetMutexProfileFraction(-1))
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		form    url.Values
+		key     string
+		status  int
+		want    int
+	}{
+		{"block rate", blockProfileRate, url.Values{"rate": {"100"}}, "rate", http.StatusOK, 100},
+		{"block rate read", blockProfileRate, nil, "rate", http.StatusOK, 100},
+		{"invalid block rate", blockProfileRate, url.Values{"rate": {"often"}}, "rate", http.StatusBadRequest, 0},
+		{"mutex fraction", mutexProfileFraction, url.Values{"fraction": {"5"}}, "fraction", http.StatusOK, 5},
+		{"mutex fraction read", mutexProfileFraction, nil, "fraction", http.StatusOK, 5},
+		{"negative mutex fraction", mutexProfileFraction, url.Values{"fraction": {"-1"}}, "fraction", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.form != nil {
+				r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.form.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			rec := httptest.NewRecorder()
+			tt.handler(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got map[string]int
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got[tt.key] != tt.want {
+				t.Errorf("%s = %d, want %d", tt.key, got[tt.key], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package statsview
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// profileDescriptions describe the profiles of the runtime, the other ones
+// are created by the program with pprof.NewProfile
+var profileDescriptions = map[string]string{
+	"allocs":       "A sampling of all past memory allocations",
+	"block":        "Stack traces that led to blocking on synchronization primitives",
+	"goroutine":    "Stack traces of all current goroutines",
+	"heap":         "A sampling of memory allocations of live objects",
+	"mutex":        "Stack traces of holders of contended mutexes",
+	"threadcreate": "Stack traces that led to the creation of new OS threads",
+}
+
+// profileInfo is a profile of pprof.Profiles
+type profileInfo struct {
+	Name        string `json:"name"`
+	Count       int    `json:"count"`
+	Description string `json:"description"`
+	Custom      bool   `json:"custom"`
+}
+
+// listProfiles returns the profiles of pprof.Profiles by name, the custom
+// ones of the program included
+func listProfiles(w http.ResponseWriter, r *http.Request) {
+	infos := []profileInfo{}
+	for _, p := range pprof.Profiles() {
+		desc, builtin := profileDescriptions[p.Name()]
+		if !builtin {
+			desc = "Custom profile created with pprof.NewProfile"
+		}
+		infos = append(infos, profileInfo{Name: p.Name(), Count: p.Count(), Description: desc, Custom: !builtin})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	writeData(w, r, infos)
+}
+
+// captureProfile writes the profile `name` at the `debug` level, the pprof
+// format is downloaded and the text forms are shown. It's served along
+// with the pprof routes only
+func captureProfile(w http.ResponseWriter, r *http.Request) {
+	if !viewer.PprofEnabled() {
+		http.Error(w, "statsview: pprof is disabled", http.StatusNotFound)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	p := pprof.Lookup(name)
+	if p == nil {
+		http.Error(w, "statsview: unknown profile "+name, http.StatusNotFound)
+		return
+	}
+	debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+	if debug < 0 || debug > 2 {
+		http.Error(w, "statsview: invalid debug level", http.StatusBadRequest)
+		return
+	}
+
+	if debug == 0 {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="%s-%s.pb.gz"`, fileName(name), time.Now().Format("20060102-150405")))
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	if err := p.WriteTo(w, debug); err != nil {
+		viewer.Logger().Error("statsview: failed to write profile", "profile", name, "err", err)
+	}
+}
+
+// fileName replaces the characters of a custom profile name which aren't
+// safe in a file name
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+var profilesTpl = template.Must(template.New("profiles").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Statsview - Profiles</title>
+	<script src="{{ .Base }}/statics/jquery.min.js"></script>
+	<style>
+		body { font-family: sans-serif; margin: 20px 40px; }
+		table { border-collapse: collapse; }
+		th, td { padding: 4px 12px; text-align: left; }
+		td.count { text-align: right; }
+		tr:nth-child(even) { background: #f6f6f6; }
+		.custom { color: #2f4554; font-weight: bold; }
+	</style>
+</head>
+<body>
+	<h3>Profiles</h3>
+	{{- if .Pprof }}
+	<p>The profiles of runtime/pprof, the custom ones created by the program included. Debug 0 downloads the
+		pprof format for <code>go tool pprof</code>, 1 shows the text form and 2 the goroutine stacks in the
+		form of an unrecovered panic.</p>
+	{{- else }}
+	<p>The captures are disabled with pprof, see viewer.WithoutPprof.</p>
+	{{- end }}
+	<table>
+		<thead><tr><th>Profile</th><th>Count</th><th>Description</th><th>Debug</th><th></th></tr></thead>
+		<tbody id="profiles"></tbody>
+	</table>
+	<p>CPU time: <a href="{{ .Base }}/flamegraph">flame graph</a>{{ if .Pprof }},
+		<a href="{{ .PprofPath }}/profile?seconds=30">CPU profile (30s)</a>{{ end }},
+		<a href="{{ .Base }}/profile/wallclock?seconds=30">wall-clock profile (30s)</a></p>
+<script type="text/javascript">
+"use strict";
+const base = {{ .Base }};
+let debug = {};
+function profiles_sync() {
+	$.getJSON(base + "/profiles/list", function (profiles) {
+		$("#profiles select").each(function () { debug[$(this).data("name")] = $(this).val(); });
+		let body = $("<tbody id='profiles'>");
+		for (const p of profiles) {
+			let levels = $("<select>").attr("data-name", p.name);
+			for (const level of [0, 1, 2]) {
+				if (level < 2 || p.name === "goroutine") {
+					levels.append($("<option>").val(level).text(level));
+				}
+			}
+			levels.val(debug[p.name] || "0");
+			let capture = $("<button>").text("Capture").prop("disabled", {{ not .Pprof }}).on("click", function () {
+				let url = base + "/profiles/capture?name=" + encodeURIComponent(p.name) + "&debug=" + levels.val();
+				window.open(url, levels.val() === "0" ? "_self" : "_blank");
+			});
+			body.append($("<tr>")
+				.append($("<td>").addClass(p.custom ? "custom" : "").text(p.name))
+				.append($("<td class='count'>").text(p.count))
+				.append($("<td>").text(p.description))
+				.append($("<td>").append(levels))
+				.append($("<td>").append(capture)));
+		}
+		$("#profiles").replaceWith(body);
+	});
+}
+$(function () {
+	profiles_sync();
+	setInterval(profiles_sync, 5000);
+});
+</script>
+</body>
+</html>
+`))
+
+func profilesPage(w http.ResponseWriter, r *http.Request) {
+	err := writeHTML(w, r, func(w io.Writer) error {
+		return profilesTpl.Execute(w, struct {
+			Base      string
+			PprofPath string
+			Pprof     bool
+		}{
+			Base:      basePath(r),
+			PprofPath: pprofPath(r),
+			Pprof:     viewer.PprofEnabled(),
+		})
+	})
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to render profiles page", "err", err)
+	}
+}
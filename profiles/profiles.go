@@ -0,0 +1,121 @@
+// Package profiles loads recorded pprof profiles (heap, goroutine, CPU, ...)
+// and charts them side by side, e.g. the heap growth across the profiles
+// captured by a CI job.
+//
+//	summaries, err := profiles.LoadAll("heap-1.pb.gz", "heap-2.pb.gz", "heap-3.pb.gz")
+//	if err != nil { ... }
+//	err = profiles.Render(os.Stdout, summaries, 10)
+package profiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// Summary is the totals of a recorded profile in base units (bytes, seconds or counts)
+type Summary struct {
+	Name string
+	Time time.Time
+	// Types are the sample types like "inuse_space", Units their base units
+	Types  []string
+	Units  []string
+	Totals []float64
+	// Top is the flat value of every function for the default sample type
+	Top map[string]float64
+	// Default is the index of the default sample type
+	Default int
+}
+
+// Load reads the profile at path, gzipped or not
+func Load(path string) (Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer f.Close()
+
+	p, err := profile.Parse(f)
+	if err != nil {
+		return Summary{}, fmt.Errorf("statsview: invalid profile %s: %w", path, err)
+	}
+
+	s := Summary{
+		Name:    filepath.Base(path),
+		Time:    time.Unix(0, p.TimeNanos),
+		Top:     make(map[string]float64),
+		Default: len(p.SampleType) - 1,
+	}
+	if p.TimeNanos == 0 {
+		if fi, err := f.Stat(); err == nil {
+			s.Time = fi.ModTime()
+		}
+	}
+
+	factors := make([]float64, len(p.SampleType))
+	for i, st := range p.SampleType {
+		unit, factor := baseUnit(st.Unit)
+		s.Types = append(s.Types, st.Type)
+		s.Units = append(s.Units, unit)
+		factors[i] = factor
+		if st.Type == p.DefaultSampleType {
+			s.Default = i
+		}
+	}
+
+	s.Totals = make([]float64, len(p.SampleType))
+	for _, sample := range p.Sample {
+		for i, v := range sample.Value {
+			s.Totals[i] += float64(v) * factors[i]
+		}
+		if len(sample.Location) > 0 && s.Default >= 0 {
+			s.Top[funcName(sample.Location[0])] += float64(sample.Value[s.Default]) * factors[s.Default]
+		}
+	}
+	return s, nil
+}
+
+// LoadAll reads the profiles at paths, sorted by the time they were recorded
+func LoadAll(paths ...string) ([]Summary, error) {
+	summaries := make([]Summary, 0, len(paths))
+	for _, path := range paths {
+		s, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Time.Before(summaries[j].Time)
+	})
+	return summaries, nil
+}
+
+// baseUnit returns the base unit of a pprof unit and the factor converting to it
+func baseUnit(unit string) (string, float64) {
+	switch unit {
+	case "bytes":
+		return "bytes", 1
+	case "nanoseconds":
+		return "seconds", 1e-9
+	case "microseconds":
+		return "seconds", 1e-6
+	case "milliseconds":
+		return "seconds", 1e-3
+	case "seconds":
+		return "seconds", 1
+	}
+	return unit, 1
+}
+
+// funcName returns the innermost function of the location
+func funcName(loc *profile.Location) string {
+	if len(loc.Line) == 0 || loc.Line[0].Function == nil {
+		return fmt.Sprintf("0x%x", loc.Address)
+	}
+	return loc.Line[0].Function.Name
+}
@@ -0,0 +1,208 @@
+package profiles
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/google/pprof/profile"
+)
+
+// sample of a profile, the value of each sample type in the innermost function fn
+type sample struct {
+	fn     string
+	values []int64
+}
+
+// writeProfile writes a gzipped profile with the sample types like
+// "inuse_space/bytes" and returns its path
+func writeProfile(t *testing.T, dir, name string, at time.Time, types []string, defaultType string, samples []sample) string {
+	t.Helper()
+	p := &profile.Profile{DefaultSampleType: defaultType}
+	if !at.IsZero() {
+		p.TimeNanos = at.UnixNano()
+	}
+	for _, st := range types {
+		typ, unit, _ := strings.Cut(st, "/")
+		p.SampleType = append(p.SampleType, &profile.ValueType{Type: typ, Unit: unit})
+	}
+	funcs := map[string]*profile.Location{}
+	for _, s := range samples {
+		loc, ok := funcs[s.fn]
+		if !ok {
+			fn := &profile.Function{ID: uint64(len(p.Function) + 1), Name: s.fn}
+			loc = &profile.Location{ID: uint64(len(p.Location) + 1), Line: []profile.Line{{Function: fn}}}
+			p.Function = append(p.Function, fn)
+			p.Location = append(p.Location, loc)
+			funcs[s.fn] = loc
+		}
+		p.Sample = append(p.Sample, &profile.Sample{Location: []*profile.Location{loc}, Value: s.values})
+	}
+
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := p.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+var heapTypes = []string{"alloc_space/bytes", "inuse_space/bytes"}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    Summary
+		wantErr bool
+	}{
+		{
+			name: "heap",
+			path: writeProfile(t, dir, "heap.pb.gz", at, heapTypes, "inuse_space", []sample{
+				{"main.alloc", []int64{4096, 1024}},
+				{"main.alloc", []int64{4096, 1024}},
+				{"main.keep", []int64{100, 100}},
+			}),
+			want: Summary{
+				Name: "heap.pb.gz", Time: at,
+				Types: []string{"alloc_space", "inuse_space"}, Units: []string{"bytes", "bytes"},
+				Totals:  []float64{8292, 2148},
+				Top:     map[string]float64{"main.alloc": 2048, "main.keep": 100},
+				Default: 1,
+			},
+		},
+		{
+			name: "cpu in seconds, last type by default",
+			path: writeProfile(t, dir, "cpu.pb.gz", at, []string{"samples/count", "cpu/nanoseconds"}, "", []sample{
+				{"main.spin", []int64{3, 25e7}},
+			}),
+			want: Summary{
+				Name: "cpu.pb.gz", Time: at,
+				Types: []string{"samples", "cpu"}, Units: []string{"count", "seconds"},
+				Totals:  []float64{3, 0.25},
+				Top:     map[string]float64{"main.spin": 0.25},
+				Default: 1,
+			},
+		},
+		{name: "missing", path: filepath.Join(dir, "missing.pb.gz"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got.Time = got.Time.UTC()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := filepath.Join(dir, "invalid.pb.gz")
+	os.WriteFile(invalid, []byte("not a profile"), 0o644)
+	if _, err := Load(invalid); err == nil || !strings.Contains(err.Error(), "invalid profile") {
+		t.Errorf("Load() of an invalid profile = %v", err)
+	}
+}
+
+func TestLoadAllSortsByTime(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	paths := []string{
+		writeProfile(t, dir, "b.pb.gz", at.Add(time.Minute), heapTypes, "", nil),
+		writeProfile(t, dir, "c.pb.gz", at.Add(2*time.Minute), heapTypes, "", nil),
+		writeProfile(t, dir, "a.pb.gz", at, heapTypes, "", nil),
+	}
+
+	summaries, err := LoadAll(paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range summaries {
+		names = append(names, s.Name)
+	}
+	if want := []string{"a.pb.gz", "b.pb.gz", "c.pb.gz"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("LoadAll() = %v, want %v", names, want)
+	}
+
+	if _, err := LoadAll(append(paths, filepath.Join(dir, "missing"))...); err == nil {
+		t.Error("LoadAll() ignored a missing profile")
+	}
+}
+
+func TestCharts(t *testing.T) {
+	summaries := []Summary{
+		{Name: "1", Types: []string{"inuse_space"}, Units: []string{"bytes"}, Totals: []float64{1 << 20},
+			Top: map[string]float64{"a": 1 << 20}},
+		{Name: "2", Types: []string{"inuse_space"}, Units: []string{"bytes"}, Totals: []float64{3 << 20},
+			Top: map[string]float64{"a": 1 << 20, "b": 2 << 20, "c": 512 << 10}},
+	}
+
+	tests := []struct {
+		name      string
+		summaries []Summary
+		top       int
+		titles    []string
+		series    []string
+	}{
+		{"none", nil, 10, nil, nil},
+		{"totals only", summaries, 0, []string{"Total inuse_space"}, nil},
+		{"top 2", summaries, 2, []string{"Total inuse_space", "Top inuse_space"}, []string{"b", "a"}},
+		{"top of the last profile", summaries, 10, []string{"Total inuse_space", "Top inuse_space"}, []string{"b", "a", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Charts(tt.summaries, tt.top)
+			var titles, series []string
+			for _, l := range lines {
+				titles = append(titles, l.Title.Title)
+			}
+			if len(lines) > 1 {
+				for _, s := range lines[1].MultiSeries {
+					series = append(series, s.Name)
+				}
+			}
+			if !reflect.DeepEqual(titles, tt.titles) || !reflect.DeepEqual(series, tt.series) {
+				t.Errorf("Charts() = %v %v, want %v %v", titles, series, tt.titles, tt.series)
+			}
+			if len(lines) > 0 {
+				total := lines[0].MultiSeries[0].Data.([]opts.LineData)
+				if total[0].Value != 1.0 || total[1].Value != 3.0 {
+					t.Errorf("totals = %v, want 1 and 3 MiB", total)
+				}
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	summaries := []Summary{{Name: "1", Types: []string{"goroutine"}, Units: []string{"count"}, Totals: []float64{12},
+		Top: map[string]float64{"runtime.gopark": 10}}}
+	if err := Render(&buf, summaries, 5); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, s := range []string{`id="chart-0"`, `id="chart-1"`, "Total goroutine", "runtime.gopark"} {
+		if !strings.Contains(page, s) {
+			t.Errorf("the page doesn't contain %s", s)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package profiles
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/template"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"github.com/mortum5/statsview/statics"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// the page embeds echarts so the report could be opened offline, e.g. as a CI artifact
+var pageTpl = template.Must(template.New("profiles").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Statsview profiles</title>
+<script>{{ .EchartJS }}</script>
+<script>{{ .ThemeJS }}</script>
+<style> body { font-family:sans-serif } .box { justify-content:center; display:flex; flex-wrap:wrap } .item { width:600px; height:400px; margin:10px } </style>
+</head>
+<body>
+<div class="box">
+{{- range $i, $c := .Charts }}
+<div class="item" id="chart-{{ $i }}"></div>
+{{- end }}
+</div>
+<script>
+{{- range $i, $c := .Charts }}
+echarts.init(document.getElementById("chart-{{ $i }}"), "macarons").setOption({{ $c }});
+{{- end }}
+</script>
+</body>
+</html>
+`))
+
+// Charts returns a chart of the totals of every sample type across the
+// summaries and a chart of the top functions of the default sample type,
+// which are the largest ones of the last summary
+func Charts(summaries []Summary, top int) []*charts.Line {
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(summaries))
+	for _, s := range summaries {
+		names = append(names, s.Name)
+	}
+
+	last := summaries[len(summaries)-1]
+	var lines []*charts.Line
+	for i, typ := range last.Types {
+		unit := displayUnit(last.Units[i])
+		data := make([]opts.LineData, 0, len(summaries))
+		for _, s := range summaries {
+			data = append(data, opts.LineData{Value: valueOf(s, typ, unit)})
+		}
+		line := newLine(names, fmt.Sprintf("Total %s", typ), unit)
+		line.AddSeries(typ, data)
+		lines = append(lines, line)
+	}
+
+	if last.Default >= 0 && last.Default < len(last.Types) && top > 0 {
+		unit := displayUnit(last.Units[last.Default])
+		funcs := make([]string, 0, len(last.Top))
+		for fn := range last.Top {
+			funcs = append(funcs, fn)
+		}
+		sort.Slice(funcs, func(i, j int) bool { return last.Top[funcs[i]] > last.Top[funcs[j]] })
+		if len(funcs) > top {
+			funcs = funcs[:top]
+		}
+
+		line := newLine(names, fmt.Sprintf("Top %s", last.Types[last.Default]), unit)
+		for _, fn := range funcs {
+			data := make([]opts.LineData, 0, len(summaries))
+			for _, s := range summaries {
+				data = append(data, opts.LineData{Value: unit.Convert(s.Top[fn])})
+			}
+			line.AddSeries(fn, data)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Render writes a standalone HTML page of the Charts of summaries
+func Render(w io.Writer, summaries []Summary, top int) error {
+	var options []string
+	for _, line := range Charts(summaries, top) {
+		line.Validate()
+		bs, err := json.Marshal(line.JSON())
+		if err != nil {
+			return err
+		}
+		options = append(options, string(bs))
+	}
+
+	return pageTpl.Execute(w, struct {
+		EchartJS string
+		ThemeJS  string
+		Charts   []string
+	}{statics.EchartJS, statics.MacaronsJS, options})
+}
+
+func newLine(xAxis []string, title string, unit viewer.Unit) *charts.Line {
+	yAxis := opts.YAxis{Name: "Value"}
+	if unit != viewer.UnitNone {
+		yAxis.AxisLabel = &opts.AxisLabel{Formatter: "{value} " + string(unit)}
+	}
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: title}),
+		charts.WithLegendOpts(opts.Legend{Show: true, Top: "bottom"}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true, Trigger: "axis"}),
+		charts.WithXAxisOpts(opts.XAxis{Name: "Profile"}),
+		charts.WithYAxisOpts(yAxis),
+	)
+	line.SetXAxis(xAxis)
+	return line
+}
+
+// displayUnit charts bytes in MiB and seconds as they are
+func displayUnit(base string) viewer.Unit {
+	switch base {
+	case "bytes":
+		return viewer.UnitMiB
+	case "seconds":
+		return viewer.UnitSeconds
+	}
+	return viewer.UnitNone
+}
+
+// valueOf returns the total of the sample type, zero if the profile hasn't it
+func valueOf(s Summary, typ string, unit viewer.Unit) float64 {
+	for i, t := range s.Types {
+		if t == typ {
+			return unit.Convert(s.Totals[i])
+		}
+	}
+	return 0
+}
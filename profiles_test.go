@@ -0,0 +1,119 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+// testProfile is a custom profile of the program
+var testProfile = pprof.NewProfile("statsview.test/conns")
+
+func TestListProfiles(t *testing.T) {
+	conn := new(int)
+	testProfile.Add(conn, 0)
+	defer testProfile.Remove(conn)
+
+	rec := httptest.NewRecorder()
+	listProfiles(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/profiles/list", nil))
+	var infos []profileInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &infos); err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]profileInfo)
+	for i, p := range infos {
+		if i > 0 && infos[i-1].Name >= p.Name {
+			t.Errorf("%s listed after %s", p.Name, infos[i-1].Name)
+		}
+		byName[p.Name] = p
+	}
+
+	tests := []struct {
+		name string
+		want profileInfo
+	}{
+		{"builtin", profileInfo{Name: "threadcreate", Description: profileDescriptions["threadcreate"]}},
+		{"custom", profileInfo{Name: "statsview.test/conns", Count: 1, Description: "Custom profile created with pprof.NewProfile", Custom: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := byName[tt.want.Name]
+			if !ok {
+				t.Fatalf("%s isn't listed", tt.want.Name)
+			}
+			if !tt.want.Custom {
+				// the count of the runtime profiles varies
+				got.Count = 0
+			}
+			if got != tt.want {
+				t.Errorf("profile %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		status      int
+		contentType string
+		disposition string
+	}{
+		{name: "unknown profile", query: "?name=cpu", status: http.StatusNotFound},
+		{name: "invalid debug level", query: "?name=heap&debug=3", status: http.StatusBadRequest},
+		{
+			name:        "pprof format",
+			query:       "?name=goroutine",
+			status:      http.StatusOK,
+			contentType: "application/octet-stream",
+			disposition: `attachment; filename="goroutine-`,
+		},
+		{
+			name:        "custom profile file name",
+			query:       "?name=statsview.test/conns",
+			status:      http.StatusOK,
+			contentType: "application/octet-stream",
+			disposition: `attachment; filename="statsview.test_conns-`,
+		},
+		{name: "text", query: "?name=goroutine&debug=1", status: http.StatusOK, contentType: "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			captureProfile(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/profiles/capture"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("Content-Type = %s, want %s", ct, tt.contentType)
+			}
+			if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, tt.disposition) {
+				t.Errorf("Content-Disposition = %s, want %s...", cd, tt.disposition)
+			}
+			if rec.Body.Len() == 0 {
+				t.Error("empty profile")
+			}
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"heap", "heap"},
+		{"github.com/app/conns", "github.com_app_conns"},
+		{"db pool:open", "db_pool_open"},
+		{"sessions-v2_ü", "sessions-v2_ü"},
+	}
+	for _, tt := range tests {
+		if got := fileName(tt.name); got != tt.want {
+			t.Errorf("fileName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package statsview
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func logPanic(name string, p interface{}) {
+	viewer.Logger().Error("statsview: viewer panicked", "viewer", name, "panic", p, "stack", string(debug.Stack()))
+}
+
+// safeServe serves the viewer into a buffer so a panic results in a 500 JSON
+// error instead of a partial response, the callers count the 500 as an error
+func (vm *ViewManager) safeServe(v viewer.Viewer, r *http.Request) (resp *viewer.ResponseBuffer) {
+	resp = viewer.NewResponseBuffer(nil)
+	defer func() {
+		if p := recover(); p != nil {
+			logPanic(v.Name(), p)
+			resp = viewer.NewResponseBuffer(nil)
+			viewer.WriteError(resp, http.StatusInternalServerError, fmt.Errorf("statsview: viewer %s panicked: %v", v.Name(), p))
+		}
+	}()
+	vm.Smgr.Measure(func() { v.Serve(resp, r) })
+	return resp
+}
+
+// safeCollect collects the points of the viewer, a panic is logged and
+// counted and nothing is returned
+func (vm *ViewManager) safeCollect(v viewer.Viewer, c viewer.Collector) (points []viewer.Point) {
+	defer func() {
+		if p := recover(); p != nil {
+			logPanic(v.Name(), p)
+			vm.countError(v.Name())
+			points = nil
+		}
+	}()
+	return c.Collect()
+}
@@ -0,0 +1,104 @@
+package statsview
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// panickingViewer panics while serving and collecting
+type panickingViewer struct {
+	viewer.Viewer
+}
+
+func (vr *panickingViewer) Name() string { return "panicking" }
+
+func (vr *panickingViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	w.Write([]byte(`{"values":`))
+	panic("boom")
+}
+
+func (vr *panickingViewer) Collect() []viewer.Point {
+	panic("boom")
+}
+
+func TestRecoverPanics(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithLogger(nil))
+	viewer.SetConfiguration(viewer.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	v := &panickingViewer{Viewer: viewer.NewGoroutinesViewer()}
+	mgr, err := New(Viewers{v, viewer.NewGCNumViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		name   string
+		call   func() *httptest.ResponseRecorder
+		status int
+		errors int64
+	}{
+		{
+			name: "view",
+			call: func() *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/view/panicking", nil))
+				return rec
+			},
+			status: http.StatusInternalServerError,
+			errors: 1,
+		},
+		{
+			name: "view all",
+			call: func() *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				mgr.serveAll(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/view/all", nil))
+				return rec
+			},
+			status: http.StatusOK,
+			errors: 2,
+		},
+		{
+			name: "collect",
+			call: func() *httptest.ResponseRecorder {
+				if points := mgr.safeCollect(v, v); points != nil {
+					t.Errorf("safeCollect() = %v, want nothing", points)
+				}
+				return nil
+			},
+			errors: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := tt.call()
+			if got := *mgr.viewErrors["panicking"]; got != tt.errors {
+				t.Errorf("errors = %d, want %d", got, tt.errors)
+			}
+			if rec == nil {
+				return
+			}
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			var body map[string]json.RawMessage
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("the response isn't JSON: %s", rec.Body)
+			}
+			if tt.status == http.StatusInternalServerError && !strings.Contains(string(body["error"]), "panicked: boom") {
+				t.Errorf("error = %s", body["error"])
+			}
+			if _, ok := body["panicking"]; tt.status == http.StatusOK && ok {
+				t.Errorf("the panicking viewer is in %s", rec.Body)
+			}
+		})
+	}
+}
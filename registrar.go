@@ -0,0 +1,175 @@
+package statsview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/registry"
+	"github.com/mortum5/statsview/viewer"
+)
+
+const registryTimeout = 5 * time.Second
+
+var (
+	// registryRetry is the delay before the first retry of a failed
+	// registration, it doubles up to registryMaxRetry
+	registryRetry    = time.Second
+	registryMaxRetry = time.Minute
+
+	// interfaceAddrs lists the addresses of the host, replaced by tests
+	interfaceAddrs = net.InterfaceAddrs
+)
+
+var errNoAdvertiseAddr = errors.New("statsview: no non-loopback address to announce, see viewer.WithAdvertiseAddr")
+
+// registration is the state of the registrations running in the background
+type registration struct {
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	endpoint registry.Endpoint
+	// done are the registrars the endpoint is registered to
+	done []registry.Registrar
+}
+
+// AddRegistrar registers a service catalog the dashboard is announced to on
+// Start and removed from on Stop. It must be called before Start
+func (vm *ViewManager) AddRegistrar(r registry.Registrar) {
+	vm.registrars = append(vm.registrars, r)
+}
+
+func (vm *ViewManager) endpoint() (registry.Endpoint, error) {
+	addr, err := advertiseAddr()
+	if err != nil {
+		return registry.Endpoint{}, err
+	}
+	e := registry.Endpoint{
+		Addr:     addr,
+		BasePath: BasePath,
+	}
+	if viewer.AdminToken() != "" || viewer.ReadToken() != "" {
+		e.AuthHint = "bearer"
+	}
+	return e, nil
+}
+
+// advertiseAddr returns the address announced to the catalogs: the one of
+// WithAdvertiseAddr, or the link address with a loopback or unspecified host
+// replaced by the first non-loopback address of the host, IPv4 first
+func advertiseAddr() (string, error) {
+	if addr := viewer.AdvertiseAddr(); addr != "" {
+		return addr, nil
+	}
+	host, port, err := net.SplitHostPort(viewer.LinkAddr())
+	if err != nil {
+		return "", err
+	}
+	if !localHost(host) {
+		return viewer.LinkAddr(), nil
+	}
+
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	var found net.IP
+	for _, a := range addrs {
+		ipNet, ok := a.(*net.IPNet)
+		if !ok || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			found = ipNet.IP
+			break
+		}
+		if found == nil {
+			found = ipNet.IP
+		}
+	}
+	if found == nil {
+		return "", errNoAdvertiseAddr
+	}
+	return net.JoinHostPort(found.String(), port), nil
+}
+
+// localHost reports whether the host can't be reached from other hosts,
+// i.e. it's a loopback or an unspecified address
+func localHost(host string) bool {
+	if host == "" || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// register announces the dashboard to the registrars in the background, so
+// an unreachable catalog doesn't delay the start. A failed registration is
+// retried with a growing delay until it succeeds or the manager stops
+func (vm *ViewManager) register() {
+	if len(vm.registrars) == 0 {
+		return
+	}
+	e, err := vm.endpoint()
+	if err != nil {
+		viewer.Logger().Error("statsview: registration failed", "err", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	vm.registration.cancel, vm.registration.endpoint = cancel, e
+	for _, r := range vm.registrars {
+		vm.registration.wg.Add(1)
+		go func(r registry.Registrar) {
+			defer vm.registration.wg.Done()
+			vm.registerLoop(ctx, r, e)
+		}(r)
+	}
+}
+
+func (vm *ViewManager) registerLoop(ctx context.Context, r registry.Registrar, e registry.Endpoint) {
+	for delay := registryRetry; ; delay = min(2*delay, registryMaxRetry) {
+		attempt, cancel := context.WithTimeout(ctx, registryTimeout)
+		err := r.Register(attempt, e)
+		cancel()
+		if err == nil {
+			vm.registration.mu.Lock()
+			vm.registration.done = append(vm.registration.done, r)
+			vm.registration.mu.Unlock()
+			return
+		}
+		viewer.Logger().Error("statsview: registration failed",
+			"registrar", fmt.Sprintf("%T", r), "err", err, "retry", delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// deregister stops the registrations still retried and removes the endpoint
+// from the registrars it was registered to
+func (vm *ViewManager) deregister() {
+	if vm.registration.cancel == nil {
+		return
+	}
+	vm.registration.cancel()
+	vm.registration.wg.Wait()
+
+	vm.registration.mu.Lock()
+	done := vm.registration.done
+	vm.registration.done = nil
+	vm.registration.mu.Unlock()
+	for _, r := range done {
+		ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+		if err := r.Deregister(ctx, vm.registration.endpoint); err != nil {
+			viewer.Logger().Error("statsview: deregistration failed", "registrar", fmt.Sprintf("%T", r), "err", err)
+		}
+		cancel()
+	}
+}
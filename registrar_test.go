@@ -0,0 +1,139 @@
+package statsview
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/registry"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestAdvertiseAddr(t *testing.T) {
+	defer viewer.RestoreConfiguration(viewer.SaveConfiguration())
+	defer func(f func() ([]net.Addr, error)) { interfaceAddrs = f }(interfaceAddrs)
+
+	ipNet := func(s string) net.Addr {
+		ip, n, _ := net.ParseCIDR(s)
+		return &net.IPNet{IP: ip, Mask: n.Mask}
+	}
+	host := []net.Addr{ipNet("127.0.0.1/8"), ipNet("fe80::1/64"), ipNet("2001:db8::7/64"), ipNet("10.0.0.7/24")}
+
+	tests := []struct {
+		name      string
+		advertise string
+		link      string
+		addrs     []net.Addr
+		want      string
+		err       error
+	}{
+		{name: "explicit", advertise: "billing.internal:9000", link: "localhost:18066", addrs: host, want: "billing.internal:9000"},
+		{name: "routable link host", link: "10.1.2.3:18066", addrs: host, want: "10.1.2.3:18066"},
+		{name: "localhost resolved", link: "localhost:18066", addrs: host, want: "10.0.0.7:18066"},
+		{name: "loopback resolved", link: "127.0.0.1:8087", addrs: host, want: "10.0.0.7:8087"},
+		{name: "unspecified resolved", link: ":18066", addrs: host, want: "10.0.0.7:18066"},
+		{name: "ipv6 only", link: "[::1]:18066", addrs: host[:3], want: "[2001:db8::7]:18066"},
+		{name: "loopback only", link: "localhost:18066", addrs: host[:2], err: errNoAdvertiseAddr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithLinkAddr(tt.link), viewer.WithAdvertiseAddr(tt.advertise))
+			interfaceAddrs = func() ([]net.Addr, error) { return tt.addrs, nil }
+
+			got, err := advertiseAddr()
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Errorf("advertiseAddr() = %q, %v, want %q, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+// flakyRegistrar fails the first registrations, blocking on them until the
+// context is done when block is set
+type flakyRegistrar struct {
+	mu           sync.Mutex
+	failures     int
+	block        bool
+	registers    int
+	deregistered []registry.Endpoint
+	registered   chan registry.Endpoint
+}
+
+func (r *flakyRegistrar) Register(ctx context.Context, e registry.Endpoint) error {
+	r.mu.Lock()
+	r.registers++
+	fail := r.registers <= r.failures
+	r.mu.Unlock()
+	if r.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if fail {
+		return errors.New("catalog unreachable")
+	}
+	r.registered <- e
+	return nil
+}
+
+func (r *flakyRegistrar) Deregister(_ context.Context, e registry.Endpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deregistered = append(r.deregistered, e)
+	return nil
+}
+
+func TestRegisterInBackground(t *testing.T) {
+	defer viewer.RestoreConfiguration(viewer.SaveConfiguration())
+	defer func(retry, max time.Duration) { registryRetry, registryMaxRetry = retry, max }(registryRetry, registryMaxRetry)
+	registryRetry, registryMaxRetry = time.Millisecond, 4*time.Millisecond
+	viewer.SetConfiguration(viewer.WithAdvertiseAddr("10.0.0.7:18066"))
+
+	tests := []struct {
+		name       string
+		registrar  *flakyRegistrar
+		registered bool
+	}{
+		{"registered", &flakyRegistrar{}, true},
+		{"retried", &flakyRegistrar{failures: 3}, true},
+		{"unreachable", &flakyRegistrar{block: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.registrar.registered = make(chan registry.Endpoint, 1)
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			mgr.AddRegistrar(tt.registrar)
+
+			start := time.Now()
+			mgr.Handler()
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("started in %v, the registration delays the start", elapsed)
+			}
+			if tt.registered {
+				select {
+				case e := <-tt.registrar.registered:
+					if e.Addr != "10.0.0.7:18066" {
+						t.Errorf("registered %s", e.Addr)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("not registered")
+				}
+			}
+
+			mgr.Stop()
+			tt.registrar.mu.Lock()
+			defer tt.registrar.mu.Unlock()
+			if got := len(tt.registrar.deregistered); (got == 1) != tt.registered {
+				t.Errorf("%d deregistrations, registered %v", got, tt.registered)
+			}
+			if tt.registrar.registers <= tt.registrar.failures && tt.registered {
+				t.Errorf("%d registrations, want a retry after %d failures", tt.registrar.registers, tt.registrar.failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Consul registers the dashboard as a service of the local Consul agent
+type Consul struct {
+	agent   string
+	service string
+	token   string
+	client  *http.Client
+}
+
+// NewConsul returns the Consul registrar, agent is the agent http address
+// like `http://127.0.0.1:8500`, token is the optional ACL token
+func NewConsul(agent, service, token string) *Consul {
+	return &Consul{
+		agent:   strings.TrimSuffix(agent, "/"),
+		service: service,
+		token:   token,
+		client:  &http.Client{},
+	}
+}
+
+func (c *Consul) id(e Endpoint) string {
+	return c.service + "-statsview-" + e.Addr
+}
+
+func (c *Consul) header() http.Header {
+	h := http.Header{}
+	if c.token != "" {
+		h.Set("X-Consul-Token", c.token)
+	}
+	return h
+}
+
+func (c *Consul) Register(ctx context.Context, e Endpoint) error {
+	host, portStr, err := net.SplitHostPort(e.Addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return err
+	}
+
+	svc := map[string]interface{}{
+		"ID":      c.id(e),
+		"Name":    c.service + "-statsview",
+		"Address": host,
+		"Port":    port,
+		"Tags":    []string{"statsview"},
+		"Meta": map[string]string{
+			"statsview_path": e.BasePath,
+			"statsview_auth": e.AuthHint,
+		},
+	}
+	return doJSON(ctx, c.client, http.MethodPut, c.agent+"/v1/agent/service/register", c.header(), svc, nil)
+}
+
+func (c *Consul) Deregister(ctx context.Context, e Endpoint) error {
+	return doJSON(ctx, c.client, http.MethodPut, c.agent+"/v1/agent/service/deregister/"+c.id(e), c.header(), nil, nil)
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Etcd puts the dashboard under `<prefix><addr>` via the etcd v3 JSON gateway.
+// The key is bound to a lease which is kept alive while statsview runs, so a
+// crashed process disappears from the catalog once the TTL expires
+type Etcd struct {
+	endpoint string
+	prefix   string
+	ttl      time.Duration
+	client   *http.Client
+
+	mu     sync.Mutex
+	lease  string
+	cancel context.CancelFunc
+}
+
+// NewEtcd returns the Etcd registrar, endpoint is like `http://127.0.0.1:2379`
+// and prefix like `/statsview/my-service/`
+func NewEtcd(endpoint, prefix string, ttl time.Duration) *Etcd {
+	if ttl < 2*time.Second {
+		ttl = 30 * time.Second
+	}
+	return &Etcd{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		prefix:   prefix,
+		ttl:      ttl,
+		client:   &http.Client{},
+	}
+}
+
+func b64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func (e *Etcd) Register(ctx context.Context, ep Endpoint) error {
+	var grant struct {
+		ID string `json:"ID"`
+	}
+	err := doJSON(ctx, e.client, http.MethodPost, e.endpoint+"/v3/lease/grant", nil,
+		map[string]interface{}{"TTL": int64(e.ttl / time.Second)}, &grant)
+	if err != nil {
+		return err
+	}
+
+	value, _ := json.Marshal(ep)
+	err = doJSON(ctx, e.client, http.MethodPost, e.endpoint+"/v3/kv/put", nil, map[string]string{
+		"key":   b64(e.prefix + ep.Addr),
+		"value": b64(string(value)),
+		"lease": grant.ID,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	kctx, cancel := context.WithCancel(context.Background())
+	e.mu.Lock()
+	e.lease, e.cancel = grant.ID, cancel
+	e.mu.Unlock()
+	go e.keepAlive(kctx, grant.ID)
+	return nil
+}
+
+func (e *Etcd) keepAlive(ctx context.Context, lease string) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			doJSON(ctx, e.client, http.MethodPost, e.endpoint+"/v3/lease/keepalive", nil,
+				map[string]string{"ID": lease}, nil)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *Etcd) Deregister(ctx context.Context, _ Endpoint) error {
+	e.mu.Lock()
+	lease, cancel := e.lease, e.cancel
+	e.lease, e.cancel = "", nil
+	e.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	// revoking the lease deletes the key as well
+	return doJSON(ctx, e.client, http.MethodPost, e.endpoint+"/v3/lease/revoke", nil,
+		map[string]string{"ID": lease}, nil)
+}
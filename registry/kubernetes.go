@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// AnnotationAddr is the pod annotation holding the dashboard address
+	AnnotationAddr = "statsview/addr"
+	// AnnotationPath is the pod annotation holding the dashboard base path
+	AnnotationPath = "statsview/path"
+	// AnnotationAuth is the pod annotation holding the auth hint
+	AnnotationAuth = "statsview/auth"
+
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount/"
+)
+
+// Kubernetes annotates the pod statsview runs in, using the in-cluster
+// service account. The pod name is taken from `POD_NAME` (downward API) or
+// the hostname, the service account needs the `patch` verb on pods
+type Kubernetes struct {
+	apiServer string
+	namespace string
+	pod       string
+	token     string
+	client    *http.Client
+}
+
+// NewKubernetes returns the Kubernetes registrar configured from the in-cluster environment
+func NewKubernetes() (*Kubernetes, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("registry: not running inside a kubernetes cluster")
+	}
+
+	token, err := os.ReadFile(serviceAccountDir + "token")
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := os.ReadFile(serviceAccountDir + "namespace")
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+
+	pod := os.Getenv("POD_NAME")
+	if pod == "" {
+		if pod, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Kubernetes{
+		apiServer: "https://" + net.JoinHostPort(host, port),
+		namespace: strings.TrimSpace(string(namespace)),
+		pod:       pod,
+		token:     strings.TrimSpace(string(token)),
+		client: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+	}, nil
+}
+
+func (k *Kubernetes) patch(ctx context.Context, annotations map[string]interface{}) error {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer "+k.token)
+	h.Set("Content-Type", "application/merge-patch+json")
+
+	url := k.apiServer + "/api/v1/namespaces/" + k.namespace + "/pods/" + k.pod
+	body := map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	}
+	return doJSON(ctx, k.client, http.MethodPatch, url, h, body, nil)
+}
+
+func (k *Kubernetes) Register(ctx context.Context, e Endpoint) error {
+	return k.patch(ctx, map[string]interface{}{
+		AnnotationAddr: e.Addr,
+		AnnotationPath: e.BasePath,
+		AnnotationAuth: e.AuthHint,
+	})
+}
+
+func (k *Kubernetes) Deregister(ctx context.Context, _ Endpoint) error {
+	// null removes the keys with a merge patch
+	return k.patch(ctx, map[string]interface{}{
+		AnnotationAddr: nil,
+		AnnotationPath: nil,
+		AnnotationAuth: nil,
+	})
+}
@@ -0,0 +1,68 @@
+// Package registry announces the statsview dashboard to service catalogs so
+// fleet tooling could discover it, the entry is removed when the manager stops.
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Endpoint describes the dashboard published into a catalog
+type Endpoint struct {
+	Addr     string `json:"addr"`
+	BasePath string `json:"basePath"`
+	AuthHint string `json:"auth,omitempty"`
+}
+
+// URL returns the dashboard url
+func (e Endpoint) URL() string {
+	return "http://" + e.Addr + e.BasePath
+}
+
+// Registrar publishes the Endpoint on Start and removes it on Stop
+type Registrar interface {
+	Register(ctx context.Context, e Endpoint) error
+	Deregister(ctx context.Context, e Endpoint) error
+}
+
+// doJSON sends v as the JSON body and decodes the response into out if it's not nil
+func doJSON(ctx context.Context, client *http.Client, method, url string, header http.Header, v, out interface{}) error {
+	var body io.Reader
+	if v != nil {
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(bs)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("registry: %s %s: %s %s", method, url, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
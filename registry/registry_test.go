@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// request is a request received by the fake catalog
+type request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// catalog records the requests and answers them with the JSON of the path
+func catalog(t *testing.T, answers map[string]string) (*httptest.Server, func() []request) {
+	var (
+		mu   sync.Mutex
+		reqs []request
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bs, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		json.Unmarshal(bs, &body)
+		mu.Lock()
+		reqs = append(reqs, request{Method: r.Method, Path: r.URL.Path, Header: r.Header, Body: body})
+		mu.Unlock()
+		if answer, ok := answers[r.URL.Path]; ok {
+			io.WriteString(w, answer)
+			return
+		}
+		io.WriteString(w, "{}")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]request(nil), reqs...)
+	}
+}
+
+func TestRegistrars(t *testing.T) {
+	e := Endpoint{Addr: "10.0.0.7:18066", BasePath: "/debug/statsview", AuthHint: "token"}
+
+	tests := []struct {
+		name       string
+		registrar  func(url string) Registrar
+		answers    map[string]string
+		register   []string
+		deregister []string
+		check      func(t *testing.T, reqs []request)
+	}{
+		{
+			name:       "consul",
+			registrar:  func(url string) Registrar { return NewConsul(url+"/", "api", "acl") },
+			register:   []string{"PUT /v1/agent/service/register"},
+			deregister: []string{"PUT /v1/agent/service/deregister/api-statsview-10.0.0.7:18066"},
+			check: func(t *testing.T, reqs []request) {
+				want := map[string]interface{}{
+					"ID":      "api-statsview-10.0.0.7:18066",
+					"Name":    "api-statsview",
+					"Address": "10.0.0.7",
+					"Port":    float64(18066),
+					"Tags":    []interface{}{"statsview"},
+					"Meta":    map[string]interface{}{"statsview_path": "/debug/statsview", "statsview_auth": "token"},
+				}
+				if !reflect.DeepEqual(reqs[0].Body, want) {
+					t.Errorf("service = %v, want %v", reqs[0].Body, want)
+				}
+				if got := reqs[0].Header.Get("X-Consul-Token"); got != "acl" {
+					t.Errorf("X-Consul-Token = %q, want acl", got)
+				}
+			},
+		},
+		{
+			name:       "etcd",
+			registrar:  func(url string) Registrar { return NewEtcd(url, "/statsview/api/", time.Minute) },
+			answers:    map[string]string{"/v3/lease/grant": `{"ID": "42"}`},
+			register:   []string{"POST /v3/lease/grant", "POST /v3/kv/put"},
+			deregister: []string{"POST /v3/lease/revoke"},
+			check: func(t *testing.T, reqs []request) {
+				if ttl := reqs[0].Body["TTL"]; ttl != float64(60) {
+					t.Errorf("TTL = %v, want 60", ttl)
+				}
+				value, _ := json.Marshal(e)
+				want := map[string]interface{}{"key": b64("/statsview/api/10.0.0.7:18066"), "value": b64(string(value)), "lease": "42"}
+				if !reflect.DeepEqual(reqs[1].Body, want) {
+					t.Errorf("put = %v, want %v", reqs[1].Body, want)
+				}
+				if id := reqs[2].Body["ID"]; id != "42" {
+					t.Errorf("revoked lease %v, want 42", id)
+				}
+			},
+		},
+		{
+			name: "kubernetes",
+			registrar: func(url string) Registrar {
+				return &Kubernetes{apiServer: url, namespace: "prod", pod: "api-0", token: "sa", client: http.DefaultClient}
+			},
+			register:   []string{"PATCH /api/v1/namespaces/prod/pods/api-0"},
+			deregister: []string{"PATCH /api/v1/namespaces/prod/pods/api-0"},
+			check: func(t *testing.T, reqs []request) {
+				want := map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]interface{}{
+					AnnotationAddr: "10.0.0.7:18066", AnnotationPath: "/debug/statsview", AnnotationAuth: "token",
+				}}}
+				if !reflect.DeepEqual(reqs[0].Body, want) {
+					t.Errorf("patch = %v, want %v", reqs[0].Body, want)
+				}
+				if got := reqs[0].Header.Get("Authorization"); got != "Bearer sa" {
+					t.Errorf("Authorization = %q, want Bearer sa", got)
+				}
+				removed := map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]interface{}{
+					AnnotationAddr: nil, AnnotationPath: nil, AnnotationAuth: nil,
+				}}}
+				if !reflect.DeepEqual(reqs[1].Body, removed) {
+					t.Errorf("patch = %v, want %v", reqs[1].Body, removed)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, reqs := catalog(t, tt.answers)
+			r := tt.registrar(srv.URL)
+			if err := r.Register(context.Background(), e); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Deregister(context.Background(), e); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, req := range reqs() {
+				got = append(got, req.Method+" "+req.Path)
+			}
+			if want := append(append([]string(nil), tt.register...), tt.deregister...); !reflect.DeepEqual(got, want) {
+				t.Fatalf("requests = %v, want %v", got, want)
+			}
+			tt.check(t, reqs())
+		})
+	}
+}
+
+func TestDoJSONError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "permission denied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	err := doJSON(context.Background(), srv.Client(), http.MethodPut, srv.URL+"/v1/agent/service/register", nil, map[string]string{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "403 Forbidden permission denied") {
+		t.Errorf("doJSON() = %v, want the status and the message", err)
+	}
+}
@@ -0,0 +1,102 @@
+package statsview
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// NewRemoteViewers returns viewers charting the Go runtime metrics of another
+// process from its expvar (`/debug/vars`) or Prometheus (`/metrics`) endpoint
+// at url, the format is guessed from the path
+//
+//	mgr, err := statsview.New(statsview.NewRemoteViewers("http://10.0.0.5:6060/debug/vars"))
+func NewRemoteViewers(url string) Viewers {
+	return newRemoteViewers("remote", url, viewer.FormatOf(url))
+}
+
+func newRemoteViewers(prefix, url string, format viewer.RemoteFormat) Viewers {
+	metric := func(expvar, prom string) string {
+		if format == viewer.FormatExpvar {
+			return expvar
+		}
+		return prom
+	}
+
+	viewers := Viewers{
+		viewer.NewRemoteViewer(prefix+"-heap", "Heap", url, format, viewer.UnitMiB,
+			viewer.RemoteSeries{Name: "Alloc", Metric: metric("memstats.HeapAlloc", "go_memstats_heap_alloc_bytes")},
+			viewer.RemoteSeries{Name: "Inuse", Metric: metric("memstats.HeapInuse", "go_memstats_heap_inuse_bytes")},
+			viewer.RemoteSeries{Name: "Sys", Metric: metric("memstats.HeapSys", "go_memstats_heap_sys_bytes")},
+			viewer.RemoteSeries{Name: "Idle", Metric: metric("memstats.HeapIdle", "go_memstats_heap_idle_bytes")},
+		),
+		viewer.NewRemoteViewer(prefix+"-stack", "Stack/MSpan/MCache", url, format, viewer.UnitMiB,
+			viewer.RemoteSeries{Name: "Stack", Metric: metric("memstats.StackInuse", "go_memstats_stack_inuse_bytes")},
+			viewer.RemoteSeries{Name: "MSpan", Metric: metric("memstats.MSpanInuse", "go_memstats_mspan_inuse_bytes")},
+			viewer.RemoteSeries{Name: "MCache", Metric: metric("memstats.MCacheInuse", "go_memstats_mcache_inuse_bytes")},
+		),
+		viewer.NewRemoteViewer(prefix+"-gcsize", "GC Size", url, format, viewer.UnitMiB,
+			viewer.RemoteSeries{Name: "GCSys", Metric: metric("memstats.GCSys", "go_memstats_gc_sys_bytes")},
+			viewer.RemoteSeries{Name: "NextGC", Metric: metric("memstats.NextGC", "go_memstats_next_gc_bytes")},
+		),
+	}
+	if format == viewer.FormatExpvar {
+		viewers.Register(viewer.NewRemoteViewer(prefix+"-gcnum", "GC Number", url, format, viewer.UnitNone,
+			viewer.RemoteSeries{Name: "GcNum", Metric: "memstats.NumGC"},
+		))
+	} else {
+		// expvar has no goroutine count, Prometheus' go collector has no MemStats.NumGC
+		viewers.Register(viewer.NewRemoteViewer(prefix+"-goroutine", "Goroutines", url, format, viewer.UnitNone,
+			viewer.RemoteSeries{Name: "Goroutines", Metric: "go_goroutines"},
+		), viewer.NewRemoteViewer(prefix+"-gcnum", "GC Number", url, format, viewer.UnitNone,
+			viewer.RemoteSeries{Name: "GcNum", Metric: "go_gc_duration_seconds_count"},
+		))
+	}
+	return viewers
+}
+
+// memoryViewers are the built-in viewers charted in MiB
+var memoryViewers = map[string]bool{viewer.VHeap: true, viewer.VCStack: true, viewer.VGCSize: true, viewer.VScavenge: true}
+
+// AttachRemote returns viewers charting the endpoint at url, their names
+// are prefixed by prefix so several targets could be charted together.
+// Expvar and Prometheus endpoints get the runtime viewers of
+// NewRemoteViewers, the viewers of another statsview (its
+// `/debug/statsview/snapshot`) are discovered and charted as they are
+func AttachRemote(prefix, url string) (Viewers, error) {
+	format := viewer.FormatOf(url)
+	if format != viewer.FormatStatsview {
+		return newRemoteViewers(prefix, url, format), nil
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("statsview: %s answered %s", url, resp.Status)
+	}
+	var s snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("statsview: invalid snapshot of %s: %w", url, err)
+	}
+
+	viewers := make(Viewers, 0, len(s.Viewers))
+	for _, v := range s.Viewers {
+		unit := viewer.UnitNone
+		if memoryViewers[v.Name] {
+			unit = viewer.UnitMiB
+		}
+		series := make([]viewer.RemoteSeries, 0, len(v.Series))
+		for _, name := range v.Series {
+			series = append(series, viewer.RemoteSeries{Name: name, Metric: v.Name + "." + name})
+		}
+		viewers.Register(viewer.NewRemoteViewer(prefix+"-"+v.Name, v.Title, url, format, unit, series...))
+	}
+	return viewers, nil
+}
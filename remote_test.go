@@ -0,0 +1,72 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestAttachRemote(t *testing.T) {
+	remote, err := New(Viewers{viewer.NewGoroutinesViewer(), viewer.NewHeapViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Stop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/statsview/snapshot", remote.serveSnapshot)
+	mux.HandleFunc("/broken/debug/statsview/snapshot", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"viewers":`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		want    []string
+		wantErr string
+	}{
+		{name: "statsview", url: srv.URL + "/debug/statsview/snapshot", want: []string{"api-goroutine", "api-heap"}},
+		{name: "not found", url: srv.URL + "/missing/debug/statsview/snapshot", wantErr: "404"},
+		{name: "invalid snapshot", url: srv.URL + "/broken/debug/statsview/snapshot", wantErr: "invalid snapshot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs, err := AttachRemote("api", tt.url)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AttachRemote() = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, v := range vs {
+				names = append(names, v.Name())
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("viewers = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// expvar and Prometheus targets get the runtime viewers without a request
+	for _, url := range []string{"http://127.0.0.1:1/debug/vars", "http://127.0.0.1:1/metrics"} {
+		vs, err := AttachRemote("worker", url)
+		if err != nil || len(vs) == 0 {
+			t.Fatalf("AttachRemote(%s) = %d viewers, %v", url, len(vs), err)
+		}
+		for _, v := range vs {
+			if !strings.HasPrefix(v.Name(), "worker-") {
+				t.Errorf("viewer %s isn't prefixed by the target", v.Name())
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mortum5/statsview/internal/chartimg"
+)
+
+// section is a viewer of the report with its chart
+type section struct {
+	Viewer string
+	Stats  []Stats
+	chart  chartimg.Chart
+}
+
+// page is what the report templates are rendered from
+type page struct {
+	Title    string
+	Start    string
+	End      string
+	Duration time.Duration
+	Sections []section
+}
+
+var htmlTpl = template.Must(template.New("report").Funcs(template.FuncMap{
+	"value": formatValue,
+	"svg":   svgChart,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style> body { font-family:sans-serif; margin:20px } table { border-collapse:collapse; margin-bottom:10px } th, td { border:1px solid #ddd; padding:4px 10px; text-align:right } th:first-child, td:first-child { text-align:left } </style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>{{ .Start }} &ndash; {{ .End }} ({{ .Duration }}), values in base units</p>
+{{- range .Sections }}
+<h2>{{ .Viewer }}</h2>
+<table>
+<tr><th>Series</th><th>Min</th><th>Max</th><th>Avg</th><th>P99</th><th>Last</th><th>Samples</th></tr>
+{{- range .Stats }}
+<tr><td>{{ .Series }}</td><td>{{ value .Min }}</td><td>{{ value .Max }}</td><td>{{ value .Avg }}</td><td>{{ value .P99 }}</td><td>{{ value .Last }}</td><td>{{ .Count }}</td></tr>
+{{- end }}
+</table>
+{{ svg . }}
+{{- end }}
+</body>
+</html>
+`))
+
+// render renders the report of the session, it's called with the lock held
+func (r *Reporter) render() ([]byte, error) {
+	p := page{Title: r.title}
+	if !r.start.IsZero() {
+		p.Start = r.start.Format(time.DateTime)
+		p.End = r.end.Format(time.DateTime)
+		p.Duration = r.end.Sub(r.start).Truncate(time.Second)
+	}
+
+	index := make(map[string]int)
+	for _, key := range r.order {
+		s := r.series[key]
+		i, ok := index[s.viewer]
+		if !ok {
+			i = len(p.Sections)
+			index[s.viewer] = i
+			p.Sections = append(p.Sections, section{Viewer: s.viewer, chart: chartimg.Chart{Title: s.viewer}})
+		}
+		sec := &p.Sections[i]
+		sec.Stats = append(sec.Stats, s.stats())
+		cs := chartimg.Series{Name: s.name, Points: make([]chartimg.Point, len(s.points))}
+		for j, pt := range s.points {
+			cs.Points[j] = chartimg.Point{Time: pt.Time, Value: pt.Value}
+		}
+		sec.chart.Series = append(sec.chart.Series, cs)
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if r.format == FormatMarkdown {
+		err = renderMarkdown(&buf, p)
+	} else {
+		err = htmlTpl.Execute(&buf, p)
+	}
+	return buf.Bytes(), err
+}
+
+// svgChart inlines the chart of the section in the HTML report
+func svgChart(s section) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := chartimg.SVG(&buf, s.chart); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// renderMarkdown writes the report as Markdown, the charts are embedded as
+// PNG data URIs so the report is a single file
+func renderMarkdown(buf *bytes.Buffer, p page) error {
+	fmt.Fprintf(buf, "# %s\n\n", p.Title)
+	fmt.Fprintf(buf, "%s – %s (%s), values in base units\n", p.Start, p.End, p.Duration)
+	for _, s := range p.Sections {
+		fmt.Fprintf(buf, "\n## %s\n\n", s.Viewer)
+		buf.WriteString("| Series | Min | Max | Avg | P99 | Last | Samples |\n")
+		buf.WriteString("|:--|--:|--:|--:|--:|--:|--:|\n")
+		for _, st := range s.Stats {
+			fmt.Fprintf(buf, "| %s | %s | %s | %s | %s | %s | %d |\n", strings.ReplaceAll(st.Series, "|", `\|`),
+				formatValue(st.Min), formatValue(st.Max), formatValue(st.Avg), formatValue(st.P99), formatValue(st.Last), st.Count)
+		}
+
+		var img bytes.Buffer
+		if err := chartimg.PNG(&img, s.chart); err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "\n![%s](data:image/png;base64,%s)\n", s.Viewer, base64.StdEncoding.EncodeToString(img.Bytes()))
+	}
+	return nil
+}
+
+// formatValue keeps 4 significant digits
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
@@ -0,0 +1,230 @@
+// Package report summarizes the collected metrics of a session, the min, max,
+// average and 99th percentile of every series with a chart per viewer, into
+// HTML or Markdown reports, e.g. the artifacts of a load test.
+//
+// The Reporter is an exporter, it's registered with `ViewManager.AddExporter`
+// and writes a report periodically and a last one when the manager stops
+package report
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+)
+
+// MaxPoints bounds the points kept per series, every other point is dropped
+// when it's reached so the kept ones stay evenly spread over the session.
+// The percentile is computed on the kept points
+const MaxPoints = 1024
+
+// Format is the format of the reports
+type Format string
+
+const (
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "md"
+)
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == FormatMarkdown {
+		return "text/markdown; charset=utf-8"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// Stats summarizes a series over the session, values are in base units
+type Stats struct {
+	Viewer string
+	Series string
+	Count  int
+	Min    float64
+	Max    float64
+	Avg    float64
+	P99    float64
+	Last   float64
+}
+
+// point is a kept value of a series
+type point struct {
+	Time  time.Time
+	Value float64
+}
+
+// series accumulates a series, the stats are exact but the points are
+// decimated beyond MaxPoints
+type series struct {
+	viewer, name string
+
+	count, seen int
+	sum         float64
+	min, max    float64
+	last        float64
+	stride      int
+	points      []point
+}
+
+func (s *series) add(t time.Time, v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	if s.count == 0 || v < s.min {
+		s.min = v
+	}
+	if s.count == 0 || v > s.max {
+		s.max = v
+	}
+	s.count++
+	s.sum += v
+	s.last = v
+
+	if s.seen%s.stride == 0 {
+		s.points = append(s.points, point{Time: t, Value: v})
+		if len(s.points) >= MaxPoints {
+			kept := s.points[:0]
+			for i := 0; i < len(s.points); i += 2 {
+				kept = append(kept, s.points[i])
+			}
+			s.points = kept
+			s.stride *= 2
+		}
+	}
+	s.seen++
+}
+
+func (s *series) stats() Stats {
+	st := Stats{Viewer: s.viewer, Series: s.name, Count: s.count, Min: s.min, Max: s.max, Last: s.last}
+	if s.count == 0 {
+		return st
+	}
+	st.Avg = s.sum / float64(s.count)
+
+	values := make([]float64, len(s.points))
+	for i, p := range s.points {
+		values[i] = p.Value
+	}
+	sort.Float64s(values)
+	st.P99 = values[int(math.Ceil(0.99*float64(len(values))))-1]
+	return st
+}
+
+// Reporter accumulates the samples of the session and writes the reports
+// into a Sink
+type Reporter struct {
+	mu      sync.Mutex
+	sink    Sink
+	format  Format
+	every   time.Duration
+	title   string
+	start   time.Time
+	end     time.Time
+	written time.Time
+	order   []string
+	series  map[string]*series
+}
+
+// Option customizes a Reporter
+type Option func(*Reporter)
+
+// WithEvery writes a report every d in addition to the one written when the
+// manager stops, each report covers the whole session so far
+func WithEvery(d time.Duration) Option {
+	return func(r *Reporter) {
+		r.every = d
+	}
+}
+
+// WithFormat sets the format of the reports, FormatHTML by default
+func WithFormat(f Format) Option {
+	return func(r *Reporter) {
+		r.format = f
+	}
+}
+
+// WithTitle sets the title of the reports, e.g. the name of the load test
+func WithTitle(title string) Option {
+	return func(r *Reporter) {
+		r.title = title
+	}
+}
+
+// New returns the Reporter writing into sink
+func New(sink Sink, options ...Option) *Reporter {
+	r := &Reporter{
+		sink:   sink,
+		format: FormatHTML,
+		title:  "Statsview report",
+		series: make(map[string]*series),
+	}
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
+}
+
+// Export accumulates the samples and writes the periodic report when it's due
+func (r *Reporter) Export(ctx context.Context, samples []exporter.Sample) error {
+	r.mu.Lock()
+	now := time.Now()
+	if r.start.IsZero() {
+		r.start, r.written = now, now
+	}
+	for _, smp := range samples {
+		key := smp.Name
+		s, ok := r.series[key]
+		if !ok {
+			s = &series{viewer: smp.Labels["viewer"], name: smp.Labels["series"], stride: 1}
+			if s.viewer == "" || s.name == "" {
+				s.viewer, s.name = smp.Name, smp.Name
+			}
+			r.series[key] = s
+			r.order = append(r.order, key)
+		}
+		s.add(smp.Time, smp.Value)
+		if smp.Time.After(r.end) {
+			r.end = smp.Time
+		}
+	}
+	due := r.every > 0 && now.Sub(r.written) >= r.every
+	r.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return r.Write(ctx)
+}
+
+// Flush writes the report of the session
+func (r *Reporter) Flush(ctx context.Context) error {
+	return r.Write(ctx)
+}
+
+// Write renders the report of the session so far and delivers it to the sink
+func (r *Reporter) Write(ctx context.Context) error {
+	r.mu.Lock()
+	now := time.Now()
+	r.written = now
+	name := "statsview-report-" + now.Format("20060102-150405") + "." + string(r.format)
+	body, err := r.render()
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return r.sink.Write(ctx, name, r.format.ContentType(), body)
+}
+
+// Stats returns the summary of every series in the order they were first seen
+func (r *Reporter) Stats() []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]Stats, 0, len(r.order))
+	for _, key := range r.order {
+		stats = append(stats, r.series[key].stats())
+	}
+	return stats
+}
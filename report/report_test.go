@@ -0,0 +1,161 @@
+package report
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/exporter"
+)
+
+// memSink keeps the reports written into it
+type memSink struct {
+	names, contentTypes []string
+	bodies              []string
+}
+
+func (s *memSink) Write(_ context.Context, name, contentType string, body []byte) error {
+	s.names = append(s.names, name)
+	s.contentTypes = append(s.contentTypes, contentType)
+	s.bodies = append(s.bodies, string(body))
+	return nil
+}
+
+func samplesOf(viewer, name string, t0 time.Time, values ...float64) []exporter.Sample {
+	samples := make([]exporter.Sample, len(values))
+	for i, v := range values {
+		samples[i] = exporter.Sample{
+			Name:   "statsview_" + viewer + "_" + strings.ToLower(name),
+			Labels: map[string]string{"viewer": viewer, "series": name},
+			Value:  v,
+			Time:   t0.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return samples
+}
+
+func TestSeriesStats(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	hundred := make([]float64, 100)
+	for i := range hundred {
+		hundred[i] = float64(i + 1)
+	}
+	tests := []struct {
+		name   string
+		values []float64
+		want   Stats
+	}{
+		{"no sample", nil, Stats{Viewer: "heap", Series: "Alloc"}},
+		{"one sample", []float64{5}, Stats{Viewer: "heap", Series: "Alloc", Count: 1, Min: 5, Max: 5, Avg: 5, P99: 5, Last: 5}},
+		{"several", []float64{4, -2, 10, 8}, Stats{Viewer: "heap", Series: "Alloc", Count: 4, Min: -2, Max: 10, Avg: 5, P99: 10, Last: 8}},
+		{"percentile", hundred, Stats{Viewer: "heap", Series: "Alloc", Count: 100, Min: 1, Max: 100, Avg: 50.5, P99: 99, Last: 100}},
+		{"invalid values skipped", []float64{1, math.NaN(), 3}, Stats{Viewer: "heap", Series: "Alloc", Count: 2, Min: 1, Max: 3, Avg: 2, P99: 3, Last: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(&memSink{})
+			r.Export(context.Background(), samplesOf("heap", "Alloc", t0, tt.values...))
+			stats := r.Stats()
+			if len(tt.values) == 0 {
+				if len(stats) != 0 {
+					t.Errorf("Stats() = %+v, want none", stats)
+				}
+				return
+			}
+			if stats[0] != tt.want {
+				t.Errorf("Stats() = %+v, want %+v", stats[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestSeriesDecimation(t *testing.T) {
+	s := &series{stride: 1}
+	t0 := time.Unix(1700000000, 0)
+	for i := 0; i < 3*MaxPoints; i++ {
+		s.add(t0.Add(time.Duration(i)*time.Second), float64(i))
+	}
+	if len(s.points) >= MaxPoints {
+		t.Errorf("kept %d points, want less than %d", len(s.points), MaxPoints)
+	}
+	for i := 1; i < len(s.points); i++ {
+		if gap := s.points[i].Value - s.points[i-1].Value; gap != float64(s.stride) {
+			t.Fatalf("points %d and %d are %v apart, want the stride %d", i-1, i, gap, s.stride)
+		}
+	}
+	if st := s.stats(); st.Count != 3*MaxPoints || st.Max != 3*MaxPoints-1 {
+		t.Errorf("the stats of the decimated series are %+v", st)
+	}
+}
+
+func TestReporterWrite(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	tests := []struct {
+		name        string
+		format      Format
+		contentType string
+		want        []string
+	}{
+		{"html", FormatHTML, "text/html; charset=utf-8", []string{"<h1>Load test</h1>", "<h2>heap</h2>", "<td>Alloc</td>", "<td>Inuse</td>", "<h2>goroutine</h2>", "<svg"}},
+		{"markdown", FormatMarkdown, "text/markdown; charset=utf-8", []string{"# Load test\n", "## heap\n", "| Alloc | 1 | 3 | 2 | 3 | 3 | 3 |", "| A\\|B |", "![heap](data:image/png;base64,"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &memSink{}
+			r := New(sink, WithFormat(tt.format), WithTitle("Load test"))
+			samples := append(samplesOf("heap", "Alloc", t0, 1, 2, 3), samplesOf("heap", "Inuse", t0, 4)...)
+			samples = append(samples, samplesOf("goroutine", "A|B", t0, 7)...)
+			if err := r.Export(context.Background(), samples); err != nil {
+				t.Fatal(err)
+			}
+			if len(sink.names) != 0 {
+				t.Fatalf("wrote %d reports before the flush", len(sink.names))
+			}
+			if err := r.Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if len(sink.names) != 1 {
+				t.Fatalf("wrote %d reports, want 1", len(sink.names))
+			}
+			if !strings.HasPrefix(sink.names[0], "statsview-report-") || !strings.HasSuffix(sink.names[0], "."+string(tt.format)) {
+				t.Errorf("name = %s", sink.names[0])
+			}
+			if sink.contentTypes[0] != tt.contentType {
+				t.Errorf("content type = %s, want %s", sink.contentTypes[0], tt.contentType)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(sink.bodies[0], s) {
+					t.Errorf("report doesn't contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestReporterEvery(t *testing.T) {
+	tests := []struct {
+		name  string
+		every time.Duration
+		want  int
+	}{
+		{"only on flush", 0, 0},
+		{"not due yet", time.Hour, 0},
+		{"due", time.Nanosecond, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &memSink{}
+			r := New(sink, WithEvery(tt.every))
+			r.Export(context.Background(), samplesOf("heap", "Alloc", time.Now(), 1))
+			time.Sleep(time.Millisecond)
+			if err := r.Export(context.Background(), samplesOf("heap", "Alloc", time.Now(), 2)); err != nil {
+				t.Fatal(err)
+			}
+			if len(sink.names) != tt.want {
+				t.Errorf("wrote %d reports, want %d", len(sink.names), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Sink receives the rendered reports
+type Sink interface {
+	Write(ctx context.Context, name, contentType string, body []byte) error
+}
+
+// DirSink writes every report as a file into a directory
+type DirSink struct {
+	dir string
+}
+
+// Dir returns the Sink writing the reports into dir, which is created when
+// the first report is written
+func Dir(dir string) *DirSink {
+	return &DirSink{dir: dir}
+}
+
+func (s *DirSink) Write(_ context.Context, name, _ string, body []byte) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	// written aside and renamed so a reader never sees a partial report
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path+".tmp", body, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// WebhookSink posts every report to an URL
+type WebhookSink struct {
+	url string
+
+	// Header is added to every request, e.g. `Authorization: Bearer ...`
+	Header http.Header
+	// Client sends the requests
+	Client *http.Client
+}
+
+// Webhook returns the Sink posting the reports to url, the name of the
+// report is sent in the `X-Statsview-Report` header
+func Webhook(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		Header: make(http.Header),
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *WebhookSink) Write(ctx context.Context, name, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range s.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Statsview-Report", name)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("statsview: report webhook %s answered %s: %s", s.url, resp.Status, bytes.TrimSpace(msg))
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirSink(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	sink := Dir(dir)
+	for _, body := range []string{"first", "second"} {
+		if err := sink.Write(context.Background(), "report.html", "text/html", []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "report.html" {
+		t.Fatalf("the directory holds %v, want only report.html", entries)
+	}
+	if bs, _ := os.ReadFile(filepath.Join(dir, "report.html")); string(bs) != "second" {
+		t.Errorf("report = %q, want the last one", bs)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr string
+	}{
+		{"ok", http.StatusOK, ""},
+		{"accepted", http.StatusAccepted, ""},
+		{"rejected", http.StatusForbidden, "403 Forbidden: denied"},
+		{"failed", http.StatusBadGateway, "502 Bad Gateway: denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bs, _ := io.ReadAll(r.Body)
+				got, body = r, string(bs)
+				w.WriteHeader(tt.status)
+				io.WriteString(w, "denied\n")
+			}))
+			defer srv.Close()
+
+			sink := Webhook(srv.URL)
+			sink.Header.Set("Authorization", "Bearer s3cret")
+			err := sink.Write(context.Background(), "report.md", FormatMarkdown.ContentType(), []byte("# Report"))
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Write() = %v, want %q", err, tt.wantErr)
+			}
+			if got.Method != http.MethodPost || body != "# Report" {
+				t.Errorf("got %s %q", got.Method, body)
+			}
+			for k, want := range map[string]string{
+				"Authorization":      "Bearer s3cret",
+				"Content-Type":       "text/markdown; charset=utf-8",
+				"X-Statsview-Report": "report.md",
+			} {
+				if v := got.Header.Get(k); v != want {
+					t.Errorf("%s = %q, want %q", k, v, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package statsview
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// skipped reports whether the viewer is not collected at the moment
+func (vm *ViewManager) skipped(v viewer.Viewer) bool {
+	return vm.Smgr.Degraded() && viewer.PriorityOf(v) < viewer.PriorityNormal
+}
+
+// serveView serves the viewer metrics unless the viewer is skipped because
+// of the degraded collection, which is answered with 204 No Content
+func (vm *ViewManager) serveView(v viewer.Viewer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if vm.skipped(v) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		resp := vm.safeServe(v, r)
+		if names := selectedSeries(r); names != nil && resp.Status() == http.StatusOK {
+			if err := filterSeries(v, resp, names); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		writeBuffered(w, r, resp)
+	}
+}
+
+// collectionStatus is the state of the collection reported by `status`
+type collectionStatus struct {
+	Degraded   bool    `json:"degraded"`
+	CPU        float64 `json:"cpu"`
+	GC         float64 `json:"gc"`
+	Interval   int     `json:"interval"`
+	Collecting bool    `json:"collecting"`
+	Clients    int     `json:"clients"`
+}
+
+func (vm *ViewManager) status(w http.ResponseWriter, r *http.Request) {
+	writeData(w, r, collectionStatus{
+		Degraded:   vm.Smgr.Degraded(),
+		CPU:        vm.Smgr.CPUUsage(),
+		GC:         vm.Smgr.GCPressure(),
+		Interval:   vm.Smgr.CurrentInterval(),
+		Collecting: vm.Smgr.Collecting(),
+		Clients:    vm.Smgr.Clients(),
+	})
+}
+
+// annotatePressure marks on the charts when the collection is degraded and
+// restored, the interval changes there
+func (vm *ViewManager) annotatePressure(degraded bool, reason string) {
+	if degraded {
+		vm.Annotate(fmt.Sprintf("Collection degraded (%s), interval %dms", reason, vm.Smgr.CurrentInterval()))
+		return
+	}
+	vm.Annotate(fmt.Sprintf("Collection restored, interval %dms", vm.Smgr.CurrentInterval()))
+}
@@ -0,0 +1,118 @@
+package statsview
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mortum5/statsview/viewer"
+	"github.com/rs/cors"
+)
+
+// nonceKey is the context key of the nonce of the request
+type nonceKey struct{}
+
+// defaultSecurityHeaders are the headers set on every response unless
+// overridden by viewer.WithSecurityHeaders. The pages load their assets and
+// their data from their own origin, under the path they're served on
+func defaultSecurityHeaders() map[string]string {
+	return map[string]string{
+		"Content-Security-Policy": "default-src 'self'; " +
+			"script-src 'self' 'nonce-{nonce}'; " +
+			"style-src 'self' 'unsafe-inline'; " +
+			"img-src 'self' data: blob:; " +
+			"connect-src 'self'; " +
+			"frame-ancestors 'none'; base-uri 'self'; form-action 'self'; object-src 'none'",
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        "DENY",
+		"Referrer-Policy":        "no-referrer",
+	}
+}
+
+// securityHeadersOf returns the default headers with the configured ones
+func securityHeadersOf() map[string]string {
+	headers := defaultSecurityHeaders()
+	for name, value := range viewer.SecurityHeaders() {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	return headers
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// nonceOf returns the nonce of the inline scripts of the request
+func nonceOf(r *http.Request) string {
+	nonce, _ := r.Context().Value(nonceKey{}).(string)
+	return nonce
+}
+
+// securityHeaders sets the security headers on every response, the nonce
+// allowing the inline scripts of the pages is drawn for every request
+func securityHeaders(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce := newNonce()
+		for name, value := range securityHeadersOf() {
+			if value != "" {
+				w.Header().Set(name, strings.ReplaceAll(value, "{nonce}", nonce))
+			}
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), nonceKey{}, nonce)))
+	})
+}
+
+// crossOrigin lets the pages of any origin read the routes. The admin routes
+// are only readable cross-origin, the preflights of their POSTs and PUTs are
+// refused so no other site may send them the headers of sameOrigin
+func (vm *ViewManager) crossOrigin(h http.Handler) http.Handler {
+	shared := cors.AllowAll().Handler(h)
+	readOnly := cors.New(cors.Options{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodHead},
+		AllowedHeaders: []string{"*"},
+	}).Handler(h)
+
+	admin := make(map[string]bool)
+	for _, rt := range vm.apiRoutes() {
+		if rt.Admin {
+			admin[APIPrefix+rt.Path] = true
+			if rt.Legacy != "" {
+				admin[rt.Legacy] = true
+			}
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if admin[r.URL.Path] {
+			readOnly.ServeHTTP(w, r)
+			return
+		}
+		shared.ServeHTTP(w, r)
+	})
+}
+
+// withNonce adds nonce to the scripts of html
+func withNonce(html []byte, nonce string) []byte {
+	if nonce == "" {
+		return html
+	}
+	return bytes.ReplaceAll(html, []byte("<script"), []byte(`<script nonce="`+nonce+`"`))
+}
+
+// writeHTML writes the page rendered by render, its scripts carry the nonce
+// of the request
+func writeHTML(w http.ResponseWriter, r *http.Request, render func(io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := w.Write(withNonce(buf.Bytes(), nonceOf(r)))
+	return err
+}
@@ -0,0 +1,122 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestWithNonce(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		nonce string
+		want  string
+	}{
+		{"no nonce", `<script src="a.js"></script>`, "", `<script src="a.js"></script>`},
+		{"every script", `<script src="a.js"></script><script>run()</script>`, "n0",
+			`<script nonce="n0" src="a.js"></script><script nonce="n0">run()</script>`},
+		{"no script", `<div></div>`, "n0", `<div></div>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(withNonce([]byte(tt.html), tt.nonce)); got != tt.want {
+				t.Errorf("withNonce() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithSecurityHeaders(nil))
+
+	nonceOfCSP := regexp.MustCompile(`'nonce-([^']+)'`)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		path    string
+		want    map[string]string
+		absent  []string
+	}{
+		{
+			name: "defaults",
+			path: "/debug/statsview",
+			want: map[string]string{"X-Content-Type-Options": "nosniff", "X-Frame-Options": "DENY", "Referrer-Policy": "no-referrer"},
+		},
+		{
+			name: "data endpoints too",
+			path: "/debug/statsview/api/v1/status",
+			want: map[string]string{"X-Frame-Options": "DENY"},
+		},
+		{
+			name:    "overridden and dropped",
+			headers: map[string]string{"x-frame-options": "SAMEORIGIN", "Referrer-Policy": ""},
+			path:    "/debug/statsview",
+			want:    map[string]string{"X-Frame-Options": "SAMEORIGIN"},
+			absent:  []string{"Referrer-Policy"},
+		},
+		{
+			name:    "custom CSP with the nonce",
+			headers: map[string]string{"Content-Security-Policy": "script-src https://cdn.example.com 'nonce-{nonce}'"},
+			path:    "/debug/statsview",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithSecurityHeaders(tt.headers))
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			for name, value := range tt.want {
+				if got := rec.Header().Get(name); got != value {
+					t.Errorf("%s = %q, want %q", name, got, value)
+				}
+			}
+			for _, name := range tt.absent {
+				if _, ok := rec.Header()[name]; ok {
+					t.Errorf("%s is set", name)
+				}
+			}
+
+			m := nonceOfCSP.FindStringSubmatch(rec.Header().Get("Content-Security-Policy"))
+			if m == nil {
+				t.Fatalf("no nonce in the CSP %q", rec.Header().Get("Content-Security-Policy"))
+			}
+			if tt.path != "/debug/statsview" {
+				return
+			}
+			body := rec.Body.String()
+			if n, scripts := strings.Count(body, `<script nonce="`+m[1]+`"`), strings.Count(body, "<script"); n == 0 || n != scripts {
+				t.Errorf("%d of the %d scripts carry the nonce", n, scripts)
+			}
+		})
+	}
+
+	t.Run("nonce per request", func(t *testing.T) {
+		viewer.SetConfiguration(viewer.WithSecurityHeaders(nil))
+		mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer mgr.Stop()
+
+		seen := make(map[string]bool)
+		for i := 0; i < 3; i++ {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/goroutines", nil))
+			seen[nonceOfCSP.FindStringSubmatch(rec.Header().Get("Content-Security-Policy"))[1]] = true
+		}
+		if len(seen) != 3 {
+			t.Errorf("%d distinct nonces of 3 requests", len(seen))
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package statsview
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// selectedSeries returns the series kept by the `series` parameter, repeated
+// or comma separated, e.g. `?series=Alloc,Inuse`, nil when every series is
+func selectedSeries(r *http.Request) []string {
+	var names []string
+	for _, s := range r.URL.Query()["series"] {
+		for _, name := range strings.Split(s, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// seriesIndexes returns the indexes of the named series of the viewer in the
+// order of its chart, the names are case insensitive
+func seriesIndexes(v viewer.Viewer, names []string) ([]int, []string, error) {
+	series := viewer.MetaOf(v).Series
+	var (
+		indexes  []int
+		selected []string
+	)
+	for i, s := range series {
+		for _, name := range names {
+			if strings.EqualFold(name, s.Name) {
+				indexes = append(indexes, i)
+				selected = append(selected, s.Name)
+				break
+			}
+		}
+	}
+	for _, name := range names {
+		found := false
+		for _, s := range selected {
+			if strings.EqualFold(name, s) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("statsview: unknown series %q of viewer %s", name, v.Name())
+		}
+	}
+	return indexes, selected, nil
+}
+
+// filterSeries keeps the values of the selected series in the metrics served
+// by the viewer, the other fields are kept as they are and `series` names
+// the values left
+func filterSeries(v viewer.Viewer, resp *viewer.ResponseBuffer, names []string) error {
+	indexes, selected, err := seriesIndexes(v, names)
+	if err != nil {
+		return err
+	}
+	errFilter := fmt.Errorf("statsview: the series of viewer %s couldn't be filtered", v.Name())
+	keep := func(values []float64) ([]float64, bool) {
+		if len(values) != len(viewer.MetaOf(v).Series) {
+			return nil, false
+		}
+		kept := make([]float64, 0, len(indexes))
+		for _, i := range indexes {
+			kept = append(kept, values[i])
+		}
+		return kept, true
+	}
+
+	value, _ := resp.Value()
+	if m, ok := value.(*viewer.Metrics); ok && m != nil {
+		value = *m
+	}
+	if m, ok := value.(viewer.Metrics); ok {
+		kept, ok := keep(m.Values)
+		if !ok {
+			return errFilter
+		}
+		m.Values, m.Series = kept, selected
+		resp.SetValue(m)
+		return nil
+	}
+
+	// a viewer writing its own body, or a value of another type
+	body, err := resp.JSON()
+	if err != nil {
+		return errFilter
+	}
+	var fields map[string]interface{}
+	var m viewer.Metrics
+	if json.Unmarshal(body, &fields) != nil || json.Unmarshal(body, &m) != nil {
+		return errFilter
+	}
+	kept, ok := keep(m.Values)
+	if !ok {
+		return errFilter
+	}
+	fields["values"], fields["series"] = kept, selected
+	resp.SetValue(fields)
+	return nil
+}
@@ -0,0 +1,122 @@
+package statsview
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestSelectedSeries(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"?series=", nil},
+		{"?series=Alloc", []string{"Alloc"}},
+		{"?series=Alloc,%20Inuse,", []string{"Alloc", "Inuse"}},
+		{"?series=Alloc&series=Sys", []string{"Alloc", "Sys"}},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/debug/statsview/view/heap"+tt.query, nil)
+		if got := selectedSeries(r); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("selectedSeries(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestServeViewSeries(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewHeapViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		series []string
+		values int
+	}{
+		{name: "every series", status: http.StatusOK, values: 5},
+		{name: "in the order of the chart", query: "?series=inuse,Alloc", status: http.StatusOK, series: []string{"Alloc", "Inuse"}, values: 2},
+		{name: "repeated", query: "?series=Sys&series=NextGC", status: http.StatusOK, series: []string{"Sys", "NextGC"}, values: 2},
+		{name: "unknown series", query: "?series=Alloc,Heap", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/view/heap"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var m viewer.Metrics
+			if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Series, tt.series) || len(m.Values) != tt.values || m.Time == "" {
+				t.Errorf("metrics %+v, want the values of %v", m, tt.series)
+			}
+		})
+	}
+}
+
+func TestServeHistorySeries(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithHistory(0))
+	viewer.SetConfiguration(viewer.WithHistory(time.Hour))
+	mgr, err := New(Viewers{viewer.NewHeapViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	now := mgr.Smgr.Now()
+	for _, s := range []string{"Alloc", "Inuse", "Sys"} {
+		mgr.history.record([]viewer.Point{{Viewer: viewer.VHeap, Series: s, Value: 1, Time: now.Add(-time.Minute)}})
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   []string
+	}{
+		{name: "every series", status: http.StatusOK, want: []string{"Alloc", "Inuse", "Sys"}},
+		{name: "selected", query: "?series=alloc,Sys", status: http.StatusOK, want: []string{"Alloc", "Sys"}},
+		{name: "not recorded", query: "?series=NextGC", status: http.StatusOK, want: []string{}},
+		{name: "unknown series", query: "?series=Heap", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/history/heap"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var res viewerHistory
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, s := range res.Series {
+				got = append(got, s.Series)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("series %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
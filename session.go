@@ -0,0 +1,267 @@
+package statsview
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// sessionVersion is the version of the session file format, Open refuses
+// the files of a later version
+const sessionVersion = 1
+
+// session is the portable file of a recorded session: gzipped JSON of the
+// process, its viewers and their points
+type session struct {
+	Version  int              `json:"version"`
+	Recorded time.Time        `json:"recorded"`
+	Interval int              `json:"interval"`
+	Build    buildInfo        `json:"build"`
+	Viewers  []viewer.Meta    `json:"viewers"`
+	Samples  []sessionSamples `json:"samples"`
+}
+
+// sessionSamples are the points of a series in base units, the times are
+// unix milliseconds
+type sessionSamples struct {
+	Viewer string    `json:"viewer"`
+	Series string    `json:"series"`
+	Times  []int64   `json:"times"`
+	Values []float64 `json:"values"`
+}
+
+// errHistoryDisabled is returned by Export without history
+var errHistoryDisabled = errors.New("statsview: the history is disabled, see viewer.WithHistory")
+
+// Export writes the history recorded with viewer.WithHistory as a session
+// file, which Open replays elsewhere, e.g. a recording captured on a
+// production box opened locally. NaN and infinite values are left out
+func (vm *ViewManager) Export(w io.Writer) error {
+	if vm.historyWindow() <= 0 {
+		return errHistoryDisabled
+	}
+
+	now := vm.Smgr.Now()
+	s := session{
+		Version:  sessionVersion,
+		Recorded: now,
+		Interval: vm.Smgr.CurrentInterval(),
+		Build:    readBuildInfo(),
+		Viewers:  []viewer.Meta{},
+		Samples:  []sessionSamples{},
+	}
+	recorded := make(map[string]bool)
+	for _, k := range vm.history.keys() {
+		samples := sessionSamples{Viewer: k.Viewer, Series: k.Series}
+		for _, p := range vm.history.query(k, time.Time{}, now) {
+			if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+				continue
+			}
+			samples.Times = append(samples.Times, p.Time.UnixMilli())
+			samples.Values = append(samples.Values, p.Value)
+		}
+		if len(samples.Times) > 0 {
+			s.Samples = append(s.Samples, samples)
+			recorded[k.Viewer] = true
+		}
+	}
+	for _, v := range vm.views() {
+		if recorded[v.Name()] {
+			s.Viewers = append(s.Viewers, viewer.MetaOf(v))
+		}
+	}
+
+	gz := gzip.NewWriter(w)
+	if err := json.NewEncoder(gz).Encode(s); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// exportSession downloads the session file of the history
+func (vm *ViewManager) exportSession(w http.ResponseWriter, r *http.Request) {
+	if vm.historyWindow() <= 0 {
+		http.Error(w, errHistoryDisabled.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="statsview-%s.session"`, time.Now().Format("20060102-150405")))
+	if err := vm.Export(w); err != nil {
+		viewer.Logger().Error("statsview: failed to export the session", "err", err)
+	}
+}
+
+// Open returns a ViewManager replaying the session file written by Export:
+// the charts play the recorded points at the pace they were recorded and
+// the history endpoints serve the whole session
+//
+//	mgr, err := statsview.Open("prod.session")
+//	if err != nil { ... }
+//	mgr.Start()
+func Open(path string) (*ViewManager, error) {
+	return openSessions(path, "")
+}
+
+// Compare returns a ViewManager replaying the session file at path like
+// Open, with the session file at other overlaid to compare them, e.g. before
+// and after a code change. The series of other are drawn dashed and named
+// after its file, e.g. `Alloc (after)`, they start with the ones of path.
+// The viewers recorded in other only are left out
+//
+//	mgr, err := statsview.Compare("before.session", "after.session")
+func Compare(path, other string) (*ViewManager, error) {
+	return openSessions(path, other)
+}
+
+// replayed is a session file loaded for a replay, its series by viewer
+type replayed struct {
+	session
+	series      map[string][]viewer.ReplaySeries
+	first, last time.Time
+}
+
+// loadSession reads the session file at path for a replay
+func loadSession(path string) (replayed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return replayed{}, err
+	}
+	defer f.Close()
+
+	s, err := readSession(f)
+	if err != nil {
+		return replayed{}, fmt.Errorf("statsview: invalid session file %s: %w", path, err)
+	}
+	if len(s.Samples) == 0 {
+		return replayed{}, fmt.Errorf("statsview: session file %s has no points", path)
+	}
+
+	dims := make(map[seriesKey]viewer.Dimension)
+	for _, m := range s.Viewers {
+		for _, sm := range m.Series {
+			dims[seriesKey{Viewer: m.Name, Series: sm.Name}] = sm.Dimension
+		}
+	}
+	r := replayed{session: s, series: make(map[string][]viewer.ReplaySeries)}
+	for _, samples := range s.Samples {
+		rs := viewer.ReplaySeries{
+			Name:      samples.Series,
+			Dimension: dims[seriesKey{Viewer: samples.Viewer, Series: samples.Series}],
+			Times:     make([]time.Time, len(samples.Times)),
+			Values:    samples.Values,
+		}
+		for i, ms := range samples.Times {
+			rs.Times[i] = time.UnixMilli(ms)
+		}
+		if r.first.IsZero() || rs.Times[0].Before(r.first) {
+			r.first = rs.Times[0]
+		}
+		if end := rs.Times[len(rs.Times)-1]; end.After(r.last) {
+			r.last = end
+		}
+		r.series[samples.Viewer] = append(r.series[samples.Viewer], rs)
+	}
+	return r, nil
+}
+
+// openSessions returns the ViewManager replaying the session file at path,
+// with the one at other overlaid unless it's empty
+func openSessions(path, other string) (*ViewManager, error) {
+	base, err := loadSession(path)
+	if err != nil {
+		return nil, err
+	}
+	if other != "" {
+		o, err := loadSession(other)
+		if err != nil {
+			return nil, err
+		}
+		label := strings.TrimSuffix(filepath.Base(other), filepath.Ext(other))
+		shift := base.first.Sub(o.first)
+		for name, series := range o.series {
+			for _, rs := range series {
+				times := make([]time.Time, len(rs.Times))
+				for i, t := range rs.Times {
+					times[i] = t.Add(shift)
+				}
+				rs.Name, rs.Times, rs.Dashed = rs.Name+" ("+label+")", times, true
+				base.series[name] = append(base.series[name], rs)
+			}
+		}
+		if end := o.last.Add(shift); end.After(base.last) {
+			base.last = end
+		}
+	}
+
+	h := newHistory(base.last.Sub(base.first), 0)
+	viewers := make(Viewers, 0, len(base.Viewers))
+	for _, m := range base.Viewers {
+		series := base.series[m.Name]
+		if len(series) == 0 {
+			continue
+		}
+		for _, rs := range series {
+			points := make([]historyPoint, len(rs.Times))
+			for i, t := range rs.Times {
+				points[i] = historyPoint{Time: t, Value: rs.Values[i]}
+			}
+			h.series[seriesKey{Viewer: m.Name, Series: rs.Name}] = points
+		}
+		vr := viewer.NewReplayViewer(m.Name, m.Title, m.Description, series...)
+		vr.SetSpan(base.first, base.last)
+		viewers.Register(vr)
+	}
+	if len(viewers) == 0 {
+		return nil, fmt.Errorf("statsview: session file %s has no viewers", path)
+	}
+
+	mgr, err := New(viewers)
+	if err != nil {
+		return nil, err
+	}
+	mgr.history = h
+	mgr.sessionStart = base.first
+	return mgr, nil
+}
+
+// readSession decodes a session file, checking its version
+func readSession(r io.Reader) (session, error) {
+	var s session
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return s, err
+	}
+	if err := json.NewDecoder(gz).Decode(&s); err != nil {
+		return s, err
+	}
+	if s.Version < 1 || s.Version > sessionVersion {
+		return s, fmt.Errorf("unsupported version %d", s.Version)
+	}
+	for _, samples := range s.Samples {
+		if len(samples.Times) != len(samples.Values) || len(samples.Times) == 0 {
+			return s, fmt.Errorf("series %s.%s has %d times and %d values",
+				samples.Viewer, samples.Series, len(samples.Times), len(samples.Values))
+		}
+	}
+	return s, nil
+}
+
+// historyWindow returns how far back the history reaches, the whole
+// session replayed by Open, rounded up to the minute
+func (vm *ViewManager) historyWindow() time.Duration {
+	if !vm.sessionStart.IsZero() {
+		return vm.Smgr.Now().Sub(vm.sessionStart).Truncate(time.Minute) + time.Minute
+	}
+	return viewer.HistoryWindow()
+}
@@ -0,0 +1,310 @@
+package statsview
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// gzipped compresses the JSON of v as a session file
+func gzipped(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// recordingManager returns a manager with history whose goroutine series
+// recorded the values a second apart, up to now
+func recordingManager(t *testing.T, values ...float64) *ViewManager {
+	t.Helper()
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mgr.Stop)
+	now := mgr.Smgr.Now().Truncate(time.Millisecond)
+	for i, v := range values {
+		at := now.Add(time.Duration(i-len(values)+1) * time.Second)
+		mgr.history.record([]viewer.Point{{Viewer: viewer.VGoroutine, Series: "Goroutines", Value: v, Time: at}})
+	}
+	return mgr
+}
+
+func TestExport(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	if err := mgr.Export(new(bytes.Buffer)); !errors.Is(err, errHistoryDisabled) {
+		t.Errorf("Export() without history = %v, want %v", err, errHistoryDisabled)
+	}
+
+	defer viewer.SetConfiguration(viewer.WithHistory(0))
+	viewer.SetConfiguration(viewer.WithHistory(time.Hour))
+
+	tests := []struct {
+		name    string
+		values  []float64
+		want    []float64
+		viewers []string
+	}{
+		{name: "nothing recorded", viewers: []string{}},
+		{name: "recorded", values: []float64{10, 20, 30}, want: []float64{10, 20, 30}, viewers: []string{viewer.VGoroutine}},
+		{name: "NaN and infinite left out", values: []float64{10, math.NaN(), math.Inf(1), 40}, want: []float64{10, 40}, viewers: []string{viewer.VGoroutine}},
+		{name: "only NaN", values: []float64{math.NaN()}, viewers: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := recordingManager(t, tt.values...)
+			var buf bytes.Buffer
+			if err := mgr.Export(&buf); err != nil {
+				t.Fatal(err)
+			}
+			s, err := readSession(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Version != sessionVersion {
+				t.Errorf("version = %d, want %d", s.Version, sessionVersion)
+			}
+			viewers := []string{}
+			for _, m := range s.Viewers {
+				viewers = append(viewers, m.Name)
+			}
+			if !reflect.DeepEqual(viewers, tt.viewers) {
+				t.Errorf("viewers = %v, want %v", viewers, tt.viewers)
+			}
+			var got []float64
+			for _, samples := range s.Samples {
+				if samples.Viewer == viewer.VGoroutine && samples.Series == "Goroutines" {
+					got = samples.Values
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSession(t *testing.T) {
+	valid := session{
+		Version: sessionVersion,
+		Samples: []sessionSamples{{Viewer: "heap", Series: "Alloc", Times: []int64{1, 2}, Values: []float64{3, 4}}},
+	}
+	mismatched := valid
+	mismatched.Samples = []sessionSamples{{Viewer: "heap", Series: "Alloc", Times: []int64{1, 2}, Values: []float64{3}}}
+	empty := valid
+	empty.Samples = []sessionSamples{{Viewer: "heap", Series: "Alloc"}}
+
+	tests := []struct {
+		name    string
+		file    []byte
+		wantErr bool
+	}{
+		{"valid", gzipped(t, valid), false},
+		{"not gzipped", []byte(`{"version": 1}`), true},
+		{"invalid JSON", gzipped(t, "session"), true},
+		{"no version", gzipped(t, session{}), true},
+		{"later version", gzipped(t, session{Version: sessionVersion + 1}), true},
+		{"more times than values", gzipped(t, mismatched), true},
+		{"series without points", gzipped(t, empty), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := readSession(bytes.NewReader(tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readSession() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(s, valid) {
+				t.Errorf("readSession() = %+v, want %+v", s, valid)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithHistory(0))
+	viewer.SetConfiguration(viewer.WithHistory(time.Hour))
+
+	var exported bytes.Buffer
+	if err := recordingManager(t, 10, 20, 30).Export(&exported); err != nil {
+		t.Fatal(err)
+	}
+	viewer.SetConfiguration(viewer.WithHistory(0))
+
+	dir := t.TempDir()
+	write := func(name string, b []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "exported", path: write("prod.session", exported.Bytes())},
+		{name: "missing", path: filepath.Join(dir, "missing.session"), wantErr: "no such file"},
+		{name: "invalid", path: write("invalid.session", []byte("{}")), wantErr: "invalid session file"},
+		{name: "no points", path: write("empty.session", gzipped(t, session{Version: sessionVersion})), wantErr: "has no points"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := Open(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Open() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			if w := mgr.historyWindow(); w < 2*time.Second {
+				t.Errorf("history window = %v, want the session replayed", w)
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/history/goroutine", nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("history status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			var res viewerHistory
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			var got []float64
+			for _, s := range res.Series {
+				for _, p := range s.Points {
+					got = append(got, p.Value)
+				}
+			}
+			if want := []float64{10, 20, 30}; !reflect.DeepEqual(got, want) {
+				t.Errorf("replayed history = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestExportSession(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithHistory(0))
+
+	tests := []struct {
+		name    string
+		history time.Duration
+		status  int
+	}{
+		{"history disabled", 0, http.StatusNotFound},
+		{"history enabled", time.Hour, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithHistory(tt.history))
+			mgr := recordingManager(t)
+
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/session", nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/gzip" {
+				t.Errorf("Content-Type = %s, want application/gzip", ct)
+			}
+			if cd := rec.Header().Get("Content-Disposition"); !strings.HasSuffix(cd, `.session"`) {
+				t.Errorf("Content-Disposition = %s, want a session file", cd)
+			}
+			if _, err := readSession(rec.Body); err != nil {
+				t.Errorf("invalid session file: %v", err)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithHistory(0))
+
+	goroutines := viewer.Meta{Name: viewer.VGoroutine, Title: "Goroutines", Series: []viewer.SeriesMeta{{Name: "Goroutines"}}}
+	heap := viewer.Meta{Name: viewer.VHeap, Title: "Heap", Series: []viewer.SeriesMeta{{Name: "Alloc"}}}
+	dir := t.TempDir()
+	write := func(name string, s session) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, gzipped(t, s), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	before := write("before.session", session{
+		Version: sessionVersion,
+		Viewers: []viewer.Meta{goroutines},
+		Samples: []sessionSamples{{Viewer: viewer.VGoroutine, Series: "Goroutines", Times: []int64{1000, 2000, 3000}, Values: []float64{10, 20, 30}}},
+	})
+	// recorded an hour later with a viewer the other session doesn't have
+	after := write("after.session", session{
+		Version: sessionVersion,
+		Viewers: []viewer.Meta{goroutines, heap},
+		Samples: []sessionSamples{
+			{Viewer: viewer.VGoroutine, Series: "Goroutines", Times: []int64{3601000, 3605000}, Values: []float64{1, 2}},
+			{Viewer: viewer.VHeap, Series: "Alloc", Times: []int64{3601000}, Values: []float64{100}},
+		},
+	})
+
+	mgr, err := Compare(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	at := func(ms int64) time.Time { return time.UnixMilli(ms) }
+	tests := []struct {
+		name string
+		key  seriesKey
+		want []historyPoint
+	}{
+		{"compared", seriesKey{viewer.VGoroutine, "Goroutines"}, []historyPoint{{Time: at(1000), Value: 10}, {Time: at(2000), Value: 20}, {Time: at(3000), Value: 30}}},
+		{"overlaid from the start", seriesKey{viewer.VGoroutine, "Goroutines (after)"}, []historyPoint{{Time: at(1000), Value: 1}, {Time: at(5000), Value: 2}}},
+		{"viewer of the overlay only", seriesKey{viewer.VHeap, "Alloc (after)"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mgr.history.query(tt.key, time.Time{}, at(math.MaxInt32)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%v = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+	for _, v := range mgr.views() {
+		if v.Name() == viewer.VHeap {
+			t.Error("the viewer of the overlay only is replayed")
+		}
+	}
+
+	if _, err := Compare(before, filepath.Join(dir, "missing.session")); err == nil {
+		t.Error("Compare() with a missing session file succeeded")
+	}
+}
@@ -0,0 +1,43 @@
+package statsview
+
+import (
+	"net/http"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// viewerSnapshot holds the latest values of a viewer in base units
+type viewerSnapshot struct {
+	Name   string    `json:"name"`
+	Title  string    `json:"title"`
+	Series []string  `json:"series"`
+	Values []float64 `json:"values"`
+}
+
+// snapshot is the machine readable state of all collecting viewers, it's
+// pulled by the hub
+type snapshot struct {
+	Time    int64            `json:"time"`
+	Viewers []viewerSnapshot `json:"viewers"`
+}
+
+func (vm *ViewManager) snapshot() snapshot {
+	s := snapshot{Time: vm.Smgr.Now().UnixMilli()}
+	for _, v := range vm.views() {
+		c, ok := v.(viewer.Collector)
+		if !ok || vm.skipped(v) {
+			continue
+		}
+		vs := viewerSnapshot{Name: v.Name(), Title: v.View().Title.Title}
+		for _, p := range vm.safeCollect(v, c) {
+			vs.Series = append(vs.Series, p.Series)
+			vs.Values = append(vs.Values, p.Value)
+		}
+		s.Viewers = append(s.Viewers, vs)
+	}
+	return s
+}
+
+func (vm *ViewManager) serveSnapshot(w http.ResponseWriter, r *http.Request) {
+	writeData(w, r, vm.snapshot())
+}
@@ -0,0 +1,105 @@
+package statsview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestTrustedHeaders(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithTrustedHeaders(viewer.TrustedHeaders{}))
+
+	proxy := viewer.TrustedHeaders{
+		User:    "X-Forwarded-User",
+		Groups:  "X-Forwarded-Groups",
+		Readers: []string{"devs"},
+		Admins:  []string{"oncall", "alice@example.com"},
+	}
+	everyone := proxy
+	everyone.Readers = nil
+	behind := proxy
+	behind.Proxies = []string{"10.0.0.0/8"}
+
+	const (
+		read  = "/debug/statsview/api/v1/status"
+		admin = "/debug/statsview/control/freeosmemory"
+	)
+	tests := []struct {
+		name    string
+		headers viewer.TrustedHeaders
+		method  string
+		path    string
+		remote  string
+		user    string
+		groups  string
+		status  int
+	}{
+		{name: "read without user", headers: proxy, method: http.MethodGet, path: read, status: http.StatusUnauthorized},
+		{name: "reader group", headers: proxy, method: http.MethodGet, path: read, user: "bob", groups: "qa, devs", status: http.StatusOK},
+		{name: "not a reader", headers: proxy, method: http.MethodGet, path: read, user: "bob", groups: "qa", status: http.StatusUnauthorized},
+		{name: "every user reads", headers: everyone, method: http.MethodGet, path: read, user: "bob", status: http.StatusOK},
+		{name: "admin reads", headers: proxy, method: http.MethodGet, path: read, user: "Alice@example.com", status: http.StatusOK},
+		{name: "admin user", headers: proxy, method: http.MethodPost, path: admin, user: "alice@example.com", status: http.StatusNoContent},
+		{name: "admin group", headers: proxy, method: http.MethodPost, path: admin, user: "bob", groups: "devs,oncall", status: http.StatusNoContent},
+		{name: "reader refused the admin routes", headers: proxy, method: http.MethodPost, path: admin, user: "bob", groups: "devs", status: http.StatusUnauthorized},
+		{name: "trusted proxy", headers: behind, method: http.MethodPost, path: admin, remote: "10.1.2.3:4321", user: "alice@example.com", status: http.StatusNoContent},
+		{name: "untrusted proxy", headers: behind, method: http.MethodPost, path: admin, remote: "192.0.2.1:4321", user: "alice@example.com", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := viewer.SetConfiguration(viewer.WithTrustedHeaders(tt.headers)); err != nil {
+				t.Fatal(err)
+			}
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Header.Set("X-Requested-With", "XMLHttpRequest")
+			if tt.remote != "" {
+				r.RemoteAddr = tt.remote
+			}
+			if tt.user != "" {
+				r.Header.Set("X-Forwarded-User", tt.user)
+			}
+			if tt.groups != "" {
+				r.Header.Set("X-Forwarded-Groups", tt.groups)
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, r)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPrincipalOfTrustedHeaders(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithTrustedHeaders(viewer.TrustedHeaders{}))
+	viewer.SetConfiguration(viewer.WithTrustedHeaders(viewer.TrustedHeaders{
+		User: "X-Forwarded-User", Groups: "X-Forwarded-Groups", Admins: []string{"oncall"},
+	}))
+
+	tests := []struct {
+		name, user, groups, want string
+	}{
+		{"anonymous", "", "", ""},
+		{"admin group", "bob", "oncall", "bob (trusted-header)"},
+		{"blank user", "  ", "oncall", ""},
+		{"not an admin", "bob", "devs", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/debug/statsview/control/gc", nil)
+			r.Header.Set("X-Forwarded-User", tt.user)
+			r.Header.Set("X-Forwarded-Groups", tt.groups)
+			if got := principalOf(r); got != tt.want {
+				t.Errorf("principalOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package statics
+
+// FlamegraphJS is the flamegraph.js asset, it draws the call tree served by
+// statsview as a flame graph with a custom echarts series
+const FlamegraphJS = `
+"use strict";
+// statsview_flamegraph renders the call tree root ({name, value, children})
+// into the element, clicking a frame zooms into it and clicking the root
+// zooms out again. format formats the values in the tooltip
+function statsview_flamegraph(el, root, format) {
+	// the names are kept aside, echarts parses the values as numbers
+	let frames = [], names = [];
+	let depth = 0;
+	(function fold(node, level, start) {
+		frames.push([level, start, start + node.value, node.value]);
+		names.push(node.name);
+		depth = Math.max(depth, level + 1);
+		let offset = start;
+		for (const child of node.children || []) {
+			fold(child, level + 1, offset);
+			offset += child.value;
+		}
+	})(root, 0, 0);
+
+	function color(name) {
+		let hash = 0;
+		for (let i = 0; i < name.length; i++) {
+			hash = (hash * 31 + name.charCodeAt(i)) | 0;
+		}
+		hash = Math.abs(hash);
+		return "hsl(" + (hash % 50) + ", " + (70 + hash % 20) + "%, " + (55 + hash % 15) + "%)";
+	}
+
+	let view = { min: 0, max: root.value };
+	el.style.height = Math.max(200, depth * 20 + 40) + "px";
+	let chart = echarts.getInstanceByDom(el) || echarts.init(el);
+	chart.clear();
+	chart.setOption({
+		animation: false,
+		grid: { left: 0, right: 0, top: 10, bottom: 10 },
+		tooltip: {
+			formatter: function (params) {
+				let v = params.value;
+				let share = root.value > 0 ? (v[3] / root.value * 100).toFixed(2) : "0";
+				return $("<div>").text(names[params.dataIndex]).html() + "<br>" + format(v[3]) + " (" + share + "%)";
+			}
+		},
+		xAxis: { type: "value", min: 0, max: root.value, show: false },
+		yAxis: { type: "value", min: 0, max: depth, show: false },
+		series: [{
+			type: "custom",
+			data: frames,
+			encode: { x: [1, 2], y: 0 },
+			renderItem: function (params, api) {
+				// the frames are clipped to the zoomed range
+				let start = Math.max(api.value(1), view.min);
+				let end = Math.min(api.value(2), view.max);
+				if (end <= start) {
+					return;
+				}
+				let left = api.coord([start, api.value(0) + 1]);
+				let right = api.coord([end, api.value(0)]);
+				let width = right[0] - left[0];
+				if (width < 1) {
+					return;
+				}
+				let name = names[params.dataIndex];
+				let chars = Math.floor((width - 6) / 6);
+				let text = chars < 3 ? "" : (name.length > chars ? name.slice(0, chars - 1) + "…" : name);
+				return {
+					type: "rect",
+					shape: { x: left[0], y: left[1], width: width - 1, height: right[1] - left[1] - 1 },
+					style: api.style({
+						fill: color(name),
+						stroke: null,
+						text: text,
+						textFill: "#000",
+						textPosition: "insideLeft",
+						fontSize: 11
+					})
+				};
+			}
+		}]
+	});
+	chart.off("click");
+	chart.on("click", function (params) {
+		let v = params.value;
+		view = { min: v[1], max: v[2] };
+		chart.setOption({ xAxis: { min: view.min, max: view.max } });
+	});
+	return chart;
+}
+`
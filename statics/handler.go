@@ -0,0 +1,69 @@
+package statics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheControl is sent with every asset, clients revalidate with the ETag
+// once it expired
+const CacheControl = "public, max-age=3600"
+
+type asset struct {
+	content     []byte
+	contentType string
+	etag        string
+
+	gzipOnce sync.Once
+	gzipped  []byte
+}
+
+// Handler serves the asset with an ETag and Cache-Control, the content is
+// gzip compressed once on first request for clients accepting it
+func Handler(content, contentType string) http.Handler {
+	sum := sha256.Sum256([]byte(content))
+	return &asset{
+		content:     []byte(content),
+		contentType: contentType,
+		etag:        hex.EncodeToString(sum[:8]),
+	}
+}
+
+// JS serves a JavaScript asset, see Handler
+func JS(content string) http.Handler {
+	return Handler(content, "application/javascript; charset=utf-8")
+}
+
+func (a *asset) gzip() []byte {
+	a.gzipOnce.Do(func() {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		zw.Write(a.content)
+		zw.Close()
+		a.gzipped = buf.Bytes()
+	})
+	return a.gzipped
+}
+
+func (a *asset) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	h.Set("Content-Type", a.contentType)
+	h.Set("Cache-Control", CacheControl)
+	h.Add("Vary", "Accept-Encoding")
+
+	content, etag := a.content, a.etag
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		content, etag = a.gzip(), a.etag+"-gz"
+		h.Set("Content-Encoding", "gzip")
+	}
+	h.Set("ETag", `"`+etag+`"`)
+
+	// ServeContent answers conditional requests with 304 Not Modified
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+}
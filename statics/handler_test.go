@@ -0,0 +1,76 @@
+package statics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	const content = "console.log('statsview');"
+	h := JS(content)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := rec.Header().Get("ETag")
+
+	tests := []struct {
+		name        string
+		encoding    string
+		ifNoneMatch string
+		status      int
+		gzipped     bool
+	}{
+		{name: "plain", status: http.StatusOK},
+		{name: "gzip", encoding: "gzip, deflate", status: http.StatusOK, gzipped: true},
+		{name: "not modified", ifNoneMatch: etag, status: http.StatusNotModified},
+		{name: "gzip etag differs", encoding: "gzip", ifNoneMatch: etag, status: http.StatusOK, gzipped: true},
+		{name: "gzip not modified", encoding: "gzip", ifNoneMatch: etag[:len(etag)-1] + `-gz"`, status: http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/statics/app.js", nil)
+			if tt.encoding != "" {
+				r.Header.Set("Accept-Encoding", tt.encoding)
+			}
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if cc := rec.Header().Get("Cache-Control"); cc != CacheControl {
+				t.Errorf("Cache-Control = %s, want %s", cc, CacheControl)
+			}
+			if tt.status == http.StatusNotModified {
+				return
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/javascript; charset=utf-8" {
+				t.Errorf("Content-Type = %s", ct)
+			}
+
+			body := rec.Body.Bytes()
+			if tt.gzipped {
+				if ce := rec.Header().Get("Content-Encoding"); ce != "gzip" {
+					t.Fatalf("Content-Encoding = %s, want gzip", ce)
+				}
+				zr, err := gzip.NewReader(bytes.NewReader(body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if body, err = io.ReadAll(zr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if string(body) != content {
+				t.Errorf("body = %q, want %q", body, content)
+			}
+		})
+	}
+}
@@ -9,16 +9,21 @@ package statsview
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/pprof"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/go-echarts/go-echarts/v2/components"
 	"github.com/go-echarts/go-echarts/v2/templates"
+	"github.com/mortum5/statsview/internal/anomaly"
+	"github.com/mortum5/statsview/notify"
+	"github.com/mortum5/statsview/registry"
 	"github.com/mortum5/statsview/statics"
 	"github.com/mortum5/statsview/viewer"
 	"github.com/pkg/browser"
-	"github.com/rs/cors"
 )
 
 func init() {
@@ -28,14 +33,401 @@ func init() {
 	<html>
 		{{- template "header" . }}
 	<body>
-	<style> .box { justify-content:center; display:flex; flex-wrap:wrap } </style>
+	<style> .box { justify-content:center; display:flex; flex-wrap:wrap } .nav { text-align:center; font-family:sans-serif } </style>
+	<div class="nav">
+		<span id="degraded" style="display:none; color:#c23531"></span>
+		<a id="leaks" href="{{ .Base }}/goroutines" style="display:none; color:#c23531"></a>
+		<a id="stuck" href="{{ .Base }}/stuck" style="display:none; color:#c23531"></a>
+		<a id="memtrend" href="{{ .Base }}/objects" style="display:none; color:#c23531"></a>
+		<a href="{{ .Base }}/goroutines">Goroutines</a> |
+		<a href="{{ .Base }}/objects">Live objects</a> |
+		<a href="{{ .Base }}/heapdiff">Heap diff</a> |
+		<a href="{{ .Base }}/locks">Contended locks</a> |
+		<a href="{{ .Base }}/profiles">Profiles</a> |
+		<a href="{{ .Base }}/flamegraph">Flame graph</a> |
+		{{- if .Admin }}
+		Baseline <input id="baseline-last" size="4" value="5m"> <button id="baseline-set">Record</button>
+		<button id="baseline-clear">Clear</button> |
+		Block rate <input id="block-rate" size="8"> <button id="block-set">Set</button> |
+		Mutex fraction <input id="mutex-fraction" size="8"> <button id="mutex-set">Set</button> |
+		GOGC <input id="gogc" size="5"> GOMEMLIMIT <input id="gomemlimit" size="8"> <button id="gc-set">Set</button> |
+		GOMAXPROCS <input id="gomaxprocs" size="3"> <button id="gomaxprocs-set">Set</button>
+		<button id="force-gc">Force GC</button>
+		<button id="free-os-memory">Free OS memory</button> |
+		{{- else if .SignIn }}
+		<a href="{{ .Base }}?admin">Admin</a> |
+		{{- end }}
+		Theme <select id="ui-theme"><option value="">default</option><option>macarons</option><option>westeros</option></select>
+		Layout <select id="ui-layout"><option value="">default</option><option>compact</option><option>wide</option></select>
+		<button id="ui-reset">Reset view</button>
+	</div>
+	<div id="buildinfo" class="nav" style="color:#888; font-size:12px; margin-top:4px"></div>
+	<script type="text/javascript">
+	// the routes are served under the path the dashboard was requested on,
+	// which includes the prefix of the router group statsview is mounted in
+	const base = {{ .Base }};
+	const admin = {{ .Admin }};
+	window.statsview_base = base;
+	function profile_set(name, param) {
+		let data = {};
+		data[param] = $("#" + name + "-" + param).val();
+		admin_post(base + "/profile/" + name, data,
+			function (r) { $("#" + name + "-" + param).val(r[param]); });
+	}
+	function gc_show(r) {
+		$("#gogc").val(r.gogc);
+		$("#gomemlimit").val(r.gomemlimit);
+	}
+	// the token is asked for once the post is refused, the admins signed in
+	// by a trusted proxy don't need one
+	function admin_post(url, data, done, token) {
+		token = token || sessionStorage.getItem("statsview-token");
+		$.ajax({
+			type: "POST",
+			url: url,
+			data: data,
+			headers: token ? { Authorization: "Bearer " + token } : {},
+			success: function (r) {
+				if (token) {
+					sessionStorage.setItem("statsview-token", token);
+				}
+				if (done) {
+					done(r);
+				}
+				annotations_sync();
+			},
+			error: function (xhr) {
+				if (xhr.status === 401) {
+					sessionStorage.removeItem("statsview-token");
+					let next = prompt("Admin token");
+					if (next) {
+						admin_post(url, data, done, next);
+					}
+					return;
+				}
+				alert(xhr.responseText);
+			}
+		});
+	}
+	function gc_set() {
+		admin_post(base + "/gc", { gogc: $("#gogc").val(), gomemlimit: $("#gomemlimit").val() }, gc_show);
+	}
+	function gomaxprocs_set() {
+		admin_post(base + "/control/gomaxprocs", { procs: $("#gomaxprocs").val() },
+			function (r) { $("#gomaxprocs").val(r.procs); });
+	}
+	function annotations_sync() {
+		$.getJSON(base + "/annotations", function (as) {
+			$(".item").each(function () {
+				let chart = echarts.getInstanceByDom(this);
+				if (!chart) {
+					return;
+				}
+				let opt = chart.getOption();
+				let x = opt.xAxis[0].data;
+				let s = opt.series[opt.series.length - 1];
+				let kept = ((s.markLine && s.markLine.data) || []).filter(d => d.xAxis === undefined);
+				let marks = (as || []).filter(a => x.indexOf(a.time) >= 0)
+					.map(a => ({ name: a.text, xAxis: a.time, label: { formatter: a.text } }));
+				s.markLine = { symbol: "none", data: kept.concat(marks) };
+				chart.setOption(opt);
+			});
+		});
+	}
+	// marks_set replaces the marked areas of a kind on a series, the other
+	// kinds are kept
+	function marks_set(s, kind, areas) {
+		let kept = ((s.markArea && s.markArea.data) || []).filter(a => a[0].name !== kind);
+		areas.forEach(a => a[0].name = kind);
+		s.markArea = { silent: true, data: kept.concat(areas) };
+	}
+	// the bands of the baseline are drawn behind their series
+	function baseline_sync() {
+		$.getJSON(base + "/baseline", function (b) { baseline_show(b.bands); })
+			.fail(function () { baseline_show([]); });
+	}
+	function baseline_show(bands) {
+		let charts = window.statsview_charts || {};
+		for (const route in charts) {
+			let chart = charts[route];
+			let opt = chart.getOption();
+			opt.series.forEach(function (s, i) {
+				let color = opt.color[i % opt.color.length];
+				let areas = bands.filter(b => b.viewer === route && b.series === s.name)
+					.map(b => [{ yAxis: b.low, itemStyle: { color: color, opacity: 0.12 } }, { yAxis: b.high }]);
+				marks_set(s, "baseline", areas);
+			});
+			chart.setOption(opt);
+		}
+	}
+	// the anomalous regions are shaded on their series, the ones scrolled
+	// out of the chart are dropped. The sync stops when the detection is disabled
+	let anomalies_timer = null;
+	function anomalies_sync() {
+		$.getJSON(base + "/anomalies", function (regions) {
+			let charts = window.statsview_charts || {};
+			for (const route in charts) {
+				let chart = charts[route];
+				let opt = chart.getOption();
+				let x = opt.xAxis[0].data;
+				if (x.length === 0) {
+					continue;
+				}
+				opt.series.forEach(function (s) {
+					let areas = regions.filter(r => r.viewer === route && r.series === s.name).map(function (r) {
+						let from = x.indexOf(r.from), to = x.indexOf(r.to);
+						if (from < 0 && to < 0) {
+							return null;
+						}
+						return [{ xAxis: x[from < 0 ? 0 : from], itemStyle: { color: "rgba(194, 53, 49, 0.15)" } },
+							{ xAxis: x[to < 0 ? x.length - 1 : to] }];
+					}).filter(a => a !== null);
+					marks_set(s, "anomaly", areas);
+				});
+				chart.setOption(opt);
+			}
+		}).fail(function (xhr) {
+			if (xhr.status === 404) {
+				clearInterval(anomalies_timer);
+			}
+		});
+	}
+	function status_sync() {
+		$.getJSON(base + "/status", function (r) {
+			$("#degraded").toggle(r.degraded)
+				.text("Degraded collection, CPU " + (r.cpu * 100).toFixed(0) + "%, GC " + (r.gc * 100).toFixed(0) +
+					"%, interval " + r.interval + "ms |");
+		});
+	}
+	function leaks_sync() {
+		$.getJSON(base + "/goroutines/leaks", function (leaks) {
+			let text = leaks.map(l => (l.createdBy ? l.createdBy.func : l.key) + " +" + l.growth).join(", ");
+			$("#leaks").toggle(leaks.length > 0).text("Goroutine leak suspected: " + text + " |");
+		});
+	}
+	function stuck_sync() {
+		$.getJSON(base + "/goroutines/stuck", function (stuck) {
+			let count = stuck.reduce((n, s) => n + s.count, 0);
+			let top = stuck.length > 0 && stuck[0].stack.length > 0 ? ", " + stuck[0].stack[0].func : "";
+			$("#stuck").toggle(stuck.length > 0).text("Goroutines stuck: " + count + top + " |");
+		});
+	}
+	function mib(bytes) {
+		return (bytes / (1 << 20)).toFixed(1) + " MiB";
+	}
+	function memtrend_sync() {
+		$.getJSON(base + "/memory/trend", function (ws) {
+			let text = ws.map(function (w) {
+				let t = w.series + " " + mib(w.current) + " +" + mib(w.rate * 60) + "/min";
+				if (w.limit) {
+					t += ", " + w.limitSource + " " + mib(w.limit) + " in ~" + Math.round(w.eta / 6e10) + " min";
+				}
+				return t;
+			}).join(", ");
+			$("#memtrend").toggle(ws.length > 0).text("Memory growing: " + text + " |");
+		});
+	}
+	function buildinfo_sync() {
+		$.getJSON(base + "/buildinfo", function (b) {
+			let parts = [];
+			if (b.path) {
+				let rev = b.revision ? " rev " + b.revision.slice(0, 12) + (b.modified ? "+dirty" : "") : "";
+				parts.push(b.path + " " + b.version + rev);
+			}
+			parts.push(b.goVersion + " " + b.goos + "/" + b.goarch,
+				"GOMAXPROCS " + b.gomaxprocs + "/" + b.numcpu + " CPU",
+				"GOGC " + b.gogc, "GOMEMLIMIT " + b.gomemlimit,
+				"started " + b.startFormatted + " (up " + b.uptime + ")");
+			$("#buildinfo").text(parts.join(" · "));
+		});
+	}
+	// the dashboard reloads as viewers are registered or unregistered and as
+	// the configuration changes
+	let config = null;
+	function config_sync() {
+		$.getJSON(base + "/config", function (c) {
+			let current = JSON.stringify(c);
+			if (config !== null && current !== config) {
+				location.reload();
+			}
+			config = current;
+		});
+	}
+	// the state of the charts, their zoom and the series toggled off in their
+	// legends, is kept by viewer in the local storage through the hooks of
+	// their view templates and restored on load. The theme and the layout
+	// are kept too, the theme is mirrored in a cookie as the page is drawn
+	// with it
+	function statsview_chart_state(chart) {
+		let opt = chart.getOption();
+		let state = { hidden: [] };
+		if (opt.legend && opt.legend[0] && opt.legend[0].selected) {
+			let selected = opt.legend[0].selected;
+			state.hidden = Object.keys(selected).filter(function (name) { return !selected[name]; });
+		}
+		if (opt.dataZoom && opt.dataZoom[0]) {
+			state.zoom = { start: opt.dataZoom[0].start, end: opt.dataZoom[0].end };
+		}
+		return state;
+	}
+	function statsview_chart_restore(chart, state) {
+		(state.hidden || []).forEach(function (name) {
+			chart.dispatchAction({ type: "legendUnSelect", name: name });
+		});
+		if (state.zoom) {
+			chart.dispatchAction({ type: "dataZoom", start: state.zoom.start, end: state.zoom.end });
+		}
+	}
+	function ui_load(key) {
+		try {
+			return JSON.parse(localStorage.getItem(key));
+		} catch (e) {
+			return null;
+		}
+	}
+	function ui_save(key, value) {
+		try {
+			localStorage.setItem(key, JSON.stringify(value));
+		} catch (e) {
+		}
+	}
+	function ui_cookie(name) {
+		let found = document.cookie.split("; ").find(function (c) { return c.startsWith(name + "="); });
+		return found ? found.slice(name.length + 1) : "";
+	}
+	function theme_set(theme) {
+		ui_save("statsview-theme", theme);
+		document.cookie = "statsview-theme=" + theme + "; path=/; SameSite=Strict; max-age=" + (theme ? 31536000 : 0);
+		location.reload();
+	}
+	const layouts = { compact: ["420px", "280px"], wide: ["95%", "400px"] };
+	function layout_set(name) {
+		ui_save("statsview-layout", name);
+		if (!layouts[name]) {
+			location.reload();
+			return;
+		}
+		layout_apply(name);
+	}
+	function layout_apply(name) {
+		let size = layouts[name];
+		if (!size) {
+			return;
+		}
+		$(".box .item").each(function () {
+			this.style.width = size[0];
+			this.style.height = size[1];
+			let chart = echarts.getInstanceByDom(this);
+			if (chart) {
+				chart.resize();
+			}
+		});
+	}
+	function ui_reset() {
+		Object.keys(localStorage).filter(function (k) { return k.startsWith("statsview-"); })
+			.forEach(function (k) { localStorage.removeItem(k); });
+		theme_set("");
+	}
+	function ui_restore() {
+		// the cookie could have expired or been cleared, the page is drawn
+		// again once with the theme kept
+		let theme = ui_load("statsview-theme") || "";
+		if (theme && ui_cookie("statsview-theme") !== theme && !sessionStorage.getItem("statsview-theme-reloaded")) {
+			sessionStorage.setItem("statsview-theme-reloaded", "1");
+			theme_set(theme);
+			return;
+		}
+		$("#ui-theme").val(theme);
+		let layout = ui_load("statsview-layout") || "";
+		$("#ui-layout").val(layout);
+		layout_apply(layout);
+
+		// every chart is restored before the changes are saved, the zoom of
+		// a chart moves the ones synchronized with it
+		const states = window.statsview_state || {};
+		for (const route in states) {
+			let state = ui_load("statsview-state:" + route);
+			if (state) {
+				states[route].restore(state);
+			}
+		}
+		for (const route in states) {
+			let chart = window.statsview_charts[route];
+			let save = function () { ui_save("statsview-state:" + route, states[route].save()); };
+			chart.on("datazoom", save);
+			chart.on("legendselectchanged", save);
+		}
+	}
+	// the dashboard holds an explicit lease so the collection keeps running
+	// while it's open and stops as soon as it's closed
+	const client = Math.random().toString(36).slice(2);
+	function lease() {
+		$.post(base + "/lease", { client: client });
+	}
+	window.addEventListener("pagehide", function () {
+		navigator.sendBeacon(base + "/lease?release=1&client=" + client);
+	});
+	$(function () {
+		// the handlers are bound here, the CSP refuses inline ones
+		$("#block-set").on("click", function () { profile_set("block", "rate"); });
+		$("#mutex-set").on("click", function () { profile_set("mutex", "fraction"); });
+		$("#gc-set").on("click", gc_set);
+		$("#gomaxprocs-set").on("click", gomaxprocs_set);
+		$("#force-gc").on("click", function () { admin_post(base + "/control/gc", {}); });
+		$("#free-os-memory").on("click", function () { admin_post(base + "/control/freeosmemory", {}); });
+		$("#baseline-set").on("click", function () {
+			admin_post(base + "/baseline", { last: $("#baseline-last").val() }, function (b) { baseline_show(b.bands); });
+		});
+		$("#baseline-clear").on("click", function () {
+			admin_post(base + "/baseline", { clear: 1 }, function () { baseline_show([]); });
+		});
+		$("#ui-theme").on("change", function () { theme_set($(this).val()); });
+		$("#ui-layout").on("change", function () { layout_set($(this).val()); });
+		$("#ui-reset").on("click", ui_reset);
+		ui_restore();
+		lease();
+		setInterval(lease, 5000);
+		status_sync();
+		setInterval(status_sync, 5000);
+		buildinfo_sync();
+		setInterval(buildinfo_sync, 5000);
+		setInterval(annotations_sync, 5000);
+		anomalies_timer = setInterval(anomalies_sync, 5000);
+		baseline_sync();
+		setInterval(baseline_sync, 10000);
+		leaks_sync();
+		setInterval(leaks_sync, 10000);
+		stuck_sync();
+		setInterval(stuck_sync, 10000);
+		memtrend_sync();
+		setInterval(memtrend_sync, 10000);
+		config_sync();
+		setInterval(config_sync, 5000);
+		if (admin) {
+			$.getJSON(base + "/gc", gc_show);
+			$.getJSON(base + "/control/gomaxprocs", function (r) { $("#gomaxprocs").val(r.procs); });
+			$.getJSON(base + "/profile/block", function (r) { $("#block-rate").val(r.rate); });
+			$.getJSON(base + "/profile/mutex", function (r) { $("#mutex-fraction").val(r.fraction); });
+		}
+	});
+	</script>
 	<div class="box"> {{- range .Charts }} {{ template "base" . }} {{- end }} </div>
+	<script type="text/javascript">
+		echarts.connect("` + viewer.ChartGroup + `");
+	</script>
 	</body>
 	</html>
 	{{ end }}
 		`
 }
 
+const (
+	// BasePath is the path prefix of the dashboard and its data endpoints
+	BasePath = "/debug/statsview"
+	// PprofPath is the path prefix of the integrated pprof endpoints
+	PprofPath = "/debug/pprof"
+)
+
 // Viewers represent collection of Viewer
 type Viewers []viewer.Viewer
 
@@ -57,6 +449,40 @@ func NewDefaultViewers() Viewers {
 	}
 }
 
+// NewProcViewers returns the viewers of the counters the kernel keeps for the
+// process in /proc/self: the context switches, the page faults and the
+// block I/O. They explain slowdowns while the Go runtime looks fine, they
+// chart nothing outside of linux
+func NewProcViewers() Viewers {
+	return Viewers{
+		viewer.NewCtxSwitchViewer(),
+		viewer.NewPageFaultsViewer(),
+		viewer.NewProcIOViewer(),
+	}
+}
+
+// NewDiskViewers returns the viewers of the disk usage of the mount points
+// of paths and of the block I/O of the process, e.g. for services writing
+// spill files or a WAL
+func NewDiskViewers(paths ...string) Viewers {
+	return Viewers{
+		viewer.NewDiskViewer(paths),
+		viewer.NewProcIOViewer(),
+	}
+}
+
+// NewHostViewers returns the opt-in viewers of the machine the process runs
+// on: the usage of every core, the load average and the memory and swap, so
+// one page covers both where there is no other monitoring. They chart
+// nothing outside of linux
+func NewHostViewers() Viewers {
+	return Viewers{
+		viewer.NewHostCPUViewer(),
+		viewer.NewHostLoadViewer(),
+		viewer.NewHostMemoryViewer(),
+	}
+}
+
 // NewEmptyViewers returns empty collection without any Viewer
 func NewEmptyViewers() Viewers {
 	return Viewers{}
@@ -67,11 +493,82 @@ func (v *Viewers) Register(views ...viewer.Viewer) {
 	*v = append(*v, views...)
 }
 
+// viewerByName returns the served viewer of the name, nil if there is none
+func (vm *ViewManager) viewerByName(name string) viewer.Viewer {
+	return findViewer(vm.views(), name)
+}
+
+// enabled returns the viewers selected by viewer.WithViewers
+func (v Viewers) enabled() Viewers {
+	names := viewer.EnabledViewers()
+	if len(names) == 0 {
+		return v
+	}
+
+	enabled := make(Viewers, 0, len(names))
+	for _, vr := range v {
+		for _, name := range names {
+			if vr.Name() == name {
+				enabled = append(enabled, vr)
+				break
+			}
+		}
+	}
+	return enabled
+}
+
 // ViewManager
 type ViewManager struct {
 	srv *http.Server
 
-	Smgr   *viewer.StatsMgr
+	exporters  []exporterEntry
+	exportWg   sync.WaitGroup
+	registrars []registry.Registrar
+	// registration is the state of the registrations to the registrars
+	registration registration
+
+	recorder   *flightRecorder
+	recorderMu sync.Mutex
+
+	annotations   []annotation
+	annotationsMu sync.Mutex
+	audit         auditLog
+
+	anomalies    *anomaly.Detector
+	anomalyHooks []func(Anomaly)
+	notifiers    []notify.Notifier
+	baseline     baselineStore
+
+	history *history
+	// sessionStart is the start of the session replayed by Open
+	sessionStart time.Time
+
+	objects  objectsSampler
+	locks    locksSampler
+	heapBase heapBaseline
+	leaks    leakSampler
+	stuck    stuckSampler
+
+	memTrend memTrendSampler
+	limiter  *rateLimiter
+
+	initOnce    sync.Once
+	initErr     error
+	initialized int32
+
+	running int32
+	started int32
+
+	// changeMu serializes Register, Unregister and the live configuration,
+	// viewsMu guards the viewers and viewErrors they replace
+	changeMu   sync.Mutex
+	viewsMu    sync.RWMutex
+	known      []viewer.Viewer
+	viewErrors map[string]*int64
+
+	Smgr *viewer.StatsMgr
+	// Views are the served viewers, Register and Unregister replace the
+	// slice instead of modifying it
 	Views  []viewer.Viewer
 	Ctx    context.Context
 	Cancel context.CancelFunc
@@ -79,31 +576,89 @@ type ViewManager struct {
 
 // Start runs a http server and begin to collect metrics
 func (vm *ViewManager) Start() error {
-	var t time.Timer = *time.NewTimer(time.Second)
-	defer t.Stop()
+	ln, err := net.Listen("tcp", vm.srv.Addr)
+	if err != nil {
+		viewer.Logger().Error("statsview: failed to listen", "addr", vm.srv.Addr, "err", err)
+		return err
+	}
+
+	if err := vm.run(); err != nil {
+		ln.Close()
+		return err
+	}
+
 	if viewer.BrowserOpen() {
-		t = *time.AfterFunc(time.Second, func() {
+		t := time.AfterFunc(time.Second, func() {
 			browser.OpenURL(fmt.Sprintf("http://%s/debug/statsview", viewer.Addr()))
 		})
+		defer t.Stop()
+	}
 
+	viewer.Logger().Info("statsview: server started", "addr", ln.Addr().String())
+	atomic.StoreInt32(&vm.started, 1)
+	err = vm.srv.Serve(ln)
+	if err != nil && err != http.ErrServerClosed {
+		viewer.Logger().Error("statsview: server failed", "err", err)
 	}
-	return vm.srv.ListenAndServe()
+	return err
 }
 
-// Stop shutdown the http server gracefully
+// Handler returns the handler serving every statsview route under BasePath
+// and PprofPath, it allows mounting statsview into an existing server or
+// framework instead of calling Start, under any prefix, e.g. a router group
+// at `/admin` serving `/admin/debug/statsview`. The viewers, the exporters,
+// the history and the registrars are started here then, a failure is logged
+// and answered with a 500
+func (vm *ViewManager) Handler() http.Handler {
+	if err := vm.run(); err != nil {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			viewer.WriteError(w, http.StatusInternalServerError, err)
+		})
+	}
+	atomic.StoreInt32(&vm.started, 1)
+	return stripMount(vm.srv.Handler)
+}
+
+// Stop shutdown the http server gracefully, the exporters get the last samples
+// and are flushed before Stop returns
 func (vm *ViewManager) Stop() {
+	vm.deregister()
+	atomic.StoreInt32(&vm.started, 0)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	vm.srv.Shutdown(ctx)
+	if err := vm.srv.Shutdown(ctx); err != nil {
+		viewer.Logger().Error("statsview: failed to shutdown server", "err", err)
+	}
 	vm.Cancel()
+
+	vm.recorderMu.Lock()
+	if vm.recorder != nil {
+		vm.recorder.stop()
+		vm.recorder = nil
+	}
+	vm.recorderMu.Unlock()
+
+	// the exporters get the last samples while the viewers are still open,
+	// as Headless.Stop does
+	if len(vm.exporters) > 0 {
+		vm.exportWg.Wait()
+		flushExporters(vm.exporters, vm.collect())
+	}
+
+	if atomic.CompareAndSwapInt32(&vm.initialized, 1, 0) {
+		vm.closeViewers(vm.views())
+	}
+	viewer.Logger().Info("statsview: server stopped")
 }
 
-// New creates a new ViewManager instance
-func New(viewers Viewers) *ViewManager {
-	page := components.NewPage()
-	page.PageTitle = "Statsview"
-	page.AssetsHost = fmt.Sprintf("http://%s/debug/statsview/statics/", viewer.LinkAddr())
-	page.Assets.JSAssets.Add("jquery.min.js")
+// New creates a new ViewManager instance, it fails if the configured view
+// template is invalid
+func New(viewers Viewers) (*ViewManager, error) {
+	if err := viewer.ValidateTemplate(viewer.Template()); err != nil {
+		viewer.Logger().Error("statsview: invalid view template", "err", err)
+		return nil, err
+	}
 
 	mgr := &ViewManager{
 		srv: &http.Server{
@@ -111,49 +666,79 @@ func New(viewers Viewers) *ViewManager {
 			ReadTimeout:    time.Minute,
 			WriteTimeout:   time.Minute,
 			MaxHeaderBytes: 1 << 20,
+			// handler panics recovered by net/http end up in the configured logger
+			ErrorLog: slog.NewLogLogger(viewer.Logger().Handler(), slog.LevelError),
 		},
 	}
 	mgr.Ctx, mgr.Cancel = context.WithCancel(context.Background())
-	mgr.Views = viewers
-
-	smgr := viewer.NewStatsMgr(mgr.Ctx)
+	mgr.known = append([]viewer.Viewer(nil), viewers...)
+	mgr.Views = viewers.enabled()
+	mgr.viewErrors = make(map[string]*int64, len(viewers))
 	for _, v := range mgr.Views {
-		v.SetStatsMgr(smgr)
+		mgr.viewErrors[v.Name()] = new(int64)
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/debug/pprof/", pprof.Index)
-	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
-
 	for _, v := range mgr.Views {
-		page.AddCharts(v.View())
-		mux.HandleFunc("/debug/statsview/view/"+v.Name(), v.Serve)
+		if r, ok := v.(viewer.Resolver); ok {
+			if err := r.Resolve(mgr.viewerByName); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	mux.HandleFunc("/debug/statsview", func(w http.ResponseWriter, _ *http.Request) {
-		page.Render(w)
-	})
-
-	staticsPrev := "/debug/statsview/statics/"
-	mux.HandleFunc(staticsPrev+"echarts.min.js", func(w http.ResponseWriter, _ *http.Request) {
-		w.Write([]byte(statics.EchartJS))
-	})
+	mgr.Smgr = viewer.NewStatsMgr(mgr.Ctx)
+	mgr.Smgr.OnPressure(mgr.annotatePressure)
+	mgr.history = newHistory(viewer.HistoryWindow(), viewer.HistoryRetention().MaxSamples)
+	if threshold := viewer.AnomalyThreshold(); threshold > 0 {
+		mgr.anomalies = anomaly.New(threshold)
+	}
+	mgr.notifiers = append(mgr.notifiers, viewer.Notifiers()...)
+	for _, exp := range viewer.Exporters() {
+		mgr.AddExporter(exp, 0)
+	}
+	mgr.limiter = newRateLimiter()
+	for _, v := range mgr.Views {
+		v.SetStatsMgr(mgr.Smgr)
+		v.View().Validate()
+	}
 
-	mux.HandleFunc(staticsPrev+"jquery.min.js", func(w http.ResponseWriter, _ *http.Request) {
-		w.Write([]byte(statics.JqueryJS))
-	})
+	mux := http.NewServeMux()
+	if viewer.PprofEnabled() {
+		// the profiles and the traces are as sensitive as their captures
+		// served by the API
+		mux.HandleFunc("/debug/pprof/", requireAdminRead(pprof.Index))
+		mux.HandleFunc("/debug/pprof/cmdline", requireAdminRead(pprof.Cmdline))
+		mux.HandleFunc("/debug/pprof/profile", requireAdminRead(pprof.Profile))
+		mux.HandleFunc("/debug/pprof/symbol", requireAdminRead(pprof.Symbol))
+		mux.HandleFunc("/debug/pprof/trace", requireAdminRead(pprof.Trace))
+	}
 
-	mux.HandleFunc(staticsPrev+"themes/westeros.js", func(w http.ResponseWriter, _ *http.Request) {
-		w.Write([]byte(statics.WesterosJS))
-	})
+	mgr.handleAPI(mux)
+	mux.HandleFunc("/debug/statsview", mgr.servePage)
+	mux.HandleFunc(EmbedPath, mgr.serveEmbed)
+	mux.HandleFunc("/debug/statsview/lease", mgr.Smgr.ServeLease)
+	mux.HandleFunc("/debug/statsview/healthz", mgr.healthz)
+	mux.HandleFunc("/debug/statsview/readyz", mgr.readyz)
+	mux.HandleFunc("/debug/statsview/goroutines", goroutinesPage)
+	mux.HandleFunc("/debug/statsview/stuck", stuckPage)
+	mux.HandleFunc("/debug/statsview/image/", mgr.serveImage)
+	mux.HandleFunc("/debug/statsview/objects", objectsPage)
+	mux.HandleFunc("/debug/statsview/locks", locksPage)
+	mux.HandleFunc("/debug/statsview/heapdiff", heapDiffPage)
+	mux.HandleFunc("/debug/statsview/flamegraph", flamegraphPage)
+	mux.HandleFunc("/debug/statsview/profiles", profilesPage)
+	mux.HandleFunc("/debug/statsview/grafana/", mgr.grafanaTest)
+	mux.HandleFunc("/debug/statsview/grafana/search", mgr.grafanaSearch)
+	mux.HandleFunc("/debug/statsview/grafana/query", mgr.grafanaQuery)
+	mux.HandleFunc("/debug/statsview/grafana/annotations", mgr.grafanaAnnotations)
 
-	mux.HandleFunc(staticsPrev+"themes/macarons.js", func(w http.ResponseWriter, _ *http.Request) {
-		w.Write([]byte(statics.MacaronsJS))
-	})
+	staticsPrev := "/debug/statsview/statics/"
+	mux.Handle(staticsPrev+"echarts.min.js", statics.JS(statics.EchartJS))
+	mux.Handle(staticsPrev+"jquery.min.js", statics.JS(statics.JqueryJS))
+	mux.Handle(staticsPrev+"flamegraph.js", statics.JS(statics.FlamegraphJS))
+	mux.Handle(staticsPrev+"themes/westeros.js", statics.JS(statics.WesterosJS))
+	mux.Handle(staticsPrev+"themes/macarons.js", statics.JS(statics.MacaronsJS))
 
-	mgr.srv.Handler = cors.AllowAll().Handler(mux)
-	return mgr
+	mgr.srv.Handler = mgr.crossOrigin(securityHeaders(mgr.countServed(requireAllowed(requireRead(mux)))))
+	return mgr, nil
 }
@@ -0,0 +1,35 @@
+package statsview
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestEnabledViewers(t *testing.T) {
+	defer viewer.SetConfiguration(viewer.WithViewers())
+
+	all := Viewers{viewer.NewGoroutinesViewer(), viewer.NewGCNumViewer(), viewer.NewHeapViewer()}
+	tests := []struct {
+		name    string
+		enabled []string
+		want    []string
+	}{
+		{"all", nil, []string{viewer.VGoroutine, viewer.VGCNum, viewer.VHeap}},
+		{"selected", []string{viewer.VHeap, viewer.VGoroutine}, []string{viewer.VGoroutine, viewer.VHeap}},
+		{"unknown", []string{"missing"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viewer.SetConfiguration(viewer.WithViewers(tt.enabled...))
+			got := []string{}
+			for _, v := range all.enabled() {
+				got = append(got, v.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package statsviewtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// Clock is a fake viewer.Clock which only moves when it's told to, its
+// tickers fire as the clock is advanced past their period
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*ticker
+}
+
+// NewClock returns a Clock stopped at t
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a ticker firing every d of the clock
+func (c *Clock) NewTicker(d time.Duration) viewer.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &ticker{clock: c, c: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward by d and fires the tickers due meanwhile.
+// Like time.Ticker, the ticks a slow receiver misses are dropped
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t and fires the tickers due meanwhile
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+	for _, tk := range c.tickers {
+		for tk.period > 0 && !tk.next.After(t) {
+			select {
+			case tk.c <- tk.next:
+			default:
+			}
+			tk.next = tk.next.Add(tk.period)
+		}
+	}
+}
+
+// ticker is a viewer.Ticker of a fake Clock
+type ticker struct {
+	clock  *Clock
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *ticker) Reset(d time.Duration) {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.period, t.next = d, t.clock.now.Add(d)
+}
+
+func (t *ticker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, tk := range t.clock.tickers {
+		if tk == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
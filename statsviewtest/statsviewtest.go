@@ -0,0 +1,187 @@
+// Package statsviewtest helps testing custom viewers without a browser: it
+// starts a ViewManager on a random port, times the collections with a fake
+// clock, feeds synthetic memory statistics and asserts on the metrics served
+// to the charts.
+//
+//	func TestMyViewer(t *testing.T) {
+//		viewers := statsview.NewEmptyViewers()
+//		viewers.Register(NewMyViewer())
+//
+//		s := statsviewtest.Start(t, viewers)
+//		s.SetMemStats(runtime.MemStats{HeapAlloc: 42 << 20})
+//		s.AssertValues(NewMyViewer().Name(), 42)
+//	}
+//
+// The configuration of statsview is global, it's restored once the test is
+// done and the tests using the package mustn't run in parallel.
+package statsviewtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// Epoch is the time the fake clock of a Server starts at
+var Epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Server is a running ViewManager whose time is Clock: the collections are
+// timed with it and the polling loops only tick as it's advanced
+type Server struct {
+	t       testing.TB
+	Manager *statsview.ViewManager
+	Addr    string
+	Clock   *Clock
+	Client  *http.Client
+}
+
+// Start applies opts, starts a ViewManager with viewers on a random port
+// and waits until it serves. The manager runs on a fake clock starting at
+// Epoch, it collects on Refresh or as the clock is advanced past the
+// interval. Everything is torn down with t.Cleanup, the configuration is
+// restored
+func Start(t testing.TB, viewers statsview.Viewers, opts ...viewer.Option) *Server {
+	t.Helper()
+
+	addr, err := freeAddr()
+	if err != nil {
+		t.Fatalf("statsviewtest: %v", err)
+	}
+	clock := NewClock(Epoch)
+	saved := viewer.SaveConfiguration()
+	t.Cleanup(func() { viewer.RestoreConfiguration(saved) })
+	opts = append([]viewer.Option{viewer.WithTimeLocation(time.UTC), viewer.WithClock(clock)}, opts...)
+	if err := viewer.SetConfiguration(append(opts, viewer.WithAddr(addr))...); err != nil {
+		t.Fatalf("statsviewtest: %v", err)
+	}
+
+	mgr, err := statsview.New(viewers)
+	if err != nil {
+		t.Fatalf("statsviewtest: %v", err)
+	}
+	s := &Server{
+		t:       t,
+		Manager: mgr,
+		Addr:    addr,
+		Clock:   clock,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	go mgr.Start()
+	t.Cleanup(mgr.Stop)
+
+	if err := s.waitServing(); err != nil {
+		t.Fatalf("statsviewtest: the dashboard is not served: %v", err)
+	}
+	return s
+}
+
+// freeAddr returns a free localhost address
+func freeAddr() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer ln.Close()
+	return ln.Addr().String(), nil
+}
+
+func (s *Server) waitServing() error {
+	var err error
+	for i := 0; i < 50; i++ {
+		var resp *http.Response
+		if resp, err = s.Client.Get(s.URL("/debug/statsview/healthz")); err == nil {
+			resp.Body.Close()
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return err
+}
+
+// URL returns the URL of path on the server, e.g. `/debug/statsview/view/heap`
+func (s *Server) URL(path string) string {
+	return "http://" + s.Addr + path
+}
+
+// Get returns the body of path, the test fails unless it's answered with 200 OK
+func (s *Server) Get(path string) []byte {
+	s.t.Helper()
+
+	resp, err := s.Client.Get(s.URL(path))
+	if err != nil {
+		s.t.Fatalf("statsviewtest: GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.t.Fatalf("statsviewtest: GET %s: %v", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		s.t.Fatalf("statsviewtest: GET %s answered %s: %s", path, resp.Status, body)
+	}
+	return body
+}
+
+// GetJSON decodes the JSON body of path into v
+func (s *Server) GetJSON(path string, v interface{}) {
+	s.t.Helper()
+	if err := json.Unmarshal(s.Get(path), v); err != nil {
+		s.t.Fatalf("statsviewtest: GET %s: %v", path, err)
+	}
+}
+
+// SetMemStats makes the collections read ms instead of the memory of the
+// process, and collects right away. The collection is timed with Clock
+func (s *Server) SetMemStats(ms runtime.MemStats) {
+	s.Manager.Smgr.SetMemStatsReader(func(dst *runtime.MemStats) {
+		*dst = ms
+	})
+	s.Refresh()
+}
+
+// Refresh collects right away rather than on the next interval
+func (s *Server) Refresh() {
+	s.Manager.Smgr.Refresh()
+}
+
+// Metrics returns the metrics the chart of the viewer is served
+func (s *Server) Metrics(name string) viewer.Metrics {
+	s.t.Helper()
+
+	var m viewer.Metrics
+	s.GetJSON("/debug/statsview/view/"+name, &m)
+	return m
+}
+
+// AssertValues fails the test unless the chart of the viewer is served the
+// values, in the order of its series and in the unit of the chart. Values
+// are compared with a relative tolerance of 1e-9
+func (s *Server) AssertValues(name string, want ...float64) {
+	s.t.Helper()
+
+	got := s.Metrics(name).Values
+	if err := compareValues(got, want); err != nil {
+		s.t.Errorf("statsviewtest: %s: %v, got %v, want %v", name, err, got, want)
+	}
+}
+
+func compareValues(got, want []float64) error {
+	if len(got) != len(want) {
+		return fmt.Errorf("%d values instead of %d", len(got), len(want))
+	}
+	for i := range want {
+		if d := math.Abs(got[i] - want[i]); d > 1e-9*math.Max(math.Abs(want[i]), 1) {
+			return fmt.Errorf("value %d differs", i)
+		}
+	}
+	return nil
+}
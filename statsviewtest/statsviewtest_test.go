@@ -0,0 +1,113 @@
+package statsviewtest
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestClock(t *testing.T) {
+	c := NewClock(Epoch)
+	tests := []struct {
+		name string
+		move func()
+		want time.Time
+	}{
+		{"stopped", func() {}, Epoch},
+		{"advance", func() { c.Advance(time.Minute) }, Epoch.Add(time.Minute)},
+		{"advance again", func() { c.Advance(2 * time.Second) }, Epoch.Add(time.Minute + 2*time.Second)},
+		{"set", func() { c.Set(Epoch.Add(time.Hour)) }, Epoch.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.move()
+			if got := c.Now(); !got.Equal(tt.want) {
+				t.Errorf("Now() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		got, want []float64
+		ok        bool
+	}{
+		{"equal", []float64{1, 2}, []float64{1, 2}, true},
+		{"within the tolerance", []float64{1e12 + 1e-4}, []float64{1e12}, true},
+		{"near zero", []float64{1e-10}, []float64{0}, true},
+		{"different", []float64{1, 2.001}, []float64{1, 2}, false},
+		{"fewer values", []float64{1}, []float64{1, 2}, false},
+		{"more values", []float64{1, 2, 3}, []float64{1, 2}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := compareValues(tt.got, tt.want); (err == nil) != tt.ok {
+				t.Errorf("compareValues() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestServer(t *testing.T) {
+	viewers := statsview.NewEmptyViewers()
+	viewers.Register(viewer.NewHeapViewer(viewer.WithUnit(viewer.UnitMiB)))
+	s := Start(t, viewers, viewer.WithInterval(60000))
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		ms      runtime.MemStats
+		want    []float64
+	}{
+		{"first collection", 0, runtime.MemStats{HeapAlloc: 1 << 20, HeapInuse: 2 << 20, HeapSys: 8 << 20, HeapIdle: 6 << 20, NextGC: 4 << 20}, []float64{1, 2, 8, 6, 4}},
+		{"later collection", time.Minute, runtime.MemStats{HeapAlloc: 3 << 19, HeapInuse: 2 << 20, HeapSys: 8 << 20, HeapIdle: 6 << 20, NextGC: 4 << 20}, []float64{1.5, 2, 8, 6, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.Clock.Advance(tt.advance)
+			s.SetMemStats(tt.ms)
+			s.AssertValues(viewer.VHeap, tt.want...)
+
+			if m := s.Metrics(viewer.VHeap); m.Time != viewer.FormatTime(s.Clock.Now()) {
+				t.Errorf("collected at %s, want the fake clock %s", m.Time, viewer.FormatTime(s.Clock.Now()))
+			}
+		})
+	}
+}
+
+func TestClockTicker(t *testing.T) {
+	c := NewClock(Epoch)
+	tk := c.NewTicker(10 * time.Second)
+	tests := []struct {
+		name string
+		move func()
+		want time.Time
+	}{
+		{"before the period", func() { c.Advance(9 * time.Second) }, time.Time{}},
+		{"at the period", func() { c.Advance(time.Second) }, Epoch.Add(10 * time.Second)},
+		{"missed ticks dropped", func() { c.Advance(35 * time.Second) }, Epoch.Add(20 * time.Second)},
+		{"reset", func() { tk.Reset(time.Minute); c.Advance(59 * time.Second) }, time.Time{}},
+		{"after the reset", func() { c.Advance(time.Second) }, Epoch.Add(105 * time.Second)},
+		{"stopped", func() { tk.Stop(); c.Advance(time.Hour) }, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.move()
+			select {
+			case got := <-tk.C():
+				if !got.Equal(tt.want) {
+					t.Errorf("tick at %v, want %v", got, tt.want)
+				}
+			default:
+				if !tt.want.IsZero() {
+					t.Errorf("no tick, want one at %v", tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package statsview
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// seriesSummary is the aggregate of a recorded series in base units
+type seriesSummary struct {
+	Target string  `json:"target"`
+	Viewer string  `json:"viewer"`
+	Series string  `json:"series"`
+	Count  int     `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Last   float64 `json:"last"`
+}
+
+// summary is the aggregate of the history within [From, To]
+type summary struct {
+	From   time.Time       `json:"from"`
+	To     time.Time       `json:"to"`
+	Series []seriesSummary `json:"series"`
+}
+
+// summarize aggregates the points, NaN and infinite values are skipped
+func summarize(key seriesKey, points []historyPoint) (seriesSummary, bool) {
+	s := seriesSummary{Target: key.String(), Viewer: key.Viewer, Series: key.Series}
+	var mean, m2 float64
+	for _, p := range points {
+		v := p.Value
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		if s.Count == 0 || v < s.Min {
+			s.Min = v
+		}
+		if s.Count == 0 || v > s.Max {
+			s.Max = v
+		}
+		// Welford's online variance, stable for the large byte counts
+		s.Count++
+		d := v - mean
+		mean += d / float64(s.Count)
+		m2 += d * (v - mean)
+		s.Last = v
+	}
+	if s.Count == 0 {
+		return s, false
+	}
+	s.Mean, s.StdDev = mean, math.Sqrt(m2/float64(s.Count))
+	return s, true
+}
+
+// serveSummary returns the min, max, mean, standard deviation and last value
+// of every recorded series, e.g. for a CI script gating on the max heap of an
+// integration test. `last` bounds the history aggregated, `series` keeps the
+// targets containing one of its values, e.g. `?series=heap.&series=goroutine`
+func (vm *ViewManager) serveSummary(w http.ResponseWriter, r *http.Request) {
+	window := vm.historyWindow()
+	if window <= 0 {
+		http.Error(w, "statsview: the history is disabled, see viewer.WithHistory", http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	if s := q.Get("last"); s != "" {
+		last, err := time.ParseDuration(s)
+		if err != nil || last <= 0 {
+			http.Error(w, "statsview: invalid last duration", http.StatusBadRequest)
+			return
+		}
+		window = last
+	}
+
+	to := vm.Smgr.Now()
+	res := summary{From: to.Add(-window), To: to, Series: []seriesSummary{}}
+	for _, k := range vm.history.keys() {
+		if !matchesAny(k.String(), q["series"]) {
+			continue
+		}
+		if s, ok := summarize(k, vm.history.query(k, res.From, res.To)); ok {
+			res.Series = append(res.Series, s)
+		}
+	}
+	writeData(w, r, res)
+}
+
+// matchesAny reports whether target contains one of the patterns, any target
+// matches without pattern
+func matchesAny(target string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if strings.Contains(target, p) {
+			return true
+		}
+	}
+	return false
+}
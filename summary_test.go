@@ -0,0 +1,124 @@
+package statsview
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestSummarize(t *testing.T) {
+	key := seriesKey{"heap", "HeapAlloc"}
+	pointsOf := func(values ...float64) []historyPoint {
+		ps := make([]historyPoint, len(values))
+		for i, v := range values {
+			ps[i] = historyPoint{Time: time.Unix(int64(i), 0), Value: v}
+		}
+		return ps
+	}
+	tests := []struct {
+		name   string
+		points []historyPoint
+		want   seriesSummary
+		ok     bool
+	}{
+		{"no point", nil, seriesSummary{}, false},
+		{"only invalid values", pointsOf(math.NaN(), math.Inf(1)), seriesSummary{}, false},
+		{"one point", pointsOf(7), seriesSummary{Count: 1, Min: 7, Max: 7, Mean: 7, Last: 7}, true},
+		{"several", pointsOf(2, 4, 4, 4, 5, 5, 7, 9), seriesSummary{Count: 8, Min: 2, Max: 9, Mean: 5, StdDev: 2, Last: 9}, true},
+		{"invalid values skipped", pointsOf(1, math.NaN(), 3), seriesSummary{Count: 2, Min: 1, Max: 3, Mean: 2, StdDev: 1, Last: 3}, true},
+		{"large values", pointsOf(1<<40+1, 1<<40+3), seriesSummary{Count: 2, Min: 1<<40 + 1, Max: 1<<40 + 3, Mean: 1<<40 + 2, StdDev: 1, Last: 1<<40 + 3}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := summarize(key, tt.points)
+			if ok != tt.ok {
+				t.Fatalf("summarize() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			tt.want.Target, tt.want.Viewer, tt.want.Series = "heap.HeapAlloc", "heap", "HeapAlloc"
+			if got != tt.want {
+				t.Errorf("summarize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeSummary(t *testing.T) {
+	viewer.SetConfiguration(viewer.WithHistory(time.Hour))
+	defer viewer.SetConfiguration(viewer.WithHistory(0))
+
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	now := time.Now()
+	for _, ago := range []time.Duration{30 * time.Minute, 2 * time.Minute, time.Minute} {
+		v := float64(ago / time.Minute)
+		mgr.history.record([]viewer.Point{
+			{Viewer: "heap", Series: "HeapAlloc", Value: v, Time: now.Add(-ago)},
+			{Viewer: "app", Series: "Requests", Value: 10 * v, Time: now.Add(-ago)},
+		})
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   map[string][2]float64 // target: count, max
+	}{
+		{"all", "", http.StatusOK, map[string][2]float64{"app.Requests": {3, 300}, "heap.HeapAlloc": {3, 30}}},
+		{"last", "?last=5m", http.StatusOK, map[string][2]float64{"app.Requests": {2, 20}, "heap.HeapAlloc": {2, 2}}},
+		{"series", "?series=heap.", http.StatusOK, map[string][2]float64{"heap.HeapAlloc": {3, 30}}},
+		{"several series", "?series=heap.&series=Requests&last=90s", http.StatusOK, map[string][2]float64{"app.Requests": {1, 10}, "heap.HeapAlloc": {1, 1}}},
+		{"no match", "?series=missing", http.StatusOK, map[string][2]float64{}},
+		{"invalid last", "?last=soon", http.StatusBadRequest, nil},
+		{"negative last", "?last=-5m", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/summary"+tt.query, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.want == nil {
+				return
+			}
+			var res summary
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string][2]float64)
+			for _, s := range res.Series {
+				got[s.Target] = [2]float64{float64(s.Count), s.Max}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summary = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeSummaryWithoutHistory(t *testing.T) {
+	viewer.SetConfiguration(viewer.WithHistory(0))
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	rec := httptest.NewRecorder()
+	mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/summary", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
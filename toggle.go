@@ -0,0 +1,137 @@
+package statsview
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+var errNoToggleSignal = errors.New("statsview: a toggle signal is required on this platform")
+
+// SignalToggle starts the dashboard on a signal and stops it on the next
+// one, the process doesn't pay for the listener and the collection until
+// someone needs to debug it
+type SignalToggle struct {
+	viewers func() Viewers
+	sigs    chan os.Signal
+	done    chan struct{}
+
+	mu      sync.Mutex
+	mgr     *ViewManager
+	onStart func(*ViewManager)
+}
+
+// EnableSignalToggle toggles a ViewManager on every sig, SIGUSR1 by default
+// (there is no default on windows). Every start gets fresh viewers of the
+// viewers function, e.g. NewDefaultViewers, since the viewers of a stopped
+// manager are closed
+//
+//	$ kill -USR1 <pid>   # start the dashboard
+//	$ kill -USR1 <pid>   # stop it again
+func EnableSignalToggle(viewers func() Viewers, sig ...os.Signal) (*SignalToggle, error) {
+	if len(sig) == 0 {
+		if defaultToggleSignal == nil {
+			return nil, errNoToggleSignal
+		}
+		sig = []os.Signal{defaultToggleSignal}
+	}
+	if err := viewer.ValidateTemplate(viewer.Template()); err != nil {
+		return nil, err
+	}
+
+	t := &SignalToggle{
+		viewers: viewers,
+		sigs:    make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	signal.Notify(t.sigs, sig...)
+	go t.loop()
+	return t, nil
+}
+
+// OnStart sets a function called with every new ViewManager before it
+// starts, e.g. to add exporters or registrars
+func (t *SignalToggle) OnStart(fn func(*ViewManager)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onStart = fn
+}
+
+// Manager returns the running ViewManager, nil while the dashboard is off
+func (t *SignalToggle) Manager() *ViewManager {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.mgr
+}
+
+// Toggle starts the dashboard when it's off and stops it otherwise, as the
+// signal does. It fails when the ViewManager couldn't be created, a failure
+// to start it is logged and the dashboard is off again
+func (t *SignalToggle) Toggle() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.mgr != nil {
+		t.mgr.Stop()
+		t.mgr = nil
+		return nil
+	}
+
+	// a stopped manager couldn't be restarted, every start gets a new one
+	mgr, err := New(t.viewers())
+	if err != nil {
+		return err
+	}
+	if t.onStart != nil {
+		t.onStart(mgr)
+	}
+	t.mgr = mgr
+	go t.start(mgr)
+	return nil
+}
+
+// start runs mgr until it's stopped, the toggle is reset when it fails
+func (t *SignalToggle) start(mgr *ViewManager) {
+	err := mgr.Start()
+	if err == nil || errors.Is(err, http.ErrServerClosed) {
+		return
+	}
+	viewer.Logger().Error("statsview: failed to start the toggled dashboard", "err", err)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mgr == mgr {
+		mgr.Stop()
+		t.mgr = nil
+	}
+}
+
+// Disable stops handling the signal and stops the dashboard if it's running
+func (t *SignalToggle) Disable() {
+	signal.Stop(t.sigs)
+	close(t.done)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mgr != nil {
+		t.mgr.Stop()
+		t.mgr = nil
+	}
+}
+
+func (t *SignalToggle) loop() {
+	for {
+		select {
+		case <-t.sigs:
+			if err := t.Toggle(); err != nil {
+				viewer.Logger().Error("statsview: failed to toggle the dashboard", "err", err)
+			}
+		case <-t.done:
+			return
+		}
+	}
+}
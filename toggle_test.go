@@ -0,0 +1,115 @@
+package statsview
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestToggle(t *testing.T) {
+	defer viewer.RestoreConfiguration(viewer.SaveConfiguration())
+	viewer.SetConfiguration(viewer.WithAddr("127.0.0.1:0"))
+
+	tests := []struct {
+		name    string
+		toggles int
+		running bool
+		starts  int
+	}{
+		{"off", 0, false, 0},
+		{"started", 1, true, 1},
+		{"stopped", 2, false, 1},
+		{"restarted", 3, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toggle := &SignalToggle{viewers: func() Viewers { return Viewers{viewer.NewGoroutinesViewer()} }, done: make(chan struct{})}
+			var started []*ViewManager
+			toggle.OnStart(func(mgr *ViewManager) { started = append(started, mgr) })
+
+			for i := 0; i < tt.toggles; i++ {
+				if err := toggle.Toggle(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if running := toggle.Manager() != nil; running != tt.running {
+				t.Errorf("running = %v, want %v", running, tt.running)
+			}
+			if len(started) != tt.starts {
+				t.Errorf("started %d managers, want %d", len(started), tt.starts)
+			}
+			if tt.starts == 2 && started[0] == started[1] {
+				t.Error("the stopped manager was restarted")
+			}
+
+			toggle.Disable()
+			if toggle.Manager() != nil {
+				t.Error("Disable() didn't stop the dashboard")
+			}
+		})
+	}
+}
+
+func TestSignalToggle(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	tests := []struct {
+		name    string
+		addr    string
+		running bool
+	}{
+		{"started", "127.0.0.1:0", true},
+		{"address in use", ln.Addr().String(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer viewer.RestoreConfiguration(viewer.SaveConfiguration())
+			viewer.SetConfiguration(viewer.WithAddr(tt.addr))
+
+			var built []viewer.Viewer
+			toggle := &SignalToggle{viewers: func() Viewers {
+				v := viewer.NewGoroutinesViewer()
+				built = append(built, v)
+				return Viewers{v}
+			}}
+			defer func() {
+				if mgr := toggle.Manager(); mgr != nil {
+					mgr.Stop()
+				}
+			}()
+
+			if err := toggle.Toggle(); err != nil {
+				t.Fatal(err)
+			}
+			// a failure to start resets the toggle in the background
+			deadline := time.Now().Add(5 * time.Second)
+			for !tt.running && toggle.Manager() != nil && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if running := toggle.Manager() != nil; running != tt.running {
+				t.Fatalf("running = %v, want %v", running, tt.running)
+			}
+
+			if tt.running {
+				if err := toggle.Toggle(); err != nil {
+					t.Fatal(err)
+				}
+				if toggle.Manager() != nil {
+					t.Fatal("the dashboard wasn't stopped")
+				}
+			}
+			if err := toggle.Toggle(); err != nil {
+				t.Fatal(err)
+			}
+			if len(built) != 2 || built[0] == built[1] {
+				t.Errorf("built %d viewers, want fresh viewers for each of the 2 managers", len(built))
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package statsview
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultToggleSignal os.Signal = syscall.SIGUSR1
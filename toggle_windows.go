@@ -0,0 +1,8 @@
+//go:build windows
+
+package statsview
+
+import "os"
+
+// windows has no user defined signal to default to
+var defaultToggleSignal os.Signal
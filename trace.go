@@ -0,0 +1,50 @@
+package statsview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+var errFlightRecorderDisabled = errors.New("statsview: flight recorder is disabled, see viewer.WithFlightRecorder")
+
+// DumpTrace writes the execution trace of the last flight recorder window
+// into w. It's meant to be called at the moment something goes wrong, e.g.
+// from an alert hook, to capture what happened right before
+func (vm *ViewManager) DumpTrace(w io.Writer) error {
+	vm.recorderMu.Lock()
+	defer vm.recorderMu.Unlock()
+
+	if vm.recorder == nil {
+		return errFlightRecorderDisabled
+	}
+	return vm.recorder.writeTo(w)
+}
+
+// flightTrace downloads the trace of the flight recorder. The trace is
+// dumped into a buffer first, so a slow download doesn't hold the recorder
+// and a failed dump is answered with a 500 before anything is written
+func (vm *ViewManager) flightTrace(w http.ResponseWriter, _ *http.Request) {
+	var buf bytes.Buffer
+	if err := vm.DumpTrace(&buf); err != nil {
+		if errors.Is(err, errFlightRecorderDisabled) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		viewer.Logger().Error("statsview: failed to dump flight recorder", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="flight-%s.trace"`, time.Now().Format("20060102-150405")))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	buf.WriteTo(w)
+}
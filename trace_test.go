@@ -0,0 +1,106 @@
+package statsview
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlightTrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		status  int
+		err     error
+	}{
+		{"disabled", false, http.StatusNotFound, errFlightRecorderDisabled},
+		{"enabled", true, http.StatusOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := &ViewManager{}
+			if tt.enabled {
+				recorder, err := newFlightRecorder(time.Second)
+				if err != nil {
+					t.Skip(err)
+				}
+				defer recorder.stop()
+				vm.recorder = recorder
+			}
+
+			var buf bytes.Buffer
+			if err := vm.DumpTrace(&buf); !errors.Is(err, tt.err) {
+				t.Fatalf("DumpTrace() = %v, want %v", err, tt.err)
+			}
+			if tt.enabled && buf.Len() == 0 {
+				t.Error("DumpTrace() wrote no trace")
+			}
+
+			rec := httptest.NewRecorder()
+			vm.flightTrace(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/trace/flight", nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.enabled {
+				if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="flight-`) {
+					t.Errorf("Content-Disposition = %q", cd)
+				}
+				if rec.Body.Len() == 0 {
+					t.Error("no trace served")
+				}
+			}
+		})
+	}
+}
+
+// stalledWriter is a client not reading the response until it's released
+type stalledWriter struct {
+	*httptest.ResponseRecorder
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (w *stalledWriter) Write(p []byte) (int, error) {
+	close(w.writing)
+	<-w.release
+	return w.ResponseRecorder.Write(p)
+}
+
+func TestFlightTraceStalledClient(t *testing.T) {
+	recorder, err := newFlightRecorder(time.Second)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer recorder.stop()
+	vm := &ViewManager{recorder: recorder}
+
+	w := &stalledWriter{httptest.NewRecorder(), make(chan struct{}), make(chan struct{})}
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		vm.flightTrace(w, httptest.NewRequest(http.MethodGet, "/debug/statsview/trace/flight", nil))
+	}()
+	<-w.writing
+
+	dumped := make(chan error, 1)
+	go func() { dumped <- vm.DumpTrace(io.Discard) }()
+	select {
+	case err := <-dumped:
+		if err != nil {
+			t.Errorf("DumpTrace() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("DumpTrace() blocked by the download")
+	}
+
+	close(w.release)
+	<-served
+	if w.Code != http.StatusOK || w.Body.Len() == 0 {
+		t.Errorf("status = %d, %d bytes served", w.Code, w.Body.Len())
+	}
+}
@@ -0,0 +1,57 @@
+package statsview
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mortum5/statsview/internal/msgpack"
+	"github.com/mortum5/statsview/viewer"
+)
+
+var errInvalidJSON = errors.New("statsview: the viewer wrote invalid JSON")
+
+// serveAll serves the latest metrics of every viewer keyed by the viewer name,
+// so the dashboard polls once per interval instead of once per chart. Skipped
+// and failing viewers are left out. The values of the viewers are encoded
+// once, in the format the client accepts
+func (vm *ViewManager) serveAll(w http.ResponseWriter, r *http.Request) {
+	views := vm.views()
+	packed := acceptsMsgpack(r)
+	results := make(map[string]interface{}, len(views))
+	for _, v := range views {
+		if vm.skipped(v) {
+			continue
+		}
+
+		resp := vm.safeServe(v, r)
+		if resp.Status() >= http.StatusInternalServerError {
+			vm.countError(v.Name())
+		}
+		if resp.Status() != http.StatusOK {
+			continue
+		}
+		result, err := encodeResult(resp, packed)
+		if err != nil {
+			viewer.Logger().Error("statsview: failed to encode the metrics", "viewer", v.Name(), "err", err)
+			vm.countError(v.Name())
+			continue
+		}
+		results[v.Name()] = result
+	}
+	writeData(w, r, results)
+}
+
+// encodeResult encodes the response of a viewer to be embedded as it is in
+// the response of serveAll
+func encodeResult(resp *viewer.ResponseBuffer, packed bool) (interface{}, error) {
+	if packed {
+		bs, err := encodeMsgpack(resp)
+		return msgpack.Raw(bs), err
+	}
+	bs, err := resp.JSON()
+	if err == nil && !json.Valid(bs) {
+		err = errInvalidJSON
+	}
+	return json.RawMessage(bs), err
+}
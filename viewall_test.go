@@ -0,0 +1,133 @@
+package statsview
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+
+	"github.com/mortum5/statsview/internal/msgpack"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// stubViewer answers every request with a fixed status and body
+type stubViewer struct {
+	name   string
+	status int
+	body   string
+}
+
+func (vr *stubViewer) Name() string                      { return vr.name }
+func (vr *stubViewer) View() *charts.Line                { return viewer.NewBasicView(vr.name) }
+func (vr *stubViewer) SetStatsMgr(smgr *viewer.StatsMgr) {}
+
+func (vr *stubViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(vr.status)
+	w.Write([]byte(vr.body))
+}
+
+// valueViewer answers every request with its metrics
+type valueViewer struct {
+	stubViewer
+	m viewer.Metrics
+}
+
+func (vr *valueViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	viewer.WriteJSON(w, vr.m)
+}
+
+func TestServeAll(t *testing.T) {
+	mgr, err := New(Viewers{
+		&stubViewer{name: "ok", status: http.StatusOK, body: `{"values":[1,2],"time":"12:00:00"}`},
+		&stubViewer{name: "invalid", status: http.StatusOK, body: `{"values":`},
+		&stubViewer{name: "empty", status: http.StatusNoContent},
+		&stubViewer{name: "failed", status: http.StatusInternalServerError, body: `{"error":"boom"}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		name   string
+		errors int64
+	}{
+		{"first poll", 1},
+		{"second poll", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mgr.serveAll(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/view/all", nil))
+
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %s, want application/json", ct)
+			}
+			var results map[string]viewer.Metrics
+			if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || len(results["ok"].Values) != 2 || results["ok"].Time != "12:00:00" {
+				t.Errorf("results = %+v, want the ok viewer only", results)
+			}
+			if got := *mgr.viewErrors["failed"]; got != tt.errors {
+				t.Errorf("errors = %d, want %d", got, tt.errors)
+			}
+		})
+	}
+}
+
+func TestServeAllEncoding(t *testing.T) {
+	m := viewer.Metrics{Values: []float64{3}, Time: "12:00:00"}
+	mgr, err := New(Viewers{
+		&stubViewer{name: "ok", status: http.StatusOK, body: `{"values":[1,2],"time":"12:00:00"}`},
+		&valueViewer{stubViewer: stubViewer{name: "value"}, m: m},
+		&stubViewer{name: "failed", status: http.StatusInternalServerError, body: `{"error":"boom"}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	jsonBody, err := json.Marshal(map[string]interface{}{"ok": json.RawMessage(`{"values":[1,2],"time":"12:00:00"}`), "value": m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := msgpack.Marshal(map[string]interface{}{
+		"ok":    map[string]interface{}{"values": []interface{}{1.0, 2.0}, "time": "12:00:00"},
+		"value": m,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		want        []byte
+	}{
+		{"json", "", "application/json", jsonBody},
+		{"msgpack", ContentTypeMsgpack, ContentTypeMsgpack, packed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/debug/statsview/view/all", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			mgr.serveAll(rec, r)
+
+			if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("Content-Type = %s, want %s", ct, tt.contentType)
+			}
+			if !bytes.Equal(rec.Body.Bytes(), tt.want) {
+				t.Errorf("body = %q, want %q", rec.Body.Bytes(), tt.want)
+			}
+		})
+	}
+}
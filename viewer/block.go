@@ -0,0 +1,73 @@
+package viewer
+
+import (
+	"net/http"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VBlock is the name of BlockViewer
+	VBlock = "block"
+)
+
+// BlockViewer collects the blocking contention metrics via the `block` profile.
+// The profile is empty unless `runtime.SetBlockProfileRate` has been called
+type BlockViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
+}
+
+// NewBlockViewer returns the BlockViewer instance
+// Series: Events / Delay
+func NewBlockViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(append([]ViewerOption{WithSecondaryAxis("Delay")}, vopts...))
+	unit := o.unitOf(VBlock, DimensionSeconds)
+	graph := o.newBasicView(VBlock)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Block Contention"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Total"}),
+	)
+	graph.AddSeries("Events", []opts.LineData{}).
+		AddSeries("Delay", []opts.LineData{})
+
+	o.apply(graph)
+	formatSeries(graph, unit, DimensionCount, map[string]Dimension{"Delay": DimensionSeconds})
+	return &BlockViewer{graph: graph, opts: o, unit: unit}
+}
+
+func (vr *BlockViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *BlockViewer) Name() string {
+	return VBlock
+}
+
+func (vr *BlockViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Priority is low since reading the profile is expensive
+func (vr *BlockViewer) Priority() Priority {
+	return PriorityLow
+}
+
+// Collect returns the total number of contention events and delay in seconds
+func (vr *BlockViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+	c := readContention("block")
+	return []Point{
+		{Viewer: VBlock, Series: "Events", Value: float64(c.Count), Time: t},
+		{Viewer: VBlock, Series: "Delay", Value: c.Delay, Time: t},
+	}
+}
+
+func (vr *BlockViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := contentionMetrics(vr.Collect(), vr.unit, vr.opts)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,202 @@
+package viewer
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroup locates the memory and cpu controllers of the current process,
+// both cgroup v1 and the unified v2 hierarchy are supported
+type cgroup struct {
+	v2      bool
+	memDir  string
+	cpuDir  string
+	acctDir string
+}
+
+var (
+	cgroupOnce sync.Once
+	cgroupSelf *cgroup
+)
+
+// selfCgroup returns the cgroup of the process, nil outside of a cgroup (or Linux)
+func selfCgroup() *cgroup {
+	cgroupOnce.Do(func() {
+		cgroupSelf = detectCgroup("/proc/self/cgroup")
+	})
+	return cgroupSelf
+}
+
+func detectCgroup(path string) *cgroup {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	_, err = os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers"))
+	cg := &cgroup{v2: err == nil}
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(sc.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if cg.v2 {
+			if parts[0] == "0" {
+				cg.memDir = cgroupDir(cgroupRoot, parts[2])
+				cg.cpuDir, cg.acctDir = cg.memDir, cg.memDir
+			}
+			continue
+		}
+		for _, c := range strings.Split(parts[1], ",") {
+			switch c {
+			case "memory":
+				cg.memDir = cgroupDir(filepath.Join(cgroupRoot, "memory"), parts[2])
+			case "cpu":
+				cg.cpuDir = cgroupDir(filepath.Join(cgroupRoot, parts[1]), parts[2])
+			case "cpuacct":
+				cg.acctDir = cgroupDir(filepath.Join(cgroupRoot, parts[1]), parts[2])
+			}
+		}
+	}
+	if cg.memDir == "" && cg.cpuDir == "" && cg.acctDir == "" {
+		return nil
+	}
+	return cg
+}
+
+// cgroupDir joins the mount point with the cgroup path, without a cgroup
+// namespace the path is the one of the host and doesn't exist in the
+// container, the mount point itself is the cgroup then
+func cgroupDir(mount, path string) string {
+	dir := filepath.Join(mount, path)
+	if _, err := os.Stat(dir); err != nil {
+		return mount
+	}
+	return dir
+}
+
+func readCgroupFile(dir, name string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	bs, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(bs)), true
+}
+
+func readCgroupFloat(dir, name string) (float64, bool) {
+	s, ok := readCgroupFile(dir, name)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// memoryLimit returns the memory limit in bytes, 0 when unlimited
+func (cg *cgroup) memoryLimit() float64 {
+	if cg.v2 {
+		v, _ := readCgroupFloat(cg.memDir, "memory.max")
+		return v
+	}
+	v, _ := readCgroupFloat(cg.memDir, "memory.limit_in_bytes")
+	// v1 reports "unlimited" as a huge page aligned number
+	if v >= 1<<62 {
+		return 0
+	}
+	return v
+}
+
+// memoryUsage returns the memory charged to the cgroup in bytes
+func (cg *cgroup) memoryUsage() (float64, bool) {
+	if cg.v2 {
+		return readCgroupFloat(cg.memDir, "memory.current")
+	}
+	return readCgroupFloat(cg.memDir, "memory.usage_in_bytes")
+}
+
+// cpuLimit returns the CPU quota in cores, 0 when unlimited
+func (cg *cgroup) cpuLimit() float64 {
+	if cg.v2 {
+		s, ok := readCgroupFile(cg.cpuDir, "cpu.max")
+		fields := strings.Fields(s)
+		if !ok || len(fields) != 2 || fields[0] == "max" {
+			return 0
+		}
+		quota, err1 := strconv.ParseFloat(fields[0], 64)
+		period, err2 := strconv.ParseFloat(fields[1], 64)
+		if err1 != nil || err2 != nil || period <= 0 {
+			return 0
+		}
+		return quota / period
+	}
+
+	quota, ok1 := readCgroupFloat(cg.cpuDir, "cpu.cfs_quota_us")
+	period, ok2 := readCgroupFloat(cg.cpuDir, "cpu.cfs_period_us")
+	if !ok1 || !ok2 || quota <= 0 || period <= 0 {
+		return 0
+	}
+	return quota / period
+}
+
+// cpuUsage returns the CPU time consumed by the cgroup in seconds
+func (cg *cgroup) cpuUsage() (float64, bool) {
+	if cg.v2 {
+		s, ok := readCgroupFile(cg.cpuDir, "cpu.stat")
+		if !ok {
+			return 0, false
+		}
+		for _, line := range strings.Split(s, "\n") {
+			if v, found := strings.CutPrefix(line, "usage_usec "); found {
+				us, err := strconv.ParseFloat(v, 64)
+				return us / 1e6, err == nil
+			}
+		}
+		return 0, false
+	}
+	ns, ok := readCgroupFloat(cg.acctDir, "cpuacct.usage")
+	return ns / 1e9, ok
+}
+
+// ContainerLimits returns the memory limit in bytes and the CPU limit in
+// cores of the cgroup the process runs in, 0 means unlimited or unknown
+func ContainerLimits() (memory, cpu float64) {
+	cg := selfCgroup()
+	if cg == nil {
+		return 0, 0
+	}
+	return cg.memoryLimit(), cg.cpuLimit()
+}
+
+// hostMemory returns MemTotal of `/proc/meminfo` in bytes, 0 if unknown
+func hostMemory() float64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if v, found := strings.CutPrefix(sc.Text(), "MemTotal:"); found {
+			kb, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), " kB"), 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
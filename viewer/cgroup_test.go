@@ -0,0 +1,122 @@
+package viewer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cgroupFiles writes the files of a cgroup into a temporary directory
+func cgroupFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCgroupLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		v2       bool
+		files    map[string]string
+		memLimit float64
+		memUsage float64
+		cpuLimit float64
+		cpuUsage float64
+	}{
+		{
+			name: "v2",
+			v2:   true,
+			files: map[string]string{
+				"memory.max":     "536870912",
+				"memory.current": "104857600",
+				"cpu.max":        "150000 100000",
+				"cpu.stat":       "usage_usec 2500000\nuser_usec 2000000\nsystem_usec 500000",
+			},
+			memLimit: 512 << 20, memUsage: 100 << 20, cpuLimit: 1.5, cpuUsage: 2.5,
+		},
+		{
+			name:     "v2 unlimited",
+			v2:       true,
+			files:    map[string]string{"memory.max": "max", "memory.current": "4096", "cpu.max": "max 100000"},
+			memLimit: 0, memUsage: 4096, cpuLimit: 0,
+		},
+		{
+			name: "v1",
+			files: map[string]string{
+				"memory.limit_in_bytes": "1073741824",
+				"memory.usage_in_bytes": "2048",
+				"cpu.cfs_quota_us":      "50000",
+				"cpu.cfs_period_us":     "100000",
+				"cpuacct.usage":         "3000000000",
+			},
+			memLimit: 1 << 30, memUsage: 2048, cpuLimit: 0.5, cpuUsage: 3,
+		},
+		{
+			name:     "v1 unlimited",
+			files:    map[string]string{"memory.limit_in_bytes": "9223372036854771712", "cpu.cfs_quota_us": "-1", "cpu.cfs_period_us": "100000"},
+			memLimit: 0, cpuLimit: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := cgroupFiles(t, tt.files)
+			cg := &cgroup{v2: tt.v2, memDir: dir, cpuDir: dir, acctDir: dir}
+			if got := cg.memoryLimit(); got != tt.memLimit {
+				t.Errorf("memoryLimit() = %v, want %v", got, tt.memLimit)
+			}
+			if got, _ := cg.memoryUsage(); got != tt.memUsage {
+				t.Errorf("memoryUsage() = %v, want %v", got, tt.memUsage)
+			}
+			if got := cg.cpuLimit(); got != tt.cpuLimit {
+				t.Errorf("cpuLimit() = %v, want %v", got, tt.cpuLimit)
+			}
+			if got, _ := cg.cpuUsage(); got != tt.cpuUsage {
+				t.Errorf("cpuUsage() = %v, want %v", got, tt.cpuUsage)
+			}
+		})
+	}
+}
+
+func TestDetectCgroup(t *testing.T) {
+	// the hierarchy read is the one mounted on the host
+	_, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers"))
+	v2 := err == nil
+
+	tests := []struct {
+		name    string
+		content string
+		v1, v2  bool
+	}{
+		{name: "no file"},
+		{name: "garbage", content: "garbage\n"},
+		{name: "v1 memory", content: "4:memory:/docker/abc\n", v1: true},
+		{name: "v1 cpu", content: "3:cpu,cpuacct:/docker/abc\n", v1: true},
+		{name: "v2 unified", content: "0::/system.slice/app.service\n", v2: true},
+		{name: "both", content: "4:memory:/docker/abc\n0::/docker/abc\n", v1: true, v2: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cgroup")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := tt.v1
+			if v2 {
+				want = tt.v2
+			}
+			cg := detectCgroup(path)
+			if (cg != nil) != want {
+				t.Fatalf("detectCgroup() = %+v, want found %v", cg, want)
+			}
+			if cg != nil && cg.v2 != v2 {
+				t.Errorf("v2 = %v, want %v", cg.v2, v2)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package viewer
+
+import (
+	"time"
+)
+
+// Clock is the time source of the collection: the time of the collections,
+// the leases keeping it running and the tickers of the polling loops. A fake
+// clock set by WithClock lets tests advance the time instead of sleeping
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers the ticks of a Clock like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+// systemClock is the Clock of the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// SystemClock is the default Clock, it reads the time of the system
+var SystemClock Clock = systemClock{}
+
+// WithClock sets the Clock of the managers created afterwards, e.g. the
+// fake clock of statsviewtest. nil restores SystemClock
+func WithClock(c Clock) Option {
+	return func(cfg *config) {
+		cfg.Clock = c
+	}
+}
+
+// CurrentClock returns the Clock set by WithClock or SystemClock
+func CurrentClock() Clock {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	if defaultCfg.Clock == nil {
+		return SystemClock
+	}
+	return defaultCfg.Clock
+}
+
+// Now returns the time of the Clock of the manager
+func (s *StatsMgr) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Clock returns the Clock of the manager, the loops collecting on its
+// behalf tick with it
+func (s *StatsMgr) Clock() Clock {
+	return s.clock
+}
@@ -0,0 +1,107 @@
+package viewer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// manualClock is a Clock moved by hand whose tickers fire on Tick
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+	c   chan time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) NewTicker(time.Duration) Ticker {
+	return manualTicker{c.c}
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type manualTicker struct {
+	c chan time.Time
+}
+
+func (t manualTicker) C() <-chan time.Time { return t.c }
+func (t manualTicker) Reset(time.Duration) {}
+func (t manualTicker) Stop()               {}
+
+func TestCurrentClock(t *testing.T) {
+	defer func(c Clock) { defaultCfg.Clock = c }(defaultCfg.Clock)
+
+	fake := &manualClock{}
+	tests := []struct {
+		name  string
+		clock Clock
+		want  Clock
+	}{
+		{"fake", fake, fake},
+		{"restored", nil, SystemClock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetConfiguration(WithClock(tt.clock))
+			if got := CurrentClock(); got != tt.want {
+				t.Errorf("CurrentClock() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsMgrClock(t *testing.T) {
+	defer func(c Clock) { defaultCfg.Clock = c }(defaultCfg.Clock)
+	epoch := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := &manualClock{now: epoch, c: make(chan time.Time)}
+	SetConfiguration(WithClock(clock))
+
+	s := NewStatsMgr(context.Background())
+	defer s.Cancel()
+	if s.Clock() != clock || !s.Now().Equal(epoch) {
+		t.Fatalf("the manager runs on %v at %v, want the fake clock at %v", s.Clock(), s.Now(), epoch)
+	}
+
+	tests := []struct {
+		name       string
+		advance    time.Duration
+		lease      time.Duration
+		collecting bool
+	}{
+		{"leased", 0, time.Minute, true},
+		{"still leased", 59 * time.Second, 0, true},
+		{"expired", 2 * time.Second, 0, false},
+		{"leased again", time.Hour, 10 * time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			if tt.lease > 0 {
+				s.Lease("client", tt.lease)
+			}
+			if got := s.Collecting(); got != tt.collecting {
+				t.Errorf("Collecting() = %v, want %v", got, tt.collecting)
+			}
+		})
+	}
+
+	// a tick of the fake clock collects at the time of the clock
+	clock.c <- clock.Now()
+	clock.c <- clock.Now() // the first tick is handled once the second is received
+	if got := s.CollectTime(); !got.Equal(clock.Now()) {
+		t.Errorf("CollectTime() = %v, want %v", got, clock.Now())
+	}
+	if got := s.LastPoll(); !got.Equal(clock.Now()) {
+		t.Errorf("LastPoll() = %v, want %v", got, clock.Now())
+	}
+}
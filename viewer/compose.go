@@ -0,0 +1,223 @@
+package viewer
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// SeriesRef is a series of another viewer drawn on a composed chart
+type SeriesRef struct {
+	viewer string
+	series string
+	label  string
+	dim    Dimension
+	source source
+}
+
+// source is a viewer or a collector referenced directly
+type source interface {
+	Name() string
+	SetStatsMgr(smgr *StatsMgr)
+}
+
+// Ref references the series of the viewer registered under the name, e.g.
+// `Ref(VGoroutine, "Goroutines")`. It's drawn as `viewer.series`
+func Ref(viewer, series string) SeriesRef {
+	return SeriesRef{viewer: viewer, series: series, label: viewer + "." + series, dim: DimensionCount}
+}
+
+// RefOf references the series of v, which doesn't need to be registered
+func RefOf(v Viewer, series string) SeriesRef {
+	r := Ref(v.Name(), series)
+	r.source = v
+	return r
+}
+
+// CollectorRef references the series of c, which renders no chart of its
+// own. A collector referenced by several viewers feeds all of their charts,
+// e.g. a line and a bar chart of the same series
+func CollectorRef(c Collector, series string) SeriesRef {
+	r := Ref(c.Name(), series)
+	r.source = c
+	return r
+}
+
+// As names the series on the composed chart
+func (r SeriesRef) As(label string) SeriesRef {
+	r.label = label
+	return r
+}
+
+// In sets the dimension of the series for its labels and tooltip, e.g.
+// DimensionBytes for a heap size, DimensionCount by default
+func (r SeriesRef) In(dim Dimension) SeriesRef {
+	r.dim = dim
+	return r
+}
+
+// Resolver is implemented by the viewers referencing other viewers by name,
+// the ViewManager resolves them once every viewer is registered
+type Resolver interface {
+	Resolve(lookup func(name string) Viewer) error
+}
+
+// ComposedViewer charts series of other viewers together, e.g. the
+// goroutines with the heap, without writing a new Viewer. The series are
+// collected from their viewers in base units
+type ComposedViewer struct {
+	name    string
+	refs    []SeriesRef
+	sources []Collector
+	smgr    *StatsMgr
+	graph   *charts.Line
+	opts    viewerOptions
+}
+
+// Compose returns the viewer of the named chart drawing the series refs
+//
+//	viewer.Compose("concurrency",
+//		viewer.Ref(viewer.VGoroutine, "Goroutines"),
+//		viewer.Ref(viewer.VHeap, "Alloc").In(viewer.DimensionBytes),
+//	)
+func Compose(name string, refs ...SeriesRef) *ComposedViewer {
+	return ComposeWith(name, refs)
+}
+
+// ComposeWith is Compose customized by vopts, the series of the chart are
+// the labels of the refs
+func ComposeWith(name string, refs []SeriesRef, vopts ...ViewerOption) *ComposedViewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(name)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: name}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Value"}),
+	)
+	dims := make(map[string]Dimension, len(refs))
+	for _, r := range refs {
+		graph.AddSeries(r.label, []opts.LineData{})
+		dims[r.label] = r.dim
+	}
+
+	o.apply(graph)
+	axis := DimensionCount
+	if len(refs) > 0 {
+		axis = refs[0].dim
+	}
+	formatSeries(graph, UnitNone, axis, dims)
+	return &ComposedViewer{name: name, refs: refs, graph: graph, opts: o}
+}
+
+// Resolve binds the refs to their viewers, they must be collectors
+func (vr *ComposedViewer) Resolve(lookup func(name string) Viewer) error {
+	sources, err := resolveRefs(vr.name, vr.refs, lookup)
+	vr.sources = sources
+	return err
+}
+
+// resolveRefs returns the collectors of the refs of the named viewer
+func resolveRefs(name string, refs []SeriesRef, lookup func(name string) Viewer) ([]Collector, error) {
+	sources := make([]Collector, len(refs))
+	for i, r := range refs {
+		v := r.source
+		if v == nil {
+			if found := lookup(r.viewer); found != nil {
+				v = found
+			}
+		}
+		if v == nil {
+			return nil, fmt.Errorf("statsview: viewer %s references the unknown viewer %s", name, r.viewer)
+		}
+		c, ok := v.(Collector)
+		if !ok {
+			return nil, fmt.Errorf("statsview: viewer %s references %s which doesn't collect points", name, r.viewer)
+		}
+		sources[i] = c
+	}
+	return sources, nil
+}
+
+// setRefsStatsMgr sets the manager of the viewers and the collectors
+// referenced directly
+func setRefsStatsMgr(refs []SeriesRef, smgr *StatsMgr) {
+	for _, r := range refs {
+		if r.source != nil {
+			r.source.SetStatsMgr(smgr)
+		}
+	}
+}
+
+// refsPriority is the lowest priority of the referenced viewers and
+// collectors
+func refsPriority(sources []Collector) Priority {
+	p := PriorityHigh
+	for _, s := range sources {
+		sp := PriorityNormal
+		if pr, ok := s.(Prioritized); ok {
+			sp = pr.Priority()
+		}
+		if sp < p {
+			p = sp
+		}
+	}
+	return p
+}
+
+// collectRefs returns the points of the refs labeled as the refs, every
+// referenced viewer is collected once. A series its viewer didn't collect is 0
+func collectRefs(name string, refs []SeriesRef, sources []Collector, t time.Time) []Point {
+	collected := make(map[string][]Point, len(sources))
+	points := make([]Point, len(refs))
+	for i, r := range refs {
+		points[i] = Point{Viewer: name, Series: r.label, Time: t}
+		if i >= len(sources) {
+			continue
+		}
+		ps, ok := collected[r.viewer]
+		if !ok {
+			ps = sources[i].Collect()
+			collected[r.viewer] = ps
+		}
+		for _, p := range ps {
+			if p.Series == r.series {
+				points[i].Value = p.Value
+				break
+			}
+		}
+	}
+	return points
+}
+
+// SetStatsMgr sets the manager of the viewers referenced with RefOf too
+func (vr *ComposedViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+	setRefsStatsMgr(vr.refs, smgr)
+}
+
+func (vr *ComposedViewer) Name() string {
+	return vr.name
+}
+
+func (vr *ComposedViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Priority is the lowest priority of the referenced viewers, the chart is
+// skipped under pressure if one of them is
+func (vr *ComposedViewer) Priority() Priority {
+	return refsPriority(vr.sources)
+}
+
+// Collect returns the referenced series
+func (vr *ComposedViewer) Collect() []Point {
+	return collectRefs(vr.name, vr.refs, vr.sources, vr.smgr.CollectTime())
+}
+
+func (vr *ComposedViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 6)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,222 @@
+package viewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+)
+
+func TestComposedViewerResolve(t *testing.T) {
+	heap := NewHeapViewer()
+	lookup := func(name string) Viewer {
+		switch name {
+		case VHeap:
+			return heap
+		case "const":
+			return newConstViewer()
+		case "page":
+			return pageViewer{}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name string
+		refs []SeriesRef
+		err  string
+	}{
+		{"none", nil, ""},
+		{"registered", []SeriesRef{Ref(VHeap, "Alloc"), Ref("const", "Sum")}, ""},
+		{"not registered", []SeriesRef{RefOf(NewGoroutinesViewer(), "Goroutines")}, ""},
+		{"unknown", []SeriesRef{Ref(VHeap, "Alloc"), Ref("missing", "Sum")}, "references the unknown viewer missing"},
+		{"not a collector", []SeriesRef{Ref("page", "Sum")}, "references page which doesn't collect points"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Compose("composed", tt.refs...).Resolve(lookup)
+			if tt.err == "" && err != nil {
+				t.Errorf("Resolve() = %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("Resolve() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// pageViewer serves a page without collecting points
+type pageViewer struct{}
+
+func (pageViewer) Name() string                             { return "page" }
+func (pageViewer) View() *charts.Line                       { return NewBasicView("page") }
+func (pageViewer) SetStatsMgr(*StatsMgr)                    {}
+func (pageViewer) Serve(http.ResponseWriter, *http.Request) {}
+
+func TestComposedViewerCollect(t *testing.T) {
+	s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock, numGoroutine: func() int { return 42 }}
+	s.memstats = runtime.MemStats{HeapAlloc: 3 << 20, HeapSys: 8 << 20}
+
+	heap := NewHeapViewer()
+	heap.SetStatsMgr(s)
+	lookup := func(name string) Viewer {
+		if name == VHeap {
+			return heap
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name   string
+		refs   []SeriesRef
+		series []string
+		values []float64
+	}{
+		{
+			name:   "series of a registered viewer",
+			refs:   []SeriesRef{Ref(VHeap, "Alloc"), Ref(VHeap, "Sys")},
+			series: []string{"heap.Alloc", "heap.Sys"},
+			values: []float64{3 << 20, 8 << 20},
+		},
+		{
+			name:   "viewers mixed",
+			refs:   []SeriesRef{RefOf(NewGoroutinesViewer(), "Goroutines").As("goroutines"), Ref(VHeap, "Alloc").In(DimensionBytes)},
+			series: []string{"goroutines", "heap.Alloc"},
+			values: []float64{42, 3 << 20},
+		},
+		{
+			name:   "unknown series",
+			refs:   []SeriesRef{Ref(VHeap, "Missing"), Ref(VHeap, "Alloc")},
+			series: []string{"heap.Missing", "heap.Alloc"},
+			values: []float64{0, 3 << 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vr := Compose("composed", tt.refs...)
+			if err := vr.Resolve(lookup); err != nil {
+				t.Fatal(err)
+			}
+			vr.SetStatsMgr(s)
+
+			var series []string
+			var values []float64
+			for _, p := range vr.Collect() {
+				if p.Viewer != "composed" {
+					t.Errorf("point of %s, want composed", p.Viewer)
+				}
+				series = append(series, p.Series)
+				values = append(values, p.Value)
+			}
+			if !reflect.DeepEqual(series, tt.series) || !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("Collect() = %v %v, want %v %v", series, values, tt.series, tt.values)
+			}
+
+			rec := httptest.NewRecorder()
+			vr.Serve(rec, httptest.NewRequest("GET", "/debug/statsview/view/composed", nil))
+			var m Metrics
+			if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Values, tt.values) {
+				t.Errorf("served %v, want %v", m.Values, tt.values)
+			}
+		})
+	}
+}
+
+func TestComposedViewerPriority(t *testing.T) {
+	lookup := func(name string) Viewer {
+		switch name {
+		case VHeap:
+			return NewHeapViewer()
+		case VMutex:
+			return NewMutexViewer()
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name string
+		refs []SeriesRef
+		want Priority
+	}{
+		{"none", nil, PriorityHigh},
+		{"normal", []SeriesRef{Ref(VHeap, "Alloc")}, PriorityNormal},
+		{"lowest of the viewers", []SeriesRef{Ref(VHeap, "Alloc"), Ref(VMutex, "Events")}, PriorityLow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vr := Compose("composed", tt.refs...)
+			if err := vr.Resolve(lookup); err != nil {
+				t.Fatal(err)
+			}
+			if got := vr.Priority(); got != tt.want {
+				t.Errorf("Priority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// queueCollector is a collector without chart reporting a queue depth
+type queueCollector struct {
+	smgr    *StatsMgr
+	collect int
+}
+
+func (c *queueCollector) Name() string               { return "queue" }
+func (c *queueCollector) SetStatsMgr(smgr *StatsMgr) { c.smgr = smgr }
+func (c *queueCollector) Priority() Priority         { return PriorityLow }
+
+func (c *queueCollector) Collect() []Point {
+	c.collect++
+	return []Point{{Viewer: "queue", Series: "Depth", Value: 7}}
+}
+
+func TestCollectorRef(t *testing.T) {
+	s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock}
+	queue := &queueCollector{}
+	lookup := func(string) Viewer { return nil }
+
+	tests := []struct {
+		name      string
+		chartType ChartType
+		typ       string
+	}{
+		{"line", ChartLine, "line"},
+		{"bar", ChartBar, "bar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vr := ComposeWith(tt.name, []SeriesRef{CollectorRef(queue, "Depth")}, WithChartType(tt.chartType))
+			if err := vr.Resolve(lookup); err != nil {
+				t.Fatal(err)
+			}
+			vr.SetStatsMgr(s)
+			if queue.smgr != s {
+				t.Error("the manager of the collector isn't set")
+			}
+			if typ := vr.View().MultiSeries[0].Type; typ != tt.typ {
+				t.Errorf("series type = %s, want %s", typ, tt.typ)
+			}
+			if p := vr.Priority(); p != PriorityLow {
+				t.Errorf("Priority() = %v, want the one of the collector", p)
+			}
+
+			collected := queue.collect
+			ps := vr.Collect()
+			if len(ps) != 1 || ps[0].Viewer != tt.name || ps[0].Series != "queue.Depth" || ps[0].Value != 7 {
+				t.Errorf("Collect() = %+v", ps)
+			}
+			if queue.collect != collected+1 {
+				t.Errorf("the collector was collected %d times", queue.collect-collected)
+			}
+		})
+	}
+}
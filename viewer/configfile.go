@@ -0,0 +1,216 @@
+package viewer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mortum5/statsview/exporter"
+	"github.com/mortum5/statsview/notify"
+)
+
+// FileConfig is the part of the configuration which could be set without a
+// recompile, zero fields are left unchanged
+//
+//	addr: "0.0.0.0:18066"
+//	interval: 2s
+//	theme: westeros
+//	viewers: [heap, goroutine, gcnum]
+//	admin_token: "s3cret"
+//	anomaly_threshold: 4
+//	notifiers:
+//	  - type: slack
+//	    url: https://hooks.slack.com/services/...
+//	exporters:
+//	  - type: remote_write
+//	    url: http://mimir:9009/api/v1/push
+type FileConfig struct {
+	Addr          string   `yaml:"addr" toml:"addr"`
+	LinkAddr      string   `yaml:"link_addr" toml:"link_addr"`
+	Interval      string   `yaml:"interval" toml:"interval"`
+	MaxPoints     int      `yaml:"max_points" toml:"max_points"`
+	TimeFormat    string   `yaml:"time_format" toml:"time_format"`
+	Theme         string   `yaml:"theme" toml:"theme"`
+	Viewers       []string `yaml:"viewers" toml:"viewers"`
+	AdminToken    string   `yaml:"admin_token" toml:"admin_token"`
+	ReadToken     string   `yaml:"read_token" toml:"read_token"`
+	AllowedCIDRs  []string `yaml:"allowed_cidrs" toml:"allowed_cidrs"`
+	AlwaysCollect bool     `yaml:"always_collect" toml:"always_collect"`
+	// SecurityHeaders is only read from files
+	SecurityHeaders map[string]string `yaml:"security_headers" toml:"security_headers"`
+	// ViewerMaxPoints is only read from files too
+	ViewerMaxPoints map[string]int `yaml:"viewer_max_points" toml:"viewer_max_points"`
+	// AnomalyThreshold enables the anomaly detection when positive
+	AnomalyThreshold float64 `yaml:"anomaly_threshold" toml:"anomaly_threshold"`
+	// Notifiers are only read from files as well
+	Notifiers []notify.Config `yaml:"notifiers" toml:"notifiers"`
+	// Exporters are only read from files as well
+	Exporters []exporter.Config `yaml:"exporters" toml:"exporters"`
+	// TrustedHeaders are only read from files too
+	TrustedHeaders *TrustedHeaders `yaml:"trusted_headers" toml:"trusted_headers"`
+}
+
+// ConfigFromFile reads the FileConfig of a YAML (.yaml, .yml) or TOML (.toml) file
+//
+//	opts, err := viewer.ConfigFromFile("statsview.yaml")
+//	if err != nil { ... }
+//	viewer.SetConfiguration(opts...)
+func ConfigFromFile(path string) ([]Option, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fc FileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(bs, &fc)
+	case ".toml":
+		err = toml.Unmarshal(bs, &fc)
+	default:
+		return nil, fmt.Errorf("statsview: unsupported config file format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("statsview: invalid config file %s: %w", path, err)
+	}
+	return fc.Options()
+}
+
+// ConfigFromEnv reads the FileConfig of the STATSVIEW_* environment variables,
+// e.g. STATSVIEW_ADDR, STATSVIEW_INTERVAL or STATSVIEW_VIEWERS=heap,goroutine
+func ConfigFromEnv() ([]Option, error) {
+	fc := FileConfig{
+		Addr:       os.Getenv("STATSVIEW_ADDR"),
+		LinkAddr:   os.Getenv("STATSVIEW_LINK_ADDR"),
+		Interval:   os.Getenv("STATSVIEW_INTERVAL"),
+		TimeFormat: os.Getenv("STATSVIEW_TIME_FORMAT"),
+		Theme:      os.Getenv("STATSVIEW_THEME"),
+		AdminToken: os.Getenv("STATSVIEW_ADMIN_TOKEN"),
+		ReadToken:  os.Getenv("STATSVIEW_READ_TOKEN"),
+	}
+	if s := os.Getenv("STATSVIEW_MAX_POINTS"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("statsview: invalid STATSVIEW_MAX_POINTS %q", s)
+		}
+		fc.MaxPoints = n
+	}
+	if s := os.Getenv("STATSVIEW_VIEWERS"); s != "" {
+		for _, name := range strings.Split(s, ",") {
+			fc.Viewers = append(fc.Viewers, strings.TrimSpace(name))
+		}
+	}
+	if s := os.Getenv("STATSVIEW_ALLOWED_CIDRS"); s != "" {
+		for _, cidr := range strings.Split(s, ",") {
+			fc.AllowedCIDRs = append(fc.AllowedCIDRs, strings.TrimSpace(cidr))
+		}
+	}
+	if s := os.Getenv("STATSVIEW_ALWAYS_COLLECT"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("statsview: invalid STATSVIEW_ALWAYS_COLLECT %q", s)
+		}
+		fc.AlwaysCollect = b
+	}
+	return fc.Options()
+}
+
+// Options validates the FileConfig and returns the options setting its fields
+func (fc FileConfig) Options() ([]Option, error) {
+	var opts []Option
+	if fc.Addr != "" {
+		opts = append(opts, WithAddr(fc.Addr))
+	}
+	if fc.LinkAddr != "" {
+		opts = append(opts, WithLinkAddr(fc.LinkAddr))
+	}
+	if fc.Interval != "" {
+		interval, err := parseInterval(fc.Interval)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithInterval(interval))
+	}
+	if fc.MaxPoints > 0 {
+		opts = append(opts, WithMaxPoints(fc.MaxPoints))
+	}
+	for name, n := range fc.ViewerMaxPoints {
+		if n <= 0 {
+			return nil, fmt.Errorf("statsview: invalid max points %d of viewer %q", n, name)
+		}
+		opts = append(opts, WithMaxPointsOf(name, n))
+	}
+	if fc.TimeFormat != "" {
+		opts = append(opts, WithTimeFormat(fc.TimeFormat))
+	}
+	if fc.Theme != "" {
+		theme := Theme(strings.ToLower(fc.Theme))
+		if theme != ThemeWesteros && theme != ThemeMacarons {
+			return nil, fmt.Errorf("statsview: unknown theme %q", fc.Theme)
+		}
+		opts = append(opts, WithTheme(theme))
+	}
+	if len(fc.Viewers) > 0 {
+		opts = append(opts, WithViewers(fc.Viewers...))
+	}
+	if fc.AdminToken != "" {
+		opts = append(opts, WithAdminToken(fc.AdminToken))
+	}
+	if len(fc.AllowedCIDRs) > 0 {
+		opts = append(opts, WithAllowedCIDRs(fc.AllowedCIDRs))
+	}
+	if len(fc.SecurityHeaders) > 0 {
+		opts = append(opts, WithSecurityHeaders(fc.SecurityHeaders))
+	}
+	if fc.ReadToken != "" {
+		opts = append(opts, WithReadToken(fc.ReadToken))
+	}
+	if fc.AlwaysCollect {
+		opts = append(opts, WithAlwaysCollect())
+	}
+	if h := fc.TrustedHeaders; h != nil {
+		if h.User == "" {
+			return nil, fmt.Errorf("statsview: the trusted headers require the header of the user")
+		}
+		opts = append(opts, WithTrustedHeaders(*h))
+	}
+	if fc.AnomalyThreshold < 0 {
+		return nil, fmt.Errorf("statsview: invalid anomaly threshold %g", fc.AnomalyThreshold)
+	}
+	if fc.AnomalyThreshold > 0 {
+		opts = append(opts, WithAnomalyDetection(fc.AnomalyThreshold))
+	}
+	for _, c := range fc.Notifiers {
+		n, err := notify.New(c)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithNotifiers(n))
+	}
+	for _, c := range fc.Exporters {
+		e, err := exporter.New(c)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithExporters(e))
+	}
+	return opts, nil
+}
+
+// parseInterval accepts a duration like "500ms" or "2s", or milliseconds
+func parseInterval(s string) (int, error) {
+	if ms, err := strconv.Atoi(s); err == nil && ms > 0 {
+		return ms, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Millisecond {
+		return 0, fmt.Errorf("statsview: invalid interval %q", s)
+	}
+	return int(d / time.Millisecond), nil
+}
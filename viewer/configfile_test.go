@@ -0,0 +1,246 @@
+package viewer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// applied returns the configuration set by opts on an empty one
+func applied(opts []Option) config {
+	c := config{ViewerPoints: map[string]int{}}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if len(c.ViewerPoints) == 0 {
+		c.ViewerPoints = nil
+	}
+	return c
+}
+
+func TestConfigFromFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    config
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "statsview.yaml",
+			content: `addr: "0.0.0.0:18066"
+interval: 2s
+max_points: 50
+theme: Westeros
+viewers: [heap, goroutine]
+admin_token: s3cret
+read_token: reader
+always_collect: true
+`,
+			want: config{ListenAddr: "0.0.0.0:18066", LinkAddr: "0.0.0.0:18066", Interval: 2000, MaxPoints: 50, maxPointsSet: true, Theme: ThemeWesteros,
+				Viewers: []string{"heap", "goroutine"}, AdminToken: "s3cret", ReadToken: "reader", AlwaysCollect: true},
+		},
+		{
+			name:    "toml",
+			file:    "statsview.toml",
+			content: "interval = \"500\"\ntime_format = \"15:04\"\ntheme = \"macarons\"\n",
+			want:    config{Interval: 500, TimeFormat: "15:04", Theme: ThemeMacarons},
+		},
+		{
+			name:    "security headers",
+			file:    "statsview.toml",
+			content: "[security_headers]\nX-Frame-Options = \"SAMEORIGIN\"\nReferrer-Policy = \"\"\n",
+			want:    config{SecurityHeaders: map[string]string{"X-Frame-Options": "SAMEORIGIN", "Referrer-Policy": ""}},
+		},
+		{
+			name:    "viewer max points",
+			file:    "statsview.yaml",
+			content: "viewer_max_points: {heap: 300, goroutine: 60}\n",
+			want:    config{ViewerPoints: map[string]int{"heap": 300, "goroutine": 60}},
+		},
+		{
+			name:    "anomaly threshold",
+			file:    "statsview.yaml",
+			content: "anomaly_threshold: 3\n",
+			want:    config{Anomalies: 3},
+		},
+		{
+			name:    "trusted headers",
+			file:    "statsview.yaml",
+			content: "trusted_headers:\n  user: X-Forwarded-User\n  groups: X-Forwarded-Groups\n  admins: [oncall]\n  proxies: [10.0.0.0/8]\n",
+			want: config{TrustedHeaders: TrustedHeaders{User: "X-Forwarded-User", Groups: "X-Forwarded-Groups",
+				Admins: []string{"oncall"}, Proxies: []string{"10.0.0.0/8"}}},
+		},
+		{name: "empty", file: "statsview.yml"},
+		{name: "unknown format", file: "statsview.json", content: "{}", wantErr: "unsupported config file format"},
+		{name: "invalid yaml", file: "statsview.yaml", content: "viewers: {", wantErr: "invalid config file"},
+		{name: "invalid interval", file: "statsview.yaml", content: "interval: soon", wantErr: "invalid interval"},
+		{name: "sub-millisecond interval", file: "statsview.yaml", content: "interval: 10us", wantErr: "invalid interval"},
+		{name: "invalid viewer max points", file: "statsview.toml", content: "[viewer_max_points]\nheap = 0\n", wantErr: `invalid max points 0 of viewer "heap"`},
+		{name: "unknown theme", file: "statsview.toml", content: `theme = "dark"`, wantErr: "unknown theme"},
+		{name: "trusted headers without user", file: "statsview.toml", content: "[trusted_headers]\ngroups = \"X-Forwarded-Groups\"\n", wantErr: "require the header of the user"},
+		{name: "negative anomaly threshold", file: "statsview.yaml", content: "anomaly_threshold: -1\n", wantErr: "invalid anomaly threshold"},
+		{name: "invalid notifier", file: "statsview.yaml", content: "notifiers:\n  - type: pager\n", wantErr: `unknown notifier type "pager"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			opts, err := ConfigFromFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ConfigFromFile() = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := applied(opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ConfigFromFile(filepath.Join(t.TempDir(), "missing.yaml")); !os.IsNotExist(err) {
+		t.Errorf("ConfigFromFile() of a missing file = %v", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    config
+		wantErr string
+	}{
+		{name: "none"},
+		{
+			name: "set",
+			env: map[string]string{
+				"STATSVIEW_INTERVAL":       "1s",
+				"STATSVIEW_MAX_POINTS":     "30",
+				"STATSVIEW_VIEWERS":        "heap, gcnum",
+				"STATSVIEW_ALWAYS_COLLECT": "true",
+				"STATSVIEW_ADMIN_TOKEN":    "s3cret",
+				"STATSVIEW_READ_TOKEN":     "reader",
+			},
+			want: config{Interval: 1000, MaxPoints: 30, maxPointsSet: true, Viewers: []string{"heap", "gcnum"}, AlwaysCollect: true, AdminToken: "s3cret", ReadToken: "reader"},
+		},
+		{name: "invalid max points", env: map[string]string{"STATSVIEW_MAX_POINTS": "many"}, wantErr: "STATSVIEW_MAX_POINTS"},
+		{name: "invalid always collect", env: map[string]string{"STATSVIEW_ALWAYS_COLLECT": "sure"}, wantErr: "STATSVIEW_ALWAYS_COLLECT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			opts, err := ConfigFromEnv()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ConfigFromEnv() = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := applied(opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromFileNotifiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    int
+	}{
+		{"yaml", "statsview.yaml", `
+notifiers:
+  - type: slack
+    url: https://hooks.slack.test/services/x
+    throttle: 10m
+  - type: telegram
+    token: "123:ABC"
+    chat_id: "-100"
+`, 2},
+		{"toml", "statsview.toml", `
+[[notifiers]]
+type = "discord"
+url = "https://discord.test/api/webhooks/x"
+`, 1},
+		{"none", "statsview.yaml", "anomaly_threshold: 4\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			opts, err := ConfigFromFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(applied(opts).Notifiers); got != tt.want {
+				t.Errorf("got %d notifiers, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromFileExporters(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{"yaml", "statsview.yaml", `
+exporters:
+  - type: influx
+    url: http://influx:8086/api/v2/write?org=lab&bucket=perf
+    headers: {Authorization: "Token s3cret"}
+  - type: remote_write
+    url: http://mimir:9009/api/v1/push
+    interval: 30s
+`, 2, false},
+		{"toml", "statsview.toml", `
+[[exporters]]
+type = "remote_write"
+url = "http://mimir:9009/api/v1/push"
+`, 1, false},
+		{"invalid", "statsview.yaml", `
+exporters:
+  - type: remote_write
+`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			opts, err := ConfigFromFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var c config
+			for _, opt := range opts {
+				opt(&c)
+			}
+			if len(c.Exporters) != tt.want {
+				t.Errorf("got %d exporters, want %d", len(c.Exporters), tt.want)
+			}
+		})
+	}
+}
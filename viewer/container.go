@@ -0,0 +1,120 @@
+package viewer
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VContainer is the name of ContainerViewer
+	VContainer = "container"
+)
+
+// ContainerViewer collects the memory and CPU usage of the cgroup the
+// process runs in, as percentage of the container limits
+type ContainerViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+
+	memLimit float64
+	cpuLimit float64
+
+	mu       sync.Mutex
+	lastCPU  float64
+	lastTime time.Time
+	cpuRate  float64
+}
+
+// NewContainerViewer returns the ContainerViewer instance, without a memory
+// limit the usage is relative to the host memory and without a CPU quota to
+// the number of CPUs
+// Series: Memory / CPU
+func NewContainerViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	memLimit, cpuLimit := ContainerLimits()
+	if memLimit == 0 {
+		memLimit = hostMemory()
+	}
+	if cpuLimit == 0 {
+		cpuLimit = float64(runtime.NumCPU())
+	}
+
+	graph := o.newBasicView(VContainer)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    "Container",
+			Subtitle: fmt.Sprintf("Limits: %.0f MiB memory, %.2f CPU", UnitMiB.Convert(memLimit), cpuLimit),
+		}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Usage", AxisLabel: &opts.AxisLabel{Formatter: "{value} %"}}),
+	)
+	graph.AddSeries("Memory", []opts.LineData{},
+		charts.WithMarkLineNameYAxisItemOpts(opts.MarkLineNameYAxisItem{Name: "Limit", YAxis: 100}),
+	).AddSeries("CPU", []opts.LineData{})
+
+	o.apply(graph)
+	return &ContainerViewer{graph: graph, opts: o, memLimit: memLimit, cpuLimit: cpuLimit}
+}
+
+func (vr *ContainerViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *ContainerViewer) Name() string {
+	return VContainer
+}
+
+func (vr *ContainerViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// cpuPercent returns the CPU usage since the previous call, the last rate is
+// reused when called again too quickly to give a meaningful delta
+func (vr *ContainerViewer) cpuPercent(cg *cgroup) float64 {
+	usage, ok := cg.cpuUsage()
+	if !ok {
+		return 0
+	}
+
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	now := time.Now()
+	if elapsed := now.Sub(vr.lastTime).Seconds(); elapsed >= 0.1 {
+		if !vr.lastTime.IsZero() {
+			vr.cpuRate = (usage - vr.lastCPU) / elapsed
+		}
+		vr.lastCPU, vr.lastTime = usage, now
+	}
+	return vr.cpuRate / vr.cpuLimit * 100
+}
+
+// Collect returns the memory and CPU usage in percent of the limits, nothing
+// outside of a cgroup
+func (vr *ContainerViewer) Collect() []Point {
+	cg := selfCgroup()
+	if cg == nil {
+		return nil
+	}
+
+	t := vr.smgr.CollectTime()
+	var memPercent float64
+	if mem, ok := cg.memoryUsage(); ok && vr.memLimit > 0 {
+		memPercent = mem / vr.memLimit * 100
+	}
+	return []Point{
+		{Viewer: VContainer, Series: "Memory", Value: memPercent, Time: t},
+		{Viewer: VContainer, Series: "CPU", Value: vr.cpuPercent(cg), Time: t},
+	}
+}
+
+func (vr *ContainerViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 2)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,77 @@
+package viewer
+
+import (
+	"bufio"
+	"bytes"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+)
+
+// contention is the sum of all records of a block or mutex profile
+type contention struct {
+	Count int64
+	Delay float64 // seconds
+}
+
+// contentionMetrics converts the Events and Delay points of a contention
+// viewer, only the delay is in unit
+func contentionMetrics(points []Point, unit Unit, o viewerOptions) Metrics {
+	t := points[0].Time
+	points = o.selectPoints(points)
+	values := make([]float64, 0, len(points))
+	for _, p := range points {
+		if p.Series == "Delay" {
+			values = append(values, fixedPrecision(unit.Convert(p.Value), o.precisionOr(6)))
+			continue
+		}
+		values = append(values, p.Value)
+	}
+	return NewMetrics(values, t)
+}
+
+// readContention parses the `debug=1` text form of the named contention profile
+func readContention(name string) contention {
+	var c contention
+	p := pprof.Lookup(name)
+	if p == nil {
+		return c
+	}
+
+	var buf bytes.Buffer
+	if err := p.WriteTo(&buf, 1); err != nil {
+		Logger().Error("statsview: failed to read profile", "profile", name, "err", err)
+		return c
+	}
+
+	var cycles, cps, period float64 = 0, 0, 1
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "cycles/second="):
+			cps, _ = strconv.ParseFloat(strings.TrimPrefix(line, "cycles/second="), 64)
+		case strings.HasPrefix(line, "sampling period="):
+			period, _ = strconv.ParseFloat(strings.TrimPrefix(line, "sampling period="), 64)
+		case line == "" || line[0] == '#' || line[0] == '-':
+		default:
+			fields := strings.Fields(line)
+			if len(fields) < 3 || fields[2] != "@" {
+				continue
+			}
+			cy, _ := strconv.ParseFloat(fields[0], 64)
+			n, _ := strconv.ParseInt(fields[1], 10, 64)
+			cycles += cy
+			c.Count += n
+		}
+	}
+
+	if period > 0 {
+		cycles *= period
+		c.Count *= int64(period)
+	}
+	if cps > 0 {
+		c.Delay = cycles / cps
+	}
+	return c
+}
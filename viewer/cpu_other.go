@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package viewer
+
+import "time"
+
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
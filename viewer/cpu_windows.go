@@ -0,0 +1,24 @@
+//go:build windows
+
+package viewer
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPUTime returns the user and kernel CPU time consumed by the process
+func processCPUTime() (time.Duration, bool) {
+	h, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0, false
+	}
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(h, &creation, &exit, &kernel, &user); err != nil {
+		return 0, false
+	}
+	// Filetime counts 100-nanosecond intervals
+	ticks := int64(kernel.HighDateTime)<<32 | int64(kernel.LowDateTime) +
+		int64(user.HighDateTime)<<32 | int64(user.LowDateTime)
+	return time.Duration(ticks * 100), true
+}
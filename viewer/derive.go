@@ -0,0 +1,213 @@
+package viewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Transform creates the state of a derived series, the returned function is
+// called with every new value of the series and returns the derived value
+type Transform func() func(t time.Time, v float64) float64
+
+// Rate derives the per second rate of a cumulative counter, e.g. GC/s from NumGC
+func Rate() Transform {
+	return func() func(time.Time, float64) float64 {
+		var (
+			prev     float64
+			prevTime time.Time
+		)
+		return func(t time.Time, v float64) float64 {
+			defer func() { prev, prevTime = v, t }()
+			elapsed := t.Sub(prevTime).Seconds()
+			if prevTime.IsZero() || elapsed <= 0 {
+				return 0
+			}
+			return (v - prev) / elapsed
+		}
+	}
+}
+
+// Delta derives the change of a series since the previous value
+func Delta() Transform {
+	return func() func(time.Time, float64) float64 {
+		var (
+			prev  float64
+			first = true
+		)
+		return func(_ time.Time, v float64) float64 {
+			defer func() { prev, first = v, false }()
+			if first {
+				return 0
+			}
+			return v - prev
+		}
+	}
+}
+
+// MovingAverage derives the average of the last n values of a series
+func MovingAverage(n int) Transform {
+	if n < 1 {
+		n = 1
+	}
+	return func() func(time.Time, float64) float64 {
+		var (
+			window = make([]float64, 0, n)
+			sum    float64
+		)
+		return func(_ time.Time, v float64) float64 {
+			if len(window) == n {
+				sum -= window[0]
+				window = append(window[:0], window[1:]...)
+			}
+			window = append(window, v)
+			sum += v
+			return sum / float64(len(window))
+		}
+	}
+}
+
+// derivedState is the state of a transform fed once per collection: the
+// values of a collection already fed return the same derived value, so every
+// reader of the collection gets the same
+type derivedState struct {
+	transform func(time.Time, float64) float64
+	at        time.Time
+	value     float64
+}
+
+func (s *derivedState) apply(t time.Time, v float64) float64 {
+	if !s.at.IsZero() && !t.After(s.at) {
+		return s.value
+	}
+	s.at, s.value = t, s.transform(t, v)
+	return s.value
+}
+
+// DerivedViewer wraps a viewer and transforms one of its series server-side
+// before serving it. Served values, in the unit of the chart, and collected
+// points, in base units, are derived separately, both once per collection
+type DerivedViewer struct {
+	Viewer
+
+	series  string
+	index   int
+	smgr    *StatsMgr
+	mu      sync.Mutex
+	serve   derivedState
+	collect derivedState
+}
+
+// Derive wraps the viewer so the named series is transformed by t, calls
+// could be nested to transform several series or to combine transforms
+//
+//	v := viewer.Derive(viewer.NewGCNumViewer(), "GcNum", viewer.Rate())
+//	v = viewer.Derive(v, "GcNum", viewer.MovingAverage(5))
+func Derive(v Viewer, series string, t Transform) Viewer {
+	index := -1
+	for i, s := range v.View().MultiSeries {
+		if s.Name == series {
+			index = i
+		}
+	}
+	if index < 0 {
+		Logger().Error("statsview: derived series not found", "viewer", v.Name(), "series", series)
+		return v
+	}
+
+	return &DerivedViewer{
+		Viewer:  v,
+		series:  series,
+		index:   index,
+		serve:   derivedState{transform: t()},
+		collect: derivedState{transform: t()},
+	}
+}
+
+// SetStatsMgr keeps the manager for the collection time and passes it on
+func (vr *DerivedViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+	vr.Viewer.SetStatsMgr(smgr)
+}
+
+// Priority keeps the priority of the wrapped viewer
+func (vr *DerivedViewer) Priority() Priority {
+	return PriorityOf(vr.Viewer)
+}
+
+// Collect returns the derived points if the wrapped viewer is a Collector
+func (vr *DerivedViewer) Collect() []Point {
+	c, ok := vr.Viewer.(Collector)
+	if !ok {
+		return nil
+	}
+	points := c.Collect()
+
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	for i, p := range points {
+		if p.Series == vr.series {
+			points[i].Value = vr.collect.apply(p.Time, p.Value)
+		}
+	}
+	return points
+}
+
+// Serve serves the metrics of the wrapped viewer with the derived series.
+// The derived values are served as they are, unrounded, so the small rates,
+// e.g. bytes per nanosecond, aren't rounded to 0
+func (vr *DerivedViewer) Serve(w http.ResponseWriter, r *http.Request) {
+	buf := NewResponseBuffer(w.Header())
+	vr.Viewer.Serve(buf, r)
+
+	metrics, ok := servedMetrics(buf)
+	if !ok {
+		if v, valued := buf.Value(); valued {
+			WriteJSONStatus(w, buf.Status(), v)
+			return
+		}
+		w.WriteHeader(buf.Status())
+		w.Write(buf.Body())
+		return
+	}
+
+	if vr.index < len(metrics.Values) {
+		var t time.Time
+		if vr.smgr != nil {
+			t = vr.smgr.CollectTime()
+		}
+		vr.mu.Lock()
+		metrics.Values[vr.index] = vr.serve.apply(t, metrics.Values[vr.index])
+		vr.mu.Unlock()
+	}
+
+	WriteJSON(w, metrics)
+}
+
+// servedMetrics returns a copy of the metrics served into b, false when the
+// viewer failed or served something else
+func servedMetrics(b *ResponseBuffer) (Metrics, bool) {
+	if b.Status() != http.StatusOK {
+		return Metrics{}, false
+	}
+	var metrics Metrics
+	v, _ := b.Value()
+	switch m := v.(type) {
+	case Metrics:
+		metrics = m
+	case *Metrics:
+		if m == nil {
+			return Metrics{}, false
+		}
+		metrics = *m
+	default:
+		bs, err := b.JSON()
+		if err != nil || json.Unmarshal(bs, &metrics) != nil {
+			return Metrics{}, false
+		}
+	}
+	// the values may be kept by the viewer
+	metrics.Values = append([]float64(nil), metrics.Values...)
+	return metrics, true
+}
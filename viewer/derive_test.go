@@ -0,0 +1,278 @@
+package viewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// constViewer serves and collects the values set by the test for the
+// series Sum and Count
+type constViewer struct {
+	graph  *charts.Line
+	values []float64
+	status int
+}
+
+func newConstViewer() *constViewer {
+	graph := NewBasicView("const")
+	graph.AddSeries("Sum", []opts.LineData{}).AddSeries("Count", []opts.LineData{})
+	return &constViewer{graph: graph, status: http.StatusOK}
+}
+
+func (vr *constViewer) Name() string               { return "const" }
+func (vr *constViewer) View() *charts.Line         { return vr.graph }
+func (vr *constViewer) SetStatsMgr(smgr *StatsMgr) {}
+
+func (vr *constViewer) Collect() []Point {
+	return []Point{
+		{Viewer: "const", Series: "Sum", Value: vr.values[0]},
+		{Viewer: "const", Series: "Count", Value: vr.values[1]},
+	}
+}
+
+func (vr *constViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	if vr.status != http.StatusOK {
+		http.Error(w, "failed", vr.status)
+		return
+	}
+	bs, _ := json.Marshal(Metrics{Values: vr.values, Time: "12:00:00"})
+	w.Write(bs)
+}
+
+// counterViewer serves a counter set by the test
+type counterViewer struct {
+	graph *charts.Line
+	smgr  *StatsMgr
+	value float64
+}
+
+func newCounterViewer() *counterViewer {
+	graph := charts.NewLine()
+	graph.AddSeries("Count", []opts.LineData{})
+	return &counterViewer{graph: graph}
+}
+
+func (vr *counterViewer) Name() string               { return "counter" }
+func (vr *counterViewer) View() *charts.Line         { return vr.graph }
+func (vr *counterViewer) SetStatsMgr(smgr *StatsMgr) { vr.smgr = smgr }
+
+func (vr *counterViewer) Collect() []Point {
+	return []Point{{Viewer: "counter", Series: "Count", Value: vr.value, Time: vr.smgr.CollectTime()}}
+}
+
+func (vr *counterViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	WriteJSON(w, NewMetrics([]float64{vr.value}, vr.smgr.CollectTime()))
+}
+
+func TestDeriveOncePerCollection(t *testing.T) {
+	start := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	inner := newCounterViewer()
+	smgr := &StatsMgr{time: start}
+	v := Derive(inner, "Count", Rate())
+	v.SetStatsMgr(smgr)
+	c := v.(Collector)
+
+	served := func() float64 {
+		rec := httptest.NewRecorder()
+		v.Serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		var m Metrics
+		if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		return m.Values[0]
+	}
+
+	tests := []struct {
+		advance time.Duration
+		value   float64
+		want    float64
+	}{
+		{0, 10, 0},
+		{2 * time.Second, 30, 10},
+		{4 * time.Second, 30, 0},
+		{time.Second, 35, 5},
+	}
+	for i, tt := range tests {
+		smgr.time = smgr.time.Add(tt.advance)
+		inner.value = tt.value
+		// every reader of a collection gets the same rate, e.g. two
+		// dashboards, the exporters and the history
+		for reader := 0; reader < 3; reader++ {
+			if got := c.Collect()[0].Value; got != tt.want {
+				t.Errorf("%d: collected rate = %v, want %v", i, got, tt.want)
+			}
+			if got := served(); got != tt.want {
+				t.Errorf("%d: served rate = %v, want %v", i, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	start := time.Unix(1000, 0)
+	tests := []struct {
+		name      string
+		transform Transform
+		times     []int
+		values    []float64
+		want      []float64
+	}{
+		{"rate", Rate(), []int{0, 2, 4, 5}, []float64{10, 14, 14, 24}, []float64{0, 2, 0, 10}},
+		{"rate same time", Rate(), []int{0, 0, 1}, []float64{1, 5, 6}, []float64{0, 0, 1}},
+		{"delta", Delta(), []int{0, 1, 2, 3}, []float64{5, 8, 6, 6}, []float64{0, 3, -2, 0}},
+		{"moving average", MovingAverage(3), []int{0, 1, 2, 3, 4}, []float64{3, 6, 9, 12, 0}, []float64{3, 4.5, 6, 9, 7}},
+		{"moving average of 1", MovingAverage(0), []int{0, 1, 2}, []float64{3, 6, 9}, []float64{3, 6, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.transform()
+			for i, v := range tt.values {
+				got := f(start.Add(time.Duration(tt.times[i])*time.Second), v)
+				if got != tt.want[i] {
+					t.Errorf("value %d: got %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDerive(t *testing.T) {
+	tests := []struct {
+		name    string
+		series  string
+		status  int
+		derived bool
+		values  [][]float64
+		want    [][]float64
+	}{
+		{
+			name:    "delta of count",
+			series:  "Count",
+			status:  http.StatusOK,
+			derived: true,
+			values:  [][]float64{{1, 10}, {2, 15}, {3, 15}},
+			want:    [][]float64{{1, 0}, {2, 5}, {3, 0}},
+		},
+		{
+			name:   "unknown series",
+			series: "Missing",
+			status: http.StatusOK,
+			values: [][]float64{{1, 10}, {2, 15}},
+			want:   [][]float64{{1, 10}, {2, 15}},
+		},
+		{
+			name:    "failed serve",
+			series:  "Sum",
+			status:  http.StatusInternalServerError,
+			derived: true,
+			values:  [][]float64{{1, 10}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := newConstViewer()
+			cv.status = tt.status
+			v := Derive(cv, tt.series, Delta())
+			if _, ok := v.(*DerivedViewer); ok != tt.derived {
+				t.Fatalf("derived = %v, want %v", ok, tt.derived)
+			}
+
+			for i, values := range tt.values {
+				cv.values = values
+				rec := httptest.NewRecorder()
+				v.Serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+				if rec.Code != tt.status {
+					t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+				}
+				if tt.status != http.StatusOK {
+					continue
+				}
+
+				var m Metrics
+				if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+					t.Fatal(err)
+				}
+				if len(m.Values) != 2 || m.Values[0] != tt.want[i][0] || m.Values[1] != tt.want[i][1] {
+					t.Errorf("served %v, want %v", m.Values, tt.want[i])
+				}
+
+				// collected points are derived separately from the served values
+				points := v.(Collector).Collect()
+				if points[0].Value != tt.want[i][0] || points[1].Value != tt.want[i][1] {
+					t.Errorf("collected %v, want %v", points, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// keptViewer serves the metrics it keeps, as a pointer
+type keptViewer struct {
+	*constViewer
+	kept Metrics
+}
+
+func (vr *keptViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	WriteJSON(w, &vr.kept)
+}
+
+func TestDeriveServedValues(t *testing.T) {
+	start := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		viewer func(values []float64) Viewer
+		values [][]float64
+		want   []float64
+	}{
+		{
+			name: "small rate unrounded",
+			viewer: func(values []float64) Viewer {
+				cv := newConstViewer()
+				cv.values = values
+				return cv
+			},
+			// 2^-22 per second, 0 once rounded to 6 decimals
+			values: [][]float64{{0, 0}, {1.0 / 4096, 0}},
+			want:   []float64{0, 1.0 / 4096 / 1024},
+		},
+		{
+			name: "value of the viewer",
+			viewer: func(values []float64) Viewer {
+				return &keptViewer{constViewer: newConstViewer(), kept: Metrics{Values: values, Time: "12:00:00"}}
+			},
+			values: [][]float64{{0, 0}, {2048, 0}},
+			want:   []float64{0, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := []float64{0, 0}
+			smgr := &StatsMgr{time: start}
+			v := Derive(tt.viewer(values), "Sum", Rate())
+			v.SetStatsMgr(smgr)
+
+			for i := range tt.values {
+				smgr.time = start.Add(time.Duration(i) * 1024 * time.Second)
+				copy(values, tt.values[i])
+				rec := httptest.NewRecorder()
+				v.Serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+				var m Metrics
+				if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+					t.Fatal(err)
+				}
+				if m.Values[0] != tt.want[i] {
+					t.Errorf("%d: served %v, want %v", i, m.Values[0], tt.want[i])
+				}
+				if values[0] != tt.values[i][0] {
+					t.Errorf("%d: the values of the viewer changed to %v", i, values)
+				}
+			}
+		})
+	}
+}
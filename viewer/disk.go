@@ -0,0 +1,80 @@
+package viewer
+
+import (
+	"net/http"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VDisk is the name of DiskViewer
+	VDisk = "disk"
+)
+
+// DiskViewer collects the used and the free space of the filesystems of
+// mount points, e.g. the ones of spill files or a WAL filling up
+type DiskViewer struct {
+	paths []string
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
+}
+
+// NewDiskViewer returns the DiskViewer of the paths, the working directory
+// without any. The free space is the one left to unprivileged users
+// Series: <path> Used / <path> Free per path
+func NewDiskViewer(paths []string, vopts ...ViewerOption) Viewer {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	o := newViewerOptions(vopts)
+	unit := o.unitOf(VDisk, DimensionBytes)
+	graph := o.newBasicView(VDisk)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Disk"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Size"}),
+	)
+	for _, p := range paths {
+		graph.AddSeries(p+" Used", []opts.LineData{}).
+			AddSeries(p+" Free", []opts.LineData{})
+	}
+
+	o.apply(graph)
+	formatSeries(graph, unit, DimensionBytes, nil)
+	return &DiskViewer{paths: paths, graph: graph, opts: o, unit: unit}
+}
+
+func (vr *DiskViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *DiskViewer) Name() string {
+	return VDisk
+}
+
+func (vr *DiskViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Collect returns the used and the free bytes of every path, 0 for the paths
+// which can't be read
+func (vr *DiskViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+	points := make([]Point, 0, 2*len(vr.paths))
+	for _, p := range vr.paths {
+		size, free, _ := diskUsage(p)
+		points = append(points,
+			Point{Viewer: VDisk, Series: p + " Used", Value: size - free, Time: t},
+			Point{Viewer: VDisk, Series: p + " Free", Value: free, Time: t},
+		)
+	}
+	return points
+}
+
+func (vr *DiskViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), vr.unit, 2)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,60 @@
+package viewer
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestDiskViewer(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	tests := []struct {
+		name   string
+		paths  []string
+		series []string
+		// readable tells which paths report a size
+		readable []bool
+	}{
+		{"working directory", nil, []string{". Used", ". Free"}, []bool{true}},
+		{"paths", []string{"/", missing}, []string{"/ Used", "/ Free", missing + " Used", missing + " Free"}, []bool{true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StatsMgr{time: time.Unix(1700000000, 0)}
+			vr := NewDiskViewer(tt.paths)
+			vr.SetStatsMgr(s)
+
+			var names []string
+			for _, series := range vr.View().MultiSeries {
+				names = append(names, series.Name)
+			}
+			if !reflect.DeepEqual(names, tt.series) {
+				t.Errorf("series = %v, want %v", names, tt.series)
+			}
+
+			points := vr.(Collector).Collect()
+			if len(points) != len(tt.series) {
+				t.Fatalf("collected %d points, want %d", len(points), len(tt.series))
+			}
+			for i, p := range points {
+				if p.Series != tt.series[i] || !p.Time.Equal(s.time) {
+					t.Errorf("point %d = %s at %v, want %s", i, p.Series, p.Time, tt.series[i])
+				}
+			}
+			if runtime.GOOS != "linux" {
+				return
+			}
+			for i, readable := range tt.readable {
+				used, free := points[2*i].Value, points[2*i+1].Value
+				if readable != (used+free > 0) {
+					t.Errorf("%s: used %v and free %v, want readable %v", tt.series[2*i], used, free, readable)
+				}
+				if used < 0 || free < 0 {
+					t.Errorf("%s: used %v and free %v, want non-negative sizes", tt.series[2*i], used, free)
+				}
+			}
+		})
+	}
+}
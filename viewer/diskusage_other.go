@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !windows
+
+package viewer
+
+func diskUsage(string) (size, free float64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,16 @@
+//go:build linux || darwin || freebsd || dragonfly
+
+package viewer
+
+import "syscall"
+
+// diskUsage returns the size of the filesystem of path and the space left
+// to unprivileged users in bytes
+func diskUsage(path string) (size, free float64, ok bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, false
+	}
+	bsize := float64(st.Bsize)
+	return float64(st.Blocks) * bsize, float64(st.Bavail) * bsize, true
+}
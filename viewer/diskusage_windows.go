@@ -0,0 +1,25 @@
+//go:build windows
+
+package viewer
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceEx = kernel32.NewProc("GetDiskFreeSpaceExW")
+
+// diskUsage returns the size of the volume of path and the space left to
+// the user of the process in bytes
+func diskUsage(path string) (size, free float64, ok bool) {
+	p, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	var available, total, totalFree uint64
+	if r, _, _ := procGetDiskFreeSpaceEx.Call(uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&available)), uintptr(unsafe.Pointer(&total)), uintptr(unsafe.Pointer(&totalFree))); r == 0 {
+		return 0, 0, false
+	}
+	return float64(total), float64(available), true
+}
@@ -0,0 +1,167 @@
+package viewer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VDNS is the name of DNSViewer
+	VDNS = "dns"
+)
+
+// DNSViewer is an active probe resolving hostnames on every interval, it
+// charts the lookup latency of every host and the failed lookups, since DNS
+// flakiness frequently masquerades as application slowness. It probes only
+// while the metrics are collected
+type DNSViewer struct {
+	// Resolver resolves the hosts, e.g. to probe a given server, it must be
+	// set before the viewer is served
+	Resolver *net.Resolver
+
+	hosts []string
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+
+	mu       sync.Mutex
+	latency  []float64
+	failures float64
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewDNSViewer returns the DNSViewer of the hosts resolved by
+// net.DefaultResolver, a lookup times out after the interval
+// Series: <host> per host / Failures
+func NewDNSViewer(hosts []string, vopts ...ViewerOption) *DNSViewer {
+	o := newViewerOptions(append([]ViewerOption{WithSecondaryAxis("Failures")}, vopts...))
+	graph := o.newBasicView(VDNS)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "DNS lookups"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Latency"}),
+	)
+	for _, h := range hosts {
+		graph.AddSeries(h, []opts.LineData{})
+	}
+	graph.AddSeries("Failures", []opts.LineData{})
+
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionSeconds, map[string]Dimension{"Failures": DimensionCount})
+	return &DNSViewer{
+		Resolver: net.DefaultResolver,
+		hosts:    hosts,
+		graph:    graph,
+		opts:     o,
+		latency:  make([]float64, len(hosts)),
+	}
+}
+
+func (vr *DNSViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *DNSViewer) Name() string {
+	return VDNS
+}
+
+func (vr *DNSViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Init starts probing
+func (vr *DNSViewer) Init(ctx context.Context) error {
+	ctx, vr.cancel = context.WithCancel(ctx)
+	vr.done = make(chan struct{})
+	go vr.probing(ctx)
+	return nil
+}
+
+// Close stops probing
+func (vr *DNSViewer) Close() error {
+	if vr.cancel != nil {
+		vr.cancel()
+		<-vr.done
+	}
+	return nil
+}
+
+func (vr *DNSViewer) probing(ctx context.Context) {
+	defer close(vr.done)
+
+	interval := vr.smgr.CurrentInterval()
+	ticker := vr.smgr.Clock().NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if vr.smgr.Collecting() {
+				vr.probe(ctx, time.Duration(interval)*time.Millisecond)
+			}
+			if current := vr.smgr.CurrentInterval(); current != interval {
+				interval = current
+				ticker.Reset(time.Duration(interval) * time.Millisecond)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// probe resolves the hosts concurrently, a failed lookup keeps the latency
+// it took to fail
+func (vr *DNSViewer) probe(ctx context.Context, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	latency := make([]float64, len(vr.hosts))
+	var failures int64
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, h := range vr.hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			start := time.Now()
+			_, err := vr.Resolver.LookupHost(ctx, host)
+			latency[i] = time.Since(start).Seconds()
+			if err != nil {
+				mu.Lock()
+				failures++
+				mu.Unlock()
+			}
+		}(i, h)
+	}
+	wg.Wait()
+
+	vr.mu.Lock()
+	vr.latency, vr.failures = latency, float64(failures)
+	vr.mu.Unlock()
+}
+
+// Collect returns the latency of the last lookup of every host in seconds
+// and the number of failed lookups
+func (vr *DNSViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	points := make([]Point, 0, len(vr.hosts)+1)
+	for i, h := range vr.hosts {
+		points = append(points, Point{Viewer: VDNS, Series: h, Value: vr.latency[i], Time: t})
+	}
+	return append(points, Point{Viewer: VDNS, Series: "Failures", Value: vr.failures, Time: t})
+}
+
+func (vr *DNSViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 6)
+
+	WriteJSON(w, metrics)
+}
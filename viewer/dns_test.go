@@ -0,0 +1,118 @@
+package viewer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// failingResolver fails every DNS query, after the context is done when it
+// hangs
+func failingResolver(hang bool) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			if hang {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return nil, errors.New("dns server unreachable")
+		},
+	}
+}
+
+func TestDNSViewerProbe(t *testing.T) {
+	tests := []struct {
+		name     string
+		hosts    []string
+		hang     bool
+		failures float64
+	}{
+		{"no hosts", nil, false, 0},
+		{"resolved", []string{"127.0.0.1", "::1"}, false, 0},
+		{"failed", []string{"127.0.0.1", "statsview.invalid", "other.invalid"}, false, 2},
+		{"timed out", []string{"statsview.invalid"}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vr := NewDNSViewer(tt.hosts)
+			vr.Resolver = failingResolver(tt.hang)
+			vr.SetStatsMgr(&StatsMgr{})
+
+			timeout := 50 * time.Millisecond
+			vr.probe(context.Background(), timeout)
+			points := vr.Collect()
+			if len(points) != len(tt.hosts)+1 {
+				t.Fatalf("collected %d points, want %d", len(points), len(tt.hosts)+1)
+			}
+			for i, h := range tt.hosts {
+				p := points[i]
+				if p.Series != h || p.Value <= 0 {
+					t.Errorf("point %d = %s %v, want the latency of %s", i, p.Series, p.Value, h)
+				}
+				if tt.hang && p.Value < timeout.Seconds()/2 {
+					t.Errorf("%s took %vs, want about the timeout of %v", h, p.Value, timeout)
+				}
+			}
+			if last := points[len(points)-1]; last.Series != "Failures" || last.Value != tt.failures {
+				t.Errorf("last point = %s %v, want Failures %v", last.Series, last.Value, tt.failures)
+			}
+		})
+	}
+}
+
+func TestDNSViewerProbing(t *testing.T) {
+	clock := &manualClock{now: time.Unix(1700000000, 0), c: make(chan time.Time)}
+	s := &StatsMgr{leases: make(map[string]time.Time), clock: clock}
+	vr := NewDNSViewer([]string{"statsview.invalid"})
+	vr.Resolver = failingResolver(false)
+	vr.SetStatsMgr(s)
+	if err := vr.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// a probe replaces the latencies
+	probed := func() *float64 {
+		vr.mu.Lock()
+		defer vr.mu.Unlock()
+		return &vr.latency[0]
+	}
+
+	tests := []struct {
+		name   string
+		lease  bool
+		probed bool
+	}{
+		{"idle", false, false},
+		{"collecting", true, true},
+		{"idle again", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.lease {
+				s.Lease("client", time.Minute)
+			} else {
+				s.Release("client")
+			}
+			// the probe of the tick of the previous case is done once this
+			// one is received
+			clock.c <- clock.Now()
+			before := probed()
+			clock.c <- clock.Now()
+			clock.c <- clock.Now() // the first tick is handled once the second is received
+			if probed := probed() != before; probed != tt.probed {
+				t.Errorf("probed = %v, want %v", probed, tt.probed)
+			}
+		})
+	}
+
+	if err := vr.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case clock.c <- clock.Now():
+		t.Error("still probing after Close")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -1,9 +1,7 @@
 package viewer
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -18,19 +16,23 @@ const (
 type GCCPUFractionViewer struct {
 	smgr  *StatsMgr
 	graph *charts.Line
+	opts  viewerOptions
 }
 
 // NewGCCPUFractionViewer returns the GCCPUFractionViewer instance
 // Series: Fraction
-func NewGCCPUFractionViewer() Viewer {
-	graph := NewBasicView(VGCCPUFraction)
+func NewGCCPUFractionViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(VGCCPUFraction)
 	graph.SetGlobalOptions(
 		charts.WithTitleOpts(opts.Title{Title: "GC CPUFraction"}),
-		charts.WithYAxisOpts(opts.YAxis{Name: "Percent", AxisLabel: &opts.AxisLabel{Formatter: "{value} %", Rotate: 35}}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Percent", AxisLabel: &opts.AxisLabel{Rotate: 35}}),
 	)
 	graph.AddSeries("Fraction", []opts.LineData{})
 
-	return &GCCPUFractionViewer{graph: graph}
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionRatio, nil)
+	return &GCCPUFractionViewer{graph: graph, opts: o}
 }
 
 func (vr *GCCPUFractionViewer) SetStatsMgr(smgr *StatsMgr) {
@@ -45,16 +47,18 @@ func (vr *GCCPUFractionViewer) View() *charts.Line {
 	return vr.graph
 }
 
-func (vr *GCCPUFractionViewer) Serve(w http.ResponseWriter, _ *http.Request) {
-	vr.smgr.Tick()
+// Collect returns the fraction of CPU time used by the GC
+func (vr *GCCPUFractionViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
 
-	memstats.mu.RLock()
-	metrics := Metrics{
-		Values: []float64{fixedPrecision(memstats.Stats.GCCPUFraction, 6)},
-		Time:   time.Unix(vr.smgr.GetTime(), 0).Format(TimeFormat()),
+	ms := vr.smgr.MemStats()
+	return []Point{
+		{Viewer: VGCCPUFraction, Series: "Fraction", Value: ms.GCCPUFraction, Time: t},
 	}
-	memstats.mu.RUnlock()
+}
+
+func (vr *GCCPUFractionViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 6)
 
-	bs, _ := json.Marshal(metrics)
-	w.Write(bs)
+	WriteJSON(w, metrics)
 }
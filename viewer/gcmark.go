@@ -0,0 +1,33 @@
+package viewer
+
+// GCMark reports the GC cycles which ran since the previous collection, they
+// are marked on the memory and GC charts
+type GCMark struct {
+	Count  uint32 `json:"count"`
+	Forced uint32 `json:"forced"`
+}
+
+// updateGCMark compares the fresh memstats with the previous ones, it must
+// be called with statsMu held
+func (s *StatsMgr) updateGCMark(prevNumGC, prevForced uint32, first bool) {
+	s.gcMark = GCMark{}
+	if first {
+		// the cycles before the first collection aren't on the charts
+		return
+	}
+	s.gcMark = GCMark{
+		Count:  s.memstats.NumGC - prevNumGC,
+		Forced: s.memstats.NumForcedGC - prevForced,
+	}
+}
+
+// GCMark returns the GC cycles of the last collection, nil if none ran
+func (s *StatsMgr) GCMark() *GCMark {
+	s.statsMu.RLock()
+	defer s.statsMu.RUnlock()
+	if s.gcMark.Count == 0 {
+		return nil
+	}
+	m := s.gcMark
+	return &m
+}
@@ -0,0 +1,52 @@
+package viewer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGCMark(t *testing.T) {
+	tests := []struct {
+		name              string
+		first             bool
+		prevNumGC, numGC  uint32
+		prevForced, force uint32
+		want              *GCMark
+	}{
+		{name: "first collection", first: true, prevNumGC: 0, numGC: 7, want: nil},
+		{name: "no cycle", prevNumGC: 7, numGC: 7, want: nil},
+		{name: "cycles", prevNumGC: 7, numGC: 9, want: &GCMark{Count: 2}},
+		{name: "forced", prevNumGC: 7, numGC: 8, prevForced: 1, force: 2, want: &GCMark{Count: 1, Forced: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StatsMgr{}
+			s.memstats = runtime.MemStats{NumGC: tt.numGC, NumForcedGC: tt.force}
+			s.updateGCMark(tt.prevNumGC, tt.prevForced, tt.first)
+
+			got := s.GCMark()
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("GCMark() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeGCMark(t *testing.T) {
+	s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock}
+	s.memstats = runtime.MemStats{NumGC: 3, NumForcedGC: 1}
+	s.updateGCMark(2, 0, false)
+
+	for _, v := range []Viewer{NewGCNumViewer(), NewGCSizeViewer(), NewHeapViewer()} {
+		v.SetStatsMgr(s)
+		rec := httptest.NewRecorder()
+		v.Serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if !strings.Contains(rec.Body.String(), `"gc":{"count":1,"forced":1}`) {
+			t.Errorf("%s served %s without the GC mark", v.Name(), rec.Body)
+		}
+	}
+}
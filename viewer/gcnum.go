@@ -1,9 +1,7 @@
 package viewer
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -18,19 +16,23 @@ const (
 type GCNumViewer struct {
 	smgr  *StatsMgr
 	graph *charts.Line
+	opts  viewerOptions
 }
 
 // NewGCNumViewer returns the GCNumViewer instance
 // Series: GcNum
-func NewGCNumViewer() Viewer {
-	graph := NewBasicView(VGCNum)
+func NewGCNumViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(VGCNum)
 	graph.SetGlobalOptions(
 		charts.WithTitleOpts(opts.Title{Title: "GC Number"}),
 		charts.WithYAxisOpts(opts.YAxis{Name: "Num"}),
 	)
 	graph.AddSeries("GcNum", []opts.LineData{})
 
-	return &GCNumViewer{graph: graph}
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionCount, nil)
+	return &GCNumViewer{graph: graph, opts: o}
 }
 
 func (vr *GCNumViewer) SetStatsMgr(smgr *StatsMgr) {
@@ -45,16 +47,19 @@ func (vr *GCNumViewer) View() *charts.Line {
 	return vr.graph
 }
 
-func (vr *GCNumViewer) Serve(w http.ResponseWriter, _ *http.Request) {
-	vr.smgr.Tick()
+// Collect returns the number of completed GC cycles
+func (vr *GCNumViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
 
-	memstats.mu.RLock()
-	metrics := Metrics{
-		Values: []float64{float64(memstats.Stats.NumGC)},
-		Time:   time.Unix(vr.smgr.GetTime(), 0).Format(TimeFormat()),
+	ms := vr.smgr.MemStats()
+	return []Point{
+		{Viewer: VGCNum, Series: "GcNum", Value: float64(ms.NumGC), Time: t},
 	}
-	memstats.mu.RUnlock()
+}
+
+func (vr *GCNumViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 0)
+	metrics.GC = vr.smgr.GCMark()
 
-	bs, _ := json.Marshal(metrics)
-	w.Write(bs)
+	WriteJSON(w, metrics)
 }
@@ -0,0 +1,114 @@
+package viewer
+
+import (
+	"net/http"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VGCCycles is the name of GCCyclesViewer
+	VGCCycles = "gccycles"
+	// VGCCPU is the name of GCCPUViewer
+	VGCCPU = "gccpu"
+)
+
+var gcCyclesSeries = []runtimeSeries{
+	{Name: "Automatic", Metric: "/gc/cycles/automatic:gc-cycles"},
+	{Name: "Forced", Metric: "/gc/cycles/forced:gc-cycles"},
+	{Name: "Total", Metric: "/gc/cycles/total:gc-cycles"},
+}
+
+var gcCPUSeries = []runtimeSeries{
+	{Name: "MarkAssist", Metric: "/cpu/classes/gc/mark/assist:cpu-seconds"},
+	{Name: "MarkDedicated", Metric: "/cpu/classes/gc/mark/dedicated:cpu-seconds"},
+	{Name: "MarkIdle", Metric: "/cpu/classes/gc/mark/idle:cpu-seconds"},
+	{Name: "Pause", Metric: "/cpu/classes/gc/pause:cpu-seconds"},
+	{Name: "ScavengeAssist", Metric: "/cpu/classes/scavenge/assist:cpu-seconds"},
+	{Name: "ScavengeBackground", Metric: "/cpu/classes/scavenge/background:cpu-seconds"},
+}
+
+// runtimeViewer charts runtime/metrics series, as they are or as per second
+// rates of cumulative metrics
+type runtimeViewer struct {
+	name   string
+	series []runtimeSeries
+	rate   bool
+	reader *runtimeReader
+	smgr   *StatsMgr
+	graph  *charts.Line
+	opts   viewerOptions
+}
+
+func newRuntimeViewer(name, title, yAxis string, series []runtimeSeries, rate bool, vopts []ViewerOption) *runtimeViewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(name)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: title}),
+		charts.WithYAxisOpts(opts.YAxis{Name: yAxis}),
+	)
+	for _, s := range series {
+		graph.AddSeries(s.Name, []opts.LineData{})
+	}
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionCount, nil)
+	return &runtimeViewer{
+		name:   name,
+		series: series,
+		rate:   rate,
+		reader: newRuntimeReader(series),
+		graph:  graph,
+		opts:   o,
+	}
+}
+
+// NewGCCyclesViewer returns the viewer of the GC cycles via `runtime/metrics`
+// Series: Automatic / Forced / Total
+func NewGCCyclesViewer(vopts ...ViewerOption) Viewer {
+	return newRuntimeViewer(VGCCycles, "GC Cycles", "Cycles", gcCyclesSeries, false, vopts)
+}
+
+// NewGCCPUViewer returns the viewer of the CPU spent by the GC and the
+// scavenger by phase via `runtime/metrics`, in CPU cores (CPU seconds per
+// second). It breaks down what GCCPUFraction hides, e.g. mutators slowed by
+// mark assists
+// Series: MarkAssist / MarkDedicated / MarkIdle / Pause / ScavengeAssist / ScavengeBackground
+func NewGCCPUViewer(vopts ...ViewerOption) Viewer {
+	return newRuntimeViewer(VGCCPU, "GC CPU", "Cores", gcCPUSeries, true, vopts)
+}
+
+func (vr *runtimeViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *runtimeViewer) Name() string {
+	return vr.name
+}
+
+func (vr *runtimeViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Collect returns the values of the last collection
+func (vr *runtimeViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+
+	var values []float64
+	if vr.rate {
+		values = vr.reader.rates(t)
+	} else {
+		values = vr.reader.read(t)
+	}
+	points := make([]Point, 0, len(values))
+	for i, v := range values {
+		points = append(points, Point{Viewer: vr.name, Series: vr.series[i].Name, Value: v, Time: t})
+	}
+	return points
+}
+
+func (vr *runtimeViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 6)
+
+	WriteJSON(w, metrics)
+}
@@ -1,9 +1,7 @@
 package viewer
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -18,20 +16,26 @@ const (
 type GCSizeViewer struct {
 	smgr  *StatsMgr
 	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
 }
 
 // NewGCSizeViewer returns the GCSizeViewer instance
 // Series: GCSys / NextGC
-func NewGCSizeViewer() Viewer {
-	graph := NewBasicView(VGCSize)
+func NewGCSizeViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	unit := o.unitOf(VGCSize, DimensionBytes)
+	graph := o.newBasicView(VGCSize)
 	graph.SetGlobalOptions(
 		charts.WithTitleOpts(opts.Title{Title: "GC Size"}),
-		charts.WithYAxisOpts(opts.YAxis{Name: "Size", AxisLabel: &opts.AxisLabel{Formatter: "{value} MB"}}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Size"}),
 	)
 	graph.AddSeries("GCSys", []opts.LineData{}).
 		AddSeries("NextGC", []opts.LineData{})
 
-	return &GCSizeViewer{graph: graph}
+	o.apply(graph)
+	formatSeries(graph, unit, DimensionBytes, nil)
+	return &GCSizeViewer{graph: graph, opts: o, unit: unit}
 }
 
 func (vr *GCSizeViewer) SetStatsMgr(smgr *StatsMgr) {
@@ -46,19 +50,20 @@ func (vr *GCSizeViewer) View() *charts.Line {
 	return vr.graph
 }
 
-func (vr *GCSizeViewer) Serve(w http.ResponseWriter, _ *http.Request) {
-	vr.smgr.Tick()
+// Collect returns the GC size metrics in bytes
+func (vr *GCSizeViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
 
-	memstats.mu.RLock()
-	metrics := Metrics{
-		Values: []float64{
-			fixedPrecision(float64(memstats.Stats.GCSys)/1024/1024, 2),
-			fixedPrecision(float64(memstats.Stats.NextGC)/1024/1024, 2),
-		},
-		Time: time.Unix(vr.smgr.GetTime(), 0).Format(TimeFormat()),
+	ms := vr.smgr.MemStats()
+	return []Point{
+		{Viewer: VGCSize, Series: "GCSys", Value: float64(ms.GCSys), Time: t},
+		{Viewer: VGCSize, Series: "NextGC", Value: float64(ms.NextGC), Time: t},
 	}
-	memstats.mu.RUnlock()
+}
+
+func (vr *GCSizeViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), vr.unit, 2)
+	metrics.GC = vr.smgr.GCMark()
 
-	bs, _ := json.Marshal(metrics)
-	w.Write(bs)
+	WriteJSON(w, metrics)
 }
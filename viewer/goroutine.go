@@ -1,10 +1,7 @@
 package viewer
 
 import (
-	"encoding/json"
 	"net/http"
-	"runtime"
-	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -19,19 +16,23 @@ const (
 type GoroutinesViewer struct {
 	smgr  *StatsMgr
 	graph *charts.Line
+	opts  viewerOptions
 }
 
 // NewGoroutinesViewer returns the GoroutinesViewer instance
 // Series: Goroutines
-func NewGoroutinesViewer() Viewer {
-	graph := NewBasicView(VGoroutine)
+func NewGoroutinesViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(VGoroutine)
 	graph.SetGlobalOptions(
 		charts.WithYAxisOpts(opts.YAxis{Name: "Num"}),
 		charts.WithTitleOpts(opts.Title{Title: "Goroutines"}),
 	)
 	graph.AddSeries("Goroutines", []opts.LineData{})
 
-	return &GoroutinesViewer{graph: graph}
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionCount, nil)
+	return &GoroutinesViewer{graph: graph, opts: o}
 }
 
 func (vr *GoroutinesViewer) SetStatsMgr(smgr *StatsMgr) {
@@ -46,14 +47,15 @@ func (vr *GoroutinesViewer) View() *charts.Line {
 	return vr.graph
 }
 
-func (vr *GoroutinesViewer) Serve(w http.ResponseWriter, _ *http.Request) {
-	vr.smgr.Tick()
-
-	metrics := Metrics{
-		Values: []float64{float64(runtime.NumGoroutine())},
-		Time:   time.Unix(vr.smgr.GetTime(), 0).Format(TimeFormat()),
+// Collect returns the number of goroutines
+func (vr *GoroutinesViewer) Collect() []Point {
+	return []Point{
+		{Viewer: VGoroutine, Series: "Goroutines", Value: float64(vr.smgr.NumGoroutine()), Time: vr.smgr.CollectTime()},
 	}
+}
+
+func (vr *GoroutinesViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 0)
 
-	bs, _ := json.Marshal(metrics)
-	w.Write(bs)
+	WriteJSON(w, metrics)
 }
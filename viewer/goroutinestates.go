@@ -0,0 +1,113 @@
+package viewer
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/mortum5/statsview/internal/goroutine"
+)
+
+const (
+	// VGoroutineStates is the name of GoroutineStatesViewer
+	VGoroutineStates = "goroutine-states"
+)
+
+// GoroutineStatesViewer collects the goroutines by state from the goroutine
+// profile: running, runnable or waiting on a channel, a select, the network,
+// a syscall, a sync primitive or a sleep. A flat count hides whether the
+// goroutines are stuck or busy
+type GoroutineStatesViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+
+	mu     sync.Mutex
+	at     time.Time
+	counts []float64
+}
+
+// NewGoroutineStatesViewer returns the GoroutineStatesViewer instance, the
+// states are stacked up to the total
+// Series: Running / Runnable / Chan / Select / IOWait / Syscall / Sync / Sleep / Other
+func NewGoroutineStatesViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(VGoroutineStates)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Goroutine states"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Num"}),
+	)
+	for _, c := range goroutine.Categories {
+		graph.AddSeries(c, []opts.LineData{})
+	}
+	for i := range graph.MultiSeries {
+		graph.MultiSeries[i].Stack = "states"
+		graph.MultiSeries[i].AreaStyle = &opts.AreaStyle{Opacity: 0.5}
+	}
+
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionCount, nil)
+	return &GoroutineStatesViewer{graph: graph, opts: o}
+}
+
+func (vr *GoroutineStatesViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *GoroutineStatesViewer) Name() string {
+	return VGoroutineStates
+}
+
+func (vr *GoroutineStatesViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Priority is low since the goroutine profile walks every stack
+func (vr *GoroutineStatesViewer) Priority() Priority {
+	return PriorityLow
+}
+
+// Collect returns the number of goroutines in every state, the profile is
+// read once per collection
+func (vr *GoroutineStatesViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+
+	vr.mu.Lock()
+	if vr.counts == nil || !t.Equal(vr.at) {
+		vr.at, vr.counts = t, countStates()
+	}
+	counts := vr.counts
+	vr.mu.Unlock()
+
+	points := make([]Point, len(goroutine.Categories))
+	for i, c := range goroutine.Categories {
+		points[i] = Point{Viewer: VGoroutineStates, Series: c, Value: counts[i], Time: t}
+	}
+	return points
+}
+
+// countStates counts the goroutines of a snapshot by category
+func countStates() []float64 {
+	counts := make([]float64, len(goroutine.Categories))
+	gs, err := goroutine.Capture()
+	if err != nil {
+		Logger().Error("statsview: failed to read profile", "profile", "goroutine", "err", err)
+		return counts
+	}
+	index := make(map[string]int, len(goroutine.Categories))
+	for i, c := range goroutine.Categories {
+		index[c] = i
+	}
+	for _, g := range gs {
+		counts[index[goroutine.Category(g.State)]]++
+	}
+	return counts
+}
+
+func (vr *GoroutineStatesViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 0)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,69 @@
+package viewer
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mortum5/statsview/internal/goroutine"
+)
+
+func TestGoroutineStatesViewer(t *testing.T) {
+	s := &StatsMgr{time: time.Unix(1700000000, 0)}
+	vr := NewGoroutineStatesViewer()
+	vr.SetStatsMgr(s)
+	states := func() map[string]float64 {
+		counts := make(map[string]float64)
+		for _, p := range vr.(Collector).Collect() {
+			counts[p.Series] = p.Value
+		}
+		return counts
+	}
+
+	ch := make(chan struct{})
+	var mu sync.Mutex
+	mu.Lock()
+	tests := []struct {
+		name  string
+		start func()
+		// state is the category of the goroutines started
+		state string
+	}{
+		{"chan", func() { <-ch }, "Chan"},
+		{"select", func() {
+			select {
+			case <-ch:
+			case <-time.After(time.Hour):
+			}
+		}, "Select"},
+		{"sync", func() { mu.Lock(); mu.Unlock() }, "Sync"},
+		{"sleep", func() { time.Sleep(time.Hour) }, "Sleep"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := states()[tt.state]
+			for i := 0; i < 3; i++ {
+				go tt.start()
+			}
+			// the goroutines block soon after they're started
+			var got float64
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+				s.time = s.time.Add(time.Second)
+				if got = states()[tt.state]; got >= before+3 {
+					break
+				}
+			}
+			if got < before+3 {
+				t.Errorf("%v goroutines in %s, want at least %v", got, tt.state, before+3)
+			}
+		})
+	}
+	close(ch)
+	mu.Unlock()
+
+	// the profile is read once per collection
+	counts := states()
+	if again := states(); len(again) != len(goroutine.Categories) || again["Sleep"] != counts["Sleep"] {
+		t.Errorf("collected %v then %v at the same time", counts, again)
+	}
+}
@@ -1,9 +1,7 @@
 package viewer
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -18,22 +16,40 @@ const (
 type HeapViewer struct {
 	smgr  *StatsMgr
 	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
 }
 
 // NewHeapViewer returns the HeapViewer instance
-// Series: Alloc / Inuse / Sys / Idle
-func NewHeapViewer() Viewer {
-	graph := NewBasicView(VHeap)
+// Series: Alloc / Inuse / Sys / Idle / NextGC
+func NewHeapViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	unit := o.unitOf(VHeap, DimensionBytes)
+	graph := o.newBasicView(VHeap)
 	graph.SetGlobalOptions(
 		charts.WithTitleOpts(opts.Title{Title: "Heap"}),
-		charts.WithYAxisOpts(opts.YAxis{Name: "Size", AxisLabel: &opts.AxisLabel{Formatter: "{value} MB"}}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Size"}),
 	)
+	// the container memory limit is drawn as a horizontal line on Sys, which is
+	// the closest to the memory the OOM killer accounts
+	var sysOpts []charts.SeriesOpts
+	if limit, _ := ContainerLimits(); limit > 0 {
+		sysOpts = append(sysOpts, charts.WithMarkLineNameYAxisItemOpts(opts.MarkLineNameYAxisItem{
+			Name:  "Memory limit",
+			YAxis: fixedPrecision(unit.Convert(limit), 2),
+		}))
+	}
+	// NextGC is the heap target of the next GC, dashed to show how close
+	// Alloc gets to it and how GOGC/GOMEMLIMIT changes move it
 	graph.AddSeries("Alloc", []opts.LineData{}).
 		AddSeries("Inuse", []opts.LineData{}).
-		AddSeries("Sys", []opts.LineData{}).
-		AddSeries("Idle", []opts.LineData{})
+		AddSeries("Sys", []opts.LineData{}, sysOpts...).
+		AddSeries("Idle", []opts.LineData{}).
+		AddSeries("NextGC", []opts.LineData{}, charts.WithLineStyleOpts(opts.LineStyle{Type: "dashed"}))
 
-	return &HeapViewer{graph: graph}
+	o.apply(graph)
+	formatSeries(graph, unit, DimensionBytes, nil)
+	return &HeapViewer{graph: graph, opts: o, unit: unit}
 }
 func (vr *HeapViewer) SetStatsMgr(smgr *StatsMgr) {
 	vr.smgr = smgr
@@ -46,21 +62,23 @@ func (vr *HeapViewer) View() *charts.Line {
 	return vr.graph
 }
 
-func (vr *HeapViewer) Serve(w http.ResponseWriter, _ *http.Request) {
-	vr.smgr.Tick()
+// Collect returns the heap metrics in bytes
+func (vr *HeapViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
 
-	memstats.mu.RLock()
-	metrics := Metrics{
-		Values: []float64{
-			fixedPrecision(float64(memstats.Stats.HeapAlloc)/1024/1024, 2),
-			fixedPrecision(float64(memstats.Stats.HeapInuse)/1024/1024, 2),
-			fixedPrecision(float64(memstats.Stats.HeapSys)/1024/1024, 2),
-			fixedPrecision(float64(memstats.Stats.HeapIdle)/1024/1024, 2),
-		},
-		Time: time.Unix(vr.smgr.GetTime(), 0).Format(TimeFormat()),
+	ms := vr.smgr.MemStats()
+	return []Point{
+		{Viewer: VHeap, Series: "Alloc", Value: float64(ms.HeapAlloc), Time: t},
+		{Viewer: VHeap, Series: "Inuse", Value: float64(ms.HeapInuse), Time: t},
+		{Viewer: VHeap, Series: "Sys", Value: float64(ms.HeapSys), Time: t},
+		{Viewer: VHeap, Series: "Idle", Value: float64(ms.HeapIdle), Time: t},
+		{Viewer: VHeap, Series: "NextGC", Value: float64(ms.NextGC), Time: t},
 	}
-	memstats.mu.RUnlock()
+}
+
+func (vr *HeapViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), vr.unit, 2)
+	metrics.GC = vr.smgr.GCMark()
 
-	bs, _ := json.Marshal(metrics)
-	w.Write(bs)
+	WriteJSON(w, metrics)
 }
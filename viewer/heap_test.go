@@ -0,0 +1,27 @@
+package viewer
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestHeapViewer(t *testing.T) {
+	s := &StatsMgr{}
+	s.memstats = runtime.MemStats{HeapAlloc: 1, HeapInuse: 2, HeapSys: 3, HeapIdle: 4, NextGC: 5}
+	v := NewHeapViewer()
+	v.SetStatsMgr(s)
+
+	points := v.(Collector).Collect()
+	series := v.View().MultiSeries
+	if len(points) != len(series) {
+		t.Fatalf("collected %d points for %d series", len(points), len(series))
+	}
+	for i, p := range points {
+		if p.Series != series[i].Name || p.Value != float64(i+1) {
+			t.Errorf("point %d = %s %v, want %s %d", i, p.Series, p.Value, series[i].Name, i+1)
+		}
+	}
+	if last := series[len(series)-1]; last.Name != "NextGC" || last.LineStyle == nil || last.LineStyle.Type != "dashed" {
+		t.Errorf("the last series is %s, want the dashed NextGC", last.Name)
+	}
+}
@@ -0,0 +1,129 @@
+package viewer
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// VHostCPU is the name of the per core CPU usage ProcViewer
+	VHostCPU = "host-cpu"
+	// VHostLoad is the name of the load average ProcViewer
+	VHostLoad = "host-load"
+	// VHostMemory is the name of the host memory ProcViewer
+	VHostMemory = "host-memory"
+)
+
+// NewHostCPUViewer returns the ProcViewer of the usage of every core of the
+// machine between the last two collections from /proc/stat, the time spent
+// neither idle nor waiting for I/O
+// Series: cpu0 / cpu1 / ... per core
+func NewHostCPUViewer(vopts ...ViewerOption) Viewer {
+	cores := len(readCPUTimes())
+	if cores == 0 {
+		cores = runtime.NumCPU()
+	}
+	series := make([]string, cores)
+	for i := range series {
+		series[i] = "cpu" + strconv.Itoa(i)
+	}
+
+	var prev []cpuTimes
+	return newProcGauge(VHostCPU, "Host CPU", DimensionRatio, series, func() ([]float64, bool) {
+		cur := readCPUTimes()
+		if len(cur) == 0 {
+			return nil, false
+		}
+		usage := make([]float64, len(series))
+		for i := range usage {
+			if i >= len(cur) || i >= len(prev) {
+				continue
+			}
+			if total := cur[i].total - prev[i].total; total > 0 {
+				usage[i] = 1 - (cur[i].idle-prev[i].idle)/total
+			}
+		}
+		prev = cur
+		return usage, true
+	}, vopts)
+}
+
+// cpuTimes are the jiffies a core spent in total and idle or waiting for I/O
+type cpuTimes struct {
+	total, idle float64
+}
+
+// readCPUTimes returns the times of the cores of /proc/stat, the guest times
+// are included in the user ones already
+func readCPUTimes() []cpuTimes {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var times []cpuTimes
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// cpuN user nice system idle iowait irq softirq steal guest guest_nice
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 9 || !strings.HasPrefix(fields[0], "cpu") || fields[0] == "cpu" {
+			continue
+		}
+		var t cpuTimes
+		for i, field := range fields[1:9] {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil
+			}
+			t.total += v
+			if i == 3 || i == 4 {
+				t.idle += v
+			}
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+// NewHostLoadViewer returns the ProcViewer of the load average of the
+// machine over 1, 5 and 15 minutes from /proc/loadavg
+// Series: Load1 / Load5 / Load15
+func NewHostLoadViewer(vopts ...ViewerOption) Viewer {
+	return newProcGauge(VHostLoad, "Load average", DimensionCount,
+		[]string{"Load1", "Load5", "Load15"}, func() ([]float64, bool) {
+			bs, err := os.ReadFile("/proc/loadavg")
+			if err != nil {
+				return nil, false
+			}
+			fields := strings.Fields(string(bs))
+			if len(fields) < 3 {
+				return nil, false
+			}
+			loads := make([]float64, 3)
+			for i := range loads {
+				if loads[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+					return nil, false
+				}
+			}
+			return loads, true
+		}, vopts)
+}
+
+// NewHostMemoryViewer returns the ProcViewer of the memory of the machine
+// from /proc/meminfo: the used one, the one available to start new
+// applications without swapping, and the used swap
+// Series: Used / Available / SwapUsed
+func NewHostMemoryViewer(vopts ...ViewerOption) Viewer {
+	return newProcGauge(VHostMemory, "Host memory", DimensionBytes,
+		[]string{"Used", "Available", "SwapUsed"}, func() ([]float64, bool) {
+			m, ok := procKeyValues("/proc/meminfo", "MemTotal", "MemAvailable", "SwapTotal", "SwapFree")
+			if !ok {
+				return nil, false
+			}
+			return []float64{m[0] - m[1], m[1], m[2] - m[3]}, true
+		}, vopts)
+}
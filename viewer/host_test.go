@@ -0,0 +1,90 @@
+package viewer
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestReadCPUTimes(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("/proc/stat is linux only")
+	}
+	times := readCPUTimes()
+	if len(times) == 0 {
+		t.Fatal("readCPUTimes() read no core")
+	}
+	for i, ct := range times {
+		if ct.total <= 0 || ct.idle > ct.total {
+			t.Errorf("core %d: total %v, idle %v", i, ct.total, ct.idle)
+		}
+	}
+}
+
+func TestHostViewers(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the host viewers read /proc")
+	}
+	tests := []struct {
+		name   string
+		viewer Viewer
+		series []string
+		check  func(values []float64) bool
+	}{
+		{
+			name:   VHostLoad,
+			viewer: NewHostLoadViewer(),
+			series: []string{"Load1", "Load5", "Load15"},
+			check: func(values []float64) bool {
+				return values[0] >= 0 && values[1] >= 0 && values[2] >= 0
+			},
+		},
+		{
+			name:   VHostMemory,
+			viewer: NewHostMemoryViewer(),
+			series: []string{"Used", "Available", "SwapUsed"},
+			check: func(values []float64) bool {
+				return values[0] > 0 && values[1] > 0 && values[2] >= 0
+			},
+		},
+		{
+			name:   VHostCPU,
+			viewer: NewHostCPUViewer(),
+			check: func(values []float64) bool {
+				for _, v := range values {
+					if v < 0 || v > 1 {
+						return false
+					}
+				}
+				return true
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StatsMgr{time: time.Unix(1700000000, 0)}
+			tt.viewer.SetStatsMgr(s)
+			collector := tt.viewer.(Collector)
+			collector.Collect()
+			s.time = s.time.Add(time.Second)
+			points := collector.Collect()
+
+			if tt.series != nil && len(points) != len(tt.series) {
+				t.Fatalf("collected %d points, want %d", len(points), len(tt.series))
+			}
+			if tt.series == nil && len(points) != len(readCPUTimes()) {
+				t.Fatalf("collected %d points, want one per core", len(points))
+			}
+			values := make([]float64, len(points))
+			for i, p := range points {
+				if p.Viewer != tt.name || (tt.series != nil && p.Series != tt.series[i]) {
+					t.Errorf("point %d = %s %s", i, p.Viewer, p.Series)
+				}
+				values[i] = p.Value
+			}
+			if !tt.check(values) {
+				t.Errorf("Collect() = %v", values)
+			}
+		})
+	}
+}
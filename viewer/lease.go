@@ -0,0 +1,140 @@
+package viewer
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultLeaseTTL is the lease duration granted to a client which didn't ask for one
+	DefaultLeaseTTL = 15 * time.Second
+	// MaxLeaseTTL bounds the lease duration a client could ask for
+	MaxLeaseTTL = 10 * time.Minute
+	// MaxLeases bounds the leases held at once, a new client is refused
+	// beyond, the collection runs anyway then
+	MaxLeases = 1000
+)
+
+// ErrTooManyLeases is returned by Lease to a new client once MaxLeases are held
+var ErrTooManyLeases = errors.New("statsview: too many leases")
+
+// Lease keeps the collection running for ttl on behalf of the client, a
+// client renews its lease by calling Lease again. A new client is refused
+// once MaxLeases unexpired leases are held
+func (s *StatsMgr) Lease(client string, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+	if ttl > MaxLeaseTTL {
+		ttl = MaxLeaseTTL
+	}
+	now := s.clock.Now()
+	expires := now.Add(ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	held, ok := s.leases[client]
+	if !ok {
+		s.pruneLocked(now)
+		if len(s.leases) >= MaxLeases {
+			return ErrTooManyLeases
+		}
+	}
+	if held.Before(expires) {
+		s.leases[client] = expires
+	}
+	if s.idle.Before(expires) {
+		s.idle = expires
+	}
+	return nil
+}
+
+// Release drops the lease of the client, e.g. when the dashboard is closed
+func (s *StatsMgr) Release(client string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.leases[client]
+	if !ok {
+		return
+	}
+	delete(s.leases, client)
+
+	// only the lease expiring last moves the idle time
+	if expires.Equal(s.idle) {
+		s.idle = time.Time{}
+		for _, e := range s.leases {
+			if e.After(s.idle) {
+				s.idle = e
+			}
+		}
+	}
+}
+
+// pruneLocked drops the expired leases
+func (s *StatsMgr) pruneLocked(now time.Time) {
+	for client, expires := range s.leases {
+		if now.After(expires) {
+			delete(s.leases, client)
+		}
+	}
+}
+
+// Clients returns the number of clients holding a lease
+func (s *StatsMgr) Clients() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(s.clock.Now())
+	return len(s.leases)
+}
+
+// Collecting reports whether the metrics are collected, i.e. a lease is
+// held or WithAlwaysCollect is set
+func (s *StatsMgr) Collecting() bool {
+	return AlwaysCollect() || s.clock.Now().Before(s.idleTime())
+}
+
+// idleTime returns the time the last lease expires
+func (s *StatsMgr) idleTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.idle
+}
+
+// ServeLease renews the collection lease of `client` for `ttl` seconds
+// (default DefaultLeaseTTL), `release=1` drops it. A new client is answered
+// 429 Too Many Requests once MaxLeases are held
+func (s *StatsMgr) ServeLease(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "statsview: lease requires POST", http.StatusMethodNotAllowed)
+		return
+	}
+	client := r.FormValue("client")
+	if client == "" || len(client) > 64 {
+		http.Error(w, "statsview: invalid client", http.StatusBadRequest)
+		return
+	}
+
+	if r.FormValue("release") != "" {
+		s.Release(client)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var ttl time.Duration
+	if v := r.FormValue("ttl"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			http.Error(w, "statsview: invalid ttl", http.StatusBadRequest)
+			return
+		}
+		ttl = time.Duration(secs) * time.Second
+	}
+	if err := s.Lease(client, ttl); err != nil {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,153 @@
+package viewer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedClock is a Clock stopped at now
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time                 { return c.now }
+func (c *fixedClock) NewTicker(time.Duration) Ticker { return nil }
+
+func TestLease(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)}
+	s := &StatsMgr{leases: make(map[string]time.Time), clock: clock}
+
+	tests := []struct {
+		name       string
+		do         func() error
+		wantErr    error
+		clients    int
+		collecting bool
+	}{
+		{"no lease", func() error { return nil }, nil, 0, false},
+		{"lease", func() error { return s.Lease("a", time.Minute) }, nil, 1, true},
+		{"shorter renewal kept", func() error { return s.Lease("a", time.Second) }, nil, 1, true},
+		{"second client", func() error { return s.Lease("b", 2*time.Minute) }, nil, 2, true},
+		{"release of the last expiring", func() error { s.Release("b"); return nil }, nil, 1, true},
+		{"expired", func() error { clock.now = clock.now.Add(2 * time.Minute); return nil }, nil, 0, false},
+		{"release unknown", func() error { s.Release("c"); return nil }, nil, 0, false},
+		{"full", func() error {
+			for i := 0; i < MaxLeases; i++ {
+				if err := s.Lease(fmt.Sprint(i), time.Minute); err != nil {
+					return err
+				}
+			}
+			return s.Lease("late", time.Minute)
+		}, ErrTooManyLeases, MaxLeases, true},
+		{"renewal when full", func() error { return s.Lease("0", time.Minute) }, nil, MaxLeases, true},
+		{"pruned on insert", func() error {
+			clock.now = clock.now.Add(2 * time.Minute)
+			return s.Lease("late", time.Minute)
+		}, nil, 1, true},
+	}
+	for _, tt := range tests {
+		if err := tt.do(); err != tt.wantErr {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if got := s.Clients(); got != tt.clients {
+			t.Errorf("%s: clients = %d, want %d", tt.name, got, tt.clients)
+		}
+		if got := clock.now.Before(s.idleTime()); got != tt.collecting {
+			t.Errorf("%s: collecting = %v, want %v", tt.name, got, tt.collecting)
+		}
+	}
+}
+
+func TestLeaseTTL(t *testing.T) {
+	defer func(always bool) { defaultCfg.AlwaysCollect = always }(defaultCfg.AlwaysCollect)
+
+	type lease struct {
+		client string
+		ttl    time.Duration
+	}
+	tests := []struct {
+		name       string
+		always     bool
+		leases     []lease
+		tick       bool
+		release    string
+		clients    int
+		collecting bool
+		idleIn     time.Duration
+	}{
+		{name: "no lease", clients: 0, collecting: false},
+		{name: "always collect", always: true, clients: 0, collecting: true},
+		{name: "default ttl", leases: []lease{{"a", 0}}, clients: 1, collecting: true, idleIn: DefaultLeaseTTL},
+		{name: "max ttl", leases: []lease{{"a", time.Hour}}, clients: 1, collecting: true, idleIn: MaxLeaseTTL},
+		{name: "renewal doesn't shorten", leases: []lease{{"a", time.Minute}, {"a", time.Second}}, clients: 1, collecting: true, idleIn: time.Minute},
+		{name: "several clients", leases: []lease{{"a", time.Minute}, {"b", 2 * time.Minute}}, clients: 2, collecting: true, idleIn: 2 * time.Minute},
+		{name: "released", leases: []lease{{"a", time.Minute}, {"b", 2 * time.Minute}}, release: "b", clients: 1, collecting: true, idleIn: time.Minute},
+		{name: "expired", leases: []lease{{"a", time.Nanosecond}}, clients: 0, collecting: false},
+		{name: "tick takes no lease", tick: true, clients: 0, collecting: false},
+		{name: "tick keeps the leases", leases: []lease{{"a", time.Minute}}, tick: true, clients: 1, collecting: true, idleIn: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.AlwaysCollect = tt.always
+			s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock}
+			now := time.Now()
+			for _, l := range tt.leases {
+				s.Lease(l.client, l.ttl)
+			}
+			if tt.tick {
+				s.Tick()
+			}
+			if tt.release != "" {
+				s.Release(tt.release)
+			}
+			time.Sleep(time.Millisecond)
+
+			if got := s.Clients(); got != tt.clients {
+				t.Errorf("Clients() = %d, want %d", got, tt.clients)
+			}
+			if got := s.Collecting(); got != tt.collecting {
+				t.Errorf("Collecting() = %v, want %v", got, tt.collecting)
+			}
+			if tt.idleIn == 0 {
+				return
+			}
+			if idle := s.idleTime(); idle.Before(now.Add(tt.idleIn)) || idle.After(now.Add(tt.idleIn+time.Second)) {
+				t.Errorf("idle at %v, want %v", idle.Sub(now), tt.idleIn)
+			}
+		})
+	}
+}
+
+func TestServeLease(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		form   url.Values
+		status int
+	}{
+		{"lease", http.MethodPost, url.Values{"client": {"a"}}, http.StatusNoContent},
+		{"ttl", http.MethodPost, url.Values{"client": {"a"}, "ttl": {"60"}}, http.StatusNoContent},
+		{"release", http.MethodPost, url.Values{"client": {"a"}, "release": {"1"}}, http.StatusNoContent},
+		{"GET", http.MethodGet, url.Values{"client": {"a"}}, http.StatusMethodNotAllowed},
+		{"no client", http.MethodPost, url.Values{}, http.StatusBadRequest},
+		{"long client", http.MethodPost, url.Values{"client": {strings.Repeat("a", 65)}}, http.StatusBadRequest},
+		{"invalid ttl", http.MethodPost, url.Values{"client": {"a"}, "ttl": {"-1"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock}
+			r := httptest.NewRequest(tt.method, "/debug/statsview/lease", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			s.ServeLease(rec, r)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
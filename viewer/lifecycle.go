@@ -0,0 +1,32 @@
+package viewer
+
+import (
+	"context"
+)
+
+// Initializer is implemented by viewers which acquire resources, e.g. files,
+// sockets or OS handles. Init is called once before the viewer is served
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// Closer is implemented by viewers which release resources when the manager stops
+type Closer interface {
+	Close() error
+}
+
+// Init forwards to the wrapped viewer
+func (vr *DerivedViewer) Init(ctx context.Context) error {
+	if i, ok := vr.Viewer.(Initializer); ok {
+		return i.Init(ctx)
+	}
+	return nil
+}
+
+// Close forwards to the wrapped viewer
+func (vr *DerivedViewer) Close() error {
+	if c, ok := vr.Viewer.(Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
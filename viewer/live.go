@@ -0,0 +1,118 @@
+package viewer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/go-echarts/go-echarts/v2/types"
+)
+
+// cfgMu guards the configuration, every accessor reads it under the read
+// lock since SetConfiguration could be called while the server runs, e.g.
+// to change the interval, the max points, the theme or the enabled viewers
+var cfgMu sync.RWMutex
+
+// viewTemplate is the view template a chart was built with
+type viewTemplate struct {
+	tpl       string
+	route     string
+	maxPoints int
+	js        string
+}
+
+// viewTemplates holds the view template of every chart, keyed by chart
+var viewTemplates sync.Map
+
+// addViewTemplate executes the view template of graph and adds it to the
+// functions of the chart, maxPoints overrides the configured MaxPoints when
+// positive
+func addViewTemplate(graph *charts.Line, tpl, route string, maxPoints int) error {
+	graph.ChartID = ChartID(route)
+	js, err := genViewTemplate(tpl, graph.ChartID, route, maxPoints)
+	if err != nil {
+		return err
+	}
+	graph.AddJSFuncs(js)
+	viewTemplates.Store(graph, viewTemplate{tpl: tpl, route: route, maxPoints: maxPoints, js: jsFunc(js)})
+	return nil
+}
+
+// ChartID returns the ID of the chart of the route, the ID of its element and
+// of its goecharts_<ID> variable. It's stable across restarts so that the
+// dashboard finds the state it saved of the chart
+func ChartID(route string) string {
+	id := strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, route)
+	if id != route {
+		// the routes differing by the replaced runes keep distinct IDs
+		h := fnv.New32a()
+		h.Write([]byte(route))
+		id = fmt.Sprintf("%s_%08x", id, h.Sum32())
+	}
+	return "statsview_" + id
+}
+
+// jsFunc returns js the way it's kept by the functions of a chart
+func jsFunc(js string) string {
+	var fns opts.JSFunctions
+	fns.AddJSFuncs(js)
+	return fns.Fns[0]
+}
+
+// CurrentView returns the chart of v following the settings changed since it
+// was built: its view template is executed with the current interval and max
+// points and it's drawn with the current theme. The chart is copied rather
+// than modified when they changed
+func CurrentView(v Viewer) *charts.Line {
+	return ThemedView(v, ChartTheme())
+}
+
+// ThemedView returns the CurrentView of v drawn with theme instead of the
+// configured one, e.g. the theme picked by a user
+func ThemedView(v Viewer, t Theme) *charts.Line {
+	graph := v.View()
+	theme := string(t)
+	var prev, js string
+	if t, ok := viewTemplates.Load(graph); ok {
+		vt := t.(viewTemplate)
+		if current, err := genViewTemplate(vt.tpl, graph.ChartID, vt.route, vt.maxPoints); err == nil {
+			if current = jsFunc(current); current != vt.js {
+				prev, js = vt.js, current
+			}
+		}
+	}
+	if js == "" && graph.Initialization.Theme == theme {
+		return graph
+	}
+
+	current := *graph
+	if js != "" {
+		current.JSFunctions.Fns = make([]string, len(graph.JSFunctions.Fns))
+		for i, fn := range graph.JSFunctions.Fns {
+			if fn == prev {
+				fn = js
+			}
+			current.JSFunctions.Fns[i] = fn
+		}
+	}
+	if graph.Initialization.Theme != theme {
+		current.Initialization.Theme = theme
+		current.JSAssets = types.OrderedSet{}
+		current.JSAssets.Init()
+		for _, asset := range graph.JSAssets.Values {
+			if !strings.Contains(asset, "themes/") {
+				current.JSAssets.Add(asset)
+			}
+		}
+		current.JSAssets.Add("themes/" + theme + ".js")
+	}
+	return &current
+}
@@ -0,0 +1,123 @@
+package viewer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCurrentView(t *testing.T) {
+	defer func(interval, maxPoints int, theme Theme) {
+		defaultCfg.Interval, defaultCfg.MaxPoints, defaultCfg.Theme = interval, maxPoints, theme
+	}(defaultCfg.Interval, defaultCfg.MaxPoints, defaultCfg.Theme)
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		copied bool
+		js     string
+		theme  Theme
+	}{
+		{name: "unchanged", js: "}, 2000);", theme: DefaultTheme},
+		{name: "interval", opts: []Option{WithInterval(500)}, copied: true, js: "}, 500);", theme: DefaultTheme},
+		{name: "max points", opts: []Option{WithMaxPoints(90)}, copied: true, js: "x.length > 90", theme: DefaultTheme},
+		{name: "theme", opts: []Option{WithTheme(ThemeWesteros)}, copied: true, js: "}, 2000);", theme: ThemeWesteros},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.Interval, defaultCfg.MaxPoints, defaultCfg.Theme = DefaultInterval, DefaultMaxPoints, DefaultTheme
+			v := NewHeapViewer()
+			graph := v.View()
+			graph.Validate()
+			fns := strings.Join(graph.JSFunctions.Fns, "")
+			SetConfiguration(tt.opts...)
+
+			current := CurrentView(v)
+			if copied := current != graph; copied != tt.copied {
+				t.Fatalf("copied = %v, want %v", copied, tt.copied)
+			}
+			if js := strings.Join(current.JSFunctions.Fns, ""); !strings.Contains(js, tt.js) {
+				t.Errorf("the view template doesn't contain %s", tt.js)
+			}
+			if current.Initialization.Theme != string(tt.theme) {
+				t.Errorf("theme = %s, want %s", current.Initialization.Theme, tt.theme)
+			}
+			themes := 0
+			for _, asset := range current.JSAssets.Values {
+				if strings.Contains(asset, "themes/") {
+					themes++
+					if !strings.HasSuffix(asset, "themes/"+string(tt.theme)+".js") {
+						t.Errorf("asset %s, want the theme %s", asset, tt.theme)
+					}
+				}
+			}
+			if themes != 1 {
+				t.Errorf("%d theme assets, want 1", themes)
+			}
+			if strings.Join(graph.JSFunctions.Fns, "") != fns || graph.Initialization.Theme != string(DefaultTheme) {
+				t.Error("the chart of the viewer was modified")
+			}
+		})
+	}
+}
+
+func TestThemedView(t *testing.T) {
+	defer func(theme Theme) { defaultCfg.Theme = theme }(defaultCfg.Theme)
+	defaultCfg.Theme = DefaultTheme
+
+	v := NewHeapViewer()
+	v.View().Validate()
+	tests := []struct {
+		name   string
+		theme  Theme
+		copied bool
+	}{
+		{"configured theme", DefaultTheme, false},
+		{"picked theme", ThemeWesteros, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := ThemedView(v, tt.theme)
+			if copied := graph != v.View(); copied != tt.copied {
+				t.Errorf("copied = %v, want %v", copied, tt.copied)
+			}
+			if graph.Initialization.Theme != string(tt.theme) {
+				t.Errorf("theme = %s, want %s", graph.Initialization.Theme, tt.theme)
+			}
+		})
+	}
+	if v.View().Initialization.Theme != string(DefaultTheme) {
+		t.Error("the chart of the viewer was drawn with the picked theme")
+	}
+}
+
+func TestChartID(t *testing.T) {
+	tests := []struct {
+		route string
+		want  string
+	}{
+		{"heap", "statsview_heap"},
+		{"gc_pause", "statsview_gc_pause"},
+		{"proc-net", "statsview_proc_net_"},
+		{"proc.net", "statsview_proc_net_"},
+		{"db/pool", "statsview_db_pool_"},
+	}
+	ids := make(map[string]string)
+	for _, tt := range tests {
+		got := ChartID(tt.route)
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("ChartID(%q) = %q, want %s...", tt.route, got, tt.want)
+		}
+		if got != ChartID(tt.route) {
+			t.Errorf("ChartID(%q) isn't stable", tt.route)
+		}
+		if other, ok := ids[got]; ok {
+			t.Errorf("routes %q and %q share the ID %s", other, tt.route, got)
+		}
+		ids[got] = tt.route
+	}
+
+	v := NewHeapViewer()
+	if got := v.View().ChartID; got != ChartID(VHeap) {
+		t.Errorf("chart ID of the heap viewer = %q, want %q", got, ChartID(VHeap))
+	}
+}
@@ -0,0 +1,26 @@
+package viewer
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardHandler drops every record, it's used when no logger is configured
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+var discardLogger = slog.New(discardHandler{})
+
+// Logger returns the configured logger, logs are discarded by default
+func Logger() *slog.Logger {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	if defaultCfg.Logger == nil {
+		return discardLogger
+	}
+	return defaultCfg.Logger
+}
@@ -0,0 +1,134 @@
+package viewer
+
+import (
+	"sync"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+)
+
+// SeriesMeta describes a series of a chart. The values are collected in the
+// base unit of their dimension and served to the chart in Unit, empty for
+// counts and ratios
+type SeriesMeta struct {
+	Name      string    `json:"name"`
+	Dimension Dimension `json:"dimension,omitempty"`
+	Unit      Unit      `json:"unit,omitempty"`
+}
+
+// Meta describes a viewer for the tools discovering the charts
+type Meta struct {
+	Name        string       `json:"name"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Series      []SeriesMeta `json:"series"`
+	Priority    Priority     `json:"priority"`
+	Collects    bool         `json:"collects"`
+}
+
+// Describer is implemented by the viewers describing what they chart, it
+// takes precedence over WithDescription
+type Describer interface {
+	Description() string
+}
+
+// descriptions of the builtin viewers
+var descriptions = map[string]string{
+	VBlock:           "Goroutine blocking events and delay from the block profile",
+	VCtxSwitch:       "Voluntary and involuntary context switches of the process per second",
+	VContainer:       "Memory and CPU usage relative to the limits of the container",
+	VDisk:            "Used and free space of the filesystems of the mount points",
+	VDNS:             "Lookup latency of the probed hostnames and the failed lookups",
+	VGCCPUFraction:   "Fraction of the CPU time used by the GC since the program started",
+	VGCNum:           "Number of completed GC cycles",
+	VGCCycles:        "Automatic and forced GC cycles",
+	VGCCPU:           "CPU cores used by the GC and the scavenger by phase",
+	VGCSize:          "Heap size targeted by the next GC and the GC metadata",
+	VGoroutine:       "Number of goroutines",
+	VHostCPU:         "Usage of every core of the machine",
+	VHostLoad:        "Load average of the machine over 1, 5 and 15 minutes",
+	VHostMemory:      "Used and available memory and used swap of the machine",
+	VGoroutineStates: "Goroutines by state from the goroutine profile",
+	VHandles:         "Open file descriptors or handles and OS threads of the process",
+	VHeap:            "Heap memory allocated, in use, obtained from the OS and idle",
+	VMutex:           "Mutex contention events and delay from the mutex profile",
+	VNetIO:           "Bytes and packets received and sent per second",
+	VOverhead:        "Collection time, allocations and requests of statsview itself",
+	VPageFaults:      "Minor and major page faults of the process per second",
+	VPause:           "Recent GC stop-the-world pauses",
+	VProcess:         "Resident memory and CPU usage of the process as seen by the OS",
+	VProcIO:          "Bytes read from and written to the storage by the process per second",
+	VScavenge:        "Idle heap released to the OS and still retained",
+	VTCP:             "TCP connections of the process by state",
+	VWallClock:       "Fractions of the goroutines on and off CPU",
+	VCStack:          "Stack and span memory in use and obtained from the OS",
+}
+
+// chartMeta holds the series and the description recorded as the charts are
+// built, keyed by chart
+var chartMeta sync.Map
+
+type recordedMeta struct {
+	series      []SeriesMeta
+	description string
+}
+
+func loadMeta(graph *charts.Line) recordedMeta {
+	if m, ok := chartMeta.Load(graph); ok {
+		return m.(recordedMeta)
+	}
+	return recordedMeta{}
+}
+
+// recordSeries records the series of graph, it's called by formatSeries
+func recordSeries(graph *charts.Line, series []SeriesMeta) {
+	m := loadMeta(graph)
+	m.series = series
+	chartMeta.Store(graph, m)
+}
+
+func recordDescription(graph *charts.Line, description string) {
+	m := loadMeta(graph)
+	m.description = description
+	chartMeta.Store(graph, m)
+}
+
+// servedUnit is the unit a series of dim is served in by a chart in unit
+func servedUnit(unit Unit, dim Dimension) Unit {
+	if unit != UnitAuto && unit != UnitNone && unit.dimension() == dim {
+		return unit
+	}
+	switch dim {
+	case DimensionBytes:
+		return UnitBytes
+	case DimensionSeconds:
+		return UnitSeconds
+	}
+	return UnitNone
+}
+
+// MetaOf describes v. The series of the viewers whose chart doesn't declare
+// their dimensions are listed without
+func MetaOf(v Viewer) Meta {
+	graph := v.View()
+	recorded := loadMeta(graph)
+	m := Meta{
+		Name:        v.Name(),
+		Title:       graph.Title.Title,
+		Description: recorded.description,
+		Series:      recorded.series,
+		Priority:    PriorityOf(v),
+	}
+	if d, ok := v.(Describer); ok {
+		m.Description = d.Description()
+	} else if m.Description == "" {
+		m.Description = descriptions[v.Name()]
+	}
+	if m.Series == nil {
+		m.Series = make([]SeriesMeta, 0, len(graph.MultiSeries))
+		for _, s := range graph.MultiSeries {
+			m.Series = append(m.Series, SeriesMeta{Name: s.Name})
+		}
+	}
+	_, m.Collects = v.(Collector)
+	return m
+}
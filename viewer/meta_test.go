@@ -0,0 +1,111 @@
+package viewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetaOf(t *testing.T) {
+	heapSeries := func(unit Unit) []SeriesMeta {
+		var series []SeriesMeta
+		for _, name := range []string{"Alloc", "Inuse", "Sys", "Idle", "NextGC"} {
+			series = append(series, SeriesMeta{Name: name, Dimension: DimensionBytes, Unit: unit})
+		}
+		return series
+	}
+
+	tests := []struct {
+		name   string
+		viewer Viewer
+		want   Meta
+	}{
+		{
+			name:   "builtin",
+			viewer: NewHeapViewer(),
+			want: Meta{
+				Name:        VHeap,
+				Title:       "Heap",
+				Description: descriptions[VHeap],
+				Series:      heapSeries(UnitBytes),
+				Priority:    PriorityNormal,
+				Collects:    true,
+			},
+		},
+		{
+			name:   "unit and description",
+			viewer: NewHeapViewer(WithUnit(UnitMiB), WithTitle("API heap"), WithDescription("Heap of the API")),
+			want: Meta{
+				Name:        VHeap,
+				Title:       "API heap",
+				Description: "Heap of the API",
+				Series:      heapSeries(UnitMiB),
+				Priority:    PriorityNormal,
+				Collects:    true,
+			},
+		},
+		{
+			name:   "count",
+			viewer: NewGoroutinesViewer(),
+			want: Meta{
+				Name:        VGoroutine,
+				Title:       "Goroutines",
+				Description: descriptions[VGoroutine],
+				Series:      []SeriesMeta{{Name: "Goroutines", Dimension: DimensionCount}},
+				Priority:    PriorityNormal,
+				Collects:    true,
+			},
+		},
+		{
+			name:   "series without dimensions",
+			viewer: newConstViewer(),
+			want: Meta{
+				Name:     "const",
+				Series:   []SeriesMeta{{Name: "Sum"}, {Name: "Count"}},
+				Priority: PriorityNormal,
+				Collects: true,
+			},
+		},
+		{
+			name:   "scatter",
+			viewer: Scatter("scatter", Ref(VGoroutine, "Goroutines"), Ref(VHeap, "Alloc").In(DimensionBytes)),
+			want: Meta{
+				Name:  "scatter",
+				Title: "scatter",
+				Series: []SeriesMeta{
+					{Name: "goroutine.Goroutines", Dimension: DimensionCount},
+					{Name: "heap.Alloc", Dimension: DimensionBytes, Unit: UnitBytes},
+				},
+				Priority: PriorityHigh,
+				Collects: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MetaOf(tt.viewer); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MetaOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServedUnit(t *testing.T) {
+	tests := []struct {
+		unit Unit
+		dim  Dimension
+		want Unit
+	}{
+		{UnitAuto, DimensionBytes, UnitBytes},
+		{UnitNone, DimensionSeconds, UnitSeconds},
+		{UnitMiB, DimensionBytes, UnitMiB},
+		{UnitMiB, DimensionSeconds, UnitSeconds},
+		{UnitMilliseconds, DimensionSeconds, UnitMilliseconds},
+		{UnitAuto, DimensionCount, UnitNone},
+		{UnitAuto, DimensionRatio, UnitNone},
+	}
+	for _, tt := range tests {
+		if got := servedUnit(tt.unit, tt.dim); got != tt.want {
+			t.Errorf("servedUnit(%q, %q) = %q, want %q", tt.unit, tt.dim, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package viewer
+
+import (
+	"net/http"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VMutex is the name of MutexViewer
+	VMutex = "mutex"
+)
+
+// MutexViewer collects the mutex contention metrics via the `mutex` profile.
+// The profile is empty unless `runtime.SetMutexProfileFraction` has been called
+type MutexViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
+}
+
+// NewMutexViewer returns the MutexViewer instance
+// Series: Events / Delay
+func NewMutexViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(append([]ViewerOption{WithSecondaryAxis("Delay")}, vopts...))
+	unit := o.unitOf(VMutex, DimensionSeconds)
+	graph := o.newBasicView(VMutex)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Mutex Contention"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Total"}),
+	)
+	graph.AddSeries("Events", []opts.LineData{}).
+		AddSeries("Delay", []opts.LineData{})
+
+	o.apply(graph)
+	formatSeries(graph, unit, DimensionCount, map[string]Dimension{"Delay": DimensionSeconds})
+	return &MutexViewer{graph: graph, opts: o, unit: unit}
+}
+
+func (vr *MutexViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *MutexViewer) Name() string {
+	return VMutex
+}
+
+func (vr *MutexViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Priority is low since reading the profile is expensive
+func (vr *MutexViewer) Priority() Priority {
+	return PriorityLow
+}
+
+// Collect returns the total number of contention events and delay in seconds
+func (vr *MutexViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+	c := readContention("mutex")
+	return []Point{
+		{Viewer: VMutex, Series: "Events", Value: float64(c.Count), Time: t},
+		{Viewer: VMutex, Series: "Delay", Value: c.Delay, Time: t},
+	}
+}
+
+func (vr *MutexViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := contentionMetrics(vr.Collect(), vr.unit, vr.opts)
+
+	WriteJSON(w, metrics)
+}
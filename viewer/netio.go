@@ -0,0 +1,79 @@
+package viewer
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// VNetIO is the name of the network I/O ProcViewer
+	VNetIO = "netio"
+)
+
+// NewNetIOViewer returns the ProcViewer of the bytes and the packets received
+// and sent per second, read from /proc/self/net/dev. The counters are the
+// ones of the interfaces of the network namespace of the process, in a
+// container they're the process' own. Without interfaces the ones other
+// than the loopback are summed, the viewer of a subset is named after it,
+// e.g. `netio-eth0`
+// Series: Received / Sent / RxPackets / TxPackets
+func NewNetIOViewer(interfaces []string, vopts ...ViewerOption) Viewer {
+	name, title := VNetIO, "Network I/O"
+	vopts = append([]ViewerOption{WithSecondaryAxis("RxPackets", "TxPackets")}, vopts...)
+	if len(interfaces) > 0 {
+		name += "-" + strings.Join(interfaces, "-")
+		title += " " + strings.Join(interfaces, ", ")
+		vopts = append([]ViewerOption{WithDescription(descriptions[VNetIO])}, vopts...)
+	}
+	packets := map[string]Dimension{"RxPackets": DimensionCount, "TxPackets": DimensionCount}
+	return newProcViewer(name, title, DimensionBytes, packets,
+		[]string{"Received", "Sent", "RxPackets", "TxPackets"}, func() ([]float64, bool) {
+			return netDevCounters(interfaces)
+		}, vopts)
+}
+
+// netDevCounters sums the received and sent bytes and packets of the
+// interfaces, all but the loopback without interfaces
+func netDevCounters(interfaces []string) ([]float64, bool) {
+	f, err := os.Open("/proc/self/net/dev")
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	wanted := func(iface string) bool {
+		if len(interfaces) == 0 {
+			return iface != "lo"
+		}
+		for _, name := range interfaces {
+			if name == iface {
+				return true
+			}
+		}
+		return false
+	}
+
+	sums := make([]float64, 4)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		iface, counters, ok := strings.Cut(sc.Text(), ":")
+		if !ok || !wanted(strings.TrimSpace(iface)) {
+			continue
+		}
+		// receive bytes, packets, ... then transmit bytes, packets, ...
+		fields := strings.Fields(counters)
+		if len(fields) < 10 {
+			continue
+		}
+		for i, field := range []int{0, 8, 1, 9} {
+			v, err := strconv.ParseFloat(fields[field], 64)
+			if err != nil {
+				return nil, false
+			}
+			sums[i] += v
+		}
+	}
+	return sums, true
+}
@@ -0,0 +1,80 @@
+package viewer
+
+import (
+	"net"
+	"runtime"
+	"testing"
+)
+
+func TestNewNetIOViewer(t *testing.T) {
+	tests := []struct {
+		name       string
+		interfaces []string
+		want       string
+		title      string
+	}{
+		{"all interfaces", nil, VNetIO, "Network I/O"},
+		{"one interface", []string{"eth0"}, "netio-eth0", "Network I/O eth0"},
+		{"interfaces", []string{"eth0", "eth1"}, "netio-eth0-eth1", "Network I/O eth0, eth1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vr := NewNetIOViewer(tt.interfaces)
+			if vr.Name() != tt.want {
+				t.Errorf("Name() = %s, want %s", vr.Name(), tt.want)
+			}
+			if title := vr.View().Title.Title; title != tt.title {
+				t.Errorf("title = %s, want %s", title, tt.title)
+			}
+			if meta := MetaOf(vr); meta.Description != descriptions[VNetIO] {
+				t.Errorf("description = %q, want the one of %s", meta.Description, VNetIO)
+			}
+		})
+	}
+}
+
+func TestNetDevCounters(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("/proc/self/net/dev is linux only")
+	}
+
+	// traffic on the loopback, which is left out by default
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		if c, err := l.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+	before, ok := netDevCounters([]string{"lo"})
+	if !ok {
+		t.Fatal("netDevCounters() failed")
+	}
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Write(make([]byte, 1024))
+	c.Close()
+
+	tests := []struct {
+		name       string
+		interfaces []string
+		check      func(got []float64) bool
+	}{
+		{"loopback", []string{"lo"}, func(got []float64) bool { return got[0] >= before[0]+1024 && got[2] > before[2] }},
+		{"unknown interface", []string{"nonexistent0"}, func(got []float64) bool { return got[0] == 0 && got[1] == 0 && got[2] == 0 && got[3] == 0 }},
+		{"all but the loopback", nil, func(got []float64) bool { return len(got) == 4 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := netDevCounters(tt.interfaces)
+			if !ok || len(got) != 4 || !tt.check(got) {
+				t.Errorf("netDevCounters(%v) = %v, %v", tt.interfaces, got, ok)
+			}
+		})
+	}
+}
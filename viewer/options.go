@@ -0,0 +1,234 @@
+package viewer
+
+import (
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// ViewerOption customizes the chart of a built-in viewer, e.g.
+// `NewHeapViewer(WithTitle("API heap"), WithSeries("Alloc", "NextGC"))`
+type ViewerOption func(*viewerOptions)
+
+// viewerOptions is the customization of a viewer, zero values keep the
+// defaults of the viewer
+type viewerOptions struct {
+	title     string
+	desc      string
+	unit      Unit
+	series    []string
+	precision int
+	maxPoints int
+	logScale  bool
+	secondary []string
+	chartType ChartType
+}
+
+func newViewerOptions(vopts []ViewerOption) viewerOptions {
+	o := viewerOptions{precision: -1}
+	for _, opt := range vopts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTitle replaces the title of the chart
+func WithTitle(title string) ViewerOption {
+	return func(o *viewerOptions) {
+		o.title = title
+	}
+}
+
+// WithDescription sets the description of the viewer served by the
+// metadata endpoint
+func WithDescription(desc string) ViewerOption {
+	return func(o *viewerOptions) {
+		o.desc = desc
+	}
+}
+
+// WithUnit sets the unit of the chart, it takes precedence over
+// WithViewerUnit. A unit of another dimension than the one of the viewer,
+// e.g. seconds for the heap sizes, is ignored
+func WithUnit(unit Unit) ViewerOption {
+	return func(o *viewerOptions) {
+		o.unit = unit
+	}
+}
+
+// WithSeries keeps only the named series on the chart, in the order of the
+// viewer. The viewer still collects all of them for the exporters
+func WithSeries(names ...string) ViewerOption {
+	return func(o *viewerOptions) {
+		o.series = names
+	}
+}
+
+// WithPrecision rounds the values served to the chart to digits decimals
+func WithPrecision(digits int) ViewerOption {
+	return func(o *viewerOptions) {
+		o.precision = digits
+	}
+}
+
+// WithViewerMaxPoints overrides WithMaxPoints for the chart
+func WithViewerMaxPoints(n int) ViewerOption {
+	return func(o *viewerOptions) {
+		if n > 0 {
+			o.maxPoints = n
+		}
+	}
+}
+
+// WithLogScale uses a logarithmic Y axis, for series of different
+// magnitudes. Zero values can't be drawn on it
+func WithLogScale() ViewerOption {
+	return func(o *viewerOptions) {
+		o.logScale = true
+	}
+}
+
+// WithSecondaryAxis draws the named series against a second Y axis on the
+// right, e.g. a count next to bytes. Without series it clears the secondary
+// axis a viewer uses by default
+func WithSecondaryAxis(series ...string) ViewerOption {
+	return func(o *viewerOptions) {
+		o.secondary = series
+	}
+}
+
+// ChartType is the way the series of a chart are drawn
+type ChartType string
+
+const (
+	ChartLine ChartType = "line"
+	ChartArea ChartType = "area"
+	ChartStep ChartType = "step"
+	ChartBar  ChartType = "bar"
+)
+
+// WithChartType draws the series of the chart as t, lines by default. With
+// CollectorRef one collector feeds charts of several types
+func WithChartType(t ChartType) ViewerOption {
+	return func(o *viewerOptions) {
+		o.chartType = t
+	}
+}
+
+// unitOf returns the unit set by WithUnit, else the one configured by
+// WithViewerUnit or UnitAuto, units of another dimension than dim are ignored
+func (o viewerOptions) unitOf(name string, dim Dimension) Unit {
+	if o.unit != "" && o.unit.measures(dim) {
+		return o.unit
+	}
+	return unitOf(name, dim, UnitAuto)
+}
+
+// precisionOr returns the precision set by WithPrecision or def
+func (o viewerOptions) precisionOr(def int) int {
+	if o.precision >= 0 {
+		return o.precision
+	}
+	return def
+}
+
+// newBasicView returns the basic view of the route honoring WithViewerMaxPoints
+func (o viewerOptions) newBasicView(route string) *charts.Line {
+	return newBasicView(route, o.maxPoints)
+}
+
+// apply sets the title, drops the series not kept by WithSeries and sets
+// up the Y axes, it's called once the chart is complete
+func (o viewerOptions) apply(graph *charts.Line) {
+	o.applyTitle(graph)
+	if len(o.series) > 0 {
+		kept := graph.MultiSeries[:0]
+		for _, s := range graph.MultiSeries {
+			if o.selected(s.Name) {
+				kept = append(kept, s)
+			}
+		}
+		graph.MultiSeries = kept
+	}
+	o.applyAxes(graph)
+	o.applyChartType(graph)
+}
+
+func (o viewerOptions) applyTitle(graph *charts.Line) {
+	if o.title != "" {
+		graph.Title.Title = o.title
+	}
+	if o.desc != "" {
+		recordDescription(graph, o.desc)
+	}
+}
+
+// applyAxes moves the series set by WithSecondaryAxis to a second Y axis,
+// which has no split lines to keep the grid readable, and sets the scale
+func (o viewerOptions) applyAxes(graph *charts.Line) {
+	secondary := ""
+	for i := range graph.MultiSeries {
+		for _, name := range o.secondary {
+			if graph.MultiSeries[i].Name == name {
+				graph.MultiSeries[i].YAxisIndex = 1
+				if secondary == "" {
+					secondary = name
+				}
+			}
+		}
+	}
+	if secondary != "" && len(graph.YAxisList) < 2 {
+		graph.ExtendYAxis(opts.YAxis{Name: secondary, SplitLine: &opts.SplitLine{Show: false}})
+	}
+	if o.logScale {
+		for i := range graph.YAxisList {
+			graph.YAxisList[i].Type = "log"
+		}
+	}
+}
+
+// applyChartType sets the type of the series set by WithChartType
+func (o viewerOptions) applyChartType(graph *charts.Line) {
+	for i := range graph.MultiSeries {
+		s := &graph.MultiSeries[i]
+		switch o.chartType {
+		case ChartArea:
+			s.AreaStyle = &opts.AreaStyle{Opacity: 0.3}
+		case ChartStep:
+			s.Step = true
+		case ChartBar:
+			s.Type = "bar"
+		}
+	}
+}
+
+func (o viewerOptions) selected(series string) bool {
+	if len(o.series) == 0 {
+		return true
+	}
+	for _, name := range o.series {
+		if name == series {
+			return true
+		}
+	}
+	return false
+}
+
+// selectPoints returns the points of the series kept by WithSeries
+func (o viewerOptions) selectPoints(points []Point) []Point {
+	if len(o.series) == 0 {
+		return points
+	}
+	selected := make([]Point, 0, len(o.series))
+	for _, p := range points {
+		if o.selected(p.Series) {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// metricsOf converts the selected points into the Metrics served to the
+// chart, rounded to the precision set by WithPrecision or def
+func (o viewerOptions) metricsOf(points []Point, unit Unit, def int) Metrics {
+	return metricsOf(o.selectPoints(points), unit, o.precisionOr(def))
+}
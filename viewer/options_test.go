@@ -0,0 +1,183 @@
+package viewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestViewerOptions(t *testing.T) {
+	s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock}
+	s.memstats = runtime.MemStats{HeapAlloc: 3 << 19, HeapInuse: 2 << 20, HeapSys: 4 << 20, HeapIdle: 1 << 20, NextGC: 1<<20 + 1<<18}
+
+	tests := []struct {
+		name      string
+		vopts     []ViewerOption
+		title     string
+		series    []string
+		values    []float64
+		maxPoints string
+	}{
+		{
+			name:   "defaults",
+			title:  "Heap",
+			series: []string{"Alloc", "Inuse", "Sys", "Idle", "NextGC"},
+			values: []float64{3 << 19, 2 << 20, 4 << 20, 1 << 20, 1<<20 + 1<<18},
+		},
+		{
+			name:   "title",
+			vopts:  []ViewerOption{WithTitle("API heap")},
+			title:  "API heap",
+			series: []string{"Alloc", "Inuse", "Sys", "Idle", "NextGC"},
+			values: []float64{3 << 19, 2 << 20, 4 << 20, 1 << 20, 1<<20 + 1<<18},
+		},
+		{
+			name:   "series in the order of the viewer",
+			vopts:  []ViewerOption{WithSeries("NextGC", "Alloc")},
+			title:  "Heap",
+			series: []string{"Alloc", "NextGC"},
+			values: []float64{3 << 19, 1<<20 + 1<<18},
+		},
+		{
+			name:   "unit",
+			vopts:  []ViewerOption{WithUnit(UnitKiB), WithSeries("Alloc")},
+			title:  "Heap",
+			series: []string{"Alloc"},
+			values: []float64{1536},
+		},
+		{
+			name:   "unit of another dimension",
+			vopts:  []ViewerOption{WithUnit(UnitSeconds), WithSeries("Alloc")},
+			title:  "Heap",
+			series: []string{"Alloc"},
+			values: []float64{3 << 19},
+		},
+		{
+			name:   "precision",
+			vopts:  []ViewerOption{WithUnit(UnitMiB), WithPrecision(0), WithSeries("Alloc", "NextGC")},
+			title:  "Heap",
+			series: []string{"Alloc", "NextGC"},
+			values: []float64{2, 1},
+		},
+		{
+			name:      "max points",
+			vopts:     []ViewerOption{WithViewerMaxPoints(120)},
+			title:     "Heap",
+			series:    []string{"Alloc", "Inuse", "Sys", "Idle", "NextGC"},
+			values:    []float64{3 << 19, 2 << 20, 4 << 20, 1 << 20, 1<<20 + 1<<18},
+			maxPoints: "x.length > 120",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewHeapViewer(tt.vopts...)
+			v.SetStatsMgr(s)
+
+			graph := v.View()
+			if graph.Title.Title != tt.title {
+				t.Errorf("title = %q, want %q", graph.Title.Title, tt.title)
+			}
+			var series []string
+			for _, s := range graph.MultiSeries {
+				series = append(series, s.Name)
+			}
+			if !reflect.DeepEqual(series, tt.series) {
+				t.Errorf("series = %v, want %v", series, tt.series)
+			}
+			if tt.maxPoints != "" && !strings.Contains(strings.Join(graph.JSFunctions.Fns, ""), tt.maxPoints) {
+				t.Errorf("view template doesn't contain %q", tt.maxPoints)
+			}
+
+			rec := httptest.NewRecorder()
+			v.Serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			var m Metrics
+			if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Values, tt.values) {
+				t.Errorf("served %v, want %v", m.Values, tt.values)
+			}
+			// the exporters still get every series in bytes
+			if points := v.(Collector).Collect(); len(points) != 5 || points[0].Value != 3<<19 {
+				t.Errorf("collected %v, want the 5 series in bytes", points)
+			}
+		})
+	}
+}
+
+func TestViewerAxes(t *testing.T) {
+	tests := []struct {
+		name   string
+		viewer Viewer
+		// axes is the label dimension of every Y axis
+		axes []string
+		// secondary is the series on the second Y axis
+		secondary []string
+		scale     string
+	}{
+		{"single axis", NewHeapViewer(), []string{"'bytes'"}, nil, ""},
+		{"secondary by default", NewBlockViewer(), []string{"'count'", "'seconds'"}, []string{"Delay"}, ""},
+		{"secondary cleared", NewBlockViewer(WithSecondaryAxis()), []string{"'count'"}, nil, ""},
+		{"secondary series", NewHeapViewer(WithSecondaryAxis("NextGC", "Idle")), []string{"'bytes'", "'bytes'"}, []string{"Idle", "NextGC"}, ""},
+		{"secondary series not kept", NewHeapViewer(WithSeries("Alloc"), WithSecondaryAxis("NextGC")), []string{"'bytes'"}, nil, ""},
+		{"fixed unit on both axes", NewMutexViewer(WithUnit(UnitMilliseconds)), []string{"'count'", "'=ms'"}, []string{"Delay"}, ""},
+		{"log scale", NewBlockViewer(WithLogScale()), []string{"'count'", "'seconds'"}, []string{"Delay"}, "log"},
+		{"log scale of the pauses", NewPauseViewer(WithLogScale()), []string{"'seconds'"}, nil, "log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := tt.viewer.View()
+			if len(graph.YAxisList) != len(tt.axes) {
+				t.Fatalf("got %d Y axes, want %d", len(graph.YAxisList), len(tt.axes))
+			}
+			for i, y := range graph.YAxisList {
+				if got := string(y.AxisLabel.Formatter); !strings.Contains(got, "statsview_format(v, "+tt.axes[i]+")") {
+					t.Errorf("Y axis %d formatter = %s, want the %s dimension", i, got, tt.axes[i])
+				}
+				if y.Type != tt.scale {
+					t.Errorf("Y axis %d type = %q, want %q", i, y.Type, tt.scale)
+				}
+			}
+			var secondary []string
+			for _, s := range graph.MultiSeries {
+				if s.YAxisIndex == 1 {
+					secondary = append(secondary, s.Name)
+				}
+			}
+			if !reflect.DeepEqual(secondary, tt.secondary) {
+				t.Errorf("secondary axis series = %v, want %v", secondary, tt.secondary)
+			}
+		})
+	}
+}
+
+func TestWithChartType(t *testing.T) {
+	tests := []struct {
+		chartType ChartType
+		typ       string
+		area      bool
+		step      bool
+	}{
+		{"", "line", false, false},
+		{ChartLine, "line", false, false},
+		{ChartArea, "line", true, false},
+		{ChartStep, "line", false, true},
+		{ChartBar, "bar", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.chartType), func(t *testing.T) {
+			graph := NewHeapViewer(WithChartType(tt.chartType)).View()
+			for _, s := range graph.MultiSeries {
+				if s.Type != tt.typ || (s.AreaStyle != nil) != tt.area || s.Step != tt.step {
+					t.Errorf("series %s: type %s, area %v, step %v, want %s, %v, %v",
+						s.Name, s.Type, s.AreaStyle != nil, s.Step, tt.typ, tt.area, tt.step)
+				}
+			}
+		})
+	}
+}
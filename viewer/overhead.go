@@ -0,0 +1,147 @@
+package viewer
+
+import (
+	"net/http"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VOverhead is the name of OverheadViewer
+	VOverhead = "overhead"
+)
+
+// Overhead is the cost of statsview itself since the manager was created
+type Overhead struct {
+	// Collections is the number of collections, polls and served viewers
+	Collections int64 `json:"collections"`
+	// CollectTime is the time spent collecting, most of it is CPU time
+	// since `runtime.ReadMemStats()` stops the world
+	CollectTime time.Duration `json:"collectTime"`
+	// AllocBytes is the heap allocated while collecting. The runtime only
+	// counts the allocations of the whole process, what other goroutines
+	// allocated meanwhile is included, it's an upper bound
+	AllocBytes uint64 `json:"allocBytes"`
+	// ServedBytes is the size of the responses of every statsview route
+	ServedBytes uint64 `json:"servedBytes"`
+}
+
+// overheadMeter accumulates the Overhead, it's updated atomically
+type overheadMeter struct {
+	collections int64
+	collectNs   int64
+	allocBytes  uint64
+	servedBytes uint64
+}
+
+func heapAllocs() uint64 {
+	s := []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}}
+	metrics.Read(s)
+	return s[0].Value.Uint64()
+}
+
+// Measure runs fn and accounts its duration and allocations as collection
+// overhead
+func (s *StatsMgr) Measure(fn func()) {
+	allocs := heapAllocs()
+	start := time.Now()
+	fn()
+	atomic.AddInt64(&s.overhead.collectNs, int64(time.Since(start)))
+	atomic.AddUint64(&s.overhead.allocBytes, heapAllocs()-allocs)
+	atomic.AddInt64(&s.overhead.collections, 1)
+}
+
+// AddServed accounts n bytes written to a client
+func (s *StatsMgr) AddServed(n int) {
+	atomic.AddUint64(&s.overhead.servedBytes, uint64(n))
+}
+
+// Overhead returns the accumulated cost of statsview
+func (s *StatsMgr) Overhead() Overhead {
+	return Overhead{
+		Collections: atomic.LoadInt64(&s.overhead.collections),
+		CollectTime: time.Duration(atomic.LoadInt64(&s.overhead.collectNs)),
+		AllocBytes:  atomic.LoadUint64(&s.overhead.allocBytes),
+		ServedBytes: atomic.LoadUint64(&s.overhead.servedBytes),
+	}
+}
+
+// OverheadViewer charts the cost of statsview itself from the overhead
+// accounted by StatsMgr, to verify it's cheap enough to leave on
+type OverheadViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+
+	mu     sync.Mutex
+	at     time.Time
+	cur    Overhead
+	prev   Overhead
+	prevAt time.Time
+}
+
+// NewOverheadViewer returns the OverheadViewer instance
+// Series: CollectTime / Allocs / Served, per second
+func NewOverheadViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(append([]ViewerOption{WithSecondaryAxis("CollectTime")}, vopts...))
+	graph := o.newBasicView(VOverhead)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Statsview overhead", Subtitle: "Per second"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Value"}),
+	)
+	graph.AddSeries("CollectTime", []opts.LineData{}).
+		AddSeries("Allocs", []opts.LineData{}).
+		AddSeries("Served", []opts.LineData{})
+
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionBytes, map[string]Dimension{"CollectTime": DimensionSeconds})
+	return &OverheadViewer{graph: graph, opts: o}
+}
+
+func (vr *OverheadViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *OverheadViewer) Name() string {
+	return VOverhead
+}
+
+func (vr *OverheadViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Collect returns the collect time in seconds and the allocated and served
+// bytes, per second between the last two collections
+func (vr *OverheadViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+
+	vr.mu.Lock()
+	if !t.Equal(vr.at) {
+		vr.prev, vr.prevAt = vr.cur, vr.at
+		vr.cur, vr.at = vr.smgr.Overhead(), t
+	}
+	var collect, allocs, served float64
+	if elapsed := vr.at.Sub(vr.prevAt).Seconds(); !vr.prevAt.IsZero() && elapsed > 0 {
+		collect = (vr.cur.CollectTime - vr.prev.CollectTime).Seconds() / elapsed
+		allocs = float64(vr.cur.AllocBytes-vr.prev.AllocBytes) / elapsed
+		served = float64(vr.cur.ServedBytes-vr.prev.ServedBytes) / elapsed
+	}
+	vr.mu.Unlock()
+
+	return []Point{
+		{Viewer: VOverhead, Series: "CollectTime", Value: collect, Time: t},
+		{Viewer: VOverhead, Series: "Allocs", Value: allocs, Time: t},
+		{Viewer: VOverhead, Series: "Served", Value: served, Time: t},
+	}
+}
+
+func (vr *OverheadViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 9)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,63 @@
+package viewer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeasure(t *testing.T) {
+	s := &StatsMgr{}
+	var sink [][]byte
+	s.Measure(func() {
+		time.Sleep(time.Millisecond)
+		sink = append(sink, make([]byte, 1<<20))
+	})
+	s.AddServed(100)
+	s.AddServed(24)
+
+	o := s.Overhead()
+	if o.Collections != 1 {
+		t.Errorf("collections = %d, want 1", o.Collections)
+	}
+	if o.CollectTime < time.Millisecond {
+		t.Errorf("collect time = %v, want at least 1ms", o.CollectTime)
+	}
+	if o.AllocBytes < 1<<20 {
+		t.Errorf("allocated %d bytes, want at least %d", o.AllocBytes, 1<<20)
+	}
+	if o.ServedBytes != 124 {
+		t.Errorf("served %d bytes, want 124", o.ServedBytes)
+	}
+	_ = sink
+}
+
+func TestOverheadCollect(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	tests := []struct {
+		name     string
+		at       time.Time
+		overhead overheadMeter
+		// want is CollectTime, Allocs and Served per second
+		want [3]float64
+	}{
+		{"first collection", t0, overheadMeter{collectNs: int64(time.Second), allocBytes: 1000, servedBytes: 10}, [3]float64{}},
+		{"rates", t0.Add(2 * time.Second), overheadMeter{collectNs: int64(2 * time.Second), allocBytes: 3000, servedBytes: 50}, [3]float64{0.5, 1000, 20}},
+		{"same collection", t0.Add(2 * time.Second), overheadMeter{collectNs: int64(5 * time.Second), allocBytes: 9000, servedBytes: 90}, [3]float64{0.5, 1000, 20}},
+		{"idle", t0.Add(4 * time.Second), overheadMeter{collectNs: int64(5 * time.Second), allocBytes: 9000, servedBytes: 90}, [3]float64{1.5, 3000, 20}},
+	}
+	s := &StatsMgr{}
+	vr := NewOverheadViewer().(*OverheadViewer)
+	vr.SetStatsMgr(s)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.time = tt.at
+			s.overhead = tt.overhead
+			points := vr.Collect()
+			for i, p := range points {
+				if p.Value != tt.want[i] || !p.Time.Equal(tt.at) {
+					t.Errorf("%s = %v at %v, want %v at %v", p.Series, p.Value, p.Time, tt.want[i], tt.at)
+				}
+			}
+		})
+	}
+}
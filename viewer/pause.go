@@ -0,0 +1,138 @@
+package viewer
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VPause is the name of PauseViewer
+	VPause = "pause"
+)
+
+// pauseTemplate replaces the whole window on every update instead of
+// appending the latest value
+const pauseTemplate = pollerTemplate + `function {{ .ViewID }}_sync(result) {
+    if (!result) {
+        return;
+    }
+    let opt = goecharts_{{ .ViewID }}.getOption();
+    opt.xAxis[0].data = result.times;
+    opt.series[0].data = result.pauses.map(function (v) { return { value: v }; });
+    goecharts_{{ .ViewID }}.setOption(opt);
+}`
+
+// PauseMetrics is the window of the recent GC pauses, oldest first
+type PauseMetrics struct {
+	Pauses []float64 `json:"pauses"`
+	Times  []string  `json:"times"`
+	Time   string    `json:"time"`
+}
+
+// PauseViewer renders the whole `MemStats.PauseNs` window of the last 256 GC
+// pauses, a bad pause between two collections isn't missed by the sampling
+type PauseViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
+}
+
+// NewPauseViewer returns the PauseViewer instance, it keeps its own template
+// since the window is replaced on every update. WithSeries and
+// WithViewerMaxPoints don't apply to the window
+// Series: Pause (collected: Last / Max)
+func NewPauseViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	unit := o.unitOf(VPause, DimensionSeconds)
+	graph := charts.NewLine()
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "GC Pauses"}),
+		charts.WithLegendOpts(opts.Legend{Show: true}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true, Trigger: "axis"}),
+		charts.WithXAxisOpts(opts.XAxis{Name: "End"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Pause"}),
+		toolboxOpts(),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "600px",
+			Height: "400px",
+			Theme:  string(ChartTheme()),
+		}),
+	)
+	graph.SetXAxis([]string{}).AddSeries("Pause", []opts.LineData{})
+	graph.MultiSeries[0].Type = "bar"
+	o.applyTitle(graph)
+	o.applyAxes(graph)
+	formatSeries(graph, unit, DimensionSeconds, nil)
+
+	if err := addViewTemplate(graph, pauseTemplate, VPause, 0); err != nil {
+		Logger().Error("statsview: failed to generate view template", "route", VPause, "err", err)
+	}
+	return &PauseViewer{graph: graph, opts: o, unit: unit}
+}
+
+func (vr *PauseViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *PauseViewer) Name() string {
+	return VPause
+}
+
+func (vr *PauseViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// pauses returns the recorded pauses in seconds and their end, oldest first
+func (vr *PauseViewer) pauses() ([]float64, []time.Time) {
+	ms := vr.smgr.MemStats()
+	n := ms.NumGC
+	if n > uint32(len(ms.PauseNs)) {
+		n = uint32(len(ms.PauseNs))
+	}
+
+	pauses := make([]float64, 0, n)
+	ends := make([]time.Time, 0, n)
+	for i := ms.NumGC - n; i < ms.NumGC; i++ {
+		j := i % uint32(len(ms.PauseNs))
+		pauses = append(pauses, float64(ms.PauseNs[j])/1e9)
+		ends = append(ends, time.Unix(0, int64(ms.PauseEnd[j])))
+	}
+	return pauses, ends
+}
+
+// Collect returns the last and the longest pause of the window in seconds
+func (vr *PauseViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+	pauses, _ := vr.pauses()
+
+	var last, max float64
+	for _, p := range pauses {
+		if p > max {
+			max = p
+		}
+		last = p
+	}
+	return []Point{
+		{Viewer: VPause, Series: "Last", Value: last, Time: t},
+		{Viewer: VPause, Series: "Max", Value: max, Time: t},
+	}
+}
+
+func (vr *PauseViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	pauses, ends := vr.pauses()
+	metrics := PauseMetrics{
+		Pauses: make([]float64, 0, len(pauses)),
+		Times:  make([]string, 0, len(ends)),
+		Time:   FormatTime(vr.smgr.CollectTime()),
+	}
+	for i, p := range pauses {
+		metrics.Pauses = append(metrics.Pauses, fixedPrecision(vr.unit.Convert(p), vr.opts.precisionOr(6)))
+		metrics.Times = append(metrics.Times, FormatTime(ends[i]))
+	}
+
+	WriteJSON(w, metrics)
+}
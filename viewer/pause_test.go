@@ -0,0 +1,70 @@
+package viewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPauseViewer(t *testing.T) {
+	// the pause i lasts i ms
+	memstats := func(numGC uint32) runtime.MemStats {
+		ms := runtime.MemStats{NumGC: numGC}
+		for i := uint32(0); i < numGC; i++ {
+			j := i % uint32(len(ms.PauseNs))
+			ms.PauseNs[j] = uint64(i) * 1e6
+			ms.PauseEnd[j] = uint64(time.Unix(int64(i), 0).UnixNano())
+		}
+		return ms
+	}
+
+	tests := []struct {
+		name     string
+		numGC    uint32
+		count    int
+		first    float64
+		last     float64
+		max      float64
+		firstEnd int64
+	}{
+		{name: "no GC", numGC: 0},
+		{name: "few", numGC: 3, count: 3, first: 0, last: 2, max: 2, firstEnd: 0},
+		{name: "wrapped", numGC: 300, count: 256, first: 44, last: 299, max: 299, firstEnd: 44},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock}
+			s.memstats = memstats(tt.numGC)
+			v := NewPauseViewer(WithUnit(UnitMilliseconds)).(*PauseViewer)
+			v.SetStatsMgr(s)
+
+			points := v.Collect()
+			if got := []float64{points[0].Value, points[1].Value}; !reflect.DeepEqual(got, []float64{tt.last / 1e3, tt.max / 1e3}) {
+				t.Errorf("Collect() = %v, want last %vms and max %vms", got, tt.last, tt.max)
+			}
+
+			rec := httptest.NewRecorder()
+			v.Serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			var m PauseMetrics
+			if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if len(m.Pauses) != tt.count || len(m.Times) != tt.count {
+				t.Fatalf("served %d pauses and %d times, want %d", len(m.Pauses), len(m.Times), tt.count)
+			}
+			if tt.count == 0 {
+				return
+			}
+			if m.Pauses[0] != tt.first || m.Pauses[tt.count-1] != tt.last {
+				t.Errorf("served pauses from %vms to %vms, want %v to %v", m.Pauses[0], m.Pauses[tt.count-1], tt.first, tt.last)
+			}
+			if want := FormatTime(time.Unix(tt.firstEnd, 0)); m.Times[0] != want {
+				t.Errorf("the first pause ended at %s, want %s", m.Times[0], want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package viewer
+
+import (
+	"runtime"
+	"runtime/metrics"
+	"time"
+)
+
+// Priority ranks viewers when the collection is degraded under CPU or GC pressure
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// DefaultDegradeFactor is the multiplier of the collecting interval while
+// the collection is degraded
+const DefaultDegradeFactor = 4
+
+// Prioritized is implemented by viewers which don't have the normal priority.
+// Low priority viewers, usually the expensive ones, are skipped while the
+// collection is degraded
+type Prioritized interface {
+	Priority() Priority
+}
+
+// PriorityOf returns the priority of the viewer
+func PriorityOf(v Viewer) Priority {
+	if p, ok := v.(Prioritized); ok {
+		return p.Priority()
+	}
+	return PriorityNormal
+}
+
+// cpuMeter measures the CPU usage of the process between two calls as a
+// fraction of the GOMAXPROCS capacity
+type cpuMeter struct {
+	wall time.Time
+	cpu  time.Duration
+}
+
+func (m *cpuMeter) usage() (float64, bool) {
+	cpu, ok := processCPUTime()
+	if !ok {
+		return 0, false
+	}
+	now := time.Now()
+	defer func() { m.wall, m.cpu = now, cpu }()
+
+	if m.wall.IsZero() {
+		return 0, false
+	}
+	elapsed := now.Sub(m.wall) * time.Duration(runtime.GOMAXPROCS(0))
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return float64(cpu-m.cpu) / float64(elapsed), true
+}
+
+// gcMeter measures the fraction of the CPU capacity spent in the GC. The
+// runtime estimates it at every GC cycle, the last estimate holds until the
+// next one, or drops to zero when no cycle ran for gcIdleAfter
+type gcMeter struct {
+	samples []metrics.Sample
+	gc      float64
+	total   float64
+	last    float64
+	updated time.Time
+}
+
+const gcIdleAfter = time.Second
+
+func (m *gcMeter) usage() (float64, bool) {
+	if m.samples == nil {
+		m.samples = []metrics.Sample{
+			{Name: "/cpu/classes/gc/total:cpu-seconds"},
+			{Name: "/cpu/classes/total:cpu-seconds"},
+		}
+	}
+	metrics.Read(m.samples)
+	if m.samples[0].Value.Kind() != metrics.KindFloat64 || m.samples[1].Value.Kind() != metrics.KindFloat64 {
+		return 0, false
+	}
+	gc, total := m.samples[0].Value.Float64(), m.samples[1].Value.Float64()
+
+	now := time.Now()
+	switch {
+	case m.updated.IsZero():
+		m.gc, m.total, m.updated = gc, total, now
+		return 0, false
+	case total > m.total:
+		m.last = (gc - m.gc) / (total - m.total)
+		m.gc, m.total, m.updated = gc, total, now
+	case now.Sub(m.updated) >= gcIdleAfter:
+		m.last = 0
+	}
+	return m.last, true
+}
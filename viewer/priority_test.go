@@ -0,0 +1,126 @@
+package viewer
+
+import (
+	"math"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPriorityOf(t *testing.T) {
+	tests := []struct {
+		viewer Viewer
+		want   Priority
+	}{
+		{NewGoroutinesViewer(), PriorityNormal},
+		{NewBlockViewer(), PriorityLow},
+		{NewMutexViewer(), PriorityLow},
+	}
+	for _, tt := range tests {
+		if got := PriorityOf(tt.viewer); got != tt.want {
+			t.Errorf("PriorityOf(%s) = %v, want %v", tt.viewer.Name(), got, tt.want)
+		}
+	}
+}
+
+// meterAt returns a meter whose next usage is about usage
+func meterAt(t *testing.T, usage float64) *cpuMeter {
+	cpu, ok := processCPUTime()
+	if !ok {
+		t.Skip("the CPU time of the process isn't available")
+	}
+	elapsed := time.Second * time.Duration(runtime.GOMAXPROCS(0))
+	return &cpuMeter{wall: time.Now().Add(-time.Second), cpu: cpu - time.Duration(usage*float64(elapsed))}
+}
+
+// gcMeterAt returns a meter whose next usage is about usage, the runtime
+// updates the GC CPU estimates only at every GC cycle
+func gcMeterAt(t *testing.T, usage float64) *gcMeter {
+	m := &gcMeter{}
+	if _, ok := m.usage(); !ok && m.updated.IsZero() {
+		t.Skip("the GC CPU metrics aren't available")
+	}
+	const elapsed = 1000.0
+	m.gc -= usage * elapsed
+	m.total -= elapsed
+	return m
+}
+
+func TestCheckPressure(t *testing.T) {
+	defer func(cpu, gc float64) {
+		defaultCfg.CPUThreshold, defaultCfg.GCThreshold = cpu, gc
+	}(defaultCfg.CPUThreshold, defaultCfg.GCThreshold)
+	defaultCfg.CPUThreshold, defaultCfg.GCThreshold = 0.5, 0.25
+
+	s := &StatsMgr{}
+	var pressures []string
+	s.OnPressure(func(degraded bool, reason string) {
+		if degraded {
+			pressures = append(pressures, reason)
+		} else {
+			pressures = append(pressures, "restored")
+		}
+	})
+	tests := []struct {
+		name     string
+		usage    float64
+		gc       float64
+		changed  bool
+		degraded bool
+		pressure string
+	}{
+		{"below the threshold", 0.3, 0.1, false, false, ""},
+		{"above the threshold", 0.9, 0.1, true, true, "CPU 90%"},
+		{"still above", 0.7, 0.1, false, true, ""},
+		{"below but close to the threshold", 0.45, 0.1, false, true, ""},
+		{"clearly below", 0.1, 0.1, true, false, "restored"},
+		{"gc pressure", 0.1, 0.5, true, true, "GC 50%"},
+		{"gc close to the threshold", 0.1, 0.22, false, true, ""},
+		{"gc clearly below", 0.1, 0.05, true, false, "restored"},
+		{"both", 0.6, 0.3, true, true, "CPU 60%, GC 30%"},
+		{"one still close", 0.1, 0.21, false, true, ""},
+		{"both clearly below", 0.1, 0.1, true, false, "restored"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pressures = nil
+			if changed := s.checkPressure(meterAt(t, tt.usage), gcMeterAt(t, tt.gc)); changed != tt.changed {
+				t.Errorf("checkPressure() = %v, want %v", changed, tt.changed)
+			}
+			if tt.pressure == "" && len(pressures) != 0 || tt.pressure != "" && (len(pressures) != 1 || pressures[0] != tt.pressure) {
+				t.Errorf("OnPressure called with %q, want %q", pressures, tt.pressure)
+			}
+			if s.Degraded() != tt.degraded {
+				t.Errorf("Degraded() = %v, want %v", s.Degraded(), tt.degraded)
+			}
+			want := Interval()
+			if tt.degraded {
+				want *= DefaultDegradeFactor
+			}
+			if got := s.CurrentInterval(); got != want {
+				t.Errorf("CurrentInterval() = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestGCMeterIdle(t *testing.T) {
+	tests := []struct {
+		name    string
+		updated time.Duration
+		want    float64
+	}{
+		{"last estimate holds", 0, 0.4},
+		{"no cycle for a while", gcIdleAfter, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := gcMeterAt(t, 0)
+			m.total = math.MaxFloat64 // no CPU time elapsed since the last read
+			m.last, m.updated = 0.4, time.Now().Add(-tt.updated)
+			if got, ok := m.usage(); !ok || got != tt.want {
+				t.Errorf("usage() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
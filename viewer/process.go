@@ -0,0 +1,150 @@
+package viewer
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VProcess is the name of ProcessViewer
+	VProcess = "process"
+	// VHandles is the name of HandlesViewer
+	VHandles = "handles"
+)
+
+// procStats is what the OS reports about the process, 0 where the platform
+// doesn't report it
+type procStats struct {
+	rss, handles, threads float64
+}
+
+// ProcessViewer collects the CPU usage and the resident memory of the process
+// as the OS sees them, e.g. memory outside of the Go heap. It's read from
+// /proc on linux and from the process counters on windows
+type ProcessViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
+
+	mu    sync.Mutex
+	at    time.Time
+	meter cpuMeter
+	cpu   float64
+}
+
+// NewProcessViewer returns the ProcessViewer instance, the CPU usage is a
+// fraction of the GOMAXPROCS capacity
+// Series: RSS / CPU
+func NewProcessViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(append([]ViewerOption{WithSecondaryAxis("CPU")}, vopts...))
+	unit := o.unitOf(VProcess, DimensionBytes)
+	graph := o.newBasicView(VProcess)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Process"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Size"}),
+	)
+	graph.AddSeries("RSS", []opts.LineData{}).
+		AddSeries("CPU", []opts.LineData{})
+
+	o.apply(graph)
+	formatSeries(graph, unit, DimensionBytes, map[string]Dimension{"CPU": DimensionRatio})
+	return &ProcessViewer{graph: graph, opts: o, unit: unit}
+}
+
+func (vr *ProcessViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *ProcessViewer) Name() string {
+	return VProcess
+}
+
+func (vr *ProcessViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Collect returns the resident memory in bytes and the CPU usage between the
+// last two collections, 0 where the platform doesn't report them
+func (vr *ProcessViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+
+	vr.mu.Lock()
+	if !t.Equal(vr.at) {
+		vr.at = t
+		vr.cpu, _ = vr.meter.usage()
+	}
+	cpu := vr.cpu
+	vr.mu.Unlock()
+
+	st := processStats()
+	return []Point{
+		{Viewer: VProcess, Series: "RSS", Value: st.rss, Time: t},
+		{Viewer: VProcess, Series: "CPU", Value: cpu, Time: t},
+	}
+}
+
+func (vr *ProcessViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), vr.unit, 4)
+
+	WriteJSON(w, metrics)
+}
+
+// HandlesViewer collects the open handles and the OS threads of the process,
+// file descriptors on unix and kernel object handles on windows. A steady
+// growth of either is a leak the Go runtime doesn't see
+type HandlesViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+}
+
+// NewHandlesViewer returns the HandlesViewer instance
+// Series: FDs (Handles on windows) / Threads
+func NewHandlesViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(VHandles)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Handles"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Num"}),
+	)
+	graph.AddSeries(handlesSeries, []opts.LineData{}).
+		AddSeries("Threads", []opts.LineData{})
+
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionCount, nil)
+	return &HandlesViewer{graph: graph, opts: o}
+}
+
+func (vr *HandlesViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *HandlesViewer) Name() string {
+	return VHandles
+}
+
+func (vr *HandlesViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Collect returns the open handles and the threads, 0 where the platform
+// doesn't report them
+func (vr *HandlesViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+	st := processStats()
+	return []Point{
+		{Viewer: VHandles, Series: handlesSeries, Value: st.handles, Time: t},
+		{Viewer: VHandles, Series: "Threads", Value: st.threads, Time: t},
+	}
+}
+
+func (vr *HandlesViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 0)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,70 @@
+package viewer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProcessViewers(t *testing.T) {
+	tests := []struct {
+		name   string
+		viewer Viewer
+		series []string
+	}{
+		{"process", NewProcessViewer(), []string{"RSS", "CPU"}},
+		{"handles", NewHandlesViewer(), []string{handlesSeries, "Threads"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StatsMgr{time: time.Unix(1700000000, 0)}
+			tt.viewer.SetStatsMgr(s)
+
+			points := tt.viewer.(Collector).Collect()
+			series := tt.viewer.View().MultiSeries
+			if len(points) != len(tt.series) || len(series) != len(tt.series) {
+				t.Fatalf("collected %d points for %d series, want %d", len(points), len(series), len(tt.series))
+			}
+			for i, p := range points {
+				if p.Viewer != tt.name || p.Series != tt.series[i] || series[i].Name != tt.series[i] {
+					t.Errorf("point %d = %s %s, want %s %s", i, p.Viewer, p.Series, tt.name, tt.series[i])
+				}
+				if !p.Time.Equal(s.time) {
+					t.Errorf("point %d at %v, want the collection time %v", i, p.Time, s.time)
+				}
+				if p.Value < 0 {
+					t.Errorf("point %d = %v, want a non-negative value", i, p.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessViewerCPU(t *testing.T) {
+	s := &StatsMgr{time: time.Unix(1700000000, 0)}
+	vr := NewProcessViewer().(*ProcessViewer)
+	vr.SetStatsMgr(s)
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		// measured tells whether the meter is read again
+		measured bool
+	}{
+		{"first collection", 0, true},
+		{"same collection", 0, false},
+		{"next collection", time.Second, true},
+		{"collected again", 0, false},
+	}
+	var last time.Time
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.time = s.time.Add(tt.advance)
+			vr.Collect()
+			measured := !vr.meter.wall.Equal(last)
+			last = vr.meter.wall
+			if measured != tt.measured {
+				t.Errorf("meter read = %v, want %v", measured, tt.measured)
+			}
+		})
+	}
+}
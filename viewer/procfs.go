@@ -0,0 +1,211 @@
+package viewer
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VCtxSwitch is the name of the context switches ProcViewer
+	VCtxSwitch = "ctxswitch"
+	// VPageFaults is the name of the page faults ProcViewer
+	VPageFaults = "pagefaults"
+	// VProcIO is the name of the block I/O ProcViewer
+	VProcIO = "procio"
+)
+
+// ProcViewer charts the per second rates of counters the kernel keeps in
+// /proc, e.g. the context switches or the page faults of the process which
+// explain slowdowns the Go runtime doesn't see, or gauges like the load
+// average. It charts nothing outside of linux
+type ProcViewer struct {
+	name   string
+	series []string
+	read   func() ([]float64, bool)
+	gauge  bool
+	smgr   *StatsMgr
+	graph  *charts.Line
+	opts   viewerOptions
+	unit   Unit
+
+	mu     sync.Mutex
+	at     time.Time
+	cur    []float64
+	prev   []float64
+	prevAt time.Time
+}
+
+// newProcViewer returns the ProcViewer of the series read, the series
+// missing in dims measure dim
+func newProcViewer(name, title string, dim Dimension, dims map[string]Dimension, series []string, read func() ([]float64, bool), vopts []ViewerOption) *ProcViewer {
+	o := newViewerOptions(vopts)
+	unit := o.unitOf(name, dim)
+	graph := o.newBasicView(name)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: title, Subtitle: "Per second"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Rate"}),
+	)
+	for _, s := range series {
+		graph.AddSeries(s, []opts.LineData{})
+	}
+
+	o.apply(graph)
+	formatSeries(graph, unit, dim, dims)
+	return &ProcViewer{name: name, series: series, read: read, graph: graph, opts: o, unit: unit}
+}
+
+// newProcGauge returns the ProcViewer charting the values read as they are
+func newProcGauge(name, title string, dim Dimension, series []string, read func() ([]float64, bool), vopts []ViewerOption) *ProcViewer {
+	vr := newProcViewer(name, title, dim, nil, series, read, vopts)
+	vr.graph.Title.Subtitle = ""
+	vr.gauge = true
+	return vr
+}
+
+// NewCtxSwitchViewer returns the ProcViewer of the voluntary context
+// switches, e.g. blocking syscalls, and the involuntary ones, the threads
+// preempted by the kernel as the CPU is saturated or throttled
+// Series: Voluntary / Involuntary
+func NewCtxSwitchViewer(vopts ...ViewerOption) Viewer {
+	return newProcViewer(VCtxSwitch, "Context switches", DimensionCount, nil,
+		[]string{"Voluntary", "Involuntary"}, func() ([]float64, bool) {
+			return procKeyValues("/proc/self/status", "voluntary_ctxt_switches", "nonvoluntary_ctxt_switches")
+		}, vopts)
+}
+
+// NewPageFaultsViewer returns the ProcViewer of the minor page faults and of
+// the major ones, which read the page from the disk, e.g. under memory
+// pressure or from swap
+// Series: Minor / Major
+func NewPageFaultsViewer(vopts ...ViewerOption) Viewer {
+	return newProcViewer(VPageFaults, "Page faults", DimensionCount, nil,
+		[]string{"Minor", "Major"}, procFaults, vopts)
+}
+
+// NewProcIOViewer returns the ProcViewer of the bytes the process caused to
+// be read from and written to the storage
+// Series: Read / Write
+func NewProcIOViewer(vopts ...ViewerOption) Viewer {
+	return newProcViewer(VProcIO, "Block I/O", DimensionBytes, nil,
+		[]string{"Read", "Write"}, func() ([]float64, bool) {
+			return procKeyValues("/proc/self/io", "read_bytes", "write_bytes")
+		}, vopts)
+}
+
+// procKeyValues returns the values of the keys of a `key: value` file of
+// /proc, in the order of the keys. Values in kB are converted to bytes
+func procKeyValues(path string, keys ...string) ([]float64, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	values := make([]float64, len(keys))
+	found := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, value, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		for i, k := range keys {
+			if key != k {
+				continue
+			}
+			value, kb := strings.CutSuffix(strings.TrimSpace(value), " kB")
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, false
+			}
+			if kb {
+				v *= 1024
+			}
+			values[i] = v
+			found++
+		}
+	}
+	return values, found == len(keys)
+}
+
+// procFaults returns the minor and major faults of /proc/self/stat, the
+// fields are counted after the command which may contain spaces
+func procFaults() ([]float64, bool) {
+	bs, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return nil, false
+	}
+	s := string(bs)
+	fields := strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+	// minflt and majflt are the 10th and the 12th fields, the state the 3rd
+	if len(fields) < 10 {
+		return nil, false
+	}
+	minor, err := strconv.ParseFloat(fields[7], 64)
+	if err != nil {
+		return nil, false
+	}
+	major, err := strconv.ParseFloat(fields[9], 64)
+	if err != nil {
+		return nil, false
+	}
+	return []float64{minor, major}, true
+}
+
+func (vr *ProcViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *ProcViewer) Name() string {
+	return vr.name
+}
+
+func (vr *ProcViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Collect returns the rates per second between the last two collections or
+// the gauges, nothing when they can't be read
+func (vr *ProcViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	if !t.Equal(vr.at) {
+		cur, ok := vr.read()
+		if !ok {
+			return nil
+		}
+		vr.prev, vr.prevAt = vr.cur, vr.at
+		vr.cur, vr.at = cur, t
+	}
+	if vr.cur == nil {
+		return nil
+	}
+
+	elapsed := vr.at.Sub(vr.prevAt).Seconds()
+	points := make([]Point, len(vr.series))
+	for i, s := range vr.series {
+		points[i] = Point{Viewer: vr.name, Series: s, Time: t}
+		if vr.gauge {
+			points[i].Value = vr.cur[i]
+		} else if vr.prev != nil && elapsed > 0 {
+			points[i].Value = (vr.cur[i] - vr.prev[i]) / elapsed
+		}
+	}
+	return points
+}
+
+func (vr *ProcViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), vr.unit, 2)
+
+	WriteJSON(w, metrics)
+}
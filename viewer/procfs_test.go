@@ -0,0 +1,167 @@
+package viewer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestProcKeyValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		keys    []string
+		want    []float64
+		ok      bool
+	}{
+		{
+			name:    "status",
+			content: "Name:\tapp\nvoluntary_ctxt_switches:\t120\nnonvoluntary_ctxt_switches:\t7\n",
+			keys:    []string{"voluntary_ctxt_switches", "nonvoluntary_ctxt_switches"},
+			want:    []float64{120, 7},
+			ok:      true,
+		},
+		{
+			name:    "order of the keys",
+			content: "rchar: 10\nread_bytes: 4096\nwrite_bytes: 8192\n",
+			keys:    []string{"write_bytes", "read_bytes"},
+			want:    []float64{8192, 4096},
+			ok:      true,
+		},
+		{
+			name:    "missing key",
+			content: "read_bytes: 4096\n",
+			keys:    []string{"read_bytes", "write_bytes"},
+		},
+		{
+			name:    "invalid value",
+			content: "read_bytes: many\nwrite_bytes: 1\n",
+			keys:    []string{"read_bytes", "write_bytes"},
+		},
+		{
+			name:    "kB",
+			content: "MemTotal:       16384 kB\nMemAvailable:    8192 kB\nHugePages_Total:       2\n",
+			keys:    []string{"MemTotal", "MemAvailable", "HugePages_Total"},
+			want:    []float64{16384 * 1024, 8192 * 1024, 2},
+			ok:      true,
+		},
+		{
+			name:    "lines without a key",
+			content: "garbage\n\nread_bytes: 1\nwrite_bytes: 2\n",
+			keys:    []string{"read_bytes", "write_bytes"},
+			want:    []float64{1, 2},
+			ok:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "status")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := procKeyValues(path, tt.keys...)
+			if ok != tt.ok || (ok && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("procKeyValues() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if _, ok := procKeyValues(filepath.Join(t.TempDir(), "missing"), "read_bytes"); ok {
+		t.Error("procKeyValues() of a missing file succeeded")
+	}
+}
+
+func TestProcFaults(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("/proc/self/stat is linux only")
+	}
+	faults, ok := procFaults()
+	if !ok || len(faults) != 2 {
+		t.Fatalf("procFaults() = %v, %v", faults, ok)
+	}
+	if faults[0] <= 0 {
+		t.Errorf("minor faults = %v, a running process faults", faults[0])
+	}
+}
+
+func TestProcViewer(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	counters := [][]float64{{100, 10}, {150, 10}, {250, 30}}
+	reads := 0
+	vr := newProcViewer("test", "Test", DimensionCount, nil, []string{"A", "B"}, func() ([]float64, bool) {
+		if reads >= len(counters) {
+			return nil, false
+		}
+		reads++
+		return counters[reads-1], true
+	}, nil)
+	s := &StatsMgr{}
+	vr.SetStatsMgr(s)
+
+	tests := []struct {
+		name string
+		at   time.Duration
+		want []float64
+	}{
+		{"first collection", 0, []float64{0, 0}},
+		{"same collection", 0, []float64{0, 0}},
+		{"one second later", time.Second, []float64{50, 0}},
+		{"two seconds later", 3 * time.Second, []float64{50, 10}},
+		{"collected again", 3 * time.Second, []float64{50, 10}},
+		{"unreadable counters", 4 * time.Second, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.time = start.Add(tt.at)
+			var got []float64
+			for i, p := range vr.Collect() {
+				if p.Series != vr.series[i] || !p.Time.Equal(s.time) {
+					t.Errorf("point %d = %s at %v", i, p.Series, p.Time)
+				}
+				got = append(got, p.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Collect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcGauge(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	loads := [][]float64{{1.5, 0.5}, {2, 0.75}}
+	reads := 0
+	vr := newProcGauge("test", "Test", DimensionCount, []string{"A", "B"}, func() ([]float64, bool) {
+		reads++
+		return loads[(reads-1)%len(loads)], true
+	}, nil)
+	vr.SetStatsMgr(&StatsMgr{})
+	if sub := vr.View().Title.Subtitle; sub != "" {
+		t.Errorf("subtitle = %q, a gauge isn't per second", sub)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Duration
+		want []float64
+	}{
+		{"first collection", 0, []float64{1.5, 0.5}},
+		{"next collection", time.Second, []float64{2, 0.75}},
+		{"collected again", time.Second, []float64{2, 0.75}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vr.smgr.time = start.Add(tt.at)
+			var got []float64
+			for _, p := range vr.Collect() {
+				got = append(got, p.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Collect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
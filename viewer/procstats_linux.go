@@ -0,0 +1,46 @@
+//go:build linux
+
+package viewer
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// handlesSeries names the open handles of the process, file descriptors on
+// unix
+const handlesSeries = "FDs"
+
+// processStats reads the resident memory, the open file descriptors and the
+// threads of the process from /proc/self
+func processStats() procStats {
+	var st procStats
+	if bs, err := os.ReadFile("/proc/self/statm"); err == nil {
+		if fields := strings.Fields(string(bs)); len(fields) > 1 {
+			if pages, err := strconv.ParseFloat(fields[1], 64); err == nil {
+				st.rss = pages * float64(os.Getpagesize())
+			}
+		}
+	}
+	if fds, err := os.ReadDir("/proc/self/fd"); err == nil {
+		st.handles = float64(len(fds))
+	}
+
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return st
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if v, found := strings.CutPrefix(sc.Text(), "Threads:"); found {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				st.threads = n
+			}
+			break
+		}
+	}
+	return st
+}
@@ -0,0 +1,41 @@
+//go:build linux
+
+package viewer
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProcessStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		files int
+	}{
+		{"baseline", 0},
+		{"open files", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := processStats()
+			for i := 0; i < tt.files; i++ {
+				f, err := os.Open(os.DevNull)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+			}
+			st := processStats()
+
+			if st.rss <= 0 {
+				t.Errorf("rss = %v, want the resident memory", st.rss)
+			}
+			if st.threads < 1 {
+				t.Errorf("threads = %v, want at least the main thread", st.threads)
+			}
+			if got := st.handles - before.handles; got != float64(tt.files) {
+				t.Errorf("%v more handles, want %d", got, tt.files)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package viewer
+
+// handlesSeries names the open handles of the process, file descriptors on
+// unix
+const handlesSeries = "FDs"
+
+// processStats is unavailable on this platform, the CPU usage is still
+// measured
+func processStats() procStats {
+	return procStats{}
+}
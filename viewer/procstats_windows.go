@@ -0,0 +1,76 @@
+//go:build windows
+
+package viewer
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// handlesSeries names the open handles of the process, kernel object
+// handles on windows
+const handlesSeries = "Handles"
+
+var (
+	kernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	procGetProcessMemoryInfo  = kernel32.NewProc("K32GetProcessMemoryInfo")
+	procGetProcessHandleCount = kernel32.NewProc("GetProcessHandleCount")
+)
+
+// processMemoryCounters is PROCESS_MEMORY_COUNTERS of psapi.h
+type processMemoryCounters struct {
+	cb                         uint32
+	pageFaultCount             uint32
+	peakWorkingSetSize         uintptr
+	workingSetSize             uintptr
+	quotaPeakPagedPoolUsage    uintptr
+	quotaPagedPoolUsage        uintptr
+	quotaPeakNonPagedPoolUsage uintptr
+	quotaNonPagedPoolUsage     uintptr
+	pagefileUsage              uintptr
+	peakPagefileUsage          uintptr
+}
+
+// processStats reads the working set, the open handles and the threads of
+// the process, the working set is the resident memory of windows
+func processStats() procStats {
+	var st procStats
+	h, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return st
+	}
+
+	var mem processMemoryCounters
+	mem.cb = uint32(unsafe.Sizeof(mem))
+	if ok, _, _ := procGetProcessMemoryInfo.Call(uintptr(h), uintptr(unsafe.Pointer(&mem)), uintptr(mem.cb)); ok != 0 {
+		st.rss = float64(mem.workingSetSize)
+	}
+	var handles uint32
+	if ok, _, _ := procGetProcessHandleCount.Call(uintptr(h), uintptr(unsafe.Pointer(&handles))); ok != 0 {
+		st.handles = float64(handles)
+	}
+	if n, ok := processThreads(); ok {
+		st.threads = n
+	}
+	return st
+}
+
+// processThreads counts the threads of the process from a snapshot of the
+// running processes
+func processThreads() (float64, bool) {
+	snap, err := syscall.CreateToolhelp32Snapshot(syscall.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return 0, false
+	}
+	defer syscall.CloseHandle(snap)
+
+	pid := uint32(syscall.Getpid())
+	var entry syscall.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = syscall.Process32First(snap, &entry); err == nil; err = syscall.Process32Next(snap, &entry) {
+		if entry.ProcessID == pid {
+			return float64(entry.Threads), true
+		}
+	}
+	return 0, false
+}
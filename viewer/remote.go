@@ -0,0 +1,457 @@
+package viewer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// RemoteFormat is the exposition format of a remote endpoint
+type RemoteFormat string
+
+const (
+	// FormatExpvar is the JSON of `/debug/vars`, metrics are dotted paths like `memstats.HeapAlloc`
+	FormatExpvar RemoteFormat = "expvar"
+	// FormatPrometheus is the Prometheus text format of `/metrics`, metrics are
+	// names with optional label matchers like `http_requests_total{code="200"}`
+	FormatPrometheus RemoteFormat = "prometheus"
+	// FormatStatsview is the JSON of another statsview's `/debug/statsview/snapshot`,
+	// metrics are the viewer and series names like `heap.HeapAlloc`
+	FormatStatsview RemoteFormat = "statsview"
+)
+
+// FormatOf guesses the format of the endpoint from its path
+func FormatOf(url string) RemoteFormat {
+	if strings.Contains(url, "/debug/statsview") {
+		return FormatStatsview
+	}
+	if strings.Contains(url, "/debug/vars") {
+		return FormatExpvar
+	}
+	return FormatPrometheus
+}
+
+// RemoteSeries maps a remote metric to a series of the chart
+type RemoteSeries struct {
+	Name   string
+	Metric string
+}
+
+// errNotScraped is returned by the lookups until the first fetch is done
+var errNotScraped = errors.New("statsview: remote target not scraped yet")
+
+// scraper fetches a remote endpoint every interval in the background, the
+// viewers sharing the url share the fetch and read its last result
+type scraper struct {
+	key    string
+	url    string
+	format RemoteFormat
+	client *http.Client
+	users  int
+	stop   chan struct{}
+
+	mu      sync.RWMutex
+	fetched time.Time
+	values  map[string]interface{}
+	samples []promSample
+	err     error
+}
+
+var (
+	scrapersMu sync.Mutex
+	scrapers   = make(map[string]*scraper)
+)
+
+// acquireScraper returns the scraper of the url, started by its first user
+func acquireScraper(url string, format RemoteFormat) *scraper {
+	scrapersMu.Lock()
+	defer scrapersMu.Unlock()
+
+	key := string(format) + " " + url
+	s, ok := scrapers[key]
+	if !ok {
+		s = &scraper{
+			key:    key,
+			url:    url,
+			format: format,
+			client: &http.Client{Timeout: 5 * time.Second},
+			stop:   make(chan struct{}),
+		}
+		scrapers[key] = s
+		go s.run()
+	}
+	s.users++
+	return s
+}
+
+// release stops the scraper once its last user is gone
+func (s *scraper) release() {
+	scrapersMu.Lock()
+	defer scrapersMu.Unlock()
+
+	if s.users--; s.users == 0 {
+		close(s.stop)
+		delete(scrapers, s.key)
+	}
+}
+
+// run fetches the endpoint every interval until the scraper is released
+func (s *scraper) run() {
+	for {
+		s.scrape()
+
+		timer := time.NewTimer(time.Duration(Interval()) * time.Millisecond)
+		select {
+		case <-timer.C:
+		case <-s.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// scrape fetches the endpoint and keeps the result, the readers aren't
+// blocked meanwhile
+func (s *scraper) scrape() {
+	values, samples, err := s.fetch()
+	fetched := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetched, s.err = fetched, err
+	if err == nil {
+		s.values, s.samples = values, samples
+	}
+}
+
+// lookup returns the value of metric of the last fetch and the time it was
+// fetched, the error of the last fetch when it failed
+func (s *scraper) lookup(metric string) (float64, time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.err != nil {
+		return 0, s.fetched, s.err
+	}
+	if s.fetched.IsZero() {
+		return 0, s.fetched, errNotScraped
+	}
+
+	var (
+		v   float64
+		err error
+	)
+	if s.format == FormatPrometheus {
+		v, err = lookupProm(s.samples, metric)
+	} else {
+		v, err = lookupExpvar(s.values, metric)
+	}
+	return v, s.fetched, err
+}
+
+func (s *scraper) fetch() (map[string]interface{}, []promSample, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("statsview: %s answered %s", s.url, resp.Status)
+	}
+
+	switch s.format {
+	case FormatExpvar:
+		var values map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&values)
+		return values, nil, err
+	case FormatStatsview:
+		values, err := decodeSnapshot(resp.Body)
+		return values, nil, err
+	}
+	samples, err := parseProm(resp.Body)
+	return nil, samples, err
+}
+
+// remoteSnapshot is the snapshot served by statsview, values are in base units
+type remoteSnapshot struct {
+	Time    int64 `json:"time"`
+	Viewers []struct {
+		Name   string    `json:"name"`
+		Title  string    `json:"title"`
+		Series []string  `json:"series"`
+		Values []float64 `json:"values"`
+	} `json:"viewers"`
+}
+
+// decodeSnapshot nests the snapshot values by viewer and series name, so they
+// are looked up like expvar paths
+func decodeSnapshot(r io.Reader) (map[string]interface{}, error) {
+	var snap remoteSnapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(snap.Viewers))
+	for _, v := range snap.Viewers {
+		series := make(map[string]interface{}, len(v.Series))
+		for i, name := range v.Series {
+			if i < len(v.Values) {
+				series[name] = v.Values[i]
+			}
+		}
+		values[v.Name] = series
+	}
+	return values, nil
+}
+
+func lookupExpvar(values map[string]interface{}, metric string) (float64, error) {
+	var cur interface{} = values
+	for _, key := range strings.Split(metric, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return 0, fmt.Errorf("statsview: metric %q not found", metric)
+		}
+		if cur, ok = m[key]; !ok {
+			return 0, fmt.Errorf("statsview: metric %q not found", metric)
+		}
+	}
+
+	switch v := cur.(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("statsview: metric %q is not a number", metric)
+}
+
+type promSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// parseProm parses the Prometheus text exposition format, comments and
+// timestamps are ignored
+func parseProm(r io.Reader) ([]promSample, error) {
+	var samples []promSample
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		var s promSample
+		rest := line
+		if i := strings.IndexByte(line, '{'); i > 0 {
+			j := strings.LastIndexByte(line, '}')
+			if j < i {
+				continue
+			}
+			s.name, s.labels, rest = line[:i], parseLabels(line[i+1:j]), line[j+1:]
+		} else {
+			i := strings.IndexAny(line, " \t")
+			if i < 0 {
+				continue
+			}
+			s.name, rest = line[:i], line[i:]
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		s.value = v
+		samples = append(samples, s)
+	}
+	return samples, sc.Err()
+}
+
+// parseLabels parses `a="1",b="2"`, escaped quotes in the values are kept as is
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || eq+1 >= len(s) || s[eq+1] != '"' {
+			break
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = s[eq+2:]
+
+		end := 0
+		for end < len(s) && (s[end] != '"' || (end > 0 && s[end-1] == '\\')) {
+			end++
+		}
+		if end >= len(s) {
+			break
+		}
+		labels[name] = s[:end]
+		s = strings.TrimLeft(s[end+1:], ", ")
+	}
+	return labels
+}
+
+// lookupProm returns the first sample with the metric name having all the given labels
+func lookupProm(samples []promSample, metric string) (float64, error) {
+	name, matchers := metric, map[string]string(nil)
+	if i := strings.IndexByte(metric, '{'); i > 0 && strings.HasSuffix(metric, "}") {
+		name, matchers = metric[:i], parseLabels(metric[i+1:len(metric)-1])
+	}
+
+next:
+	for _, s := range samples {
+		if s.name != name {
+			continue
+		}
+		for k, v := range matchers {
+			if s.labels[k] != v {
+				continue next
+			}
+		}
+		return s.value, nil
+	}
+	return 0, fmt.Errorf("statsview: metric %q not found", metric)
+}
+
+// RemoteViewer charts metrics of another process read from its expvar,
+// Prometheus or statsview endpoint, for services which couldn't embed the
+// dashboard. The endpoint is scraped in the background from Init to Close,
+// the points are stamped with the time of the fetch
+type RemoteViewer struct {
+	name   string
+	url    string
+	format RemoteFormat
+	series []RemoteSeries
+	unit   Unit
+	smgr   *StatsMgr
+	graph  *charts.Line
+
+	mu      sync.Mutex
+	scraper *scraper
+}
+
+// NewRemoteViewer returns the RemoteViewer charting series from the endpoint
+// at url, values are expected in base units (bytes or seconds) when unit is set
+//
+//	viewer.NewRemoteViewer("api-heap", "API Heap", "http://10.0.0.5:6060/debug/vars",
+//		viewer.FormatExpvar, viewer.UnitMiB,
+//		viewer.RemoteSeries{Name: "Alloc", Metric: "memstats.HeapAlloc"})
+func NewRemoteViewer(name, title, url string, format RemoteFormat, unit Unit, series ...RemoteSeries) Viewer {
+	unit = unitOf(name, unit.dimension(), unit)
+	graph := NewBasicView(name)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: title, Subtitle: url}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Value"}),
+	)
+	for _, s := range series {
+		graph.AddSeries(s.Name, []opts.LineData{})
+	}
+	formatSeries(graph, unit, unit.dimension(), nil)
+
+	return &RemoteViewer{
+		name:   name,
+		url:    url,
+		format: format,
+		series: series,
+		unit:   unit,
+		graph:  graph,
+	}
+}
+
+// Init starts scraping the endpoint
+func (vr *RemoteViewer) Init(context.Context) error {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	if vr.scraper == nil {
+		vr.scraper = acquireScraper(vr.url, vr.format)
+	}
+	return nil
+}
+
+// Close stops scraping the endpoint unless other viewers chart it
+func (vr *RemoteViewer) Close() error {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	if vr.scraper != nil {
+		vr.scraper.release()
+		vr.scraper = nil
+	}
+	return nil
+}
+
+func (vr *RemoteViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *RemoteViewer) Name() string {
+	return vr.name
+}
+
+func (vr *RemoteViewer) View() *charts.Line {
+	return vr.graph
+}
+
+func (vr *RemoteViewer) collect() ([]Point, error) {
+	vr.mu.Lock()
+	s := vr.scraper
+	vr.mu.Unlock()
+	if s == nil {
+		return nil, errNotScraped
+	}
+
+	points := make([]Point, 0, len(vr.series))
+	for _, rs := range vr.series {
+		v, fetched, err := s.lookup(rs.Metric)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, Point{Viewer: vr.name, Series: rs.Name, Value: v, Time: fetched})
+	}
+	return points, nil
+}
+
+// Collect returns the remote values of the last fetch, nothing when the
+// endpoint failed or wasn't scraped yet
+func (vr *RemoteViewer) Collect() []Point {
+	points, err := vr.collect()
+	if err != nil && err != errNotScraped {
+		Logger().Warn("statsview: failed to scrape remote target", "viewer", vr.name, "err", err)
+	}
+	return points
+}
+
+func (vr *RemoteViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	points, err := vr.collect()
+	if err == errNotScraped {
+		WriteError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		Logger().Warn("statsview: failed to scrape remote target", "viewer", vr.name, "err", err)
+		WriteError(w, http.StatusBadGateway, err)
+		return
+	}
+	metrics := metricsOf(points, vr.unit, 2)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,250 @@
+package viewer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]string
+	}{
+		{``, map[string]string{}},
+		{`a="1"`, map[string]string{"a": "1"}},
+		{`a="1",b="2"`, map[string]string{"a": "1", "b": "2"}},
+		{` a="1", b="" `, map[string]string{"a": "1", "b": ""}},
+		{`path="/a,b=c"`, map[string]string{"path": "/a,b=c"}},
+		{`msg="say \"hi\"",x="y"`, map[string]string{"msg": `say \"hi\"`, "x": "y"}},
+		{`a="1",b=2`, map[string]string{"a": "1"}},
+		{`a="unterminated`, map[string]string{}},
+		{`novalue`, map[string]string{}},
+	}
+	for _, tt := range tests {
+		if got := parseLabels(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseLabels(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseProm(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []promSample
+	}{
+		{"empty", "", nil},
+		{"comments", "# HELP up Up\n# TYPE up gauge\n", nil},
+		{"plain", "up 1\n", []promSample{{name: "up", value: 1}}},
+		{"timestamp ignored", "up 1 1700000000000\n", []promSample{{name: "up", value: 1}}},
+		{"tab separated", "up\t0.5\n", []promSample{{name: "up", value: 0.5}}},
+		{
+			"labels",
+			`http_requests_total{code="200",method="GET"} 1027` + "\n",
+			[]promSample{{name: "http_requests_total", labels: map[string]string{"code": "200", "method": "GET"}, value: 1027}},
+		},
+		{
+			"brace in a value",
+			`m{path="/{id}"} 3` + "\n",
+			[]promSample{{name: "m", labels: map[string]string{"path": "/{id}"}, value: 3}},
+		},
+		{"exponent", "go_memstats_alloc_bytes 1.5e+06\n", []promSample{{name: "go_memstats_alloc_bytes", value: 1.5e6}}},
+		{"special values", "a +Inf\nb -Inf\n", []promSample{{name: "a", value: math.Inf(1)}, {name: "b", value: math.Inf(-1)}}},
+		{"invalid lines skipped", "novalue\nbad{a=\"1\" 2\nx abc\nok 2\n", []promSample{{name: "ok", value: 2}}},
+		{"blank lines", "\n  \nup 1\n\n", []promSample{{name: "up", value: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProm(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseProm() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupProm(t *testing.T) {
+	samples := []promSample{
+		{name: "requests", labels: map[string]string{"code": "200"}, value: 10},
+		{name: "requests", labels: map[string]string{"code": "500"}, value: 2},
+		{name: "up", value: 1},
+	}
+	tests := []struct {
+		metric string
+		want   float64
+		ok     bool
+	}{
+		{"up", 1, true},
+		{"requests", 10, true},
+		{`requests{code="500"}`, 2, true},
+		{`requests{code="404"}`, 0, false},
+		{"down", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := lookupProm(samples, tt.metric)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("lookupProm(%q) = %v, %v, want %v, ok %v", tt.metric, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		url  string
+		want RemoteFormat
+	}{
+		{"http://localhost:6060/debug/vars", FormatExpvar},
+		{"http://localhost:9090/metrics", FormatPrometheus},
+		{"http://localhost:18066/debug/statsview/snapshot", FormatStatsview},
+	}
+	for _, tt := range tests {
+		if got := FormatOf(tt.url); got != tt.want {
+			t.Errorf("FormatOf(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeSnapshot(t *testing.T) {
+	values, err := decodeSnapshot(strings.NewReader(`{"time":1,"viewers":[
+		{"name":"heap","title":"Heap","series":["HeapAlloc","HeapInuse"],"values":[1024,2048]},
+		{"name":"goroutine","title":"Goroutines","series":["Goroutines"],"values":[]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		metric string
+		want   float64
+		ok     bool
+	}{
+		{"heap.HeapAlloc", 1024, true},
+		{"heap.HeapInuse", 2048, true},
+		{"heap.HeapSys", 0, false},
+		{"goroutine.Goroutines", 0, false},
+		{"gcnum.GcNum", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := lookupExpvar(values, tt.metric)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("lookupExpvar(%q) = %v, %v, want %v, ok %v", tt.metric, got, err, tt.want, tt.ok)
+		}
+	}
+
+	if _, err := decodeSnapshot(strings.NewReader(`{"viewers":`)); err == nil {
+		t.Error("decodeSnapshot() accepted a truncated snapshot")
+	}
+}
+
+func waitScraped(t *testing.T, vr *RemoteViewer) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, _, err := vr.scraper.lookup("up"); err != errNotScraped {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the endpoint wasn't scraped")
+}
+
+func TestRemoteViewer(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		init   bool
+		points int
+		served int
+	}{
+		{"last fetch", http.StatusOK, true, 1, http.StatusOK},
+		{"failing endpoint", http.StatusInternalServerError, true, 0, http.StatusBadGateway},
+		{"not initialized", http.StatusOK, false, 0, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt32(&hits, 1)
+				w.WriteHeader(tt.status)
+				fmt.Fprintln(w, "up 1")
+			}))
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			vr := NewRemoteViewer("remote-up", "Up", srv.URL, FormatPrometheus, UnitNone,
+				RemoteSeries{Name: "Up", Metric: "up"}).(*RemoteViewer)
+			vr.SetStatsMgr(NewStatsMgr(ctx))
+			if tt.init {
+				vr.Init(ctx)
+				defer vr.Close()
+				waitScraped(t, vr)
+			}
+
+			for i := 0; i < 3; i++ {
+				points := vr.Collect()
+				if len(points) != tt.points {
+					t.Fatalf("got %d points, want %d", len(points), tt.points)
+				}
+				if len(points) > 0 {
+					if _, fetched, _ := vr.scraper.lookup("up"); !points[0].Time.Equal(fetched) {
+						t.Errorf("point stamped %v, want the fetch time %v", points[0].Time, fetched)
+					}
+				}
+				rec := httptest.NewRecorder()
+				vr.Serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+				if rec.Code != tt.served {
+					t.Errorf("served %d, want %d", rec.Code, tt.served)
+				}
+			}
+			// the reads don't fetch, only the background loop does
+			if tt.init && atomic.LoadInt32(&hits) != 1 {
+				t.Errorf("fetched %d times, want once", hits)
+			}
+		})
+	}
+}
+
+func TestRemoteViewerClose(t *testing.T) {
+	url := "http://127.0.0.1:1/metrics"
+	key := string(FormatPrometheus) + " " + url
+	a := NewRemoteViewer("a", "A", url, FormatPrometheus, UnitNone).(*RemoteViewer)
+	b := NewRemoteViewer("b", "B", url, FormatPrometheus, UnitNone).(*RemoteViewer)
+
+	scraping := func() bool {
+		scrapersMu.Lock()
+		defer scrapersMu.Unlock()
+		_, ok := scrapers[key]
+		return ok
+	}
+	tests := []struct {
+		name string
+		do   func()
+		want bool
+	}{
+		{"created", func() {}, false},
+		{"first init", func() { a.Init(context.Background()) }, true},
+		{"second init", func() { b.Init(context.Background()) }, true},
+		{"init again", func() { b.Init(context.Background()) }, true},
+		{"first close", func() { a.Close() }, true},
+		{"close again", func() { a.Close() }, true},
+		{"last close", func() { b.Close() }, false},
+	}
+	for _, tt := range tests {
+		tt.do()
+		if got := scraping(); got != tt.want {
+			t.Errorf("%s: scraping = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package viewer
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// ReplaySeries is a recorded series in base units, its times ascending.
+// Dashed series are drawn dashed, e.g. the ones of another session overlaid
+// for a comparison
+type ReplaySeries struct {
+	Name      string
+	Dimension Dimension
+	Times     []time.Time
+	Values    []float64
+	Dashed    bool
+}
+
+// ReplayViewer charts a recorded session again, the points are played at
+// the pace they were recorded from the first collection on and start over
+// at the end of the session
+type ReplayViewer struct {
+	name        string
+	description string
+	series      []ReplaySeries
+	first, last time.Time
+	mu          sync.Mutex
+	started     time.Time
+	smgr        *StatsMgr
+	graph       *charts.Line
+}
+
+// NewReplayViewer returns the ReplayViewer of the recorded series, the ones
+// without points are left out
+//
+//	viewer.NewReplayViewer("heap", "Heap", "", viewer.ReplaySeries{
+//		Name: "Alloc", Dimension: viewer.DimensionBytes, Times: times, Values: values})
+func NewReplayViewer(name, title, description string, series ...ReplaySeries) *ReplayViewer {
+	vr := &ReplayViewer{name: name, description: description}
+	dims := make(map[string]Dimension, len(series))
+	for _, s := range series {
+		if len(s.Times) == 0 || len(s.Times) != len(s.Values) {
+			continue
+		}
+		if vr.first.IsZero() || s.Times[0].Before(vr.first) {
+			vr.first = s.Times[0]
+		}
+		if end := s.Times[len(s.Times)-1]; end.After(vr.last) {
+			vr.last = end
+		}
+		dims[s.Name] = s.Dimension
+		vr.series = append(vr.series, s)
+	}
+
+	axis := DimensionCount
+	if len(vr.series) > 0 && vr.series[0].Dimension != "" {
+		axis = vr.series[0].Dimension
+	}
+	vr.graph = NewBasicView(name)
+	vr.graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: title, Subtitle: "replay"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Value"}),
+	)
+	for _, s := range vr.series {
+		if s.Dashed {
+			vr.graph.AddSeries(s.Name, []opts.LineData{}, charts.WithLineStyleOpts(opts.LineStyle{Type: "dashed"}))
+			continue
+		}
+		vr.graph.AddSeries(s.Name, []opts.LineData{})
+	}
+	formatSeries(vr.graph, UnitAuto, axis, dims)
+	return vr
+}
+
+func (vr *ReplayViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *ReplayViewer) Name() string {
+	return vr.name
+}
+
+func (vr *ReplayViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Description returns the description of the recorded viewer
+func (vr *ReplayViewer) Description() string {
+	return vr.description
+}
+
+// SetSpan sets the replayed span, the one of its points by default. The
+// viewers of a session replay it in step with the span of the session
+func (vr *ReplayViewer) SetSpan(first, last time.Time) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	vr.first, vr.last = first, last
+}
+
+// Collect returns the recorded points at the replayed time, with their
+// recorded times
+func (vr *ReplayViewer) Collect() []Point {
+	now := vr.smgr.Now()
+	vr.mu.Lock()
+	if vr.started.IsZero() {
+		vr.started = now
+	}
+	elapsed := now.Sub(vr.started)
+	first, last := vr.first, vr.last
+	vr.mu.Unlock()
+
+	// the last point is held for an interval before starting over
+	at := first
+	if span := last.Sub(first); span > 0 {
+		at = first.Add(elapsed % (span + time.Duration(Interval())*time.Millisecond))
+	}
+
+	points := make([]Point, 0, len(vr.series))
+	for _, s := range vr.series {
+		// the last point recorded by then, the first one before the series started
+		i := sort.Search(len(s.Times), func(i int) bool { return s.Times[i].After(at) }) - 1
+		if i < 0 {
+			i = 0
+		}
+		points = append(points, Point{Viewer: vr.name, Series: s.Name, Value: s.Values[i], Time: s.Times[i]})
+	}
+	return points
+}
+
+func (vr *ReplayViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := metricsOf(vr.Collect(), UnitAuto, 2)
+
+	WriteJSON(w, metrics)
+}
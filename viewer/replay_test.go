@@ -0,0 +1,110 @@
+package viewer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReplayViewer(t *testing.T) {
+	epoch := time.Unix(1700000000, 0)
+	at := func(s int) time.Time { return epoch.Add(time.Duration(s) * time.Second) }
+	clock := &manualClock{now: time.Unix(0, 0)}
+	vr := NewReplayViewer("heap", "Heap", "recorded heap",
+		ReplaySeries{Name: "Alloc", Dimension: DimensionBytes, Times: []time.Time{at(0), at(2), at(4)}, Values: []float64{1, 2, 3}},
+		ReplaySeries{Name: "Sys", Times: []time.Time{at(2)}, Values: []float64{10}},
+		ReplaySeries{Name: "Empty"},
+		ReplaySeries{Name: "Mismatched", Times: []time.Time{at(0)}, Values: []float64{1, 2}},
+	)
+	vr.SetStatsMgr(&StatsMgr{clock: clock})
+
+	if got, want := len(vr.View().MultiSeries), 2; got != want {
+		t.Fatalf("%d series charted, want %d", got, want)
+	}
+
+	// the span is 4s and the last point is held for the interval of 2s
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    []Point
+	}{
+		{"first collection", 0, []Point{
+			{Viewer: "heap", Series: "Alloc", Value: 1, Time: at(0)},
+			{Viewer: "heap", Series: "Sys", Value: 10, Time: at(2)},
+		}},
+		{"between points", 3 * time.Second, []Point{
+			{Viewer: "heap", Series: "Alloc", Value: 2, Time: at(2)},
+			{Viewer: "heap", Series: "Sys", Value: 10, Time: at(2)},
+		}},
+		{"last point held", 2 * time.Second, []Point{
+			{Viewer: "heap", Series: "Alloc", Value: 3, Time: at(4)},
+			{Viewer: "heap", Series: "Sys", Value: 10, Time: at(2)},
+		}},
+		{"started over", time.Second, []Point{
+			{Viewer: "heap", Series: "Alloc", Value: 1, Time: at(0)},
+			{Viewer: "heap", Series: "Sys", Value: 10, Time: at(2)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			if got := vr.Collect(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Collect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayViewerSetSpan(t *testing.T) {
+	epoch := time.Unix(1700000000, 0)
+	at := func(s int) time.Time { return epoch.Add(time.Duration(s) * time.Second) }
+	clock := &manualClock{now: time.Unix(0, 0)}
+	vr := NewReplayViewer("heap", "Heap", "",
+		ReplaySeries{Name: "Alloc", Times: []time.Time{at(10), at(12)}, Values: []float64{1, 2}})
+	vr.SetStatsMgr(&StatsMgr{clock: clock})
+	// replayed in step with a session starting 10s earlier
+	vr.SetSpan(at(0), at(12))
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    float64
+	}{
+		{"before the series started", 0, 1},
+		{"series started", 10 * time.Second, 1},
+		{"later point", 2 * time.Second, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Advance(tt.advance)
+			if got := vr.Collect()[0].Value; got != tt.want {
+				t.Errorf("Collect() value = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayViewerDashed(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	vr := NewReplayViewer("heap", "Heap", "",
+		ReplaySeries{Name: "Alloc", Times: []time.Time{at}, Values: []float64{1}},
+		ReplaySeries{Name: "Alloc (after)", Times: []time.Time{at}, Values: []float64{2}, Dashed: true},
+	)
+	tests := []struct {
+		series int
+		want   string
+	}{
+		{0, ""},
+		{1, "dashed"},
+	}
+	for _, tt := range tests {
+		s := vr.View().MultiSeries[tt.series]
+		var got string
+		if s.LineStyle != nil {
+			got = s.LineStyle.Type
+		}
+		if got != tt.want {
+			t.Errorf("%s line type = %q, want %q", s.Name, got, tt.want)
+		}
+	}
+}
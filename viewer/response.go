@@ -0,0 +1,113 @@
+package viewer
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// NewMetrics returns the Metrics of values collected at t, the time is
+// formatted with FormatTime and also set in unix milliseconds when
+// WithNumericTime is configured
+func NewMetrics(values []float64, t time.Time) Metrics {
+	m := Metrics{Values: values, Time: FormatTime(t)}
+	if numericTime() && !t.IsZero() {
+		m.Unix = t.UnixMilli()
+	}
+	return m
+}
+
+// WriteJSON writes v as JSON, an encoding error (e.g. a NaN value) is logged
+// and answered with a 500 JSON error instead of an empty body
+func WriteJSON(w http.ResponseWriter, v interface{}) {
+	WriteJSONStatus(w, http.StatusOK, v)
+}
+
+// WriteJSONStatus writes v as JSON with the status code, like WriteJSON. A
+// ResponseBuffer keeps v as it is, its owner encodes it
+func WriteJSONStatus(w http.ResponseWriter, status int, v interface{}) {
+	if b, ok := w.(*ResponseBuffer); ok {
+		b.Header().Set("Content-Type", "application/json")
+		b.status = status
+		b.SetValue(v)
+		return
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		Logger().Error("statsview: failed to encode response", "err", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(bs)
+}
+
+// WriteError writes err as a JSON error with the status code
+func WriteError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	bs, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Write(bs)
+}
+
+// ResponseBuffer buffers the response of a viewer. The value written with
+// WriteJSON is kept unencoded, so the response could be altered without
+// decoding it and is encoded once, in the format negotiated with the client
+type ResponseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	value  interface{}
+	valued bool
+}
+
+// NewResponseBuffer returns an empty buffer writing the headers to header, a
+// new set when it's nil
+func NewResponseBuffer(header http.Header) *ResponseBuffer {
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &ResponseBuffer{header: header, status: http.StatusOK}
+}
+
+func (b *ResponseBuffer) Header() http.Header    { return b.header }
+func (b *ResponseBuffer) WriteHeader(status int) { b.status = status }
+
+// Write appends p to the body, which replaces the value written before
+func (b *ResponseBuffer) Write(p []byte) (int, error) {
+	b.value, b.valued = nil, false
+	return b.body.Write(p)
+}
+
+// Status returns the status code written, 200 by default
+func (b *ResponseBuffer) Status() int {
+	return b.status
+}
+
+// Value returns the value written with WriteJSON, false when the body was
+// written directly
+func (b *ResponseBuffer) Value() (interface{}, bool) {
+	return b.value, b.valued
+}
+
+// SetValue replaces the response with v
+func (b *ResponseBuffer) SetValue(v interface{}) {
+	b.body.Reset()
+	b.value, b.valued = v, true
+}
+
+// Body returns the body written directly
+func (b *ResponseBuffer) Body() []byte {
+	return b.body.Bytes()
+}
+
+// JSON returns the response as JSON, the value encoded or the body as it
+// was written
+func (b *ResponseBuffer) JSON() ([]byte, error) {
+	if !b.valued {
+		return b.body.Bytes(), nil
+	}
+	return json.Marshal(b.value)
+}
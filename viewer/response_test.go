@@ -0,0 +1,120 @@
+package viewer
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewMetrics(t *testing.T) {
+	defer func(numeric bool, format string) {
+		defaultCfg.NumericTime, defaultCfg.TimeFormat = numeric, format
+	}(defaultCfg.NumericTime, defaultCfg.TimeFormat)
+	defaultCfg.TimeFormat = "15:04:05"
+
+	at := time.Date(2026, 10, 16, 12, 30, 15, 0, time.Local)
+	tests := []struct {
+		name    string
+		numeric bool
+		t       time.Time
+		want    Metrics
+	}{
+		{"formatted", false, at, Metrics{Values: []float64{1}, Time: "12:30:15"}},
+		{"numeric", true, at, Metrics{Values: []float64{1}, Time: "12:30:15", Unix: at.UnixMilli()}},
+		{"numeric zero time", true, time.Time{}, Metrics{Values: []float64{1}, Time: time.Time{}.Format("15:04:05")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.NumericTime = tt.numeric
+			got := NewMetrics([]float64{1}, tt.t)
+			if got.Time != tt.want.Time || got.Unix != tt.want.Unix || len(got.Values) != 1 {
+				t.Errorf("NewMetrics() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	defer func(l *slog.Logger) { defaultCfg.Logger = l }(defaultCfg.Logger)
+	defaultCfg.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name   string
+		write  func(w http.ResponseWriter)
+		status int
+		body   string
+	}{
+		{"value", func(w http.ResponseWriter) { WriteJSON(w, Metrics{Values: []float64{1.5}, Time: "t"}) }, http.StatusOK, `{"values":[1.5],"time":"t"}`},
+		{"NaN", func(w http.ResponseWriter) { WriteJSON(w, []float64{math.NaN()}) }, http.StatusInternalServerError, `{"error":"json: unsupported value: NaN"}`},
+		{"error", func(w http.ResponseWriter) { WriteError(w, http.StatusBadGateway, errors.New("down")) }, http.StatusBadGateway, `{"error":"down"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.write(rec)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %s, want application/json", ct)
+			}
+			if rec.Body.String() != tt.body {
+				t.Errorf("body = %s, want %s", rec.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestResponseBuffer(t *testing.T) {
+	m := Metrics{Values: []float64{1.5}, Time: "t"}
+	tests := []struct {
+		name   string
+		write  func(w http.ResponseWriter)
+		status int
+		value  interface{}
+		json   string
+	}{
+		{"value kept", func(w http.ResponseWriter) { WriteJSON(w, m) }, http.StatusOK, m, `{"values":[1.5],"time":"t"}`},
+		{"status", func(w http.ResponseWriter) { WriteJSONStatus(w, http.StatusAccepted, m) }, http.StatusAccepted, m, `{"values":[1.5],"time":"t"}`},
+		{"body", func(w http.ResponseWriter) { w.Write([]byte(`{"a":1}`)) }, http.StatusOK, nil, `{"a":1}`},
+		{
+			"body replaces the value",
+			func(w http.ResponseWriter) {
+				WriteJSON(w, m)
+				w.Write([]byte(`{"a":1}`))
+			},
+			http.StatusOK, nil, `{"a":1}`,
+		},
+		{
+			"value replaces the body",
+			func(w http.ResponseWriter) {
+				w.Write([]byte(`{"a":1}`))
+				WriteJSON(w, m)
+			},
+			http.StatusOK, m, `{"values":[1.5],"time":"t"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewResponseBuffer(nil)
+			tt.write(b)
+			if b.Status() != tt.status {
+				t.Errorf("status = %d, want %d", b.Status(), tt.status)
+			}
+			v, ok := b.Value()
+			if ok != (tt.value != nil) || (ok && !reflect.DeepEqual(v, tt.value)) {
+				t.Errorf("value = %v, %t, want %v", v, ok, tt.value)
+			}
+			bs, err := b.JSON()
+			if err != nil || string(bs) != tt.json {
+				t.Errorf("JSON() = %s, %v, want %s", bs, err, tt.json)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package viewer
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultCompactEvery is the period of the compaction of the history
+const DefaultCompactEvery = time.Minute
+
+// Tier downsamples the recorded points older than After to their means of
+// every Step
+type Tier struct {
+	After time.Duration
+	Step  time.Duration
+}
+
+// Retention bounds the history recorded with WithHistory, whose window is
+// the max age of the points
+type Retention struct {
+	// MaxSamples bounds the points kept a series, the oldest are dropped
+	// first, zero means no bound
+	MaxSamples int
+	// Tiers downsample the older points, e.g. to a point a minute after an
+	// hour and a point every 10 minutes after a day
+	Tiers []Tier
+	// CompactEvery is the period of the background compaction applying the
+	// tiers, DefaultCompactEvery when zero
+	CompactEvery time.Duration
+}
+
+// WithRetention sets the retention of the history, the tiers without a
+// positive step are ignored
+func WithRetention(r Retention) Option {
+	return func(c *config) {
+		tiers := make([]Tier, 0, len(r.Tiers))
+		for _, t := range r.Tiers {
+			if t.Step > 0 && t.After >= 0 {
+				tiers = append(tiers, t)
+			}
+		}
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].After < tiers[j].After })
+		r.Tiers = tiers
+		if r.CompactEvery <= 0 {
+			r.CompactEvery = DefaultCompactEvery
+		}
+		if r.MaxSamples < 0 {
+			r.MaxSamples = 0
+		}
+		c.Retention = r
+	}
+}
+
+// HistoryRetention returns the retention of the history, its tiers sorted
+// by age
+func HistoryRetention() Retention {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	r := defaultCfg.Retention
+	if r.CompactEvery <= 0 {
+		r.CompactEvery = DefaultCompactEvery
+	}
+	r.Tiers = append([]Tier(nil), r.Tiers...)
+	return r
+}
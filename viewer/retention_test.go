@@ -0,0 +1,51 @@
+package viewer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithRetention(t *testing.T) {
+	defer func(r Retention) { defaultCfg.Retention = r }(defaultCfg.Retention)
+
+	tests := []struct {
+		name string
+		r    Retention
+		want Retention
+	}{
+		{"default", Retention{}, Retention{CompactEvery: DefaultCompactEvery}},
+		{
+			"tiers sorted by age",
+			Retention{Tiers: []Tier{{After: 24 * time.Hour, Step: 10 * time.Minute}, {After: time.Hour, Step: time.Minute}}, CompactEvery: time.Second},
+			Retention{Tiers: []Tier{{After: time.Hour, Step: time.Minute}, {After: 24 * time.Hour, Step: 10 * time.Minute}}, CompactEvery: time.Second},
+		},
+		{
+			"invalid tiers ignored",
+			Retention{Tiers: []Tier{{After: time.Hour}, {After: -time.Hour, Step: time.Minute}, {After: 0, Step: time.Second}}},
+			Retention{Tiers: []Tier{{After: 0, Step: time.Second}}, CompactEvery: DefaultCompactEvery},
+		},
+		{"negative max samples", Retention{MaxSamples: -1}, Retention{CompactEvery: DefaultCompactEvery}},
+		{"max samples", Retention{MaxSamples: 100}, Retention{MaxSamples: 100, CompactEvery: DefaultCompactEvery}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetConfiguration(WithRetention(tt.r)); err != nil {
+				t.Fatal(err)
+			}
+			if got := HistoryRetention(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HistoryRetention() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryRetentionCopy(t *testing.T) {
+	defer func(r Retention) { defaultCfg.Retention = r }(defaultCfg.Retention)
+
+	SetConfiguration(WithRetention(Retention{Tiers: []Tier{{After: time.Hour, Step: time.Minute}}}))
+	HistoryRetention().Tiers[0].Step = time.Hour
+	if got := HistoryRetention().Tiers[0].Step; got != time.Minute {
+		t.Errorf("tier step = %v after changing the returned copy, want %v", got, time.Minute)
+	}
+}
@@ -0,0 +1,80 @@
+package viewer
+
+import (
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// runtimeSeries maps a chart series to a runtime/metrics name
+type runtimeSeries struct {
+	Name   string
+	Metric string
+}
+
+// runtimeReader reads runtime/metrics once per collection, metrics not
+// supported by the running Go version read as zero
+type runtimeReader struct {
+	mu      sync.Mutex
+	samples []metrics.Sample
+	at      time.Time
+	values  []float64
+	prev    []float64
+	prevAt  time.Time
+}
+
+func newRuntimeReader(series []runtimeSeries) *runtimeReader {
+	r := &runtimeReader{
+		samples: make([]metrics.Sample, len(series)),
+		values:  make([]float64, len(series)),
+		prev:    make([]float64, len(series)),
+	}
+	for i, s := range series {
+		r.samples[i].Name = s.Metric
+	}
+	return r
+}
+
+// read returns the values at the collection time t, the metrics are read
+// again only when t changed
+func (r *runtimeReader) read(t time.Time) []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !t.Equal(r.at) {
+		copy(r.prev, r.values)
+		r.prevAt = r.at
+		r.at = t
+
+		metrics.Read(r.samples)
+		for i, s := range r.samples {
+			switch s.Value.Kind() {
+			case metrics.KindUint64:
+				r.values[i] = float64(s.Value.Uint64())
+			case metrics.KindFloat64:
+				r.values[i] = s.Value.Float64()
+			default:
+				r.values[i] = 0
+			}
+		}
+	}
+	return append([]float64(nil), r.values...)
+}
+
+// rates returns the per second rates of the cumulative values between the
+// last two collections, zero until there are two
+func (r *runtimeReader) rates(t time.Time) []float64 {
+	values := r.read(t)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rates := make([]float64, len(values))
+	elapsed := r.at.Sub(r.prevAt).Seconds()
+	if r.prevAt.IsZero() || elapsed <= 0 {
+		return rates
+	}
+	for i, v := range values {
+		rates[i] = (v - r.prev[i]) / elapsed
+	}
+	return rates
+}
@@ -0,0 +1,80 @@
+package viewer
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRuntimeReader(t *testing.T) {
+	series := []runtimeSeries{
+		{Name: "Forced", Metric: "/gc/cycles/forced:gc-cycles"},
+		{Name: "Unknown", Metric: "/not/a/metric:units"},
+	}
+	t0 := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		gc   bool
+		// forced is the expected increase of the forced cycles since t0
+		forced float64
+		// rate is the expected rate of the forced cycles
+		rate float64
+	}{
+		{"first collection", t0, false, 0, 0},
+		{"same collection isn't read again", t0, true, 0, 0},
+		{"next collection", t0.Add(2 * time.Second), false, 1, 0.5},
+		{"rate of the last two collections", t0.Add(3 * time.Second), true, 2, 1},
+	}
+	r := newRuntimeReader(series)
+	var base float64
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.gc {
+				runtime.GC()
+			}
+			values := r.read(tt.at)
+			if i == 0 {
+				base = values[0]
+			}
+			if got := values[0] - base; got != tt.forced {
+				t.Errorf("forced cycles increased by %v, want %v", got, tt.forced)
+			}
+			if values[1] != 0 {
+				t.Errorf("unsupported metric = %v, want 0", values[1])
+			}
+			if got := r.rates(tt.at)[0]; got != tt.rate {
+				t.Errorf("rate = %v, want %v", got, tt.rate)
+			}
+		})
+	}
+}
+
+func TestRuntimeViewerCollect(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	s := &StatsMgr{time: at}
+
+	tests := []struct {
+		name   string
+		viewer Viewer
+		series []runtimeSeries
+	}{
+		{"gc cycles", NewGCCyclesViewer(), gcCyclesSeries},
+		{"gc cpu", NewGCCPUViewer(), gcCPUSeries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.viewer.SetStatsMgr(s)
+			points := tt.viewer.(Collector).Collect()
+			if len(points) != len(tt.series) {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.series))
+			}
+			for i, p := range points {
+				if p.Viewer != tt.viewer.Name() || p.Series != tt.series[i].Name || !p.Time.Equal(at) {
+					t.Errorf("point %d = %+v", i, p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package viewer
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// scatterTemplate appends the latest pair of values as a point numbered by
+// the collection, the visual map colors the points from the oldest to the
+// latest one
+const scatterTemplate = pollerTemplate + `var {{ .ViewID }}_seq = 0;
+function {{ .ViewID }}_sync(result) {
+    if (!result || result.values.length < 2) {
+        return;
+    }
+    let opt = goecharts_{{ .ViewID }}.getOption();
+
+    let data = opt.series[0].data;
+    data.push([result.values[0], result.values[1], result.time, {{ .ViewID }}_seq++]);
+    if (data.length > {{ .MaxPoints }}) {
+        data = data.slice(1);
+    }
+    opt.series[0].data = data;
+    opt.visualMap[0].dimension = 3;
+    opt.visualMap[0].min = data[0][3];
+    opt.visualMap[0].max = Math.max(data[data.length - 1][3], data[0][3] + 1);
+    goecharts_{{ .ViewID }}.setOption(opt);
+}`
+
+// scatterJS formats the tooltip of a scatter point, its value is
+// [x, y, time, seq]
+const scatterJS = `function statsview_scatter_tooltip(p, names, dims) {
+    return [
+        p.value[2],
+        names[0] + ": " + statsview_format(p.value[0], dims[0]),
+        names[1] + ": " + statsview_format(p.value[1], dims[1])
+    ].join("<br/>");
+}`
+
+// ScatterViewer plots a series of another viewer against a second one, e.g.
+// the heap against the goroutines, to confirm a suspected correlation. The
+// points are colored by their time, the latest ones stand out
+type ScatterViewer struct {
+	name    string
+	refs    []SeriesRef
+	sources []Collector
+	smgr    *StatsMgr
+	graph   *charts.Line
+	opts    viewerOptions
+}
+
+// Scatter returns the viewer of the named chart plotting y against x, the
+// refs are resolved like the ones of Compose
+//
+//	viewer.Scatter("heap-vs-goroutines",
+//		viewer.Ref(viewer.VGoroutine, "Goroutines"),
+//		viewer.Ref(viewer.VHeap, "Alloc").In(viewer.DimensionBytes),
+//	)
+//
+// WithViewerMaxPoints bounds the points plotted, WithLogScale applies to
+// the Y axis and WithSeries doesn't apply
+func Scatter(name string, x, y SeriesRef, vopts ...ViewerOption) *ScatterViewer {
+	o := newViewerOptions(vopts)
+	graph := charts.NewLine()
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: name}),
+		charts.WithLegendOpts(opts.Legend{Show: false}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true, Trigger: "item"}),
+		charts.WithXAxisOpts(opts.XAxis{Name: x.label, Type: "value", Scale: true}),
+		charts.WithYAxisOpts(opts.YAxis{Name: y.label, Type: "value", Scale: true}),
+		charts.WithVisualMapOpts(opts.VisualMap{
+			Max:     1,
+			Text:    []string{"latest", "oldest"},
+			InRange: &opts.VisualMapInRange{Color: []string{"#d4e4f7", "#5470c6", "#c23531"}},
+		}),
+		toolboxOpts(),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  "600px",
+			Height: "400px",
+			Theme:  string(ChartTheme()),
+		}),
+	)
+	graph.AddSeries(y.label, []opts.LineData{})
+	graph.MultiSeries[0].Type = "scatter"
+	o.applyTitle(graph)
+	o.applyAxes(graph)
+
+	graph.XAxisList[0].AxisLabel = &opts.AxisLabel{
+		Formatter: opts.FuncOpts(fmt.Sprintf("function (v) { return statsview_format(v, '%s'); }", x.dim)),
+	}
+	graph.YAxisList[0].AxisLabel = &opts.AxisLabel{
+		Formatter: opts.FuncOpts(fmt.Sprintf("function (v) { return statsview_format(v, '%s'); }", y.dim)),
+	}
+	graph.Tooltip.Formatter = opts.FuncOpts(fmt.Sprintf(
+		"function (p) { return statsview_scatter_tooltip(p, ['%s', '%s'], ['%s', '%s']); }",
+		x.label, y.label, x.dim, y.dim,
+	))
+	graph.AddJSFuncs(formatJS, scatterJS)
+	recordSeries(graph, []SeriesMeta{
+		{Name: x.label, Dimension: x.dim, Unit: servedUnit(UnitNone, x.dim)},
+		{Name: y.label, Dimension: y.dim, Unit: servedUnit(UnitNone, y.dim)},
+	})
+
+	if err := addViewTemplate(graph, scatterTemplate, name, o.maxPoints); err != nil {
+		Logger().Error("statsview: failed to generate view template", "route", name, "err", err)
+	}
+	return &ScatterViewer{name: name, refs: []SeriesRef{x, y}, graph: graph, opts: o}
+}
+
+// Resolve binds the refs to their viewers, they must be collectors
+func (vr *ScatterViewer) Resolve(lookup func(name string) Viewer) error {
+	sources, err := resolveRefs(vr.name, vr.refs, lookup)
+	vr.sources = sources
+	return err
+}
+
+// SetStatsMgr sets the manager of the viewers referenced with RefOf too
+func (vr *ScatterViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+	setRefsStatsMgr(vr.refs, smgr)
+}
+
+func (vr *ScatterViewer) Name() string {
+	return vr.name
+}
+
+func (vr *ScatterViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Priority is the lowest priority of the referenced viewers
+func (vr *ScatterViewer) Priority() Priority {
+	return refsPriority(vr.sources)
+}
+
+// Collect returns the x and the y series
+func (vr *ScatterViewer) Collect() []Point {
+	return collectRefs(vr.name, vr.refs, vr.sources, vr.smgr.CollectTime())
+}
+
+// Serve returns the x and the y values in base units
+func (vr *ScatterViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := metricsOf(vr.Collect(), UnitNone, vr.opts.precisionOr(6))
+
+	WriteJSON(w, metrics)
+}
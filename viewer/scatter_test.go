@@ -0,0 +1,105 @@
+package viewer
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScatterViewer(t *testing.T) {
+	s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock, numGoroutine: func() int { return 42 }}
+	s.memstats = runtime.MemStats{HeapAlloc: 3 << 20, HeapSys: 8 << 20}
+	lookup := func(name string) Viewer {
+		if name == VHeap {
+			return NewHeapViewer()
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name   string
+		x, y   SeriesRef
+		vopts  []ViewerOption
+		err    string
+		values []float64
+		js     []string
+	}{
+		{
+			name:   "y against x",
+			x:      RefOf(NewGoroutinesViewer(), "Goroutines"),
+			y:      Ref(VHeap, "Alloc").In(DimensionBytes),
+			values: []float64{42, 3 << 20},
+			js: []string{
+				"statsview_format(v, 'count')",
+				"statsview_format(v, 'bytes')",
+				"statsview_scatter_tooltip(p, ['goroutine.Goroutines', 'heap.Alloc'], ['count', 'bytes'])",
+				"data.length > 30)",
+			},
+		},
+		{
+			name:   "labels and max points",
+			x:      Ref(VHeap, "Sys").As("sys").In(DimensionBytes),
+			y:      Ref(VHeap, "Alloc").As("alloc").In(DimensionBytes),
+			vopts:  []ViewerOption{WithViewerMaxPoints(50)},
+			values: []float64{8 << 20, 3 << 20},
+			js:     []string{"['sys', 'alloc']", "data.length > 50)"},
+		},
+		{
+			name: "unknown viewer",
+			x:    Ref(VHeap, "Sys"),
+			y:    Ref("missing", "Alloc"),
+			err:  "references the unknown viewer missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vr := Scatter("scatter", tt.x, tt.y, tt.vopts...)
+			err := vr.Resolve(lookup)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Resolve() = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range vr.sources {
+				v.(Viewer).SetStatsMgr(s)
+			}
+			vr.SetStatsMgr(s)
+
+			graph := vr.View()
+			if typ := graph.MultiSeries[0].Type; typ != "scatter" {
+				t.Errorf("series type = %s, want scatter", typ)
+			}
+			if graph.XAxisList[0].Name != tt.x.label || graph.YAxisList[0].Name != tt.y.label {
+				t.Errorf("axes = %s, %s, want %s, %s", graph.XAxisList[0].Name, graph.YAxisList[0].Name, tt.x.label, tt.y.label)
+			}
+			js := strings.Join(append(graph.JSFunctions.Fns,
+				string(graph.XAxisList[0].AxisLabel.Formatter),
+				string(graph.YAxisList[0].AxisLabel.Formatter),
+				string(graph.Tooltip.Formatter),
+			), "\n")
+			for _, want := range tt.js {
+				if !strings.Contains(js, want) {
+					t.Errorf("the chart doesn't contain %s", want)
+				}
+			}
+
+			rec := httptest.NewRecorder()
+			vr.Serve(rec, httptest.NewRequest("GET", "/debug/statsview/view/scatter", nil))
+			var m Metrics
+			if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Values, tt.values) {
+				t.Errorf("served %v, want %v", m.Values, tt.values)
+			}
+		})
+	}
+}
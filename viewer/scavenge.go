@@ -0,0 +1,72 @@
+package viewer
+
+import (
+	"net/http"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VScavenge is the name of ScavengeViewer
+	VScavenge = "scavenge"
+)
+
+// ScavengeViewer collects the idle heap returned to the OS by the scavenger
+// via `runtime.ReadMemStats()`. Retained is the idle memory still held by
+// the process, which `debug.FreeOSMemory()` would return
+type ScavengeViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
+}
+
+// NewScavengeViewer returns the ScavengeViewer instance
+// Series: Idle / Released / Retained
+func NewScavengeViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	unit := o.unitOf(VScavenge, DimensionBytes)
+	graph := o.newBasicView(VScavenge)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Scavenger"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Size"}),
+	)
+	graph.AddSeries("Idle", []opts.LineData{}).
+		AddSeries("Released", []opts.LineData{}).
+		AddSeries("Retained", []opts.LineData{})
+
+	o.apply(graph)
+	formatSeries(graph, unit, DimensionBytes, nil)
+	return &ScavengeViewer{graph: graph, opts: o, unit: unit}
+}
+
+func (vr *ScavengeViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *ScavengeViewer) Name() string {
+	return VScavenge
+}
+
+func (vr *ScavengeViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Collect returns the scavenger metrics in bytes
+func (vr *ScavengeViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+
+	ms := vr.smgr.MemStats()
+	return []Point{
+		{Viewer: VScavenge, Series: "Idle", Value: float64(ms.HeapIdle), Time: t},
+		{Viewer: VScavenge, Series: "Released", Value: float64(ms.HeapReleased), Time: t},
+		{Viewer: VScavenge, Series: "Retained", Value: float64(ms.HeapIdle - ms.HeapReleased), Time: t},
+	}
+}
+
+func (vr *ScavengeViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), vr.unit, 2)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,42 @@
+package viewer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestScavengeViewer(t *testing.T) {
+	tests := []struct {
+		name     string
+		idle     uint64
+		released uint64
+		want     []float64
+	}{
+		{"nothing released", 8 << 20, 0, []float64{8 << 20, 0, 8 << 20}},
+		{"partly released", 8 << 20, 6 << 20, []float64{8 << 20, 6 << 20, 2 << 20}},
+		{"all released", 3 << 19, 3 << 19, []float64{3 << 19, 3 << 19, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock}
+			s.memstats = runtime.MemStats{HeapIdle: tt.idle, HeapReleased: tt.released}
+			v := NewScavengeViewer()
+			v.SetStatsMgr(s)
+
+			rec := httptest.NewRecorder()
+			v.Serve(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			var m Metrics
+			if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Values, tt.want) {
+				t.Errorf("served %v, want %v", m.Values, tt.want)
+			}
+		})
+	}
+}
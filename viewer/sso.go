@@ -0,0 +1,54 @@
+package viewer
+
+import "net/netip"
+
+// TrustedHeaders authenticates the users by the identity headers set by an
+// authenticating proxy, e.g. oauth2-proxy or Pomerium, in front of statsview
+//
+//	trusted_headers:
+//	  user: X-Forwarded-User
+//	  groups: X-Forwarded-Groups
+//	  admins: [oncall, alice@example.com]
+//	  proxies: ["10.0.0.0/8"]
+type TrustedHeaders struct {
+	// User is the header of the user, e.g. X-Forwarded-User or
+	// X-Pomerium-Claim-Email, the mode is disabled when it's empty
+	User string `yaml:"user" toml:"user"`
+	// Groups is the header of the comma separated groups of the user, e.g.
+	// X-Forwarded-Groups, optional
+	Groups string `yaml:"groups" toml:"groups"`
+	// Readers are the users and groups allowed to read, every user set by
+	// the proxy when it's empty
+	Readers []string `yaml:"readers" toml:"readers"`
+	// Admins are the users and groups allowed to use the admin routes
+	Admins []string `yaml:"admins" toml:"admins"`
+	// Proxies are the CIDRs of the proxies whose headers are trusted, every
+	// address when it's empty: statsview must then only be reachable through
+	// the proxy
+	Proxies []string `yaml:"proxies" toml:"proxies"`
+}
+
+// TrustedHeaderAuth returns the identity headers trusted by WithTrustedHeaders
+func TrustedHeaderAuth() TrustedHeaders {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.TrustedHeaders
+}
+
+// TrustedProxies returns the prefixes of the proxies whose identity headers
+// are trusted, every address is trusted when it's empty
+func TrustedProxies() []netip.Prefix {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.proxies
+}
+
+// WithTrustedHeaders authenticates the requests by the identity headers of
+// an authenticating proxy, mapping its users and groups to the read-only and
+// admin modes. Setting it requires authenticating the read-only routes.
+// SetConfiguration fails on an invalid proxy CIDR
+func WithTrustedHeaders(h TrustedHeaders) Option {
+	return func(c *config) {
+		c.TrustedHeaders = h
+	}
+}
@@ -0,0 +1,48 @@
+package viewer
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestWithTrustedHeaders(t *testing.T) {
+	defer func(h TrustedHeaders, proxies []netip.Prefix) {
+		defaultCfg.TrustedHeaders, defaultCfg.proxies = h, proxies
+	}(defaultCfg.TrustedHeaders, defaultCfg.proxies)
+
+	valid := TrustedHeaders{User: "X-Forwarded-User", Proxies: []string{"10.0.0.0/8", "192.0.2.1"}}
+	tests := []struct {
+		name        string
+		h           TrustedHeaders
+		wantErr     bool
+		wantUser    string
+		wantProxies []netip.Prefix
+	}{
+		{
+			name: "proxies", h: valid, wantUser: "X-Forwarded-User",
+			wantProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.1/32")},
+		},
+		{name: "every proxy", h: TrustedHeaders{User: "X-Forwarded-User"}, wantUser: "X-Forwarded-User", wantProxies: []netip.Prefix{}},
+		{
+			name: "invalid proxy kept the previous headers", h: TrustedHeaders{User: "X-Other", Proxies: []string{"10.0.0.0/33"}}, wantErr: true,
+			wantUser:    "X-Forwarded-User",
+			wantProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.1/32")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetConfiguration(WithTrustedHeaders(valid))
+			err := SetConfiguration(WithTrustedHeaders(tt.h))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetConfiguration() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := TrustedHeaderAuth().User; got != tt.wantUser {
+				t.Errorf("user header = %q, want %q", got, tt.wantUser)
+			}
+			if got := TrustedProxies(); !reflect.DeepEqual(got, tt.wantProxies) {
+				t.Errorf("TrustedProxies() = %v, want %v", got, tt.wantProxies)
+			}
+		})
+	}
+}
@@ -1,9 +1,7 @@
 package viewer
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
@@ -18,22 +16,28 @@ const (
 type StackViewer struct {
 	smgr  *StatsMgr
 	graph *charts.Line
+	opts  viewerOptions
+	unit  Unit
 }
 
 // NewStackViewer returns the StackViewer instance
 // Series: StackSys / StackInuse / MSpanSys / MSpanInuse
-func NewStackViewer() Viewer {
-	graph := NewBasicView(VCStack)
+func NewStackViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	unit := o.unitOf(VCStack, DimensionBytes)
+	graph := o.newBasicView(VCStack)
 	graph.SetGlobalOptions(
 		charts.WithTitleOpts(opts.Title{Title: "Stack"}),
-		charts.WithYAxisOpts(opts.YAxis{Name: "Size", AxisLabel: &opts.AxisLabel{Formatter: "{value} MB"}}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Size"}),
 	)
 	graph.AddSeries("Sys", []opts.LineData{}).
 		AddSeries("Inuse", []opts.LineData{}).
 		AddSeries("MSpan Sys", []opts.LineData{}).
 		AddSeries("MSpan Inuse", []opts.LineData{})
 
-	return &StackViewer{graph: graph}
+	o.apply(graph)
+	formatSeries(graph, unit, DimensionBytes, nil)
+	return &StackViewer{graph: graph, opts: o, unit: unit}
 }
 
 func (vr *StackViewer) SetStatsMgr(smgr *StatsMgr) {
@@ -48,21 +52,21 @@ func (vr *StackViewer) View() *charts.Line {
 	return vr.graph
 }
 
-func (vr *StackViewer) Serve(w http.ResponseWriter, _ *http.Request) {
-	vr.smgr.Tick()
+// Collect returns the stack metrics in bytes
+func (vr *StackViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
 
-	memstats.mu.RLock()
-	metrics := Metrics{
-		Values: []float64{
-			fixedPrecision(float64(memstats.Stats.StackSys)/1024/1024, 2),
-			fixedPrecision(float64(memstats.Stats.StackInuse)/1024/1024, 2),
-			fixedPrecision(float64(memstats.Stats.MSpanSys)/1024/1024, 2),
-			fixedPrecision(float64(memstats.Stats.MSpanInuse)/1024/1024, 2),
-		},
-		Time: time.Unix(vr.smgr.GetTime(), 0).Format(TimeFormat()),
+	ms := vr.smgr.MemStats()
+	return []Point{
+		{Viewer: VCStack, Series: "Sys", Value: float64(ms.StackSys), Time: t},
+		{Viewer: VCStack, Series: "Inuse", Value: float64(ms.StackInuse), Time: t},
+		{Viewer: VCStack, Series: "MSpan Sys", Value: float64(ms.MSpanSys), Time: t},
+		{Viewer: VCStack, Series: "MSpan Inuse", Value: float64(ms.MSpanInuse), Time: t},
 	}
-	memstats.mu.RUnlock()
+}
+
+func (vr *StackViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), vr.unit, 2)
 
-	bs, _ := json.Marshal(metrics)
-	w.Write(bs)
+	WriteJSON(w, metrics)
 }
@@ -0,0 +1,147 @@
+package viewer
+
+import (
+	"bufio"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const (
+	// VTCP is the name of TCPViewer
+	VTCP = "tcp"
+)
+
+// tcpSeries are the states charted by TCPViewer, the other states than
+// LISTEN are counted as Other
+var tcpSeries = []string{"Established", "TimeWait", "CloseWait", "Other"}
+
+// tcpStates maps the states of /proc/net/tcp to the index of their series
+var tcpStates = map[string]int{
+	"01": 0,  // ESTABLISHED
+	"06": 1,  // TIME_WAIT
+	"08": 2,  // CLOSE_WAIT
+	"0A": -1, // LISTEN
+}
+
+// TCPViewer counts the TCP connections of the process by state from
+// /proc/self/net/tcp and tcp6, so connection leaks (CLOSE_WAIT piling up as
+// the peer closed but the process didn't) and lingering sockets show up. It
+// charts nothing outside of linux
+type TCPViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+}
+
+// NewTCPViewer returns the TCPViewer instance. The kernel detaches the
+// sockets in TIME_WAIT from the process, they're the ones of its network
+// namespace, its own in a container
+// Series: Established / TimeWait / CloseWait / Other
+func NewTCPViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(VTCP)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "TCP connections"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Num"}),
+	)
+	for _, s := range tcpSeries {
+		graph.AddSeries(s, []opts.LineData{})
+	}
+
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionCount, nil)
+	return &TCPViewer{graph: graph, opts: o}
+}
+
+func (vr *TCPViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *TCPViewer) Name() string {
+	return VTCP
+}
+
+func (vr *TCPViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// socketInodes returns the inodes of the sockets opened by the process
+func socketInodes() (map[string]bool, bool) {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return nil, false
+	}
+	inodes := make(map[string]bool, len(fds))
+	for _, fd := range fds {
+		link, err := os.Readlink("/proc/self/fd/" + fd.Name())
+		if err != nil {
+			continue
+		}
+		if inode, found := strings.CutPrefix(link, "socket:["); found {
+			inodes[strings.TrimSuffix(inode, "]")] = true
+		}
+	}
+	return inodes, true
+}
+
+// countTCP adds the connections of the table at path to counts, the ones of
+// other processes are left out
+func countTCP(path string, inodes map[string]bool, counts []float64) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+	sc.Scan()
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		state, inode := fields[3], fields[9]
+		i, known := tcpStates[state]
+		switch {
+		case i < 0:
+			continue
+		case !known:
+			i = len(tcpSeries) - 1
+		}
+		// the sockets in TIME_WAIT have no inode anymore
+		if state != "06" && !inodes[inode] {
+			continue
+		}
+		counts[i]++
+	}
+}
+
+// Collect returns the number of connections by state, nothing when the
+// sockets of the process can't be read
+func (vr *TCPViewer) Collect() []Point {
+	inodes, ok := socketInodes()
+	if !ok {
+		return nil
+	}
+	counts := make([]float64, len(tcpSeries))
+	countTCP("/proc/self/net/tcp", inodes, counts)
+	countTCP("/proc/self/net/tcp6", inodes, counts)
+
+	t := vr.smgr.CollectTime()
+	points := make([]Point, len(tcpSeries))
+	for i, s := range tcpSeries {
+		points[i] = Point{Viewer: VTCP, Series: s, Value: counts[i], Time: t}
+	}
+	return points
+}
+
+func (vr *TCPViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 0)
+
+	WriteJSON(w, metrics)
+}
@@ -0,0 +1,97 @@
+package viewer
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// tcpTable is the header of /proc/net/tcp
+const tcpTable = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+// tcpLine is a /proc/net/tcp line of a socket in state with inode
+func tcpLine(state, inode string) string {
+	return "   0: 0100007F:1F90 0100007F:C350 " + state + " 00000000:00000000 00:00000000 00000000  1000        0 " + inode + " 1 0000000000000000 20 4 30 10 -1\n"
+}
+
+func TestCountTCP(t *testing.T) {
+	inodes := map[string]bool{"100": true, "101": true, "102": true, "103": true}
+	tests := []struct {
+		name  string
+		lines []string
+		want  []float64
+	}{
+		{"empty", nil, []float64{0, 0, 0, 0}},
+		{
+			"states",
+			[]string{tcpLine("01", "100"), tcpLine("08", "101"), tcpLine("04", "102"), tcpLine("01", "103")},
+			[]float64{2, 0, 1, 1},
+		},
+		{"listening sockets left out", []string{tcpLine("0A", "100")}, []float64{0, 0, 0, 0}},
+		{"other processes left out", []string{tcpLine("01", "200"), tcpLine("08", "201")}, []float64{0, 0, 0, 0}},
+		{"time wait without inode", []string{tcpLine("06", "0"), tcpLine("06", "0")}, []float64{0, 2, 0, 0}},
+		{"short lines skipped", []string{"   0: 0100007F:1F90\n", tcpLine("01", "100")}, []float64{1, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tcp")
+			if err := os.WriteFile(path, []byte(tcpTable+strings.Join(tt.lines, "")), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			counts := make([]float64, len(tcpSeries))
+			countTCP(path, inodes, counts)
+			if !reflect.DeepEqual(counts, tt.want) {
+				t.Errorf("countTCP() = %v, want %v", counts, tt.want)
+			}
+		})
+	}
+
+	counts := make([]float64, len(tcpSeries))
+	countTCP(filepath.Join(t.TempDir(), "missing"), inodes, counts)
+	if !reflect.DeepEqual(counts, []float64{0, 0, 0, 0}) {
+		t.Errorf("countTCP() of a missing table = %v", counts)
+	}
+}
+
+func TestTCPViewer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("/proc/self/net/tcp is linux only")
+	}
+	vr := NewTCPViewer()
+	vr.SetStatsMgr(&StatsMgr{})
+	established := func() float64 {
+		for _, p := range vr.(Collector).Collect() {
+			if p.Series == "Established" {
+				return p.Value
+			}
+		}
+		t.Fatal("no Established point")
+		return 0
+	}
+
+	before := established()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	s, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// both ends of the connection belong to the process
+	if got := established(); got != before+2 {
+		t.Errorf("%v established connections, want %v", got, before+2)
+	}
+}
@@ -0,0 +1,182 @@
+package viewer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// Unit is the display unit of chart values, metrics are always collected
+// in base units (bytes and seconds) and converted only for rendering
+type Unit string
+
+const (
+	UnitNone Unit = ""
+
+	UnitBytes Unit = "B"
+	UnitKiB   Unit = "KiB"
+	UnitMiB   Unit = "MiB"
+	UnitGiB   Unit = "GiB"
+
+	UnitSeconds      Unit = "s"
+	UnitMilliseconds Unit = "ms"
+	UnitMicroseconds Unit = "µs"
+
+	// UnitAuto serves the values in base units and scales the labels and
+	// the tooltips of the chart to their magnitude
+	UnitAuto Unit = "auto"
+)
+
+var unitFactors = map[Unit]float64{
+	UnitBytes: 1,
+	UnitKiB:   1 << 10,
+	UnitMiB:   1 << 20,
+	UnitGiB:   1 << 30,
+
+	UnitSeconds:      1,
+	UnitMilliseconds: 1e-3,
+	UnitMicroseconds: 1e-6,
+}
+
+// Convert converts v from the base unit to u
+func (u Unit) Convert(v float64) float64 {
+	if f, ok := unitFactors[u]; ok {
+		return v / f
+	}
+	return v
+}
+
+func (u Unit) isTime() bool {
+	return u == UnitSeconds || u == UnitMilliseconds || u == UnitMicroseconds
+}
+
+// Dimension is what the values of a series measure
+type Dimension string
+
+const (
+	DimensionCount   Dimension = "count"
+	DimensionBytes   Dimension = "bytes"
+	DimensionSeconds Dimension = "seconds"
+	DimensionRatio   Dimension = "ratio"
+)
+
+// dimension returns the dimension measured in u, UnitNone and UnitAuto
+// measure counts
+func (u Unit) dimension() Dimension {
+	switch {
+	case u.isTime():
+		return DimensionSeconds
+	case u == UnitBytes || u == UnitKiB || u == UnitMiB || u == UnitGiB:
+		return DimensionBytes
+	}
+	return DimensionCount
+}
+
+// measures reports whether u could be the unit of a dimension
+func (u Unit) measures(dim Dimension) bool {
+	if u == UnitAuto {
+		return true
+	}
+	_, known := unitFactors[u]
+	return known && u.dimension() == dim
+}
+
+// unitOf returns the unit configured for the viewer or def if nothing or
+// a unit of another dimension than dim was configured
+func unitOf(name string, dim Dimension, def Unit) Unit {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	u, ok := defaultCfg.Units[name]
+	if !ok || !u.measures(dim) {
+		return def
+	}
+	return u
+}
+
+// formatJS scales the values of the charts to their magnitude, it's added
+// to the charts formatted by formatSeries. The function declarations are
+// hoisted, they're defined before the chart renders
+const formatJS = `function statsview_scale(v, steps) {
+    let s = steps[0];
+    for (const step of steps) {
+        if (Math.abs(v) >= step[0]) {
+            s = step;
+        }
+    }
+    let n = v / s[0];
+    return (Math.abs(n) >= 100 ? n.toFixed(0) : parseFloat(n.toPrecision(3))) + s[1];
+}
+function statsview_format(v, dim) {
+    if (typeof v !== "number" || !isFinite(v)) {
+        return v;
+    }
+    if (dim && dim.charAt(0) === "=") {
+        return v + " " + dim.slice(1);
+    }
+    switch (dim) {
+    case "bytes":
+        return statsview_scale(v, [[1, " B"], [1024, " KiB"], [1048576, " MiB"], [1073741824, " GiB"], [1099511627776, " TiB"]]);
+    case "seconds":
+        return v === 0 ? "0 s" : statsview_scale(v, [[1e-9, " ns"], [1e-6, " µs"], [1e-3, " ms"], [1, " s"]]);
+    case "ratio":
+        return parseFloat((v * 100).toPrecision(3)) + "%";
+    }
+    return statsview_scale(v, [[1, ""], [1e3, "k"], [1e6, "M"], [1e9, "G"]]);
+}
+function statsview_tooltip(params, dims) {
+    if (!params.length) {
+        return "";
+    }
+    let lines = [params[0].axisValueLabel];
+    for (const p of params) {
+        lines.push(p.marker + p.seriesName + ": " + statsview_format(p.value, dims[p.seriesName]));
+    }
+    return lines.join("<br/>");
+}`
+
+// formatSeries declares the dimensions of the series of graph, the series
+// missing in dims measure the axis dimension. It's called once the axes are
+// set up by viewerOptions.apply. With UnitAuto or UnitNone the
+// Y axis labels and the tooltip are scaled to the magnitude of the values,
+// e.g. KiB/MiB/GiB or µs/ms/s, with a fixed unit the unit is appended
+func formatSeries(graph *charts.Line, unit Unit, axis Dimension, dims map[string]Dimension) {
+	scaled := unit == UnitAuto || unit == UnitNone
+	spec := func(d Dimension) string {
+		if !scaled && d == unit.dimension() {
+			return "=" + string(unit)
+		}
+		return string(d)
+	}
+
+	// a secondary axis measures the dimension of its first series
+	axes := make([]Dimension, len(graph.YAxisList))
+	axes[0] = axis
+	specs := make([]string, 0, len(graph.MultiSeries))
+	series := make([]SeriesMeta, 0, len(graph.MultiSeries))
+	for _, s := range graph.MultiSeries {
+		d, ok := dims[s.Name]
+		if !ok {
+			d = axis
+		}
+		series = append(series, SeriesMeta{Name: s.Name, Dimension: d, Unit: servedUnit(unit, d)})
+		if i := s.YAxisIndex; i > 0 && i < len(axes) && axes[i] == "" {
+			axes[i] = d
+		}
+		specs = append(specs, fmt.Sprintf("'%s': '%s'", s.Name, spec(d)))
+	}
+	for i := range graph.YAxisList {
+		if axes[i] == "" {
+			axes[i] = axis
+		}
+		y := &graph.YAxisList[i]
+		if y.AxisLabel == nil {
+			y.AxisLabel = &opts.AxisLabel{}
+		}
+		y.AxisLabel.Formatter = opts.FuncOpts(fmt.Sprintf("function (v) { return statsview_format(v, '%s'); }", spec(axes[i])))
+	}
+	graph.Tooltip.Formatter = opts.FuncOpts(fmt.Sprintf("function (ps) { return statsview_tooltip(ps, {%s}); }", strings.Join(specs, ", ")))
+	graph.AddJSFuncs(formatJS)
+	recordSeries(graph, series)
+}
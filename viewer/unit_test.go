@@ -0,0 +1,95 @@
+package viewer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+func TestUnitConvert(t *testing.T) {
+	tests := []struct {
+		unit Unit
+		v    float64
+		want float64
+	}{
+		{UnitBytes, 2048, 2048},
+		{UnitKiB, 2048, 2},
+		{UnitMiB, 3 << 20, 3},
+		{UnitGiB, 1 << 29, 0.5},
+		{UnitSeconds, 1.5, 1.5},
+		{UnitMilliseconds, 0.25, 250},
+		{UnitMicroseconds, 0.5, 500000},
+		{Unit("furlong"), 7, 7},
+		{UnitAuto, 7, 7},
+	}
+	for _, tt := range tests {
+		if got := tt.unit.Convert(tt.v); got != tt.want {
+			t.Errorf("%s.Convert(%v) = %v, want %v", tt.unit, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestUnitOf(t *testing.T) {
+	defer func(units map[string]Unit) { defaultCfg.Units = units }(defaultCfg.Units)
+	defaultCfg.Units = map[string]Unit{}
+	SetConfiguration(
+		WithViewerUnit(VHeap, UnitGiB),
+		WithViewerUnit(VCStack, UnitMilliseconds),
+		WithViewerUnit(VBlock, Unit("furlong")),
+		WithViewerUnit(VGCSize, UnitAuto),
+	)
+
+	tests := []struct {
+		name string
+		dim  Dimension
+		def  Unit
+		want Unit
+	}{
+		{VHeap, DimensionBytes, UnitMiB, UnitGiB},
+		{VCStack, DimensionBytes, UnitMiB, UnitMiB},
+		{VBlock, DimensionSeconds, UnitSeconds, UnitSeconds},
+		{VMutex, DimensionSeconds, UnitAuto, UnitAuto},
+		{VGCSize, DimensionBytes, UnitMiB, UnitAuto},
+		{VHeap, DimensionSeconds, UnitAuto, UnitAuto},
+	}
+	for _, tt := range tests {
+		if got := unitOf(tt.name, tt.dim, tt.def); got != tt.want {
+			t.Errorf("unitOf(%s, %s, %s) = %s, want %s", tt.name, tt.dim, tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSeries(t *testing.T) {
+	tests := []struct {
+		name    string
+		unit    Unit
+		axis    Dimension
+		dims    map[string]Dimension
+		label   string
+		tooltip string
+	}{
+		{"auto", UnitAuto, DimensionBytes, nil, "'bytes'", "{'A': 'bytes', 'B': 'bytes'}"},
+		{"none", UnitNone, DimensionCount, nil, "'count'", "{'A': 'count', 'B': 'count'}"},
+		{"fixed unit", UnitMiB, DimensionBytes, nil, "'=MiB'", "{'A': '=MiB', 'B': '=MiB'}"},
+		{"series of another dimension", UnitMilliseconds, DimensionSeconds, map[string]Dimension{"B": DimensionCount}, "'=ms'", "{'A': '=ms', 'B': 'count'}"},
+		{"ratio", UnitAuto, DimensionCount, map[string]Dimension{"A": DimensionRatio}, "'count'", "{'A': 'ratio', 'B': 'count'}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := NewBasicView("test")
+			graph.AddSeries("A", []opts.LineData{}).AddSeries("B", []opts.LineData{})
+			formatSeries(graph, tt.unit, tt.axis, tt.dims)
+
+			if got := string(graph.YAxisList[0].AxisLabel.Formatter); !strings.Contains(got, "statsview_format(v, "+tt.label+")") {
+				t.Errorf("axis label formatter = %s, want the %s dimension", got, tt.label)
+			}
+			if got := string(graph.Tooltip.Formatter); !strings.Contains(got, "statsview_tooltip(ps, "+tt.tooltip+")") {
+				t.Errorf("tooltip formatter = %s, want %s", got, tt.tooltip)
+			}
+			if !strings.Contains(strings.Join(graph.JSFunctions.Fns, ""), "function statsview_format(") {
+				t.Error("the format functions aren't added to the chart")
+			}
+		})
+	}
+}
@@ -4,9 +4,15 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
+	"math"
 	"net/http"
+	"net/netip"
 	"runtime"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"text/template"
@@ -15,23 +21,75 @@ import (
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/go-echarts/go-echarts/v2/types"
+
+	"github.com/mortum5/statsview/exporter"
+	"github.com/mortum5/statsview/notify"
 )
 
 // Metrics
 type Metrics struct {
 	Values []float64 `json:"values"`
 	Time   string    `json:"time"`
+	// Unix is the time in unix milliseconds, only set with WithNumericTime
+	Unix int64 `json:"unix,omitempty"`
+	// GC is set by the memory and GC viewers when GC cycles ran since the
+	// previous collection
+	GC *GCMark `json:"gc,omitempty"`
+	// Series names the values when they're filtered by the `series`
+	// parameter of the API
+	Series []string `json:"series,omitempty"`
+}
+
+// Point is a single value of a viewer series in base units
+type Point struct {
+	Viewer string
+	Series string
+	Value  float64
+	Time   time.Time
 }
 
 type config struct {
 	AutoOpenBrowser bool
 	Interval        int
 	MaxPoints       int
+	maxPointsSet    bool
+	ViewerPoints    map[string]int
 	Template        string
 	ListenAddr      string
 	LinkAddr        string
+	AdvertiseAddr   string
 	TimeFormat      string
+	TimeLocation    *time.Location
 	Theme           Theme
+	Units           map[string]Unit
+	FlightRecorder  time.Duration
+	WithoutPprof    bool
+	WithoutSync     bool
+	CPUThreshold    float64
+	GCThreshold     float64
+	Logger          *slog.Logger
+	AdminToken      string
+	AdminAuth       Authenticator
+	ReadToken       string
+	ReadAuth        Authenticator
+	AllowedCIDRs    []string
+	allowed         []netip.Prefix
+	TrustedHeaders  TrustedHeaders
+	proxies         []netip.Prefix
+	RateLimit       float64
+	RateBurst       int
+	SecurityHeaders map[string]string
+	EmbedAncestors  []string
+	AlwaysCollect   bool
+	NumericTime     bool
+	Viewers         []string
+	History         time.Duration
+	Retention       Retention
+	StuckThreshold  time.Duration
+	Anomalies       float64
+	Notifiers       []notify.Notifier
+	Exporters       []exporter.Exporter
+	Clock           Clock
 }
 
 type Theme string
@@ -41,78 +99,376 @@ const (
 	ThemeMacarons Theme = types.ThemeMacarons
 )
 
-const (
-	DefaultTemplate = `
-$(function () { setInterval({{ .ViewID }}_sync, {{ .Interval }}); });
-function {{ .ViewID }}_sync() {
-    $.ajax({
-        type: "GET",
-        url: "http://{{ .Addr }}/debug/statsview/view/{{ .Route }}",
-        dataType: "json",
-        success: function (result) {
-            let opt = goecharts_{{ .ViewID }}.getOption();
-
-            let x = opt.xAxis[0].data;
-            x.push(result.time);
-            if (x.length > {{ .MaxPoints }}) {
-                x = x.slice(1);
+// pollerTemplate registers the sync function of the view, which is called
+// with its metrics by the single poller of all views, and the hooks saving
+// and restoring the state of its chart in the browser, its zoom and hidden
+// series. A template could replace the hooks to keep more of its state. The
+// metrics are polled under window.statsview_base when the page sets it, e.g.
+// the dashboard mounted in a router group
+const pollerTemplate = `
+window.statsview_views = window.statsview_views || {};
+window.statsview_views["{{ .Route }}"] = {{ .ViewID }}_sync;
+window.statsview_charts = window.statsview_charts || {};
+window.statsview_charts["{{ .Route }}"] = goecharts_{{ .ViewID }};
+window.statsview_state = window.statsview_state || {};
+window.statsview_state["{{ .Route }}"] = {
+    save: function () { return statsview_chart_state(goecharts_{{ .ViewID }}); },
+    restore: function (state) { statsview_chart_restore(goecharts_{{ .ViewID }}, state); }
+};
+if (!window.statsview_poller) {
+    window.statsview_poller = setInterval(function () {
+        $.ajax({
+            type: "GET",
+            url: (window.statsview_base || "http://{{ .Addr }}/debug/statsview") + "/view/all",
+            dataType: "json",
+            success: function (results) {
+                for (const route in results) {
+                    if (window.statsview_views[route]) {
+                        window.statsview_views[route](results[route]);
+                    }
+                }
             }
-            opt.xAxis[0].data = x;
+        });
+    }, {{ .Interval }});
+}
+`
 
-            for (let i = 0; i < result.values.length; i++) {
-                let y = opt.series[i].data;
-                y.push({ value: result.values[i] });
-                if (y.length > {{ .MaxPoints }}) {
-                    y = y.slice(1);
-                }
-                opt.series[i].data = y;
+// ChartGroup is the ECharts group of the charts sharing their time axis,
+// hovering or zooming one of them moves the others
+const ChartGroup = "statsview"
 
-                goecharts_{{ .ViewID }}.setOption(opt);
-            }
+const (
+	DefaultTemplate = pollerTemplate + `function {{ .ViewID }}_sync(result) {
+    if (!result) {
+        return;
+    }
+    let opt = goecharts_{{ .ViewID }}.getOption();
+
+    let x = opt.xAxis[0].data;
+    x.push(result.time);
+    if (x.length > {{ .MaxPoints }}) {
+        x = x.slice(1);
+    }
+    opt.xAxis[0].data = x;
+
+    for (let i = 0; i < result.values.length; i++) {
+        let y = opt.series[i].data;
+        y.push({ value: result.values[i] });
+        if (y.length > {{ .MaxPoints }}) {
+            y = y.slice(1);
         }
-    });
+        opt.series[i].data = y;
+    }
+
+    let marks = opt.series[0] && opt.series[0].markPoint;
+    if (result.gc) {
+        marks = marks || { symbol: "pin", symbolSize: 24, data: [] };
+        if (!marks.data.some(function (d) { return d.coord[0] === result.time; })) {
+            marks.data.push({
+                name: result.gc.forced ? "forced GC" : "GC",
+                coord: [result.time, result.values[0]],
+                value: result.gc.forced ? "F" : result.gc.count,
+                itemStyle: { color: result.gc.forced ? "#c23531" : "#91c7ae" }
+            });
+        }
+    }
+    if (marks) {
+        marks.data = marks.data.filter(function (d) { return x.indexOf(d.coord[0]) >= 0; });
+        opt.series[0].markPoint = marks;
+    }
+    goecharts_{{ .ViewID }}.setOption(opt);
 }`
 	DefaultMaxPoints  = 30
 	DefaultTimeFormat = "15:04:05"
 	DefaultInterval   = 2000
 	DefaultAddr       = "localhost:18066"
 	DefaultTheme      = ThemeMacarons
+	// DefaultStuckThreshold is how long a goroutine waits at the same site
+	// before it's reported as stuck
+	DefaultStuckThreshold = time.Minute
+	// DefaultAnomalyThreshold is how many standard deviations away from its
+	// average a value is anomalous
+	DefaultAnomalyThreshold = 4
 )
 
 var defaultCfg = &config{
-	Interval:   DefaultInterval,
-	MaxPoints:  DefaultMaxPoints,
-	Template:   DefaultTemplate,
-	ListenAddr: DefaultAddr,
-	LinkAddr:   DefaultAddr,
-	TimeFormat: DefaultTimeFormat,
-	Theme:      DefaultTheme,
+	Interval:     DefaultInterval,
+	MaxPoints:    DefaultMaxPoints,
+	Template:     DefaultTemplate,
+	ListenAddr:   DefaultAddr,
+	LinkAddr:     DefaultAddr,
+	TimeFormat:   DefaultTimeFormat,
+	Theme:        DefaultTheme,
+	Units:        map[string]Unit{},
+	ViewerPoints: map[string]int{},
 }
 
 type Option func(c *config)
 
 // Addr returns the default server listening address
 func Addr() string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
 	return defaultCfg.ListenAddr
 }
 
 // LinkAddr returns the default html link address
 func LinkAddr() string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
 	return defaultCfg.LinkAddr
 }
 
+// AdvertiseAddr returns the address announced to the service catalogs, set
+// by WithAdvertiseAddr
+func AdvertiseAddr() string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.AdvertiseAddr
+}
+
 // Interval returns the default collecting interval of ViewManager
 func Interval() int {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
 	return defaultCfg.Interval
 }
 
-// TimeFormat returns time format
+// MaxPoints returns the maximum points of each chart series
+func MaxPoints() int {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.MaxPoints
+}
+
+// MaxPointsOf returns the maximum points of the series of the named viewer:
+// the ones set by WithMaxPointsOf, else the ones of the history window at the
+// interval while the history is enabled and WithMaxPoints isn't set, else
+// MaxPoints
+func MaxPointsOf(name string) int {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	if n, ok := defaultCfg.ViewerPoints[name]; ok {
+		return n
+	}
+	if defaultCfg.History > 0 && !defaultCfg.maxPointsSet {
+		n := int(defaultCfg.History / (time.Duration(defaultCfg.Interval) * time.Millisecond))
+		if n > defaultCfg.MaxPoints {
+			return n
+		}
+	}
+	return defaultCfg.MaxPoints
+}
+
+// ChartTheme returns the theme of the charts
+func ChartTheme() Theme {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.Theme
+}
+
+// Template returns the view template
+func Template() string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.Template
+}
+
+// TimeFormat returns time format, the default format shows milliseconds
+// when the interval is shorter than a second
 func TimeFormat() string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	if defaultCfg.TimeFormat == DefaultTimeFormat && defaultCfg.Interval < 1000 {
+		return DefaultTimeFormat + ".000"
+	}
 	return defaultCfg.TimeFormat
 }
 
+// TimeLocation returns the zone the times are formatted in
+func TimeLocation() *time.Location {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	if defaultCfg.TimeLocation == nil {
+		return time.Local
+	}
+	return defaultCfg.TimeLocation
+}
+
+// numericTime returns whether the served metrics get their time in unix
+// milliseconds too
+func numericTime() bool {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.NumericTime
+}
+
+// FormatTime formats t with TimeFormat in TimeLocation
+func FormatTime(t time.Time) string {
+	return t.In(TimeLocation()).Format(TimeFormat())
+}
+
+// FlightRecorderWindow returns the execution trace window kept by the flight recorder
+func FlightRecorderWindow() time.Duration {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.FlightRecorder
+}
+
+// PprofEnabled returns whether the `/debug/pprof/*` routes are served
+func PprofEnabled() bool {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return !defaultCfg.WithoutPprof
+}
+
+// ChartSync returns whether the cursors and the zoom of the charts are synchronized
+func ChartSync() bool {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return !defaultCfg.WithoutSync
+}
+
+// CPUThreshold returns the CPU usage which degrades the collection, zero means never
+func CPUThreshold() float64 {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.CPUThreshold
+}
+
+// GCThreshold returns the GC CPU fraction which degrades the collection,
+// zero means never
+func GCThreshold() float64 {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.GCThreshold
+}
+
+// AdminToken returns the bearer token required by the tuning endpoints
+func AdminToken() string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.AdminToken
+}
+
+// AdminAuth returns the Authenticator of the admin routes set by WithAdminAuth
+func AdminAuth() Authenticator {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.AdminAuth
+}
+
+// ReadToken returns the token required by the read-only routes
+func ReadToken() string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.ReadToken
+}
+
+// ReadAuth returns the Authenticator of the read-only routes set by
+// WithReadAuth
+func ReadAuth() Authenticator {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.ReadAuth
+}
+
+// AllowedCIDRs returns the prefixes of the addresses allowed by
+// WithAllowedCIDRs, every address is allowed when it's empty
+func AllowedCIDRs() []netip.Prefix {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.allowed
+}
+
+// RateLimit returns the requests per second and the burst allowed to every
+// client address by WithRateLimit, 0 when the requests aren't limited
+func RateLimit() (float64, int) {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	if defaultCfg.RateLimit <= 0 || defaultCfg.RateBurst <= 0 {
+		return 0, 0
+	}
+	return defaultCfg.RateLimit, defaultCfg.RateBurst
+}
+
+// SecurityHeaders returns the security headers set by WithSecurityHeaders
+// over the default ones
+func SecurityHeaders() map[string]string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.SecurityHeaders
+}
+
+// EmbedAncestors returns the sources allowed to frame the embedded charts,
+// every source by default
+func EmbedAncestors() []string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	if len(defaultCfg.EmbedAncestors) == 0 {
+		return []string{"*"}
+	}
+	return defaultCfg.EmbedAncestors
+}
+
+// AlwaysCollect returns whether the metrics are collected without any client
+func AlwaysCollect() bool {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.AlwaysCollect
+}
+
+// EnabledViewers returns the names of the viewers to serve, empty means all
+func EnabledViewers() []string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.Viewers
+}
+
+// HistoryWindow returns how long the collected points are kept, zero means not at all
+func HistoryWindow() time.Duration {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.History
+}
+
+// AnomalyThreshold returns how many standard deviations away from their
+// average the values are anomalous, zero means the detection is disabled
+func AnomalyThreshold() float64 {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.Anomalies
+}
+
+// Notifiers returns the notifiers of the anomalies
+func Notifiers() []notify.Notifier {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.Notifiers
+}
+
+// Exporters returns the exporters added by New to the ViewManager
+func Exporters() []exporter.Exporter {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return defaultCfg.Exporters
+}
+
+// StuckThreshold returns how long a goroutine is blocked at the same site
+// before it's reported as stuck
+func StuckThreshold() time.Duration {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	if defaultCfg.StuckThreshold <= 0 {
+		return DefaultStuckThreshold
+	}
+	return defaultCfg.StuckThreshold
+}
+
 // BrowserOpen returns flag of browser open
 func BrowserOpen() bool {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
 	return defaultCfg.AutoOpenBrowser
 }
 
@@ -127,6 +483,18 @@ func WithInterval(interval int) Option {
 func WithMaxPoints(n int) Option {
 	return func(c *config) {
 		c.MaxPoints = n
+		c.maxPointsSet = true
+	}
+}
+
+// WithMaxPointsOf sets the maximum points of the series of the named viewer
+// over WithMaxPoints, e.g. many more to follow a long soak test. WithViewerMaxPoints
+// of the viewer takes precedence
+func WithMaxPointsOf(name string, n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.ViewerPoints[name] = n
+		}
 	}
 }
 
@@ -153,6 +521,15 @@ func WithLinkAddr(addr string) Option {
 	}
 }
 
+// WithAdvertiseAddr sets the address announced to the service catalogs,
+// e.g. `10.0.0.7:18066`. By default it's the link address, with a loopback
+// or unspecified host replaced by the first non-loopback address of the host
+func WithAdvertiseAddr(addr string) Option {
+	return func(c *config) {
+		c.AdvertiseAddr = addr
+	}
+}
+
 // WithTimeFormat sets the time format for the line-chart Y-axis label
 func WithTimeFormat(s string) Option {
 	return func(c *config) {
@@ -160,6 +537,13 @@ func WithTimeFormat(s string) Option {
 	}
 }
 
+// WithTimeLocation sets the zone the times are formatted in, e.g. time.UTC
+func WithTimeLocation(loc *time.Location) Option {
+	return func(c *config) {
+		c.TimeLocation = loc
+	}
+}
+
 // WithTheme sets the theme of the charts
 func WithTheme(theme Theme) Option {
 	return func(c *config) {
@@ -167,6 +551,207 @@ func WithTheme(theme Theme) Option {
 	}
 }
 
+// WithViewerUnit sets the display unit of the named viewer, e.g. UnitGiB for
+// VHeap or UnitMilliseconds for VBlock
+func WithViewerUnit(name string, unit Unit) Option {
+	return func(c *config) {
+		c.Units[name] = unit
+	}
+}
+
+// WithFlightRecorder continuously keeps the last window of the execution trace
+// in memory, which could be dumped via `/debug/statsview/trace/flight` (go1.25+)
+func WithFlightRecorder(window time.Duration) Option {
+	return func(c *config) {
+		c.FlightRecorder = window
+	}
+}
+
+// WithoutPprof disables the `/debug/pprof/*` routes, e.g. when pprof is
+// already served elsewhere or must not be exposed at all
+func WithoutPprof() Option {
+	return func(c *config) {
+		c.WithoutPprof = true
+	}
+}
+
+// WithoutChartSync stops synchronizing the cursors and the zoom of the charts
+func WithoutChartSync() Option {
+	return func(c *config) {
+		c.WithoutSync = true
+	}
+}
+
+// WithCPUThreshold degrades the collection when the CPU usage of the process
+// exceeds threshold (a fraction of GOMAXPROCS, e.g. 0.8): the collecting interval
+// is lengthened by DefaultDegradeFactor and low priority viewers are skipped
+// until the usage drops again
+func WithCPUThreshold(threshold float64) Option {
+	return func(c *config) {
+		c.CPUThreshold = threshold
+	}
+}
+
+// WithGCThreshold degrades the collection like WithCPUThreshold when the
+// fraction of the CPU capacity spent in the GC exceeds threshold (e.g. 0.25).
+// Reading the memstats stops the world, collecting less often under GC
+// pressure avoids adding to it
+func WithGCThreshold(threshold float64) Option {
+	return func(c *config) {
+		c.GCThreshold = threshold
+	}
+}
+
+// WithLogger sets the logger for server lifecycle, collection errors,
+// template failures and handler panics
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) {
+		c.Logger = l
+	}
+}
+
+// WithAdminToken sets the bearer token required by the endpoints changing the
+// process, e.g. the GC tuning. Those endpoints are refused without a token
+func WithAdminToken(token string) Option {
+	return func(c *config) {
+		c.AdminToken = token
+	}
+}
+
+// Authenticator reports whether a request is allowed, e.g. by checking a
+// session cookie or a header set by a proxy
+type Authenticator func(r *http.Request) bool
+
+// WithAdminAuth allows the requests accepted by a to the admin routes, along
+// with the ones carrying the admin token
+func WithAdminAuth(a Authenticator) Option {
+	return func(c *config) {
+		c.AdminAuth = a
+	}
+}
+
+// WithReadToken sets the token required by the read-only routes, the charts
+// and the metrics, as a bearer token or the password of basic auth so
+// browsers prompt for it. The admin token is accepted too
+func WithReadToken(token string) Option {
+	return func(c *config) {
+		c.ReadToken = token
+	}
+}
+
+// WithReadAuth allows the requests accepted by a to the read-only routes,
+// setting it requires authenticating them
+func WithReadAuth(a Authenticator) Option {
+	return func(c *config) {
+		c.ReadAuth = a
+	}
+}
+
+// WithAllowedCIDRs serves only the clients whose address is in one of the
+// CIDRs, e.g. "10.0.0.0/8", a single address is accepted too. SetConfiguration
+// fails on an invalid CIDR
+func WithAllowedCIDRs(cidrs []string) Option {
+	return func(c *config) {
+		c.AllowedCIDRs = cidrs
+	}
+}
+
+// WithRateLimit limits the requests of every client address to the JSON
+// endpoints to perSecond on average, with bursts of burst requests. Zero
+// values disable the limit
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *config) {
+		c.RateLimit, c.RateBurst = perSecond, burst
+	}
+}
+
+// WithSecurityHeaders sets security headers of every response over the
+// default ones, e.g. a Content-Security-Policy allowing another origin.
+// "{nonce}" in a value is replaced by the nonce of the inline scripts of the
+// page and an empty value drops the header
+func WithSecurityHeaders(headers map[string]string) Option {
+	return func(c *config) {
+		c.SecurityHeaders = headers
+	}
+}
+
+// WithEmbedAncestors restricts the pages allowed to frame the embedded
+// charts to the CSP sources, e.g. "https://wiki.example.com"
+func WithEmbedAncestors(sources ...string) Option {
+	return func(c *config) {
+		c.EmbedAncestors = sources
+	}
+}
+
+// WithAlwaysCollect keeps collecting even when no client holds a lease, e.g.
+// to record history or feed exporters with no browser open
+func WithAlwaysCollect() Option {
+	return func(c *config) {
+		c.AlwaysCollect = true
+	}
+}
+
+// WithNumericTime adds the collection time in unix milliseconds to the served
+// metrics, so clients could render it in their own timezone
+func WithNumericTime() Option {
+	return func(c *config) {
+		c.NumericTime = true
+	}
+}
+
+// WithViewers serves only the named viewers of the collection given to the
+// manager, e.g. to trim the dashboard from a config file
+func WithViewers(names ...string) Option {
+	return func(c *config) {
+		c.Viewers = names
+	}
+}
+
+// WithHistory keeps the collected points for the window, e.g. for the
+// Grafana datasource endpoints
+func WithHistory(window time.Duration) Option {
+	return func(c *config) {
+		c.History = window
+	}
+}
+
+// WithAnomalyDetection flags the values of the collected series farther than
+// threshold standard deviations from their moving average, DefaultAnomalyThreshold
+// when it's not positive. The anomalous regions are shaded on the charts
+func WithAnomalyDetection(threshold float64) Option {
+	return func(c *config) {
+		if threshold <= 0 {
+			threshold = DefaultAnomalyThreshold
+		}
+		c.Anomalies = threshold
+	}
+}
+
+// WithNotifiers sends the anomalies starting and resolved to the notifiers,
+// e.g. notify.Slack, with WithAnomalyDetection
+func WithNotifiers(notifiers ...notify.Notifier) Option {
+	return func(c *config) {
+		c.Notifiers = append(c.Notifiers, notifiers...)
+	}
+}
+
+// WithExporters adds the exporters to the ViewManagers created by New, e.g.
+// the exporters of a config file, as AddExporter with the default flush
+// timeout
+func WithExporters(exporters ...exporter.Exporter) Option {
+	return func(c *config) {
+		c.Exporters = append(c.Exporters, exporters...)
+	}
+}
+
+// WithStuckThreshold sets how long a goroutine is blocked on the same
+// channel or lock before it's reported as stuck
+func WithStuckThreshold(d time.Duration) Option {
+	return func(c *config) {
+		c.StuckThreshold = d
+	}
+}
+
 // WithBrowserOpen sets openning browser with addr
 func WithBrowserOpen() Option {
 	return func(c *config) {
@@ -174,40 +759,151 @@ func WithBrowserOpen() Option {
 	}
 }
 
-// SetConfiguration apply configuration sets
-func SetConfiguration(opts ...Option) {
+// SetConfiguration apply configuration sets. An invalid template is not
+// applied, the previous one is kept and the error is returned. The interval,
+// the max points, the theme and the enabled viewers could be set while the
+// server runs, see CurrentView
+func SetConfiguration(opts ...Option) error {
+	cfgMu.Lock()
+	defer cfgMu.Unlock()
+	prev, prevCIDRs, prevHeaders := defaultCfg.Template, defaultCfg.AllowedCIDRs, defaultCfg.TrustedHeaders
 	for _, opt := range opts {
 		opt(defaultCfg)
 	}
+
+	if err := ValidateTemplate(defaultCfg.Template); err != nil {
+		defaultCfg.Template = prev
+		return err
+	}
+	allowed, err := parseCIDRs("allowed CIDR", defaultCfg.AllowedCIDRs)
+	if err != nil {
+		defaultCfg.AllowedCIDRs = prevCIDRs
+		return err
+	}
+	proxies, err := parseCIDRs("trusted proxy", defaultCfg.TrustedHeaders.Proxies)
+	if err != nil {
+		defaultCfg.TrustedHeaders = prevHeaders
+		return err
+	}
+	defaultCfg.allowed, defaultCfg.proxies = allowed, proxies
+	return nil
 }
 
-// Viewer is the abstraction of a Graph which in charge of collecting metrics from somewhere
-type Viewer interface {
+// Snapshot is a copy of the configuration, see SaveConfiguration
+type Snapshot struct {
+	cfg config
+}
+
+// SaveConfiguration returns a copy of the configuration to be set back by
+// RestoreConfiguration, e.g. by the tests changing it
+func SaveConfiguration() Snapshot {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return Snapshot{cfg: defaultCfg.clone()}
+}
+
+// RestoreConfiguration sets the configuration back to the snapshot
+func RestoreConfiguration(s Snapshot) {
+	cfgMu.Lock()
+	defer cfgMu.Unlock()
+	*defaultCfg = s.cfg.clone()
+}
+
+// clone copies c, the maps and the slices changed in place by the options
+// included
+func (c *config) clone() config {
+	cp := *c
+	cp.ViewerPoints = maps.Clone(c.ViewerPoints)
+	cp.Units = maps.Clone(c.Units)
+	cp.SecurityHeaders = maps.Clone(c.SecurityHeaders)
+	cp.Notifiers = slices.Clone(c.Notifiers)
+	cp.Exporters = slices.Clone(c.Exporters)
+	return cp
+}
+
+// parseCIDRs parses the CIDRs of WithAllowedCIDRs and of the trusted proxies,
+// an address is the prefix of its own
+func parseCIDRs(what string, cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, s := range cidrs {
+		s = strings.TrimSpace(s)
+		if addr, err := netip.ParseAddr(s); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("statsview: invalid %s %q", what, s)
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes, nil
+}
+
+// Renderer produces the chart of a viewer and serves its data to the chart
+type Renderer interface {
 	Name() string
 	View() *charts.Line
 	Serve(w http.ResponseWriter, _ *http.Request)
-	SetStatsMgr(smgr *StatsMgr)
 }
 
-type statsEntity struct {
-	Stats *runtime.MemStats
-	mu    sync.RWMutex
+// Collector produces the samples of a viewer in base units without serving a
+// request, e.g. for exporters and the headless mode. A collector needn't
+// render a chart, CollectorRef charts its series
+type Collector interface {
+	Name() string
+	SetStatsMgr(smgr *StatsMgr)
+	Collect() []Point
 }
 
-var memstats = &statsEntity{Stats: &runtime.MemStats{}}
+// Viewer is the abstraction of a Graph which in charge of collecting metrics
+// from somewhere, it's a Renderer reading the manager. The built-in viewers
+// are Collectors too
+type Viewer interface {
+	Renderer
+	SetStatsMgr(smgr *StatsMgr)
+}
 
-// StatsMgr runs polling memstats and sets time
+// StatsMgr runs polling memstats and sets time, every manager owns its
+// memstats snapshot which its viewers read via MemStats
 type StatsMgr struct {
-	last   int64
-	time   int64
-	Ctx    context.Context
-	Cancel context.CancelFunc
+	mu sync.RWMutex
+	// leases maps the client id to the time its lease expires, the polling
+	// pauses once every lease expired
+	leases map[string]time.Time
+	// idle is the time the last lease expires
+	idle time.Time
+	// time is the time of the last collection
+	time time.Time
+
+	statsMu  sync.RWMutex
+	memstats runtime.MemStats
+	gcMark   GCMark
+
+	overhead overheadMeter
+
+	degraded   int32
+	cpuUsage   uint64
+	gcPressure uint64
+	// onPressure is called when the collection is degraded or restored
+	onPressure func(degraded bool, reason string)
+	clock      Clock
+	// readMemStats and numGoroutine are replaced by tests and demos, see
+	// SetMemStatsReader and SetGoroutinesReader
+	readMemStats func(*runtime.MemStats)
+	numGoroutine func() int
+	lastPoll     int64
+	Ctx          context.Context
+	Cancel       context.CancelFunc
 }
 
 // NewStatsMgr create new instance
 func NewStatsMgr(ctx context.Context) *StatsMgr {
 	s := &StatsMgr{
-		last: time.Now().Unix() + int64(float64(Interval())/1000.0)*2,
+		leases:       make(map[string]time.Time),
+		clock:        CurrentClock(),
+		readMemStats: runtime.ReadMemStats,
+		numGoroutine: runtime.NumGoroutine,
 	}
 	s.Ctx, s.Cancel = context.WithCancel(ctx)
 	go s.polling()
@@ -215,38 +911,188 @@ func NewStatsMgr(ctx context.Context) *StatsMgr {
 	return s
 }
 
-// Tick atomically set last to (current time + 2*interval)
-func (s *StatsMgr) Tick() {
-	atomic.StoreInt64(&s.last, time.Now().Unix()+int64(float64(Interval())/1000.0)*2)
-}
+// Tick used to keep the polling running for two intervals after every
+// scrape, it does nothing: the polling runs while a client holds a lease.
+//
+// Deprecated: hold a lease with Lease or `/debug/statsview/lease`, or
+// collect with no client with WithAlwaysCollect
+func (s *StatsMgr) Tick() {}
 
-// GetTick returns tick value
+// GetTick returns the unix time the polling pauses unless a lease is renewed
 func (s *StatsMgr) GetTick() int64 {
-	return atomic.LoadInt64(&s.last)
+	return s.idleTime().Unix()
 }
 
-// TimeUpdate atomically set time to current time
+// TimeUpdate sets the collection time to the current time
 func (s *StatsMgr) TimeUpdate() {
-	atomic.StoreInt64(&s.time, time.Now().Unix())
+	s.mu.Lock()
+	s.time = s.clock.Now()
+	s.mu.Unlock()
+}
+
+// SetMemStatsReader replaces how the memory statistics are read, e.g. to
+// feed synthetic ones in tests. nil restores runtime.ReadMemStats
+func (s *StatsMgr) SetMemStatsReader(read func(*runtime.MemStats)) {
+	if read == nil {
+		read = runtime.ReadMemStats
+	}
+	s.statsMu.Lock()
+	s.readMemStats = read
+	s.statsMu.Unlock()
 }
 
-// GetTime returns saved time
+// SetGoroutinesReader replaces how the goroutines are counted, e.g. to feed
+// a synthetic count in demos. nil restores runtime.NumGoroutine
+func (s *StatsMgr) SetGoroutinesReader(read func() int) {
+	if read == nil {
+		read = runtime.NumGoroutine
+	}
+	s.statsMu.Lock()
+	s.numGoroutine = read
+	s.statsMu.Unlock()
+}
+
+// NumGoroutine returns the number of goroutines
+func (s *StatsMgr) NumGoroutine() int {
+	s.statsMu.RLock()
+	read := s.numGoroutine
+	s.statsMu.RUnlock()
+	return read()
+}
+
+// Refresh collects right away rather than on the next interval, e.g. once a
+// test changed the memory statistics
+func (s *StatsMgr) Refresh() {
+	s.Measure(s.collect)
+}
+
+// MemStats returns a copy of the memory statistics read by the last collection
+func (s *StatsMgr) MemStats() runtime.MemStats {
+	s.statsMu.RLock()
+	defer s.statsMu.RUnlock()
+	return s.memstats
+}
+
+// GetTime returns the unix time of the last collection
+//
+// Deprecated: GetTime is truncated to seconds, use CollectTime
 func (s *StatsMgr) GetTime() int64 {
-	return atomic.LoadInt64(&s.time)
+	return s.CollectTime().Unix()
+}
+
+// CollectTime returns the time of the last collection, the zero time means
+// nothing was collected yet
+func (s *StatsMgr) CollectTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.time
+}
+
+// LastPoll returns the time the polling loop was running last time, the
+// zero time means it didn't run yet
+func (s *StatsMgr) LastPoll() time.Time {
+	if n := atomic.LoadInt64(&s.lastPoll); n > 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
+}
+
+// Degraded reports whether the collection is degraded under CPU or GC pressure
+func (s *StatsMgr) Degraded() bool {
+	return atomic.LoadInt32(&s.degraded) == 1
+}
+
+// CPUUsage returns the last measured CPU usage as a fraction of GOMAXPROCS,
+// it's only measured when a CPU threshold is configured
+func (s *StatsMgr) CPUUsage() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.cpuUsage))
+}
+
+// GCPressure returns the last measured fraction of the CPU capacity spent
+// in the GC, it's only measured when a GC threshold is configured
+func (s *StatsMgr) GCPressure() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.gcPressure))
+}
+
+// OnPressure sets fn to be called when the collection is degraded or
+// restored, with the reason of the change
+func (s *StatsMgr) OnPressure(fn func(degraded bool, reason string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onPressure = fn
+}
+
+// CurrentInterval returns the effective collecting interval in milliseconds
+func (s *StatsMgr) CurrentInterval() int {
+	if s.Degraded() {
+		return Interval() * DefaultDegradeFactor
+	}
+	return Interval()
+}
+
+// checkPressure updates the degraded state, it returns true if the state changed
+func (s *StatsMgr) checkPressure(cpu *cpuMeter, gc *gcMeter) bool {
+	var reasons []string
+	// recover only once the usages are clearly below the thresholds to avoid flapping
+	recovered := true
+	measure := func(name string, threshold float64, usage float64, ok bool, store *uint64) {
+		if threshold <= 0 || !ok {
+			return
+		}
+		atomic.StoreUint64(store, math.Float64bits(usage))
+		if usage > threshold {
+			reasons = append(reasons, fmt.Sprintf("%s %.0f%%", name, usage*100))
+		}
+		if usage >= threshold*0.8 {
+			recovered = false
+		}
+	}
+	usage, ok := cpu.usage()
+	measure("CPU", CPUThreshold(), usage, ok, &s.cpuUsage)
+	usage, ok = gc.usage()
+	measure("GC", GCThreshold(), usage, ok, &s.gcPressure)
+
+	var reason string
+	switch {
+	case !s.Degraded() && len(reasons) > 0:
+		atomic.StoreInt32(&s.degraded, 1)
+		reason = strings.Join(reasons, ", ")
+		Logger().Warn("statsview: collection degraded under pressure", "reason", reason, "interval", s.CurrentInterval())
+	case s.Degraded() && recovered:
+		atomic.StoreInt32(&s.degraded, 0)
+		Logger().Info("statsview: collection restored", "cpu", s.CPUUsage(), "gc", s.GCPressure())
+	default:
+		return false
+	}
+
+	s.mu.RLock()
+	fn := s.onPressure
+	s.mu.RUnlock()
+	if fn != nil {
+		fn(s.Degraded(), reason)
+	}
+	return true
 }
 
 func (s *StatsMgr) polling() {
-	ticker := time.NewTicker(time.Duration(Interval()) * time.Millisecond)
+	interval := Interval()
+	ticker := s.clock.NewTicker(time.Duration(interval) * time.Millisecond)
 	defer ticker.Stop()
 
+	cpu, gc := &cpuMeter{}, &gcMeter{}
 	for {
 		select {
-		case <-ticker.C:
-			if s.GetTick() > time.Now().Unix() {
-				memstats.mu.Lock()
-				s.TimeUpdate()
-				runtime.ReadMemStats(memstats.Stats)
-				memstats.mu.Unlock()
+		case <-ticker.C():
+			atomic.StoreInt64(&s.lastPoll, s.clock.Now().UnixNano())
+			// the interval changes as the collection is degraded or restored
+			// and as it's configured while the server runs
+			s.checkPressure(cpu, gc)
+			if current := s.CurrentInterval(); current != interval {
+				interval = current
+				ticker.Reset(time.Duration(interval) * time.Millisecond)
+			}
+			if s.Collecting() {
+				s.Measure(s.collect)
 			}
 		case <-s.Ctx.Done():
 			return
@@ -254,47 +1100,104 @@ func (s *StatsMgr) polling() {
 	}
 }
 
-func genViewTemplate(vid, route string) string {
-	tpl, err := template.New("view").Parse(defaultCfg.Template)
+// collect reads the memstats and sets the collection time
+func (s *StatsMgr) collect() {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	first := s.CollectTime().IsZero()
+	prevNumGC, prevForced := s.memstats.NumGC, s.memstats.NumForcedGC
+	s.TimeUpdate()
+	s.readMemStats(&s.memstats)
+	s.updateGCMark(prevNumGC, prevForced, first)
+}
+
+// viewTemplateData is the data the view template is executed with
+type viewTemplateData struct {
+	Interval  int
+	MaxPoints int
+	Addr      string
+	Route     string
+	ViewID    string
+}
+
+func execViewTemplate(t string, c viewTemplateData) (string, error) {
+	tpl, err := template.New("view").Parse(t)
 	if err != nil {
-		panic("statsview: failed to parse template " + err.Error())
-	}
-
-	var c = struct {
-		Interval  int
-		MaxPoints int
-		Addr      string
-		Route     string
-		ViewID    string
-	}{
-		Interval:  defaultCfg.Interval,
-		MaxPoints: defaultCfg.MaxPoints,
-		Addr:      defaultCfg.LinkAddr,
-		Route:     route,
-		ViewID:    vid,
+		return "", fmt.Errorf("statsview: failed to parse template: %w", err)
 	}
 
 	buf := bytes.Buffer{}
 	if err := tpl.Execute(&buf, c); err != nil {
-		panic("statsview: failed to execute template " + err.Error())
+		return "", fmt.Errorf("statsview: failed to execute template: %w", err)
 	}
+	return buf.String(), nil
+}
 
-	return buf.String()
+// ValidateTemplate checks that t could be parsed and executed as a view template
+func ValidateTemplate(t string) error {
+	_, err := execViewTemplate(t, viewTemplateData{
+		Interval:  DefaultInterval,
+		MaxPoints: DefaultMaxPoints,
+		Addr:      DefaultAddr,
+		Route:     "validate",
+		ViewID:    "validate",
+	})
+	return err
 }
 
+// genViewTemplate executes the view template of the chart vid, maxPoints
+// overrides the configured MaxPointsOf the route when positive
+func genViewTemplate(t, vid, route string, maxPoints int) (string, error) {
+	if maxPoints <= 0 {
+		maxPoints = MaxPointsOf(route)
+	}
+	return execViewTemplate(t, viewTemplateData{
+		Interval:  Interval(),
+		MaxPoints: maxPoints,
+		Addr:      LinkAddr(),
+		Route:     route,
+		ViewID:    vid,
+	})
+}
+
+// fixedPrecision rounds n to p decimals, a negative p keeps n as is
 func fixedPrecision(n float64, p int) float64 {
-	var r float64
-	switch p {
-	case 2:
-		r, _ = strconv.ParseFloat(fmt.Sprintf("%.2f", n), 64)
-	case 6:
-		r, _ = strconv.ParseFloat(fmt.Sprintf("%.6f", n), 64)
+	if p < 0 {
+		return n
 	}
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(n, 'f', p, 64), 64)
 	return r
 }
 
+// metricsOf converts points into the Metrics served to the charts
+func metricsOf(points []Point, unit Unit, precision int) Metrics {
+	values := make([]float64, 0, len(points))
+	var t time.Time
+	for _, p := range points {
+		values = append(values, fixedPrecision(unit.Convert(p.Value), precision))
+		t = p.Time
+	}
+	return NewMetrics(values, t)
+}
+
 // NewBasicView generate new charts.Line with default variables
 func NewBasicView(route string) *charts.Line {
+	return newBasicView(route, 0)
+}
+
+// toolboxOpts shows the button saving the chart as a PNG image
+func toolboxOpts() charts.GlobalOpts {
+	return charts.WithToolboxOpts(opts.Toolbox{
+		Show:  true,
+		Right: "5%",
+		Feature: &opts.ToolBoxFeature{
+			SaveAsImage: &opts.ToolBoxFeatureSaveAsImage{Show: true, Title: "Save as image"},
+		},
+	})
+}
+
+func newBasicView(route string, maxPoints int) *charts.Line {
 	graph := charts.NewLine()
 	graph.SetGlobalOptions(
 		charts.WithLegendOpts(opts.Legend{Show: true}),
@@ -305,13 +1208,22 @@ func NewBasicView(route string) *charts.Line {
 			Start: 0,
 			End:   100,
 		}),
+		toolboxOpts(),
 		charts.WithInitializationOpts(opts.Initialization{
 			Width:  "600px",
 			Height: "400px",
-			Theme:  string(defaultCfg.Theme),
+			Theme:  string(ChartTheme()),
 		}),
 	)
 	graph.SetXAxis([]string{}).SetSeriesOptions(charts.WithLineChartOpts(opts.LineChart{Smooth: true}))
-	graph.AddJSFuncs(genViewTemplate(graph.ChartID, route))
+	// the template is validated by SetConfiguration, a failure here leaves
+	// the chart static rather than taking down the host application
+	if err := addViewTemplate(graph, Template(), route, maxPoints); err != nil {
+		Logger().Error("statsview: failed to generate view template", "route", route, "err", err)
+		return graph
+	}
+	if ChartSync() {
+		graph.AddJSFuncs(fmt.Sprintf("goecharts_%s.group = %q;", graph.ChartID, ChartGroup))
+	}
 	return graph
 }
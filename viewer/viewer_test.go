@@ -0,0 +1,389 @@
+package viewer
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPprofEnabled(t *testing.T) {
+	defer func(without bool) { defaultCfg.WithoutPprof = without }(defaultCfg.WithoutPprof)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{"default", nil, true},
+		{"without pprof", []Option{WithoutPprof()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.WithoutPprof = false
+			SetConfiguration(tt.opts...)
+			if got := PprofEnabled(); got != tt.want {
+				t.Errorf("PprofEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tpl     string
+		wantErr string
+	}{
+		{"default", DefaultTemplate, ""},
+		{"fields", `{{ .ViewID }} {{ .Route }} {{ .Interval }} {{ .MaxPoints }} {{ .Addr }}`, ""},
+		{"unparsable", `{{ .ViewID `, "failed to parse template"},
+		{"unknown field", `{{ .Chart }}`, "failed to execute template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTemplate(tt.tpl)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ValidateTemplate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetConfigurationKeepsValidTemplate(t *testing.T) {
+	defer func(tpl string) { defaultCfg.Template = tpl }(defaultCfg.Template)
+
+	if err := SetConfiguration(WithTemplate(`{{ .ViewID `)); err == nil {
+		t.Error("SetConfiguration() accepted an invalid template")
+	}
+	if Template() != DefaultTemplate {
+		t.Errorf("the invalid template replaced the previous one: %q", Template())
+	}
+	if err := SetConfiguration(WithTemplate(`{{ .ViewID }}`)); err != nil {
+		t.Errorf("SetConfiguration() = %v", err)
+	}
+	if got, _ := genViewTemplate(Template(), "goecharts_heap", "heap", DefaultMaxPoints); got != "goecharts_heap" {
+		t.Errorf("the view template = %q, want goecharts_heap", got)
+	}
+}
+
+func TestAllowedCIDRs(t *testing.T) {
+	defer func(cidrs []string, allowed []netip.Prefix) {
+		defaultCfg.AllowedCIDRs, defaultCfg.allowed = cidrs, allowed
+	}(defaultCfg.AllowedCIDRs, defaultCfg.allowed)
+
+	tests := []struct {
+		name  string
+		cidrs []string
+		want  []string
+		err   bool
+	}{
+		{name: "none", want: []string{}},
+		{name: "prefixes", cidrs: []string{"10.0.0.0/8", " 2001:db8::/32"}, want: []string{"10.0.0.0/8", "2001:db8::/32"}},
+		{name: "masked", cidrs: []string{"10.1.2.3/8"}, want: []string{"10.0.0.0/8"}},
+		{name: "addresses", cidrs: []string{"192.0.2.1", "::ffff:192.0.2.2", "2001:db8::1"}, want: []string{"192.0.2.1/32", "192.0.2.2/32", "2001:db8::1/128"}},
+		{name: "invalid keeps the previous ones", cidrs: []string{"10.0.0.0/8", "localhost"}, want: []string{"192.0.2.1/32", "192.0.2.2/32", "2001:db8::1/128"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetConfiguration(WithAllowedCIDRs(tt.cidrs))
+			if (err != nil) != tt.err {
+				t.Fatalf("SetConfiguration() = %v", err)
+			}
+			got := []string{}
+			for _, p := range AllowedCIDRs() {
+				got = append(got, p.String())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("AllowedCIDRs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	defer func(interval int, format string) {
+		defaultCfg.Interval, defaultCfg.TimeFormat = interval, format
+	}(defaultCfg.Interval, defaultCfg.TimeFormat)
+
+	tests := []struct {
+		name     string
+		interval int
+		format   string
+		want     string
+	}{
+		{"default", DefaultInterval, DefaultTimeFormat, DefaultTimeFormat},
+		{"sub-second", 250, DefaultTimeFormat, DefaultTimeFormat + ".000"},
+		{"custom sub-second", 250, "15:04", "15:04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.Interval, defaultCfg.TimeFormat = tt.interval, tt.format
+			if got := TimeFormat(); got != tt.want {
+				t.Errorf("TimeFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsMgrLease(t *testing.T) {
+	defer func(interval int) { defaultCfg.Interval = interval }(defaultCfg.Interval)
+	defaultCfg.Interval = 100
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	before := time.Now()
+	s := NewStatsMgr(ctx)
+
+	// nothing is collected until a client holds a lease, a scrape doesn't
+	// take one
+	s.Tick()
+	time.Sleep(300 * time.Millisecond)
+	if got := s.CollectTime(); !got.IsZero() {
+		t.Fatalf("collected at %v with no lease", got)
+	}
+
+	s.Lease("test", 200*time.Millisecond)
+	for deadline := time.Now().Add(time.Second); s.CollectTime().IsZero(); {
+		if time.Now().After(deadline) {
+			t.Fatal("nothing collected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := s.CollectTime(); got.Before(before) || got.Unix() != s.GetTime() {
+		t.Errorf("CollectTime() = %v, GetTime() = %d", got, s.GetTime())
+	}
+
+	// once the lease expired the collection pauses
+	time.Sleep(400 * time.Millisecond)
+	paused := s.CollectTime()
+	time.Sleep(300 * time.Millisecond)
+	if got := s.CollectTime(); !got.Equal(paused) {
+		t.Errorf("collected at %v while idle", got)
+	}
+}
+
+func TestMemStats(t *testing.T) {
+	defer func(interval int) { defaultCfg.Interval = interval }(defaultCfg.Interval)
+	defaultCfg.Interval = 10
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	polled := NewStatsMgr(ctx)
+	polled.Lease("test", time.Second)
+	idle := &StatsMgr{leases: make(map[string]time.Time), clock: SystemClock}
+
+	for deadline := time.Now().Add(time.Second); polled.CollectTime().IsZero(); {
+		if time.Now().After(deadline) {
+			t.Fatal("nothing collected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// stop the polling so the snapshot doesn't change under the test
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+
+	tests := []struct {
+		name   string
+		smgr   *StatsMgr
+		filled bool
+	}{
+		{"collected", polled, true},
+		{"not collected", idle, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := tt.smgr.MemStats()
+			if filled := ms.HeapAlloc > 0; filled != tt.filled {
+				t.Errorf("HeapAlloc = %d, want filled %v", ms.HeapAlloc, tt.filled)
+			}
+
+			// the viewers read the snapshot of their own manager
+			v := NewGCNumViewer()
+			v.SetStatsMgr(tt.smgr)
+			points := v.(Collector).Collect()
+			if len(points) != 1 || points[0].Value != float64(tt.smgr.MemStats().NumGC) {
+				t.Errorf("Collect() = %v, want NumGC %d", points, tt.smgr.MemStats().NumGC)
+			}
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	defer func(loc *time.Location, format string) {
+		defaultCfg.TimeLocation, defaultCfg.TimeFormat = loc, format
+	}(defaultCfg.TimeLocation, defaultCfg.TimeFormat)
+	defaultCfg.TimeFormat = "15:04"
+
+	tokyo := time.FixedZone("JST", 9*3600)
+	at := time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		loc  *time.Location
+		want string
+	}{
+		{"local", nil, at.In(time.Local).Format("15:04")},
+		{"utc", time.UTC, "12:30"},
+		{"fixed zone", tokyo, "21:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.TimeLocation = nil
+			SetConfiguration(WithTimeLocation(tt.loc))
+			if got := FormatTime(at); got != tt.want {
+				t.Errorf("FormatTime() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChartSync(t *testing.T) {
+	defer func(without bool) { defaultCfg.WithoutSync = without }(defaultCfg.WithoutSync)
+
+	tests := []struct {
+		name  string
+		opts  []Option
+		group bool
+	}{
+		{"default", nil, true},
+		{"without sync", []Option{WithoutChartSync()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.WithoutSync = false
+			SetConfiguration(tt.opts...)
+			if ChartSync() != tt.group {
+				t.Errorf("ChartSync() = %v, want %v", ChartSync(), tt.group)
+			}
+			graph := NewBasicView(VHeap)
+			want := fmt.Sprintf("goecharts_%s.group = %q;", graph.ChartID, ChartGroup)
+			if got := strings.Contains(strings.Join(graph.JSFunctions.Fns, ""), want); got != tt.group {
+				t.Errorf("chart in the group = %v, want %v", got, tt.group)
+			}
+		})
+	}
+}
+
+func TestMaxPointsOf(t *testing.T) {
+	defer func(interval, maxPoints int, set bool, points map[string]int, history time.Duration) {
+		defaultCfg.Interval, defaultCfg.MaxPoints, defaultCfg.maxPointsSet = interval, maxPoints, set
+		defaultCfg.ViewerPoints, defaultCfg.History = points, history
+	}(defaultCfg.Interval, defaultCfg.MaxPoints, defaultCfg.maxPointsSet, defaultCfg.ViewerPoints, defaultCfg.History)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]int
+	}{
+		{name: "default", want: map[string]int{VHeap: DefaultMaxPoints, VGoroutine: DefaultMaxPoints}},
+		{name: "max points", opts: []Option{WithMaxPoints(90)}, want: map[string]int{VHeap: 90, VGoroutine: 90}},
+		{
+			name: "per viewer",
+			opts: []Option{WithMaxPoints(90), WithMaxPointsOf(VHeap, 600), WithMaxPointsOf(VGoroutine, 0)},
+			want: map[string]int{VHeap: 600, VGoroutine: 90},
+		},
+		{
+			name: "history window",
+			opts: []Option{WithHistory(time.Hour), WithInterval(2000)},
+			want: map[string]int{VHeap: 1800, VGoroutine: 1800},
+		},
+		{
+			name: "history window shorter than the max points",
+			opts: []Option{WithHistory(time.Minute), WithInterval(2000)},
+			want: map[string]int{VHeap: DefaultMaxPoints, VGoroutine: DefaultMaxPoints},
+		},
+		{
+			name: "max points over the history window",
+			opts: []Option{WithHistory(time.Hour), WithMaxPoints(120), WithMaxPointsOf(VHeap, 3600)},
+			want: map[string]int{VHeap: 3600, VGoroutine: 120},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.Interval, defaultCfg.MaxPoints, defaultCfg.maxPointsSet = DefaultInterval, DefaultMaxPoints, false
+			defaultCfg.ViewerPoints, defaultCfg.History = map[string]int{}, 0
+			if err := SetConfiguration(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if got := MaxPointsOf(name); got != want {
+					t.Errorf("MaxPointsOf(%s) = %d, want %d", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnomalyThreshold(t *testing.T) {
+	defer func(threshold float64) { defaultCfg.Anomalies = threshold }(defaultCfg.Anomalies)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want float64
+	}{
+		{"disabled", nil, 0},
+		{"threshold", []Option{WithAnomalyDetection(3)}, 3},
+		{"default threshold", []Option{WithAnomalyDetection(0)}, DefaultAnomalyThreshold},
+		{"negative threshold", []Option{WithAnomalyDetection(-1)}, DefaultAnomalyThreshold},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultCfg.Anomalies = 0
+			if err := SetConfiguration(tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got := AnomalyThreshold(); got != tt.want {
+				t.Errorf("AnomalyThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessorsWhileConfiguring(t *testing.T) {
+	defer RestoreConfiguration(SaveConfiguration())
+
+	tests := []struct {
+		name   string
+		option Option
+		read   func()
+	}{
+		{"addr", WithAddr("localhost:18066"), func() { Addr() }},
+		{"link addr", WithLinkAddr("localhost:8080"), func() { LinkAddr() }},
+		{"template", WithTemplate(DefaultTemplate), func() { Template() }},
+		{"time format", WithTimeFormat("15:04"), func() { TimeFormat() }},
+		{"time location", WithTimeLocation(time.UTC), func() { FormatTime(time.Now()) }},
+		{"admin token", WithAdminToken("s3cret"), func() { AdminToken() }},
+		{"allowed CIDRs", WithAllowedCIDRs([]string{"127.0.0.1"}), func() { AllowedCIDRs() }},
+		{"history", WithHistory(time.Hour), func() { HistoryWindow() }},
+		{"always collect", WithAlwaysCollect(), func() { AlwaysCollect() }},
+		{"numeric time", WithNumericTime(), func() { NewMetrics([]float64{1}, time.Now()) }},
+		{"logger", WithLogger(nil), func() { Logger() }},
+		{"basic view", WithInterval(500), func() { NewBasicView("test") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					if err := SetConfiguration(tt.option); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					tt.read()
+				}
+			}()
+			wg.Wait()
+		})
+	}
+}
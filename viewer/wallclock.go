@@ -0,0 +1,113 @@
+package viewer
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/mortum5/statsview/internal/wallprof"
+)
+
+const (
+	// VWallClock is the name of WallClockViewer
+	VWallClock = "wallclock"
+)
+
+// WallClockViewer splits the wall-clock time of the goroutines into the
+// on-CPU time, the goroutines running, runnable or in a syscall, and the
+// off-CPU time, the ones parked on a channel, a lock, the network or a
+// timer. A CPU profile only sees the former
+type WallClockViewer struct {
+	smgr  *StatsMgr
+	graph *charts.Line
+	opts  viewerOptions
+
+	mu    sync.Mutex
+	at    time.Time
+	split []float64
+}
+
+// NewWallClockViewer returns the WallClockViewer instance, the fractions of
+// the goroutines sampled on every collection are stacked up to 1
+// Series: OnCPU / OffCPU
+func NewWallClockViewer(vopts ...ViewerOption) Viewer {
+	o := newViewerOptions(vopts)
+	graph := o.newBasicView(VWallClock)
+	graph.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: "Wall-clock time"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Ratio"}),
+	)
+	graph.AddSeries("OnCPU", []opts.LineData{}).
+		AddSeries("OffCPU", []opts.LineData{})
+	for i := range graph.MultiSeries {
+		graph.MultiSeries[i].Stack = "wallclock"
+		graph.MultiSeries[i].AreaStyle = &opts.AreaStyle{Opacity: 0.5}
+	}
+
+	o.apply(graph)
+	formatSeries(graph, UnitNone, DimensionRatio, nil)
+	return &WallClockViewer{graph: graph, opts: o}
+}
+
+func (vr *WallClockViewer) SetStatsMgr(smgr *StatsMgr) {
+	vr.smgr = smgr
+}
+
+func (vr *WallClockViewer) Name() string {
+	return VWallClock
+}
+
+func (vr *WallClockViewer) View() *charts.Line {
+	return vr.graph
+}
+
+// Priority is low since sampling the goroutines stops the world
+func (vr *WallClockViewer) Priority() Priority {
+	return PriorityLow
+}
+
+// Collect returns the fractions of the goroutines on and off CPU, they're
+// sampled once per collection
+func (vr *WallClockViewer) Collect() []Point {
+	t := vr.smgr.CollectTime()
+
+	vr.mu.Lock()
+	if vr.split == nil || !t.Equal(vr.at) {
+		vr.at, vr.split = t, wallClockSplit()
+	}
+	split := vr.split
+	vr.mu.Unlock()
+
+	return []Point{
+		{Viewer: VWallClock, Series: "OnCPU", Value: split[0], Time: t},
+		{Viewer: VWallClock, Series: "OffCPU", Value: split[1], Time: t},
+	}
+}
+
+// wallClockSplit samples the goroutines and returns the fractions on and
+// off CPU
+func wallClockSplit() []float64 {
+	stacks := wallprof.Stacks()
+	split := make([]float64, 2)
+	if len(stacks) == 0 {
+		return split
+	}
+	for _, s := range stacks {
+		if wallprof.OffCPU(s) {
+			split[1]++
+		} else {
+			split[0]++
+		}
+	}
+	split[0] /= float64(len(stacks))
+	split[1] /= float64(len(stacks))
+	return split
+}
+
+func (vr *WallClockViewer) Serve(w http.ResponseWriter, _ *http.Request) {
+	metrics := vr.opts.metricsOf(vr.Collect(), UnitNone, 4)
+
+	WriteJSON(w, metrics)
+}
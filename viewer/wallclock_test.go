@@ -0,0 +1,50 @@
+package viewer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWallClockViewer(t *testing.T) {
+	s := &StatsMgr{time: time.Unix(1700000000, 0)}
+	vr := NewWallClockViewer().(*WallClockViewer)
+	vr.SetStatsMgr(s)
+
+	ch := make(chan struct{})
+	defer close(ch)
+	for i := 0; i < 10; i++ {
+		go func() { <-ch }()
+	}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		sampled bool
+	}{
+		{"first collection", 0, true},
+		{"same collection", 0, false},
+		{"next collection", time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.time = s.time.Add(tt.advance)
+			before := vr.split
+			points := vr.Collect()
+			if len(points) != 2 || points[0].Series != "OnCPU" || points[1].Series != "OffCPU" {
+				t.Fatalf("Collect() = %+v", points)
+			}
+			if sum := points[0].Value + points[1].Value; sum < 0.999 || sum > 1.001 {
+				t.Errorf("the fractions sum up to %v, want 1", sum)
+			}
+			if points[1].Value <= 0 {
+				t.Errorf("OffCPU = %v with parked goroutines", points[1].Value)
+			}
+			if before == nil {
+				return
+			}
+			if sampled := &before[0] != &vr.split[0]; sampled != tt.sampled {
+				t.Errorf("sampled = %v, want %v", sampled, tt.sampled)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package statsview
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+// views returns the served viewers, the slice isn't modified afterwards
+func (vm *ViewManager) views() []viewer.Viewer {
+	vm.viewsMu.RLock()
+	defer vm.viewsMu.RUnlock()
+	return vm.Views
+}
+
+// Viewers returns the served viewers, it's safe to call while viewers are
+// registered and unregistered
+func (vm *ViewManager) Viewers() []viewer.Viewer {
+	return vm.views()
+}
+
+// countError counts an error of the named viewer, the errors of a viewer
+// unregistered meanwhile are dropped
+func (vm *ViewManager) countError(name string) {
+	vm.viewsMu.RLock()
+	counter := vm.viewErrors[name]
+	vm.viewsMu.RUnlock()
+	if counter != nil {
+		atomic.AddInt64(counter, 1)
+	}
+}
+
+// knownNames returns the names of the viewers given to New and registered,
+// served or not
+func (vm *ViewManager) knownNames() []string {
+	vm.viewsMu.RLock()
+	defer vm.viewsMu.RUnlock()
+	names := make([]string, 0, len(vm.known))
+	for _, v := range vm.known {
+		names = append(names, v.Name())
+	}
+	return names
+}
+
+// prepare readies v to be served: its references are resolved, its chart is
+// validated and it's initialized when the manager is
+func (vm *ViewManager) prepare(v viewer.Viewer) error {
+	if r, ok := v.(viewer.Resolver); ok {
+		if err := r.Resolve(vm.viewerByName); err != nil {
+			return err
+		}
+	}
+	v.SetStatsMgr(vm.Smgr)
+	v.View().Validate()
+	if in, ok := v.(viewer.Initializer); ok && atomic.LoadInt32(&vm.initialized) == 1 {
+		if err := in.Init(vm.Ctx); err != nil {
+			viewer.Logger().Error("statsview: failed to init viewer", "viewer", v.Name(), "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// release closes the viewers no longer served when the manager initialized them
+func (vm *ViewManager) release(views []viewer.Viewer) {
+	if atomic.LoadInt32(&vm.initialized) == 1 {
+		vm.closeViewers(views)
+	}
+}
+
+// publish replaces the served viewers, the error counters follow them
+func (vm *ViewManager) publish(known, views []viewer.Viewer) {
+	vm.viewsMu.Lock()
+	defer vm.viewsMu.Unlock()
+	vm.known, vm.Views = known, views
+	counters := make(map[string]*int64, len(views))
+	for _, v := range views {
+		counter := vm.viewErrors[v.Name()]
+		if counter == nil {
+			counter = new(int64)
+		}
+		counters[v.Name()] = counter
+	}
+	vm.viewErrors = counters
+}
+
+// Register adds v to the viewers while the server is running, e.g. an ad-hoc
+// custom viewer attached during a debugging session. Its routes are served
+// right away, its chart shows up as the dashboard reloads and it's
+// initialized when the manager is. It fails when a viewer of the same name is
+// known or when v fails to initialize
+func (vm *ViewManager) Register(v viewer.Viewer) error {
+	vm.changeMu.Lock()
+	defer vm.changeMu.Unlock()
+
+	for _, name := range vm.knownNames() {
+		if name == v.Name() {
+			return fmt.Errorf("statsview: viewer %s is already registered", v.Name())
+		}
+	}
+	if err := vm.prepare(v); err != nil {
+		return err
+	}
+
+	vm.viewsMu.RLock()
+	known := append(append(make([]viewer.Viewer, 0, len(vm.known)+1), vm.known...), v)
+	views := append(append(make([]viewer.Viewer, 0, len(vm.Views)+1), vm.Views...), v)
+	vm.viewsMu.RUnlock()
+	vm.publish(known, views)
+
+	viewer.Logger().Info("statsview: viewer registered", "viewer", v.Name())
+	return nil
+}
+
+// Unregister removes the named viewer while the server is running, its
+// routes answer 404 Not Found and its chart is gone as the dashboard reloads.
+// The viewer is closed when the manager initialized it. It reports whether
+// the viewer was known
+func (vm *ViewManager) Unregister(name string) bool {
+	vm.changeMu.Lock()
+	defer vm.changeMu.Unlock()
+
+	vm.viewsMu.RLock()
+	known := without(vm.known, name)
+	views := without(vm.Views, name)
+	removed := len(known) < len(vm.known)
+	served := len(views) < len(vm.Views)
+	closed := findViewer(vm.Views, name)
+	vm.viewsMu.RUnlock()
+	if !removed {
+		return false
+	}
+	vm.publish(known, views)
+
+	if served {
+		vm.release([]viewer.Viewer{closed})
+	}
+	viewer.Logger().Info("statsview: viewer unregistered", "viewer", name)
+	return true
+}
+
+// serveOnly serves the known viewers of names in the order they're known,
+// the others are kept to be served again
+func (vm *ViewManager) serveOnly(names []string) error {
+	vm.changeMu.Lock()
+	defer vm.changeMu.Unlock()
+
+	vm.viewsMu.RLock()
+	known, served := vm.known, vm.Views
+	vm.viewsMu.RUnlock()
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		if findViewer(known, name) == nil {
+			return fmt.Errorf("statsview: unknown viewer %q", name)
+		}
+		wanted[name] = true
+	}
+
+	var views, added, removed []viewer.Viewer
+	for _, v := range known {
+		isServed := findViewer(served, v.Name()) != nil
+		switch {
+		case wanted[v.Name()] && !isServed:
+			if err := vm.prepare(v); err != nil {
+				vm.release(added)
+				return err
+			}
+			added = append(added, v)
+		case !wanted[v.Name()] && isServed:
+			removed = append(removed, v)
+		}
+		if wanted[v.Name()] {
+			views = append(views, v)
+		}
+	}
+	vm.publish(known, views)
+	vm.release(removed)
+	return nil
+}
+
+// findViewer returns the viewer of the name in views, nil if there is none
+func findViewer(views []viewer.Viewer, name string) viewer.Viewer {
+	for _, v := range views {
+		if v.Name() == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// without returns a copy of views without the named viewer
+func without(views []viewer.Viewer, name string) []viewer.Viewer {
+	kept := make([]viewer.Viewer, 0, len(views))
+	for _, v := range views {
+		if v.Name() != name {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
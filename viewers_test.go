@@ -0,0 +1,140 @@
+package statsview
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestRegister(t *testing.T) {
+	errInit := errors.New("init failed")
+	tests := []struct {
+		name        string
+		viewer      viewer.Viewer
+		initialized bool
+		err         string
+		inits       int
+	}{
+		{name: "new viewer", viewer: viewer.NewHeapViewer()},
+		{name: "duplicate", viewer: viewer.NewGoroutinesViewer(), err: "viewer goroutine is already registered"},
+		{name: "composed", viewer: viewer.Compose("concurrency", viewer.Ref(viewer.VGoroutine, "Goroutines"))},
+		{name: "unresolved", viewer: viewer.Compose("concurrency", viewer.Ref(viewer.VHeap, "Alloc")), err: "unknown viewer heap"},
+		{
+			name:   "not initialized yet",
+			viewer: &lifecycleViewer{Viewer: viewer.NewGCNumViewer(), name: "hooked"},
+		},
+		{
+			name:        "initialized right away",
+			viewer:      &lifecycleViewer{Viewer: viewer.NewGCNumViewer(), name: "hooked"},
+			initialized: true,
+			inits:       1,
+		},
+		{
+			name:        "failed to initialize",
+			viewer:      &lifecycleViewer{Viewer: viewer.NewGCNumViewer(), name: "hooked", initErr: errInit},
+			initialized: true,
+			err:         "init failed",
+			inits:       1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mgr.Stop()
+			if tt.initialized {
+				if err := mgr.initViewers(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			get := func(path string) *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				return rec
+			}
+			name := tt.viewer.Name()
+			before := get("/debug/statsview/view/" + name).Code
+
+			err = mgr.Register(tt.viewer)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Register() = %v, want %q", err, tt.err)
+				}
+				if got := get("/debug/statsview/view/" + name).Code; got != before {
+					t.Errorf("the refused viewer changed the route from %d to %d", before, got)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, path := range []string{"/debug/statsview/view/" + name, APIPrefix + "/metrics/" + name, EmbedPath + name} {
+					if rec := get(path); rec.Code != http.StatusOK {
+						t.Errorf("%s status = %d", path, rec.Code)
+					}
+				}
+				if body := get("/debug/statsview").Body.String(); strings.Count(body, `class="item"`) != 2 {
+					t.Errorf("the dashboard doesn't show the 2 charts")
+				}
+			}
+			if lv, ok := tt.viewer.(*lifecycleViewer); ok && lv.inits != tt.inits {
+				t.Errorf("%d inits, want %d", lv.inits, tt.inits)
+			}
+		})
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	hooked := &lifecycleViewer{Viewer: viewer.NewGCNumViewer(), name: "hooked"}
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer(), hooked})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+	if err := mgr.initViewers(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		want   bool
+		closes int
+		served []string
+	}{
+		{"hooked", true, 1, []string{"goroutine"}},
+		{"hooked", false, 1, []string{"goroutine"}},
+		{"unknown", false, 1, []string{"goroutine"}},
+		{"goroutine", true, 1, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mgr.Unregister(tt.name); got != tt.want {
+				t.Errorf("Unregister() = %v, want %v", got, tt.want)
+			}
+			if hooked.closes != tt.closes {
+				t.Errorf("%d closes, want %d", hooked.closes, tt.closes)
+			}
+			rec := httptest.NewRecorder()
+			mgr.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/statsview/view/"+tt.name, nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want 404", rec.Code)
+			}
+			served := []string{}
+			for _, v := range mgr.views() {
+				served = append(served, v.Name())
+			}
+			if strings.Join(served, ",") != strings.Join(tt.served, ",") {
+				t.Errorf("served %v, want %v", served, tt.served)
+			}
+			if _, ok := mgr.health().Viewers[tt.name]; ok {
+				t.Errorf("the health of %s is reported", tt.name)
+			}
+		})
+	}
+}
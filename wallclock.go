@@ -0,0 +1,74 @@
+package statsview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/mortum5/statsview/internal/wallprof"
+	"github.com/mortum5/statsview/viewer"
+)
+
+// wallClockHz is the rate the goroutines are sampled at by the wall-clock
+// profile, off the multiples of the usual tickers
+const wallClockHz = 99
+
+// wallClockCapturing is set while a wall-clock profile is captured, one at
+// a time since every sample stops the world
+var wallClockCapturing int32
+
+var errWallClockCapturing = errors.New("statsview: a wall-clock profile is already being captured")
+
+// captureWallClock samples the goroutines for d or until ctx is done
+func captureWallClock(ctx context.Context, d time.Duration) (*profile.Profile, error) {
+	if !atomic.CompareAndSwapInt32(&wallClockCapturing, 0, 1) {
+		return nil, errWallClockCapturing
+	}
+	defer atomic.StoreInt32(&wallClockCapturing, 0)
+	return wallprof.Profile(ctx, d, wallClockHz), nil
+}
+
+// profileDuration reads the `seconds` of a profile capture, 30 by default,
+// the capture must end before the server times the response out
+func (vm *ViewManager) profileDuration(r *http.Request) (time.Duration, error) {
+	seconds := 30
+	if s := r.URL.Query().Get("seconds"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return 0, errors.New("statsview: invalid seconds")
+		}
+		seconds = n
+	}
+	d := time.Duration(seconds) * time.Second
+	if timeout := vm.srv.WriteTimeout; timeout > 0 && d >= timeout {
+		return 0, errors.New("statsview: profile duration exceeds the server's WriteTimeout")
+	}
+	return d, nil
+}
+
+// wallClockProfile captures a wall-clock profile of `seconds` and serves it
+// for download in the pprof format
+func (vm *ViewManager) wallClockProfile(w http.ResponseWriter, r *http.Request) {
+	d, err := vm.profileDuration(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p, err := captureWallClock(r.Context(), d)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="wallclock-%s.pb.gz"`, time.Now().Format("20060102-150405")))
+	if err := p.Write(w); err != nil {
+		viewer.Logger().Error("statsview: failed to write wall-clock profile", "err", err)
+	}
+}
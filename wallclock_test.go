@@ -0,0 +1,72 @@
+package statsview
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+	"github.com/mortum5/statsview/viewer"
+)
+
+func TestWallClockProfile(t *testing.T) {
+	mgr, err := New(Viewers{viewer.NewGoroutinesViewer()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Stop()
+
+	tests := []struct {
+		name      string
+		query     string
+		capturing bool
+		timeout   time.Duration
+		status    int
+	}{
+		{name: "invalid seconds", query: "?seconds=long", status: http.StatusBadRequest},
+		{name: "zero seconds", query: "?seconds=0", status: http.StatusBadRequest},
+		{name: "exceeds the write timeout", query: "?seconds=3600", status: http.StatusBadRequest},
+		{name: "already capturing", query: "?seconds=1", capturing: true, status: http.StatusConflict},
+		{name: "captured until the client left", query: "?seconds=1", timeout: 100 * time.Millisecond, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.capturing {
+				atomic.StoreInt32(&wallClockCapturing, 1)
+				defer atomic.StoreInt32(&wallClockCapturing, 0)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/debug/statsview/profile/wallclock"+tt.query, nil)
+			if tt.timeout > 0 {
+				ctx, cancel := context.WithTimeout(r.Context(), tt.timeout)
+				defer cancel()
+				r = r.WithContext(ctx)
+			}
+			rec := httptest.NewRecorder()
+			mgr.wallClockProfile(rec, r)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, `filename="wallclock-`) {
+				t.Errorf("Content-Disposition = %s", cd)
+			}
+			p, err := profile.Parse(rec.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.PeriodType.Type != "wallclock" || len(p.Sample) == 0 {
+				t.Errorf("profile of %s with %d samples", p.PeriodType.Type, len(p.Sample))
+			}
+			if atomic.LoadInt32(&wallClockCapturing) != 0 {
+				t.Error("still capturing after the profile was served")
+			}
+		})
+	}
+}